@@ -18,10 +18,14 @@ all from a beautiful terminal interface powered by Bubble Tea.`,
 }
 
 func main() {
+	cli.AddVerbosityFlags(rootCmd)
+
 	// Add commands
 	rootCmd.AddCommand(cli.DashboardCmd)
 	rootCmd.AddCommand(cli.ServeCmd)
 	rootCmd.AddCommand(cli.BuildCmd)
+	rootCmd.AddCommand(cli.LogsCmd)
+	rootCmd.AddCommand(cli.PackageCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
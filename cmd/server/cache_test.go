@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCacheHeaders(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":    {Data: []byte("<html></html>")},
+		"app/main.wasm": {Data: []byte("wasm")},
+		"app/other.js":  {Data: []byte("wasm")},
+	}
+	etags, err := computeETags(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(etags) != 3 {
+		t.Fatalf("got %d ETags, want 3: %v", len(etags), etags)
+	}
+	if etags["/app/main.wasm"] != etags["/app/other.js"] || etags["/index.html"] == etags["/app/main.wasm"] {
+		t.Errorf("ETags should follow content: %v", etags)
+	}
+	handler := addCacheHeaders(http.FileServer(http.FS(files)), etags)
+
+	tests := []struct {
+		name        string
+		path        string
+		ifNoneMatch string
+		etag        string
+		status      int
+	}{
+		{name: "asset", path: "/app/main.wasm", etag: etags["/app/main.wasm"], status: http.StatusOK},
+		{name: "revalidated", path: "/app/main.wasm", ifNoneMatch: etags["/app/main.wasm"], etag: etags["/app/main.wasm"], status: http.StatusNotModified},
+		{name: "stale", path: "/app/main.wasm", ifNoneMatch: `"old"`, etag: etags["/app/main.wasm"], status: http.StatusOK},
+		{name: "directory index", path: "/", etag: etags["/index.html"], status: http.StatusOK},
+		{name: "unclean path", path: "/app/../app/main.wasm", etag: etags["/app/main.wasm"]},
+		{name: "missing", path: "/nope.js", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.status != 0 && rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+			wantCache := ""
+			if tt.etag != "" {
+				wantCache = "public, no-cache"
+			}
+			if got := rec.Header().Get("Cache-Control"); got != wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, wantCache)
+			}
+		})
+	}
+}
+
+func TestCacheHeadersDevMode(t *testing.T) {
+	handler := addCacheHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/main.wasm", nil))
+
+	if got := rec.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+	if got := rec.Header().Get("ETag"); got != "" {
+		t.Errorf("unexpected ETag %q in dev mode", got)
+	}
+}
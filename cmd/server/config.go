@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/mbarlow/local-first/internal/monitoring"
+	"github.com/spf13/viper"
+)
+
+// loadConfig reads the optional local.yaml shared with the CLI
+func loadConfig() {
+	viper.SetConfigName("local")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(".")
+	viper.AddConfigPath("$HOME/.config/local-first")
+
+	viper.SetDefault("server.max_body", 1<<20) // 1 MiB
+	viper.SetDefault("server.max_body_routes", map[string]int64{})
+	viper.SetDefault("server.log_memory_bytes", monitoring.DefaultMaxLogBytes)
+
+	// Extra response headers for every request, with per-route overrides by
+	// path prefix
+	viper.SetDefault("server.headers", map[string]string{})
+	viper.SetDefault("server.header_routes", map[string]map[string]string{})
+
+	// Connection timeouts. A write timeout of 0 disables it, which long-lived
+	// streaming endpoints such as SSE require since the response never ends.
+	viper.SetDefault("server.read_header_timeout", "5s")
+	viper.SetDefault("server.read_timeout", "30s")
+	viper.SetDefault("server.write_timeout", "60s")
+	viper.SetDefault("server.idle_timeout", "120s")
+
+	// Server log mirror that the dashboard tails when it attaches to a
+	// server it didn't start ("" disables it)
+	viper.SetDefault("server.log_file", filepath.Join(".local-first", "server.log"))
+
+	// Bounds on the sync hub, and where it persists documents ("" keeps
+	// them in memory only)
+	viper.SetDefault("sync.max_docs", 1000)
+	viper.SetDefault("sync.max_ops", 10000)
+	viper.SetDefault("sync.dir", filepath.Join(".local-first", "sync"))
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			log.Printf("Error reading config: %v", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// openLogFile mirrors the server's log output to path, so a dashboard that
+// didn't start the server can still follow it. An empty path disables the
+// mirror. The returned function restores stderr-only logging and closes the
+// file.
+func openLogFile(path string) func() {
+	if path == "" {
+		return func() {}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Failed to create log directory: %v", err)
+		return func() {}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Failed to open log file: %v", err)
+		return func() {}
+	}
+
+	log.SetOutput(io.MultiWriter(os.Stderr, f))
+	return func() {
+		log.SetOutput(os.Stderr)
+		f.Close()
+	}
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mbarlow/local-first/internal/monitoring"
+	"github.com/mbarlow/local-first/internal/synchub"
+	"github.com/spf13/viper"
 )
 
 func main() {
@@ -17,10 +24,15 @@ func main() {
 		port      = flag.String("port", "8080", "Port to serve on")
 		devMode   = flag.Bool("dev", false, "Run in development mode (serve from filesystem)")
 		staticDir = flag.String("static", "./web", "Static files directory (dev mode only)")
+		pprofOn   = flag.Bool("pprof", false, "Serve runtime profiles under /debug/pprof/ (exposes internals)")
 	)
 	flag.Parse()
+	loadConfig()
+	closeLog := openLogFile(viper.GetString("server.log_file"))
+	defer closeLog()
 
 	var fileServer http.Handler
+	var etags map[string]string
 
 	if *devMode {
 		// Development mode: serve from filesystem
@@ -44,39 +56,286 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to create sub filesystem: %v", err)
 		}
+		etags, err = computeETags(webFS)
+		if err != nil {
+			log.Fatalf("Failed to hash embedded files: %v", err)
+		}
 		log.Println("Production mode: serving from embedded files")
 		fileServer = http.FileServer(http.FS(webFS))
 	}
+	fileServer = addCacheHeaders(fileServer, etags)
 
 	// Add monitoring middleware
 	monitor := monitoring.NewMonitor()
+	monitor.SetMaxLogBytes(viper.GetInt("server.log_memory_bytes"))
 	
 	// Wrap the file server with CORS headers for WASM
-	corsHandler := addCORSHeaders(fileServer)
+	corsHandler := addCORSHeaders(fileServer, contentTypeOverrides())
+	
+	mux := http.NewServeMux()
+	mux.Handle("/", corsHandler)
+	mux.Handle("/api/stats", monitor.StatsHandler())
+	mux.Handle("/api/logs", monitor.LogsHandler())
+	mux.Handle("/debug/inflight", monitor.InFlightHandler())
+	if *pprofOn {
+		registerPprof(mux)
+	}
+	
+	// Relay operation logs between replicas
+	var store synchub.Store = synchub.MemoryStore{}
+	if dir := viper.GetString("sync.dir"); dir != "" {
+		fileStore, err := synchub.NewFileStore(dir)
+		if err != nil {
+			log.Fatalf("Failed to open sync store: %v", err)
+		}
+		store = fileStore
+	}
+	hub, err := synchub.NewHubWithStore(viper.GetInt("sync.max_docs"), viper.GetInt("sync.max_ops"), store)
+	if err != nil {
+		log.Fatalf("Failed to start sync hub: %v", err)
+	}
+	mux.Handle("/api/sync", hub.Handler())
+	mux.Handle("/ws/sync", hub.LiveHandler())
 	
 	// Add monitoring
-	handler := monitor.Middleware(corsHandler)
+	headers, routeHeaders := customHeaders()
+	handler := monitor.Middleware(addCustomHeaders(limitRequestBody(mux, viper.GetInt64("server.max_body"), bodyLimitOverrides()), headers, routeHeaders))
 
 	addr := fmt.Sprintf(":%s", *port)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: viper.GetDuration("server.read_header_timeout"),
+		ReadTimeout:       viper.GetDuration("server.read_timeout"),
+		WriteTimeout:      viper.GetDuration("server.write_timeout"),
+		IdleTimeout:       viper.GetDuration("server.idle_timeout"),
+	}
+
+	// Shut down gracefully on SIGINT/SIGTERM so pending log writes land on
+	// disk. ListenAndServe returns as soon as Shutdown starts, so done
+	// marks when in-flight requests have drained.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		<-sigCh
+
+		log.Println("Shutting down server...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown error: %v", err)
+		}
+	}()
+
 	log.Printf("Server starting on http://localhost%s", addr)
 	
-	if err := http.ListenAndServe(addr, handler); err != nil {
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		monitor.Flush()
+		hub.Close()
 		log.Fatalf("Server failed: %v", err)
 	}
+
+	<-done
+	monitor.Flush()
+	if err := hub.Close(); err != nil {
+		log.Printf("Failed to save sync state: %v", err)
+	}
+	log.Println("Server stopped")
+}
+
+// defaultContentTypes pins the MIME type of modern web assets that the OS
+// mime database may not know about
+var defaultContentTypes = map[string]string{
+	".wasm":        "application/wasm",
+	".mjs":         "text/javascript; charset=utf-8",
+	".js":          "text/javascript; charset=utf-8",
+	".map":         "application/json",
+	".webmanifest": "application/manifest+json",
+	".wasm.br":     "application/wasm",
+	".wasm.gz":     "application/wasm",
+}
+
+// contentTypeOverrides merges server.content_types from config over the
+// defaults. Keys are file suffixes such as ".mjs".
+func contentTypeOverrides() map[string]string {
+	types := make(map[string]string, len(defaultContentTypes))
+	for ext, ct := range defaultContentTypes {
+		types[ext] = ct
+	}
+	for ext, ct := range viper.GetStringMapString("server.content_types") {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		types[strings.ToLower(ext)] = ct
+	}
+	return types
+}
+
+// contentTypeFor returns the override for the longest matching suffix
+func contentTypeFor(path string, types map[string]string) (string, bool) {
+	path = strings.ToLower(path)
+	best, found := "", ""
+	for ext, ct := range types {
+		if strings.HasSuffix(path, ext) && len(ext) > len(best) {
+			best, found = ext, ct
+		}
+	}
+	return found, best != ""
 }
 
 // addCORSHeaders adds necessary headers for WASM execution
-func addCORSHeaders(next http.Handler) http.Handler {
+func addCORSHeaders(next http.Handler, types map[string]string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// These headers are required for SharedArrayBuffer and WASM
 		w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
 		w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
 		
-		// Set correct MIME type for WASM files
+		// Pin content types that vary across platforms
+		if ct, ok := contentTypeFor(r.URL.Path, types); ok {
+			w.Header().Set("Content-Type", ct)
+			
+			// Precompressed WASM is served as-is with its encoding
+			switch {
+			case strings.HasSuffix(r.URL.Path, ".wasm.br"):
+				w.Header().Set("Content-Encoding", "br")
+			case strings.HasSuffix(r.URL.Path, ".wasm.gz"):
+				w.Header().Set("Content-Encoding", "gzip")
+			}
+		}
+		
 		if filepath.Ext(r.URL.Path) == ".wasm" {
-			w.Header().Set("Content-Type", "application/wasm")
+			// Large WASM files may be fetched in ranges; advertise support and
+			// let cross-origin callers read the range headers
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Access-Control-Expose-Headers", "Content-Range, Content-Length, Accept-Ranges")
 		}
 		
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
+
+// limitRequestBody caps request bodies at limit bytes, or the override for the
+// longest matching route prefix. Prefixes are lowercase and matched without
+// regard to case, as viper lowercases config keys. Requests that declare a
+// larger body are rejected up front; others fail with *http.MaxBytesError
+// when the handler reads past the limit, which handlers report as 413.
+func limitRequestBody(next http.Handler, limit int64, overrides map[string]int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		max := limit
+		matched := ""
+		path := strings.ToLower(r.URL.Path)
+		for prefix, routeLimit := range overrides {
+			if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+				matched = prefix
+				max = routeLimit
+			}
+		}
+		
+		if max > 0 {
+			if r.ContentLength > max {
+				log.Printf("Rejected %s %s: body of %d bytes exceeds limit of %d", r.Method, r.URL.Path, r.ContentLength, max)
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+		}
+		
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bodyLimitOverrides reads per-route body limits from server.max_body_routes,
+// keyed by lowercase route prefix
+func bodyLimitOverrides() map[string]int64 {
+	overrides := make(map[string]int64)
+	for route := range viper.GetStringMap("server.max_body_routes") {
+		overrides[strings.ToLower(route)] = viper.GetInt64("server.max_body_routes." + route)
+	}
+	return overrides
+}
+
+// addCustomHeaders sets the configured headers on every response, then the
+// headers for the longest matching route prefix on top. Prefixes are
+// lowercase and matched without regard to case, as in limitRequestBody. A
+// route value of "" removes a global header for that route. Headers set
+// later by handlers, such as the COOP/COEP headers WASM needs, take
+// precedence.
+func addCustomHeaders(next http.Handler, headers map[string]string, routes map[string]map[string]string) http.Handler {
+	if len(headers) == 0 && len(routes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		
+		matched := ""
+		path := strings.ToLower(r.URL.Path)
+		for prefix := range routes {
+			if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+				matched = prefix
+			}
+		}
+		if matched != "" {
+			for name, value := range routes[matched] {
+				if value == "" {
+					w.Header().Del(name)
+				} else {
+					w.Header().Set(name, value)
+				}
+			}
+		}
+		
+		next.ServeHTTP(w, r)
+	})
+}
+
+// customHeaders reads server.headers and server.header_routes, logging and
+// dropping entries that are not valid HTTP headers
+func customHeaders() (map[string]string, map[string]map[string]string) {
+	headers := validHeaders("server.headers", viper.GetStringMapString("server.headers"))
+	routes := make(map[string]map[string]string)
+	for route := range viper.GetStringMap("server.header_routes") {
+		key := "server.header_routes." + route
+		if headers := validHeaders(key, viper.GetStringMapString(key)); len(headers) > 0 {
+			routes[strings.ToLower(route)] = headers
+		}
+	}
+	return headers, routes
+}
+
+// validHeaders returns the entries of headers whose names are HTTP tokens
+// and whose values contain no control characters, warning about the rest
+func validHeaders(key string, headers map[string]string) map[string]string {
+	valid := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if !isHeaderName(name) {
+			log.Printf("Warning: ignoring %s: %q is not a valid header name", key, name)
+			continue
+		}
+		if strings.ContainsFunc(value, func(r rune) bool { return r < ' ' && r != '\t' || r == 0x7f }) {
+			log.Printf("Warning: ignoring %s: value of %s contains control characters", key, name)
+			continue
+		}
+		valid[http.CanonicalHeaderKey(name)] = value
+	}
+	return valid
+}
+
+// isHeaderName reports whether name is an RFC 9110 token
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/viper"
+)
+
+func TestLimitRequestBody(t *testing.T) {
+	// The handler reports the MaxBytesError a read past the limit returns
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case err != nil:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.Write(data)
+		}
+	})
+	handler := limitRequestBody(echo, 10, map[string]int64{"/api/sync": 20})
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		chunked bool
+		want    int
+	}{
+		{name: "within limit", path: "/api/x", body: "0123456789", want: http.StatusOK},
+		{name: "declared too large", path: "/api/x", body: "0123456789a", want: http.StatusRequestEntityTooLarge},
+		{name: "chunked too large", path: "/api/x", body: "0123456789a", chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "route override", path: "/api/sync", body: strings.Repeat("x", 20), want: http.StatusOK},
+		{name: "route override chunked too large", path: "/api/sync", body: strings.Repeat("x", 21), chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "route override ignores case", path: "/API/Sync", body: strings.Repeat("x", 20), want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddCORSHeadersRange(t *testing.T) {
+	files := fstest.MapFS{"app.wasm": {Data: []byte("0123456789abcdef")}}
+	handler := addCORSHeaders(http.FileServer(http.FS(files)), contentTypeOverrides())
+
+	req := httptest.NewRequest(http.MethodGet, "/app.wasm", nil)
+	req.Header.Set("Range", "bytes=4-7")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+	if body := rec.Body.String(); body != "4567" {
+		t.Errorf("body = %q, want %q", body, "4567")
+	}
+	for header, want := range map[string]string{
+		"Content-Type":                 "application/wasm",
+		"Content-Range":                "bytes 4-7/16",
+		"Accept-Ranges":                "bytes",
+		"Cross-Origin-Embedder-Policy": "require-corp",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if exposed := rec.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "Content-Range") {
+		t.Errorf("Content-Range is not exposed: %q", exposed)
+	}
+}
+
+func TestContentTypeOverrides(t *testing.T) {
+	viper.Set("server.content_types", map[string]string{"md": "text/markdown", ".JS": "application/javascript"})
+	t.Cleanup(viper.Reset)
+	types := contentTypeOverrides()
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{path: "/main.wasm", want: "application/wasm", ok: true},
+		{path: "/MAIN.WASM", want: "application/wasm", ok: true},
+		{path: "/main.wasm.br", want: "application/wasm", ok: true},
+		{path: "/app.mjs", want: "text/javascript; charset=utf-8", ok: true},
+		{path: "/app.js", want: "application/javascript", ok: true},
+		{path: "/README.md", want: "text/markdown", ok: true},
+		{path: "/index.html"},
+		{path: "/wasm"},
+	}
+
+	for _, tt := range tests {
+		got, ok := contentTypeFor(tt.path, types)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %q %v, want %q %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAddCORSHeadersPrecompressed(t *testing.T) {
+	handler := addCORSHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultContentTypes)
+
+	tests := []struct {
+		path     string
+		encoding string
+	}{
+		{path: "/main.wasm.br", encoding: "br"},
+		{path: "/main.wasm.gz", encoding: "gzip"},
+		{path: "/main.wasm"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if ct := rec.Header().Get("Content-Type"); ct != "application/wasm" {
+			t.Errorf("%s: Content-Type = %q", tt.path, ct)
+		}
+		if enc := rec.Header().Get("Content-Encoding"); enc != tt.encoding {
+			t.Errorf("%s: Content-Encoding = %q, want %q", tt.path, enc, tt.encoding)
+		}
+	}
+}
+
+func TestAddCustomHeaders(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app.wasm" {
+			w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
+		}
+	})
+	handler := addCustomHeaders(next,
+		map[string]string{"X-Frame-Options": "DENY", "Cross-Origin-Embedder-Policy": "unsafe-none"},
+		map[string]map[string]string{
+			"/api":      {"Cache-Control": "no-store"},
+			"/api/docs": {"Cache-Control": "max-age=60", "X-Frame-Options": ""},
+		})
+
+	tests := []struct {
+		path    string
+		headers map[string]string
+	}{
+		{path: "/", headers: map[string]string{"X-Frame-Options": "DENY", "Cache-Control": ""}},
+		{path: "/api/sync", headers: map[string]string{"X-Frame-Options": "DENY", "Cache-Control": "no-store"}},
+		{path: "/API/Docs/x", headers: map[string]string{"X-Frame-Options": "", "Cache-Control": "max-age=60"}},
+		// handlers override the configured headers
+		{path: "/app.wasm", headers: map[string]string{"Cross-Origin-Embedder-Policy": "require-corp"}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		for name, want := range tt.headers {
+			if got := rec.Header().Get(name); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.path, name, got, want)
+			}
+		}
+	}
+}
+
+func TestCustomHeaders(t *testing.T) {
+	viper.Set("server.headers", map[string]string{"x-powered-by": "local-first", "bad header": "x", "X-Bad": "a\nb"})
+	viper.Set("server.header_routes", map[string]interface{}{
+		"/Docs": map[string]string{"cache-control": "no-cache"},
+		"/none": map[string]string{"bad header": "x"},
+	})
+	t.Cleanup(viper.Reset)
+
+	headers, routes := customHeaders()
+	if len(headers) != 1 || headers["X-Powered-By"] != "local-first" {
+		t.Errorf("headers = %v", headers)
+	}
+	if len(routes) != 1 || routes["/docs"]["Cache-Control"] != "no-cache" {
+		t.Errorf("routes = %v", routes)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/pprof"
+)
+
+// registerPprof serves the runtime profiles under /debug/pprof/. The
+// handlers are registered on mux explicitly rather than through the
+// package's init, which only touches http.DefaultServeMux, so they are
+// absent unless the -pprof flag asks for them.
+func registerPprof(mux *http.ServeMux) {
+	log.Println("WARNING: pprof enabled at /debug/pprof/; it exposes process internals, so do not use it on a public address")
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
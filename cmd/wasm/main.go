@@ -26,6 +26,136 @@ func main() {
 	goAPI.Set("formatJSON", js.FuncOf(apiHandler.FormatJSON))
 	goAPI.Set("generateID", js.FuncOf(apiHandler.GenerateID))
 	goAPI.Set("getVersion", js.FuncOf(apiHandler.GetVersion))
+	goAPI.Set("setHandlerTimeout", js.FuncOf(apiHandler.SetHandlerTimeout))
+	goAPI.Set("generateTOTPSecret", js.FuncOf(apiHandler.GenerateTOTPSecret))
+	goAPI.Set("generateTOTP", js.FuncOf(apiHandler.GenerateTOTP))
+	goAPI.Set("validateTOTP", js.FuncOf(apiHandler.ValidateTOTP))
+	goAPI.Set("validateForm", js.FuncOf(apiHandler.ValidateForm))
+	goAPI.Set("parseURL", js.FuncOf(apiHandler.ParseURL))
+	goAPI.Set("parseQueryString", js.FuncOf(apiHandler.ParseQueryString))
+	goAPI.Set("buildQueryString", js.FuncOf(apiHandler.BuildQueryString))
+	goAPI.Set("movingAverage", js.FuncOf(apiHandler.MovingAverage))
+	goAPI.Set("downsample", js.FuncOf(apiHandler.Downsample))
+	goAPI.Set("inferSchema", js.FuncOf(apiHandler.InferSchema))
+	goAPI.Set("escape", js.FuncOf(apiHandler.Escape))
+	goAPI.Set("unescape", js.FuncOf(apiHandler.Unescape))
+	goAPI.Set("convertBase", js.FuncOf(apiHandler.ConvertBase))
+	goAPI.Set("wrapText", js.FuncOf(apiHandler.WrapText))
+	goAPI.Set("countGraphemes", js.FuncOf(apiHandler.CountGraphemes))
+	goAPI.Set("textSimilarity", js.FuncOf(apiHandler.TextSimilarity))
+	goAPI.Set("canonicalizeJSON", js.FuncOf(apiHandler.CanonicalizeJSON))
+	goAPI.Set("statsInit", js.FuncOf(apiHandler.StatsInit))
+	goAPI.Set("statsAdd", js.FuncOf(apiHandler.StatsAdd))
+	goAPI.Set("statsResult", js.FuncOf(apiHandler.StatsResult))
+	goAPI.Set("statsRelease", js.FuncOf(apiHandler.StatsRelease))
+	goAPI.Set("redactPII", js.FuncOf(apiHandler.RedactPII))
+	goAPI.Set("generateFake", js.FuncOf(apiHandler.GenerateFake))
+	goAPI.Set("bloomInit", js.FuncOf(apiHandler.BloomInit))
+	goAPI.Set("bloomAdd", js.FuncOf(apiHandler.BloomAdd))
+	goAPI.Set("bloomCheck", js.FuncOf(apiHandler.BloomCheck))
+	goAPI.Set("bloomExport", js.FuncOf(apiHandler.BloomExport))
+	goAPI.Set("bloomImport", js.FuncOf(apiHandler.BloomImport))
+	goAPI.Set("bloomRelease", js.FuncOf(apiHandler.BloomRelease))
+	goAPI.Set("formatDuration", js.FuncOf(apiHandler.FormatDuration))
+	goAPI.Set("formatBytes", js.FuncOf(apiHandler.FormatBytes))
+	goAPI.Set("mergeJSON", js.FuncOf(apiHandler.MergeJSON))
+	goAPI.Set("applyJSONPatch", js.FuncOf(apiHandler.ApplyJSONPatch))
+	goAPI.Set("threeWayMerge", js.FuncOf(apiHandler.ThreeWayMerge))
+	goAPI.Set("opLogAppend", js.FuncOf(apiHandler.OpLogAppend))
+	goAPI.Set("opLogSince", js.FuncOf(apiHandler.OpLogSince))
+	goAPI.Set("opLogMerge", js.FuncOf(apiHandler.OpLogMerge))
+	goAPI.Set("lwwInit", js.FuncOf(apiHandler.LWWInit))
+	goAPI.Set("lwwSet", js.FuncOf(apiHandler.LWWSet))
+	goAPI.Set("lwwGet", js.FuncOf(apiHandler.LWWGet))
+	goAPI.Set("lwwMerge", js.FuncOf(apiHandler.LWWMerge))
+	goAPI.Set("lwwRelease", js.FuncOf(apiHandler.LWWRelease))
+	goAPI.Set("counterInit", js.FuncOf(apiHandler.CounterInit))
+	goAPI.Set("counterIncrement", js.FuncOf(apiHandler.CounterIncrement))
+	goAPI.Set("counterDecrement", js.FuncOf(apiHandler.CounterDecrement))
+	goAPI.Set("counterValue", js.FuncOf(apiHandler.CounterValue))
+	goAPI.Set("counterMerge", js.FuncOf(apiHandler.CounterMerge))
+	goAPI.Set("counterRelease", js.FuncOf(apiHandler.CounterRelease))
+	goAPI.Set("orsetInit", js.FuncOf(apiHandler.ORSetInit))
+	goAPI.Set("orsetAdd", js.FuncOf(apiHandler.ORSetAdd))
+	goAPI.Set("orsetRemove", js.FuncOf(apiHandler.ORSetRemove))
+	goAPI.Set("orsetValues", js.FuncOf(apiHandler.ORSetValues))
+	goAPI.Set("orsetMerge", js.FuncOf(apiHandler.ORSetMerge))
+	goAPI.Set("orsetRelease", js.FuncOf(apiHandler.ORSetRelease))
+	goAPI.Set("opLogDelta", js.FuncOf(apiHandler.OpLogDelta))
+	goAPI.Set("opLogApplyDelta", js.FuncOf(apiHandler.OpLogApplyDelta))
+	goAPI.Set("presenceConnect", js.FuncOf(apiHandler.PresenceConnect))
+	goAPI.Set("presencePublish", js.FuncOf(apiHandler.PresencePublish))
+	goAPI.Set("presenceDisconnect", js.FuncOf(apiHandler.PresenceDisconnect))
+	goAPI.Set("exportState", js.FuncOf(apiHandler.ExportState))
+	goAPI.Set("importState", js.FuncOf(apiHandler.ImportState))
+	goAPI.Set("registerMigration", js.FuncOf(apiHandler.RegisterMigration))
+	goAPI.Set("migrate", js.FuncOf(apiHandler.Migrate))
+	goAPI.Set("computeTextPatch", js.FuncOf(apiHandler.ComputeTextPatch))
+	goAPI.Set("applyTextPatch", js.FuncOf(apiHandler.ApplyTextPatch))
+	goAPI.Set("validateJSONSafe", js.FuncOf(apiHandler.ValidateJSONSafe))
+	goAPI.Set("chunkContent", js.FuncOf(apiHandler.ChunkContent))
+	goAPI.Set("rngSeed", js.FuncOf(apiHandler.RNGSeed))
+	goAPI.Set("rngFloat", js.FuncOf(apiHandler.RNGFloat))
+	goAPI.Set("rngInt", js.FuncOf(apiHandler.RNGInt))
+	goAPI.Set("rngShuffle", js.FuncOf(apiHandler.RNGShuffle))
+	goAPI.Set("rngRelease", js.FuncOf(apiHandler.RNGRelease))
+	goAPI.Set("parseCron", js.FuncOf(apiHandler.ParseCron))
+	goAPI.Set("geoDistance", js.FuncOf(apiHandler.GeoDistance))
+	goAPI.Set("geoBoundingBox", js.FuncOf(apiHandler.GeoBoundingBox))
+	goAPI.Set("pointInPolygon", js.FuncOf(apiHandler.PointInPolygon))
+	goAPI.Set("geohashEncode", js.FuncOf(apiHandler.GeohashEncode))
+	goAPI.Set("geohashDecode", js.FuncOf(apiHandler.GeohashDecode))
+	goAPI.Set("geohashNeighbors", js.FuncOf(apiHandler.GeohashNeighbors))
+	goAPI.Set("validateBarcode", js.FuncOf(apiHandler.ValidateBarcode))
+	goAPI.Set("convertUnits", js.FuncOf(apiHandler.ConvertUnits))
+	goAPI.Set("evaluateFormula", js.FuncOf(apiHandler.EvaluateFormula))
+	goAPI.Set("formatSQL", js.FuncOf(apiHandler.FormatSQL))
+	goAPI.Set("analyzeSQL", js.FuncOf(apiHandler.AnalyzeSQL))
+	goAPI.Set("crc32", js.FuncOf(apiHandler.CRC32))
+	goAPI.Set("crc16", js.FuncOf(apiHandler.CRC16))
+	goAPI.Set("parseMultipart", js.FuncOf(apiHandler.ParseMultipart))
+	goAPI.Set("parseFormURLEncoded", js.FuncOf(apiHandler.ParseFormURLEncoded))
+	goAPI.Set("imageHistogram", js.FuncOf(apiHandler.ImageHistogram))
+	goAPI.Set("imageCompare", js.FuncOf(apiHandler.ImageCompare))
+	goAPI.Set("resizeImage", js.FuncOf(apiHandler.ResizeImage))
+	goAPI.Set("extractEXIF", js.FuncOf(apiHandler.ExtractEXIF))
+	goAPI.Set("filterJSONArray", js.FuncOf(apiHandler.FilterJSONArray))
+	goAPI.Set("parseCookies", js.FuncOf(apiHandler.ParseCookies))
+	goAPI.Set("buildCookie", js.FuncOf(apiHandler.BuildCookie))
+	goAPI.Set("parseSemVer", js.FuncOf(apiHandler.ParseSemVer))
+	goAPI.Set("compareSemVer", js.FuncOf(apiHandler.CompareSemVer))
+	goAPI.Set("fetch", js.FuncOf(apiHandler.Fetch))
+	goAPI.Set("enqueueRequest", js.FuncOf(apiHandler.EnqueueRequest))
+	goAPI.Set("flushOutbox", js.FuncOf(apiHandler.FlushOutbox))
+	goAPI.Set("outboxStatus", js.FuncOf(apiHandler.OutboxStatus))
+	goAPI.Set("merkleRoot", js.FuncOf(apiHandler.MerkleRoot))
+	goAPI.Set("merkleProof", js.FuncOf(apiHandler.MerkleProof))
+	goAPI.Set("merkleVerify", js.FuncOf(apiHandler.MerkleVerify))
+	goAPI.Set("setSketch", js.FuncOf(apiHandler.SetSketch))
+	goAPI.Set("reconcileSets", js.FuncOf(apiHandler.ReconcileSets))
+	goAPI.Set("highlightCode", js.FuncOf(apiHandler.HighlightCode))
+	goAPI.Set("validateIBAN", js.FuncOf(apiHandler.ValidateIBAN))
+	goAPI.Set("validateBIC", js.FuncOf(apiHandler.ValidateBIC))
+	goAPI.Set("startJob", js.FuncOf(apiHandler.StartJob))
+	goAPI.Set("jobStatus", js.FuncOf(apiHandler.JobStatus))
+	goAPI.Set("jobResult", js.FuncOf(apiHandler.JobResult))
+	goAPI.Set("cancelJob", js.FuncOf(apiHandler.CancelJob))
+	goAPI.Set("textAnalyzeInit", js.FuncOf(apiHandler.TextAnalyzeInit))
+	goAPI.Set("textAnalyzeFeed", js.FuncOf(apiHandler.TextAnalyzeFeed))
+	goAPI.Set("textAnalyzeResult", js.FuncOf(apiHandler.TextAnalyzeResult))
+	goAPI.Set("textAnalyzeRelease", js.FuncOf(apiHandler.TextAnalyzeRelease))
+	goAPI.Set("setHandleLimits", js.FuncOf(apiHandler.SetHandleLimits))
+	goAPI.Set("stringToColor", js.FuncOf(apiHandler.StringToColor))
+	goAPI.Set("extractOutline", js.FuncOf(apiHandler.ExtractOutline))
+	goAPI.Set("stripHTML", js.FuncOf(apiHandler.StripHTML))
+	goAPI.Set("snippet", js.FuncOf(apiHandler.Snippet))
+	goAPI.Set("evaluateRules", js.FuncOf(apiHandler.EvaluateRules))
+	goAPI.Set("groupedStats", js.FuncOf(apiHandler.GroupedStats))
+	goAPI.Set("pivot", js.FuncOf(apiHandler.Pivot))
+	goAPI.Set("sniffTabular", js.FuncOf(apiHandler.SniffTabular))
+	goAPI.Set("valueCounts", js.FuncOf(apiHandler.ValueCounts))
+	goAPI.Set("setResponseMode", js.FuncOf(apiHandler.SetResponseMode))
+	goAPI.Set("normalizeEmail", js.FuncOf(apiHandler.NormalizeEmail))
 	
 	// Add a simple test function
 	goAPI.Set("test", js.FuncOf(func(this js.Value, inputs []js.Value) interface{} {
@@ -41,21 +171,29 @@ func main() {
 	js.Global().Set("goAPI", goAPI)
 
 	// Register cleanup callback
-	js.Global().Set("goAPICleanup", js.FuncOf(cleanup))
+	js.Global().Set("goAPICleanup", js.FuncOf(cleanup(apiHandler)))
 
 	fmt.Println("Go API functions registered globally as 'goAPI'")
-	fmt.Println("Available functions: processData, validateInput, calculateStats, formatJSON, generateID, getVersion")
+	fmt.Println("Available functions:")
+	names := js.Global().Get("Object").Call("keys", goAPI)
+	for i := 0; i < names.Length(); i++ {
+		fmt.Println("  " + names.Index(i).String())
+	}
 
 	// Keep the Go program alive
 	<-make(chan bool)
 }
 
-// cleanup releases Go resources when called from JavaScript
-func cleanup(this js.Value, inputs []js.Value) interface{} {
-	fmt.Println("Cleaning up Go WASM resources...")
-	return map[string]interface{}{
-		"success": true,
-		"message": "Cleanup complete",
+// cleanup returns a callback that releases Go resources when called from
+// JavaScript
+func cleanup(apiHandler *api.Handler) func(this js.Value, inputs []js.Value) interface{} {
+	return func(this js.Value, inputs []js.Value) interface{} {
+		fmt.Println("Cleaning up Go WASM resources...")
+		apiHandler.Cleanup()
+		return map[string]interface{}{
+			"success": true,
+			"message": "Cleanup complete",
+		}
 	}
 }
 
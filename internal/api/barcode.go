@@ -0,0 +1,31 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// ValidateBarcode validates an ISBN-10, ISBN-13, EAN-13 or UPC-A code. The
+// optional options object takes type (isbn10, isbn13, ean13 or upca;
+// detected from the length by default) and partial, which treats the input
+// as missing its check digit and returns the completed code.
+func (h *Handler) ValidateBarcode(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Barcode string required")
+	}
+
+	kind := ""
+	partial := false
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		if v := inputs[1].Get("type"); v.Type() == js.TypeString {
+			kind = v.String()
+		}
+		partial = inputs[1].Get("partial").Truthy()
+	}
+
+	result, err := h.processor.ValidateBarcode(inputs[0].String(), kind, partial)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Barcode checked")
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// ConvertBase converts an integer string between bases 2-36
+func (h *Handler) ConvertBase(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 {
+		return h.errorResponse("Requires number, source base and target base")
+	}
+
+	from, err := jsInt(inputs, 1, "Source base")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	to, err := jsInt(inputs, 2, "Target base")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.ConvertBase(inputs[0].String(), from, to)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, fmt.Sprintf("Converted to base %d", result["toBase"]))
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// storeBloom registers a filter and returns its handle
+func (h *Handler) storeBloom(bf *core.BloomFilter) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.handles.add(bf)
+}
+
+// BloomInit creates a Bloom filter sized for the expected number of items and
+// target false positive rate, and returns its handle
+func (h *Handler) BloomInit(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires expected items and false positive rate")
+	}
+
+	expected, err := jsInt(inputs, 0, "Expected items")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	rate, err := jsFloat(inputs, 1, "False positive rate")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	bf, err := core.NewBloomFilter(expected, rate)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	handle, err := h.storeBloom(bf)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	result := bf.Info()
+	result["handle"] = handle
+
+	return h.successResponse(result, "Bloom filter created")
+}
+
+// BloomAdd adds a string, or an array of strings, to a filter
+func (h *Handler) BloomAdd(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and an item")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	var items []string
+	switch v := fromJSValue(inputs[1]).(type) {
+	case string:
+		items = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return h.errorResponse("Items must be strings")
+			}
+			items = append(items, s)
+		}
+	default:
+		return h.errorResponse("Item must be a string or an array of strings")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bf, ok := lookupHandle[*core.BloomFilter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown Bloom filter handle: %d", handle))
+	}
+	for _, item := range items {
+		bf.Add(item)
+	}
+
+	return h.successResponse(bf.Info(), fmt.Sprintf("Added %d items", len(items)))
+}
+
+// BloomCheck reports whether an item is probably in a filter
+func (h *Handler) BloomCheck(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and an item")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bf, ok := lookupHandle[*core.BloomFilter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown Bloom filter handle: %d", handle))
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"probablyContains": bf.Test(inputs[1].String()),
+	}, "Membership checked")
+}
+
+// BloomExport serializes a filter to base64
+func (h *Handler) BloomExport(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bf, ok := lookupHandle[*core.BloomFilter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown Bloom filter handle: %d", handle))
+	}
+
+	result := bf.Info()
+	result["data"] = bf.Encode()
+
+	return h.successResponse(result, "Bloom filter exported")
+}
+
+// BloomImport restores a filter from base64 and returns a new handle
+func (h *Handler) BloomImport(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No filter data provided")
+	}
+
+	bf, err := core.DecodeBloomFilter(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	handle, err := h.storeBloom(bf)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	result := bf.Info()
+	result["handle"] = handle
+
+	return h.successResponse(result, "Bloom filter imported")
+}
+
+// BloomRelease frees a filter
+func (h *Handler) BloomRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	_, ok := releaseHandle[*core.BloomFilter](h, handle)
+	h.mu.Unlock()
+
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown Bloom filter handle: %d", handle))
+	}
+
+	return h.successResponse(nil, "Bloom filter released")
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// ChunkContent splits a string, Uint8Array or ArrayBuffer into
+// content-defined chunks. The optional second argument is the average chunk
+// size in bytes.
+func (h *Handler) ChunkContent(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No content provided")
+	}
+
+	data, err := jsBytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	avgSize := core.DefaultChunkSize
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeNumber {
+		avgSize = inputs[1].Int()
+	}
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ChunkContent(ctx, data, avgSize)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Content chunked")
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// StringToColor returns a consistent color for a string, such as a user
+// name or tag, as hex and HSL with a contrasting black or white text
+// color. Options may set saturation and lightness in percent (defaults 65
+// and 50).
+func (h *Handler) StringToColor(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("String required")
+	}
+
+	saturation, lightness := float64(core.DefaultColorSaturation), float64(core.DefaultColorLightness)
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		if v := inputs[1].Get("saturation"); v.Type() == js.TypeNumber {
+			saturation = v.Float()
+		}
+		if v := inputs[1].Get("lightness"); v.Type() == js.TypeNumber {
+			lightness = v.Float()
+		}
+	}
+
+	result, err := h.processor.StringToColor(inputs[0].String(), saturation, lightness)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Color generated")
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// ParseCookies parses a Cookie header into name/value pairs. The optional
+// options object takes decode, which percent-decodes values.
+func (h *Handler) ParseCookies(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Cookie header string required")
+	}
+
+	decode := false
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		decode = inputs[1].Get("decode").Truthy()
+	}
+
+	result, err := h.processor.ParseCookies(inputs[0].String(), decode)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Cookies parsed")
+}
+
+// BuildCookie builds a Set-Cookie header from an attributes object with
+// name, value, path, domain, expires (an ISO 8601 string, epoch
+// milliseconds or a Date), maxAge in seconds, secure, httpOnly, sameSite
+// (Strict, Lax or None) and partitioned
+func (h *Handler) BuildCookie(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeObject {
+		return h.errorResponse("Cookie attributes object required")
+	}
+
+	attrs := inputs[0]
+	str := func(key string) string {
+		if v := attrs.Get(key); v.Type() == js.TypeString {
+			return v.String()
+		}
+		return ""
+	}
+	opts := core.CookieOptions{
+		Name:        str("name"),
+		Value:       str("value"),
+		Path:        str("path"),
+		Domain:      str("domain"),
+		SameSite:    str("sameSite"),
+		Secure:      attrs.Get("secure").Truthy(),
+		HTTPOnly:    attrs.Get("httpOnly").Truthy(),
+		Partitioned: attrs.Get("partitioned").Truthy(),
+	}
+
+	switch v := attrs.Get("expires"); v.Type() {
+	case js.TypeString:
+		t, err := time.Parse(time.RFC3339, v.String())
+		if err != nil {
+			return h.errorResponse("expires must be an ISO 8601 date-time such as 2024-01-31T09:00:00Z")
+		}
+		opts.Expires = t
+	case js.TypeNumber:
+		opts.Expires = time.UnixMilli(int64(v.Float()))
+	case js.TypeObject:
+		if v.Get("getTime").Type() != js.TypeFunction {
+			return h.errorResponse("expires must be a date string, epoch milliseconds or a Date")
+		}
+		opts.Expires = time.UnixMilli(int64(v.Call("getTime").Float()))
+	}
+	if v := attrs.Get("maxAge"); v.Type() == js.TypeNumber {
+		maxAge := v.Int()
+		opts.MaxAge = &maxAge
+	}
+
+	result, err := h.processor.BuildCookie(opts)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Cookie built")
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall/js"
+)
+
+// checksumOptions reads the algorithm option and the expected checksum,
+// given as a number or a hex string, from an options object
+func checksumOptions(inputs []js.Value, key string) (string, *uint32, error) {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeObject {
+		return "", nil, nil
+	}
+	options := inputs[1]
+
+	name := ""
+	if v := options.Get(key); v.Type() == js.TypeString {
+		name = v.String()
+	}
+
+	var expected *uint32
+	switch v := options.Get("expected"); v.Type() {
+	case js.TypeNumber:
+		n := uint32(v.Float())
+		expected = &n
+	case js.TypeString:
+		s := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v.String())), "0x")
+		n, err := strconv.ParseUint(s, 16, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("expected must be a number or hex string")
+		}
+		e := uint32(n)
+		expected = &e
+	}
+	return name, expected, nil
+}
+
+// CRC32 computes the CRC-32 of base64 (or Uint8Array/ArrayBuffer) input.
+// The optional options object takes polynomial (ieee, castagnoli or
+// koopman) and expected, which adds a valid flag to the result.
+func (h *Handler) CRC32(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No data provided")
+	}
+
+	data, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	polynomial, expected, err := checksumOptions(inputs, "polynomial")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.CRC32(data, polynomial, expected)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "CRC-32 computed")
+}
+
+// CRC16 computes the CRC-16 of base64 (or Uint8Array/ArrayBuffer) input.
+// The optional options object takes variant (ccitt-false, xmodem, kermit,
+// arc or modbus) and expected, which adds a valid flag to the result.
+func (h *Handler) CRC16(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No data provided")
+	}
+
+	data, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	variant, expected, err := checksumOptions(inputs, "variant")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.CRC16(data, variant, expected)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "CRC-16 computed")
+}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// LWWInit creates a last-write-wins register and returns its handle. The
+// optional argument is a saved state to restore.
+func (h *Handler) LWWInit(this js.Value, inputs []js.Value) interface{} {
+	state, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	reg, err := core.ParseLWWRegister(state)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	handle, err := h.handles.add(reg)
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"handle": handle,
+	}, "LWW register created")
+}
+
+// LWWSet writes a value with a timestamp (e.g. Date.now()) and replica ID.
+// Writes older than the current value are ignored.
+func (h *Handler) LWWSet(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 4 {
+		return h.errorResponse("Requires a handle, value, timestamp and replica ID")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	timestamp, err := jsFloat(inputs, 2, "Timestamp")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	reg, ok := lookupHandle[*core.LWWRegister](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown register handle: %d", handle))
+	}
+	applied := reg.Set(fromJSValue(inputs[1]), int64(timestamp), inputs[3].String())
+
+	result := reg.State()
+	result["applied"] = applied
+	return h.successResponse(result, "Register updated")
+}
+
+// LWWGet returns the register's value along with its timestamp and replica,
+// which together form the state to persist or transmit
+func (h *Handler) LWWGet(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	reg, ok := lookupHandle[*core.LWWRegister](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown register handle: %d", handle))
+	}
+
+	return h.successResponse(reg.State(), "Register retrieved")
+}
+
+// LWWMerge merges another register into the one at handle a. The second
+// argument is either another handle or a state from lwwGet.
+func (h *Handler) LWWMerge(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and another register")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	reg, ok := lookupHandle[*core.LWWRegister](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown register handle: %d", handle))
+	}
+
+	var other *core.LWWRegister
+	if inputs[1].Type() == js.TypeNumber {
+		if other, ok = lookupHandle[*core.LWWRegister](h, inputs[1].Int()); !ok {
+			return h.errorResponse(fmt.Sprintf("Unknown register handle: %d", inputs[1].Int()))
+		}
+	} else {
+		state, err := jsonArg(inputs[1])
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		if other, err = core.ParseLWWRegister(state); err != nil {
+			return h.errorResponse(err.Error())
+		}
+	}
+
+	changed := reg.Merge(other)
+
+	result := reg.State()
+	result["changed"] = changed
+	return h.successResponse(result, "Registers merged")
+}
+
+// LWWRelease frees a register
+func (h *Handler) LWWRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	releaseHandle[*core.LWWRegister](h, handle)
+	h.mu.Unlock()
+
+	return h.successResponse(nil, "LWW register released")
+}
+
+// CounterInit creates a counter CRDT and returns its handle. The argument is
+// either a kind ("g" for grow-only, "pn" for increment/decrement) or a saved
+// state from counterValue.
+func (h *Handler) CounterInit(this js.Value, inputs []js.Value) interface{} {
+	var counter *core.Counter
+	var err error
+	switch {
+	case len(inputs) == 0 || inputs[0].IsUndefined() || inputs[0].IsNull():
+		counter, err = core.NewCounter("pn")
+	case inputs[0].Type() == js.TypeString && !strings.HasPrefix(strings.TrimSpace(inputs[0].String()), "{"):
+		counter, err = core.NewCounter(inputs[0].String())
+	default:
+		var state string
+		if state, err = jsonArg(inputs[0]); err == nil {
+			counter, err = core.ParseCounter(state)
+		}
+	}
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	handle, err := h.handles.add(counter)
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result := counter.State()
+	result["handle"] = handle
+	return h.successResponse(result, "Counter created")
+}
+
+// CounterIncrement adds to the count of a replica. The optional amount
+// defaults to 1; negative amounts require a PN-Counter.
+func (h *Handler) CounterIncrement(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and a replica ID")
+	}
+	return h.adjustCounter(inputs, 1)
+}
+
+// CounterDecrement subtracts from a PN-Counter. The optional amount defaults
+// to 1.
+func (h *Handler) CounterDecrement(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and a replica ID")
+	}
+	return h.adjustCounter(inputs, -1)
+}
+
+func (h *Handler) adjustCounter(inputs []js.Value, sign int64) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	amount := int64(1)
+	if len(inputs) > 2 && !inputs[2].IsUndefined() && !inputs[2].IsNull() {
+		f, err := jsFloat(inputs, 2, "Amount")
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		amount = int64(f)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counter, ok := lookupHandle[*core.Counter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown counter handle: %d", handle))
+	}
+	if err := counter.Increment(inputs[1].String(), sign*amount); err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(counter.State(), "Counter updated")
+}
+
+// CounterValue returns the counter's total and its serializable state
+func (h *Handler) CounterValue(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counter, ok := lookupHandle[*core.Counter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown counter handle: %d", handle))
+	}
+
+	return h.successResponse(counter.State(), "Counter retrieved")
+}
+
+// CounterMerge merges another counter into the one at handle a. The second
+// argument is either another handle or a state from counterValue.
+func (h *Handler) CounterMerge(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and another counter")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counter, ok := lookupHandle[*core.Counter](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown counter handle: %d", handle))
+	}
+
+	var other *core.Counter
+	if inputs[1].Type() == js.TypeNumber {
+		if other, ok = lookupHandle[*core.Counter](h, inputs[1].Int()); !ok {
+			return h.errorResponse(fmt.Sprintf("Unknown counter handle: %d", inputs[1].Int()))
+		}
+	} else {
+		state, err := jsonArg(inputs[1])
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		if other, err = core.ParseCounter(state); err != nil {
+			return h.errorResponse(err.Error())
+		}
+	}
+
+	if err := counter.Merge(other); err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(counter.State(), "Counters merged")
+}
+
+// CounterRelease frees a counter
+func (h *Handler) CounterRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	releaseHandle[*core.Counter](h, handle)
+	h.mu.Unlock()
+
+	return h.successResponse(nil, "Counter released")
+}
+
+// orsetResponse returns the set's values and state
+func (h *Handler) orsetResponse(set *core.ORSet, extra map[string]interface{}, message string) interface{} {
+	result, err := set.State()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	for key, value := range extra {
+		result[key] = value
+	}
+	return h.successResponse(result, message)
+}
+
+// ORSetInit creates an observed-remove set and returns its handle. The
+// optional argument is a saved state to restore.
+func (h *Handler) ORSetInit(this js.Value, inputs []js.Value) interface{} {
+	state, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	set, err := core.ParseORSet(state)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	handle, err := h.handles.add(set)
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.orsetResponse(set, map[string]interface{}{"handle": handle}, "OR-Set created")
+}
+
+// ORSetAdd adds an element on behalf of a replica
+func (h *Handler) ORSetAdd(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 {
+		return h.errorResponse("Requires a handle, element and replica ID")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	set, ok := lookupHandle[*core.ORSet](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown OR-Set handle: %d", handle))
+	}
+	tag := set.Add(inputs[1].String(), inputs[2].String())
+
+	return h.orsetResponse(set, map[string]interface{}{"tag": tag}, "Element added")
+}
+
+// ORSetRemove removes every observed add of an element
+func (h *Handler) ORSetRemove(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and an element")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	set, ok := lookupHandle[*core.ORSet](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown OR-Set handle: %d", handle))
+	}
+	removed := set.Remove(inputs[1].String())
+
+	return h.orsetResponse(set, map[string]interface{}{"removed": removed}, "Element removed")
+}
+
+// ORSetValues returns the set's elements and serializable state
+func (h *Handler) ORSetValues(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	set, ok := lookupHandle[*core.ORSet](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown OR-Set handle: %d", handle))
+	}
+
+	return h.orsetResponse(set, nil, "OR-Set retrieved")
+}
+
+// ORSetMerge merges another set into the one at handle a. The second
+// argument is either another handle or a state from orsetValues.
+func (h *Handler) ORSetMerge(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and another set")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	set, ok := lookupHandle[*core.ORSet](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown OR-Set handle: %d", handle))
+	}
+
+	var other *core.ORSet
+	if inputs[1].Type() == js.TypeNumber {
+		if other, ok = lookupHandle[*core.ORSet](h, inputs[1].Int()); !ok {
+			return h.errorResponse(fmt.Sprintf("Unknown OR-Set handle: %d", inputs[1].Int()))
+		}
+	} else {
+		state, err := jsonArg(inputs[1])
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		if other, err = core.ParseORSet(state); err != nil {
+			return h.errorResponse(err.Error())
+		}
+	}
+
+	set.Merge(other)
+
+	return h.orsetResponse(set, nil, "Sets merged")
+}
+
+// ORSetRelease frees a set
+func (h *Handler) ORSetRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	releaseHandle[*core.ORSet](h, handle)
+	h.mu.Unlock()
+
+	return h.successResponse(nil, "OR-Set released")
+}
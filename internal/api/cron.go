@@ -0,0 +1,42 @@
+package api
+
+import (
+	"syscall/js"
+	"time"
+)
+
+// ParseCron validates a cron expression and returns a description and the
+// next fire times. The optional options object takes from (an ISO 8601
+// string, whose offset the schedule is evaluated in, or epoch milliseconds
+// for UTC; defaults to now) and count (defaults to 5).
+func (h *Handler) ParseCron(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Cron expression required")
+	}
+
+	from := time.Now().UTC()
+	count := 5
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		options := inputs[1]
+		switch v := options.Get("from"); v.Type() {
+		case js.TypeString:
+			t, err := time.Parse(time.RFC3339, v.String())
+			if err != nil {
+				return h.errorResponse("from must be an ISO 8601 date-time such as 2024-01-31T09:00:00Z")
+			}
+			from = t
+		case js.TypeNumber:
+			from = time.UnixMilli(int64(v.Float())).UTC()
+		}
+		if v := options.Get("count"); v.Type() == js.TypeNumber {
+			count = v.Int()
+		}
+	}
+
+	result, err := h.processor.ParseCron(inputs[0].String(), from, count)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Cron expression parsed")
+}
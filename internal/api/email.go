@@ -0,0 +1,28 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// NormalizeEmail parses an email address, reporting whether it is valid
+// and why not, its normalized form with a lowercased domain, any +tag and
+// signals of whether the domain looks able to receive mail. The optional
+// options object takes canonicalize, which adds a canonical form for
+// deduplication with the tag removed and, for Gmail, dots removed.
+func (h *Handler) NormalizeEmail(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Email address required")
+	}
+
+	canonicalize := false
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		canonicalize = inputs[1].Get("canonicalize").Truthy()
+	}
+
+	result, err := h.processor.NormalizeEmail(inputs[0].String(), canonicalize)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Email address checked")
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// Escape encodes a string for an html, attribute, url, json or shell context
+func (h *Handler) Escape(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires input and context")
+	}
+
+	input, context := inputs[0].String(), inputs[1].String()
+	escaped, err := h.processor.Escape(input, context)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"result":  escaped,
+		"context": context,
+	}, "Input escaped")
+}
+
+// Unescape decodes a string escaped for a reversible context
+func (h *Handler) Unescape(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires input and context")
+	}
+
+	input, context := inputs[0].String(), inputs[1].String()
+	unescaped, err := h.processor.Unescape(input, context)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"result":  unescaped,
+		"context": context,
+	}, "Input unescaped")
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// GenerateFake produces placeholder data for prototyping. The optional second
+// argument is an object with count, seed and, for the "objects" kind, a spec
+// mapping field names to types.
+func (h *Handler) GenerateFake(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No kind provided")
+	}
+
+	var opts core.FakeOptions
+	if len(inputs) > 1 {
+		if options, ok := fromJSValue(inputs[1]).(map[string]interface{}); ok {
+			if count, ok := options["count"].(float64); ok {
+				opts.Count = int(count)
+			}
+			if seed, ok := options["seed"].(float64); ok {
+				opts.Seed = int64(seed)
+			}
+			if spec, ok := options["spec"].(map[string]interface{}); ok {
+				opts.Spec = make(map[string]string, len(spec))
+				for field, fieldType := range spec {
+					opts.Spec[field], _ = fieldType.(string)
+				}
+			}
+		}
+	}
+
+	result, err := h.processor.GenerateFake(inputs[0].String(), opts)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Fake data generated")
+}
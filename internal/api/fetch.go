@@ -0,0 +1,300 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// fetchRequest is a parsed goAPI.fetch options object
+type fetchRequest struct {
+	url          string
+	method       string
+	headers      map[string]string
+	body         js.Value
+	timeout      time.Duration
+	responseType string
+	policy       core.RetryPolicy
+}
+
+// errFetchTimeout reports an attempt aborted by its timeout
+var errFetchTimeout = errors.New("request timed out")
+
+// awaitPromise blocks the calling goroutine until p settles. It must not be
+// called from a js.FuncOf callback: the promise can only settle once the
+// callback has returned control to the event loop.
+func awaitPromise(p js.Value) (js.Value, error) {
+	done := make(chan struct{})
+	var result js.Value
+	var err error
+
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result = args[0]
+		close(done)
+		return nil
+	})
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		reason := args[0]
+		switch {
+		case reason.Type() == js.TypeObject && reason.Get("name").String() == "AbortError":
+			err = errFetchTimeout
+		case reason.Type() == js.TypeObject && reason.Get("message").Type() == js.TypeString:
+			err = errors.New(reason.Get("message").String())
+		default:
+			err = errors.New(reason.String())
+		}
+		close(done)
+		return nil
+	})
+	defer onResolve.Release()
+	defer onReject.Release()
+
+	p.Call("then", onResolve, onReject)
+	<-done
+	return result, err
+}
+
+// parseFetchOptions reads the options object passed to goAPI.fetch
+func parseFetchOptions(options js.Value) (*fetchRequest, error) {
+	req := &fetchRequest{
+		method:       "GET",
+		headers:      map[string]string{},
+		body:         js.Undefined(),
+		timeout:      10 * time.Second,
+		responseType: "auto",
+		policy:       core.DefaultRetryPolicy(),
+	}
+	if options.Type() == js.TypeString {
+		req.url = options.String()
+		return req, nil
+	}
+	if options.Type() != js.TypeObject || options.Get("url").Type() != js.TypeString {
+		return nil, fmt.Errorf("options with a url required")
+	}
+
+	req.url = options.Get("url").String()
+	if v := options.Get("method"); v.Type() == js.TypeString {
+		req.method = strings.ToUpper(v.String())
+	}
+	if v := options.Get("headers"); v.Type() == js.TypeObject {
+		for name, value := range jsonStringMap(fromJSValue(v)) {
+			req.headers[name] = value
+		}
+	}
+	switch v := options.Get("body"); v.Type() {
+	case js.TypeUndefined, js.TypeNull:
+	case js.TypeString:
+		req.body = v
+	default:
+		if v.InstanceOf(js.Global().Get("Uint8Array")) || v.InstanceOf(js.Global().Get("ArrayBuffer")) {
+			req.body = v
+			break
+		}
+		// plain objects and arrays are sent as JSON
+		req.body = js.Global().Get("JSON").Call("stringify", v)
+		if !hasHeader(req.headers, "Content-Type") {
+			req.headers["Content-Type"] = "application/json"
+		}
+	}
+	if v := options.Get("timeoutMs"); v.Type() == js.TypeNumber {
+		req.timeout = time.Duration(v.Int()) * time.Millisecond
+	}
+	if v := options.Get("retries"); v.Type() == js.TypeNumber {
+		req.policy.Retries = max(v.Int(), 0)
+	}
+	if v := options.Get("retryDelayMs"); v.Type() == js.TypeNumber {
+		req.policy.BaseDelay = time.Duration(v.Int()) * time.Millisecond
+	}
+	if v := options.Get("maxRetryDelayMs"); v.Type() == js.TypeNumber {
+		req.policy.MaxDelay = time.Duration(v.Int()) * time.Millisecond
+	}
+	req.policy.Unsafe = options.Get("retryUnsafe").Truthy()
+	if v := options.Get("responseType"); v.Type() == js.TypeString {
+		req.responseType = v.String()
+	}
+	switch req.responseType {
+	case "auto", "json", "text":
+	default:
+		return nil, fmt.Errorf("unknown responseType %q (expected auto, json or text)", req.responseType)
+	}
+	return req, nil
+}
+
+// jsonStringMap keeps the string values of a decoded object
+func jsonStringMap(v interface{}) map[string]string {
+	out := map[string]string{}
+	if m, ok := v.(map[string]interface{}); ok {
+		for k, val := range m {
+			out[k] = fmt.Sprint(val)
+		}
+	}
+	return out
+}
+
+// hasHeader reports whether headers sets name, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchOnce makes a single attempt, aborting it after req.timeout
+func fetchOnce(req *fetchRequest) (js.Value, error) {
+	init := js.Global().Get("Object").New()
+	init.Set("method", req.method)
+	headers := js.Global().Get("Object").New()
+	for name, value := range req.headers {
+		headers.Set(name, value)
+	}
+	init.Set("headers", headers)
+	if !req.body.IsUndefined() {
+		init.Set("body", req.body)
+	}
+
+	if req.timeout > 0 {
+		controller := js.Global().Get("AbortController").New()
+		init.Set("signal", controller.Get("signal"))
+		timer := time.AfterFunc(req.timeout, func() {
+			controller.Call("abort")
+		})
+		defer timer.Stop()
+	}
+
+	resp, err := awaitPromise(js.Global().Call("fetch", req.url, init))
+	if err != nil {
+		return js.Value{}, err
+	}
+	return resp, nil
+}
+
+// doFetch runs the attempts for req and builds the response envelope
+func (h *Handler) doFetch(req *fetchRequest) js.Value {
+	attempt := 0
+	for {
+		attempt++
+		resp, err := fetchOnce(req)
+		status := 0
+		if err == nil {
+			status = resp.Get("status").Int()
+		}
+		if !req.policy.ShouldRetry(req.method, attempt, status) {
+			if err != nil {
+				return h.errorResponse(fmt.Sprintf("fetch %s failed after %d attempt(s): %v", req.url, attempt, err))
+			}
+			return h.fetchResult(req, resp, attempt)
+		}
+
+		retryAfter := ""
+		if err == nil {
+			if v := resp.Get("headers").Call("get", "Retry-After"); v.Type() == js.TypeString {
+				retryAfter = v.String()
+			}
+		}
+		time.Sleep(req.policy.Delay(attempt, retryAfter, time.Now()))
+	}
+}
+
+// fetchResult reads the response headers and body
+func (h *Handler) fetchResult(req *fetchRequest, resp js.Value, attempts int) js.Value {
+	headers := map[string]interface{}{}
+	collect := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		headers[args[1].String()] = args[0].String()
+		return nil
+	})
+	resp.Get("headers").Call("forEach", collect)
+	collect.Release()
+
+	textValue, err := awaitPromise(resp.Call("text"))
+	if err != nil {
+		return h.errorResponse(fmt.Sprintf("failed to read response body: %v", err))
+	}
+	text := textValue.String()
+
+	result := map[string]interface{}{
+		"url":      resp.Get("url").String(),
+		"status":   resp.Get("status").Int(),
+		"ok":       resp.Get("ok").Bool(),
+		"headers":  headers,
+		"attempts": attempts,
+	}
+
+	contentType, _ := headers["content-type"].(string)
+	isJSON := req.responseType == "json" ||
+		req.responseType == "auto" && strings.Contains(contentType, "json")
+	if isJSON && text != "" {
+		var data interface{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			if req.responseType == "json" {
+				return h.errorResponse(fmt.Sprintf("response is not valid JSON: %v", err))
+			}
+			result["body"] = text
+		} else {
+			result["json"] = data
+		}
+	} else {
+		result["body"] = text
+	}
+
+	return h.successResponse(result, fmt.Sprintf("HTTP %d", resp.Get("status").Int()))
+}
+
+// Fetch wraps the browser fetch with a per-attempt timeout, retries with
+// exponential backoff and JSON handling. It returns a Promise because the
+// request completes on later turns of the event loop: the work runs in a
+// goroutine that yields to JS while waiting, so the page stays responsive
+// and other goAPI calls keep working meanwhile.
+//
+// The argument is a URL or an options object with url, method, headers,
+// body (a string, bytes, or an object sent as JSON), timeoutMs (per
+// attempt, default 10000, 0 disables it), retries (default 3),
+// retryDelayMs, maxRetryDelayMs, retryUnsafe (also retry POST and PATCH)
+// and responseType (auto, json or text). Network errors, timeouts, 408,
+// 429 and most 5xx responses are retried, honouring Retry-After.
+//
+// The Promise never rejects. It resolves with the usual response object:
+// success with status, ok, headers, attempts and json or body once any
+// response arrives, including a final 4xx or 5xx, or an error when no
+// response could be obtained.
+func (h *Handler) Fetch(this js.Value, inputs []js.Value) interface{} {
+	var options js.Value
+	if len(inputs) > 0 {
+		options = inputs[0]
+	}
+	req, err := parseFetchOptions(options)
+	if err != nil {
+		return resolvedPromise(h.errorResponse(err.Error()))
+	}
+
+	return newPromise(func() js.Value {
+		return h.doFetch(req)
+	})
+}
+
+// newPromise returns a Promise resolved with the result of run, which is
+// called in its own goroutine so it may wait on other promises
+func newPromise(run func() js.Value) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve := args[0]
+		go func() {
+			resolve.Invoke(run())
+		}()
+		return nil
+	})
+	// the executor runs synchronously inside the Promise constructor
+	defer executor.Release()
+
+	return js.Global().Get("Promise").New(executor)
+}
+
+// resolvedPromise returns a Promise already resolved with value
+func resolvedPromise(value js.Value) js.Value {
+	return js.Global().Get("Promise").Call("resolve", value)
+}
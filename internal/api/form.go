@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// ValidateForm validates an object of field values against a schema of
+// per-field rules in a single call
+func (h *Handler) ValidateForm(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires form values and schema")
+	}
+
+	values, ok := fromJSValue(inputs[0]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Form values must be an object")
+	}
+	schema, ok := fromJSValue(inputs[1]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Schema must be an object")
+	}
+
+	result, err := h.processor.ValidateForm(values, schema)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, fmt.Sprintf("Validated %d fields", len(schema)))
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// FormatDuration renders a duration such as "1h 23m 4s". The optional second
+// argument is the input unit: "ms" (default), "us", "ns" or "s".
+func (h *Handler) FormatDuration(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeNumber {
+		return h.errorResponse("Duration must be a number")
+	}
+
+	unit := "ms"
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeString {
+		unit = inputs[1].String()
+	}
+
+	result, err := h.processor.HumanizeDuration(inputs[0].Float(), unit)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Duration formatted")
+}
+
+// FormatBytes renders a byte count such as "1.2 MB". Pass "iec" as the
+// second argument for binary units (KiB, MiB, ...).
+func (h *Handler) FormatBytes(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeNumber {
+		return h.errorResponse("Byte count must be a number")
+	}
+
+	iec := len(inputs) > 1 && inputs[1].Type() == js.TypeString && inputs[1].String() == "iec"
+
+	result, err := h.processor.HumanizeBytes(inputs[0].Float(), iec)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Byte count formatted")
+}
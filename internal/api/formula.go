@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// EvaluateFormula evaluates a spreadsheet-style formula such as
+// "IF(SUM(A1:A3) > total, 1, 0)". The optional cells object maps names to
+// numbers, booleans, strings, arrays or further formulas (strings starting
+// with =), which are evaluated on demand.
+func (h *Handler) EvaluateFormula(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Formula string required")
+	}
+
+	var cells map[string]interface{}
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		var ok bool
+		if cells, ok = fromJSValue(inputs[1]).(map[string]interface{}); !ok {
+			return h.errorResponse("Cells must be an object of names to values")
+		}
+	}
+	formula := inputs[0].String()
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.EvaluateFormula(ctx, formula, cells)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Formula evaluated")
+}
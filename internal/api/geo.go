@@ -0,0 +1,164 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// jsLatLng reads a point given as {lat, lng} (lon is accepted too) or as a
+// [lat, lng] array
+func jsLatLng(v js.Value) (core.LatLng, error) {
+	switch {
+	case v.Type() == js.TypeObject && v.InstanceOf(js.Global().Get("Array")):
+		if v.Length() != 2 || v.Index(0).Type() != js.TypeNumber || v.Index(1).Type() != js.TypeNumber {
+			return core.LatLng{}, fmt.Errorf("point array must be [lat, lng]")
+		}
+		return core.LatLng{Lat: v.Index(0).Float(), Lng: v.Index(1).Float()}, nil
+	case v.Type() == js.TypeObject:
+		lat, lng := v.Get("lat"), v.Get("lng")
+		if lng.Type() != js.TypeNumber {
+			lng = v.Get("lon")
+		}
+		if lat.Type() != js.TypeNumber || lng.Type() != js.TypeNumber {
+			return core.LatLng{}, fmt.Errorf("point must have numeric lat and lng")
+		}
+		return core.LatLng{Lat: lat.Float(), Lng: lng.Float()}, nil
+	}
+	return core.LatLng{}, fmt.Errorf("point must be {lat, lng} or [lat, lng]")
+}
+
+// GeoDistance returns the great-circle distance between two points. The
+// optional unit is km (default), m, mi or nm.
+func (h *Handler) GeoDistance(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Two points required")
+	}
+
+	a, err := jsLatLng(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	b, err := jsLatLng(inputs[1])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	unit := ""
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeString {
+		unit = inputs[2].String()
+	}
+
+	result, err := h.processor.GeoDistance(a, b, unit)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Distance calculated")
+}
+
+// GeoBoundingBox returns the lat/lng bounds of a circle around a center
+// point. When the box crosses the antimeridian, minLng is greater than
+// maxLng and crossesAntimeridian is true.
+func (h *Handler) GeoBoundingBox(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeNumber {
+		return h.errorResponse("Center point and radius required")
+	}
+
+	center, err := jsLatLng(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	unit := ""
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeString {
+		unit = inputs[2].String()
+	}
+
+	result, err := h.processor.GeoBoundingBox(center, inputs[1].Float(), unit)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Bounding box calculated")
+}
+
+// PointInPolygon reports whether a point lies inside a polygon given as an
+// array of points. Points exactly on an edge or vertex count as inside.
+func (h *Handler) PointInPolygon(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || !inputs[1].InstanceOf(js.Global().Get("Array")) {
+		return h.errorResponse("Point and polygon vertex array required")
+	}
+
+	p, err := jsLatLng(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	polygon := make([]core.LatLng, inputs[1].Length())
+	for i := range polygon {
+		v, err := jsLatLng(inputs[1].Index(i))
+		if err != nil {
+			return h.errorResponse(fmt.Sprintf("vertex %d: %v", i, err))
+		}
+		polygon[i] = v
+	}
+
+	result, err := h.processor.PointInPolygon(p, polygon)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Point tested against polygon")
+}
+
+// GeohashEncode encodes a point as a geohash. The optional precision is the
+// number of characters, 1 to 12 (default 9).
+func (h *Handler) GeohashEncode(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Point required")
+	}
+
+	p, err := jsLatLng(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	precision := 9
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeNumber {
+		precision = inputs[1].Int()
+	}
+
+	result, err := h.processor.GeohashEncode(p, precision)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Geohash encoded")
+}
+
+// GeohashDecode returns the center and bounding box of a geohash cell
+func (h *Handler) GeohashDecode(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Geohash string required")
+	}
+
+	result, err := h.processor.GeohashDecode(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Geohash decoded")
+}
+
+// GeohashNeighbors returns the eight adjacent geohashes keyed n, ne, e, se,
+// s, sw, w and nw
+func (h *Handler) GeohashNeighbors(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Geohash string required")
+	}
+
+	result, err := h.processor.GeohashNeighbors(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Geohash neighbors found")
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// GroupedStats groups an array of objects by one field and returns
+// statistics of a numeric field for each group. Fields are dotted paths
+// or JSON pointers. The optional options object takes sortBy (key, rows,
+// count, sum, mean, median, min, max or stddev; default key) and
+// descending.
+func (h *Handler) GroupedStats(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 || inputs[1].Type() != js.TypeString || inputs[2].Type() != js.TypeString {
+		return h.errorResponse("Requires an array of objects, a group-by field and a numeric field")
+	}
+	rows, ok := fromJSValue(inputs[0]).([]interface{})
+	if !ok {
+		return h.errorResponse("Rows must be an array")
+	}
+
+	sortBy, descending := "", false
+	if len(inputs) > 3 && inputs[3].Type() == js.TypeObject {
+		if v := inputs[3].Get("sortBy"); v.Type() == js.TypeString {
+			sortBy = v.String()
+		}
+		descending = inputs[3].Get("descending").Truthy()
+	}
+
+	result, err := h.processor.GroupedStats(rows, inputs[1].String(), inputs[2].String(), sortBy, descending)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Grouped statistics calculated")
+}
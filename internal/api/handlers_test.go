@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"syscall/js"
+	"testing"
+)
+
+// call invokes a handler method with Go arguments, converted as toJSValue
+// does, and decodes its response
+func call(t testing.TB, method func(js.Value, []js.Value) interface{}, args ...interface{}) map[string]interface{} {
+	t.Helper()
+	inputs := make([]js.Value, len(args))
+	for i, arg := range args {
+		inputs[i] = toJSValue(arg)
+	}
+	result, ok := method(js.Undefined(), inputs).(js.Value)
+	if !ok {
+		t.Fatalf("handler returned %T, want js.Value", result)
+	}
+	if result.Type() == js.TypeString {
+		var response map[string]interface{}
+		if err := json.Unmarshal([]byte(result.String()), &response); err != nil {
+			t.Fatalf("invalid JSON response: %v", err)
+		}
+		return response
+	}
+	response, ok := fromJSValue(result).(map[string]interface{})
+	if !ok {
+		t.Fatalf("response is not an object")
+	}
+	return response
+}
+
+// data returns the data of a successful response
+func data(t testing.TB, response map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	if response["success"] != true {
+		t.Fatalf("handler failed: %v", response["error"])
+	}
+	d, _ := response["data"].(map[string]interface{})
+	return d
+}
+
+// failed asserts that a response is an error
+func failed(t testing.TB, response map[string]interface{}) {
+	t.Helper()
+	if response["success"] != false {
+		t.Fatalf("expected an error response, got %v", response)
+	}
+}
+
+// normalize converts a value to what fromJSValue returns for it
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func numberSlice(n int) []interface{} {
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = float64(i) * 1.5
+	}
+	return values
+}
+
+func stringSlice(n int) []interface{} {
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = fmt.Sprintf("item-%d", i)
+	}
+	return values
+}
+
+func objectSlice(n int) []interface{} {
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = map[string]interface{}{"id": fmt.Sprintf("row-%d", i), "value": float64(i), "ok": i%2 == 0}
+	}
+	return values
+}
+
+// toJSValueShapes are the result shapes the conversion is tuned for
+var toJSValueShapes = []struct {
+	name  string
+	value interface{}
+}{
+	{"scalar", "hello"},
+	{"small object", map[string]interface{}{"a": 1.0, "b": "two", "c": nil}},
+	{"numbers", numberSlice(5000)},
+	{"short numbers", numberSlice(10)},
+	{"strings", stringSlice(2000)},
+	{"objects", objectSlice(1000)},
+	{"mixed", map[string]interface{}{"series": numberSlice(5000), "labels": stringSlice(500), "count": 5000}},
+	{"nested", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": objectSlice(50)}}}},
+	{"mixed numbers and strings", []interface{}{1.0, "a", 2.0, "b", true, nil}},
+}
+
+func TestToJSValue(t *testing.T) {
+	for _, tt := range toJSValueShapes {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fromJSValue(toJSValue(tt.value))
+			if want := normalize(tt.value); !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip differs:\n got %.200v\nwant %.200v", got, want)
+			}
+		})
+	}
+}
+
+func TestToJSValueNonJSON(t *testing.T) {
+	// NaN cannot go through JSON, so the value is built directly
+	value := map[string]interface{}{"mean": math.NaN(), "labels": stringSlice(10)}
+	got := toJSValue(value)
+	if mean := got.Get("mean"); mean.Type() != js.TypeNumber || !math.IsNaN(mean.Float()) {
+		t.Errorf("mean = %v, want NaN", mean)
+	}
+	if n := got.Get("labels").Length(); n != 10 {
+		t.Errorf("labels length = %d, want 10", n)
+	}
+
+	// A js.Value nested in a result is passed through
+	inner := js.Global().Get("Object").New()
+	inner.Set("x", 1)
+	got = toJSValue(map[string]interface{}{"inner": inner, "items": stringSlice(10)})
+	if !got.Get("inner").Equal(inner) {
+		t.Error("nested js.Value was not passed through")
+	}
+}
+
+// directJSValue builds a value with one call per string, element and
+// property, the conversion toJSValue used before it chose between JSON and
+// typed arrays; the benchmarks compare against it
+func directJSValue(v interface{}) js.Value {
+	switch val := v.(type) {
+	case []interface{}:
+		jsArray := js.Global().Get("Array").New(len(val))
+		for i, item := range val {
+			jsArray.SetIndex(i, directJSValue(item))
+		}
+		return jsArray
+	case map[string]interface{}:
+		jsObj := js.Global().Get("Object").New()
+		for key, value := range val {
+			jsObj.Set(key, directJSValue(value))
+		}
+		return jsObj
+	case nil:
+		return js.Null()
+	default:
+		return js.ValueOf(val)
+	}
+}
+
+func BenchmarkToJSValue(b *testing.B) {
+	for _, tt := range toJSValueShapes {
+		b.Run(tt.name+"/direct", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				directJSValue(tt.value)
+			}
+		})
+		b.Run(tt.name+"/toJSValue", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				toJSValue(tt.value)
+			}
+		})
+	}
+}
+
+// BenchmarkToJSValueDepth checks that conversion stays linear in the size
+// of deeply nested results
+func BenchmarkToJSValueDepth(b *testing.B) {
+	for _, depth := range []int{10, 100, 1000} {
+		value := interface{}(numberSlice(3))
+		for d := 0; d < depth; d++ {
+			value = map[string]interface{}{"child": value, "n": float64(d)}
+		}
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				toJSValue(value)
+			}
+		})
+	}
+}
+
+func TestNonNumericArguments(t *testing.T) {
+	// Value.Int and Value.Float panic on other types, which would stop the
+	// runtime, so these must come back as error responses
+	h := NewHandler()
+	register := data(t, call(t, h.LWWInit))["handle"]
+	counter := data(t, call(t, h.CounterInit))["handle"]
+	rng := data(t, call(t, h.RNGSeed, 42))["handle"]
+	tests := []struct {
+		name   string
+		method func(js.Value, []js.Value) interface{}
+		args   []interface{}
+	}{
+		{"convertBase source", h.ConvertBase, []interface{}{"ff", "16", 10}},
+		{"convertBase target", h.ConvertBase, []interface{}{"ff", 16, "10"}},
+		{"bloomInit expected items", h.BloomInit, []interface{}{"100", 0.01}},
+		{"bloomInit rate", h.BloomInit, []interface{}{100, "0.01"}},
+		{"bloomAdd", h.BloomAdd, []interface{}{"1", "x"}},
+		{"bloomCheck", h.BloomCheck, []interface{}{"1", "x"}},
+		{"bloomExport", h.BloomExport, []interface{}{"1"}},
+		{"bloomRelease", h.BloomRelease, []interface{}{"1"}},
+		{"lwwSet handle", h.LWWSet, []interface{}{"1", "v", 1, "r"}},
+		{"lwwSet timestamp", h.LWWSet, []interface{}{register, "v", "soon", "r"}},
+		{"lwwGet", h.LWWGet, []interface{}{"1"}},
+		{"lwwMerge", h.LWWMerge, []interface{}{"1", 2}},
+		{"lwwRelease", h.LWWRelease, []interface{}{"1"}},
+		{"counterIncrement handle", h.CounterIncrement, []interface{}{"1", "r"}},
+		{"counterIncrement amount", h.CounterIncrement, []interface{}{counter, "r", "5"}},
+		{"counterDecrement", h.CounterDecrement, []interface{}{"1", "r"}},
+		{"counterValue", h.CounterValue, []interface{}{"1"}},
+		{"counterMerge", h.CounterMerge, []interface{}{"1", 2}},
+		{"counterRelease", h.CounterRelease, []interface{}{"1"}},
+		{"orsetAdd", h.ORSetAdd, []interface{}{"1", "x", "r"}},
+		{"orsetRemove", h.ORSetRemove, []interface{}{"1", "x"}},
+		{"orsetValues", h.ORSetValues, []interface{}{"1"}},
+		{"orsetMerge", h.ORSetMerge, []interface{}{"1", 2}},
+		{"orsetRelease", h.ORSetRelease, []interface{}{"1"}},
+		{"statsAdd", h.StatsAdd, []interface{}{"1", 2}},
+		{"statsResult", h.StatsResult, []interface{}{"1"}},
+		{"statsRelease", h.StatsRelease, []interface{}{"1"}},
+		{"textAnalyzeFeed", h.TextAnalyzeFeed, []interface{}{"1", "text"}},
+		{"textAnalyzeResult", h.TextAnalyzeResult, []interface{}{"1"}},
+		{"textAnalyzeRelease", h.TextAnalyzeRelease, []interface{}{"1"}},
+		{"presencePublish", h.PresencePublish, []interface{}{"1", nil}},
+		{"presenceDisconnect", h.PresenceDisconnect, []interface{}{"1"}},
+		{"rngFloat", h.RNGFloat, []interface{}{"1"}},
+		{"rngInt handle", h.RNGInt, []interface{}{"1", 1, 6}},
+		{"rngInt min", h.RNGInt, []interface{}{rng, "1", 6}},
+		{"rngInt max", h.RNGInt, []interface{}{rng, 1, "6"}},
+		{"rngShuffle", h.RNGShuffle, []interface{}{"1", []interface{}{1, 2}}},
+		{"rngRelease", h.RNGRelease, []interface{}{"1"}},
+		{"migrate target", h.Migrate, []interface{}{map[string]interface{}{}, "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed(t, call(t, tt.method, tt.args...))
+		})
+	}
+}
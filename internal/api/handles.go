@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+)
+
+// DefaultMaxHandles is the default limit on live handles across all
+// handle-based features
+const DefaultMaxHandles = 10000
+
+// ErrTooManyHandles is returned when creating a handle would exceed the
+// live handle limit
+var ErrTooManyHandles = errors.New("too many live handles")
+
+// handleReleaser is implemented by handle values that hold resources, such
+// as sockets or running computations, that must be freed when the handle
+// is released, evicted or cleaned up
+type handleReleaser interface {
+	release()
+}
+
+// handleBusy is implemented by handle values that must not be evicted as
+// idle while they are in use, such as a running job
+type handleBusy interface {
+	busy() bool
+}
+
+type handleEntry struct {
+	value    interface{}
+	lastUsed time.Time
+}
+
+// handleRegistry stores the values behind every handle given to
+// JavaScript: statistics accumulators, Bloom filters, CRDTs, generators,
+// presence connections, text analyzers and jobs. Handles are never reused.
+// The number of live handles is capped, and with an idle timeout a handle
+// unused for that long is evicted. Callers hold h.mu.
+type handleRegistry struct {
+	entries map[int]*handleEntry
+	next    int
+	maxLive int
+	// idleTTL of zero disables idle eviction
+	idleTTL time.Duration
+}
+
+func newHandleRegistry() *handleRegistry {
+	return &handleRegistry{
+		entries: make(map[int]*handleEntry),
+		maxLive: DefaultMaxHandles,
+	}
+}
+
+// expired reports whether an entry has been idle longer than the timeout
+func (r *handleRegistry) expired(e *handleEntry, now time.Time) bool {
+	if r.idleTTL <= 0 || now.Sub(e.lastUsed) <= r.idleTTL {
+		return false
+	}
+	b, ok := e.value.(handleBusy)
+	return !ok || !b.busy()
+}
+
+// evict removes a handle and releases its value
+func (r *handleRegistry) evict(handle int) {
+	if rel, ok := r.entries[handle].value.(handleReleaser); ok {
+		rel.release()
+	}
+	delete(r.entries, handle)
+}
+
+// evictIdle removes every expired handle
+func (r *handleRegistry) evictIdle(now time.Time) {
+	for handle, e := range r.entries {
+		if r.expired(e, now) {
+			r.evict(handle)
+		}
+	}
+}
+
+// room returns how many more handles can be created
+func (r *handleRegistry) room() int {
+	r.evictIdle(time.Now())
+	return max(r.maxLive-len(r.entries), 0)
+}
+
+// add stores value under a new handle
+func (r *handleRegistry) add(value interface{}) (int, error) {
+	if r.room() == 0 {
+		return 0, fmt.Errorf("%w (limit %d); release unused handles or raise the limit with setHandleLimits", ErrTooManyHandles, r.maxLive)
+	}
+	r.next++
+	r.entries[r.next] = &handleEntry{value: value, lastUsed: time.Now()}
+	return r.next, nil
+}
+
+// entry returns a live handle's entry, evicting it first if it has expired
+func (r *handleRegistry) entry(handle int) (*handleEntry, bool) {
+	e, ok := r.entries[handle]
+	if !ok {
+		return nil, false
+	}
+	if r.expired(e, time.Now()) {
+		r.evict(handle)
+		return nil, false
+	}
+	return e, true
+}
+
+// reset releases every handle
+func (r *handleRegistry) reset() {
+	for handle := range r.entries {
+		r.evict(handle)
+	}
+}
+
+// lookupHandle returns the value of a handle that holds a T and marks it
+// used. Callers hold h.mu.
+func lookupHandle[T any](h *Handler, handle int) (T, bool) {
+	var zero T
+	e, ok := h.handles.entry(handle)
+	if !ok {
+		return zero, false
+	}
+	v, ok := e.value.(T)
+	if !ok {
+		return zero, false
+	}
+	e.lastUsed = time.Now()
+	return v, true
+}
+
+// releaseHandle removes a handle that holds a T, releasing its value.
+// Callers hold h.mu.
+func releaseHandle[T any](h *Handler, handle int) (T, bool) {
+	v, ok := lookupHandle[T](h, handle)
+	if ok {
+		h.handles.evict(handle)
+	}
+	return v, ok
+}
+
+// handlesOf returns every live handle that holds a T. Callers hold h.mu.
+func handlesOf[T any](h *Handler) map[int]T {
+	now := time.Now()
+	values := make(map[int]T)
+	for handle, e := range h.handles.entries {
+		if v, ok := e.value.(T); ok && !h.handles.expired(e, now) {
+			values[handle] = v
+		}
+	}
+	return values
+}
+
+// SetHandleLimits sets the maximum number of live handles (default 10000)
+// and the idle timeout in milliseconds after which an unused handle is
+// evicted (default 0, never). Running jobs and open presence connections
+// are not evicted while in use. Either option may be omitted; called with
+// no options it reports the current limits and live handle count.
+func (h *Handler) SetHandleLimits(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(inputs) > 0 && inputs[0].Type() == js.TypeObject {
+		if v := inputs[0].Get("maxHandles"); v.Type() == js.TypeNumber {
+			if v.Int() < 1 {
+				return h.errorResponse("maxHandles must be at least 1")
+			}
+			h.handles.maxLive = v.Int()
+		}
+		if v := inputs[0].Get("idleTimeoutMs"); v.Type() == js.TypeNumber {
+			if v.Int() < 0 {
+				return h.errorResponse("idleTimeoutMs must not be negative")
+			}
+			h.handles.idleTTL = time.Duration(v.Int()) * time.Millisecond
+		}
+	}
+	h.handles.evictIdle(time.Now())
+
+	return h.successResponse(map[string]interface{}{
+		"maxHandles":    h.handles.maxLive,
+		"idleTimeoutMs": h.handles.idleTTL.Milliseconds(),
+		"live":          len(h.handles.entries),
+	}, "Handle limits updated")
+}
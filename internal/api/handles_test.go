@@ -0,0 +1,154 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHandle records whether it was released and can report itself busy
+type fakeHandle struct {
+	released bool
+	inUse    bool
+}
+
+func (f *fakeHandle) release()   { f.released = true }
+func (f *fakeHandle) busy() bool { return f.inUse }
+
+func TestHandleLimit(t *testing.T) {
+	h := NewHandler()
+	limits := data(t, call(t, h.SetHandleLimits, map[string]interface{}{"maxHandles": 2}))
+	if limits["maxHandles"] != 2.0 || limits["live"] != 0.0 {
+		t.Fatalf("limits = %v", limits)
+	}
+
+	first := data(t, call(t, h.StatsInit))["handle"]
+	data(t, call(t, h.StatsInit))
+	response := call(t, h.StatsInit)
+	failed(t, response)
+	if !strings.Contains(response["error"].(string), ErrTooManyHandles.Error()) {
+		t.Errorf("error = %v, want %q", response["error"], ErrTooManyHandles)
+	}
+	// the cap is shared by every kind of handle
+	failed(t, call(t, h.BloomInit, 100, 0.01))
+
+	// releasing a handle makes room, and handles are not reused
+	call(t, h.StatsRelease, first)
+	third := data(t, call(t, h.StatsInit))["handle"]
+	if third == first {
+		t.Errorf("handle %v reused", first)
+	}
+	failed(t, call(t, h.StatsResult, first))
+}
+
+func TestHandleLimitsValidation(t *testing.T) {
+	h := NewHandler()
+	failed(t, call(t, h.SetHandleLimits, map[string]interface{}{"maxHandles": 0}))
+	failed(t, call(t, h.SetHandleLimits, map[string]interface{}{"idleTimeoutMs": -1}))
+
+	// no options reports the defaults
+	limits := data(t, call(t, h.SetHandleLimits))
+	if limits["maxHandles"] != float64(DefaultMaxHandles) || limits["idleTimeoutMs"] != 0.0 {
+		t.Errorf("limits = %v", limits)
+	}
+
+	// options are independent
+	data(t, call(t, h.SetHandleLimits, map[string]interface{}{"idleTimeoutMs": 500}))
+	limits = data(t, call(t, h.SetHandleLimits, map[string]interface{}{"maxHandles": 3}))
+	if limits["maxHandles"] != 3.0 || limits["idleTimeoutMs"] != 500.0 {
+		t.Errorf("limits = %v", limits)
+	}
+}
+
+func TestHandleWrongType(t *testing.T) {
+	h := NewHandler()
+	handle := data(t, call(t, h.StatsInit))["handle"]
+
+	// a statistics handle is not a Bloom filter, and releasing it as one
+	// leaves it in place
+	failed(t, call(t, h.BloomCheck, handle, "x"))
+	failed(t, call(t, h.BloomRelease, handle))
+	data(t, call(t, h.StatsResult, handle))
+}
+
+func TestHandleIdleEviction(t *testing.T) {
+	r := newHandleRegistry()
+	r.idleTTL = time.Minute
+	idle, inUse := &fakeHandle{}, &fakeHandle{inUse: true}
+	idleHandle, _ := r.add(idle)
+	busyHandle, _ := r.add(inUse)
+	freshHandle, _ := r.add(&fakeHandle{})
+
+	old := time.Now().Add(-2 * time.Minute)
+	r.entries[idleHandle].lastUsed = old
+	r.entries[busyHandle].lastUsed = old
+
+	if _, ok := r.entry(idleHandle); ok || !idle.released {
+		t.Errorf("idle handle not evicted and released")
+	}
+	if _, ok := r.entry(busyHandle); !ok || inUse.released {
+		t.Errorf("busy handle evicted")
+	}
+	if _, ok := r.entry(freshHandle); !ok {
+		t.Errorf("recently used handle evicted")
+	}
+
+	// once no longer busy, the handle is evicted at the next check
+	inUse.inUse = false
+	r.evictIdle(time.Now())
+	if !inUse.released || len(r.entries) != 1 {
+		t.Errorf("entries = %d, released = %v", len(r.entries), inUse.released)
+	}
+}
+
+func TestHandleIdleEvictionDisabled(t *testing.T) {
+	r := newHandleRegistry()
+	handle, _ := r.add(&fakeHandle{})
+	r.entries[handle].lastUsed = time.Now().Add(-24 * time.Hour)
+	if _, ok := r.entry(handle); !ok {
+		t.Error("handle evicted with no idle timeout")
+	}
+}
+
+func TestHandleIdleEvictionMakesRoom(t *testing.T) {
+	r := newHandleRegistry()
+	r.maxLive = 1
+	r.idleTTL = time.Minute
+	stale := &fakeHandle{}
+	handle, _ := r.add(stale)
+	if _, err := r.add(&fakeHandle{}); err == nil {
+		t.Fatal("add over the limit succeeded")
+	}
+	r.entries[handle].lastUsed = time.Now().Add(-2 * time.Minute)
+	if _, err := r.add(&fakeHandle{}); err != nil || !stale.released {
+		t.Errorf("add after expiry: err = %v, released = %v", err, stale.released)
+	}
+}
+
+func TestCleanupReleasesHandles(t *testing.T) {
+	h := NewHandler()
+	values := []*fakeHandle{{}, {inUse: true}}
+	h.mu.Lock()
+	for _, v := range values {
+		h.handles.add(v)
+	}
+	h.mu.Unlock()
+
+	h.Cleanup()
+	for i, v := range values {
+		if !v.released {
+			t.Errorf("handle %d not released", i)
+		}
+	}
+	if live := data(t, call(t, h.SetHandleLimits))["live"]; live != 0.0 {
+		t.Errorf("live = %v, want 0", live)
+	}
+}
+
+func TestBloomReleaseUnknown(t *testing.T) {
+	h := NewHandler()
+	handle := data(t, call(t, h.BloomInit, 100, 0.01))["handle"]
+	data(t, call(t, h.BloomRelease, handle))
+	// a second release reports the handle as unknown, as RNGRelease does
+	failed(t, call(t, h.BloomRelease, handle))
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// HighlightCode tokenizes a code snippet for syntax highlighting. The
+// second argument is the language: json, go, javascript (or js) or generic
+// (the default). Each token has a type, its text and start/end offsets in
+// UTF-16 code units, matching String.slice.
+func (h *Handler) HighlightCode(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Code string required")
+	}
+
+	language := ""
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeString {
+		language = inputs[1].String()
+	}
+	src := inputs[0].String()
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.HighlightCode(ctx, src, language)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Code tokenized")
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// ValidateIBAN checks an IBAN's country length and mod-97 checksum and
+// returns it grouped in blocks of four. Spaces and lowercase are accepted.
+func (h *Handler) ValidateIBAN(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("IBAN string required")
+	}
+
+	result, err := h.processor.ValidateIBAN(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "IBAN checked")
+}
+
+// ValidateBIC checks a BIC (SWIFT code) and splits it into institution,
+// country, location and branch codes
+func (h *Handler) ValidateBIC(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("BIC string required")
+	}
+
+	result, err := h.processor.ValidateBIC(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "BIC checked")
+}
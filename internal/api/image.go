@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// imageBins reads the optional bins option, defaulting to 256
+func imageBins(inputs []js.Value, i int) int {
+	if len(inputs) > i && inputs[i].Type() == js.TypeObject {
+		if v := inputs[i].Get("bins"); v.Type() == js.TypeNumber {
+			return v.Int()
+		}
+	}
+	return 256
+}
+
+// ImageHistogram returns per-channel histograms, means and brightness for a
+// base64 (or Uint8Array/ArrayBuffer) PNG or JPEG. The optional options
+// object takes bins (2 to 256, default 256).
+func (h *Handler) ImageHistogram(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Image data required")
+	}
+
+	data, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	bins := imageBins(inputs, 1)
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ImageHistogram(ctx, data, bins)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Histogram computed")
+}
+
+// ImageCompare scores how similar two images' colour distributions are,
+// from 0 to 1. The optional options object takes bins.
+func (h *Handler) ImageCompare(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Two images required")
+	}
+
+	a, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	b, err := jsBase64Bytes(inputs[1])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	bins := imageBins(inputs, 2)
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ImageCompare(ctx, a, b, bins)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Images compared")
+}
+
+// resizeOptions reads the options object of ResizeImage
+func resizeOptions(options js.Value) core.ResizeOptions {
+	var opts core.ResizeOptions
+	if v := options.Get("width"); v.Type() == js.TypeNumber {
+		opts.Width = v.Int()
+	}
+	if v := options.Get("height"); v.Type() == js.TypeNumber {
+		opts.Height = v.Int()
+	}
+	if v := options.Get("fit"); v.Type() == js.TypeString {
+		opts.Fit = v.String()
+	}
+	if v := options.Get("format"); v.Type() == js.TypeString {
+		opts.Format = v.String()
+	}
+	if v := options.Get("quality"); v.Type() == js.TypeNumber {
+		opts.Quality = v.Int()
+	}
+	return opts
+}
+
+// ResizeImage resizes a base64 (or Uint8Array/ArrayBuffer) PNG or JPEG and
+// returns the result as base64. Options are width, height, fit (contain,
+// cover or stretch), format (png or jpeg, default the input format) and
+// quality for JPEG output.
+func (h *Handler) ResizeImage(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeObject {
+		return h.errorResponse("Image data and options with width and/or height required")
+	}
+
+	data, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	opts := resizeOptions(inputs[1])
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ResizeImage(ctx, data, opts)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Image resized")
+}
+
+// ExtractEXIF returns the date taken, camera, orientation and GPS position
+// (as decimal lat/lng) from a base64 (or Uint8Array/ArrayBuffer) JPEG.
+// Images without EXIF give an empty metadata object.
+func (h *Handler) ExtractEXIF(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Image data required")
+	}
+
+	data, err := jsBase64Bytes(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.ExtractEXIF(data)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "EXIF metadata extracted")
+}
@@ -0,0 +1,384 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// Job statuses
+const (
+	jobPending   = "pending"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobError     = "error"
+	jobCancelled = "cancelled"
+)
+
+const (
+	// jobTTL is how long finished jobs are kept for jobStatus and jobResult
+	jobTTL = 5 * time.Minute
+	// maxActiveJobs bounds the jobs that are pending or running at once
+	maxActiveJobs = 32
+	// jobYieldInterval is how long a job computes before letting the event
+	// loop run
+	jobYieldInterval = 50 * time.Millisecond
+)
+
+// jobFunc is the pure Go work of a job. It must not touch js.Value: the
+// JavaScript arguments are converted before the job starts.
+type jobFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// job is a computation running in the background
+type job struct {
+	id       int
+	kind     string
+	status   string
+	progress float64
+	result   map[string]interface{}
+	err      string
+	cancel   context.CancelFunc
+	created  time.Time
+	started  time.Time
+	finished time.Time
+}
+
+// jobTypes prepares each job type from the arguments its synchronous
+// goAPI function takes
+var jobTypes = map[string]func(h *Handler, args []js.Value) (jobFunc, error){
+	"chunkContent": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("no content provided")
+		}
+		data, err := jsBytes(args[0])
+		if err != nil {
+			return nil, err
+		}
+		avgSize := core.DefaultChunkSize
+		if len(args) > 1 && args[1].Type() == js.TypeNumber {
+			avgSize = args[1].Int()
+		}
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.ChunkContent(ctx, data, avgSize)
+		}, nil
+	},
+	"computeTextPatch": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("requires the old and new text")
+		}
+		oldText, newText := args[0].String(), args[1].String()
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.ComputeTextPatch(ctx, oldText, newText)
+		}, nil
+	},
+	"filterJSONArray": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 || args[0].Type() != js.TypeString {
+			return nil, fmt.Errorf("JSON array string required")
+		}
+		predicate, limit, countOnly := filterArgs(args)
+		input := args[0].String()
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.FilterJSONArray(ctx, input, predicate, limit, countOnly)
+		}, nil
+	},
+	"highlightCode": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 || args[0].Type() != js.TypeString {
+			return nil, fmt.Errorf("code string required")
+		}
+		src, language := args[0].String(), ""
+		if len(args) > 1 && args[1].Type() == js.TypeString {
+			language = args[1].String()
+		}
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.HighlightCode(ctx, src, language)
+		}, nil
+	},
+	"imageHistogram": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("image data required")
+		}
+		data, err := jsBase64Bytes(args[0])
+		if err != nil {
+			return nil, err
+		}
+		bins := imageBins(args, 1)
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.ImageHistogram(ctx, data, bins)
+		}, nil
+	},
+	"merkleRoot": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("items array required")
+		}
+		items, ok := merkleItems(args[0])
+		if !ok {
+			return nil, fmt.Errorf("items must be an array")
+		}
+		includeLevels := len(args) > 1 && args[1].Type() == js.TypeObject && args[1].Get("levels").Truthy()
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.MerkleRoot(ctx, items, includeLevels)
+		}, nil
+	},
+	"resizeImage": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) < 2 || args[1].Type() != js.TypeObject {
+			return nil, fmt.Errorf("image data and options with width and/or height required")
+		}
+		data, err := jsBase64Bytes(args[0])
+		if err != nil {
+			return nil, err
+		}
+		opts := resizeOptions(args[1])
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.ResizeImage(ctx, data, opts)
+		}, nil
+	},
+	"setSketch": func(h *Handler, args []js.Value) (jobFunc, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("items object required")
+		}
+		items, ok := fromJSValue(args[0]).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("items must be an object mapping IDs to versions")
+		}
+		buckets := sketchBuckets(args, 1)
+		return func(ctx context.Context) (map[string]interface{}, error) {
+			return h.processor.SetSketch(ctx, items, buckets)
+		}, nil
+	},
+}
+
+// yieldingContext hands control back to the JavaScript event loop while a
+// job computes. Go's WASM runtime runs every goroutine on the page's single
+// thread, so a busy goroutine would otherwise freeze the page until it
+// finished. Core loops check ctx.Err() regularly, so that is where the job
+// pauses briefly once it has run for jobYieldInterval.
+type yieldingContext struct {
+	context.Context
+	lastYield time.Time
+}
+
+func (c *yieldingContext) Err() error {
+	if time.Since(c.lastYield) >= jobYieldInterval {
+		// sleeping parks every goroutine, which lets the runtime return to
+		// the event loop until the timer fires
+		time.Sleep(time.Millisecond)
+		c.lastYield = time.Now()
+	}
+	return c.Context.Err()
+}
+
+// pruneJobs drops finished jobs older than jobTTL. Callers hold h.mu.
+func (h *Handler) pruneJobs(now time.Time) {
+	for id, j := range handlesOf[*job](h) {
+		if !j.finished.IsZero() && now.Sub(j.finished) > jobTTL {
+			h.handles.evict(id)
+		}
+	}
+}
+
+// runJob runs fn and records its outcome on j. A job cancelled before
+// it finishes keeps its cancelled status and its output is discarded.
+func (h *Handler) runJob(ctx context.Context, j *job, fn jobFunc) {
+	h.mu.Lock()
+	if j.status == jobCancelled {
+		h.mu.Unlock()
+		return
+	}
+	j.status = jobRunning
+	j.started = time.Now()
+	h.mu.Unlock()
+
+	ctx = core.WithProgress(ctx, func(done, total int) {
+		h.mu.Lock()
+		j.progress = float64(done) / float64(total)
+		h.mu.Unlock()
+	})
+	result, err := fn(&yieldingContext{Context: ctx, lastYield: time.Now()})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if j.status == jobCancelled {
+		return
+	}
+	j.cancel()
+	j.finished = time.Now()
+	if err != nil {
+		j.status = jobError
+		j.err = err.Error()
+		return
+	}
+	j.status = jobDone
+	j.progress = 1
+	j.result = result
+}
+
+// summary describes a job. Callers hold h.mu.
+func (j *job) summary() map[string]interface{} {
+	s := map[string]interface{}{
+		"id":        j.id,
+		"type":      j.kind,
+		"status":    j.status,
+		"progress":  j.progress,
+		"createdAt": j.created.UnixMilli(),
+	}
+	if !j.started.IsZero() {
+		end := j.finished
+		if end.IsZero() {
+			end = time.Now()
+		}
+		s["elapsedMs"] = end.Sub(j.started).Milliseconds()
+	}
+	if j.err != "" {
+		s["error"] = j.err
+	}
+	return s
+}
+
+// lookupJob finds a job by the ID in inputs[0]. Callers hold h.mu.
+func (h *Handler) lookupJob(inputs []js.Value) (*job, error) {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("Job ID required")
+	}
+	h.pruneJobs(time.Now())
+	j, ok := lookupHandle[*job](h, inputs[0].Int())
+	if !ok {
+		return nil, fmt.Errorf("Unknown job: %d (finished jobs expire after %s)", inputs[0].Int(), jobTTL)
+	}
+	return j, nil
+}
+
+// StartJob runs a long computation in the background and returns its ID
+// straight away. The first argument is the job type, named after the goAPI
+// function it runs: chunkContent, computeTextPatch, filterJSONArray,
+// highlightCode, imageHistogram, merkleRoot, resizeImage or setSketch. The
+// remaining arguments are that function's arguments, read before
+// startJob returns, so later changes to them do not affect the job.
+//
+// Go's WASM runtime is single-threaded: jobs interleave with JavaScript
+// rather than running in parallel, pausing regularly so the page stays
+// responsive. Poll jobStatus for progress and fetch the output with
+// jobResult; finished jobs are kept for five minutes.
+func (h *Handler) StartJob(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Job type required")
+	}
+	kind := inputs[0].String()
+	prepare, ok := jobTypes[kind]
+	if !ok {
+		kinds := make([]string, 0, len(jobTypes))
+		for k := range jobTypes {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return h.errorResponse(fmt.Sprintf("Unknown job type %q (expected %s)", kind, strings.Join(kinds, ", ")))
+	}
+	fn, err := prepare(h, inputs[1:])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	h.pruneJobs(time.Now())
+	active := 0
+	for _, j := range handlesOf[*job](h) {
+		if j.finished.IsZero() {
+			active++
+		}
+	}
+	if active >= maxActiveJobs {
+		h.mu.Unlock()
+		return h.errorResponse(fmt.Sprintf("Too many jobs in progress (limit %d)", maxActiveJobs))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{kind: kind, status: jobPending, cancel: cancel, created: time.Now()}
+	if j.id, err = h.handles.add(j); err != nil {
+		h.mu.Unlock()
+		cancel()
+		return h.errorResponse(err.Error())
+	}
+	summary := j.summary()
+	h.mu.Unlock()
+
+	go h.runJob(ctx, j, fn)
+
+	return h.successResponse(summary, "Job started")
+}
+
+// cancelLocked marks an unfinished job cancelled and stops its
+// computation. Callers hold h.mu, which orders it against runJob
+// recording a result.
+func (j *job) cancelLocked() {
+	j.status = jobCancelled
+	j.finished = time.Now()
+	j.cancel()
+}
+
+// release cancels a job whose handle is evicted or cleaned up
+func (j *job) release() {
+	if j.finished.IsZero() {
+		j.cancelLocked()
+	}
+}
+
+// busy keeps an unfinished job from being evicted as idle
+func (j *job) busy() bool {
+	return j.finished.IsZero()
+}
+
+// JobStatus reports a job's status (pending, running, done, error or
+// cancelled) and
+// its progress from 0 to 1 where the computation reports it
+func (h *Handler) JobStatus(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, err := h.lookupJob(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(j.summary(), "Job "+j.status)
+}
+
+// JobResult returns a finished job's output as its synchronous function
+// would, or an error while the job is still pending or running
+func (h *Handler) JobResult(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, err := h.lookupJob(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	switch j.status {
+	case jobDone:
+		return h.successResponse(j.result, "Job done")
+	case jobError:
+		return h.errorResponse(j.err)
+	}
+	return h.errorResponse(fmt.Sprintf("Job %d is %s", j.id, j.status))
+}
+
+// CancelJob stops a pending or running job. The computation notices at its
+// next cancellation check, which core loops make every few thousand items,
+// rows or bytes; the job reports cancelled from now on and its partial
+// output is dropped. Cancelling a job that has already finished changes
+// nothing and reports its final status.
+func (h *Handler) CancelJob(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, err := h.lookupJob(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	if !j.finished.IsZero() {
+		return h.successResponse(j.summary(), fmt.Sprintf("Job already %s", j.status))
+	}
+	j.cancelLocked()
+	return h.successResponse(j.summary(), "Job cancelled")
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitJob polls a job until it leaves the pending and running states and
+// returns its final status
+func waitJob(t *testing.T, h *Handler, id interface{}) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		status := data(t, call(t, h.JobStatus, id))
+		if s := status["status"]; s != jobPending && s != jobRunning {
+			return status
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %v did not finish", id)
+	return nil
+}
+
+func TestJobLifecycle(t *testing.T) {
+	h := NewHandler()
+	started := data(t, call(t, h.StartJob, "highlightCode", "x := 1", "go"))
+	if started["status"] != jobPending || started["type"] != "highlightCode" {
+		t.Fatalf("started = %v", started)
+	}
+	id := started["id"]
+
+	status := waitJob(t, h, id)
+	if status["status"] != jobDone || status["progress"] != 1.0 {
+		t.Fatalf("status = %v", status)
+	}
+	if _, ok := status["elapsedMs"]; !ok {
+		t.Error("elapsedMs missing from a finished job")
+	}
+
+	// the result matches the synchronous call
+	result := data(t, call(t, h.JobResult, id))
+	want := data(t, call(t, h.HighlightCode, "x := 1", "go"))
+	if len(result["tokens"].([]interface{})) != len(want["tokens"].([]interface{})) || result["language"] != "go" {
+		t.Errorf("result = %v, want %v", result, want)
+	}
+}
+
+func TestJobErrors(t *testing.T) {
+	h := NewHandler()
+
+	failed(t, call(t, h.StartJob))
+	failed(t, call(t, h.StartJob, "mineBitcoin"))
+	if response := call(t, h.StartJob, "nope"); !strings.Contains(response["error"].(string), "highlightCode") {
+		t.Errorf("error does not list the job types: %v", response["error"])
+	}
+	// arguments are checked before the job starts
+	failed(t, call(t, h.StartJob, "highlightCode"))
+	failed(t, call(t, h.JobStatus))
+	failed(t, call(t, h.JobStatus, 999.0))
+
+	// a failing computation reports its error through the job
+	id := data(t, call(t, h.StartJob, "highlightCode", "x", "cobol"))["id"]
+	status := waitJob(t, h, id)
+	if status["status"] != jobError || !strings.Contains(status["error"].(string), "unsupported language") {
+		t.Errorf("status = %v", status)
+	}
+	failed(t, call(t, h.JobResult, id))
+}
+
+func TestJobResultWhilePending(t *testing.T) {
+	h := NewHandler()
+	// the job cannot run until this goroutine blocks, so it is still
+	// pending here
+	id := data(t, call(t, h.StartJob, "highlightCode", "x", "go"))["id"]
+	response := call(t, h.JobResult, id)
+	failed(t, response)
+	if !strings.Contains(response["error"].(string), "is pending") {
+		t.Errorf("error = %v", response["error"])
+	}
+	waitJob(t, h, id)
+}
+
+func TestJobLimit(t *testing.T) {
+	h := NewHandler()
+	for i := 0; i < maxActiveJobs; i++ {
+		data(t, call(t, h.StartJob, "highlightCode", "x", "go"))
+	}
+	// none of the jobs has had a chance to run, so all are active
+	failed(t, call(t, h.StartJob, "highlightCode", "x", "go"))
+}
+
+func TestJobExpires(t *testing.T) {
+	h := NewHandler()
+	id := data(t, call(t, h.StartJob, "highlightCode", "x", "go"))["id"]
+	waitJob(t, h, id)
+
+	h.mu.Lock()
+	j, _ := lookupHandle[*job](h, int(id.(float64)))
+	j.finished = time.Now().Add(-jobTTL - time.Second)
+	h.mu.Unlock()
+
+	response := call(t, h.JobStatus, id)
+	failed(t, response)
+	if !strings.Contains(response["error"].(string), "expire") {
+		t.Errorf("error = %v", response["error"])
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	h := NewHandler()
+	id := data(t, call(t, h.StartJob, "highlightCode", strings.Repeat("x ", 100000), "go"))["id"]
+
+	cancelled := data(t, call(t, h.CancelJob, id))
+	if cancelled["status"] != jobCancelled {
+		t.Fatalf("cancelled = %v", cancelled)
+	}
+	// the job goroutine sees the cancellation and leaves the status alone
+	time.Sleep(10 * time.Millisecond)
+	if status := data(t, call(t, h.JobStatus, id)); status["status"] != jobCancelled {
+		t.Errorf("status = %v", status)
+	}
+	response := call(t, h.JobResult, id)
+	failed(t, response)
+	if !strings.Contains(response["error"].(string), "is cancelled") {
+		t.Errorf("error = %v", response["error"])
+	}
+
+	// cancelling again, or cancelling a finished job, reports its final status
+	if again := call(t, h.CancelJob, id); again["message"] != "Job already cancelled" {
+		t.Errorf("second cancel = %v", again)
+	}
+	done := data(t, call(t, h.StartJob, "highlightCode", "x", "go"))["id"]
+	waitJob(t, h, done)
+	if status := data(t, call(t, h.CancelJob, done)); status["status"] != jobDone {
+		t.Errorf("cancelling a finished job = %v", status)
+	}
+
+	failed(t, call(t, h.CancelJob))
+	failed(t, call(t, h.CancelJob, 12345.0))
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	h := NewHandler()
+	// a large input runs long enough to be cancelled while running
+	id := data(t, call(t, h.StartJob, "highlightCode", strings.Repeat("x ", 500000), "go"))["id"]
+	deadline := time.Now().Add(5 * time.Second)
+	for data(t, call(t, h.JobStatus, id))["status"] == jobPending && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	data(t, call(t, h.CancelJob, id))
+	if status := waitJob(t, h, id); status["status"] != jobCancelled {
+		t.Errorf("status = %v", status)
+	}
+
+	// a cancelled job no longer counts against the active limit
+	h.mu.Lock()
+	j, _ := lookupHandle[*job](h, int(id.(float64)))
+	busy := j.busy()
+	h.mu.Unlock()
+	if busy {
+		t.Error("cancelled job is still busy")
+	}
+}
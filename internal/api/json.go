@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// CanonicalizeJSON produces RFC 8785 canonical JSON for content addressing
+func (h *Handler) CanonicalizeJSON(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No JSON string provided")
+	}
+
+	result, err := h.processor.CanonicalizeJSON(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "JSON canonicalized")
+}
+
+// jsonArg returns a JSON string argument as is, or encodes an object or array
+// argument to JSON. Strings over the default JSON limits are rejected before
+// they are decoded.
+func jsonArg(v js.Value) (string, error) {
+	if v.Type() == js.TypeString {
+		s := v.String()
+		if _, err := core.CheckJSON(s, core.DefaultJSONLimits); err != nil {
+			var limitErr *core.JSONLimitError
+			if errors.As(err, &limitErr) {
+				return "", limitErr
+			}
+		}
+		return s, nil
+	}
+	encoded, err := json.Marshal(fromJSValue(v))
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// MergeJSON applies an RFC 7386 merge patch. Both arguments may be JSON
+// strings or objects.
+func (h *Handler) MergeJSON(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a target document and a merge patch")
+	}
+
+	target, err := jsonArg(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	patch, err := jsonArg(inputs[1])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.MergeJSON(target, patch)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Merge patch applied")
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch. The document is left
+// unchanged if any operation fails.
+func (h *Handler) ApplyJSONPatch(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a document and a patch")
+	}
+
+	document, err := jsonArg(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	patch, err := jsonArg(inputs[1])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.ApplyJSONPatch(document, patch)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "JSON Patch applied")
+}
+
+// ThreeWayMerge merges local and remote edits of a base document. Each
+// argument may be a JSON string or an object. Conflicting paths are listed
+// in the result with the base, local and remote values.
+func (h *Handler) ThreeWayMerge(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 {
+		return h.errorResponse("Requires base, local and remote documents")
+	}
+
+	docs := make([]string, 3)
+	for i := range docs {
+		doc, err := jsonArg(inputs[i])
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		docs[i] = doc
+	}
+
+	result, err := h.processor.ThreeWayMerge(docs[0], docs[1], docs[2])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Documents merged")
+}
+
+// ValidateJSONSafe checks a JSON string against nesting depth, size and
+// container element limits without decoding it. The optional second argument
+// overrides the defaults with maxDepth, maxSize and maxElements (0 disables a
+// limit).
+func (h *Handler) ValidateJSONSafe(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("JSON string required")
+	}
+
+	limits := core.DefaultJSONLimits
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		for key, limit := range map[string]*int{
+			"maxDepth":    &limits.MaxDepth,
+			"maxSize":     &limits.MaxSize,
+			"maxElements": &limits.MaxElements,
+		} {
+			if v := inputs[1].Get(key); v.Type() == js.TypeNumber {
+				*limit = v.Int()
+			}
+		}
+	}
+
+	input := inputs[0].String()
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ValidateJSONSafe(ctx, input, limits)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "JSON checked")
+}
+
+// filterArgs reads the predicate and options of FilterJSONArray
+func filterArgs(inputs []js.Value) (predicate *core.JSONPredicate, limit int, countOnly bool) {
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		p := inputs[1]
+		predicate = &core.JSONPredicate{Op: "eq"}
+		if v := p.Get("path"); v.Type() == js.TypeString {
+			predicate.Path = v.String()
+		}
+		if v := p.Get("op"); v.Type() == js.TypeString {
+			predicate.Op = v.String()
+		}
+		predicate.Value = fromJSValue(p.Get("value"))
+	}
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeObject {
+		if v := inputs[2].Get("limit"); v.Type() == js.TypeNumber {
+			limit = v.Int()
+		}
+		countOnly = inputs[2].Get("countOnly").Truthy()
+	}
+	return predicate, limit, countOnly
+}
+
+// FilterJSONArray streams a JSON array string element by element, counting
+// and collecting the elements that match a predicate without decoding the
+// whole array. The optional predicate is {path, op, value} where path is a
+// JSON pointer and op is exists, eq, ne, gt, gte, lt, lte, contains,
+// matches or in; the optional options object takes limit (maximum items
+// returned) and countOnly.
+func (h *Handler) FilterJSONArray(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("JSON array string required")
+	}
+
+	predicate, limit, countOnly := filterArgs(inputs)
+	input := inputs[0].String()
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.FilterJSONArray(ctx, input, predicate, limit, countOnly)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, fmt.Sprintf("%v of %v elements matched", result["matched"], result["scanned"]))
+}
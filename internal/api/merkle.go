@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// merkleItems reads the array of items a Merkle tree is built over
+func merkleItems(v js.Value) ([]interface{}, bool) {
+	items, ok := fromJSValue(v).([]interface{})
+	return items, ok
+}
+
+// MerkleRoot returns the Merkle root of an array of items. Strings are
+// hashed as UTF-8 and other values as canonical JSON. The optional options
+// object takes levels, which also returns every level of hashes.
+func (h *Handler) MerkleRoot(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Items array required")
+	}
+	items, ok := merkleItems(inputs[0])
+	if !ok {
+		return h.errorResponse("Items must be an array")
+	}
+
+	includeLevels := false
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		includeLevels = inputs[1].Get("levels").Truthy()
+	}
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.MerkleRoot(ctx, items, includeLevels)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Merkle root computed")
+}
+
+// MerkleProof returns the inclusion proof of the item at an index
+func (h *Handler) MerkleProof(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeNumber {
+		return h.errorResponse("Items array and index required")
+	}
+	items, ok := merkleItems(inputs[0])
+	if !ok {
+		return h.errorResponse("Items must be an array")
+	}
+	index := inputs[1].Int()
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.MerkleProof(ctx, items, index)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Merkle proof generated")
+}
+
+// MerkleVerify checks an item against a root using the proof array from
+// merkleProof
+func (h *Handler) MerkleVerify(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 || inputs[2].Type() != js.TypeString {
+		return h.errorResponse("Item, proof and root required")
+	}
+	proof, ok := fromJSValue(inputs[1]).([]interface{})
+	if !ok {
+		return h.errorResponse("Proof must be an array")
+	}
+
+	result, err := h.processor.MerkleVerify(fromJSValue(inputs[0]), proof, inputs[2].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Merkle proof checked")
+}
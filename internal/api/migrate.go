@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// jsMigration adapts a JavaScript function to a core migration. The
+// function receives the state and returns the upgraded state, or nothing if
+// it modified its argument in place.
+func jsMigration(fn js.Value) core.Migration {
+	return func(state map[string]interface{}) (result map[string]interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		arg := toJSValue(state)
+		out := fn.Invoke(arg)
+		if out.IsUndefined() || out.IsNull() {
+			out = arg
+		}
+		result, ok := fromJSValue(out).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("migration must return an object")
+		}
+		return result, nil
+	}
+}
+
+// RegisterMigration registers the function that upgrades stored state from
+// version-1 to version
+func (h *Handler) RegisterMigration(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[0].Type() != js.TypeNumber || inputs[1].Type() != js.TypeFunction {
+		return h.errorResponse("Requires a version number and a migration function")
+	}
+
+	if err := h.migrations.Register(inputs[0].Int(), jsMigration(inputs[1])); err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"version":    inputs[0].Int(),
+		"migrations": h.migrations.Versions(),
+	}, "Migration registered")
+}
+
+// Migrate upgrades a state object (or JSON string) from one schema version
+// to another by running the registered migrations in order. When the from
+// version is omitted it is read from the state's schemaVersion field,
+// defaulting to 0.
+func (h *Handler) Migrate(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeNumber {
+		return h.errorResponse("Requires a state and a target version number")
+	}
+	to := inputs[1].Int()
+
+	data, err := jsonArg(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	var state map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &state); err != nil || state == nil {
+		return h.errorResponse("State must be a JSON object")
+	}
+
+	from := 0
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeNumber {
+		from = inputs[2].Int()
+	} else if v, ok := state[core.SchemaVersionKey].(float64); ok {
+		from = int(v)
+	}
+
+	result, err := h.migrations.MigrateState(state, from, to)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, fmt.Sprintf("Migrated to version %d", to))
+}
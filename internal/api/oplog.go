@@ -0,0 +1,126 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// optionalJSONArg returns the JSON form of an argument, or "" when it is
+// missing, null or undefined
+func optionalJSONArg(inputs []js.Value, i int) (string, error) {
+	if len(inputs) <= i || inputs[i].IsNull() || inputs[i].IsUndefined() {
+		return "", nil
+	}
+	return jsonArg(inputs[i])
+}
+
+// OpLogAppend appends an operation to a log and returns the updated log.
+// Arguments are the log (JSON string, object or null for a new log), the
+// replica ID and the operation data.
+func (h *Handler) OpLogAppend(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 {
+		return h.errorResponse("Requires a log, replica ID and operation data")
+	}
+
+	log, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.OpLogAppend(log, inputs[1].String(), fromJSValue(inputs[2]))
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Operation appended")
+}
+
+// OpLogSince returns the operations a replica at the given vector clock has
+// not seen yet
+func (h *Handler) OpLogSince(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No log provided")
+	}
+
+	log, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	clock, err := optionalJSONArg(inputs, 1)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.OpLogSince(log, clock)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Operations retrieved")
+}
+
+// OpLogMerge combines two logs, deduplicating operations and ordering them
+// causally
+func (h *Handler) OpLogMerge(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires two logs")
+	}
+
+	a, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	b, err := optionalJSONArg(inputs, 1)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.OpLogMerge(a, b)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Logs merged")
+}
+
+// OpLogDelta returns the operations missing from a replica's clock as
+// base64 gzipped JSON, along with the bytes saved versus the full log
+func (h *Handler) OpLogDelta(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No log provided")
+	}
+
+	log, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	have, err := optionalJSONArg(inputs, 1)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.OpLogDelta(log, have)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Delta encoded")
+}
+
+// OpLogApplyDelta merges an encoded delta from opLogDelta into a log
+func (h *Handler) OpLogApplyDelta(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a log and an encoded delta")
+	}
+
+	log, err := optionalJSONArg(inputs, 0)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.OpLogApplyDelta(log, inputs[1].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Delta applied")
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// outboxStorageKey is the localStorage key the outbox is saved under
+const outboxStorageKey = "local-first:outbox"
+
+// outboxState is the outbound request queue and its flush bookkeeping
+type outboxState struct {
+	queue    *core.Outbox
+	flushing bool
+	onOnline js.Func
+}
+
+// navigatorOnline reports navigator.onLine, assuming online where the
+// runtime has no navigator
+func navigatorOnline() bool {
+	nav := js.Global().Get("navigator")
+	if !nav.Truthy() || nav.Get("onLine").Type() != js.TypeBoolean {
+		return true
+	}
+	return nav.Get("onLine").Bool()
+}
+
+// loadOutbox returns the queue, restoring it from localStorage and
+// installing the reconnect listener on first use. A queue left over from
+// an earlier page load is flushed straight away when online. Callers hold
+// h.mu.
+func (h *Handler) loadOutbox() (*core.Outbox, error) {
+	if h.outbox.queue != nil {
+		return h.outbox.queue, nil
+	}
+
+	saved := ""
+	if ls, ok := localStorage(); ok {
+		if v := ls.Call("getItem", outboxStorageKey); v.Type() == js.TypeString {
+			saved = v.String()
+		}
+	}
+	queue, err := core.DecodeOutbox(saved)
+	if err != nil {
+		return nil, err
+	}
+	h.outbox.queue = queue
+
+	if add := js.Global().Get("addEventListener"); add.Type() == js.TypeFunction {
+		h.outbox.onOnline = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			go h.flushOutbox()
+			return nil
+		})
+		js.Global().Call("addEventListener", "online", h.outbox.onOnline)
+	}
+	if len(queue.Entries) > 0 && navigatorOnline() {
+		go h.flushOutbox()
+	}
+	return queue, nil
+}
+
+// saveOutbox writes the queue to localStorage. Callers hold h.mu.
+func (h *Handler) saveOutbox() error {
+	ls, ok := localStorage()
+	if !ok {
+		// the queue still works, it just won't survive a reload
+		return nil
+	}
+	data, err := h.outbox.queue.Encode()
+	if err != nil {
+		return err
+	}
+	ls.Call("setItem", outboxStorageKey, data)
+	return nil
+}
+
+// flushOutbox sends queued requests in order until the queue is empty or a
+// request fails to get a response or gets a retryable status; those stay
+// at the head so order is preserved. Other 4xx responses will never
+// succeed and are dropped as rejected. Only one flush runs at a time.
+func (h *Handler) flushOutbox() map[string]interface{} {
+	h.mu.Lock()
+	queue, err := h.loadOutbox()
+	if err != nil {
+		h.mu.Unlock()
+		return map[string]interface{}{"sent": 0, "rejected": []interface{}{}, "error": err.Error()}
+	}
+	if h.outbox.flushing {
+		remaining := len(queue.Entries)
+		h.mu.Unlock()
+		return map[string]interface{}{"sent": 0, "rejected": []interface{}{}, "remaining": remaining, "inProgress": true}
+	}
+	h.outbox.flushing = true
+	h.mu.Unlock()
+
+	sent := 0
+	rejected := []interface{}{}
+	var stopped string
+	for {
+		h.mu.Lock()
+		next := queue.Next()
+		h.mu.Unlock()
+		if next == nil {
+			break
+		}
+
+		headers := map[string]string{"Idempotency-Key": next.ID}
+		for name, value := range next.Headers {
+			headers[name] = value
+		}
+		req := &fetchRequest{
+			url:     next.URL,
+			method:  next.Method,
+			headers: headers,
+			body:    js.Undefined(),
+			timeout: 10 * time.Second,
+		}
+		if next.Body != "" {
+			req.body = js.ValueOf(next.Body)
+		}
+
+		resp, err := fetchOnce(req)
+		status := 0
+		if err == nil {
+			status = resp.Get("status").Int()
+		}
+
+		h.mu.Lock()
+		switch {
+		case err != nil:
+			stopped = err.Error()
+			queue.Fail(next.ID, stopped)
+		case core.RetryableStatus(status):
+			stopped = fmt.Sprintf("HTTP %d", status)
+			queue.Fail(next.ID, stopped)
+		case status >= 400:
+			queue.Ack(next.ID)
+			rejected = append(rejected, map[string]interface{}{
+				"id":     next.ID,
+				"url":    next.URL,
+				"status": status,
+			})
+		default:
+			queue.Ack(next.ID)
+			sent++
+		}
+		h.saveOutbox()
+		h.mu.Unlock()
+
+		if stopped != "" {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	h.outbox.flushing = false
+	remaining := len(queue.Entries)
+	h.mu.Unlock()
+
+	result := map[string]interface{}{
+		"sent":      sent,
+		"rejected":  rejected,
+		"remaining": remaining,
+	}
+	if stopped != "" {
+		result["error"] = stopped
+	}
+	return result
+}
+
+// EnqueueRequest queues a write request to send now or, when offline, once
+// connectivity returns. The options object takes url, method (default
+// POST), headers, body (a string, or an object sent as JSON), id (a
+// client-generated request ID; one is generated by default) and key (a
+// resource name: a newer request with the same key replaces a queued one).
+// The ID is sent as the Idempotency-Key header so servers can ignore
+// replays. The queue is saved to localStorage and survives reloads.
+func (h *Handler) EnqueueRequest(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeObject {
+		return h.errorResponse("Request options object required")
+	}
+
+	options := inputs[0]
+	req, err := parseFetchOptions(options)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	if options.Get("method").Type() != js.TypeString {
+		req.method = "POST"
+	}
+	if !req.body.IsUndefined() && req.body.Type() != js.TypeString {
+		return h.errorResponse("Queued request bodies must be strings or JSON-serializable objects")
+	}
+
+	entry := core.OutboxEntry{
+		Method:  req.method,
+		URL:     req.url,
+		Headers: req.headers,
+	}
+	if req.body.Type() == js.TypeString {
+		entry.Body = req.body.String()
+	}
+	if v := options.Get("id"); v.Type() == js.TypeString {
+		entry.ID = v.String()
+	} else {
+		entry.ID = h.processor.GenerateID("uuid")
+	}
+	if v := options.Get("key"); v.Type() == js.TypeString {
+		entry.Key = v.String()
+	}
+
+	h.mu.Lock()
+	queue, err := h.loadOutbox()
+	if err != nil {
+		h.mu.Unlock()
+		return h.errorResponse(err.Error())
+	}
+	duplicate, superseded, err := queue.Enqueue(entry)
+	if err == nil && !duplicate {
+		err = h.saveOutbox()
+	}
+	pending := len(queue.Entries)
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	online := navigatorOnline()
+	if online && !duplicate {
+		go h.flushOutbox()
+	}
+
+	result := map[string]interface{}{
+		"id":        entry.ID,
+		"duplicate": duplicate,
+		"pending":   pending,
+		"online":    online,
+	}
+	if superseded != nil {
+		result["superseded"] = superseded.ID
+	}
+
+	return h.successResponse(result, "Request queued")
+}
+
+// FlushOutbox sends queued requests now and returns a Promise resolving
+// with the number sent, those the server rejected with a 4xx, and how
+// many remain. Queued requests are also flushed automatically on load and
+// when the browser comes back online.
+func (h *Handler) FlushOutbox(this js.Value, inputs []js.Value) interface{} {
+	return newPromise(func() js.Value {
+		return h.successResponse(h.flushOutbox(), "Outbox flushed")
+	})
+}
+
+// OutboxStatus lists the queued requests without their bodies
+func (h *Handler) OutboxStatus(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	queue, err := h.loadOutbox()
+	if err != nil {
+		h.mu.Unlock()
+		return h.errorResponse(err.Error())
+	}
+	result := queue.Summary()
+	result["flushing"] = h.outbox.flushing
+	h.mu.Unlock()
+	result["online"] = navigatorOnline()
+
+	return h.successResponse(result, "Outbox status")
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// ExtractOutline returns the headings of a Markdown or HTML document as a
+// nested outline with anchor slugs for a table of contents. The optional
+// format is markdown, html or auto (the default).
+func (h *Handler) ExtractOutline(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Document string required")
+	}
+	format := ""
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeString {
+		format = inputs[1].String()
+	}
+
+	result, err := h.processor.ExtractOutline(inputs[0].String(), format)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Outline extracted")
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// Pivot builds a pivot table from an array of objects, with a row per
+// value of rowField, a column per value of columnField and the aggregate
+// of valueField in each cell, plus row, column and grand totals. The
+// optional options object takes aggregate (sum, count, mean, min or max;
+// default sum) and fill ("zero" or "null", default "null") for
+// combinations without values. valueField may be empty with count.
+func (h *Handler) Pivot(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 || inputs[1].Type() != js.TypeString || inputs[2].Type() != js.TypeString {
+		return h.errorResponse("Requires an array of objects, a row field and a column field")
+	}
+	rows, ok := fromJSValue(inputs[0]).([]interface{})
+	if !ok {
+		return h.errorResponse("Rows must be an array")
+	}
+	valueField := ""
+	if len(inputs) > 3 && inputs[3].Type() == js.TypeString {
+		valueField = inputs[3].String()
+	}
+
+	aggregate, fillZero := "", false
+	if len(inputs) > 4 && inputs[4].Type() == js.TypeObject {
+		if v := inputs[4].Get("aggregate"); v.Type() == js.TypeString {
+			aggregate = v.String()
+		}
+		if v := inputs[4].Get("fill"); v.Type() == js.TypeString {
+			switch v.String() {
+			case "zero":
+				fillZero = true
+			case "null":
+			default:
+				return h.errorResponse("Fill must be \"zero\" or \"null\"")
+			}
+		}
+	}
+
+	result, err := h.processor.Pivot(rows, inputs[1].String(), inputs[2].String(), valueField, aggregate, fillZero)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Pivot table built")
+}
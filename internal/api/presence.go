@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"syscall/js"
+)
+
+// presenceConn is a presence-only WebSocket connection to /ws/sync
+type presenceConn struct {
+	ws      js.Value
+	open    bool
+	pending string // latest state published before the socket opened
+	funcs   []js.Func
+}
+
+// close closes the socket and releases its event callbacks
+func (p *presenceConn) close() {
+	p.ws.Call("close")
+	for _, fn := range p.funcs {
+		fn.Release()
+	}
+	p.funcs = nil
+}
+
+// release closes the connection when its handle is evicted or cleaned up
+func (p *presenceConn) release() {
+	p.close()
+}
+
+// busy keeps an open connection from being evicted as idle
+func (p *presenceConn) busy() bool {
+	return p.open
+}
+
+// PresenceConnect joins the presence channel of a document on a sync server.
+// The callback receives each "peers" and "presence" message as an object; a
+// peer that disconnects is reported with left set to true.
+func (h *Handler) PresenceConnect(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 || inputs[2].Type() != js.TypeFunction {
+		return h.errorResponse("Requires a server URL, document ID and callback")
+	}
+
+	base, err := url.Parse(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(fmt.Sprintf("Invalid server URL: %v", err))
+	}
+	switch base.Scheme {
+	case "http":
+		base.Scheme = "ws"
+	case "https":
+		base.Scheme = "wss"
+	}
+	base.Path = "/ws/sync"
+	base.RawQuery = url.Values{"doc": {inputs[1].String()}, "ops": {"false"}}.Encode()
+
+	ws := js.Global().Get("WebSocket").New(base.String())
+	callback := inputs[2]
+	conn := &presenceConn{ws: ws}
+
+	onOpen := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		h.mu.Lock()
+		conn.open = true
+		pending := conn.pending
+		conn.pending = ""
+		h.mu.Unlock()
+
+		if pending != "" {
+			ws.Call("send", pending)
+		}
+		return nil
+	})
+	onMessage := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		data := args[0].Get("data")
+		if data.Type() != js.TypeString {
+			return nil
+		}
+		msg := js.Global().Get("JSON").Call("parse", data)
+		if t := msg.Get("type").String(); t == "peers" || t == "presence" {
+			callback.Invoke(msg)
+		}
+		return nil
+	})
+	onClose := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		h.mu.Lock()
+		conn.open = false
+		h.mu.Unlock()
+		return nil
+	})
+	conn.funcs = []js.Func{onOpen, onMessage, onClose}
+	ws.Set("onopen", onOpen)
+	ws.Set("onmessage", onMessage)
+	ws.Set("onclose", onClose)
+
+	h.mu.Lock()
+	handle, err := h.handles.add(conn)
+	h.mu.Unlock()
+	if err != nil {
+		conn.close()
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"handle": handle,
+		"url":    base.String(),
+	}, "Presence connecting")
+}
+
+// PresencePublish shares this client's presence state, such as a cursor or
+// selection, with the other clients of the document. Passing null clears it.
+// States published before the connection opens are sent once it does.
+func (h *Handler) PresencePublish(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and a state")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	state, err := json.Marshal(fromJSValue(inputs[1]))
+	if err != nil {
+		return h.errorResponse(fmt.Sprintf("Invalid state: %v", err))
+	}
+	msg, _ := json.Marshal(map[string]interface{}{
+		"type":  "presence",
+		"state": json.RawMessage(state),
+	})
+
+	h.mu.Lock()
+	conn, ok := lookupHandle[*presenceConn](h, handle)
+	if !ok {
+		h.mu.Unlock()
+		return h.errorResponse(fmt.Sprintf("Unknown presence handle: %d", handle))
+	}
+	open := conn.open
+	if !open {
+		conn.pending = string(msg)
+	}
+	h.mu.Unlock()
+
+	if open {
+		conn.ws.Call("send", string(msg))
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"sent":  open,
+		"bytes": len(state),
+	}, "Presence published")
+}
+
+// PresenceDisconnect leaves the presence channel; peers see the client leave
+func (h *Handler) PresenceDisconnect(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	_, ok := releaseHandle[*presenceConn](h, handle)
+	h.mu.Unlock()
+
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown presence handle: %d", handle))
+	}
+
+	return h.successResponse(nil, "Presence disconnected")
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// sketchBuckets reads the optional buckets option
+func sketchBuckets(inputs []js.Value, index int) int {
+	if len(inputs) > index && inputs[index].Type() == js.TypeObject {
+		if v := inputs[index].Get("buckets"); v.Type() == js.TypeNumber {
+			return v.Int()
+		}
+	}
+	return core.DefaultSketchBuckets
+}
+
+// SetSketch summarizes an object mapping item IDs to versions (a hash,
+// timestamp or any JSON value) into per-bucket hashes to send to another
+// replica. The optional options object takes buckets (default 128).
+func (h *Handler) SetSketch(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Items object required")
+	}
+	items, ok := fromJSValue(inputs[0]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Items must be an object mapping IDs to versions")
+	}
+	buckets := sketchBuckets(inputs, 1)
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.SetSketch(ctx, items, buckets)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Set sketch built")
+}
+
+// ReconcileSets compares local items with another replica's setSketch
+// result, returning the local IDs in differing buckets, or with its full
+// items object, returning the IDs only on each side and those whose
+// versions differ. The optional options object takes buckets for the
+// second form.
+func (h *Handler) ReconcileSets(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Local items and a remote sketch or items required")
+	}
+	local, ok := fromJSValue(inputs[0]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Local items must be an object mapping IDs to versions")
+	}
+	remote, ok := fromJSValue(inputs[1]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Remote must be a sketch or an items object")
+	}
+	buckets := sketchBuckets(inputs, 2)
+
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ReconcileSets(ctx, local, remote, buckets)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Sets reconciled")
+}
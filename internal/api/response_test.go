@@ -0,0 +1,91 @@
+package api
+
+import (
+	"math"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestSetResponseMode(t *testing.T) {
+	h := NewHandler()
+
+	tests := []struct {
+		name    string
+		args    []interface{}
+		mode    string
+		wantErr bool
+	}{
+		{name: "report default", mode: "object"},
+		{name: "json", args: []interface{}{"json"}, mode: "json"},
+		{name: "report json", mode: "json"},
+		{name: "unknown mode", args: []interface{}{"xml"}, wantErr: true},
+		{name: "not a string", args: []interface{}{1.0}, wantErr: true},
+		{name: "object", args: []interface{}{"object"}, mode: "object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := call(t, h.SetResponseMode, tt.args...)
+			if tt.wantErr {
+				failed(t, response)
+				return
+			}
+			if got := data(t, response)["mode"]; got != tt.mode {
+				t.Errorf("mode = %v, want %s", got, tt.mode)
+			}
+		})
+	}
+}
+
+func TestJSONResponses(t *testing.T) {
+	h := NewHandler()
+	call(t, h.SetResponseMode, "json")
+
+	raw := h.FormatJSON(js.Undefined(), []js.Value{js.ValueOf(`{"b":1,"a":[1,2]}`)}).(js.Value)
+	if raw.Type() != js.TypeString {
+		t.Fatalf("response type = %v, want string", raw.Type())
+	}
+	if d := data(t, call(t, h.FormatJSON, `{"b":1}`)); d["valid"] != true {
+		t.Errorf("data = %v", d)
+	}
+
+	// A result JSON cannot encode becomes an error response
+	response := h.response(map[string]interface{}{"success": true, "data": math.Inf(1), "timestamp": 1})
+	if s := response.String(); !strings.Contains(s, `"success":false`) || !strings.Contains(s, "cannot be encoded") {
+		t.Errorf("response = %s", s)
+	}
+
+	call(t, h.SetResponseMode, "object")
+	if raw := h.FormatJSON(js.Undefined(), []js.Value{js.ValueOf(`{}`)}).(js.Value); raw.Type() != js.TypeObject {
+		t.Errorf("response type = %v, want object", raw.Type())
+	}
+}
+
+// BenchmarkResponseMode compares returning a large result as an object
+// with returning it as a JSON string, including the JSON.parse a caller
+// then makes
+func BenchmarkResponseMode(b *testing.B) {
+	results := []struct {
+		name string
+		data interface{}
+	}{
+		{"objects", objectSlice(1000)},
+		{"strings", stringSlice(2000)},
+		{"numbers", numberSlice(5000)},
+	}
+	for _, r := range results {
+		for _, mode := range []string{"object", "json"} {
+			h := NewHandler()
+			h.jsonResponses = mode == "json"
+			b.Run(r.name+"/"+mode, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					response := h.successResponse(r.data, "done")
+					if h.jsonResponses {
+						jsonParse.Invoke(response)
+					}
+				}
+			})
+		}
+	}
+}
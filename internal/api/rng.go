@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// maxRNGBatch bounds how many floats RNGFloat returns in one call
+const maxRNGBatch = 100000
+
+// lookupRNG returns the generator for the handle in inputs[0]. Callers
+// must hold h.mu.
+func (h *Handler) lookupRNG(inputs []js.Value) (*core.RNG, error) {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return nil, err
+	}
+	rng, ok := lookupHandle[*core.RNG](h, handle)
+	if !ok {
+		return nil, fmt.Errorf("Unknown RNG handle: %d", handle)
+	}
+	return rng, nil
+}
+
+// RNGSeed creates a deterministic generator from an integer or string seed
+// and returns its handle. The same seed replays the same sequence on every
+// platform.
+func (h *Handler) RNGSeed(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Seed required")
+	}
+
+	var seed uint64
+	switch inputs[0].Type() {
+	case js.TypeNumber:
+		f := inputs[0].Float()
+		if f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+			return h.errorResponse("Numeric seed must be a safe integer")
+		}
+		seed = uint64(int64(f))
+	case js.TypeString:
+		seed = core.StringSeed(inputs[0].String())
+	default:
+		return h.errorResponse("Seed must be an integer or a string")
+	}
+
+	h.mu.Lock()
+	handle, err := h.handles.add(core.NewRNG(seed))
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"handle": handle,
+	}, "RNG seeded")
+}
+
+// RNGFloat returns the next number in [0, 1), or an array of them when a
+// count is given
+func (h *Handler) RNGFloat(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Handle required")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rng, err := h.lookupRNG(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeNumber {
+		return h.successResponse(map[string]interface{}{
+			"value": rng.Float64(),
+		}, "Random float generated")
+	}
+
+	count := inputs[1].Int()
+	if count < 0 || count > maxRNGBatch {
+		return h.errorResponse(fmt.Sprintf("Count must be between 0 and %d", maxRNGBatch))
+	}
+	values := make([]interface{}, count)
+	for i := range values {
+		values[i] = rng.Float64()
+	}
+	return h.successResponse(map[string]interface{}{
+		"values": values,
+	}, fmt.Sprintf("Generated %d random floats", count))
+}
+
+// RNGInt returns the next integer in [min, max], both inclusive
+func (h *Handler) RNGInt(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 {
+		return h.errorResponse("Requires a handle, min and max")
+	}
+	lo, err := jsFloat(inputs, 1, "Min")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	hi, err := jsFloat(inputs, 2, "Max")
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rng, err := h.lookupRNG(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	value, err := rng.IntRange(int64(lo), int64(hi))
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"value": value,
+	}, "Random integer generated")
+}
+
+// RNGShuffle returns a shuffled copy of an array
+func (h *Handler) RNGShuffle(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and an array")
+	}
+
+	items, ok := fromJSValue(inputs[1]).([]interface{})
+	if !ok {
+		return h.errorResponse("Input must be an array")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rng, err := h.lookupRNG(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	rng.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	return h.successResponse(map[string]interface{}{
+		"values": items,
+	}, fmt.Sprintf("Shuffled %d items", len(items)))
+}
+
+// RNGRelease frees a generator
+func (h *Handler) RNGRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := releaseHandle[*core.RNG](h, handle); !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown RNG handle: %d", handle))
+	}
+
+	return h.successResponse(nil, "RNG released")
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// EvaluateRules checks a data object against an array of business rules,
+// each {field, op, value or valueField, message, id}. Fields are dotted
+// paths or JSON pointers; op is eq, ne, gt, gte, lt, lte, contains,
+// matches, in, exists or missing. valueField compares with another field
+// of the same object, for rules such as an end date after a start date.
+func (h *Handler) EvaluateRules(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a data object and an array of rules")
+	}
+	rules, ok := fromJSValue(inputs[1]).([]interface{})
+	if !ok {
+		return h.errorResponse("Rules must be an array")
+	}
+
+	result, err := h.processor.EvaluateRules(fromJSValue(inputs[0]), rules)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Rules evaluated")
+}
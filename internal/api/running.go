@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// StatsInit creates a running statistics accumulator and returns its handle
+func (h *Handler) StatsInit(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	handle, err := h.handles.add(&core.RunningStats{})
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"handle": handle,
+	}, "Statistics accumulator created")
+}
+
+// StatsAdd adds a number, or an array of numbers, to an accumulator
+func (h *Handler) StatsAdd(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a handle and a value")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	var values []float64
+	switch inputs[1].Type() {
+	case js.TypeNumber:
+		values = []float64{inputs[1].Float()}
+	default:
+		nums, err := jsNumbers(inputs[1])
+		if err != nil {
+			return h.errorResponse("Value must be a number or an array of numbers")
+		}
+		values = nums
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	acc, ok := lookupHandle[*core.RunningStats](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown statistics handle: %d", handle))
+	}
+	for _, v := range values {
+		acc.Add(v)
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"count": acc.Count(),
+	}, fmt.Sprintf("Added %d values", len(values)))
+}
+
+// StatsResult returns the current statistics of an accumulator
+func (h *Handler) StatsResult(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	acc, ok := lookupHandle[*core.RunningStats](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown statistics handle: %d", handle))
+	}
+
+	return h.successResponse(acc.Result(), "Statistics retrieved")
+}
+
+// StatsRelease frees an accumulator
+func (h *Handler) StatsRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	releaseHandle[*core.RunningStats](h, handle)
+	h.mu.Unlock()
+
+	return h.successResponse(nil, "Statistics accumulator released")
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+)
+
+// InferSchema describes the columns of a JSON array of objects. The input
+// may be a JSON string or an array; an optional second argument sets the
+// number of rows sampled.
+func (h *Handler) InferSchema(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No data provided")
+	}
+
+	var rows []interface{}
+	if inputs[0].Type() == js.TypeString {
+		if err := json.Unmarshal([]byte(inputs[0].String()), &rows); err != nil {
+			return h.errorResponse(fmt.Sprintf("Invalid JSON array: %v", err))
+		}
+	} else {
+		arr, ok := fromJSValue(inputs[0]).([]interface{})
+		if !ok {
+			return h.errorResponse("Input must be an array of objects")
+		}
+		rows = arr
+	}
+
+	sampleSize := 0
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeNumber {
+		sampleSize = inputs[1].Int()
+	}
+
+	result, err := h.processor.InferSchema(rows, sampleSize)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Schema inferred")
+}
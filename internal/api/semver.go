@@ -0,0 +1,34 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// ParseSemVer validates a SemVer 2.0.0 string and returns its components
+func (h *Handler) ParseSemVer(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Version string required")
+	}
+
+	result, err := h.processor.ParseSemVer(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Version parsed")
+}
+
+// CompareSemVer returns -1, 0 or 1 as the first version has lower, equal
+// or higher precedence than the second
+func (h *Handler) CompareSemVer(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[0].Type() != js.TypeString || inputs[1].Type() != js.TypeString {
+		return h.errorResponse("Two version strings required")
+	}
+
+	result, err := h.processor.CompareSemVer(inputs[0].String(), inputs[1].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Versions compared")
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// MovingAverage computes simple and optional exponential moving averages
+func (h *Handler) MovingAverage(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a numeric array and window size")
+	}
+
+	values, err := jsNumbers(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	alpha := 0.0
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeNumber {
+		alpha = inputs[2].Float()
+	}
+
+	result, err := h.processor.MovingAverage(values, inputs[1].Int(), alpha)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Moving average calculated")
+}
+
+// Downsample reduces a series of points with the LTTB algorithm. Points may
+// be given as [x, y] pairs or {x, y} objects.
+func (h *Handler) Downsample(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires an array of points and a target count")
+	}
+
+	raw, ok := fromJSValue(inputs[0]).([]interface{})
+	if !ok {
+		return h.errorResponse("Points must be an array")
+	}
+
+	points := make([]core.Point, len(raw))
+	for i, item := range raw {
+		p, err := toPoint(item)
+		if err != nil {
+			return h.errorResponse(fmt.Sprintf("Point %d: %v", i, err))
+		}
+		points[i] = p
+	}
+
+	result, err := h.processor.Downsample(points, inputs[1].Int())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, fmt.Sprintf("Downsampled to %d points", result["count"]))
+}
+
+func toPoint(item interface{}) (core.Point, error) {
+	switch v := item.(type) {
+	case []interface{}:
+		if len(v) == 2 {
+			x, xok := v[0].(float64)
+			y, yok := v[1].(float64)
+			if xok && yok {
+				return core.Point{X: x, Y: y}, nil
+			}
+		}
+	case map[string]interface{}:
+		x, xok := v["x"].(float64)
+		y, yok := v["y"].(float64)
+		if xok && yok {
+			return core.Point{X: x, Y: y}, nil
+		}
+	}
+	return core.Point{}, fmt.Errorf("expected [x, y] or {x, y} with numeric values")
+}
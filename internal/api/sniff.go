@@ -0,0 +1,21 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// SniffTabular guesses the dialect of pasted tabular text (delimiter,
+// header row, column count and quoting) with a confidence score, plus a
+// preview of the first records parsed with that dialect
+func (h *Handler) SniffTabular(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Text required")
+	}
+
+	result, err := h.processor.SniffTabular(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Tabular format detected")
+}
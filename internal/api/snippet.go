@@ -0,0 +1,45 @@
+package api
+
+import (
+	"strings"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// Snippet returns a search-result excerpt of text centred on the first
+// match of the query, with matches highlighted. The query is a string of
+// space-separated terms or an array of terms, which may contain spaces.
+// maxLength defaults to 160 characters.
+func (h *Handler) Snippet(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Requires text and a query")
+	}
+
+	var terms []string
+	switch inputs[1].Type() {
+	case js.TypeString:
+		terms = strings.Fields(inputs[1].String())
+	case js.TypeObject:
+		if !inputs[1].InstanceOf(js.Global().Get("Array")) {
+			return h.errorResponse("Query must be a string or an array of terms")
+		}
+		for i := 0; i < inputs[1].Length(); i++ {
+			terms = append(terms, inputs[1].Index(i).String())
+		}
+	default:
+		return h.errorResponse("Query must be a string or an array of terms")
+	}
+
+	maxLength := core.DefaultSnippetLength
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeNumber {
+		maxLength = inputs[2].Int()
+	}
+
+	result, err := h.processor.Snippet(inputs[0].String(), terms, maxLength)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Snippet generated")
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// FormatSQL reformats a SQL string with one clause per line. The optional
+// options object takes keywordCase ("upper" or "lower") and indent (spaces
+// per level, default 2).
+func (h *Handler) FormatSQL(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("SQL string required")
+	}
+
+	var opts core.SQLFormatOptions
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		if v := inputs[1].Get("keywordCase"); v.Type() == js.TypeString {
+			opts.KeywordCase = v.String()
+		}
+		if v := inputs[1].Get("indent"); v.Type() == js.TypeNumber {
+			opts.Indent = v.Int()
+		}
+	}
+
+	result, err := h.processor.FormatSQL(inputs[0].String(), opts)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "SQL formatted")
+}
+
+// AnalyzeSQL reports the statement type, tables, columns, CTE names and
+// parameter count of each statement in a SQL string
+func (h *Handler) AnalyzeSQL(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("SQL string required")
+	}
+
+	result, err := h.processor.AnalyzeSQL(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "SQL analyzed")
+}
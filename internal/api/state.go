@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// localStorage returns the page's localStorage, if it has one
+func localStorage() (js.Value, bool) {
+	ls := js.Global().Get("localStorage")
+	return ls, ls.Truthy()
+}
+
+// localStorageKeys lists the localStorage keys starting with prefix
+func localStorageKeys(ls js.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < ls.Get("length").Int(); i++ {
+		if key := ls.Call("key", i).String(); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportState snapshots the app's local data into one versioned blob. The
+// optional options object takes namespace (a localStorage key prefix;
+// defaults to every key), indexedDB (an object mapping store names to arrays
+// of records, since IndexedDB can only be read asynchronously by the page)
+// and compress (base64 gzipped JSON instead of plain JSON). Every live CRDT
+// handle is included.
+func (h *Handler) ExportState(this js.Value, inputs []js.Value) interface{} {
+	options := map[string]interface{}{}
+	if len(inputs) > 0 && inputs[0].Type() == js.TypeObject {
+		options, _ = fromJSValue(inputs[0]).(map[string]interface{})
+	}
+
+	snapshot := core.NewSnapshot()
+	snapshot.Namespace, _ = options["namespace"].(string)
+	compress, _ := options["compress"].(bool)
+
+	if stores, ok := options["indexedDB"]; ok {
+		storeMap, ok := stores.(map[string]interface{})
+		if !ok {
+			return h.errorResponse("indexedDB must map store names to arrays of records")
+		}
+		for name, records := range storeMap {
+			list, ok := records.([]interface{})
+			if !ok {
+				return h.errorResponse(fmt.Sprintf("indexedDB store %q must be an array of records", name))
+			}
+			snapshot.IndexedDB[name] = list
+		}
+	}
+
+	if ls, ok := localStorage(); ok {
+		for _, key := range localStorageKeys(ls, snapshot.Namespace) {
+			snapshot.LocalStorage[key] = ls.Call("getItem", key).String()
+		}
+	}
+
+	h.mu.Lock()
+	for handle, reg := range handlesOf[*core.LWWRegister](h) {
+		snapshot.Registers[strconv.Itoa(handle)] = reg
+	}
+	for handle, counter := range handlesOf[*core.Counter](h) {
+		snapshot.Counters[strconv.Itoa(handle)] = counter
+	}
+	for handle, set := range handlesOf[*core.ORSet](h) {
+		snapshot.ORSets[strconv.Itoa(handle)] = set
+	}
+	encoded, err := snapshot.Encode(compress)
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result := snapshot.Summary()
+	result["state"] = encoded
+	result["compressed"] = compress
+	result["bytes"] = len(encoded)
+
+	return h.successResponse(result, "State exported")
+}
+
+// ImportState restores a blob produced by ExportState. localStorage entries
+// are written back (with options.clear, keys in the snapshot's namespace are
+// removed first), each CRDT is restored under a new handle, and the
+// IndexedDB records are returned for the page to write. Snapshots from an
+// unsupported version are rejected before anything is changed.
+func (h *Handler) ImportState(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No state provided")
+	}
+
+	data, err := jsonArg(inputs[0])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	snapshot, err := core.DecodeSnapshot(data)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	room := h.handles.room()
+	h.mu.Unlock()
+	if need := len(snapshot.Registers) + len(snapshot.Counters) + len(snapshot.ORSets); need > room {
+		return h.errorResponse(fmt.Sprintf("%v: the snapshot needs %d handles but only %d are free", ErrTooManyHandles, need, room))
+	}
+
+	clear := false
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeObject {
+		clear = inputs[1].Get("clear").Truthy()
+	}
+
+	if len(snapshot.LocalStorage) > 0 || clear {
+		ls, ok := localStorage()
+		if !ok {
+			return h.errorResponse("localStorage is not available")
+		}
+		if clear {
+			for _, key := range localStorageKeys(ls, snapshot.Namespace) {
+				ls.Call("removeItem", key)
+			}
+		}
+		for key, value := range snapshot.LocalStorage {
+			ls.Call("setItem", key, value)
+		}
+	}
+
+	registers := map[string]interface{}{}
+	counters := map[string]interface{}{}
+	orsets := map[string]interface{}{}
+
+	// room was checked above, and nothing else runs in between
+	h.mu.Lock()
+	for old, reg := range snapshot.Registers {
+		registers[old], _ = h.handles.add(reg)
+	}
+	for old, counter := range snapshot.Counters {
+		counters[old], _ = h.handles.add(counter)
+	}
+	for old, set := range snapshot.ORSets {
+		orsets[old], _ = h.handles.add(set)
+	}
+	h.mu.Unlock()
+
+	indexedDB := make(map[string]interface{}, len(snapshot.IndexedDB))
+	for name, records := range snapshot.IndexedDB {
+		indexedDB[name] = records
+	}
+
+	result := snapshot.Summary()
+	result["indexedDB"] = indexedDB
+	result["handles"] = map[string]interface{}{
+		"registers": registers,
+		"counters":  counters,
+		"orsets":    orsets,
+	}
+
+	return h.successResponse(result, "State imported")
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// StripHTML converts an HTML string to readable plain text for previews and
+// search indexing. With options.links, links are written as "text (url)".
+func (h *Handler) StripHTML(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("HTML string required")
+	}
+	showLinks := len(inputs) > 1 && inputs[1].Type() == js.TypeObject && inputs[1].Get("links").Truthy()
+
+	result, err := h.processor.StripHTML(inputs[0].String(), showLinks)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "HTML stripped")
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// WrapText reflows text to a column width. The optional third argument is an
+// object with indent, hangingIndent and preserveParagraphs.
+func (h *Handler) WrapText(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires text and width")
+	}
+
+	var opts core.WrapOptions
+	if len(inputs) > 2 {
+		if options, ok := fromJSValue(inputs[2]).(map[string]interface{}); ok {
+			opts.Indent, _ = options["indent"].(string)
+			opts.HangingIndent, _ = options["hangingIndent"].(string)
+			opts.PreserveParagraphs, _ = options["preserveParagraphs"].(bool)
+		}
+	}
+
+	result, err := h.processor.WrapText(inputs[0].String(), inputs[1].Int(), opts)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Text wrapped")
+}
+
+// CountGraphemes reports grapheme, rune and byte counts and emoji usage
+func (h *Handler) CountGraphemes(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No text provided")
+	}
+
+	result, err := h.processor.AnalyzeGraphemes(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Graphemes counted")
+}
+
+// TextSimilarity compares two documents, or a document against an array of
+// documents using TF-IDF weighting
+func (h *Handler) TextSimilarity(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a document and a second document or corpus")
+	}
+
+	query := inputs[0].String()
+
+	if corpus, ok := fromJSValue(inputs[1]).([]interface{}); ok {
+		docs := make([]string, len(corpus))
+		for i, doc := range corpus {
+			s, ok := doc.(string)
+			if !ok {
+				return h.errorResponse("Corpus must be an array of strings")
+			}
+			docs[i] = s
+		}
+
+		result, err := h.processor.CorpusSimilarity(query, docs)
+		if err != nil {
+			return h.errorResponse(err.Error())
+		}
+		return h.successResponse(result, "Corpus similarity calculated")
+	}
+
+	result, err := h.processor.TextSimilarity(query, inputs[1].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Text similarity calculated")
+}
+
+// RedactPII replaces personal data in text with placeholders. The optional
+// second argument is an array of categories to redact.
+func (h *Handler) RedactPII(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No text provided")
+	}
+
+	var categories []string
+	if len(inputs) > 1 {
+		if list, ok := fromJSValue(inputs[1]).([]interface{}); ok {
+			for _, item := range list {
+				if c, ok := item.(string); ok {
+					categories = append(categories, c)
+				}
+			}
+		}
+	}
+
+	result, err := h.processor.RedactPII(inputs[0].String(), categories)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "PII redacted")
+}
+
+// ComputeTextPatch diffs two strings into offset-based insert/delete edits.
+// Offsets count Unicode code points, not UTF-16 units.
+func (h *Handler) ComputeTextPatch(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires the old and new text")
+	}
+
+	oldText, newText := inputs[0].String(), inputs[1].String()
+	result, err := core.RunWithTimeout(h.timeout, func(ctx context.Context) (map[string]interface{}, error) {
+		return h.processor.ComputeTextPatch(ctx, oldText, newText)
+	})
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Text patch computed")
+}
+
+// ApplyTextPatch applies a patch from ComputeTextPatch (JSON string or
+// object) to the text it was computed from
+func (h *Handler) ApplyTextPatch(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires a text and a patch")
+	}
+
+	patch, err := jsonArg(inputs[1])
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	result, err := h.processor.ApplyTextPatch(inputs[0].String(), patch)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Text patch applied")
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// TextAnalyzeInit creates an accumulator for text statistics over a
+// document fed in chunks and returns its handle
+func (h *Handler) TextAnalyzeInit(this js.Value, inputs []js.Value) interface{} {
+	h.mu.Lock()
+	handle, err := h.handles.add(core.NewTextAnalyzer())
+	h.mu.Unlock()
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"handle": handle,
+	}, "Text analyzer created")
+}
+
+// TextAnalyzeFeed adds the next chunk of text. Chunks may split words or
+// characters anywhere; only the counts and word frequencies are kept.
+func (h *Handler) TextAnalyzeFeed(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[1].Type() != js.TypeString {
+		return h.errorResponse("Requires a handle and a text chunk")
+	}
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	chunk := inputs[1].String()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := lookupHandle[*core.TextAnalyzer](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown text analyzer handle: %d", handle))
+	}
+	t.Feed(chunk)
+
+	return h.successResponse(map[string]interface{}{
+		"bytes": len(chunk),
+	}, "Chunk added")
+}
+
+// TextAnalyzeResult returns the statistics of the text fed so far in the
+// shape of processData, with an optional number of top words (default 5)
+func (h *Handler) TextAnalyzeResult(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+	top := 5
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeNumber {
+		top = inputs[1].Int()
+		if top < 0 {
+			return h.errorResponse("Top word count must not be negative")
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := lookupHandle[*core.TextAnalyzer](h, handle)
+	if !ok {
+		return h.errorResponse(fmt.Sprintf("Unknown text analyzer handle: %d", handle))
+	}
+
+	return h.successResponse(t.Result(top), "Text statistics retrieved")
+}
+
+// TextAnalyzeRelease frees a text analyzer
+func (h *Handler) TextAnalyzeRelease(this js.Value, inputs []js.Value) interface{} {
+	handle, err := jsHandle(inputs)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	h.mu.Lock()
+	releaseHandle[*core.TextAnalyzer](h, handle)
+	h.mu.Unlock()
+
+	return h.successResponse(nil, "Text analyzer released")
+}
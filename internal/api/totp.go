@@ -0,0 +1,53 @@
+package api
+
+import (
+	"syscall/js"
+	"time"
+)
+
+// GenerateTOTPSecret creates a new base32 secret and otpauth:// URI
+func (h *Handler) GenerateTOTPSecret(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires issuer and account name")
+	}
+
+	result, err := h.processor.GenerateTOTPSecret(inputs[0].String(), inputs[1].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "TOTP secret generated")
+}
+
+// GenerateTOTP returns the current TOTP code for a base32 secret
+func (h *Handler) GenerateTOTP(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No secret provided")
+	}
+
+	result, err := h.processor.GenerateTOTP(inputs[0].String(), time.Now())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "TOTP code generated")
+}
+
+// ValidateTOTP checks a code against a secret with an optional skew window
+func (h *Handler) ValidateTOTP(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 {
+		return h.errorResponse("Requires secret and code")
+	}
+
+	window := 1
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeNumber {
+		window = inputs[2].Int()
+	}
+
+	result, err := h.processor.ValidateTOTP(inputs[0].String(), inputs[1].String(), window, time.Now())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "TOTP validation complete")
+}
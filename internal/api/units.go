@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// ConvertUnits converts a value between length, weight, temperature or
+// currency units. Currency conversion needs a rates object giving the
+// amount of each currency per unit of a common base, e.g. {USD: 1, EUR: 0.9}.
+func (h *Handler) ConvertUnits(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 3 || inputs[0].Type() != js.TypeNumber ||
+		inputs[1].Type() != js.TypeString || inputs[2].Type() != js.TypeString {
+		return h.errorResponse("Value, from unit and to unit required")
+	}
+
+	var rates map[string]float64
+	if len(inputs) > 3 && inputs[3].Type() == js.TypeObject {
+		table, ok := fromJSValue(inputs[3]).(map[string]interface{})
+		if !ok {
+			return h.errorResponse("Rates must be an object of currency codes to numbers")
+		}
+		rates = make(map[string]float64, len(table))
+		for code, v := range table {
+			rate, ok := v.(float64)
+			if !ok {
+				return h.errorResponse(fmt.Sprintf("Rate for %s must be a number", code))
+			}
+			rates[code] = rate
+		}
+	}
+
+	result, err := h.processor.ConvertUnits(inputs[0].Float(), inputs[1].String(), inputs[2].String(), rates)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Units converted")
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"syscall/js"
+)
+
+// ParseURL parses a URL into its components
+func (h *Handler) ParseURL(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No URL provided")
+	}
+
+	result, err := h.processor.ParseURL(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "URL parsed")
+}
+
+// ParseQueryString decodes a query string into an object
+func (h *Handler) ParseQueryString(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No query string provided")
+	}
+
+	result, err := h.processor.ParseQueryString(inputs[0].String())
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Query string parsed")
+}
+
+// BuildQueryString encodes an object into a query string
+func (h *Handler) BuildQueryString(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("No parameters provided")
+	}
+
+	params, ok := fromJSValue(inputs[0]).(map[string]interface{})
+	if !ok {
+		return h.errorResponse("Parameters must be an object")
+	}
+
+	query, err := h.processor.BuildQueryString(params)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(map[string]interface{}{
+		"query": query,
+	}, "Query string built")
+}
+
+// ParseMultipart parses a multipart/form-data body. The second argument is
+// the Content-Type header or the bare boundary; the optional options object
+// takes includeFiles, which adds each file part's content as base64.
+func (h *Handler) ParseMultipart(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) < 2 || inputs[0].Type() != js.TypeString || inputs[1].Type() != js.TypeString {
+		return h.errorResponse("Body and Content-Type or boundary required")
+	}
+
+	includeFiles := len(inputs) > 2 && inputs[2].Type() == js.TypeObject && inputs[2].Get("includeFiles").Truthy()
+
+	result, err := h.processor.ParseMultipart(inputs[0].String(), inputs[1].String(), includeFiles)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Multipart body parsed")
+}
+
+// ParseFormURLEncoded parses an application/x-www-form-urlencoded body; the
+// optional second argument is the Content-Type header to check
+func (h *Handler) ParseFormURLEncoded(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 || inputs[0].Type() != js.TypeString {
+		return h.errorResponse("Body string required")
+	}
+
+	contentType := ""
+	if len(inputs) > 1 && inputs[1].Type() == js.TypeString {
+		contentType = inputs[1].String()
+	}
+
+	result, err := h.processor.ParseFormURLEncoded(inputs[0].String(), contentType)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Form body parsed")
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"syscall/js"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// ValueCounts counts the distinct values of an array with their
+// percentages, most frequent first. The optional options object takes
+// field (to count a field of an array of objects), ignoreCase, bins (to
+// count numeric values in equal-width ranges) and top (to fold the rest
+// into an "other" entry).
+func (h *Handler) ValueCounts(this js.Value, inputs []js.Value) interface{} {
+	if len(inputs) == 0 {
+		return h.errorResponse("Array of values required")
+	}
+	items, ok := fromJSValue(inputs[0]).([]interface{})
+	if !ok {
+		return h.errorResponse("Values must be an array")
+	}
+
+	var opts core.ValueCountOptions
+	if len(inputs) > 1 {
+		if options, ok := fromJSValue(inputs[1]).(map[string]interface{}); ok {
+			opts.Field, _ = options["field"].(string)
+			opts.IgnoreCase, _ = options["ignoreCase"].(bool)
+			if bins, ok := options["bins"].(float64); ok {
+				opts.Bins = int(bins)
+			}
+			if top, ok := options["top"].(float64); ok {
+				opts.Top = int(top)
+			}
+		}
+	}
+
+	result, err := h.processor.ValueCounts(items, opts)
+	if err != nil {
+		return h.errorResponse(err.Error())
+	}
+
+	return h.successResponse(result, "Values counted")
+}
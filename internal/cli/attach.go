@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// serverLogFile is where a standalone server mirrors its log output
+func serverLogFile() string {
+	if path := viper.GetString("server.log_file"); path != "" {
+		return path
+	}
+	return filepath.Join(".", ".local-first", "server.log")
+}
+
+// serverLogFollowedMsg reports that a followServerLog read has finished
+type serverLogFollowedMsg struct{}
+
+// followServerLog logs an attached server's new output like the pipes of a
+// launched server
+func followServerLog(t *fileTail) tea.Cmd {
+	return func() tea.Msg {
+		logger := GetLogger()
+		for _, line := range t.readLines() {
+			if strings.TrimSpace(line) != "" {
+				logger.Log(LogInfo, "server", line)
+			}
+		}
+		return serverLogFollowedMsg{}
+	}
+}
+
+// updateAttachment attaches to a running server the dashboard didn't start
+// and detaches once it stops or the dashboard takes over. Only output
+// written after attaching is shown; servers the dashboard launches are read
+// from their pipes instead.
+func (m *DashboardModel) updateAttachment(status ServerStatus) {
+	switch {
+	case status != ServerRunning || currentServer != nil:
+		m.attached = nil
+	case m.attached == nil:
+		m.attached = newFileTail(serverLogFile())
+		GetLogger().Log(LogSystem, "cli", "Attached to running server, following "+m.attached.path)
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+func TestAttachFollowsServerLog(t *testing.T) {
+	logger := useTestLogger(t)
+	path := filepath.Join(t.TempDir(), "server.log")
+	if err := os.WriteFile(path, []byte("before attaching\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("server.log_file", path)
+	defer viper.Set("server.log_file", "")
+
+	var m tea.Model = DashboardModel{}
+	m, _ = m.Update(ServerStatusMsg{Status: ServerRunning})
+	if a := m.(DashboardModel).attached; a == nil || a.path != path {
+		t.Fatalf("attached = %+v, want a tail of %s", a, path)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("attached line\n\n")
+	f.Close()
+
+	// A tick starts one read; another isn't started until it reports back
+	m, _ = m.Update(tickMsg(time.Now()))
+	if !m.(DashboardModel).followingLog {
+		t.Fatal("tick didn't start following the log")
+	}
+	m, cmd := m.Update(tickMsg(time.Now()))
+	if n := batchSize(t, cmd); n != 2 {
+		t.Errorf("tick during a read queued %d commands, want 2", n)
+	}
+
+	msg := followServerLog(m.(DashboardModel).attached)()
+	m, _ = m.Update(msg)
+	if m.(DashboardModel).followingLog {
+		t.Error("read still marked in flight after it finished")
+	}
+
+	var lines []string
+	for _, entry := range logger.GetRecentLogs(maxEntries) {
+		if entry.Source == "server" {
+			lines = append(lines, entry.Message)
+		}
+	}
+	if len(lines) != 1 || lines[0] != "attached line" {
+		t.Errorf("server lines = %q, want only the one written after attaching", lines)
+	}
+
+	m, _ = m.Update(ServerStatusMsg{Status: ServerStopped})
+	if m.(DashboardModel).attached != nil {
+		t.Error("still attached after the server stopped")
+	}
+}
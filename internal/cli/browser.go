@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// openBrowser launches the system default browser at url
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// waitForServer polls url until it responds or the timeout elapses
+func waitForServer(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	return fmt.Errorf("server at %s did not respond within %s", url, timeout)
+}
+
+// openWhenReady opens url in the browser once the server answers. Failures
+// are logged rather than surfaced since opening a browser is best effort.
+func openWhenReady(url string, timeout time.Duration) {
+	logger := GetLogger()
+
+	if err := waitForServer(url, timeout); err != nil {
+		logger.Log(LogWarning, "cli", fmt.Sprintf("Not opening browser: %v", err))
+		return
+	}
+
+	if err := openBrowser(url); err != nil {
+		logger.Log(LogWarning, "cli", fmt.Sprintf("Failed to open browser: %v", err))
+		return
+	}
+
+	logger.Log(LogSystem, "cli", fmt.Sprintf("Opened %s in browser", url))
+}
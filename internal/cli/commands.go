@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -24,7 +25,15 @@ var DashboardCmd = &cobra.Command{
 		m := NewDashboardModel()
 		p := tea.NewProgram(m, tea.WithAltScreen())
 		
-		if _, err := p.Run(); err != nil {
+		_, err := p.Run()
+		
+		// Make sure pending preferences and the last log lines hit the disk
+		// before exiting
+		flushPrefs()
+		GetLogger().Log(LogSystem, "cli", "Dashboard stopped")
+		GetLogger().Flush()
+		
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error running dashboard: %v\n", err)
 			os.Exit(1)
 		}
@@ -38,20 +47,26 @@ var ServeCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetString("port")
 		dev, _ := cmd.Flags().GetBool("dev")
+		open, _ := cmd.Flags().GetBool("open")
 		
-		fmt.Printf("Starting server on port %s (dev mode: %t)\n", port, dev)
+		printStep("Starting server on port %s (dev mode: %t)", port, dev)
 		
 		args = []string{"run", "cmd/server/main.go", "-port", port}
 		if dev {
 			args = append(args, "-dev")
 		}
 		
+		printDebug("Running %s", commandLine("go", args...))
 		serverCmd := exec.Command("go", args...)
 		serverCmd.Stdout = os.Stdout
 		serverCmd.Stderr = os.Stderr
 		
+		if open {
+			go openWhenReady("http://localhost:"+port, 60*time.Second)
+		}
+		
 		if err := serverCmd.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
+			printError("Error starting server: %v", err)
 			os.Exit(1)
 		}
 	},
@@ -72,22 +87,22 @@ var BuildCmd = &cobra.Command{
 		}
 		
 		if wasm {
-			fmt.Println("Building WASM...")
+			printStep("Building WASM...")
 			if err := runMakeTarget("wasm"); err != nil {
-				fmt.Fprintf(os.Stderr, "Error building WASM: %v\n", err)
+				printError("Error building WASM: %v", err)
 				os.Exit(1)
 			}
 		}
 		
 		if server {
-			fmt.Println("Building server...")
+			printStep("Building server...")
 			if err := runMakeTarget("server"); err != nil {
-				fmt.Fprintf(os.Stderr, "Error building server: %v\n", err)
+				printError("Error building server: %v", err)
 				os.Exit(1)
 			}
 		}
 		
-		fmt.Println("Build complete!")
+		printSuccess("Build complete!")
 	},
 }
 
@@ -95,6 +110,7 @@ func init() {
 	// Serve command flags
 	ServeCmd.Flags().StringP("port", "p", "8080", "Port to run the server on")
 	ServeCmd.Flags().BoolP("dev", "d", true, "Run in development mode")
+	ServeCmd.Flags().Bool("open", false, "Open the app in the default browser once the server is up")
 	
 	// Build command flags  
 	BuildCmd.Flags().Bool("wasm", false, "Build only WASM")
@@ -110,7 +126,15 @@ func initConfig() {
 	// Set defaults
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.dev", true)
+	viper.SetDefault("server.start_retries", 2)
+	viper.SetDefault("server.breaker_threshold", DefaultBreakerThreshold)
+	viper.SetDefault("server.breaker_cooldown", DefaultBreakerCooldown.String())
 	viper.SetDefault("dashboard.refresh_interval", 1000)
+	viper.SetDefault("dashboard.theme", "dark")
+	viper.SetDefault("dashboard.idle_shutdown", "0")
+	viper.SetDefault("logging.dedup", true)
+	viper.SetDefault("logging.dedup_window_ms", int(DefaultDedupWindow/time.Millisecond))
+	viper.SetDefault("logging.max_memory_bytes", DefaultMaxLogBytes)
 	
 	if err := viper.ReadInConfig(); err != nil {
 		// Config file not found is OK, we'll use defaults
@@ -118,11 +142,26 @@ func initConfig() {
 			fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
 		}
 	}
+	
+	// Apply logging settings
+	if viper.GetBool("logging.dedup") {
+		GetLogger().SetDedupWindow(time.Duration(viper.GetInt("logging.dedup_window_ms")) * time.Millisecond)
+	} else {
+		GetLogger().SetDedupWindow(0)
+	}
+	GetLogger().SetMaxBytes(viper.GetInt("logging.max_memory_bytes"))
+	
+	// Apply server settings
+	startBreaker = newCircuitBreaker(viper.GetInt("server.breaker_threshold"), viper.GetDuration("server.breaker_cooldown"))
 }
 
 func runMakeTarget(target string) error {
+	printDebug("Running %s", commandLine("make", target))
 	cmd := exec.Command("make", target)
-	cmd.Stdout = os.Stdout
+	// With --quiet only the build's errors are shown
+	if verbosity.Allows(LogInfo) {
+		cmd.Stdout = os.Stdout
+	}
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
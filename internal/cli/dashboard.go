@@ -9,6 +9,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mbarlow/local-first/internal/core"
+	"github.com/mbarlow/local-first/internal/monitoring"
 	"github.com/spf13/viper"
 )
 
@@ -62,6 +64,27 @@ type DashboardModel struct {
 	keyMap        KeyMap
 	lastError     string
 	showError     bool
+	theme         Theme
+	
+	idleTimeout   time.Duration
+	lastIdleCheck time.Time
+	lastRequest   time.Time
+	idleFiredAt   time.Time
+	
+	// inFlight and oldestInFlight come from the server's stats, polled
+	// with the idle check
+	inFlight       int
+	oldestInFlight time.Duration
+	
+	// Set while a log load is in flight so slow reads don't pile up
+	// across ticks
+	loadingRequests bool
+	loadingLogs     bool
+	followingLog    bool
+	
+	// attached follows the log file of a server started outside the
+	// dashboard
+	attached *fileTail
 }
 
 type KeyMap struct {
@@ -72,6 +95,7 @@ type KeyMap struct {
 	NextTab  key.Binding
 	PrevTab  key.Binding
 	Clear    key.Binding
+	Open     key.Binding
 	Quit     key.Binding
 }
 
@@ -104,6 +128,10 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("c", "esc"),
 		key.WithHelp("c", "clear error"),
 	),
+	Open: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open in browser"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
@@ -116,14 +144,24 @@ func NewDashboardModel() DashboardModel {
 	// Log CLI startup
 	GetLogger().Log(LogSystem, "cli", "Dashboard started")
 	
+	tabs := []string{"Server", "Requests", "Logs"}
+	selected := loadPrefs().SelectedTab
+	if selected < 0 || selected >= len(tabs) {
+		selected = 0
+	}
+	
 	return DashboardModel{
 		server: ServerInfo{
 			Status: ServerStopped,
 			Port:   viper.GetInt("server.port"),
 		},
-		tabs:      []string{"Server", "Requests", "Logs"},
-		startTime: time.Now(),
-		keyMap:    DefaultKeyMap,
+		tabs:        tabs,
+		selectedTab: selected,
+		startTime:   time.Now(),
+		keyMap:      DefaultKeyMap,
+		theme:       GetTheme(viper.GetString("dashboard.theme")),
+		
+		idleTimeout: idleShutdownAfter(),
 	}
 }
 
@@ -163,9 +201,16 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keyMap.NextTab):
 			m.selectedTab = (m.selectedTab + 1) % len(m.tabs)
+			savePrefs(dashboardPrefs{SelectedTab: m.selectedTab})
 
 		case key.Matches(msg, m.keyMap.PrevTab):
 			m.selectedTab = (m.selectedTab - 1 + len(m.tabs)) % len(m.tabs)
+			savePrefs(dashboardPrefs{SelectedTab: m.selectedTab})
+
+		case key.Matches(msg, m.keyMap.Open):
+			if m.server.Status == ServerRunning {
+				return m, m.openInBrowser()
+			}
 
 		case key.Matches(msg, m.keyMap.Refresh):
 			return m, m.checkServerStatus()
@@ -177,16 +222,35 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tickMsg:
 		m.updateUptime()
-		return m, tea.Batch(
+		cmds := []tea.Cmd{
 			m.checkServerStatus(),
-			m.loadRequestLogs(),
-			m.loadSystemLogs(),
 			m.tick(),
-		)
+		}
+		if !m.loadingRequests {
+			m.loadingRequests = true
+			cmds = append(cmds, m.loadRequestLogs())
+		}
+		if !m.loadingLogs {
+			m.loadingLogs = true
+			cmds = append(cmds, m.loadSystemLogs())
+		}
+		if m.attached != nil && !m.followingLog {
+			m.followingLog = true
+			cmds = append(cmds, followServerLog(m.attached))
+		}
+		if m.server.Status == ServerRunning && time.Since(m.lastIdleCheck) >= idleCheckInterval {
+			m.lastIdleCheck = time.Now()
+			cmds = append(cmds, m.checkIdle())
+		}
+		return m, tea.Batch(cmds...)
+
+	case IdleStatusMsg:
+		return m.handleIdleStatus(msg)
 
 	case ServerStatusMsg:
 		m.server.Status = msg.Status
 		m.server.PID = msg.PID
+		m.updateAttachment(msg.Status)
 		if msg.Error != nil {
 			m.lastError = msg.Error.Error()
 			m.showError = true
@@ -195,17 +259,25 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if msg.Status == ServerRunning && m.startTime.IsZero() {
 			m.startTime = time.Now()
+			m.idleFiredAt = time.Time{}
+			m.lastRequest = time.Time{}
 		}
 		if msg.Status == ServerStopped {
 			m.startTime = time.Time{}
 			m.server.Uptime = 0
+			m.inFlight = 0
 		}
 
 	case RequestLogsMsg:
 		m.requests = msg.Logs
+		m.loadingRequests = false
 		
 	case LogsUpdatedMsg:
 		m.logs = msg.Logs
+		m.loadingLogs = false
+		
+	case serverLogFollowedMsg:
+		m.followingLog = false
 	}
 
 	return m, nil
@@ -252,31 +324,16 @@ func (m DashboardModel) View() string {
 }
 
 func (m DashboardModel) renderHeader() string {
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("212")).
-		MarginLeft(2)
-
-	return titleStyle.Render("🚀 Local-First Dashboard")
+	return m.theme.Title.Render("🚀 Local-First Dashboard")
 }
 
 func (m DashboardModel) renderTabs() string {
 	var tabs []string
-	activeTabStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("36")).
-		Background(lipgloss.Color("57")).
-		Padding(0, 2)
-
-	inactiveTabStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		Padding(0, 2)
-
 	for i, tab := range m.tabs {
 		if i == m.selectedTab {
-			tabs = append(tabs, activeTabStyle.Render(tab))
+			tabs = append(tabs, m.theme.ActiveTab.Render(tab))
 		} else {
-			tabs = append(tabs, inactiveTabStyle.Render(tab))
+			tabs = append(tabs, m.theme.InactiveTab.Render(tab))
 		}
 	}
 
@@ -291,24 +348,19 @@ func (m DashboardModel) renderServerTab() string {
 		Bold(true).
 		Width(20)
 
-	var statusColor lipgloss.Color
+	var statusColor lipgloss.Style
 	switch m.server.Status {
 	case ServerRunning:
-		statusColor = lipgloss.Color("42") // Green
+		statusColor = m.theme.StatusOK
 	case ServerStarting, ServerStopping:
-		statusColor = lipgloss.Color("226") // Yellow
+		statusColor = m.theme.StatusWarn
 	default:
-		statusColor = lipgloss.Color("196") // Red
+		statusColor = m.theme.StatusErr
 	}
 
 	content.WriteString(statusStyle.Render("Status:"))
 	content.WriteString(" ")
-	content.WriteString(
-		lipgloss.NewStyle().
-			Foreground(statusColor).
-			Bold(true).
-			Render(m.server.Status.String()),
-	)
+	content.WriteString(statusColor.Bold(true).Render(m.server.Status.String()))
 	content.WriteString("\n")
 
 	content.WriteString(statusStyle.Render("Port:"))
@@ -324,17 +376,34 @@ func (m DashboardModel) renderServerTab() string {
 
 		content.WriteString(statusStyle.Render("Uptime:"))
 		content.WriteString(" ")
-		content.WriteString(m.server.Uptime.Truncate(time.Second).String())
+		content.WriteString(core.FormatDuration(m.server.Uptime.Truncate(time.Second)))
 		content.WriteString("\n")
 
 		content.WriteString(statusStyle.Render("URL:"))
 		content.WriteString(" ")
-		content.WriteString(
-			lipgloss.NewStyle().
-				Foreground(lipgloss.Color("33")).
-				Underline(true).
-				Render(fmt.Sprintf("http://localhost:%d", m.server.Port)),
-		)
+		content.WriteString(m.theme.Link.Render(fmt.Sprintf("http://localhost:%d", m.server.Port)))
+		content.WriteString("\n")
+		
+		if m.inFlight > 0 {
+			content.WriteString(statusStyle.Render("In flight:"))
+			content.WriteString(" ")
+			content.WriteString(m.theme.StatusWarn.Render(fmt.Sprintf("%d (oldest %s)",
+				m.inFlight, core.FormatDuration(m.oldestInFlight.Truncate(time.Second)))))
+			content.WriteString("\n")
+		}
+		
+		if m.attached != nil {
+			content.WriteString(statusStyle.Render("Logs:"))
+			content.WriteString(" ")
+			content.WriteString("attached, following " + m.attached.path)
+			content.WriteString("\n")
+		}
+	}
+	
+	if idle := m.renderIdleStatus(); idle != "" {
+		content.WriteString(statusStyle.Render("Idle shutdown:"))
+		content.WriteString(" ")
+		content.WriteString(idle)
 		content.WriteString("\n")
 	}
 
@@ -343,18 +412,13 @@ func (m DashboardModel) renderServerTab() string {
 
 func (m DashboardModel) renderRequestsTab() string {
 	if len(m.requests) == 0 {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("No requests yet... Start the server and visit http://localhost:" + strconv.Itoa(m.server.Port))
+		return m.theme.Muted.Render("No requests yet... Start the server and visit http://localhost:" + strconv.Itoa(m.server.Port))
 	}
 
 	var content strings.Builder
 	
 	// Header
-	headerStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("33")).
-		Width(80)
+	headerStyle := m.theme.Header.Width(80)
 	
 	content.WriteString(headerStyle.Render("TIME     METHOD PATH                    STATUS DURATION"))
 	content.WriteString("\n")
@@ -371,16 +435,16 @@ func (m DashboardModel) renderRequestsTab() string {
 		timeStr := req.Timestamp.Format("15:04:05")
 		
 		// Color code by status
-		var statusColor lipgloss.Color
+		var statusColor lipgloss.Style
 		switch {
 		case req.Status >= 200 && req.Status < 300:
-			statusColor = lipgloss.Color("42") // Green
+			statusColor = m.theme.StatusOK
 		case req.Status >= 300 && req.Status < 400:
-			statusColor = lipgloss.Color("226") // Yellow
+			statusColor = m.theme.StatusWarn
 		case req.Status >= 400:
-			statusColor = lipgloss.Color("196") // Red
+			statusColor = m.theme.StatusErr
 		default:
-			statusColor = lipgloss.Color("241") // Gray
+			statusColor = m.theme.Muted
 		}
 		
 		// Truncate path if too long
@@ -390,23 +454,23 @@ func (m DashboardModel) renderRequestsTab() string {
 		}
 		
 		// Duration color based on speed
-		var durationColor lipgloss.Color
+		var durationColor lipgloss.Style
 		ms := req.Duration.Milliseconds()
 		switch {
 		case ms < 10:
-			durationColor = lipgloss.Color("42") // Green - fast
+			durationColor = m.theme.StatusOK // fast
 		case ms < 100:
-			durationColor = lipgloss.Color("226") // Yellow - medium
+			durationColor = m.theme.StatusWarn // medium
 		default:
-			durationColor = lipgloss.Color("196") // Red - slow
+			durationColor = m.theme.StatusErr // slow
 		}
 		
 		content.WriteString(fmt.Sprintf("%s %-6s %-24s %s %s\n",
 			timeStr,
 			req.Method,
 			path,
-			lipgloss.NewStyle().Foreground(statusColor).Render(fmt.Sprintf("%-3d", req.Status)),
-			lipgloss.NewStyle().Foreground(durationColor).Render(fmt.Sprintf("%4dms", ms)),
+			statusColor.Render(fmt.Sprintf("%-3d", req.Status)),
+			durationColor.Render(fmt.Sprintf("%4dms", ms)),
 		))
 	}
 	
@@ -435,30 +499,47 @@ func (m DashboardModel) renderRequestsTab() string {
 			statusCounts[500],
 		)
 		
-		content.WriteString(
-			lipgloss.NewStyle().
-				Foreground(lipgloss.Color("241")).
-				Render(summary),
-		)
+		content.WriteString(m.theme.Muted.Render(summary))
+		content.WriteString("\n")
+		content.WriteString(m.renderTopEndpoints())
 	}
 	
 	return content.String()
 }
 
+// renderTopEndpoints lists the busiest and slowest paths in the request log
+func (m DashboardModel) renderTopEndpoints() string {
+	logs := make([]monitoring.RequestLog, len(m.requests))
+	for i, req := range m.requests {
+		logs[i] = monitoring.RequestLog{
+			Path:     req.Path,
+			Duration: req.Duration.Milliseconds(),
+		}
+	}
+	
+	busiest, slowest := monitoring.TopEndpoints(logs, 3)
+	
+	var busy, slow []string
+	for _, ep := range busiest {
+		busy = append(busy, fmt.Sprintf("%s (%d)", ep.Path, ep.Count))
+	}
+	for _, ep := range slowest {
+		slow = append(slow, fmt.Sprintf("%s (p95 %dms)", ep.Path, ep.P95Duration))
+	}
+	
+	return m.theme.Muted.Render("Busiest: "+strings.Join(busy, " • ")) + "\n" +
+		m.theme.Muted.Render("Slowest: "+strings.Join(slow, " • "))
+}
+
 func (m DashboardModel) renderLogsTab() string {
 	if len(m.logs) == 0 {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("No logs yet... Start the server to see logs")
+		return m.theme.Muted.Render("No logs yet... Start the server to see logs")
 	}
 
 	var content strings.Builder
 	
 	// Header
-	headerStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("33")).
-		Width(80)
+	headerStyle := m.theme.Header.Width(80)
 	
 	content.WriteString(headerStyle.Render("TIME     LEVEL  SOURCE   MESSAGE"))
 	content.WriteString("\n")
@@ -475,31 +556,29 @@ func (m DashboardModel) renderLogsTab() string {
 		timeStr := log.Timestamp.Format("15:04:05")
 		
 		// Color code by level
-		var levelColor lipgloss.Color
+		var levelColor lipgloss.Style
 		switch log.Level {
 		case LogSystem:
-			levelColor = lipgloss.Color("33") // Blue
+			levelColor = m.theme.Info
 		case LogInfo:
-			levelColor = lipgloss.Color("42") // Green
+			levelColor = m.theme.StatusOK
 		case LogWarning:
-			levelColor = lipgloss.Color("226") // Yellow
+			levelColor = m.theme.StatusWarn
 		case LogError:
-			levelColor = lipgloss.Color("196") // Red
-		case LogDebug:
-			levelColor = lipgloss.Color("241") // Gray
+			levelColor = m.theme.StatusErr
 		default:
-			levelColor = lipgloss.Color("241") // Gray
+			levelColor = m.theme.Muted
 		}
 		
 		// Truncate message if too long
-		message := log.Message
+		message := log.DisplayMessage()
 		if len(message) > 45 {
 			message = message[:42] + "..."
 		}
 		
 		content.WriteString(fmt.Sprintf("%s %-6s %-8s %s\n",
 			timeStr,
-			lipgloss.NewStyle().Foreground(levelColor).Render(fmt.Sprintf("%-6s", log.Level.String())),
+			levelColor.Render(fmt.Sprintf("%-6s", log.Level.String())),
 			log.Source,
 			message,
 		))
@@ -525,41 +604,28 @@ func (m DashboardModel) renderLogsTab() string {
 			counts[LogError],
 		)
 		
-		content.WriteString(
-			lipgloss.NewStyle().
-				Foreground(lipgloss.Color("241")).
-				Render(summary),
-		)
+		content.WriteString(m.theme.Muted.Render(summary))
 	}
 	
 	return content.String()
 }
 
 func (m DashboardModel) renderError() string {
-	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")).
-		Background(lipgloss.Color("52")).
-		Bold(true).
-		Padding(0, 1).
-		MarginLeft(2)
-
-	return errorStyle.Render("❌ Error: " + m.lastError)
+	return m.theme.ErrorBanner.Render("❌ Error: " + m.lastError)
 }
 
 func (m DashboardModel) renderFooter() string {
-	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
-
 	help := []string{
 		"s: start",
 		"x: stop", 
 		"r: restart",
+		"o: open",
 		"c: clear error",
 		"tab: switch tabs",
 		"q: quit",
 	}
 
-	return helpStyle.Render(strings.Join(help, " • "))
+	return m.theme.Muted.Render(strings.Join(help, " • "))
 }
 
 func (m DashboardModel) updateUptime() {
@@ -568,6 +634,14 @@ func (m DashboardModel) updateUptime() {
 	}
 }
 
+func (m DashboardModel) openInBrowser() tea.Cmd {
+	url := fmt.Sprintf("http://localhost:%d", m.server.Port)
+	return func() tea.Msg {
+		openWhenReady(url, 5*time.Second)
+		return nil
+	}
+}
+
 func (m DashboardModel) tick() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
 		return tickMsg(t)
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchSize returns how many commands a batch returned by Update holds
+func batchSize(t *testing.T, cmd tea.Cmd) int {
+	t.Helper()
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("expected a batch of commands")
+	}
+	return len(batch)
+}
+
+func TestTickSkipsLoadsInFlight(t *testing.T) {
+	var m tea.Model = DashboardModel{server: ServerInfo{Status: ServerStopped}}
+
+	// The first tick starts both loads along with the status check and
+	// the next tick
+	m, cmd := m.Update(tickMsg(time.Now()))
+	if n := batchSize(t, cmd); n != 4 {
+		t.Errorf("first tick queued %d commands, want 4", n)
+	}
+	d := m.(DashboardModel)
+	if !d.loadingRequests || !d.loadingLogs {
+		t.Fatalf("loads not marked in flight: requests %v, logs %v", d.loadingRequests, d.loadingLogs)
+	}
+
+	// While they are running, ticks don't start more
+	m, cmd = m.Update(tickMsg(time.Now()))
+	if n := batchSize(t, cmd); n != 2 {
+		t.Errorf("tick during loads queued %d commands, want 2", n)
+	}
+
+	// Each finished load frees its own slot
+	m, _ = m.Update(RequestLogsMsg{Logs: []RequestLog{{Path: "/"}}})
+	d = m.(DashboardModel)
+	if d.loadingRequests || !d.loadingLogs || len(d.requests) != 1 {
+		t.Errorf("after request logs: requests %v, logs %v", d.loadingRequests, d.loadingLogs)
+	}
+	m, cmd = m.Update(tickMsg(time.Now()))
+	if n := batchSize(t, cmd); n != 3 {
+		t.Errorf("tick after one load finished queued %d commands, want 3", n)
+	}
+
+	m, _ = m.Update(LogsUpdatedMsg{})
+	if m.(DashboardModel).loadingLogs {
+		t.Error("system log load still marked in flight")
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbarlow/local-first/internal/core"
+	"github.com/spf13/viper"
+)
+
+// idleCheckInterval is how often the dashboard polls /api/stats while the
+// server is running
+const idleCheckInterval = 5 * time.Second
+
+// idleNow is the idle-shutdown clock, replaced in tests
+var idleNow = time.Now
+
+// IdleStatusMsg carries the managed server's last request time, the
+// requests it is still handling and its open upgraded connections, such as
+// sync WebSockets
+type IdleStatusMsg struct {
+	LastRequest    time.Time
+	InFlight       int
+	OldestInFlight time.Duration
+	Upgraded       int
+	Error          error
+}
+
+// idleShutdownAfter returns the configured idle timeout, or zero when
+// idle-shutdown is disabled
+func idleShutdownAfter() time.Duration {
+	timeout := viper.GetDuration("dashboard.idle_shutdown")
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// idleArmed reports whether the dashboard should stop the server once it
+// goes idle. Only servers started by the dashboard are stopped.
+func (m DashboardModel) idleArmed() bool {
+	return m.idleTimeout > 0 && m.server.Status == ServerRunning && currentServer != nil
+}
+
+// checkIdle asks the server's monitor when it last served a request and
+// how many requests are in flight
+func (m DashboardModel) checkIdle() tea.Cmd {
+	url := fmt.Sprintf("http://localhost:%d/api/stats", m.server.Port)
+	return func() tea.Msg {
+		client := &http.Client{Timeout: 2 * time.Second}
+		resp, err := client.Get(url)
+		if err != nil {
+			return IdleStatusMsg{Error: err}
+		}
+		defer resp.Body.Close()
+
+		var stats struct {
+			LastRequest      time.Time `json:"last_request"`
+			InFlight         int       `json:"in_flight"`
+			OldestInFlightMs int64     `json:"oldest_in_flight_ms"`
+			Upgraded         int       `json:"upgraded"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			return IdleStatusMsg{Error: fmt.Errorf("invalid stats response: %w", err)}
+		}
+		return IdleStatusMsg{
+			LastRequest:    stats.LastRequest,
+			InFlight:       stats.InFlight,
+			OldestInFlight: time.Duration(stats.OldestInFlightMs) * time.Millisecond,
+			Upgraded:       stats.Upgraded,
+		}
+	}
+}
+
+// handleIdleStatus records the in-flight count and stops the server once
+// it has been idle for longer than the configured timeout
+func (m DashboardModel) handleIdleStatus(msg IdleStatusMsg) (DashboardModel, tea.Cmd) {
+	if msg.Error != nil || msg.LastRequest.IsZero() {
+		return m, nil
+	}
+
+	m.lastRequest = msg.LastRequest
+	m.inFlight = msg.InFlight
+	m.oldestInFlight = msg.OldestInFlight
+	// a server still handling a request is not idle, however long ago it
+	// arrived. Upgraded connections don't count: a browser tab keeps its
+	// sync socket open whether or not anyone is using it.
+	if !m.idleArmed() || msg.InFlight > 0 {
+		return m, nil
+	}
+
+	idle := idleNow().Sub(msg.LastRequest)
+	if idle < m.idleTimeout {
+		return m, nil
+	}
+
+	m.idleFiredAt = idleNow()
+	GetLogger().Log(LogWarning, "cli", fmt.Sprintf("Idle shutdown: no requests for %s, stopping server", core.FormatDuration(idle)))
+	return m, m.stopServer()
+}
+
+// renderIdleStatus describes the idle-shutdown state for the server tab
+func (m DashboardModel) renderIdleStatus() string {
+	if m.idleTimeout <= 0 {
+		return ""
+	}
+
+	if m.server.Status != ServerRunning {
+		if !m.idleFiredAt.IsZero() {
+			return m.theme.StatusWarn.Render(fmt.Sprintf("stopped server at %s after %s idle",
+				m.idleFiredAt.Format("15:04:05"), core.FormatDuration(m.idleTimeout)))
+		}
+		return m.theme.Muted.Render(fmt.Sprintf("after %s (when started here)", core.FormatDuration(m.idleTimeout)))
+	}
+
+	if currentServer == nil {
+		return m.theme.Muted.Render("inactive (server not started by dashboard)")
+	}
+
+	status := fmt.Sprintf("armed, stops after %s", core.FormatDuration(m.idleTimeout))
+	if !m.lastRequest.IsZero() {
+		status += fmt.Sprintf(" (idle %s)", core.FormatDuration(idleNow().Sub(m.lastRequest).Truncate(time.Second)))
+	}
+	return m.theme.Info.Render(status)
+}
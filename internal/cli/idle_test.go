@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// withIdleClock fixes the idle-shutdown clock, logs to a temp file and
+// marks the server as started by the dashboard (or not) for the rest of
+// the test
+func withIdleClock(t *testing.T, now time.Time, managed bool) {
+	t.Helper()
+	useTestLogger(t)
+	prevNow, prevServer := idleNow, currentServer
+	t.Cleanup(func() { idleNow, currentServer = prevNow, prevServer })
+
+	idleNow = func() time.Time { return now }
+	currentServer = nil
+	if managed {
+		currentServer = &ServerProcess{}
+	}
+}
+
+func TestIdleShutdownAfter(t *testing.T) {
+	defer viper.Reset()
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "15m", want: 15 * time.Minute},
+		{value: "-1m", want: 0},
+	}
+	for _, tt := range tests {
+		viper.Reset()
+		if tt.value != "" {
+			viper.Set("dashboard.idle_shutdown", tt.value)
+		}
+		if got := idleShutdownAfter(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHandleIdleStatus(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		managed bool
+		msg     IdleStatusMsg
+		fires   bool
+	}{
+		{name: "disabled by default", managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-time.Hour)}},
+		{name: "not started by dashboard", timeout: time.Minute, msg: IdleStatusMsg{LastRequest: now.Add(-time.Hour)}},
+		{name: "armed but recent request", timeout: time.Minute, managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-59 * time.Second)}},
+		{name: "request in flight", timeout: time.Minute, managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-time.Hour), InFlight: 1}},
+		{name: "only a sync socket open", timeout: time.Minute, managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-time.Hour), Upgraded: 1}, fires: true},
+		{name: "fires at the timeout", timeout: time.Minute, managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-time.Minute)}, fires: true},
+		{name: "fires long after", timeout: time.Minute, managed: true, msg: IdleStatusMsg{LastRequest: now.Add(-time.Hour)}, fires: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIdleClock(t, now, tt.managed)
+			m := DashboardModel{server: ServerInfo{Status: ServerRunning}, idleTimeout: tt.timeout}
+
+			m, cmd := m.handleIdleStatus(tt.msg)
+			if fired := cmd != nil; fired != tt.fires {
+				t.Fatalf("fired = %v, want %v", fired, tt.fires)
+			}
+			if tt.fires && !m.idleFiredAt.Equal(now) {
+				t.Errorf("idleFiredAt = %s, want %s", m.idleFiredAt, now)
+			}
+			if !tt.fires && !m.idleFiredAt.IsZero() {
+				t.Errorf("idleFiredAt set without firing")
+			}
+			if !m.lastRequest.Equal(tt.msg.LastRequest) || m.inFlight != tt.msg.InFlight {
+				t.Errorf("stats not recorded: last %s, in flight %d", m.lastRequest, m.inFlight)
+			}
+		})
+	}
+}
+
+func TestCheckIdleReadsStats(t *testing.T) {
+	last := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/stats" {
+			http.NotFound(w, r)
+			return
+		}
+		// a browser tab's sync socket is open, nothing else is running
+		fmt.Fprintf(w, `{"last_request":%q,"in_flight":0,"oldest_in_flight_ms":0,"upgraded":1}`, last.Format(time.RFC3339))
+	}))
+	defer server.Close()
+	port, err := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := DashboardModel{server: ServerInfo{Port: port}}.checkIdle()().(IdleStatusMsg)
+	want := IdleStatusMsg{LastRequest: last, Upgraded: 1}
+	if msg.Error != nil || !msg.LastRequest.Equal(want.LastRequest) || msg.InFlight != 0 || msg.Upgraded != 1 {
+		t.Fatalf("got %+v, want %+v", msg, want)
+	}
+
+	withIdleClock(t, last.Add(time.Hour), true)
+	m := DashboardModel{server: ServerInfo{Status: ServerRunning, Port: port}, idleTimeout: time.Minute}
+	if _, cmd := m.handleIdleStatus(msg); cmd == nil {
+		t.Error("an open sync socket kept idle shutdown from firing")
+	}
+}
+
+func TestHandleIdleStatusIgnoresBadStats(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	withIdleClock(t, now, true)
+	m := DashboardModel{server: ServerInfo{Status: ServerRunning}, idleTimeout: time.Minute, inFlight: 2}
+
+	for _, msg := range []IdleStatusMsg{
+		{Error: errors.New("connection refused"), LastRequest: now.Add(-time.Hour)},
+		{}, // no request served yet
+	} {
+		got, cmd := m.handleIdleStatus(msg)
+		if cmd != nil || got.inFlight != 2 || !got.lastRequest.IsZero() {
+			t.Errorf("%+v: changed state or fired", msg)
+		}
+	}
+}
+
+func TestRenderIdleStatus(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		managed bool
+		model   DashboardModel
+		want    string
+	}{
+		{name: "disabled", managed: true, model: DashboardModel{server: ServerInfo{Status: ServerRunning}}, want: ""},
+		{
+			name:    "armed",
+			managed: true,
+			model:   DashboardModel{server: ServerInfo{Status: ServerRunning}, idleTimeout: 15 * time.Minute, lastRequest: now.Add(-90 * time.Second)},
+			want:    "armed, stops after 15m (idle 1m 30s)",
+		},
+		{
+			name:  "not started by dashboard",
+			model: DashboardModel{server: ServerInfo{Status: ServerRunning}, idleTimeout: 15 * time.Minute},
+			want:  "inactive",
+		},
+		{
+			name:  "stopped",
+			model: DashboardModel{server: ServerInfo{Status: ServerStopped}, idleTimeout: 15 * time.Minute},
+			want:  "after 15m (when started here)",
+		},
+		{
+			name:  "fired",
+			model: DashboardModel{server: ServerInfo{Status: ServerStopped}, idleTimeout: 15 * time.Minute, idleFiredAt: now},
+			want:  "stopped server at 12:00:00 after 15m idle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIdleClock(t, now, tt.managed)
+			tt.model.theme = GetTheme("dark")
+			got := tt.model.renderIdleStatus()
+			if tt.want == "" && got != "" {
+				t.Errorf("got %q, want nothing", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("got %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
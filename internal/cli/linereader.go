@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxLineLength is the longest line logged as a single entry. Longer lines,
+// such as large JSON log records, are split into chunks of this size.
+const maxLineLength = 64 * 1024
+
+// lineContinuedMarker ends every chunk of a split line except the last
+const lineContinuedMarker = "[continued]"
+
+// readLines calls fn with each line read from r, without the line ending.
+// Lines longer than max bytes are delivered in chunks with continued set on
+// all but the last; chunks never split a UTF-8 character. A final line
+// without a newline is delivered at EOF. The error is nil at EOF.
+func readLines(r io.Reader, max int, fn func(line string, continued bool)) error {
+	reader := bufio.NewReaderSize(r, max)
+	var carry []byte
+	for {
+		data, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			if len(carry) > 0 {
+				fn(string(carry), false)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		chunk := append(carry, data...)
+		carry = nil
+		if isPrefix {
+			// Hold back a character cut off at the buffer boundary
+			if cut := incompleteRuneStart(chunk); cut < len(chunk) {
+				carry = append([]byte(nil), chunk[cut:]...)
+				chunk = chunk[:cut]
+			}
+		}
+		fn(string(chunk), isPrefix)
+	}
+}
+
+// incompleteRuneStart returns the index of a truncated UTF-8 sequence at the
+// end of b, or len(b) if b ends on a character boundary
+func incompleteRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
+
+// fileTail tracks the read position of a log file being followed. It is
+// safe for concurrent use.
+type fileTail struct {
+	mu      sync.Mutex
+	path    string
+	info    os.FileInfo // the file offset refers to, nil if it didn't exist
+	offset  int64
+	partial string
+}
+
+// newFileTail follows path from its current end
+func newFileTail(path string) *fileTail {
+	t := &fileTail{path: path}
+	if info, err := os.Stat(path); err == nil {
+		t.info = info
+		t.offset = info.Size()
+	}
+	return t
+}
+
+// newFileTailAt follows path from offset in the file described by info,
+// such as where an initial read of the file ended
+func newFileTailAt(path string, info os.FileInfo, offset int64) *fileTail {
+	return &fileTail{path: path, info: info, offset: offset}
+}
+
+// readLines returns the complete lines appended since the last call. A file
+// that was replaced, such as by log rotation, or that shrank because it was
+// truncated is read again from the start.
+func (t *fileTail) readLines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil
+	}
+
+	// File was replaced or truncated, start over
+	if t.info != nil && !os.SameFile(t.info, info) || info.Size() < t.offset {
+		t.offset = 0
+		t.partial = ""
+	}
+	t.info = info
+
+	if info.Size() == t.offset {
+		return nil
+	}
+
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil
+	}
+	t.offset += int64(len(data))
+
+	chunk := t.partial + string(data)
+	lines := strings.Split(chunk, "\n")
+
+	// Hold on to an incomplete trailing line until it is finished
+	t.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	type line struct {
+		text      string
+		continued bool
+	}
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		want  []line
+	}{
+		{name: "empty", input: "", max: 16},
+		{name: "lines", input: "a\nb\r\nc\n", max: 16, want: []line{{"a", false}, {"b", false}, {"c", false}}},
+		{name: "final line without newline", input: "a\nb", max: 16, want: []line{{"a", false}, {"b", false}}},
+		{
+			name:  "long line split",
+			input: strings.Repeat("x", 20) + "\ny\n",
+			max:   16,
+			want:  []line{{strings.Repeat("x", 16), true}, {"xxxx", false}, {"y", false}},
+		},
+		{
+			// é is two bytes; the chunk boundary falls inside it
+			name:  "split keeps characters whole",
+			input: strings.Repeat("x", 15) + "é" + "z\n",
+			max:   16,
+			want:  []line{{strings.Repeat("x", 15), true}, {"éz", false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []line
+			err := readLines(strings.NewReader(tt.input), tt.max, func(text string, continued bool) {
+				got = append(got, line{text, continued})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	appendFile(t, path, "before\n")
+
+	tail := newFileTail(path)
+	check := func(step string, want ...string) {
+		t.Helper()
+		if got := tail.readLines(); !reflect.DeepEqual(got, want) && len(got)+len(want) > 0 {
+			t.Fatalf("%s: got %q, want %q", step, got, want)
+		}
+	}
+
+	check("nothing new")
+	appendFile(t, path, "one\ntw")
+	check("partial line held back", "one")
+	appendFile(t, path, "o\r\n")
+	check("partial line completed", "two")
+
+	// Truncated in place
+	if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check("truncated", "x")
+
+	// Rotated: renamed away and replaced by a new file that is already
+	// larger than the old offset
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "first in new file\nsecond\n")
+	check("rotated", "first in new file", "second")
+	appendFile(t, path, "third\n")
+	check("after rotation", "third")
+}
+
+func TestFileTailMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "later.log")
+	tail := newFileTail(path)
+	if got := tail.readLines(); got != nil {
+		t.Fatalf("got %q before the file exists", got)
+	}
+	appendFile(t, path, "hello\n")
+	if got := tail.readLines(); !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Fatalf("got %q, want [hello]", got)
+	}
+}
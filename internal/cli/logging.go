@@ -1,7 +1,7 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbarlow/local-first/internal/debounce"
+	"github.com/mbarlow/local-first/internal/ringbuf"
 )
 
 type LogLevel int
@@ -45,23 +47,68 @@ type LogEntry struct {
 	Level     LogLevel
 	Source    string // "server", "wasm", "cli", etc.
 	Message   string
+	Count     int       // Number of coalesced occurrences, 0 or 1 means no repeats
+	LastSeen  time.Time // Time of the most recent occurrence
 }
 
+// DisplayMessage returns the message with a repeat counter when the entry
+// has coalesced duplicates
+func (e LogEntry) DisplayMessage() string {
+	if e.Count > 1 {
+		return fmt.Sprintf("%s (repeated %dx)", e.Message, e.Count)
+	}
+	return e.Message
+}
+
+// DefaultDedupWindow is how long an identical message is coalesced into the
+// previous entry
+const DefaultDedupWindow = 2 * time.Second
+
 type Logger struct {
-	entries []LogEntry
+	entries *ringbuf.Buffer[LogEntry]
 	mu      sync.RWMutex
 	logFile string
+	
+	// buffered holds entries waiting for the next debounced file write
+	buffered []LogEntry
+	writes   *debounce.Debouncer
+
+	dedupWindow time.Duration
+	// unwrittenRepeats is set while the last entry has coalesced duplicates
+	// that haven't been recorded in the log file yet
+	unwrittenRepeats bool
+	
+	// verbosity drops entries below the chosen level before they reach
+	// memory or the file
+	verbosity Verbosity
+	
+	// entryBytes is the approximate size of entries, bounded by maxBytes
+	entryBytes int
+	maxBytes   int
+}
+
+const (
+	// maxEntries is how many log entries are kept in memory
+	maxEntries = 500
+	// DefaultMaxLogBytes caps the approximate memory held by in-memory log
+	// entries, which matters when server output has very long lines
+	DefaultMaxLogBytes = 4 << 20
+)
+
+// size approximates the memory a log entry holds
+func (e LogEntry) size() int {
+	return 64 + len(e.Source) + len(e.Message)
 }
 
 var globalLogger *Logger
 
 func init() {
-	logDir := filepath.Join(".", ".local-first")
-	os.MkdirAll(logDir, 0755)
-	
 	globalLogger = &Logger{
-		entries: make([]LogEntry, 0),
-		logFile: filepath.Join(logDir, "cli.log"),
+		entries:     ringbuf.New[LogEntry](maxEntries),
+		logFile:     filepath.Join(".", ".local-first", "cli.log"),
+		dedupWindow: DefaultDedupWindow,
+		writes:      debounce.New(debounce.DefaultDelay, 2*time.Second),
+		maxBytes:    DefaultMaxLogBytes,
 	}
 }
 
@@ -69,60 +116,145 @@ func GetLogger() *Logger {
 	return globalLogger
 }
 
+// SetVerbosity sets which levels are logged
+func (l *Logger) SetVerbosity(v Verbosity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verbosity = v
+}
+
+// SetMaxBytes caps the approximate memory used by in-memory entries. Zero
+// leaves only the count limit.
+func (l *Logger) SetMaxBytes(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxBytes = n
+}
+
+// SetDedupWindow sets how long identical messages are coalesced. Zero
+// disables coalescing so every line is kept.
+func (l *Logger) SetDedupWindow(window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dedupWindow = window
+}
+
 func (l *Logger) Log(level LogLevel, source, message string) {
+	now := time.Now()
 	entry := LogEntry{
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Level:     level,
 		Source:    source,
 		Message:   strings.TrimSpace(message),
+		Count:     1,
+		LastSeen:  now,
 	}
 	
 	l.mu.Lock()
-	l.entries = append(l.entries, entry)
-	// Keep only last 500 entries in memory
-	if len(l.entries) > 500 {
-		l.entries = l.entries[1:]
+	
+	if !l.verbosity.Allows(level) {
+		l.mu.Unlock()
+		return
+	}
+	
+	// Coalesce repeats of the last message within the window
+	if last, ok := l.entries.Last(); ok && l.dedupWindow > 0 {
+		if last.Level == entry.Level && last.Source == entry.Source &&
+			last.Message == entry.Message && now.Sub(last.LastSeen) <= l.dedupWindow {
+			last.Count++
+			last.LastSeen = now
+			l.unwrittenRepeats = true
+			l.mu.Unlock()
+			return
+		}
+	}
+	
+	// The previous run of duplicates has ended, record it in the file
+	if repeated, ok := l.takeUnwrittenRepeats(); ok {
+		l.buffered = append(l.buffered, repeated)
+	}
+	
+	// Keep only the last maxEntries entries within maxBytes in memory
+	if old, evicted := l.entries.Push(entry); evicted {
+		l.entryBytes -= old.size()
+	}
+	l.entryBytes += entry.size()
+	for l.maxBytes > 0 && l.entryBytes > l.maxBytes && l.entries.Len() > 1 {
+		old, _ := l.entries.DropOldest()
+		l.entryBytes -= old.size()
+	}
+	l.buffered = append(l.buffered, entry)
+	l.mu.Unlock()
+	
+	// Bursts of log lines are written to the file together
+	l.writes.Trigger(l.writeBuffered)
+}
+
+// takeUnwrittenRepeats returns the last entry if its repeat counter still
+// needs to be written. Callers must hold l.mu.
+func (l *Logger) takeUnwrittenRepeats() (LogEntry, bool) {
+	last, ok := l.entries.Last()
+	if !l.unwrittenRepeats || !ok {
+		return LogEntry{}, false
+	}
+	l.unwrittenRepeats = false
+	
+	repeated := *last
+	repeated.Timestamp = repeated.LastSeen
+	return repeated, true
+}
+
+// Flush writes all buffered entries to the file, including the repeat
+// counter of a pending run of duplicates. It is safe to call multiple times.
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	if repeated, ok := l.takeUnwrittenRepeats(); ok {
+		l.buffered = append(l.buffered, repeated)
 	}
 	l.mu.Unlock()
 	
-	// Write to file in background
-	go l.writeToFile(entry)
+	l.writes.Trigger(l.writeBuffered)
+	l.writes.Flush()
 }
 
-func (l *Logger) writeToFile(entry LogEntry) {
+// writeBuffered appends all buffered entries to the log file in one write
+func (l *Logger) writeBuffered() {
+	l.mu.Lock()
+	entries := l.buffered
+	l.buffered = nil
+	l.mu.Unlock()
+	
+	if len(entries) == 0 {
+		return
+	}
+	
+	// created on first write so that merely loading the package, as tests
+	// do, leaves the working directory alone
+	os.MkdirAll(filepath.Dir(l.logFile), 0755)
 	file, err := os.OpenFile(l.logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return
 	}
 	defer file.Close()
 	
-	line := fmt.Sprintf("[%s] %s [%s] %s\n",
-		entry.Timestamp.Format("15:04:05"),
-		entry.Level.String(),
-		entry.Source,
-		entry.Message,
-	)
+	var lines strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&lines, "[%s] %s [%s] %s\n",
+			entry.Timestamp.Format(time.RFC3339),
+			entry.Level.String(),
+			entry.Source,
+			entry.DisplayMessage(),
+		)
+	}
 	
-	file.WriteString(line)
+	file.WriteString(lines.String())
 }
 
 func (l *Logger) GetRecentLogs(limit int) []LogEntry {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	
-	if limit <= 0 || limit > len(l.entries) {
-		limit = len(l.entries)
-	}
-	
-	if limit == 0 {
-		return []LogEntry{}
-	}
-	
-	start := len(l.entries) - limit
-	result := make([]LogEntry, limit)
-	copy(result, l.entries[start:])
-	
-	return result
+	return l.entries.Recent(limit)
 }
 
 func (l *Logger) GetLogsBySource(source string, limit int) []LogEntry {
@@ -130,11 +262,11 @@ func (l *Logger) GetLogsBySource(source string, limit int) []LogEntry {
 	defer l.mu.RUnlock()
 	
 	var filtered []LogEntry
-	for _, entry := range l.entries {
+	l.entries.Each(func(entry LogEntry) {
 		if entry.Source == source {
 			filtered = append(filtered, entry)
 		}
-	}
+	})
 	
 	if limit > 0 && len(filtered) > limit {
 		filtered = filtered[len(filtered)-limit:]
@@ -158,13 +290,21 @@ func NewStreamReader(source string, level LogLevel) *StreamReader {
 	}
 }
 
+// Read logs each line of reader until EOF. Lines longer than maxLineLength
+// are logged in marked chunks rather than dropped, and a read error is
+// logged instead of ending the capture silently.
 func (sr *StreamReader) Read(reader io.Reader) {
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) != "" {
-			sr.logger.Log(sr.level, sr.source, line)
+	err := readLines(reader, maxLineLength, func(line string, continued bool) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		if continued {
+			line += " " + lineContinuedMarker
 		}
+		sr.logger.Log(sr.level, sr.source, line)
+	})
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		sr.logger.Log(LogError, "cli", fmt.Sprintf("Stopped reading %s output: %v", sr.source, err))
 	}
 }
 
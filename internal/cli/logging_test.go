@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/debounce"
+	"github.com/mbarlow/local-first/internal/ringbuf"
+)
+
+// newTestLogger returns a logger writing to a temp file
+func newTestLogger(t *testing.T) *Logger {
+	return &Logger{
+		entries:     ringbuf.New[LogEntry](maxEntries),
+		logFile:     filepath.Join(t.TempDir(), "cli.log"),
+		dedupWindow: DefaultDedupWindow,
+		writes:      debounce.New(debounce.DefaultDelay, 2*time.Second),
+		maxBytes:    DefaultMaxLogBytes,
+	}
+}
+
+// useTestLogger replaces the global logger with a temp-file one for the
+// rest of the test, so code under test doesn't write into the package
+// directory
+func useTestLogger(t *testing.T) *Logger {
+	t.Helper()
+	prev := globalLogger
+	globalLogger = newTestLogger(t)
+	t.Cleanup(func() {
+		globalLogger.Flush()
+		globalLogger = prev
+	})
+	return globalLogger
+}
+
+func TestLoggerDedup(t *testing.T) {
+	type line struct {
+		level   LogLevel
+		source  string
+		message string
+	}
+	tests := []struct {
+		name   string
+		window time.Duration
+		lines  []line
+		want   []string
+	}{
+		{
+			name:   "repeats coalesce",
+			window: DefaultDedupWindow,
+			lines:  []line{{LogInfo, "server", "ping"}, {LogInfo, "server", "ping "}, {LogInfo, "server", "ping"}},
+			want:   []string{"ping (repeated 3x)"},
+		},
+		{
+			name:   "different source",
+			window: DefaultDedupWindow,
+			lines:  []line{{LogInfo, "server", "ping"}, {LogInfo, "wasm", "ping"}},
+			want:   []string{"ping", "ping"},
+		},
+		{
+			name:   "different level",
+			window: DefaultDedupWindow,
+			lines:  []line{{LogInfo, "server", "ping"}, {LogError, "server", "ping"}},
+			want:   []string{"ping", "ping"},
+		},
+		{
+			name:   "only consecutive repeats",
+			window: DefaultDedupWindow,
+			lines:  []line{{LogInfo, "server", "a"}, {LogInfo, "server", "b"}, {LogInfo, "server", "a"}},
+			want:   []string{"a", "b", "a"},
+		},
+		{
+			name:  "disabled",
+			lines: []line{{LogInfo, "server", "ping"}, {LogInfo, "server", "ping"}},
+			want:  []string{"ping", "ping"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLogger(t)
+			l.SetDedupWindow(tt.window)
+			for _, ln := range tt.lines {
+				l.Log(ln.level, ln.source, ln.message)
+			}
+			var got []string
+			for _, entry := range l.GetRecentLogs(0) {
+				got = append(got, entry.DisplayMessage())
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggerDedupWindowExpires(t *testing.T) {
+	l := newTestLogger(t)
+	l.SetDedupWindow(time.Millisecond)
+	l.Log(LogInfo, "server", "ping")
+	time.Sleep(5 * time.Millisecond)
+	l.Log(LogInfo, "server", "ping")
+
+	if n := len(l.GetRecentLogs(0)); n != 2 {
+		t.Errorf("got %d entries after the window, want 2", n)
+	}
+}
+
+func TestLoggerFlushWritesRepeatCount(t *testing.T) {
+	l := newTestLogger(t)
+	l.Log(LogInfo, "server", "ping")
+	l.Log(LogInfo, "server", "ping")
+	l.Log(LogInfo, "server", "ping")
+	l.Flush()
+	l.Flush()
+
+	data, err := os.ReadFile(l.logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want the first entry and one repeat count:\n%s", len(lines), data)
+	}
+	if !strings.HasSuffix(lines[0], "[server] ping") || !strings.HasSuffix(lines[1], "[server] ping (repeated 3x)") {
+		t.Errorf("unexpected log file:\n%s", data)
+	}
+}
+
+func TestLoggerMaxBytes(t *testing.T) {
+	logger := newTestLogger(t)
+	logger.SetDedupWindow(0)
+	line := strings.Repeat("x", 100)
+	perEntry := LogEntry{Source: "server", Message: line}.size()
+	logger.SetMaxBytes(perEntry * 3)
+
+	for i := 0; i < 10; i++ {
+		logger.Log(LogInfo, "server", line)
+	}
+	if got := len(logger.GetRecentLogs(maxEntries)); got != 3 {
+		t.Errorf("kept %d entries, want 3", got)
+	}
+	if logger.entryBytes != perEntry*3 {
+		t.Errorf("entryBytes = %d, want %d", logger.entryBytes, perEntry*3)
+	}
+
+	// A single entry larger than the cap is still kept
+	logger.Log(LogInfo, "server", strings.Repeat("y", perEntry*4))
+	logs := logger.GetRecentLogs(maxEntries)
+	if len(logs) != 1 || logs[0].Message[0] != 'y' {
+		t.Errorf("kept %d entries after an oversized one", len(logs))
+	}
+
+	// Without a byte cap only the entry count applies
+	logger.SetMaxBytes(0)
+	for i := 0; i < maxEntries+10; i++ {
+		logger.Log(LogInfo, "server", line)
+	}
+	if got := len(logger.GetRecentLogs(maxEntries + 10)); got != maxEntries {
+		t.Errorf("kept %d entries, want %d", got, maxEntries)
+	}
+	logger.Flush()
+}
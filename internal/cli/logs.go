@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,56 +17,204 @@ type RequestLogsMsg struct {
 	Logs []RequestLog
 }
 
+func requestLogFile() string {
+	return filepath.Join(".", ".local-first", "requests.jsonl")
+}
+
 func (m DashboardModel) loadRequestLogs() tea.Cmd {
 	return func() tea.Msg {
-		logFile := filepath.Join(".", ".local-first", "requests.jsonl")
-		
-		// Check if file exists
-		if _, err := os.Stat(logFile); os.IsNotExist(err) {
-			return RequestLogsMsg{Logs: []RequestLog{}}
+		return RequestLogsMsg{Logs: readRequestLogs(50)}
+	}
+}
+
+// readRequestLogs parses the last limit entries of the request log file
+func readRequestLogs(limit int) []RequestLog {
+	logs, _, _ := readRequestLogsEnd(limit)
+	return logs
+}
+
+// readRequestLogsEnd is readRequestLogs that also returns the offset where
+// reading stopped and the file's info, for following the file from there
+func readRequestLogsEnd(limit int) ([]RequestLog, int64, os.FileInfo) {
+	lines, end, info := readLogLines(requestLogFile())
+	
+	// Parse the last lines (most recent logs)
+	start := len(lines) - limit
+	if start < 0 || limit <= 0 {
+		start = 0
+	}
+	
+	logs := []RequestLog{}
+	for _, line := range lines[start:] {
+		if log, ok := parseRequestLogLine(line); ok {
+			logs = append(logs, log)
 		}
-		
-		data, err := os.ReadFile(logFile)
+	}
+	
+	return logs, end, info
+}
+
+// readLogLines reads the complete lines of a log file. It also returns the
+// offset just past the last complete line, where a fileTail can continue
+// without missing or repeating a line, and the file's info.
+func readLogLines(path string) ([]string, int64, os.FileInfo) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, nil
+	}
+	defer file.Close()
+	
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, nil
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, 0, info
+	}
+	
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if end == 0 {
+		return nil, 0, info
+	}
+	lines := strings.Split(string(data[:end-1]), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, int64(end), info
+}
+
+func parseRequestLogLine(line string) (RequestLog, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return RequestLog{}, false
+	}
+	
+	var log struct {
+		Timestamp time.Time `json:"timestamp"`
+		Method    string    `json:"method"`
+		Path      string    `json:"path"`
+		Status    int       `json:"status"`
+		Duration  int64     `json:"duration_ms"`
+	}
+	
+	if err := json.Unmarshal([]byte(line), &log); err != nil {
+		return RequestLog{}, false
+	}
+	
+	return RequestLog{
+		Timestamp: log.Timestamp,
+		Method:    log.Method,
+		Path:      log.Path,
+		Status:    log.Status,
+		Duration:  time.Duration(log.Duration) * time.Millisecond,
+	}, true
+}
+
+var logLinePattern = regexp.MustCompile(`^\[([^\]]+)\] (\w+) \[([^\]]*)\] (.*)$`)
+
+// parseLogLine parses a line written by Logger.writeBuffered, which records
+// an RFC 3339 timestamp. Older files only recorded the time of day; those
+// entries are placed on day, such as the date the file was last written.
+func parseLogLine(line string, day time.Time) (LogEntry, bool) {
+	match := logLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return LogEntry{}, false
+	}
+	
+	level, ok := ParseLogLevel(match[2])
+	if !ok {
+		return LogEntry{}, false
+	}
+	
+	timestamp, err := time.Parse(time.RFC3339, match[1])
+	if err != nil {
+		t, err := time.ParseInLocation("15:04:05", match[1], time.Local)
 		if err != nil {
-			return RequestLogsMsg{Logs: []RequestLog{}}
+			return LogEntry{}, false
 		}
-		
-		lines := strings.Split(string(data), "\n")
-		var logs []RequestLog
-		
-		// Parse the last 50 lines (most recent logs)
-		start := len(lines) - 51 // Extra line for empty line at end
-		if start < 0 {
-			start = 0
+		y, mo, d := day.Date()
+		timestamp = time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+	}
+	
+	return LogEntry{
+		Timestamp: timestamp,
+		Level:     level,
+		Source:    match[3],
+		Message:   match[4],
+	}, true
+}
+
+// readLogFile parses the last limit entries of the CLI log file and returns
+// the offset where reading stopped and the file's info, for following the
+// file from there
+func readLogFile(limit int) ([]LogEntry, int64, os.FileInfo) {
+	lines, end, info := readLogLines(GetLogger().logFile)
+	day := time.Now()
+	if info != nil {
+		day = info.ModTime()
+	}
+	
+	var entries []LogEntry
+	for _, line := range lines {
+		if entry, ok := parseLogLine(line, day); ok {
+			entries = append(entries, entry)
+		}
+	}
+	
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	
+	return entries, end, info
+}
+
+// ParseLogLevel converts a level name such as "warn" or "ERROR" to a LogLevel
+func ParseLogLevel(name string) (LogLevel, bool) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "INFO":
+		return LogInfo, true
+	case "WARN", "WARNING":
+		return LogWarning, true
+	case "ERROR":
+		return LogError, true
+	case "DEBUG":
+		return LogDebug, true
+	case "SYSTEM":
+		return LogSystem, true
+	default:
+		return LogInfo, false
+	}
+}
+
+// LogFilter selects log entries by source and level. Zero values match
+// everything.
+type LogFilter struct {
+	Source string
+	Level  string
+}
+
+// Matches reports whether the entry passes the filter
+func (f LogFilter) Matches(entry LogEntry) bool {
+	if f.Source != "" && !strings.EqualFold(entry.Source, f.Source) {
+		return false
+	}
+	if f.Level != "" {
+		level, ok := ParseLogLevel(f.Level)
+		if !ok || entry.Level != level {
+			return false
 		}
-		
-		for i := start; i < len(lines); i++ {
-			line := strings.TrimSpace(lines[i])
-			if line == "" {
-				continue
-			}
-			
-			var log struct {
-				Timestamp time.Time `json:"timestamp"`
-				Method    string    `json:"method"`
-				Path      string    `json:"path"`
-				Status    int       `json:"status"`
-				Duration  int64     `json:"duration_ms"`
-			}
-			
-			if err := json.Unmarshal([]byte(line), &log); err != nil {
-				continue
-			}
-			
-			logs = append(logs, RequestLog{
-				Timestamp: log.Timestamp,
-				Method:    log.Method,
-				Path:      log.Path,
-				Status:    log.Status,
-				Duration:  time.Duration(log.Duration) * time.Millisecond,
-			})
+	}
+	return true
+}
+
+// Apply returns the entries that match the filter
+func (f LogFilter) Apply(entries []LogEntry) []LogEntry {
+	filtered := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if f.Matches(entry) {
+			filtered = append(filtered, entry)
 		}
-		
-		return RequestLogsMsg{Logs: logs}
 	}
-}
\ No newline at end of file
+	return filtered
+}
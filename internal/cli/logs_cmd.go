@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// requestSource is the pseudo-source used for entries from the request log
+const requestSource = "requests"
+
+var LogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Print recent logs",
+	Long:  "Print recent request and CLI logs from the .local-first directory without launching the dashboard",
+	Run: func(cmd *cobra.Command, args []string) {
+		source, _ := cmd.Flags().GetString("source")
+		level, _ := cmd.Flags().GetString("level")
+		limit, _ := cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		if level != "" {
+			if _, ok := ParseLogLevel(level); !ok {
+				printError("Unknown log level: %s", level)
+				os.Exit(1)
+			}
+		}
+
+		filter := LogFilter{Source: source, Level: level}
+
+		records, tails := collectLogRecords(filter, limit)
+		for _, rec := range records {
+			printLogRecord(os.Stdout, rec, asJSON)
+		}
+
+		if follow {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			followLogs(ctx, filter, asJSON, tails)
+		}
+	},
+}
+
+func init() {
+	LogsCmd.Flags().StringP("source", "s", "", "Only show logs from this source (e.g. cli, server, requests)")
+	LogsCmd.Flags().StringP("level", "l", "", "Only show logs at this level (info, warn, error, debug, system)")
+	LogsCmd.Flags().IntP("limit", "n", 50, "Maximum number of entries to print (0 for all)")
+	LogsCmd.Flags().Bool("json", false, "Print entries as JSON lines")
+	LogsCmd.Flags().BoolP("follow", "f", false, "Keep printing new entries as they are written")
+}
+
+// logRecord is a single line of output from either log file
+type logRecord struct {
+	entry   LogEntry
+	request *RequestLog
+}
+
+// requestLevel maps an HTTP status to a log level so request logs can be
+// filtered with the same --level flag as CLI logs
+func requestLevel(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return LogError
+	case status >= 400:
+		return LogWarning
+	default:
+		return LogInfo
+	}
+}
+
+func requestRecord(req RequestLog) logRecord {
+	return logRecord{
+		entry: LogEntry{
+			Timestamp: req.Timestamp,
+			Level:     requestLevel(req.Status),
+			Source:    requestSource,
+			Message: fmt.Sprintf("%s %s %d %dms",
+				req.Method, req.Path, req.Status, req.Duration.Milliseconds()),
+		},
+		request: &req,
+	}
+}
+
+// logTails follow the CLI and request log files from where
+// collectLogRecords stopped reading them
+type logTails struct {
+	cli, requests *fileTail
+}
+
+func collectLogRecords(filter LogFilter, limit int) ([]logRecord, logTails) {
+	var records []logRecord
+
+	entries, cliEnd, cliInfo := readLogFile(0)
+	for _, entry := range filter.Apply(entries) {
+		records = append(records, logRecord{entry: entry})
+	}
+
+	requests, requestsEnd, requestsInfo := readRequestLogsEnd(0)
+	tails := logTails{
+		cli:      newFileTailAt(GetLogger().logFile, cliInfo, cliEnd),
+		requests: newFileTailAt(requestLogFile(), requestsInfo, requestsEnd),
+	}
+
+	for _, req := range requests {
+		rec := requestRecord(req)
+		if filter.Matches(rec.entry) {
+			records = append(records, rec)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].entry.Timestamp.Before(records[j].entry.Timestamp)
+	})
+
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	return records, tails
+}
+
+func printLogRecord(w io.Writer, rec logRecord, asJSON bool) {
+	if !asJSON {
+		fmt.Fprintf(w, "[%s] %-6s [%s] %s\n",
+			rec.entry.Timestamp.Format("15:04:05"),
+			rec.entry.Level.String(),
+			rec.entry.Source,
+			rec.entry.Message,
+		)
+		return
+	}
+
+	out := map[string]interface{}{
+		"timestamp": rec.entry.Timestamp.Format(time.RFC3339),
+		"level":     rec.entry.Level.String(),
+		"source":    rec.entry.Source,
+		"message":   rec.entry.Message,
+	}
+	if rec.request != nil {
+		out["method"] = rec.request.Method
+		out["path"] = rec.request.Path
+		out["status"] = rec.request.Status
+		out["duration_ms"] = rec.request.Duration.Milliseconds()
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
+
+func followLogs(ctx context.Context, filter LogFilter, asJSON bool, tails logTails) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, line := range tails.cli.readLines() {
+			if entry, ok := parseLogLine(line, time.Now()); ok && filter.Matches(entry) {
+				printLogRecord(os.Stdout, logRecord{entry: entry}, asJSON)
+			}
+		}
+
+		for _, line := range tails.requests.readLines() {
+			if req, ok := parseRequestLogLine(line); ok {
+				if rec := requestRecord(req); filter.Matches(rec.entry) {
+					printLogRecord(os.Stdout, rec, asJSON)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	day := time.Date(2024, 3, 9, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		source string
+		ok     bool
+	}{
+		{
+			name:   "rfc3339",
+			line:   "[2024-03-07T10:11:12Z] INFO [server] started",
+			want:   time.Date(2024, 3, 7, 10, 11, 12, 0, time.UTC),
+			source: "server",
+			ok:     true,
+		},
+		{
+			name:   "time of day uses the given day",
+			line:   "[10:11:12] WARN [build] slow",
+			want:   time.Date(2024, 3, 9, 10, 11, 12, 0, time.Local),
+			source: "build",
+			ok:     true,
+		},
+		{name: "unknown level", line: "[10:11:12] LOUD [build] slow"},
+		{name: "bad timestamp", line: "[yesterday] INFO [build] slow"},
+		{name: "not a log line", line: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := parseLogLine(tt.line, day)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !entry.Timestamp.Equal(tt.want) {
+				t.Errorf("timestamp = %v, want %v", entry.Timestamp, tt.want)
+			}
+			if entry.Source != tt.source {
+				t.Errorf("source = %q, want %q", entry.Source, tt.source)
+			}
+		})
+	}
+}
+
+func TestReadLogLinesThenTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\r\ntwo\nthr"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, end, info := readLogLines(path)
+	if want := []string{"one", "two"}; !reflect.DeepEqual(lines, want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	if end != 9 {
+		t.Fatalf("end = %d, want 9", end)
+	}
+
+	// Lines written between the initial read and following must not be lost
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("ee\nfour\n")
+	file.Close()
+
+	tail := newFileTailAt(path, info, end)
+	if got, want := tail.readLines(), []string{"three", "four"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("tail = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+	successStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("42"))
+	failureStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
+)
+
+func init() {
+	cobra.OnInitialize(setupColor)
+}
+
+// ColorEnabled reports whether styled output should be emitted. Color is
+// disabled when NO_COLOR is set (see https://no-color.org) or when stdout is
+// not a terminal, e.g. when piped to a file.
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// setupColor forces lipgloss into plain ASCII mode when color is disabled so
+// every style rendered afterwards, including the dashboard's, emits no ANSI
+// escape sequences.
+func setupColor() {
+	if !ColorEnabled() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+func printStep(format string, a ...interface{}) {
+	if !verbosity.Allows(LogInfo) {
+		return
+	}
+	fmt.Fprintln(os.Stdout, stepStyle.Render(fmt.Sprintf(format, a...)))
+}
+
+func printSuccess(format string, a ...interface{}) {
+	if !verbosity.Allows(LogInfo) {
+		return
+	}
+	fmt.Fprintln(os.Stdout, successStyle.Render(fmt.Sprintf(format, a...)))
+}
+
+// printError writes to stderr, which may be redirected independently of
+// stdout, so it checks the terminal state separately.
+func printError(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	if os.Getenv("NO_COLOR") == "" && isTerminal(os.Stderr) {
+		msg = failureStyle.Render(msg)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mbarlow/local-first/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// requiredAssets must exist in web/ for the bundle to work
+var requiredAssets = []string{"index.html", "main.wasm", "wasm_exec.js"}
+
+// headersFile configures COOP/COEP on Netlify and Cloudflare Pages
+const headersFile = `# Cross-origin isolation headers required for SharedArrayBuffer and WASM.
+# Netlify and Cloudflare Pages read this file from the publish directory.
+/*
+  Cross-Origin-Embedder-Policy: require-corp
+  Cross-Origin-Opener-Policy: same-origin
+
+/*.wasm
+  Content-Type: application/wasm
+`
+
+// vercelConfig configures the same headers on Vercel
+const vercelConfig = `{
+  "headers": [
+    {
+      "source": "/(.*)",
+      "headers": [
+        { "key": "Cross-Origin-Embedder-Policy", "value": "require-corp" },
+        { "key": "Cross-Origin-Opener-Policy", "value": "same-origin" }
+      ]
+    },
+    {
+      "source": "/(.*).wasm",
+      "headers": [
+        { "key": "Content-Type", "value": "application/wasm" }
+      ]
+    }
+  ]
+}
+`
+
+var PackageCmd = &cobra.Command{
+	Use:   "package",
+	Short: "Bundle the app for static hosting",
+	Long:  "Build the production WASM module and gather web assets into a self-contained static bundle with header configs for common hosts",
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		makeZip, _ := cmd.Flags().GetBool("zip")
+		skipBuild, _ := cmd.Flags().GetBool("skip-build")
+
+		if !skipBuild {
+			printStep("Building WASM...")
+			if err := runMakeTarget("wasm-prod"); err != nil {
+				printError("Error building WASM: %v", err)
+				os.Exit(1)
+			}
+		}
+
+		size, err := packageAssets("web", out)
+		if err != nil {
+			printError("Error packaging: %v", err)
+			os.Exit(1)
+		}
+
+		printSuccess("Bundle written to %s (%s)", out, core.FormatBytes(size, true))
+
+		if makeZip {
+			zipPath := strings.TrimRight(out, string(os.PathSeparator)) + ".zip"
+			zipSize, err := zipDir(out, zipPath)
+			if err != nil {
+				printError("Error creating zip: %v", err)
+				os.Exit(1)
+			}
+			printSuccess("Zip written to %s (%s)", zipPath, core.FormatBytes(zipSize, true))
+		}
+	},
+}
+
+func init() {
+	PackageCmd.Flags().StringP("out", "o", "release", "Output directory for the bundle")
+	PackageCmd.Flags().Bool("zip", false, "Also create a zip archive of the bundle")
+	PackageCmd.Flags().Bool("skip-build", false, "Package existing assets without rebuilding WASM")
+}
+
+// packageAssets copies the web assets into out along with host header
+// configs and returns the total bundle size in bytes
+func packageAssets(webDir, out string) (int64, error) {
+	for _, name := range requiredAssets {
+		if _, err := os.Stat(filepath.Join(webDir, name)); err != nil {
+			return 0, fmt.Errorf("required asset %s is missing from %s", name, webDir)
+		}
+	}
+
+	if err := os.RemoveAll(out); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err := filepath.WalkDir(webDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(webDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(out, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		n, err := copyFile(path, target)
+		total += n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for name, content := range map[string]string{
+		"_headers":    headersFile,
+		"vercel.json": vercelConfig,
+	} {
+		if err := os.WriteFile(filepath.Join(out, name), []byte(content), 0644); err != nil {
+			return 0, err
+		}
+		total += int64(len(content))
+	}
+
+	return total, nil
+}
+
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	outFile, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(outFile, in)
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// zipDir archives dir into zipPath and returns the archive size
+func zipDir(dir, zipPath string) (int64, error) {
+	file, err := os.Create(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		w, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/mbarlow/local-first/internal/debounce"
+)
+
+// dashboardPrefs is dashboard state remembered between sessions
+type dashboardPrefs struct {
+	SelectedTab int `json:"selected_tab"`
+}
+
+var (
+	prefsFile   = filepath.Join(".", ".local-first", "dashboard.json")
+	prefsWrites = debounce.New(debounce.DefaultDelay, 2*debounce.DefaultDelay)
+)
+
+// loadPrefs reads saved preferences, returning defaults if there are none
+func loadPrefs() dashboardPrefs {
+	var prefs dashboardPrefs
+	data, err := os.ReadFile(prefsFile)
+	if err != nil {
+		return prefs
+	}
+	json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+// savePrefs schedules a write of the preferences. Rapid changes, such as
+// cycling through tabs, result in a single write of the latest state.
+func savePrefs(prefs dashboardPrefs) {
+	prefsWrites.Trigger(func() {
+		data, err := json.MarshalIndent(prefs, "", "  ")
+		if err != nil {
+			return
+		}
+		if err := os.WriteFile(prefsFile, data, 0644); err != nil {
+			GetLogger().Log(LogWarning, "cli", "Failed to save dashboard preferences: "+err.Error())
+		}
+	})
+}
+
+// flushPrefs writes any pending preference change immediately
+func flushPrefs() {
+	prefsWrites.Flush()
+}
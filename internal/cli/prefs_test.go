@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrefsSaveAndLoad(t *testing.T) {
+	old := prefsFile
+	prefsFile = filepath.Join(t.TempDir(), "dashboard.json")
+	t.Cleanup(func() { prefsFile = old })
+
+	if prefs := loadPrefs(); prefs != (dashboardPrefs{}) {
+		t.Errorf("got %+v without a file, want defaults", prefs)
+	}
+
+	for tab := 1; tab <= 3; tab++ {
+		savePrefs(dashboardPrefs{SelectedTab: tab})
+	}
+	if _, err := os.Stat(prefsFile); !os.IsNotExist(err) {
+		t.Errorf("preferences were written before the debounce delay: %v", err)
+	}
+
+	flushPrefs()
+	if prefs := loadPrefs(); prefs.SelectedTab != 3 {
+		t.Errorf("loaded tab %d, want the last saved tab 3", prefs.SelectedTab)
+	}
+
+	if err := os.WriteFile(prefsFile, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if prefs := loadPrefs(); prefs != (dashboardPrefs{}) {
+		t.Errorf("got %+v from a corrupt file, want defaults", prefs)
+	}
+}
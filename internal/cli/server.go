@@ -3,13 +3,16 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
 )
 
 type ServerStatusMsg struct {
@@ -21,10 +24,21 @@ type ServerStatusMsg struct {
 type ServerProcess struct {
 	cmd    *exec.Cmd
 	cancel context.CancelFunc
+	done   chan struct{} // closed once the process has exited
 }
 
 var currentServer *ServerProcess
 
+// Defaults for server.breaker_threshold and server.breaker_cooldown
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// startBreaker stops start attempts after repeated consecutive failures.
+// initConfig replaces it with one using the configured limits.
+var startBreaker = newCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)
+
 func (m DashboardModel) checkServerStatus() tea.Cmd {
 	return func() tea.Msg {
 		port := m.server.Port
@@ -58,16 +72,12 @@ func (m DashboardModel) startServer() tea.Cmd {
 			}
 		}
 		
-		// Start the server
-		ctx, cancel := context.WithCancel(context.Background())
-		
 		logger := GetLogger()
 		
 		// Build WASM first
 		logger.Log(LogSystem, "cli", "Building WASM...")
 		buildCmd := exec.Command("make", "wasm")
 		if err := buildCmd.Run(); err != nil {
-			cancel()
 			logger.Log(LogError, "cli", fmt.Sprintf("Failed to build WASM: %v", err))
 			return ServerStatusMsg{
 				Status: ServerStopped,
@@ -80,7 +90,6 @@ func (m DashboardModel) startServer() tea.Cmd {
 		logger.Log(LogSystem, "cli", "Building server...")
 		buildServerCmd := exec.Command("make", "server")
 		if err := buildServerCmd.Run(); err != nil {
-			cancel()
 			logger.Log(LogError, "cli", fmt.Sprintf("Failed to build server: %v", err))
 			return ServerStatusMsg{
 				Status: ServerStopped,
@@ -89,69 +98,130 @@ func (m DashboardModel) startServer() tea.Cmd {
 		}
 		logger.Log(LogSystem, "cli", "Server build completed")
 		
-		logger.Log(LogSystem, "cli", fmt.Sprintf("Starting server on port %d", port))
-		
-		// Start server using the built binary
-		cmd := exec.CommandContext(ctx, "./bin/server", 
-			"-dev", "-port", strconv.Itoa(port))
-		
-		// Set working directory to current directory
-		cmd.Dir = "."
-		
-		// Set up process group so we can kill child processes  
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-		
-		// Capture server output and pipe to logger
-		stdoutReader, err := cmd.StdoutPipe()
-		if err != nil {
-			cancel()
-			logger.Log(LogError, "cli", fmt.Sprintf("Failed to create stdout pipe: %v", err))
-			return ServerStatusMsg{
-				Status: ServerStopped,
-				Error:  fmt.Errorf("failed to create stdout pipe: %w", err),
-			}
-		}
-		
-		stderrReader, err := cmd.StderrPipe()
-		if err != nil {
-			cancel()
-			logger.Log(LogError, "cli", fmt.Sprintf("Failed to create stderr pipe: %v", err))
+		if !startBreaker.Allow() {
+			logger.Log(LogError, "cli", "Server start skipped: too many consecutive failures, waiting for cooldown")
 			return ServerStatusMsg{
 				Status: ServerStopped,
-				Error:  fmt.Errorf("failed to create stderr pipe: %w", err),
+				Error:  fmt.Errorf("giving up: server failed to start %d times in a row, try again later", startBreaker.Failures()),
 			}
 		}
 		
-		if err := cmd.Start(); err != nil {
-			cancel()
-			logger.Log(LogError, "cli", fmt.Sprintf("Failed to start server: %v", err))
-			return ServerStatusMsg{
-				Status: ServerStopped,
-				Error:  fmt.Errorf("failed to start server: %w", err),
-			}
+		attempts := viper.GetInt("server.start_retries") + 1
+		if attempts < 1 {
+			attempts = 1
 		}
 		
-		logger.Log(LogSystem, "cli", fmt.Sprintf("Server started with PID %d", cmd.Process.Pid))
-		
-		// Start goroutines to read server output
-		go NewStreamReader("server", LogInfo).Read(stdoutReader)
-		go NewStreamReader("server", LogError).Read(stderrReader)
+		var lastErr error
+		backoff := 500 * time.Millisecond
 		
-		currentServer = &ServerProcess{
-			cmd:    cmd,
-			cancel: cancel,
+		for attempt := 1; attempt <= attempts; attempt++ {
+			logger.Log(LogSystem, "cli", fmt.Sprintf("Starting server on port %d (attempt %d/%d)", port, attempt, attempts))
+			
+			proc, err := launchServer(port)
+			if err == nil {
+				startBreaker.Success()
+				currentServer = proc
+				logger.Log(LogSystem, "cli", "Server startup completed")
+				
+				return ServerStatusMsg{
+					Status: ServerRunning,
+					PID:    proc.cmd.Process.Pid,
+				}
+			}
+			
+			lastErr = err
+			startBreaker.Failure()
+			logger.Log(LogError, "cli", fmt.Sprintf("Start attempt %d failed: %v", attempt, err))
+			
+			if attempt < attempts && startBreaker.Allow() {
+				logger.Log(LogSystem, "cli", fmt.Sprintf("Retrying in %s...", backoff))
+				time.Sleep(backoff)
+				backoff *= 2
+				continue
+			}
+			break
 		}
 		
-		// Wait a moment for server to start
-		time.Sleep(500 * time.Millisecond)
-		
-		logger.Log(LogSystem, "cli", "Server startup completed")
-		
+		logger.Log(LogError, "cli", "Giving up on starting the server")
 		return ServerStatusMsg{
-			Status: ServerRunning,
-			PID:    cmd.Process.Pid,
+			Status: ServerStopped,
+			Error:  fmt.Errorf("giving up after repeated failures: %w", lastErr),
+		}
+	}
+}
+
+// launchServer starts the server binary and waits briefly to confirm it
+// stays up. If it exits early, the error includes its last stderr output.
+func launchServer(port int) (*ServerProcess, error) {
+	logger := GetLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	
+	// Start server using the built binary
+	cmd := exec.CommandContext(ctx, "./bin/server", 
+		"-dev", "-port", strconv.Itoa(port))
+	
+	// Set working directory to current directory
+	cmd.Dir = "."
+	
+	// Set up process group so we can kill child processes  
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	
+	// Capture server output and pipe to logger
+	stdoutReader, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	
+	stderrReader, err := cmd.StderrPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+	
+	logger.Log(LogSystem, "cli", fmt.Sprintf("Server started with PID %d", cmd.Process.Pid))
+	
+	// Read server output, keeping the tail of stderr for error reports
+	stderrTail := newLineTail(5)
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		NewStreamReader("server", LogInfo).Read(stdoutReader)
+	}()
+	go func() {
+		defer readers.Done()
+		NewStreamReader("server", LogError).Read(io.TeeReader(stderrReader, stderrTail))
+	}()
+	
+	done := make(chan struct{})
+	go func() {
+		readers.Wait()
+		cmd.Wait()
+		close(done)
+	}()
+	
+	// Wait a moment for server to start and make sure it didn't exit
+	select {
+	case <-done:
+		cancel()
+		if tail := stderrTail.String(); tail != "" {
+			return nil, fmt.Errorf("server exited during startup: %s", tail)
 		}
+		return nil, fmt.Errorf("server exited during startup")
+	case <-time.After(500 * time.Millisecond):
 	}
+	
+	return &ServerProcess{
+		cmd:    cmd,
+		cancel: cancel,
+		done:   done,
+	}, nil
 }
 
 func (m DashboardModel) stopServer() tea.Cmd {
@@ -164,6 +234,7 @@ func (m DashboardModel) stopServer() tea.Cmd {
 			// Store references before setting to nil
 			cmd := currentServer.cmd
 			cancel := currentServer.cancel
+			done := currentServer.done
 			
 			// Cancel context
 			cancel()
@@ -180,7 +251,7 @@ func (m DashboardModel) stopServer() tea.Cmd {
 				
 				// Wait for process to exit
 				go func() {
-					cmd.Wait()
+					<-done
 					logger.Log(LogSystem, "cli", "Server process has exited")
 				}()
 			}
@@ -274,4 +345,105 @@ func killProcessByPort(port int) error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// circuitBreaker opens after threshold consecutive failures and rejects
+// attempts until cooldown has passed
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	now       func() time.Time // replaced in tests
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether another attempt may be made
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if b.failures < b.threshold {
+		return true
+	}
+	
+	// Half-open: permit a single attempt once the cooldown has passed
+	if b.now().Sub(b.openedAt) >= b.cooldown {
+		b.failures = b.threshold - 1
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+func (b *circuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// lineTail is an io.Writer that keeps the last few lines written to it
+type lineTail struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial string
+}
+
+func newLineTail(max int) *lineTail {
+	return &lineTail{max: max}
+}
+
+func (t *lineTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	parts := strings.Split(t.partial+string(p), "\n")
+	t.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			t.lines = append(t.lines, line)
+		}
+	}
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+	return len(p), nil
+}
+
+// String returns the captured lines joined with " | "
+func (t *lineTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	lines := t.lines
+	if p := strings.TrimSpace(t.partial); p != "" {
+		lines = append(lines, p)
+	}
+	return strings.Join(lines, " | ")
+}
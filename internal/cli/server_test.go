@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// fakeClock is a settable time source for the circuit breaker
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	b := newCircuitBreaker(threshold, cooldown)
+	b.now = clock.now
+	return b, clock
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	for i := 0; i < 2; i++ {
+		b.Failure()
+		if !b.Allow() {
+			t.Fatalf("closed after %d failures, want open only at 3", i+1)
+		}
+	}
+	b.Failure()
+	if b.Allow() {
+		t.Error("allowed an attempt after reaching the threshold")
+	}
+	if b.Failures() != 3 {
+		t.Errorf("failures = %d, want 3", b.Failures())
+	}
+}
+
+func TestCircuitBreakerSuccessResets(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	if !b.Allow() {
+		t.Error("failures before a success still counted")
+	}
+}
+
+func TestCircuitBreakerCooldown(t *testing.T) {
+	b, clock := newTestBreaker(2, 30*time.Second)
+	b.Failure()
+	b.Failure()
+
+	clock.advance(29 * time.Second)
+	if b.Allow() {
+		t.Fatal("allowed an attempt before the cooldown passed")
+	}
+
+	// Half-open: one attempt, and a failure reopens at once
+	clock.advance(time.Second)
+	if !b.Allow() {
+		t.Fatal("rejected an attempt after the cooldown")
+	}
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("allowed a second attempt after a half-open failure")
+	}
+
+	// The cooldown restarts from the latest failure
+	clock.advance(30 * time.Second)
+	if !b.Allow() {
+		t.Fatal("rejected an attempt after the second cooldown")
+	}
+	b.Success()
+	b.Failure()
+	if !b.Allow() {
+		t.Error("a success did not close the breaker")
+	}
+}
+
+func TestCircuitBreakerThresholdFloor(t *testing.T) {
+	b, _ := newTestBreaker(0, time.Minute)
+	if !b.Allow() {
+		t.Fatal("rejected the first attempt")
+	}
+	b.Failure()
+	if b.Allow() {
+		t.Error("a zero threshold should open after one failure")
+	}
+}
+
+func TestBreakerConfig(t *testing.T) {
+	defer viper.Reset()
+	defer func(b *circuitBreaker) { startBreaker = b }(startBreaker)
+
+	viper.Set("server.breaker_threshold", 2)
+	viper.Set("server.breaker_cooldown", "1m")
+	initConfig()
+	if startBreaker.threshold != 2 || startBreaker.cooldown != time.Minute {
+		t.Errorf("breaker = %d/%s, want 2/1m", startBreaker.threshold, startBreaker.cooldown)
+	}
+}
+
+func TestLineTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		writes []string
+		want   string
+	}{
+		{name: "empty", max: 3, want: ""},
+		{name: "lines", max: 3, writes: []string{"a\nb\n"}, want: "a | b"},
+		{name: "keeps the last lines", max: 2, writes: []string{"a\nb\nc\nd\n"}, want: "c | d"},
+		{name: "lines split across writes", max: 3, writes: []string{"hel", "lo\nwor", "ld\n"}, want: "hello | world"},
+		{name: "partial line included", max: 3, writes: []string{"a\nb"}, want: "a | b"},
+		{name: "blank lines and padding dropped", max: 3, writes: []string{"  a  \n\n \nb\n"}, want: "a | b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tail := newLineTail(tt.max)
+			for _, w := range tt.writes {
+				if n, err := tail.Write([]byte(w)); n != len(w) || err != nil {
+					t.Fatalf("Write = %d, %v", n, err)
+				}
+			}
+			if got := tail.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the named style roles used when rendering the dashboard
+type Theme struct {
+	Name        string
+	Title       lipgloss.Style
+	ActiveTab   lipgloss.Style
+	InactiveTab lipgloss.Style
+	Header      lipgloss.Style
+	Muted       lipgloss.Style
+	Link        lipgloss.Style
+	StatusOK    lipgloss.Style
+	StatusWarn  lipgloss.Style
+	StatusErr   lipgloss.Style
+	Info        lipgloss.Style
+	ErrorBanner lipgloss.Style
+}
+
+type themeColors struct {
+	title, activeFg, activeBg, muted, accent lipgloss.Color
+	ok, warn, err, errBg                     lipgloss.Color
+}
+
+var themePresets = map[string]themeColors{
+	"dark": {
+		title:    "212",
+		activeFg: "36",
+		activeBg: "57",
+		muted:    "241",
+		accent:   "33",
+		ok:       "42",
+		warn:     "226",
+		err:      "196",
+		errBg:    "52",
+	},
+	"light": {
+		title:    "125",
+		activeFg: "231",
+		activeBg: "25",
+		muted:    "243",
+		accent:   "25",
+		ok:       "28",
+		warn:     "130",
+		err:      "160",
+		errBg:    "224",
+	},
+	"high-contrast": {
+		title:    "15",
+		activeFg: "0",
+		activeBg: "15",
+		muted:    "15",
+		accent:   "14",
+		ok:       "10",
+		warn:     "11",
+		err:      "9",
+		errBg:    "0",
+	},
+}
+
+// ThemeNames returns the names of the built-in theme presets
+func ThemeNames() []string {
+	return []string{"dark", "light", "high-contrast"}
+}
+
+// GetTheme returns the named preset, falling back to "dark" for unknown names
+func GetTheme(name string) Theme {
+	name = strings.ToLower(strings.TrimSpace(name))
+	colors, ok := themePresets[name]
+	if !ok {
+		name = "dark"
+		colors = themePresets[name]
+	}
+
+	return Theme{
+		Name: name,
+		Title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(colors.title).
+			MarginLeft(2),
+		ActiveTab: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(colors.activeFg).
+			Background(colors.activeBg).
+			Padding(0, 2),
+		InactiveTab: lipgloss.NewStyle().
+			Foreground(colors.muted).
+			Padding(0, 2),
+		Header: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(colors.accent),
+		Muted:      lipgloss.NewStyle().Foreground(colors.muted),
+		Link:       lipgloss.NewStyle().Foreground(colors.accent).Underline(true),
+		StatusOK:   lipgloss.NewStyle().Foreground(colors.ok),
+		StatusWarn: lipgloss.NewStyle().Foreground(colors.warn),
+		StatusErr:  lipgloss.NewStyle().Foreground(colors.err),
+		Info:       lipgloss.NewStyle().Foreground(colors.accent),
+		ErrorBanner: lipgloss.NewStyle().
+			Foreground(colors.err).
+			Background(colors.errBg).
+			Bold(true).
+			Padding(0, 1).
+			MarginLeft(2),
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Verbosity selects which log levels the CLI keeps and prints
+type Verbosity int
+
+const (
+	// VerbosityQuiet keeps errors only
+	VerbosityQuiet Verbosity = iota - 1
+	// VerbosityNormal keeps everything except debug output
+	VerbosityNormal
+	// VerbosityVerbose also keeps debug output
+	VerbosityVerbose
+)
+
+// Allows reports whether messages at level pass the verbosity
+func (v Verbosity) Allows(level LogLevel) bool {
+	switch level {
+	case LogError:
+		return true
+	case LogDebug:
+		return v >= VerbosityVerbose
+	default:
+		return v >= VerbosityNormal
+	}
+}
+
+// verbosity is the process-wide setting chosen by --quiet or --verbose
+var verbosity = VerbosityNormal
+
+// SetVerbosity applies v to console output and to the logger, which feeds
+// both the dashboard and the log file
+func SetVerbosity(v Verbosity) {
+	verbosity = v
+	GetLogger().SetVerbosity(v)
+}
+
+// AddVerbosityFlags registers the global --quiet and --verbose flags on the
+// root command and applies them before any subcommand runs
+func AddVerbosityFlags(root *cobra.Command) {
+	root.PersistentFlags().BoolP("quiet", "q", false, "Only show errors")
+	root.PersistentFlags().BoolP("verbose", "v", false, "Show debug output")
+
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		switch {
+		case quiet && verbose:
+			return fmt.Errorf("--quiet and --verbose cannot be used together")
+		case quiet:
+			SetVerbosity(VerbosityQuiet)
+		case verbose:
+			SetVerbosity(VerbosityVerbose)
+		}
+		return nil
+	}
+}
+
+// printDebug shows a diagnostic line on stderr with --verbose and records it
+// in the log
+func printDebug(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	GetLogger().Log(LogDebug, "cli", msg)
+	if verbosity.Allows(LogDebug) {
+		fmt.Fprintln(os.Stderr, "debug: "+msg)
+	}
+}
+
+// commandLine renders a command and its arguments for debug output
+func commandLine(name string, args ...string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
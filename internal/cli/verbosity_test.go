@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestVerbosityAllows(t *testing.T) {
+	tests := []struct {
+		v     Verbosity
+		level LogLevel
+		want  bool
+	}{
+		{VerbosityQuiet, LogError, true},
+		{VerbosityQuiet, LogWarning, false},
+		{VerbosityQuiet, LogSystem, false},
+		{VerbosityNormal, LogInfo, true},
+		{VerbosityNormal, LogSystem, true},
+		{VerbosityNormal, LogDebug, false},
+		{VerbosityVerbose, LogDebug, true},
+		{VerbosityVerbose, LogWarning, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.Allows(tt.level); got != tt.want {
+			t.Errorf("verbosity %d allows %s = %v, want %v", tt.v, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestVerbosityFlags(t *testing.T) {
+	defer SetVerbosity(VerbosityNormal)
+
+	tests := []struct {
+		args []string
+		want Verbosity
+		err  bool
+	}{
+		{args: []string{"run"}, want: VerbosityNormal},
+		{args: []string{"-q", "run"}, want: VerbosityQuiet},
+		{args: []string{"run", "--verbose"}, want: VerbosityVerbose},
+		{args: []string{"--quiet", "-v", "run"}, err: true},
+	}
+
+	for _, tt := range tests {
+		SetVerbosity(VerbosityNormal)
+		root := &cobra.Command{Use: "local", SilenceUsage: true, SilenceErrors: true}
+		root.AddCommand(&cobra.Command{Use: "run", Run: func(*cobra.Command, []string) {}})
+		AddVerbosityFlags(root)
+		root.SetArgs(tt.args)
+		root.SetOut(io.Discard)
+
+		err := root.Execute()
+		if (err != nil) != tt.err {
+			t.Errorf("%v: error = %v", tt.args, err)
+			continue
+		}
+		if !tt.err && verbosity != tt.want {
+			t.Errorf("%v: verbosity = %d, want %d", tt.args, verbosity, tt.want)
+		}
+	}
+}
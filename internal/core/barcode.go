@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Barcode types understood by ValidateBarcode
+const (
+	BarcodeISBN10 = "isbn10"
+	BarcodeISBN13 = "isbn13"
+	BarcodeEAN13  = "ean13"
+	BarcodeUPCA   = "upca"
+)
+
+// barcodeLengths is the full length of each barcode type, check digit
+// included
+var barcodeLengths = map[string]int{
+	BarcodeISBN10: 10,
+	BarcodeISBN13: 13,
+	BarcodeEAN13:  13,
+	BarcodeUPCA:   12,
+}
+
+// BarcodeError explains why a code failed validation. Code is "length",
+// "characters", "checkDigit" or "type".
+type BarcodeError struct {
+	Code    string
+	Message string
+}
+
+func (e *BarcodeError) Error() string {
+	return e.Message
+}
+
+// stripBarcode removes spaces and hyphens and upper-cases an ISBN-10 X
+func stripBarcode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '\t':
+			return -1
+		case 'x':
+			return 'X'
+		}
+		return r
+	}, code)
+}
+
+// detectBarcode picks a type from the length of a stripped code. Partial
+// codes are missing their check digit.
+func detectBarcode(code string, partial bool) (string, error) {
+	n := len(code)
+	if partial {
+		n++
+	}
+	switch n {
+	case 10:
+		return BarcodeISBN10, nil
+	case 12:
+		return BarcodeUPCA, nil
+	case 13:
+		if strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979") {
+			return BarcodeISBN13, nil
+		}
+		return BarcodeEAN13, nil
+	}
+	return "", &BarcodeError{Code: "length", Message: fmt.Sprintf(
+		"code has %d digits; expected 10 (ISBN-10), 12 (UPC-A) or 13 (ISBN-13/EAN-13), one fewer for a partial code", len(code))}
+}
+
+// isbn10CheckDigit returns the check character for the first nine digits of
+// an ISBN-10
+func isbn10CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(digits[i]-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// gtinCheckDigit returns the mod-10 check digit shared by EAN-13 and UPC-A.
+// Weights alternate 3, 1 from the digit nearest the check digit.
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// barcodeCheckDigit computes the check digit for body, which must be the
+// code without its final character
+func barcodeCheckDigit(kind, body string) byte {
+	if kind == BarcodeISBN10 {
+		return isbn10CheckDigit(body)
+	}
+	return gtinCheckDigit(body)
+}
+
+// ISBN10To13 converts a valid ISBN-10 to its 978-prefixed ISBN-13
+func ISBN10To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	return body + string(gtinCheckDigit(body))
+}
+
+// CheckBarcode validates a stripped code of the given type (empty to
+// detect). When partial is set, the code lacks its check digit and the
+// completed code is returned. The error is always a *BarcodeError.
+func CheckBarcode(code, kind string, partial bool) (string, string, error) {
+	if kind == "" {
+		detected, err := detectBarcode(code, partial)
+		if err != nil {
+			return "", "", err
+		}
+		kind = detected
+	}
+	length, ok := barcodeLengths[kind]
+	if !ok {
+		return "", "", &BarcodeError{Code: "type", Message: fmt.Sprintf("unknown barcode type %q (expected isbn10, isbn13, ean13 or upca)", kind)}
+	}
+
+	want := length
+	if partial {
+		want--
+	}
+	if len(code) != want {
+		return kind, "", &BarcodeError{Code: "length", Message: fmt.Sprintf("%s needs %d characters, got %d", kind, want, len(code))}
+	}
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		if c == 'X' && kind == BarcodeISBN10 && i == length-1 {
+			continue
+		}
+		return kind, "", &BarcodeError{Code: "characters", Message: fmt.Sprintf("invalid character %q at position %d", c, i)}
+	}
+	if kind == BarcodeISBN13 && !strings.HasPrefix(code, "978") && !strings.HasPrefix(code, "979") {
+		return kind, "", &BarcodeError{Code: "characters", Message: "ISBN-13 must start with 978 or 979"}
+	}
+
+	body := code[:length-1]
+	check := barcodeCheckDigit(kind, body)
+	if partial {
+		return kind, body + string(check), nil
+	}
+	if code[length-1] != check {
+		return kind, code, &BarcodeError{Code: "checkDigit", Message: fmt.Sprintf("check digit is %c, expected %c", code[length-1], check)}
+	}
+	return kind, code, nil
+}
+
+// ValidateBarcode validates an ISBN-10, ISBN-13, EAN-13 or UPC-A code, or
+// computes the check digit when partial is set. Hyphens and spaces are
+// ignored. Invalid codes are reported in the result rather than as an error.
+func (dp *DataProcessor) ValidateBarcode(input, kind string, partial bool) (map[string]interface{}, error) {
+	code := stripBarcode(input)
+	kind = strings.ToLower(kind)
+	kind, full, err := CheckBarcode(code, kind, partial)
+
+	result := map[string]interface{}{
+		"valid":   err == nil,
+		"type":    kind,
+		"input":   code,
+		"partial": partial,
+	}
+	if err != nil {
+		result["error"] = err.Error()
+		result["errorCode"] = err.(*BarcodeError).Code
+		return result, nil
+	}
+
+	result["code"] = full
+	result["checkDigit"] = string(full[len(full)-1])
+	if kind == BarcodeISBN10 {
+		result["isbn13"] = ISBN10To13(full)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package core
+
+import "testing"
+
+func TestValidateBarcode(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name      string
+		input     string
+		kind      string
+		partial   bool
+		valid     bool
+		wantType  string
+		code      string
+		errorCode string
+	}{
+		{name: "ISBN-10", input: "0-306-40615-2", valid: true, wantType: BarcodeISBN10, code: "0306406152"},
+		{name: "ISBN-10 with X", input: "0-8044-2957-x", valid: true, wantType: BarcodeISBN10, code: "080442957X"},
+		{name: "ISBN-13", input: "978 0 306 40615 7", valid: true, wantType: BarcodeISBN13, code: "9780306406157"},
+		{name: "EAN-13", input: "4006381333931", valid: true, wantType: BarcodeEAN13, code: "4006381333931"},
+		{name: "UPC-A", input: "036000291452", valid: true, wantType: BarcodeUPCA, code: "036000291452"},
+		{name: "explicit type", input: "9780306406157", kind: "EAN13", valid: true, wantType: BarcodeEAN13, code: "9780306406157"},
+		{name: "partial UPC-A", input: "03600029145", partial: true, valid: true, wantType: BarcodeUPCA, code: "036000291452"},
+		{name: "partial ISBN-10 needing X", input: "080442957", partial: true, valid: true, wantType: BarcodeISBN10, code: "080442957X"},
+		{name: "wrong check digit", input: "9780306406158", wantType: BarcodeISBN13, errorCode: "checkDigit"},
+		{name: "X outside ISBN-10", input: "03600029145X", wantType: BarcodeUPCA, errorCode: "characters"},
+		{name: "X not last", input: "0X06406152", wantType: BarcodeISBN10, errorCode: "characters"},
+		{name: "ISBN-13 prefix", input: "4006381333931", kind: "isbn13", wantType: BarcodeISBN13, errorCode: "characters"},
+		{name: "unknown length", input: "12345", errorCode: "length"},
+		{name: "length for type", input: "12345", kind: "upca", wantType: BarcodeUPCA, errorCode: "length"},
+		{name: "unknown type", input: "036000291452", kind: "qr", errorCode: "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.ValidateBarcode(tt.input, tt.kind, tt.partial)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["valid"] != tt.valid || result["type"] != tt.wantType {
+				t.Fatalf("result = %v", result)
+			}
+			if tt.valid && result["code"] != tt.code {
+				t.Errorf("code = %v, want %s", result["code"], tt.code)
+			}
+			if !tt.valid && result["errorCode"] != tt.errorCode {
+				t.Errorf("errorCode = %v, want %s (%v)", result["errorCode"], tt.errorCode, result["error"])
+			}
+		})
+	}
+}
+
+func TestISBN10To13(t *testing.T) {
+	result, _ := NewDataProcessor().ValidateBarcode("0306406152", "", false)
+	if result["isbn13"] != "9780306406157" {
+		t.Errorf("isbn13 = %v, want 9780306406157", result["isbn13"])
+	}
+	if got := ISBN10To13("080442957X"); got != "9780804429573" {
+		t.Errorf("ISBN10To13 = %s, want 9780804429573", got)
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ConvertBase converts an integer string from one base to another. Bases
+// range from 2 to 36. A source base of 0 detects it from a 0x, 0b or 0o
+// prefix (defaulting to 10); a matching prefix is also accepted when the
+// base is given explicitly. Values of any size are supported.
+func (dp *DataProcessor) ConvertBase(input string, fromBase, toBase int) (map[string]interface{}, error) {
+	if toBase < 2 || toBase > 36 {
+		return nil, fmt.Errorf("target base must be between 2 and 36")
+	}
+	if fromBase != 0 && (fromBase < 2 || fromBase > 36) {
+		return nil, fmt.Errorf("source base must be between 2 and 36")
+	}
+
+	digits := strings.ReplaceAll(strings.TrimSpace(input), "_", "")
+	negative := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	detected, rest := splitBasePrefix(digits)
+	switch {
+	case fromBase == 0 && detected != 0:
+		fromBase, digits = detected, rest
+	case fromBase == 0:
+		fromBase = 10
+	case detected == fromBase:
+		digits = rest
+	}
+
+	if digits == "" {
+		return nil, fmt.Errorf("no digits provided")
+	}
+
+	for _, r := range strings.ToLower(digits) {
+		if digitValue(r) >= fromBase {
+			return nil, fmt.Errorf("invalid digit %q for base %d", r, fromBase)
+		}
+	}
+
+	value, ok := new(big.Int).SetString(digits, fromBase)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q for base %d", digits, fromBase)
+	}
+	if negative {
+		value.Neg(value)
+	}
+
+	return map[string]interface{}{
+		"result":   value.Text(toBase),
+		"decimal":  value.Text(10),
+		"fromBase": fromBase,
+		"toBase":   toBase,
+		"bitLen":   value.BitLen(),
+	}, nil
+}
+
+// splitBasePrefix returns the base implied by a 0x, 0b or 0o prefix
+func splitBasePrefix(s string) (int, string) {
+	if len(s) < 2 || s[0] != '0' {
+		return 0, s
+	}
+	switch s[1] {
+	case 'x', 'X':
+		return 16, s[2:]
+	case 'b', 'B':
+		return 2, s[2:]
+	case 'o', 'O':
+		return 8, s[2:]
+	}
+	return 0, s
+}
+
+// digitValue returns the numeric value of a base-36 digit, or 36 if invalid
+func digitValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'z':
+		return int(r-'a') + 10
+	default:
+		return 36
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertBase(t *testing.T) {
+	tests := []struct {
+		input    string
+		from, to int
+		want     string
+		decimal  string
+		fromBase int
+	}{
+		{"255", 10, 16, "ff", "255", 10},
+		{"0xFF", 0, 2, "11111111", "255", 16},
+		{"0b1010", 0, 10, "10", "10", 2},
+		{"0o17", 0, 10, "15", "15", 8},
+		{"42", 0, 36, "16", "42", 10},
+		{"0x1f", 16, 10, "31", "31", 16},
+		{"0b1", 16, 10, "177", "177", 16},
+		{"-zz", 36, 10, "-1295", "-1295", 36},
+		{"+1_000_000", 10, 16, "f4240", "1000000", 10},
+		{"  0  ", 10, 2, "0", "0", 10},
+		{"-0", 10, 10, "0", "0", 10},
+		{"ffffffffffffffffffffffffffffffff", 16, 10, "340282366920938463463374607431768211455", "340282366920938463463374607431768211455", 16},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.ConvertBase(tt.input, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if result["result"] != tt.want || result["decimal"] != tt.decimal || result["fromBase"] != tt.fromBase {
+			t.Errorf("%q base %d to %d: got %v", tt.input, tt.from, tt.to, result)
+		}
+	}
+}
+
+func TestConvertBaseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		from, to int
+		err      string
+	}{
+		{"10", 10, 1, "target base"},
+		{"10", 10, 37, "target base"},
+		{"10", 1, 10, "source base"},
+		{"", 10, 2, "no digits"},
+		{"0x", 0, 2, "no digits"},
+		{"-", 10, 2, "no digits"},
+		{"12", 2, 10, "invalid digit"},
+		{"1.5", 10, 2, "invalid digit"},
+		{"0b2", 0, 10, "invalid digit"},
+		{"1__0", 10, 2, ""},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		_, err := dp.ConvertBase(tt.input, tt.from, tt.to)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%q: %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: error %v does not mention %q", tt.input, err, tt.err)
+		}
+	}
+}
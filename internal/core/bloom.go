@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// MaxBloomBits bounds the size of a Bloom filter's bit array (16 MiB)
+const MaxBloomBits = 1 << 27
+
+// bloomHeaderSize is the encoded size of m, k and count ahead of the bits
+const bloomHeaderSize = 8 + 4 + 8
+
+// BloomFilter is a probabilistic set: Test never reports a false negative
+// but may report a false positive at roughly the configured rate
+type BloomFilter struct {
+	bits  []uint64
+	m     uint64 // number of bits
+	k     int    // number of hash functions
+	count int    // number of items added
+}
+
+// NewBloomFilter sizes a filter for the expected number of items so that the
+// false positive rate stays near fpRate once they have all been added
+func NewBloomFilter(expectedItems int, fpRate float64) (*BloomFilter, error) {
+	if expectedItems <= 0 {
+		return nil, fmt.Errorf("expected items must be positive")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	if m > MaxBloomBits {
+		return nil, fmt.Errorf("filter would need %.0f bits, limit is %d", m, MaxBloomBits)
+	}
+	// More than 64 hashes adds nothing at any practical rate, and encoded
+	// filters are limited to 64
+	k := int(math.Min(64, math.Max(1, math.Round(m/n*math.Ln2))))
+
+	return newBloomFilter(uint64(m), k), nil
+}
+
+func newBloomFilter(m uint64, k int) *BloomFilter {
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// positions derives k bit positions from two halves of a 128-bit FNV-1a hash
+// using Kirsch-Mitzenmacher double hashing
+func (bf *BloomFilter) positions(item string) []uint64 {
+	h := fnv.New128a()
+	h.Write([]byte(item))
+	sum := h.Sum(nil)
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+
+	pos := make([]uint64, bf.k)
+	for i := range pos {
+		pos[i] = (h1 + uint64(i)*h2) % bf.m
+	}
+	return pos
+}
+
+// Add inserts an item into the filter
+func (bf *BloomFilter) Add(item string) {
+	for _, p := range bf.positions(item) {
+		bf.bits[p/64] |= 1 << (p % 64)
+	}
+	bf.count++
+}
+
+// Test reports whether the item may have been added. False means it
+// definitely was not.
+func (bf *BloomFilter) Test(item string) bool {
+	for _, p := range bf.positions(item) {
+		if bf.bits[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Info returns the filter's size, hash count, item count and the estimated
+// false positive rate at its current fill
+func (bf *BloomFilter) Info() map[string]interface{} {
+	fpr := math.Pow(1-math.Exp(-float64(bf.k)*float64(bf.count)/float64(bf.m)), float64(bf.k))
+	return map[string]interface{}{
+		"bits":                   int(bf.m),
+		"hashes":                 bf.k,
+		"count":                  bf.count,
+		"estimatedFalsePositive": fpr,
+	}
+}
+
+// Encode serializes the filter to base64 for storage or transfer
+func (bf *BloomFilter) Encode() string {
+	buf := make([]byte, bloomHeaderSize+8*len(bf.bits))
+	binary.BigEndian.PutUint64(buf[0:8], bf.m)
+	binary.BigEndian.PutUint32(buf[8:12], uint32(bf.k))
+	binary.BigEndian.PutUint64(buf[12:20], uint64(bf.count))
+	for i, word := range bf.bits {
+		binary.BigEndian.PutUint64(buf[bloomHeaderSize+8*i:], word)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeBloomFilter restores a filter produced by Encode
+func DecodeBloomFilter(encoded string) (*BloomFilter, error) {
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(buf) < bloomHeaderSize {
+		return nil, fmt.Errorf("encoded filter is too short")
+	}
+
+	m := binary.BigEndian.Uint64(buf[0:8])
+	k := int(binary.BigEndian.Uint32(buf[8:12]))
+	if m == 0 || m > MaxBloomBits || k < 1 || k > 64 {
+		return nil, fmt.Errorf("encoded filter has invalid parameters")
+	}
+
+	bf := newBloomFilter(m, k)
+	if len(buf) != bloomHeaderSize+8*len(bf.bits) {
+		return nil, fmt.Errorf("encoded filter has the wrong length")
+	}
+	bf.count = int(binary.BigEndian.Uint64(buf[12:20]))
+	for i := range bf.bits {
+		bf.bits[i] = binary.BigEndian.Uint64(buf[bloomHeaderSize+8*i:])
+	}
+	return bf, nil
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewBloomFilterSizing(t *testing.T) {
+	tests := []struct {
+		items  int
+		fpRate float64
+		bits   int
+		hashes int
+	}{
+		{1000, 0.01, 9586, 7},
+		{1000, 0.001, 14378, 10},
+		{1, 0.5, 2, 1},
+		{10, 1e-30, 1438, 64},
+	}
+
+	for _, tt := range tests {
+		bf, err := NewBloomFilter(tt.items, tt.fpRate)
+		if err != nil {
+			t.Fatalf("%d items at %v: %v", tt.items, tt.fpRate, err)
+		}
+		info := bf.Info()
+		if info["bits"] != tt.bits || info["hashes"] != tt.hashes {
+			t.Errorf("%d items at %v: got %v bits and %v hashes, want %d and %d",
+				tt.items, tt.fpRate, info["bits"], info["hashes"], tt.bits, tt.hashes)
+		}
+	}
+}
+
+func TestNewBloomFilterErrors(t *testing.T) {
+	tests := []struct {
+		items  int
+		fpRate float64
+	}{
+		{0, 0.01},
+		{-1, 0.01},
+		{10, 0},
+		{10, 1},
+		{1 << 30, 0.0001},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewBloomFilter(tt.items, tt.fpRate); err == nil {
+			t.Errorf("%d items at %v: expected an error", tt.items, tt.fpRate)
+		}
+	}
+}
+
+func TestBloomFilterMembership(t *testing.T) {
+	const n = 2000
+	bf, err := NewBloomFilter(n, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		bf.Add(fmt.Sprintf("item-%d", i))
+	}
+
+	for i := 0; i < n; i++ {
+		if !bf.Test(fmt.Sprintf("item-%d", i)) {
+			t.Fatalf("false negative for item-%d", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10*n; i++ {
+		if bf.Test(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / (10 * n); rate > 0.02 {
+		t.Errorf("false positive rate %.4f, configured 0.01", rate)
+	}
+
+	info := bf.Info()
+	if info["count"] != n {
+		t.Errorf("count = %v, want %d", info["count"], n)
+	}
+	if est := info["estimatedFalsePositive"].(float64); est < 0.005 || est > 0.015 {
+		t.Errorf("estimated false positive rate %v, configured 0.01", est)
+	}
+}
+
+func TestBloomFilterEncoding(t *testing.T) {
+	for _, fpRate := range []float64{0.1, 1e-30} {
+		bf, err := NewBloomFilter(100, fpRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range []string{"a", "b", ""} {
+			bf.Add(item)
+		}
+
+		decoded, err := DecodeBloomFilter(bf.Encode())
+		if err != nil {
+			t.Fatalf("rate %v: %v", fpRate, err)
+		}
+		if fmt.Sprint(decoded.Info()) != fmt.Sprint(bf.Info()) {
+			t.Errorf("rate %v: decoded %v, want %v", fpRate, decoded.Info(), bf.Info())
+		}
+		for _, item := range []string{"a", "b", ""} {
+			if !decoded.Test(item) {
+				t.Errorf("rate %v: decoded filter lost %q", fpRate, item)
+			}
+		}
+		if decoded.Encode() != bf.Encode() {
+			t.Errorf("rate %v: re-encoding changed the filter", fpRate)
+		}
+	}
+}
+
+func TestDecodeBloomFilterErrors(t *testing.T) {
+	bf, _ := NewBloomFilter(10, 0.1)
+	valid, _ := base64.StdEncoding.DecodeString(bf.Encode())
+	encode := func(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
+	withHeader := func(m uint64, k uint32) string {
+		buf := append([]byte(nil), valid...)
+		for i := 0; i < 8; i++ {
+			buf[i] = byte(m >> (56 - 8*i))
+		}
+		for i := 0; i < 4; i++ {
+			buf[8+i] = byte(k >> (24 - 8*i))
+		}
+		return encode(buf)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+		err     string
+	}{
+		{name: "not base64", encoded: "%%%", err: "invalid base64"},
+		{name: "too short", encoded: encode(valid[:10]), err: "too short"},
+		{name: "truncated bits", encoded: encode(valid[:len(valid)-1]), err: "wrong length"},
+		{name: "zero bits", encoded: withHeader(0, 3), err: "invalid parameters"},
+		{name: "too many bits", encoded: withHeader(MaxBloomBits+1, 3), err: "invalid parameters"},
+		{name: "zero hashes", encoded: withHeader(48, 0), err: "invalid parameters"},
+		{name: "too many hashes", encoded: withHeader(48, 65), err: "invalid parameters"},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeBloomFilter(tt.encoded)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %v does not mention %q", tt.name, err, tt.err)
+		}
+	}
+}
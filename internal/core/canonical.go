@@ -0,0 +1,234 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CanonicalJSON re-encodes a JSON document following the JSON
+// Canonicalization Scheme (RFC 8785): object keys sorted by UTF-16 code
+// units, no insignificant whitespace, minimal string escaping and numbers
+// formatted like ECMAScript's Number.prototype.toString. Semantically equal
+// documents therefore produce identical bytes.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	// More ignores stray closing delimiters, so demand a clean end of input
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	// Decoding keeps the last of repeated keys, which would give different
+	// documents the same canonical form
+	if err := checkDuplicateKeys(data); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkDuplicateKeys rejects an object with the same member name twice, as
+// RFC 8785 requires I-JSON input. data must already be valid JSON.
+func checkDuplicateKeys(data []byte) error {
+	type container struct {
+		keys    map[string]bool // nil for arrays
+		wantKey bool
+	}
+	var stack []*container
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].wantKey {
+			if key, ok := tok.(string); ok {
+				if stack[n-1].keys[key] {
+					return fmt.Errorf("duplicate object key %q", key)
+				}
+				stack[n-1].keys[key] = true
+				stack[n-1].wantKey = false
+				continue
+			}
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &container{keys: map[string]bool{}, wantKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, &container{})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+		// a value has ended, so an enclosing object expects its next key
+		if n := len(stack); n > 0 && stack[n-1].keys != nil {
+			stack[n-1].wantKey = true
+		}
+	}
+}
+
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return fmt.Errorf("number %s cannot be represented as a double", v)
+		}
+		s, err := formatES6Number(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case float64:
+		s, err := formatES6Number(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeCanonicalString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", value)
+	}
+	return nil
+}
+
+// lessUTF16 compares strings by UTF-16 code units as RFC 8785 requires
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// writeCanonicalString escapes only quotes, backslashes and control
+// characters, using the short forms where JSON defines them
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatES6Number formats a double the way ECMAScript does: plain decimal
+// notation for magnitudes in [1e-6, 1e21) and the shortest exponent form
+// otherwise. Negative zero is written as 0.
+func formatES6Number(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("NaN and Infinity are not valid JSON numbers")
+	}
+	if f == 0 {
+		return "0", nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// Go writes e.g. 1e-07; ECMAScript writes 1e-7 and always signs the exponent
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign := exp[0]
+	exp = strings.TrimLeft(exp[1:], "0")
+	return mantissa + "e" + string(sign) + exp, nil
+}
+
+// CanonicalizeJSON returns the canonical form of a JSON string
+func (dp *DataProcessor) CanonicalizeJSON(input string) (map[string]interface{}, error) {
+	if _, err := CheckJSON(input, DefaultJSONLimits); err != nil {
+		return nil, err
+	}
+	canonical, err := CanonicalJSON([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"canonical": string(canonical),
+		"size":      len(canonical),
+	}, nil
+}
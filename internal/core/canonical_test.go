@@ -0,0 +1,124 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFormatES6Number checks the number serialization samples from RFC 8785
+// appendix B
+func TestFormatES6Number(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		got, err := formatES6Number(math.Float64frombits(tt.bits))
+		if err != nil {
+			t.Fatalf("%#016x: %v", tt.bits, err)
+		}
+		if got != tt.want {
+			t.Errorf("%#016x: got %s, want %s", tt.bits, got, tt.want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := formatES6Number(f); err == nil {
+			t.Errorf("%v: expected an error", f)
+		}
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "rfc 8785 sorting",
+			input: `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`,
+			want:  "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:  "rfc 8785 values",
+			input: `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`,
+			want:  `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name:  "whitespace and nesting",
+			input: " { \"b\" : [ 1 , { \"d\" : -0 , \"c\" : 1.0 } ] , \"a\" : { } } ",
+			want:  `{"a":{},"b":[1,{"c":1,"d":0}]}`,
+		},
+		{
+			name:  "same key in different objects",
+			input: `{"a":{"a":[{"a":1},{"a":2}]},"b":{"a":null}}`,
+			want:  `{"a":{"a":[{"a":1},{"a":2}]},"b":{"a":null}}`,
+		},
+		{name: "scalar", input: `"<&>"`, want: `"<&>"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+
+			// Canonical output is a fixed point
+			again, err := CanonicalJSON(got)
+			if err != nil || string(again) != string(got) {
+				t.Errorf("canonicalizing twice gave %s (%v)", again, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONErrors(t *testing.T) {
+	for _, input := range []string{``, `{`, `{"a":1} {"b":2}`, `[1e400]`, `{'a':1}`, `{} }`, `[1] ]`, `1 ,`,
+		`{"a":1,"a":2}`, `{"a":1,"\u0061":2}`, `[{"b":{"a":1,"a":1}}]`} {
+		if _, err := CanonicalJSON([]byte(input)); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.CanonicalizeJSON(`{"b": 2, "a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["canonical"] != `{"a":1,"b":2}` || result["size"] != 13 {
+		t.Errorf("got %v", result)
+	}
+}
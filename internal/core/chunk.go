@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// DefaultChunkSize is the average chunk size ChunkContent aims for
+	DefaultChunkSize = 8 * 1024
+	// MinChunkSize and MaxChunkSize bound the configurable average
+	MinChunkSize = 64
+	MaxChunkSize = 4 << 20
+
+	// buzhashWindow is how many trailing bytes decide a boundary
+	buzhashWindow = 48
+)
+
+// buzhashTable maps each byte to a fixed pseudo-random value. It is
+// generated from a constant seed so every replica cuts identical chunks.
+var buzhashTable = func() [256]uint32 {
+	var table [256]uint32
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range table {
+		// splitmix64
+		state += 0x9E3779B97F4A7C15
+		z := state
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		table[i] = uint32(z ^ (z >> 31))
+	}
+	return table
+}()
+
+// Chunk is a content-defined slice of the input
+type Chunk struct {
+	Offset int
+	Length int
+	Hash   string // hex SHA-256 of the chunk's bytes
+}
+
+// ChunkBytes splits data at content-defined boundaries found with a buzhash
+// rolling hash over a 48-byte window. A boundary falls where the hash's low
+// bits are zero, giving chunks of about avgSize bytes (rounded to a power
+// of two), never shorter than a quarter of it nor longer than four times it.
+// Because boundaries depend only on nearby bytes, an insertion or deletion
+// only changes the chunks around it.
+func ChunkBytes(ctx context.Context, data []byte, avgSize int) ([]Chunk, error) {
+	if avgSize < MinChunkSize || avgSize > MaxChunkSize {
+		return nil, fmt.Errorf("average chunk size must be between %d and %d bytes", MinChunkSize, MaxChunkSize)
+	}
+
+	avg := 1 << (bits.Len(uint(avgSize)) - 1)
+	mask := uint32(avg - 1)
+	minSize, maxSize := avg/4, avg*4
+
+	var chunks []Chunk
+	start := 0
+	for start < len(data) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ReportProgress(ctx, start, len(data))
+
+		end := cutPoint(data[start:], mask, minSize, maxSize) + start
+		sum := sha256.Sum256(data[start:end])
+		chunks = append(chunks, Chunk{
+			Offset: start,
+			Length: end - start,
+			Hash:   hex.EncodeToString(sum[:]),
+		})
+		start = end
+	}
+	return chunks, nil
+}
+
+// cutPoint returns the length of the next chunk of data
+func cutPoint(data []byte, mask uint32, minSize, maxSize int) int {
+	if len(data) <= minSize {
+		return len(data)
+	}
+	if len(data) < maxSize {
+		maxSize = len(data)
+	}
+
+	// Prime the window with the bytes just before the earliest boundary
+	var hash uint32
+	from := minSize - buzhashWindow
+	if from < 0 {
+		from = 0
+	}
+	for i := from; i < minSize; i++ {
+		hash = bits.RotateLeft32(hash, 1) ^ buzhashTable[data[i]]
+	}
+
+	for i := minSize; i < maxSize; i++ {
+		if hash&mask == 0 {
+			return i
+		}
+		hash = bits.RotateLeft32(hash, 1) ^ buzhashTable[data[i]]
+		if out := i - buzhashWindow; out >= 0 {
+			hash ^= bits.RotateLeft32(buzhashTable[data[out]], buzhashWindow%32)
+		}
+	}
+	return maxSize
+}
+
+// ChunkContent splits data into content-defined chunks for delta sync and
+// reports each chunk's offset, length and hash
+func (dp *DataProcessor) ChunkContent(ctx context.Context, data []byte, avgSize int) (map[string]interface{}, error) {
+	chunks, err := ChunkBytes(ctx, data, avgSize)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]interface{}, len(chunks))
+	for i, c := range chunks {
+		list[i] = map[string]interface{}{
+			"offset": c.Offset,
+			"length": c.Length,
+			"hash":   c.Hash,
+		}
+	}
+
+	return map[string]interface{}{
+		"chunks":  list,
+		"count":   len(chunks),
+		"size":    len(data),
+		"avgSize": avgSize,
+	}, nil
+}
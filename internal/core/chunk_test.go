@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func randomBytes(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func TestChunkBytesBounds(t *testing.T) {
+	data := randomBytes(1, 256<<10)
+	for _, avgSize := range []int{MinChunkSize, 1000, DefaultChunkSize} {
+		chunks, err := ChunkBytes(context.Background(), data, avgSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Sizes are rounded down to a power of two
+		avg := 1
+		for avg*2 <= avgSize {
+			avg *= 2
+		}
+		offset := 0
+		for i, c := range chunks {
+			if c.Offset != offset {
+				t.Fatalf("avg %d: chunk %d starts at %d, want %d", avgSize, i, c.Offset, offset)
+			}
+			last := i == len(chunks)-1
+			if c.Length > avg*4 || (!last && c.Length < avg/4) {
+				t.Errorf("avg %d: chunk %d has length %d outside [%d, %d]", avgSize, i, c.Length, avg/4, avg*4)
+			}
+			offset += c.Length
+		}
+		if offset != len(data) {
+			t.Errorf("avg %d: chunks cover %d bytes, want %d", avgSize, offset, len(data))
+		}
+
+		// The average lands within a factor of two of the target
+		if mean := len(data) / len(chunks); mean < avg/2 || mean > avg*2 {
+			t.Errorf("avg %d: mean chunk size %d", avgSize, mean)
+		}
+	}
+}
+
+func TestChunkBytesEditLocality(t *testing.T) {
+	data := randomBytes(2, 128<<10)
+	edited := append(append(append([]byte(nil), data[:60000]...), "inserted bytes"...), data[60000:]...)
+
+	before, _ := ChunkBytes(context.Background(), data, 1024)
+	after, _ := ChunkBytes(context.Background(), edited, 1024)
+
+	known := make(map[string]bool, len(before))
+	for _, c := range before {
+		known[c.Hash] = true
+	}
+	changed := 0
+	for _, c := range after {
+		if !known[c.Hash] {
+			changed++
+		}
+	}
+	// Only the chunks around the insertion differ
+	if changed == 0 || changed > 3 {
+		t.Errorf("%d of %d chunks changed after a small insertion", changed, len(after))
+	}
+
+	again, _ := ChunkBytes(context.Background(), data, 1024)
+	for i := range before {
+		if before[i] != again[i] {
+			t.Fatalf("chunking is not deterministic at chunk %d", i)
+		}
+	}
+}
+
+func TestChunkContent(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.ChunkContent(context.Background(), []byte("short"), MinChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := result["chunks"].([]interface{})
+	if result["count"] != 1 || len(chunks) != 1 {
+		t.Fatalf("result = %v", result)
+	}
+	// SHA-256 of "short"
+	if hash := chunks[0].(map[string]interface{})["hash"]; hash != "f9b0078b5df596d2ea19010c001bbd009e651de2c57e8fb7e355f31eb9d3f739" {
+		t.Errorf("hash = %v", hash)
+	}
+
+	if result, err := dp.ChunkContent(context.Background(), nil, DefaultChunkSize); err != nil || result["count"] != 0 {
+		t.Errorf("empty input: %v, %v", result, err)
+	}
+	for _, size := range []int{MinChunkSize - 1, MaxChunkSize + 1} {
+		if _, err := dp.ChunkContent(context.Background(), []byte("x"), size); err == nil {
+			t.Errorf("avgSize %d: expected an error", size)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ChunkBytes(ctx, []byte("data"), MinChunkSize); err == nil {
+		t.Error("expected an error for a cancelled context")
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Default saturation and lightness of StringToColor, in percent. They keep
+// every hue readable on light and dark backgrounds.
+const (
+	DefaultColorSaturation = 65
+	DefaultColorLightness  = 50
+)
+
+// hslToRGB converts a hue in degrees and saturation and lightness from 0
+// to 1 into 0-255 RGB channels
+func hslToRGB(h, s, l float64) (r, g, b int) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var rf, gf, bf float64
+	switch {
+	case h < 60:
+		rf, gf, bf = c, x, 0
+	case h < 120:
+		rf, gf, bf = x, c, 0
+	case h < 180:
+		rf, gf, bf = 0, c, x
+	case h < 240:
+		rf, gf, bf = 0, x, c
+	case h < 300:
+		rf, gf, bf = x, 0, c
+	default:
+		rf, gf, bf = c, 0, x
+	}
+	channel := func(v float64) int {
+		return int(math.Round((v + m) * 255))
+	}
+	return channel(rf), channel(gf), channel(bf)
+}
+
+// relativeLuminance is the WCAG 2 luminance of an sRGB color
+func relativeLuminance(r, g, b int) float64 {
+	linear := func(c int) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// contrastRatio is the WCAG 2 contrast ratio of two luminances, from 1 to 21
+func contrastRatio(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return (a + 0.05) / (b + 0.05)
+}
+
+// StringHue maps a string to a hue from 0 to 359 using the FNV-1a hash of
+// its UTF-8 bytes, so every platform picks the same hue
+func StringHue(s string) int {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return int(h.Sum32() % 360)
+}
+
+// StringToColor derives a consistent color for a string, such as a user
+// name for an avatar or a tag, by taking the hue from its hash at a fixed
+// saturation and lightness (percentages). The result includes black or
+// white as the text color with the better contrast on that background.
+func (dp *DataProcessor) StringToColor(input string, saturation, lightness float64) (map[string]interface{}, error) {
+	if math.IsNaN(saturation) || math.IsNaN(lightness) ||
+		saturation < 0 || saturation > 100 || lightness < 0 || lightness > 100 {
+		return nil, fmt.Errorf("saturation and lightness must be between 0 and 100")
+	}
+
+	hue := StringHue(input)
+	r, g, b := hslToRGB(float64(hue), saturation/100, lightness/100)
+
+	lum := relativeLuminance(r, g, b)
+	onBlack, onWhite := contrastRatio(lum, 0), contrastRatio(lum, 1)
+	text, ratio := "#000000", onBlack
+	if onWhite > onBlack {
+		text, ratio = "#ffffff", onWhite
+	}
+
+	return map[string]interface{}{
+		"hex":           fmt.Sprintf("#%02x%02x%02x", r, g, b),
+		"hsl":           fmt.Sprintf("hsl(%d, %g%%, %g%%)", hue, saturation, lightness),
+		"hue":           hue,
+		"saturation":    saturation,
+		"lightness":     lightness,
+		"rgb":           map[string]interface{}{"r": r, "g": g, "b": b},
+		"textColor":     text,
+		"contrastRatio": math.Round(ratio*100) / 100,
+	}, nil
+}
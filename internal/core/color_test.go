@@ -0,0 +1,123 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHSLToRGB(t *testing.T) {
+	tests := []struct {
+		h, s, l float64
+		r, g, b int
+	}{
+		{0, 1, 0.5, 255, 0, 0},
+		{120, 1, 0.5, 0, 255, 0},
+		{240, 1, 0.5, 0, 0, 255},
+		{60, 1, 0.5, 255, 255, 0},
+		{300, 1, 0.5, 255, 0, 255},
+		{0, 0, 0, 0, 0, 0},
+		{200, 0, 1, 255, 255, 255},
+		{210, 0.5, 0.25, 32, 64, 96},
+	}
+	for _, tt := range tests {
+		r, g, b := hslToRGB(tt.h, tt.s, tt.l)
+		if r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("hslToRGB(%v, %v, %v) = %d, %d, %d, want %d, %d, %d", tt.h, tt.s, tt.l, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	white, black := relativeLuminance(255, 255, 255), relativeLuminance(0, 0, 0)
+	if got := contrastRatio(black, white); got != 21 {
+		t.Errorf("black on white = %v, want 21", got)
+	}
+	if got := contrastRatio(white, white); got != 1 {
+		t.Errorf("white on white = %v, want 1", got)
+	}
+}
+
+func TestStringHue(t *testing.T) {
+	// FNV-1a of the empty string is 2166136261
+	if got := StringHue(""); got != 2166136261%360 {
+		t.Errorf("StringHue(\"\") = %d, want %d", got, 2166136261%360)
+	}
+	if StringHue("alice") != StringHue("alice") {
+		t.Error("StringHue is not deterministic")
+	}
+	for _, s := range []string{"alice", "bob", "Zoë", "\U0001F600"} {
+		if h := StringHue(s); h < 0 || h >= 360 {
+			t.Errorf("StringHue(%q) = %d, want 0-359", s, h)
+		}
+	}
+}
+
+func TestStringToColor(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.StringToColor("alice", DefaultColorSaturation, DefaultColorLightness)
+	if err != nil {
+		t.Fatalf("StringToColor: %v", err)
+	}
+	hue := StringHue("alice")
+	if result["hue"] != hue {
+		t.Errorf("hue = %v, want %d", result["hue"], hue)
+	}
+	rgb := result["rgb"].(map[string]interface{})
+	r, g, b := hslToRGB(float64(hue), 0.65, 0.5)
+	if rgb["r"] != r || rgb["g"] != g || rgb["b"] != b {
+		t.Errorf("rgb = %v, want %d, %d, %d", rgb, r, g, b)
+	}
+
+	// the text color is whichever of black and white contrasts more
+	lum := relativeLuminance(r, g, b)
+	want := "#000000"
+	if contrastRatio(lum, 1) > contrastRatio(lum, 0) {
+		want = "#ffffff"
+	}
+	if result["textColor"] != want {
+		t.Errorf("textColor = %v, want %s", result["textColor"], want)
+	}
+	if ratio := result["contrastRatio"].(float64); ratio < 3 {
+		t.Errorf("contrastRatio = %v, want at least 3", ratio)
+	}
+}
+
+func TestStringToColorExtremes(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		lightness float64
+		hex, text string
+	}{
+		{0, "#000000", "#ffffff"},
+		{100, "#ffffff", "#000000"},
+	}
+	for _, tt := range tests {
+		result, err := dp.StringToColor("x", 50, tt.lightness)
+		if err != nil {
+			t.Fatalf("StringToColor: %v", err)
+		}
+		if result["hex"] != tt.hex || result["textColor"] != tt.text || result["contrastRatio"] != 21.0 {
+			t.Errorf("lightness %v = %v, %v, %v", tt.lightness, result["hex"], result["textColor"], result["contrastRatio"])
+		}
+	}
+}
+
+func TestStringToColorErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name                  string
+		saturation, lightness float64
+	}{
+		{"negative saturation", -1, 50},
+		{"saturation over 100", 101, 50},
+		{"negative lightness", 65, -1},
+		{"lightness over 100", 65, 100.5},
+		{"NaN saturation", math.NaN(), 50},
+		{"NaN lightness", 65, math.NaN()},
+	}
+	for _, tt := range tests {
+		if _, err := dp.StringToColor("x", tt.saturation, tt.lightness); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cookieTimeFormat is the IMF-fixdate format used by the Expires attribute
+const cookieTimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// isCookieName reports whether name is an RFC 6265 cookie name (a token)
+func isCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`()<>@,;:\"/[]?={}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isCookieOctet reports whether c may appear unescaped in a cookie value
+func isCookieOctet(c byte) bool {
+	return c > ' ' && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\'
+}
+
+// escapeCookieValue percent-encodes bytes that are not cookie-octets, and
+// % itself so the encoding can be reversed
+func escapeCookieValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isCookieOctet(c) && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// ParseCookies splits a Cookie request header into name/value pairs in
+// order. Surrounding double quotes are removed from values; with decode,
+// percent-encoded values are decoded when they are valid. Malformed pairs
+// are skipped, as browsers do.
+func (dp *DataProcessor) ParseCookies(header string, decode bool) (map[string]interface{}, error) {
+	cookies := []interface{}{}
+	values := map[string]interface{}{}
+	skipped := 0
+
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !ok || !isCookieName(name) {
+			skipped++
+			continue
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		if decode {
+			if decoded, err := url.PathUnescape(value); err == nil {
+				value = decoded
+			}
+		}
+
+		cookies = append(cookies, map[string]interface{}{
+			"name":  name,
+			"value": value,
+		})
+		// the first occurrence wins, matching how servers usually read them
+		if _, seen := values[name]; !seen {
+			values[name] = value
+		}
+	}
+
+	return map[string]interface{}{
+		"cookies": cookies,
+		"values":  values,
+		"count":   len(cookies),
+		"skipped": skipped,
+	}, nil
+}
+
+// CookieOptions are the attributes of a Set-Cookie header
+type CookieOptions struct {
+	Name  string
+	Value string
+	// Path and Domain scope the cookie; both are omitted when empty
+	Path   string
+	Domain string
+	// Expires is omitted when zero
+	Expires time.Time
+	// MaxAge is in seconds; nil omits it and values <= 0 delete the cookie
+	MaxAge      *int
+	Secure      bool
+	HTTPOnly    bool
+	SameSite    string
+	Partitioned bool
+}
+
+// BuildCookie renders opts as a Set-Cookie header value. The value is
+// percent-encoded where needed. Invalid names or attributes are errors;
+// combinations that browsers silently reject, such as SameSite=None
+// without Secure, are returned as warnings.
+func BuildCookie(opts CookieOptions) (string, []string, error) {
+	if !isCookieName(opts.Name) {
+		return "", nil, fmt.Errorf("invalid cookie name %q", opts.Name)
+	}
+	for attr, value := range map[string]string{"path": opts.Path, "domain": opts.Domain} {
+		if strings.ContainsAny(value, ";\r\n") {
+			return "", nil, fmt.Errorf("cookie %s must not contain ';' or line breaks", attr)
+		}
+	}
+
+	var sameSite string
+	switch strings.ToLower(opts.SameSite) {
+	case "":
+	case "strict":
+		sameSite = "Strict"
+	case "lax":
+		sameSite = "Lax"
+	case "none":
+		sameSite = "None"
+	default:
+		return "", nil, fmt.Errorf("unknown SameSite value %q (expected Strict, Lax or None)", opts.SameSite)
+	}
+
+	var b strings.Builder
+	b.WriteString(opts.Name)
+	b.WriteByte('=')
+	b.WriteString(escapeCookieValue(opts.Value))
+	if opts.Path != "" {
+		b.WriteString("; Path=" + opts.Path)
+	}
+	if opts.Domain != "" {
+		b.WriteString("; Domain=" + strings.TrimPrefix(opts.Domain, "."))
+	}
+	if !opts.Expires.IsZero() {
+		b.WriteString("; Expires=" + opts.Expires.UTC().Format(cookieTimeFormat))
+	}
+	if opts.MaxAge != nil {
+		b.WriteString("; Max-Age=" + strconv.Itoa(max(*opts.MaxAge, 0)))
+	}
+	if opts.Secure {
+		b.WriteString("; Secure")
+	}
+	if opts.HTTPOnly {
+		b.WriteString("; HttpOnly")
+	}
+	if sameSite != "" {
+		b.WriteString("; SameSite=" + sameSite)
+	}
+	if opts.Partitioned {
+		b.WriteString("; Partitioned")
+	}
+
+	var warnings []string
+	if sameSite == "None" && !opts.Secure {
+		warnings = append(warnings, "SameSite=None requires Secure; browsers will reject this cookie")
+	}
+	if opts.Partitioned && !opts.Secure {
+		warnings = append(warnings, "Partitioned requires Secure; browsers will reject this cookie")
+	}
+	if strings.HasPrefix(opts.Name, "__Secure-") && !opts.Secure {
+		warnings = append(warnings, "cookies named __Secure-* require Secure")
+	}
+	if strings.HasPrefix(opts.Name, "__Host-") && (!opts.Secure || opts.Path != "/" || opts.Domain != "") {
+		warnings = append(warnings, "cookies named __Host-* require Secure, Path=/ and no Domain")
+	}
+	if len(b.String()) > 4096 {
+		warnings = append(warnings, "cookie exceeds 4096 bytes; browsers may drop it")
+	}
+	return b.String(), warnings, nil
+}
+
+// BuildCookie renders a Set-Cookie header and reports any warnings
+func (dp *DataProcessor) BuildCookie(opts CookieOptions) (map[string]interface{}, error) {
+	header, warnings, err := BuildCookie(opts)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]interface{}, len(warnings))
+	for i, w := range warnings {
+		list[i] = w
+	}
+
+	return map[string]interface{}{
+		"header":   header,
+		"warnings": list,
+	}, nil
+}
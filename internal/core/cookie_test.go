@@ -0,0 +1,142 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCookies(t *testing.T) {
+	dp := NewDataProcessor()
+	header := `session=abc123; theme="dark"; bad name=x; noequals; ; msg=hello%20world; session=second; empty=`
+
+	result, err := dp.ParseCookies(header, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantValues := map[string]interface{}{"session": "abc123", "theme": "dark", "msg": "hello%20world", "empty": ""}
+	if !reflect.DeepEqual(result["values"], wantValues) {
+		t.Errorf("values = %v, want %v", result["values"], wantValues)
+	}
+	if result["count"] != 5 || result["skipped"] != 2 {
+		t.Errorf("count = %v, skipped = %v, want 5 and 2", result["count"], result["skipped"])
+	}
+	// every occurrence is listed in order
+	cookies := result["cookies"].([]interface{})
+	if last := cookies[3].(map[string]interface{}); last["name"] != "session" || last["value"] != "second" {
+		t.Errorf("cookies[3] = %v", last)
+	}
+
+	result, err = dp.ParseCookies("msg=hello%20world; raw=100%", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// values that are not valid percent-encoding are kept as they are
+	wantValues = map[string]interface{}{"msg": "hello world", "raw": "100%"}
+	if !reflect.DeepEqual(result["values"], wantValues) {
+		t.Errorf("decoded values = %v, want %v", result["values"], wantValues)
+	}
+}
+
+func TestBuildCookie(t *testing.T) {
+	maxAge, expired := 3600, -1
+	tests := []struct {
+		name     string
+		opts     CookieOptions
+		want     string
+		warnings int
+	}{
+		{
+			name: "minimal",
+			opts: CookieOptions{Name: "id", Value: "42"},
+			want: "id=42",
+		},
+		{
+			name: "all attributes",
+			opts: CookieOptions{Name: "id", Value: "42", Path: "/", Domain: ".example.com",
+				Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)), MaxAge: &maxAge,
+				Secure: true, HTTPOnly: true, SameSite: "none", Partitioned: true},
+			want: "id=42; Path=/; Domain=example.com; Expires=Wed, 02 Jan 2030 02:04:05 GMT; Max-Age=3600; Secure; HttpOnly; SameSite=None; Partitioned",
+		},
+		{
+			name: "escaped value",
+			opts: CookieOptions{Name: "msg", Value: `a b;"c"%`},
+			want: "msg=a%20b%3B%22c%22%25",
+		},
+		{
+			name: "deleting",
+			opts: CookieOptions{Name: "id", MaxAge: &expired, SameSite: "LAX"},
+			want: "id=; Max-Age=0; SameSite=Lax",
+		},
+		{
+			name:     "insecure SameSite=None and Partitioned",
+			opts:     CookieOptions{Name: "id", SameSite: "None", Partitioned: true},
+			want:     "id=; SameSite=None; Partitioned",
+			warnings: 2,
+		},
+		{
+			name:     "host prefix with a domain",
+			opts:     CookieOptions{Name: "__Host-id", Path: "/", Domain: "example.com", Secure: true},
+			want:     "__Host-id=; Path=/; Domain=example.com; Secure",
+			warnings: 1,
+		},
+		{
+			name:     "secure prefix",
+			opts:     CookieOptions{Name: "__Secure-id"},
+			want:     "__Secure-id=",
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := BuildCookie(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildCookie = %q, want %q", got, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+
+	_, warnings, _ := BuildCookie(CookieOptions{Name: "big", Value: strings.Repeat("x", 4096)})
+	if len(warnings) != 1 {
+		t.Errorf("warnings for a large cookie = %v", warnings)
+	}
+}
+
+func TestBuildCookieErrors(t *testing.T) {
+	for _, opts := range []CookieOptions{
+		{Name: ""},
+		{Name: "a b"},
+		{Name: "a=b"},
+		{Name: "id", Path: "/; Domain=evil.com"},
+		{Name: "id", Domain: "example.com\r\nX-Injected: 1"},
+		{Name: "id", SameSite: "sometimes"},
+	} {
+		if _, _, err := BuildCookie(opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
+
+func TestCookieRoundTrip(t *testing.T) {
+	dp := NewDataProcessor()
+	value := "naïve; \"quoted\" 100% ,\\"
+	header, _, err := BuildCookie(CookieOptions{Name: "v", Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := dp.ParseCookies(header, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result["values"].(map[string]interface{})["v"]; got != value {
+		t.Errorf("round trip = %q, want %q", got, value)
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+// crc32Tables are the supported CRC-32 polynomials
+var crc32Tables = map[string]*crc32.Table{
+	"ieee":       crc32.IEEETable,
+	"castagnoli": crc32.MakeTable(crc32.Castagnoli),
+	"koopman":    crc32.MakeTable(crc32.Koopman),
+}
+
+// crc16Params describes a CRC-16 variant in the usual Rocksoft model
+type crc16Params struct {
+	Poly    uint16
+	Init    uint16
+	XorOut  uint16
+	Reflect bool
+}
+
+// crc16Variants are the supported CRC-16 variants
+var crc16Variants = map[string]crc16Params{
+	"ccitt-false": {Poly: 0x1021, Init: 0xFFFF},
+	"xmodem":      {Poly: 0x1021},
+	"kermit":      {Poly: 0x1021, Reflect: true},
+	"arc":         {Poly: 0x8005, Reflect: true},
+	"modbus":      {Poly: 0x8005, Init: 0xFFFF, Reflect: true},
+}
+
+func sortedKeys[V any](m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// CRC32 returns the CRC-32 of data with the named polynomial (ieee,
+// castagnoli or koopman)
+func CRC32(data []byte, polynomial string) (uint32, error) {
+	if polynomial == "" {
+		polynomial = "ieee"
+	}
+	table, ok := crc32Tables[strings.ToLower(polynomial)]
+	if !ok {
+		return 0, fmt.Errorf("unknown CRC-32 polynomial %q (expected %s)", polynomial, sortedKeys(crc32Tables))
+	}
+	return crc32.Checksum(data, table), nil
+}
+
+// reverse16 reverses the bit order of a 16-bit value
+func reverse16(v uint16) uint16 {
+	var r uint16
+	for i := 0; i < 16; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// CRC16 returns the CRC-16 of data with the named variant (ccitt-false,
+// xmodem, kermit, arc or modbus)
+func CRC16(data []byte, variant string) (uint16, error) {
+	if variant == "" {
+		variant = "ccitt-false"
+	}
+	p, ok := crc16Variants[strings.ToLower(variant)]
+	if !ok {
+		return 0, fmt.Errorf("unknown CRC-16 variant %q (expected %s)", variant, sortedKeys(crc16Variants))
+	}
+
+	crc := p.Init
+	if p.Reflect {
+		// reflected variants shift right using the bit-reversed polynomial
+		poly := reverse16(p.Poly)
+		for _, b := range data {
+			crc ^= uint16(b)
+			for i := 0; i < 8; i++ {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ poly
+				} else {
+					crc >>= 1
+				}
+			}
+		}
+	} else {
+		for _, b := range data {
+			crc ^= uint16(b) << 8
+			for i := 0; i < 8; i++ {
+				if crc&0x8000 != 0 {
+					crc = crc<<1 ^ p.Poly
+				} else {
+					crc <<= 1
+				}
+			}
+		}
+	}
+	return crc ^ p.XorOut, nil
+}
+
+// checksumResult formats a checksum as hex and decimal, comparing it with
+// expected when given
+func checksumResult(algorithm, variant string, sum uint32, digits int, expected *uint32) map[string]interface{} {
+	result := map[string]interface{}{
+		"algorithm": algorithm,
+		"variant":   variant,
+		"hex":       fmt.Sprintf("%0*x", digits, sum),
+		"decimal":   int64(sum),
+	}
+	if expected != nil {
+		result["expected"] = fmt.Sprintf("%0*x", digits, *expected)
+		result["valid"] = *expected == sum
+	}
+	return result
+}
+
+// CRC32 computes a CRC-32 checksum and, when expected is set, verifies it
+func (dp *DataProcessor) CRC32(data []byte, polynomial string, expected *uint32) (map[string]interface{}, error) {
+	sum, err := CRC32(data, polynomial)
+	if err != nil {
+		return nil, err
+	}
+	if polynomial == "" {
+		polynomial = "ieee"
+	}
+	return checksumResult("crc32", strings.ToLower(polynomial), sum, 8, expected), nil
+}
+
+// CRC16 computes a CRC-16 checksum and, when expected is set, verifies it
+func (dp *DataProcessor) CRC16(data []byte, variant string, expected *uint32) (map[string]interface{}, error) {
+	sum, err := CRC16(data, variant)
+	if err != nil {
+		return nil, err
+	}
+	if variant == "" {
+		variant = "ccitt-false"
+	}
+	return checksumResult("crc16", strings.ToLower(variant), uint32(sum), 4, expected), nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import "testing"
+
+// check values for "123456789" from the CRC catalogue
+func TestCRC32(t *testing.T) {
+	tests := []struct {
+		polynomial string
+		want       uint32
+	}{
+		{"", 0xcbf43926},
+		{"IEEE", 0xcbf43926},
+		{"castagnoli", 0xe3069283},
+		{"koopman", 0x2d3dd0ae},
+	}
+	for _, tt := range tests {
+		got, err := CRC32([]byte("123456789"), tt.polynomial)
+		if err != nil {
+			t.Errorf("%q: %v", tt.polynomial, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CRC32(%q) = %08x, want %08x", tt.polynomial, got, tt.want)
+		}
+	}
+	if _, err := CRC32(nil, "crc64"); err == nil {
+		t.Error("expected an error for an unknown polynomial")
+	}
+}
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		variant string
+		want    uint16
+	}{
+		{"", 0x29b1},
+		{"ccitt-false", 0x29b1},
+		{"xmodem", 0x31c3},
+		{"Kermit", 0x2189},
+		{"arc", 0xbb3d},
+		{"modbus", 0x4b37},
+	}
+	for _, tt := range tests {
+		got, err := CRC16([]byte("123456789"), tt.variant)
+		if err != nil {
+			t.Errorf("%q: %v", tt.variant, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CRC16(%q) = %04x, want %04x", tt.variant, got, tt.want)
+		}
+	}
+	if _, err := CRC16(nil, "usb"); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+}
+
+func TestCRCResult(t *testing.T) {
+	dp := NewDataProcessor()
+	expected := uint32(0x31c3)
+	result, err := dp.CRC16([]byte("123456789"), "XMODEM", &expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["variant"] != "xmodem" || result["hex"] != "31c3" || result["decimal"] != int64(0x31c3) || result["valid"] != true {
+		t.Errorf("result = %v", result)
+	}
+
+	expected = 1
+	result, err = dp.CRC32(nil, "", &expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["variant"] != "ieee" || result["hex"] != "00000000" || result["expected"] != "00000001" || result["valid"] != false {
+		t.Errorf("result = %v", result)
+	}
+
+	result, _ = dp.CRC32([]byte("x"), "", nil)
+	if _, ok := result["valid"]; ok {
+		t.Error("valid set without an expected checksum")
+	}
+}
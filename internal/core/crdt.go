@@ -0,0 +1,314 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// LWWRegister is a last-write-wins register CRDT. The write with the highest
+// timestamp wins and equal timestamps are broken by the larger replica ID,
+// so replicas that merge the same writes converge on the same value.
+type LWWRegister struct {
+	Value     interface{} `json:"value"`
+	Timestamp int64       `json:"timestamp"`
+	Replica   string      `json:"replica"`
+}
+
+// newer reports whether a write at timestamp by replica beats the current one
+func (r *LWWRegister) newer(timestamp int64, replica string) bool {
+	if timestamp != r.Timestamp {
+		return timestamp > r.Timestamp
+	}
+	return replica > r.Replica
+}
+
+// Set records a write and reports whether it became the current value.
+// Writes older than the current one are ignored.
+func (r *LWWRegister) Set(value interface{}, timestamp int64, replica string) bool {
+	if !r.newer(timestamp, replica) {
+		return false
+	}
+	r.Value, r.Timestamp, r.Replica = value, timestamp, replica
+	return true
+}
+
+// Merge folds another replica's state into r. Merging is commutative,
+// associative and idempotent.
+func (r *LWWRegister) Merge(other *LWWRegister) bool {
+	return r.Set(other.Value, other.Timestamp, other.Replica)
+}
+
+// State returns the register in a form that can be persisted or sent to
+// another replica
+func (r *LWWRegister) State() map[string]interface{} {
+	return map[string]interface{}{
+		"value":     r.Value,
+		"timestamp": r.Timestamp,
+		"replica":   r.Replica,
+	}
+}
+
+// ParseLWWRegister decodes a register from its JSON state
+func ParseLWWRegister(data string) (*LWWRegister, error) {
+	r := &LWWRegister{}
+	if data == "" {
+		return r, nil
+	}
+	if err := json.Unmarshal([]byte(data), r); err != nil {
+		return nil, fmt.Errorf("invalid register state: %w", err)
+	}
+	return r, nil
+}
+
+// GCounter is a grow-only counter CRDT holding one count per replica
+type GCounter map[string]int64
+
+// Increment adds n (which must not be negative) to replica's count
+func (g GCounter) Increment(replica string, n int64) {
+	g[replica] += n
+}
+
+// Value returns the sum of all replica counts
+func (g GCounter) Value() int64 {
+	var total int64
+	for _, n := range g {
+		total += n
+	}
+	return total
+}
+
+// Merge takes the element-wise maximum of both counters
+func (g GCounter) Merge(other GCounter) {
+	for replica, n := range other {
+		if n > g[replica] {
+			g[replica] = n
+		}
+	}
+}
+
+// Counter is a G-Counter (Kind "g") or a PN-Counter (Kind "pn"). A
+// PN-Counter pairs a G-Counter of increments with one of decrements.
+type Counter struct {
+	Kind string   `json:"kind"`
+	P    GCounter `json:"p"`
+	N    GCounter `json:"n,omitempty"`
+}
+
+// NewCounter creates an empty counter of the given kind
+func NewCounter(kind string) (*Counter, error) {
+	switch kind {
+	case "g":
+		return &Counter{Kind: kind, P: GCounter{}}, nil
+	case "pn":
+		return &Counter{Kind: kind, P: GCounter{}, N: GCounter{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown counter kind %q (expected g or pn)", kind)
+	}
+}
+
+// ParseCounter decodes a counter from its JSON state
+func ParseCounter(data string) (*Counter, error) {
+	var c Counter
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return nil, fmt.Errorf("invalid counter state: %w", err)
+	}
+
+	counter, err := NewCounter(c.Kind)
+	if err != nil {
+		return nil, err
+	}
+	counter.P.Merge(c.P)
+	if counter.N != nil {
+		counter.N.Merge(c.N)
+	}
+	return counter, nil
+}
+
+// Increment changes replica's contribution by delta. Negative deltas are
+// only allowed on PN-Counters.
+func (c *Counter) Increment(replica string, delta int64) error {
+	switch {
+	case delta >= 0:
+		c.P.Increment(replica, delta)
+	case c.Kind == "pn":
+		c.N.Increment(replica, -delta)
+	default:
+		return fmt.Errorf("a grow-only counter cannot be decremented")
+	}
+	return nil
+}
+
+// Value returns the counter's current total
+func (c *Counter) Value() int64 {
+	return c.P.Value() - c.N.Value()
+}
+
+// Merge folds another replica's counter of the same kind into c
+func (c *Counter) Merge(other *Counter) error {
+	if other.Kind != c.Kind {
+		return fmt.Errorf("cannot merge a %s counter into a %s counter", other.Kind, c.Kind)
+	}
+	c.P.Merge(other.P)
+	if c.N != nil {
+		c.N.Merge(other.N)
+	}
+	return nil
+}
+
+// State returns the counter's value and serializable state
+func (c *Counter) State() map[string]interface{} {
+	state := map[string]interface{}{
+		"kind":  c.Kind,
+		"value": c.Value(),
+		"p":     gcounterState(c.P),
+	}
+	if c.N != nil {
+		state["n"] = gcounterState(c.N)
+	}
+	return state
+}
+
+func gcounterState(g GCounter) map[string]interface{} {
+	out := make(map[string]interface{}, len(g))
+	for replica, n := range g {
+		out[replica] = n
+	}
+	return out
+}
+
+// ORSet is an observed-remove set CRDT. Every add creates a unique tag and a
+// remove only deletes the tags it has observed, so an add concurrent with a
+// remove survives the merge.
+type ORSet struct {
+	// Adds maps each element to its live add tags
+	Adds map[string][]string `json:"adds"`
+	// Removed holds tombstoned tags so merges don't resurrect them
+	Removed []string `json:"removed"`
+	// Clock counts adds per replica to generate unique tags
+	Clock map[string]int `json:"clock"`
+}
+
+// NewORSet creates an empty set
+func NewORSet() *ORSet {
+	return &ORSet{
+		Adds:    map[string][]string{},
+		Removed: []string{},
+		Clock:   map[string]int{},
+	}
+}
+
+// ParseORSet decodes a set from its JSON state
+func ParseORSet(data string) (*ORSet, error) {
+	s := NewORSet()
+	if data == "" {
+		return s, nil
+	}
+	var state ORSet
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("invalid OR-Set state: %w", err)
+	}
+	s.Merge(&state)
+	return s, nil
+}
+
+// Add inserts element on behalf of replica and returns the new tag
+func (s *ORSet) Add(element, replica string) string {
+	s.Clock[replica]++
+	tag := fmt.Sprintf("%s:%d", replica, s.Clock[replica])
+	s.Adds[element] = append(s.Adds[element], tag)
+	return tag
+}
+
+// Remove tombstones every tag of element seen so far and reports whether
+// the element was present
+func (s *ORSet) Remove(element string) bool {
+	tags, ok := s.Adds[element]
+	if !ok {
+		return false
+	}
+	s.Removed = append(s.Removed, tags...)
+	sort.Strings(s.Removed)
+	delete(s.Adds, element)
+	return true
+}
+
+// Contains reports whether element has a live add
+func (s *ORSet) Contains(element string) bool {
+	return len(s.Adds[element]) > 0
+}
+
+// Values returns the elements currently in the set, sorted
+func (s *ORSet) Values() []string {
+	values := make([]string, 0, len(s.Adds))
+	for element := range s.Adds {
+		values = append(values, element)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Merge folds another replica's set into s: the union of adds minus the
+// union of removes
+func (s *ORSet) Merge(other *ORSet) {
+	removed := make(map[string]bool, len(s.Removed)+len(other.Removed))
+	for _, tag := range s.Removed {
+		removed[tag] = true
+	}
+	for _, tag := range other.Removed {
+		if !removed[tag] {
+			removed[tag] = true
+			s.Removed = append(s.Removed, tag)
+		}
+	}
+	sort.Strings(s.Removed)
+
+	for element, tags := range other.Adds {
+		s.Adds[element] = append(s.Adds[element], tags...)
+	}
+	for element, tags := range s.Adds {
+		live := make([]string, 0, len(tags))
+		seen := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			if !removed[tag] && !seen[tag] {
+				seen[tag] = true
+				live = append(live, tag)
+			}
+		}
+		if len(live) == 0 {
+			delete(s.Adds, element)
+			continue
+		}
+		sort.Strings(live)
+		s.Adds[element] = live
+	}
+
+	for replica, n := range other.Clock {
+		if n > s.Clock[replica] {
+			s.Clock[replica] = n
+		}
+	}
+}
+
+// State returns the set's values and serializable state
+func (s *ORSet) State() (map[string]interface{}, error) {
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var state interface{}
+	if err := json.Unmarshal(encoded, &state); err != nil {
+		return nil, err
+	}
+
+	values := s.Values()
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return map[string]interface{}{
+		"values": list,
+		"size":   len(values),
+		"state":  state,
+	}, nil
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLWWRegisterSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp int64
+		replica   string
+		applied   bool
+		value     string
+	}{
+		{name: "newer write", timestamp: 20, replica: "a", applied: true, value: "new"},
+		{name: "older write", timestamp: 5, replica: "z", applied: false, value: "initial"},
+		{name: "tie broken by larger replica", timestamp: 10, replica: "c", applied: true, value: "new"},
+		{name: "tie lost to smaller replica", timestamp: 10, replica: "a", applied: false, value: "initial"},
+		{name: "same write again", timestamp: 10, replica: "b", applied: false, value: "initial"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &LWWRegister{}
+			r.Set("initial", 10, "b")
+			if applied := r.Set("new", tt.timestamp, tt.replica); applied != tt.applied {
+				t.Errorf("applied = %v, want %v", applied, tt.applied)
+			}
+			if r.Value != tt.value {
+				t.Errorf("value = %v, want %s", r.Value, tt.value)
+			}
+		})
+	}
+}
+
+func TestLWWRegisterMergeConverges(t *testing.T) {
+	writes := []LWWRegister{
+		{Value: "x", Timestamp: 3, Replica: "a"},
+		{Value: "y", Timestamp: 7, Replica: "b"},
+		{Value: "z", Timestamp: 7, Replica: "c"},
+		{Value: "w", Timestamp: 1, Replica: "d"},
+	}
+
+	// Every merge order ends on the same write
+	for _, order := range permutations(len(writes)) {
+		r := &LWWRegister{}
+		for _, i := range order {
+			w := writes[i]
+			r.Merge(&w)
+		}
+		if r.Value != "z" || r.Timestamp != 7 || r.Replica != "c" {
+			t.Errorf("order %v converged on %+v", order, *r)
+		}
+
+		// Merging the result again changes nothing
+		again := *r
+		if r.Merge(&again) {
+			t.Errorf("order %v: merging a register into itself changed it", order)
+		}
+	}
+}
+
+func TestParseLWWRegister(t *testing.T) {
+	r, err := ParseLWWRegister(`{"value":{"n":1},"timestamp":5,"replica":"a"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"value": map[string]interface{}{"n": 1.0}, "timestamp": int64(5), "replica": "a"}
+	if !reflect.DeepEqual(r.State(), want) {
+		t.Errorf("state = %v, want %v", r.State(), want)
+	}
+
+	if r, err := ParseLWWRegister(""); err != nil || r.Timestamp != 0 || r.Value != nil {
+		t.Errorf("empty state gave %+v, %v", r, err)
+	}
+	if _, err := ParseLWWRegister(`{"timestamp":"soon"}`); err == nil {
+		t.Error("expected an error for invalid state")
+	}
+}
+
+// permutations returns every ordering of 0..n-1
+func permutations(n int) [][]int {
+	if n == 0 {
+		return [][]int{{}}
+	}
+	var out [][]int
+	for _, p := range permutations(n - 1) {
+		for i := 0; i <= len(p); i++ {
+			perm := make([]int, 0, n)
+			perm = append(perm, p[:i]...)
+			perm = append(perm, n-1)
+			perm = append(perm, p[i:]...)
+			out = append(out, perm)
+		}
+	}
+	return out
+}
+
+func TestCounterIncrement(t *testing.T) {
+	tests := []struct {
+		kind   string
+		deltas []int64
+		value  int64
+		err    bool
+	}{
+		{kind: "g", deltas: []int64{1, 2, 0}, value: 3},
+		{kind: "g", deltas: []int64{1, -1}, value: 1, err: true},
+		{kind: "pn", deltas: []int64{5, -2, -4}, value: -1},
+	}
+
+	for _, tt := range tests {
+		c, err := NewCounter(tt.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var incErr error
+		for _, d := range tt.deltas {
+			if err := c.Increment("a", d); err != nil {
+				incErr = err
+			}
+		}
+		if (incErr != nil) != tt.err {
+			t.Errorf("%s %v: error = %v", tt.kind, tt.deltas, incErr)
+		}
+		if c.Value() != tt.value {
+			t.Errorf("%s %v: value = %d, want %d", tt.kind, tt.deltas, c.Value(), tt.value)
+		}
+	}
+
+	if _, err := NewCounter("max"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func TestCounterMergeConverges(t *testing.T) {
+	// Each replica's state after its own increments
+	replicas := make([]*Counter, 3)
+	for i, deltas := range [][]int64{{3, -1}, {10}, {-4, -4, 1}} {
+		replicas[i], _ = NewCounter("pn")
+		for _, d := range deltas {
+			replicas[i].Increment(string(rune('a'+i)), d)
+		}
+	}
+
+	for _, order := range permutations(len(replicas)) {
+		c, _ := NewCounter("pn")
+		for _, i := range order {
+			// Merging a replica twice must not count it twice
+			c.Merge(replicas[i])
+			c.Merge(replicas[i])
+		}
+		if c.Value() != 5 {
+			t.Errorf("order %v: value = %d, want 5", order, c.Value())
+		}
+	}
+
+	g, _ := NewCounter("g")
+	if err := g.Merge(replicas[0]); err == nil {
+		t.Error("expected an error merging counters of different kinds")
+	}
+}
+
+func TestParseCounter(t *testing.T) {
+	c, _ := NewCounter("pn")
+	c.Increment("a", 4)
+	c.Increment("b", -6)
+	state := c.State()
+	want := map[string]interface{}{
+		"kind":  "pn",
+		"value": int64(-2),
+		"p":     map[string]interface{}{"a": int64(4)},
+		"n":     map[string]interface{}{"b": int64(6)},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("state = %v, want %v", state, want)
+	}
+
+	parsed, err := ParseCounter(`{"kind":"pn","p":{"a":4},"n":{"b":6}}`)
+	if err != nil || parsed.Value() != -2 {
+		t.Errorf("parsed value %v, %v", parsed, err)
+	}
+	g, err := ParseCounter(`{"kind":"g","p":{"a":2}}`)
+	if err != nil || g.Value() != 2 || g.N != nil {
+		t.Errorf("parsed g counter %+v, %v", g, err)
+	}
+
+	for _, data := range []string{``, `{"kind":"x"}`, `{"kind":"g","p":{"a":"1"}}`} {
+		if _, err := ParseCounter(data); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestORSetAddRemove(t *testing.T) {
+	s := NewORSet()
+	if tag := s.Add("x", "a"); tag != "a:1" {
+		t.Errorf("tag = %q, want a:1", tag)
+	}
+	s.Add("y", "a")
+	s.Add("x", "b")
+	if !s.Remove("x") {
+		t.Error("expected x to be removed")
+	}
+	if s.Remove("x") || s.Remove("z") {
+		t.Error("removing an absent element reported success")
+	}
+	if got := s.Values(); !reflect.DeepEqual(got, []string{"y"}) {
+		t.Errorf("values = %v, want [y]", got)
+	}
+
+	// Re-adding after a remove makes the element present again
+	s.Add("x", "a")
+	if !s.Contains("x") || s.Adds["x"][0] != "a:3" {
+		t.Errorf("re-added x has tags %v", s.Adds["x"])
+	}
+}
+
+func TestORSetConcurrentAddWins(t *testing.T) {
+	base := NewORSet()
+	base.Add("x", "a")
+
+	// Replica a removes x while replica b concurrently adds it again
+	a, b := NewORSet(), NewORSet()
+	a.Merge(base)
+	b.Merge(base)
+	a.Remove("x")
+	b.Add("x", "b")
+
+	for _, pair := range [][2]*ORSet{{a, b}, {b, a}} {
+		s := NewORSet()
+		s.Merge(pair[0])
+		s.Merge(pair[1])
+		if !reflect.DeepEqual(s.Values(), []string{"x"}) {
+			t.Errorf("values = %v, want [x]", s.Values())
+		}
+		if !reflect.DeepEqual(s.Adds["x"], []string{"b:1"}) {
+			t.Errorf("live tags = %v, want [b:1]", s.Adds["x"])
+		}
+	}
+
+	// A remove that observed both adds deletes the element everywhere
+	a.Merge(b)
+	a.Remove("x")
+	b.Merge(a)
+	if b.Contains("x") {
+		t.Error("x survived a remove that observed every add")
+	}
+}
+
+func TestORSetMergeConverges(t *testing.T) {
+	replicas := []*ORSet{NewORSet(), NewORSet(), NewORSet()}
+	replicas[0].Add("x", "a")
+	replicas[0].Add("y", "a")
+	replicas[1].Merge(replicas[0])
+	replicas[1].Remove("x")
+	replicas[1].Add("z", "b")
+	replicas[2].Add("x", "c")
+
+	for _, order := range permutations(len(replicas)) {
+		s := NewORSet()
+		for _, i := range order {
+			s.Merge(replicas[i])
+			s.Merge(replicas[i])
+		}
+		if want := []string{"x", "y", "z"}; !reflect.DeepEqual(s.Values(), want) {
+			t.Errorf("order %v: values = %v, want %v", order, s.Values(), want)
+		}
+		if !reflect.DeepEqual(s.Adds["x"], []string{"c:1"}) {
+			t.Errorf("order %v: x tags = %v, want [c:1]", order, s.Adds["x"])
+		}
+		if !reflect.DeepEqual(s.Removed, []string{"a:1"}) {
+			t.Errorf("order %v: removed = %v, want [a:1]", order, s.Removed)
+		}
+	}
+}
+
+func TestParseORSet(t *testing.T) {
+	s := NewORSet()
+	s.Add("x", "a")
+	s.Add("y", "a")
+	s.Remove("y")
+	state, err := s.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state["size"] != 1 || !reflect.DeepEqual(state["values"], []interface{}{"x"}) {
+		t.Errorf("state = %v", state)
+	}
+
+	encoded, err := json.Marshal(state["state"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseORSet(string(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, s) {
+		t.Errorf("parsed %+v, want %+v", parsed, s)
+	}
+	// The clock is restored so new tags don't collide with old ones
+	if tag := parsed.Add("y", "a"); tag != "a:3" {
+		t.Errorf("tag after parse = %q, want a:3", tag)
+	}
+
+	if s, err := ParseORSet(""); err != nil || len(s.Values()) != 0 {
+		t.Errorf("empty state gave %+v, %v", s, err)
+	}
+	if _, err := ParseORSet(`{"adds":[]}`); err == nil {
+		t.Error("expected an error for invalid state")
+	}
+}
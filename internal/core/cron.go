@@ -0,0 +1,380 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxCronRuns bounds how many fire times ParseCron returns
+const MaxCronRuns = 100
+
+// cronSearchYears is how far ahead Next looks before deciding a schedule
+// never fires, e.g. February 30th
+const cronSearchYears = 5
+
+// cronShortcuts are the @-prefixed aliases for common schedules
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// cronField describes one of the five schedule fields
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, ...
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: dayNames},
+}
+
+// CronSchedule is a parsed standard 5-field cron expression. Each field is
+// a bitset of the values it matches.
+type CronSchedule struct {
+	Expression string
+	minute     uint64
+	hour       uint64
+	dom        uint64
+	month      uint64
+	dow        uint64
+	// A "*" day field doesn't restrict; when both day fields are
+	// restricted a day matching either one fires, as in Vixie cron
+	domStar bool
+	dowStar bool
+}
+
+// ParseCron parses "minute hour day-of-month month day-of-week" with lists
+// (1,2), ranges (1-5), steps (*/15, 1-30/5), month and weekday names (JAN,
+// MON) and the shortcuts @yearly, @monthly, @weekly, @daily and @hourly.
+// Sunday is 0 or 7.
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if strings.HasPrefix(spec, "@") {
+		full, ok := cronShortcuts[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron shortcut %q", spec)
+		}
+		spec = full
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields (minute hour day-of-month month day-of-week), got %d", len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	// Sunday may be written as 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+
+	return &CronSchedule{
+		Expression: expr,
+		minute:     sets[0],
+		hour:       sets[1],
+		dom:        sets[2],
+		month:      sets[3],
+		dow:        sets[4],
+		domStar:    parts[2] == "*" || parts[2] == "?",
+		dowStar:    parts[4] == "*" || parts[4] == "?",
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps
+func parseCronField(text string, field cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(text, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, field.name)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			lo, hi = field.min, field.max
+			if field.name == "day of week" {
+				hi = 6
+			}
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(a, field); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(b, field); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field: start is after end", rangePart, field.name)
+			}
+		default:
+			var err error
+			if lo, err = cronValue(rangePart, field); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				// "5/15" means from 5 to the end in steps of 15
+				hi = field.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a number or name within a field's range
+func cronValue(text string, field cronField) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(text, name) {
+			return field.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", text, field.name)
+	}
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("%s value %d is out of range %d-%d", field.name, v, field.min, field.max)
+	}
+	return v, nil
+}
+
+func cronHas(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// dayMatches applies the day-of-month/day-of-week rules to t's date
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cronHas(s.dom, t.Day())
+	dowMatch := cronHas(s.dow, int(t.Weekday()))
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dowMatch
+	case s.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// Next returns the first fire time strictly after t, in t's location, or
+// false if the schedule never fires in the next few years
+func (s *CronSchedule) Next(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		if !cronHas(s.month, int(t.Month())) {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !cronHas(s.hour, t.Hour()) {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if !cronHas(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// cronAdvance returns next, unless a daylight saving gap made time.Date
+// normalize it to t or earlier, e.g. 02:00 on the day clocks skip to 03:00.
+// Then it returns the start of the hour after t so the search moves on.
+func cronAdvance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+}
+
+// cronValues lists the members of a bitset within [min, max]
+func cronValues(set uint64, min, max int) []int {
+	var values []int
+	for v := min; v <= max; v++ {
+		if cronHas(set, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// describeValues renders values as a readable list, collapsing runs of
+// three or more into "A through B"
+func describeValues(values []int, label func(int) string) string {
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, label(values[i])+" through "+label(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, label(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	if len(parts) <= 1 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// cronStep reports whether values form the full field range stepped by n,
+// as written with "*/n"
+func cronStep(values []int, min, max int) (int, bool) {
+	if len(values) < 2 || values[0] != min {
+		return 0, false
+	}
+	step := values[1] - values[0]
+	for i := 1; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0, false
+		}
+	}
+	return step, values[len(values)-1]+step > max
+}
+
+// Describe renders the schedule in English, e.g. "every day at 03:00" or
+// "every 15 minutes on Monday through Friday"
+func (s *CronSchedule) Describe() string {
+	minutes := cronValues(s.minute, 0, 59)
+	hours := cronValues(s.hour, 0, 23)
+	number := strconv.Itoa
+
+	var when string
+	switch {
+	case len(minutes) == 1 && len(hours) == 1:
+		when = fmt.Sprintf("at %02d:%02d", hours[0], minutes[0])
+	case len(minutes) == 60 && len(hours) == 24:
+		when = "every minute"
+	case len(hours) == 24:
+		if step, ok := cronStep(minutes, 0, 59); ok {
+			when = fmt.Sprintf("every %d minutes", step)
+		} else if len(minutes) == 1 && minutes[0] == 0 {
+			when = "every hour"
+		} else {
+			when = "every hour at minute " + describeValues(minutes, number)
+		}
+	case len(minutes) == 1:
+		clock := func(h int) string { return fmt.Sprintf("%02d:%02d", h, minutes[0]) }
+		if step, ok := cronStep(hours, 0, 23); ok {
+			when = fmt.Sprintf("every %d hours at minute %d", step, minutes[0])
+		} else {
+			when = "at " + describeValues(hours, clock)
+		}
+	default:
+		when = "at minute " + describeValues(minutes, number) + " past hour " + describeValues(hours, number)
+	}
+
+	var days []string
+	if !s.domStar {
+		values := cronValues(s.dom, 1, 31)
+		label := "day "
+		if len(values) > 1 {
+			label = "days "
+		}
+		days = append(days, "on "+label+describeValues(values, number)+" of the month")
+	}
+	if !s.dowStar {
+		weekday := func(d int) string { return time.Weekday(d).String() }
+		days = append(days, "on "+describeValues(cronValues(s.dow, 0, 6), weekday))
+	}
+
+	description := when
+	if len(days) > 0 {
+		description += " " + strings.Join(days, " or ")
+	} else if strings.HasPrefix(when, "at ") {
+		description = "every day " + when
+	}
+
+	if months := cronValues(s.month, 1, 12); len(months) < 12 {
+		monthName := func(m int) string { return time.Month(m).String() }
+		description += " in " + describeValues(months, monthName)
+	}
+	return description
+}
+
+// ParseCron validates a cron expression and returns its description and the
+// next count fire times after from
+func (dp *DataProcessor) ParseCron(expr string, from time.Time, count int) (map[string]interface{}, error) {
+	if count < 1 || count > MaxCronRuns {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxCronRuns)
+	}
+
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]interface{}, 0, count)
+	t := from
+	for len(runs) < count {
+		next, ok := schedule.Next(t)
+		if !ok {
+			break
+		}
+		runs = append(runs, next.Format(time.RFC3339))
+		t = next
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("cron expression %q never fires", expr)
+	}
+
+	return map[string]interface{}{
+		"expression":  schedule.Expression,
+		"description": schedule.Describe(),
+		"next":        runs,
+		"from":        from.Format(time.RFC3339),
+	}, nil
+}
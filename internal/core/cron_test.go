@@ -0,0 +1,135 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2025, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * MON-FRI", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches
+		{"0 0 20 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 16 * 1", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		got, ok := s.Next(from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s: next = %v, %v; want %v", tt.expr, got, ok, tt.want)
+		}
+	}
+
+	s, _ := ParseCron("0 0 30 2 *")
+	if _, ok := s.Next(from); ok {
+		t.Error("February 30th fired")
+	}
+}
+
+func TestCronNextKeepsLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	s, _ := ParseCron("30 2 * * *")
+	// 02:30 doesn't exist on the day clocks spring forward, so the run
+	// lands on the next day that has it
+	got, ok := s.Next(time.Date(2025, 3, 8, 12, 0, 0, 0, loc))
+	if !ok || got.Location() != loc || got.Hour() != 2 || got.Minute() != 30 || got.Day() != 10 {
+		t.Errorf("next = %v, %v", got, ok)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@sometimes",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
+
+func TestCronDescribe(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"* * * * *", "every minute"},
+		{"0 3 * * *", "every day at 03:00"},
+		{"*/15 * * * 1-5", "every 15 minutes on Monday through Friday"},
+		{"0 * * * *", "every hour"},
+		{"5,10 * * * *", "every hour at minute 5 and 10"},
+		{"0 */6 * * *", "every 6 hours at minute 0"},
+		{"30 8,12,17 * * *", "every day at 08:30, 12:30 and 17:30"},
+		{"0 0 1,15 * *", "at 00:00 on days 1 and 15 of the month"},
+		{"0 0 1 * 0", "at 00:00 on day 1 of the month or on Sunday"},
+		{"@yearly", "at 00:00 on day 1 of the month in January"},
+		{"0-1 2-3 * * *", "every day at minute 0 and 1 past hour 2 and 3"},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Describe(); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronHandler(t *testing.T) {
+	dp := NewDataProcessor()
+	from := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	result, err := dp.ParseCron("@hourly", from, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"2025-01-15T11:00:00Z", "2025-01-15T12:00:00Z", "2025-01-15T13:00:00Z"}
+	if !reflect.DeepEqual(result["next"], want) || result["expression"] != "@hourly" {
+		t.Errorf("result = %v", result)
+	}
+
+	for _, count := range []int{0, MaxCronRuns + 1} {
+		if _, err := dp.ParseCron("* * * * *", from, count); err == nil {
+			t.Errorf("count %d: expected an error", count)
+		}
+	}
+	if _, err := dp.ParseCron("0 0 31 4 *", from, 1); err == nil {
+		t.Error("expected an error for a schedule that never fires")
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MaxDecompressedSize bounds how much a gzipped payload may expand to
+const MaxDecompressedSize = 16 << 20
+
+// Delta returns a log holding only the operations a replica at have is
+// missing, with the full clock so the receiver can advance its own
+func (l *OpLog) Delta(have VectorClock) *OpLog {
+	return &OpLog{Ops: l.Since(have), Clock: l.Clock.Copy()}
+}
+
+// GzipJSON encodes v as gzip-compressed JSON
+func GzipJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GunzipJSON decodes gzip-compressed JSON into v, refusing payloads that
+// expand beyond MaxDecompressedSize
+func GunzipJSON(data []byte, v interface{}) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid gzip data: %w", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(zr, MaxDecompressedSize+1))
+	if err != nil {
+		return fmt.Errorf("invalid gzip data: %w", err)
+	}
+	if len(raw) > MaxDecompressedSize {
+		return fmt.Errorf("decompressed payload exceeds %d bytes", MaxDecompressedSize)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// OpLogDelta encodes the operations a replica at the have clock is missing
+// as base64 gzipped JSON and reports the size against sending the full log
+func (dp *DataProcessor) OpLogDelta(logJSON, haveJSON string) (map[string]interface{}, error) {
+	log, err := ParseOpLog(logJSON)
+	if err != nil {
+		return nil, err
+	}
+	have := VectorClock{}
+	if haveJSON != "" {
+		if err := json.Unmarshal([]byte(haveJSON), &have); err != nil {
+			return nil, fmt.Errorf("invalid vector clock: %w", err)
+		}
+	}
+
+	full, err := json.Marshal(log)
+	if err != nil {
+		return nil, err
+	}
+	delta := log.Delta(have)
+	raw, err := json.Marshal(delta)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := GzipJSON(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := 0.0
+	if len(full) > 0 {
+		saved = float64(len(full)-len(compressed)) / float64(len(full)) * 100
+	}
+
+	return map[string]interface{}{
+		"delta":           base64.StdEncoding.EncodeToString(compressed),
+		"ops":             len(delta.Ops),
+		"fullBytes":       len(full),
+		"deltaBytes":      len(raw),
+		"compressedBytes": len(compressed),
+		"savedBytes":      len(full) - len(compressed),
+		"savedPercent":    saved,
+	}, nil
+}
+
+// OpLogApplyDelta merges a delta produced by OpLogDelta into a log
+func (dp *DataProcessor) OpLogApplyDelta(logJSON, encoded string) (map[string]interface{}, error) {
+	log, err := ParseOpLog(logJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 delta: %w", err)
+	}
+	delta := &OpLog{}
+	if err := GunzipJSON(compressed, delta); err != nil {
+		return nil, err
+	}
+	if delta.Clock == nil {
+		delta.Clock = VectorClock{}
+	}
+
+	added := log.Merge(delta)
+
+	result, err := opLogResult(log)
+	if err != nil {
+		return nil, err
+	}
+	result["added"] = added
+	return result, nil
+}
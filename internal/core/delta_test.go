@@ -0,0 +1,105 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGzipJSONRoundTrip(t *testing.T) {
+	in := map[string]interface{}{"ops": []interface{}{"x", 1.0}, "clock": map[string]interface{}{"a": 2.0}}
+	data, err := GzipJSON(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := GunzipJSON(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %v, want %v", out, in)
+	}
+
+	if err := GunzipJSON([]byte("not gzip"), &out); err == nil {
+		t.Error("expected an error for data that isn't gzipped")
+	}
+}
+
+func TestGunzipJSONSizeLimit(t *testing.T) {
+	// A small payload that expands past the limit
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`"`))
+	zw.Write(bytes.Repeat([]byte("a"), MaxDecompressedSize))
+	zw.Write([]byte(`"`))
+	zw.Close()
+
+	var s string
+	err := GunzipJSON(buf.Bytes(), &s)
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("err = %v, want a size error", err)
+	}
+}
+
+func TestOpLogDeltaRoundTrip(t *testing.T) {
+	dp := NewDataProcessor()
+	source, _ := ParseOpLog("")
+	for i := 0; i < 20; i++ {
+		source.Append("a", strings.Repeat("note ", 10))
+	}
+	source.Append("b", "reply")
+	logJSON, _ := json.Marshal(source)
+
+	// The receiver already has the first 15 operations from a
+	receiver := &OpLog{Ops: append([]Operation(nil), source.Ops[:15]...), Clock: VectorClock{"a": 15}}
+	receiverJSON, _ := json.Marshal(receiver)
+
+	delta, err := dp.OpLogDelta(string(logJSON), `{"a":15}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta["ops"] != 6 {
+		t.Errorf("ops = %v, want 6", delta["ops"])
+	}
+	if delta["compressedBytes"].(int) >= delta["fullBytes"].(int) {
+		t.Errorf("compressed delta is no smaller than the full log: %v", delta)
+	}
+
+	applied, err := dp.OpLogApplyDelta(string(receiverJSON), delta["delta"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied["added"] != 6 {
+		t.Errorf("added = %v, want 6", applied["added"])
+	}
+	if applied["json"] != string(logJSON) {
+		t.Errorf("applied log differs from the source:\n%s\n%s", applied["json"], logJSON)
+	}
+
+	// Applying the same delta again adds nothing
+	again, err := dp.OpLogApplyDelta(applied["json"].(string), delta["delta"].(string))
+	if err != nil || again["added"] != 0 {
+		t.Errorf("reapplied: added %v, %v", again["added"], err)
+	}
+}
+
+func TestOpLogDeltaErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	if _, err := dp.OpLogDelta("{", ""); err == nil {
+		t.Error("expected an error for an invalid log")
+	}
+	if _, err := dp.OpLogDelta("", "[1]"); err == nil {
+		t.Error("expected an error for an invalid clock")
+	}
+	if _, err := dp.OpLogApplyDelta("", "%%%"); err == nil {
+		t.Error("expected an error for invalid base64")
+	}
+	notGzip := base64.StdEncoding.EncodeToString([]byte("{}"))
+	if _, err := dp.OpLogApplyDelta("", notGzip); err == nil {
+		t.Error("expected an error for a delta that isn't gzipped")
+	}
+}
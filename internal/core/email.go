@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+)
+
+// gmailDomains ignore dots in the local part and deliver to the same
+// mailboxes, so canonical forms drop the dots and use gmail.com
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// reservedTLDs never route mail on the public internet (RFC 2606, 6761)
+var reservedTLDs = map[string]bool{
+	"example": true, "invalid": true, "localhost": true, "test": true, "local": true,
+}
+
+// checkEmailDomain validates a host name domain in its ASCII form: at most
+// 253 characters of dot-separated labels of 1 to 63 letters, digits and
+// hyphens, not starting or ending with a hyphen
+func checkEmailDomain(domain string) error {
+	if len(domain) > 253 {
+		return fmt.Errorf("domain is longer than 253 characters")
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("domain label %q must be 1 to 63 characters", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("domain label %q must not start or end with a hyphen", label)
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !isASCIILetter(c) && (c < '0' || c > '9') && c != '-' {
+				return fmt.Errorf("domain label %q has an invalid character", label)
+			}
+		}
+	}
+	return nil
+}
+
+// NormalizeEmail parses an email address with net/mail, which accepts
+// what the simple "email" validation rejects, such as quoted local parts
+// and internationalized domains, and rejects what it accepts, such as
+// consecutive dots. A display name form like "Ann <ann@example.com>" is
+// accepted. The domain is lowercased and checked label by label; the local
+// part keeps its case as RFC 5321 allows servers to treat it as
+// case-sensitive. With canonicalize, the canonical form also lowercases
+// the local part, drops a +tag and, for Gmail, removes dots, so addresses
+// that reach the same mailbox compare equal for deduplication. The
+// signals describe whether the domain looks like one that can receive
+// mail; no DNS lookup is made.
+func (dp *DataProcessor) NormalizeEmail(input string, canonicalize bool) (map[string]interface{}, error) {
+	invalid := func(reason string) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"input":  input,
+			"valid":  false,
+			"reason": reason,
+		}, nil
+	}
+
+	parsed, err := mail.ParseAddress(input)
+	if err != nil {
+		return invalid(strings.TrimPrefix(err.Error(), "mail: "))
+	}
+	at := strings.LastIndexByte(parsed.Address, '@')
+	local, domain := parsed.Address[:at], strings.ToLower(parsed.Address[at+1:])
+	if len(local) > 64 {
+		return invalid("local part is longer than 64 characters")
+	}
+
+	ipLiteral := strings.HasPrefix(domain, "[")
+	asciiDomain := domain
+	if ipLiteral {
+		ip := strings.TrimPrefix(strings.Trim(domain, "[]"), "ipv6:")
+		if net.ParseIP(ip) == nil {
+			return invalid(fmt.Sprintf("domain literal %s is not an IP address", domain))
+		}
+	} else {
+		if asciiDomain, err = ToASCIIHost(domain); err != nil {
+			return invalid(fmt.Sprintf("domain: %v", err))
+		}
+		if err := checkEmailDomain(asciiDomain); err != nil {
+			return invalid(err.Error())
+		}
+	}
+
+	// mail.Address quotes the local part again where it needs quoting
+	address := strings.Trim((&mail.Address{Address: local + "@" + domain}).String(), "<>")
+	quoted := strings.HasPrefix(address, `"`)
+
+	tag := ""
+	base := local
+	if i := strings.IndexByte(local, '+'); i > 0 && !quoted {
+		base, tag = local[:i], local[i+1:]
+	}
+
+	tld := asciiDomain[strings.LastIndexByte(asciiDomain, '.')+1:]
+	dotted := !ipLiteral && strings.Contains(asciiDomain, ".")
+	validTLD := dotted && len(tld) >= 2 && (strings.HasPrefix(tld, acePrefix) || strings.Trim(tld, "abcdefghijklmnopqrstuvwxyz") == "")
+	reserved := !ipLiteral && reservedTLDs[tld]
+
+	result := map[string]interface{}{
+		"input":       input,
+		"valid":       true,
+		"address":     address,
+		"name":        parsed.Name,
+		"local":       local,
+		"domain":      domain,
+		"asciiDomain": asciiDomain,
+		"tag":         tag,
+		"quoted":      quoted,
+		"signals": map[string]interface{}{
+			"dottedDomain":   dotted,
+			"validTLD":       validTLD,
+			"ipLiteral":      ipLiteral,
+			"reservedDomain": reserved,
+			"mxLikely":       validTLD && !reserved,
+		},
+	}
+
+	if canonicalize {
+		canonicalLocal, canonicalDomain := local, domain
+		if !quoted {
+			canonicalLocal = strings.ToLower(base)
+			if gmailDomains[domain] {
+				canonicalLocal = strings.ReplaceAll(canonicalLocal, ".", "")
+				canonicalDomain = "gmail.com"
+			}
+		}
+		result["canonical"] = strings.Trim((&mail.Address{Address: canonicalLocal + "@" + canonicalDomain}).String(), "<>")
+	}
+
+	return result, nil
+}
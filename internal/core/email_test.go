@@ -0,0 +1,114 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		input       string
+		address     string
+		asciiDomain string
+		tag         string
+		canonical   string
+		quoted      bool
+	}{
+		{"Ann@Example.COM", "Ann@example.com", "example.com", "", "ann@example.com", false},
+		{"ann+news@example.com", "ann+news@example.com", "example.com", "news", "ann@example.com", false},
+		{"A.n.n+x@GoogleMail.com", "A.n.n+x@googlemail.com", "googlemail.com", "x", "ann@gmail.com", false},
+		{"Ann <ann@example.com>", "ann@example.com", "example.com", "", "ann@example.com", false},
+		{`"a b+c"@example.com`, `"a b+c"@example.com`, "example.com", "", `"a b+c"@example.com`, true},
+		{"user@bücher.de", "user@bücher.de", "xn--bcher-kva.de", "", "user@bücher.de", false},
+		{"user@[192.0.2.1]", "user@[192.0.2.1]", "[192.0.2.1]", "", "user@[192.0.2.1]", false},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.NormalizeEmail(tt.input, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if result["valid"] != true {
+			t.Errorf("%s: invalid: %v", tt.input, result["reason"])
+			continue
+		}
+		if result["address"] != tt.address {
+			t.Errorf("%s: address = %v, want %s", tt.input, result["address"], tt.address)
+		}
+		if result["asciiDomain"] != tt.asciiDomain {
+			t.Errorf("%s: asciiDomain = %v, want %s", tt.input, result["asciiDomain"], tt.asciiDomain)
+		}
+		if result["tag"] != tt.tag {
+			t.Errorf("%s: tag = %v, want %s", tt.input, result["tag"], tt.tag)
+		}
+		if result["canonical"] != tt.canonical {
+			t.Errorf("%s: canonical = %v, want %s", tt.input, result["canonical"], tt.canonical)
+		}
+		if result["quoted"] != tt.quoted {
+			t.Errorf("%s: quoted = %v, want %v", tt.input, result["quoted"], tt.quoted)
+		}
+	}
+}
+
+func TestNormalizeEmailSignals(t *testing.T) {
+	tests := []struct {
+		input    string
+		validTLD bool
+		reserved bool
+		ip       bool
+		mx       bool
+	}{
+		{"ann@example.org", true, false, false, true},
+		{"ann@example.test", true, true, false, false},
+		{"ann@localhost", false, true, false, false},
+		{"ann@[192.0.2.1]", false, false, true, false},
+		{"ann@example.xn--p1ai", true, false, false, true},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.NormalizeEmail(tt.input, false)
+		if err != nil || result["valid"] != true {
+			t.Fatalf("%s: %v %v", tt.input, err, result["reason"])
+		}
+		if _, ok := result["canonical"]; ok {
+			t.Errorf("%s: canonical set without canonicalize", tt.input)
+		}
+		signals := result["signals"].(map[string]interface{})
+		if signals["validTLD"] != tt.validTLD || signals["reservedDomain"] != tt.reserved ||
+			signals["ipLiteral"] != tt.ip || signals["mxLikely"] != tt.mx {
+			t.Errorf("%s: signals = %v", tt.input, signals)
+		}
+	}
+}
+
+func TestNormalizeEmailInvalid(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"", "no address"},
+		{"ann", "missing '@'"},
+		{"a..b@example.com", ""},
+		{strings.Repeat("a", 65) + "@example.com", "longer than 64"},
+		{"ann@-example.com", "invalid label"},
+		{"ann@" + strings.Repeat("a", 64) + ".com", "1 to 63"},
+		{"ann@[999.0.2.1]", ""},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.NormalizeEmail(tt.input, true)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if result["valid"] != false {
+			t.Errorf("%q: accepted as %v", tt.input, result["address"])
+			continue
+		}
+		if reason, _ := result["reason"].(string); !strings.Contains(reason, tt.reason) {
+			t.Errorf("%q: reason %q does not mention %q", tt.input, reason, tt.reason)
+		}
+	}
+}
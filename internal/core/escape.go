@@ -0,0 +1,83 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// EscapeContexts lists the supported escape targets. All are reversible with
+// Unescape except "shell", whose quoting is only meant to be consumed by a
+// POSIX shell.
+var EscapeContexts = []string{"html", "attribute", "url", "json", "shell"}
+
+// attributeReplacer escapes everything html.EscapeString does plus
+// characters that can end an unquoted attribute value
+var attributeReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&#34;",
+	"'", "&#39;",
+	"`", "&#96;",
+	"=", "&#61;",
+	" ", "&#32;",
+	"\t", "&#9;",
+	"\n", "&#10;",
+	"\r", "&#13;",
+)
+
+// Escape encodes input for safe inclusion in the given context
+func (dp *DataProcessor) Escape(input, context string) (string, error) {
+	switch context {
+	case "html":
+		return html.EscapeString(input), nil
+	case "attribute":
+		return attributeReplacer.Replace(input), nil
+	case "url":
+		return url.QueryEscape(input), nil
+	case "json":
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(true)
+		if err := enc.Encode(input); err != nil {
+			return "", err
+		}
+		// Drop the surrounding quotes and trailing newline
+		encoded := strings.TrimSuffix(buf.String(), "\n")
+		return encoded[1 : len(encoded)-1], nil
+	case "shell":
+		// Single quotes disable all expansion; embedded quotes are closed,
+		// escaped and reopened
+		return "'" + strings.ReplaceAll(input, "'", `'\''`) + "'", nil
+	default:
+		return "", fmt.Errorf("unknown escape context: %s", context)
+	}
+}
+
+// Unescape reverses Escape for the reversible contexts
+func (dp *DataProcessor) Unescape(input, context string) (string, error) {
+	switch context {
+	case "html", "attribute":
+		return html.UnescapeString(input), nil
+	case "url":
+		decoded, err := url.QueryUnescape(input)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL encoding: %w", err)
+		}
+		return decoded, nil
+	case "json":
+		var decoded string
+		if err := json.Unmarshal([]byte(`"`+input+`"`), &decoded); err != nil {
+			return "", fmt.Errorf("invalid JSON string escape: %w", err)
+		}
+		return decoded, nil
+	case "shell":
+		return "", fmt.Errorf("shell escaping is not reversible")
+	default:
+		return "", fmt.Errorf("unknown escape context: %s", context)
+	}
+}
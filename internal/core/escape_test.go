@@ -0,0 +1,96 @@
+package core
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		context string
+		input   string
+		want    string
+	}{
+		{"html", `<a href="x">Tom & 'Jerry'</a>`, "&lt;a href=&#34;x&#34;&gt;Tom &amp; &#39;Jerry&#39;&lt;/a&gt;"},
+		{"attribute", "a=b c`d\n", "a&#61;b&#32;c&#96;d&#10;"},
+		{"url", "a b&c=d/é", "a+b%26c%3Dd%2F%C3%A9"},
+		{"json", "say \"hi\"\n<script>\u2028", `say \"hi\"\n\u003cscript\u003e\u2028`},
+		{"shell", "it's $HOME", `'it'\''s $HOME'`},
+		{"shell", "", "''"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		got, err := dp.Escape(tt.input, tt.context)
+		if err != nil {
+			t.Fatalf("%s %q: %v", tt.context, tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s %q: got %q, want %q", tt.context, tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := dp.Escape("x", "sql"); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	inputs := []string{"", "plain", `<tag attr="v">&amp; 'q' ` + "`b`=\t\r\n", "ünïcödé 😀 %zz +", "\x00\x1f\\"}
+
+	dp := NewDataProcessor()
+	for _, context := range []string{"html", "attribute", "url", "json"} {
+		for _, input := range inputs {
+			escaped, err := dp.Escape(input, context)
+			if err != nil {
+				t.Fatalf("%s %q: %v", context, input, err)
+			}
+			got, err := dp.Unescape(escaped, context)
+			if err != nil {
+				t.Fatalf("%s %q: %v", context, escaped, err)
+			}
+			if got != input {
+				t.Errorf("%s: %q round trips to %q", context, input, got)
+			}
+		}
+	}
+}
+
+func TestEscapeShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+
+	dp := NewDataProcessor()
+	for _, input := range []string{"it's", "$(rm -rf /) `x` \\ \"q\" *", "a\nb"} {
+		escaped, _ := dp.Escape(input, "shell")
+		out, err := exec.Command(sh, "-c", "printf %s "+escaped).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != input {
+			t.Errorf("shell printed %q for %q", out, input)
+		}
+	}
+}
+
+func TestUnescapeErrors(t *testing.T) {
+	tests := []struct {
+		context string
+		input   string
+	}{
+		{"url", "%zz"},
+		{"json", `\x`},
+		{"json", `"`},
+		{"shell", "'x'"},
+		{"sql", "x"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		if _, err := dp.Unescape(tt.input, tt.context); err == nil {
+			t.Errorf("%s %q: expected an error", tt.context, tt.input)
+		}
+	}
+}
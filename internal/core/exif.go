@@ -0,0 +1,339 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// EXIF tags read by ParseEXIF
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920A
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+	tagLensModel        = 0xA434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// maxIFDEntries bounds the entries read from one directory
+const maxIFDEntries = 1000
+
+// exifTypeSizes is the byte size of each TIFF field type
+var exifTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// exifOrientations describes the orientation tag values
+var exifOrientations = map[int]string{
+	1: "normal",
+	2: "mirrored horizontally",
+	3: "rotated 180°",
+	4: "mirrored vertically",
+	5: "mirrored horizontally and rotated 270° clockwise",
+	6: "rotated 90° clockwise",
+	7: "mirrored horizontally and rotated 90° clockwise",
+	8: "rotated 270° clockwise",
+}
+
+// errNoEXIF reports a JPEG without an EXIF segment
+var errNoEXIF = errors.New("no EXIF data")
+
+// exifField is one raw IFD entry
+type exifField struct {
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+// tiffReader reads IFDs from a TIFF block with bounds checks
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// readIFD returns the entries of the directory at offset keyed by tag
+func (r *tiffReader) readIFD(offset uint32) (map[uint16]exifField, error) {
+	if int64(offset)+2 > int64(len(r.data)) {
+		return nil, fmt.Errorf("IFD offset %d is outside the EXIF data", offset)
+	}
+	n := int(r.order.Uint16(r.data[offset:]))
+	if n > maxIFDEntries {
+		return nil, fmt.Errorf("IFD has %d entries", n)
+	}
+
+	fields := make(map[uint16]exifField, n)
+	for i := 0; i < n; i++ {
+		pos := int(offset) + 2 + i*12
+		if pos+12 > len(r.data) {
+			return nil, fmt.Errorf("IFD entry %d is truncated", i)
+		}
+		entry := r.data[pos : pos+12]
+		tag := r.order.Uint16(entry)
+		typ := r.order.Uint16(entry[2:])
+		count := r.order.Uint32(entry[4:])
+		size, ok := exifTypeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := int64(size) * int64(count)
+		var value []byte
+		if total <= 4 {
+			value = entry[8 : 8+total]
+		} else {
+			start := int64(r.order.Uint32(entry[8:]))
+			if start+total > int64(len(r.data)) {
+				// skip values pointing outside the block rather than failing
+				continue
+			}
+			value = r.data[start : start+total]
+		}
+		fields[tag] = exifField{typ: typ, count: count, data: value}
+	}
+	return fields, nil
+}
+
+func (r *tiffReader) str(f exifField) (string, bool) {
+	if f.typ != 2 {
+		return "", false
+	}
+	s := strings.TrimRight(string(f.data), "\x00 ")
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), s != ""
+}
+
+func (r *tiffReader) uint(f exifField) (uint32, bool) {
+	switch {
+	case f.typ == 3 && len(f.data) >= 2:
+		return uint32(r.order.Uint16(f.data)), true
+	case f.typ == 4 && len(f.data) >= 4:
+		return r.order.Uint32(f.data), true
+	case f.typ == 1 && len(f.data) >= 1:
+		return uint32(f.data[0]), true
+	}
+	return 0, false
+}
+
+// rationals returns unsigned or signed rational values as floats
+func (r *tiffReader) rationals(f exifField) []float64 {
+	if f.typ != 5 && f.typ != 10 {
+		return nil
+	}
+	var out []float64
+	for i := 0; i+8 <= len(f.data); i += 8 {
+		num, den := r.order.Uint32(f.data[i:]), r.order.Uint32(f.data[i+4:])
+		if den == 0 {
+			return nil
+		}
+		if f.typ == 10 {
+			out = append(out, float64(int32(num))/float64(int32(den)))
+		} else {
+			out = append(out, float64(num)/float64(den))
+		}
+	}
+	return out
+}
+
+// findEXIF returns the TIFF block of a JPEG's EXIF APP1 segment
+func findEXIF(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("not a JPEG image")
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, errNoEXIF
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// fill byte
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// start of scan or end of image: metadata segments come first
+			return nil, errNoEXIF
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil, errNoEXIF
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return segment[6:], nil
+		}
+		pos += 2 + length
+	}
+	return nil, errNoEXIF
+}
+
+// parseEXIFDate converts an EXIF "2006:01:02 15:04:05" timestamp to ISO 8601
+// without a zone, since EXIF records local camera time
+func parseEXIFDate(s string) (string, bool) {
+	t, err := time.Parse("2006:01:02 15:04:05", s)
+	if err != nil {
+		return "", false
+	}
+	return t.Format("2006-01-02T15:04:05"), true
+}
+
+// ParseEXIF extracts common EXIF fields from JPEG bytes. A JPEG without
+// EXIF returns an empty map; malformed EXIF returns an error.
+func ParseEXIF(data []byte) (map[string]interface{}, error) {
+	tiff, err := findEXIF(data)
+	if errors.Is(err, errNoEXIF) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(tiff) < 8 {
+		return nil, fmt.Errorf("EXIF header is truncated")
+	}
+
+	r := &tiffReader{data: tiff}
+	switch string(tiff[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("EXIF has an unknown byte order")
+	}
+	if r.order.Uint16(tiff[2:]) != 42 {
+		return nil, fmt.Errorf("EXIF has an invalid TIFF header")
+	}
+
+	ifd0, err := r.readIFD(r.order.Uint32(tiff[4:]))
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]interface{}{}
+
+	for tag, key := range map[uint16]string{tagMake: "make", tagModel: "model", tagSoftware: "software"} {
+		if s, ok := r.str(ifd0[tag]); ok {
+			meta[key] = s
+		}
+	}
+	if v, ok := r.uint(ifd0[tagOrientation]); ok {
+		meta["orientation"] = int(v)
+		if desc, ok := exifOrientations[int(v)]; ok {
+			meta["orientationDescription"] = desc
+		}
+	}
+	if s, ok := r.str(ifd0[tagDateTime]); ok {
+		if iso, ok := parseEXIFDate(s); ok {
+			meta["dateModified"] = iso
+		}
+	}
+
+	if offset, ok := r.uint(ifd0[tagExifIFD]); ok {
+		sub, err := r.readIFD(offset)
+		if err != nil {
+			return nil, fmt.Errorf("EXIF sub-IFD: %w", err)
+		}
+		if s, ok := r.str(sub[tagDateTimeOriginal]); ok {
+			if iso, ok := parseEXIFDate(s); ok {
+				meta["dateTaken"] = iso
+			}
+		}
+		if s, ok := r.str(sub[tagLensModel]); ok {
+			meta["lensModel"] = s
+		}
+		if v := r.rationals(sub[tagExposureTime]); len(v) == 1 {
+			meta["exposureTime"] = v[0]
+		}
+		if v := r.rationals(sub[tagFNumber]); len(v) == 1 {
+			meta["fNumber"] = v[0]
+		}
+		if v := r.rationals(sub[tagFocalLength]); len(v) == 1 {
+			meta["focalLength"] = v[0]
+		}
+		if v, ok := r.uint(sub[tagISO]); ok {
+			meta["iso"] = int(v)
+		}
+		if w, ok := r.uint(sub[tagPixelXDimension]); ok {
+			meta["width"] = int(w)
+		}
+		if h, ok := r.uint(sub[tagPixelYDimension]); ok {
+			meta["height"] = int(h)
+		}
+	}
+
+	if offset, ok := r.uint(ifd0[tagGPSIFD]); ok {
+		gps, err := r.readIFD(offset)
+		if err != nil {
+			return nil, fmt.Errorf("GPS IFD: %w", err)
+		}
+		if location, ok := r.gpsLocation(gps); ok {
+			meta["gps"] = location
+		}
+	}
+	return meta, nil
+}
+
+// gpsLocation converts GPS degree/minute/second rationals to decimal
+// degrees, negative for south and west
+func (r *tiffReader) gpsLocation(gps map[uint16]exifField) (map[string]interface{}, bool) {
+	coord := func(valueTag, refTag uint16, negative string) (float64, bool) {
+		dms := r.rationals(gps[valueTag])
+		if len(dms) != 3 {
+			return 0, false
+		}
+		v := dms[0] + dms[1]/60 + dms[2]/3600
+		if ref, _ := r.str(gps[refTag]); strings.EqualFold(ref, negative) {
+			v = -v
+		}
+		return v, true
+	}
+
+	lat, ok1 := coord(tagGPSLatitude, tagGPSLatitudeRef, "S")
+	lng, ok2 := coord(tagGPSLongitude, tagGPSLongitudeRef, "W")
+	if !ok1 || !ok2 || (LatLng{Lat: lat, Lng: lng}).Validate() != nil {
+		return nil, false
+	}
+	location := map[string]interface{}{
+		"lat": lat,
+		"lng": lng,
+	}
+	if alt := r.rationals(gps[tagGPSAltitude]); len(alt) == 1 && !math.IsInf(alt[0], 0) {
+		// altitude reference 1 means below sea level
+		if f := gps[tagGPSAltitudeRef]; len(f.data) > 0 && f.data[0] == 1 {
+			alt[0] = -alt[0]
+		}
+		location["altitude"] = alt[0]
+	}
+	return location, true
+}
+
+// ExtractEXIF returns the EXIF metadata of a JPEG; images without EXIF give
+// an empty metadata object
+func (dp *DataProcessor) ExtractEXIF(data []byte) (map[string]interface{}, error) {
+	meta, err := ParseEXIF(data)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"hasExif":  len(meta) > 0,
+		"metadata": meta,
+	}, nil
+}
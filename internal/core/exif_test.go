@@ -0,0 +1,203 @@
+package core
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exifOrder is a byte order that can also append
+type exifOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// exifEntry is an IFD entry to encode; value is the raw field data
+type exifEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    []byte
+}
+
+func exifASCII(tag uint16, s string) exifEntry {
+	return exifEntry{tag, 2, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+func exifShort(order exifOrder, tag uint16, v uint16) exifEntry {
+	b := make([]byte, 2)
+	order.PutUint16(b, v)
+	return exifEntry{tag, 3, 1, b}
+}
+
+func exifLong(order exifOrder, tag uint16, v uint32) exifEntry {
+	b := make([]byte, 4)
+	order.PutUint32(b, v)
+	return exifEntry{tag, 4, 1, b}
+}
+
+func exifRationals(order exifOrder, tag uint16, pairs ...uint32) exifEntry {
+	b := make([]byte, 4*len(pairs))
+	for i, v := range pairs {
+		order.PutUint32(b[4*i:], v)
+	}
+	return exifEntry{tag, 5, uint32(len(pairs) / 2), b}
+}
+
+// appendIFD writes a directory at the end of tiff, followed by the values
+// too large to fit in their entries, and returns the result and the
+// directory's offset
+func appendIFD(tiff []byte, order exifOrder, entries []exifEntry) ([]byte, uint32) {
+	offset := uint32(len(tiff))
+	dataAt := offset + 2 + uint32(12*len(entries)) + 4
+	var data []byte
+	tiff = order.AppendUint16(tiff, uint16(len(entries)))
+	for _, e := range entries {
+		tiff = order.AppendUint16(tiff, e.tag)
+		tiff = order.AppendUint16(tiff, e.typ)
+		tiff = order.AppendUint32(tiff, e.count)
+		if len(e.value) <= 4 {
+			tiff = append(tiff, append(e.value, make([]byte, 4-len(e.value))...)...)
+			continue
+		}
+		tiff = order.AppendUint32(tiff, dataAt+uint32(len(data)))
+		data = append(data, e.value...)
+	}
+	tiff = order.AppendUint32(tiff, 0)
+	return append(tiff, data...), offset
+}
+
+// exifJPEG wraps a TIFF block in a JPEG APP1 segment
+func exifJPEG(tiff []byte) []byte {
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00, 0xFF, 0xE1}
+	jpeg = binary.BigEndian.AppendUint16(jpeg, uint16(len(segment)+2))
+	jpeg = append(jpeg, segment...)
+	return append(jpeg, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9)
+}
+
+// testEXIF builds a camera-style TIFF block: the EXIF and GPS directories
+// come first so that IFD0 can point back at them
+func testEXIF(order exifOrder) []byte {
+	tiff := []byte("II")
+	if order == binary.BigEndian {
+		tiff = []byte("MM")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 0)
+
+	tiff, exifIFD := appendIFD(tiff, order, []exifEntry{
+		exifASCII(tagDateTimeOriginal, "2024:05:06 07:08:09"),
+		exifRationals(order, tagExposureTime, 1, 250),
+		exifRationals(order, tagFNumber, 28, 10),
+		exifShort(order, tagISO, 400),
+		exifRationals(order, tagFocalLength, 50, 1),
+		exifLong(order, tagPixelXDimension, 4000),
+		exifShort(order, tagPixelYDimension, 3000),
+		exifASCII(tagLensModel, "50mm f/1.8"),
+	})
+	tiff, gpsIFD := appendIFD(tiff, order, []exifEntry{
+		exifASCII(tagGPSLatitudeRef, "S"),
+		exifRationals(order, tagGPSLatitude, 33, 1, 51, 1, 3240, 100),
+		exifASCII(tagGPSLongitudeRef, "E"),
+		exifRationals(order, tagGPSLongitude, 151, 1, 12, 1, 3600, 100),
+		{tagGPSAltitudeRef, 1, 1, []byte{1}},
+		exifRationals(order, tagGPSAltitude, 58, 1),
+	})
+	tiff, ifd0 := appendIFD(tiff, order, []exifEntry{
+		exifASCII(tagMake, "Canon"),
+		exifASCII(tagModel, "EOS"),
+		exifShort(order, tagOrientation, 6),
+		exifASCII(tagDateTime, "2024:05:07 10:00:00"),
+		exifLong(order, tagExifIFD, exifIFD),
+		exifLong(order, tagGPSIFD, gpsIFD),
+		// values pointing outside the block are skipped
+		{tagSoftware, 2, 100, []byte{0xFF, 0xFF, 0, 0, 0}},
+	})
+	order.PutUint32(tiff[4:], ifd0)
+	return tiff
+}
+
+func TestParseEXIF(t *testing.T) {
+	for _, order := range []exifOrder{binary.LittleEndian, binary.BigEndian} {
+		meta, err := ParseEXIF(exifJPEG(testEXIF(order)))
+		if err != nil {
+			t.Fatalf("%v: %v", order, err)
+		}
+		gps, _ := meta["gps"].(map[string]interface{})
+		delete(meta, "gps")
+
+		want := map[string]interface{}{
+			"make":                   "Canon",
+			"model":                  "EOS",
+			"orientation":            6,
+			"orientationDescription": "rotated 90° clockwise",
+			"dateModified":           "2024-05-07T10:00:00",
+			"dateTaken":              "2024-05-06T07:08:09",
+			"lensModel":              "50mm f/1.8",
+			"exposureTime":           0.004,
+			"fNumber":                2.8,
+			"focalLength":            50.0,
+			"iso":                    400,
+			"width":                  4000,
+			"height":                 3000,
+		}
+		if !reflect.DeepEqual(meta, want) {
+			t.Errorf("%v: metadata = %v, want %v", order, meta, want)
+		}
+		if math.Abs(gps["lat"].(float64)+33.859) > 1e-9 || math.Abs(gps["lng"].(float64)-151.21) > 1e-9 || gps["altitude"] != -58.0 {
+			t.Errorf("%v: gps = %v", order, gps)
+		}
+	}
+}
+
+func TestParseEXIFWithoutEXIF(t *testing.T) {
+	dp := NewDataProcessor()
+	for _, data := range [][]byte{
+		{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9},
+		{0xFF, 0xD8, 0xFF, 0xFF, 0xE0, 0x00, 0x02, 0xFF, 0xD9},
+		{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x08, 'X', 'M', 'P', 0, 0, 0},
+		{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF},
+	} {
+		result, err := dp.ExtractEXIF(data)
+		if err != nil {
+			t.Errorf("% x: %v", data, err)
+			continue
+		}
+		if result["hasExif"] != false || len(result["metadata"].(map[string]interface{})) != 0 {
+			t.Errorf("% x: result = %v", data, result)
+		}
+	}
+}
+
+func TestParseEXIFErrors(t *testing.T) {
+	valid := testEXIF(binary.LittleEndian)
+	badOrder := append([]byte("XX"), valid[2:]...)
+	badMagic := append([]byte{}, valid...)
+	badMagic[2] = 0
+	badOffset := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(badOffset[4:], 1<<30)
+	truncated := valid[:binary.LittleEndian.Uint32(valid[4:])+2+12*3]
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n"), "not a JPEG"},
+		{"short header", exifJPEG(valid[:6]), "truncated"},
+		{"byte order", exifJPEG(badOrder), "unknown byte order"},
+		{"magic number", exifJPEG(badMagic), "invalid TIFF header"},
+		{"IFD offset", exifJPEG(badOffset), "outside the EXIF data"},
+		{"truncated IFD", exifJPEG(truncated), "truncated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEXIF(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxFakeCount bounds how many items GenerateFake returns in one call
+const MaxFakeCount = 1000
+
+// FakeOptions controls GenerateFake
+type FakeOptions struct {
+	// Count is the number of items to generate (default 1)
+	Count int
+	// Seed makes the output reproducible; zero picks a time-based seed
+	Seed int64
+	// Spec maps field names to field types for the "objects" kind
+	Spec map[string]string
+}
+
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing
+	elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad
+	minim veniam quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo
+	consequat duis aute irure in reprehenderit voluptate velit esse cillum fugiat
+	nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui
+	officia deserunt mollit anim id est laborum`)
+
+var fakeFirstNames = []string{
+	"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken",
+	"Frances", "Edsger", "Radia", "Donald", "Hedy", "John", "Katherine", "Tim",
+}
+
+var fakeLastNames = []string{
+	"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov",
+	"Thompson", "Allen", "Dijkstra", "Perlman", "Knuth", "Lamarr", "McCarthy",
+	"Johnson", "Berners-Lee",
+}
+
+var fakeDomains = []string{"example.com", "example.org", "example.net", "test.dev"}
+
+// FakeFieldTypes lists the field types accepted in a FakeOptions.Spec
+var FakeFieldTypes = []string{
+	"name", "firstName", "lastName", "email", "word", "sentence", "paragraph",
+	"int", "float", "bool", "date", "uuid",
+}
+
+// fakeGenerator produces fake values from a seeded random source
+type fakeGenerator struct {
+	rng *rand.Rand
+}
+
+func (g *fakeGenerator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+func (g *fakeGenerator) word() string {
+	return g.pick(loremWords)
+}
+
+func (g *fakeGenerator) sentence() string {
+	n := 6 + g.rng.Intn(10)
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.word()
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ") + "."
+}
+
+func (g *fakeGenerator) paragraph() string {
+	n := 3 + g.rng.Intn(4)
+	sentences := make([]string, n)
+	for i := range sentences {
+		sentences[i] = g.sentence()
+	}
+	return strings.Join(sentences, " ")
+}
+
+func (g *fakeGenerator) name() (first, last string) {
+	return g.pick(fakeFirstNames), g.pick(fakeLastNames)
+}
+
+func (g *fakeGenerator) email() string {
+	first, last := g.name()
+	local := strings.ToLower(first + "." + strings.ReplaceAll(last, "-", ""))
+	return fmt.Sprintf("%s%d@%s", local, g.rng.Intn(100), g.pick(fakeDomains))
+}
+
+func (g *fakeGenerator) uuid() string {
+	b := make([]byte, 16)
+	g.rng.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (g *fakeGenerator) field(fieldType string) (interface{}, error) {
+	switch fieldType {
+	case "name":
+		first, last := g.name()
+		return first + " " + last, nil
+	case "firstName":
+		return g.pick(fakeFirstNames), nil
+	case "lastName":
+		return g.pick(fakeLastNames), nil
+	case "email":
+		return g.email(), nil
+	case "word":
+		return g.word(), nil
+	case "sentence":
+		return g.sentence(), nil
+	case "paragraph":
+		return g.paragraph(), nil
+	case "int":
+		return g.rng.Intn(1000), nil
+	case "float":
+		return float64(g.rng.Intn(100000)) / 100, nil
+	case "bool":
+		return g.rng.Intn(2) == 1, nil
+	case "date":
+		base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		return base.AddDate(0, 0, g.rng.Intn(5*365)).Format("2006-01-02"), nil
+	case "uuid":
+		return g.uuid(), nil
+	default:
+		return nil, fmt.Errorf("unknown field type %q (expected one of %s)", fieldType, strings.Join(FakeFieldTypes, ", "))
+	}
+}
+
+// GenerateFake produces count items of the given kind: words, sentences,
+// paragraphs, names, emails, or objects built from opts.Spec. The same seed
+// always yields the same items.
+func (dp *DataProcessor) GenerateFake(kind string, opts FakeOptions) (map[string]interface{}, error) {
+	count := opts.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 || count > MaxFakeCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxFakeCount)
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		// Keep within 2^53 so the seed survives a round trip through JS
+		seed = time.Now().UnixNano() & (1<<53 - 1)
+	}
+	g := &fakeGenerator{rng: rand.New(rand.NewSource(seed))}
+
+	// Generate object fields in a fixed order so seeds are reproducible
+	var fields []string
+	if kind == "objects" {
+		if len(opts.Spec) == 0 {
+			return nil, fmt.Errorf("objects require a field spec")
+		}
+		for field := range opts.Spec {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+
+	items := make([]interface{}, count)
+	for i := range items {
+		switch kind {
+		case "words":
+			items[i] = g.word()
+		case "sentences":
+			items[i] = g.sentence()
+		case "paragraphs":
+			items[i] = g.paragraph()
+		case "names":
+			items[i], _ = g.field("name")
+		case "emails":
+			items[i] = g.email()
+		case "objects":
+			obj := make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				value, err := g.field(opts.Spec[field])
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", field, err)
+				}
+				obj[field] = value
+			}
+			items[i] = obj
+		default:
+			return nil, fmt.Errorf("unknown kind %q (expected words, sentences, paragraphs, names, emails or objects)", kind)
+		}
+	}
+
+	return map[string]interface{}{
+		"kind":  kind,
+		"count": count,
+		"seed":  seed,
+		"items": items,
+	}, nil
+}
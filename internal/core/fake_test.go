@@ -0,0 +1,114 @@
+package core
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateFakeSeeded(t *testing.T) {
+	dp := NewDataProcessor()
+	spec := map[string]string{"id": "uuid", "name": "name", "joined": "date"}
+
+	for _, kind := range []string{"words", "sentences", "paragraphs", "names", "emails", "objects"} {
+		opts := FakeOptions{Count: 5, Seed: 42, Spec: spec}
+		a, err := dp.GenerateFake(kind, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		b, _ := dp.GenerateFake(kind, opts)
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: the same seed gave different items", kind)
+		}
+		c, _ := dp.GenerateFake(kind, FakeOptions{Count: 5, Seed: 43, Spec: spec})
+		if reflect.DeepEqual(a["items"], c["items"]) {
+			t.Errorf("%s: different seeds gave the same items", kind)
+		}
+		if len(a["items"].([]interface{})) != 5 || a["count"] != 5 || a["seed"] != int64(42) {
+			t.Errorf("%s: got %v", kind, a)
+		}
+	}
+}
+
+func TestGenerateFakeFields(t *testing.T) {
+	formats := map[string]*regexp.Regexp{
+		"name":      regexp.MustCompile(`^[A-Z][a-z]+ [A-Z][A-Za-z-]+$`),
+		"firstName": regexp.MustCompile(`^[A-Z][a-z]+$`),
+		"lastName":  regexp.MustCompile(`^[A-Z][A-Za-z-]+$`),
+		"email":     regexp.MustCompile(`^[a-z]+\.[a-z]+\d{1,2}@(example\.(com|org|net)|test\.dev)$`),
+		"word":      regexp.MustCompile(`^[a-z]+$`),
+		"sentence":  regexp.MustCompile(`^[A-Z][a-z]*( [a-z]+){5,14}\.$`),
+		"paragraph": regexp.MustCompile(`^[A-Z].*\.$`),
+		"uuid":      regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+	}
+
+	spec := make(map[string]string)
+	for _, fieldType := range FakeFieldTypes {
+		spec[fieldType] = fieldType
+	}
+
+	dp := NewDataProcessor()
+	result, err := dp.GenerateFake("objects", FakeOptions{Count: 50, Seed: 7, Spec: spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range result["items"].([]interface{}) {
+		obj := item.(map[string]interface{})
+		for field, re := range formats {
+			if s, _ := obj[field].(string); !re.MatchString(s) {
+				t.Errorf("%s = %q", field, obj[field])
+			}
+		}
+		if n, ok := obj["int"].(int); !ok || n < 0 || n >= 1000 {
+			t.Errorf("int = %v", obj["int"])
+		}
+		if f, ok := obj["float"].(float64); !ok || f < 0 || f >= 1000 {
+			t.Errorf("float = %v", obj["float"])
+		}
+		if _, ok := obj["bool"].(bool); !ok {
+			t.Errorf("bool = %v", obj["bool"])
+		}
+		date, err := time.Parse("2006-01-02", obj["date"].(string))
+		if err != nil || date.Year() < 2020 || date.Year() > 2024 {
+			t.Errorf("date = %v", obj["date"])
+		}
+	}
+}
+
+func TestGenerateFakeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		opts FakeOptions
+		err  string
+	}{
+		{name: "unknown kind", kind: "cars", err: "unknown kind"},
+		{name: "negative count", kind: "words", opts: FakeOptions{Count: -1}, err: "count must be"},
+		{name: "count too large", kind: "words", opts: FakeOptions{Count: MaxFakeCount + 1}, err: "count must be"},
+		{name: "objects without spec", kind: "objects", err: "field spec"},
+		{name: "unknown field type", kind: "objects", opts: FakeOptions{Spec: map[string]string{"x": "color"}}, err: "field x: unknown field type"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		_, err := dp.GenerateFake(tt.kind, tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %v does not mention %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestGenerateFakeDefaults(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GenerateFake("words", FakeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := result["seed"].(int64)
+	if result["count"] != 1 || seed == 0 || seed >= 1<<53 {
+		t.Errorf("got count %v seed %d", result["count"], seed)
+	}
+}
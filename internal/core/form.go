@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// FieldRule describes the constraints for a single form field. A negative
+// length disables that bound.
+type FieldRule struct {
+	Required  bool
+	Type      string
+	MinLength int
+	MaxLength int
+	Pattern   string
+}
+
+// ParseFieldRule reads a rule object with optional required, type,
+// minLength, maxLength and pattern properties
+func ParseFieldRule(raw interface{}) (FieldRule, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return FieldRule{}, fmt.Errorf("rule must be an object")
+	}
+
+	rule := FieldRule{MinLength: -1, MaxLength: -1}
+	if v, ok := obj["required"].(bool); ok {
+		rule.Required = v
+	}
+	if v, ok := obj["type"].(string); ok {
+		rule.Type = v
+	}
+	if v, ok := obj["minLength"].(float64); ok {
+		rule.MinLength = int(v)
+	}
+	if v, ok := obj["maxLength"].(float64); ok {
+		rule.MaxLength = int(v)
+	}
+	if v, ok := obj["pattern"].(string); ok {
+		rule.Pattern = v
+	}
+	return rule, nil
+}
+
+// ValidateField returns the list of rule violations for a value. Types
+// other than string, number, boolean and any fall back to ValidateByType.
+func ValidateField(value interface{}, rule FieldRule) []string {
+	var messages []string
+
+	str, isString := value.(string)
+	if value == nil || (isString && str == "") {
+		if rule.Required {
+			messages = append(messages, "Field is required")
+		}
+		return messages
+	}
+
+	switch rule.Type {
+	case "", "any":
+	case "string":
+		if !isString {
+			messages = append(messages, "Must be a string")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			messages = append(messages, "Must be a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			messages = append(messages, "Must be a boolean")
+		}
+	default:
+		// Fall back to the built-in validators (email, url, phone, json)
+		if !isString {
+			messages = append(messages, fmt.Sprintf("Must be a %s string", rule.Type))
+		} else if valid, message := ValidateByType(str, rule.Type); !valid {
+			messages = append(messages, message)
+		}
+	}
+
+	if isString {
+		length := len([]rune(str))
+		if rule.MinLength >= 0 && length < rule.MinLength {
+			messages = append(messages, fmt.Sprintf("Must be at least %d characters", rule.MinLength))
+		}
+		if rule.MaxLength >= 0 && length > rule.MaxLength {
+			messages = append(messages, fmt.Sprintf("Must be at most %d characters", rule.MaxLength))
+		}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("Invalid pattern: %v", err))
+			} else if !re.MatchString(str) {
+				messages = append(messages, "Does not match the required pattern")
+			}
+		}
+	}
+
+	return messages
+}
+
+// ValidateForm validates an object of field values against a schema of
+// per-field rules, reporting each field's violations
+func (dp *DataProcessor) ValidateForm(values, schema map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(schema))
+	errorCount := 0
+
+	for name, rawRule := range schema {
+		rule, err := ParseFieldRule(rawRule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule for %s: %w", name, err)
+		}
+
+		messages := ValidateField(values[name], rule)
+		if len(messages) > 0 {
+			errorCount++
+		}
+
+		msgs := make([]interface{}, len(messages))
+		for i, m := range messages {
+			msgs[i] = m
+		}
+		fields[name] = map[string]interface{}{
+			"valid":    len(messages) == 0,
+			"messages": msgs,
+		}
+	}
+
+	return map[string]interface{}{
+		"valid":      errorCount == 0,
+		"fields":     fields,
+		"errorCount": errorCount,
+	}, nil
+}
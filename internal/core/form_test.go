@@ -0,0 +1,79 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateField(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		rule  map[string]interface{}
+		want  []string
+	}{
+		{name: "optional empty", value: "", rule: map[string]interface{}{}},
+		{name: "required missing", value: nil, rule: map[string]interface{}{"required": true}, want: []string{"Field is required"}},
+		{name: "required empty string", value: "", rule: map[string]interface{}{"required": true}, want: []string{"Field is required"}},
+		{name: "string", value: "ann", rule: map[string]interface{}{"type": "string"}},
+		{name: "not a string", value: 3.0, rule: map[string]interface{}{"type": "string"}, want: []string{"Must be a string"}},
+		{name: "number", value: 3.0, rule: map[string]interface{}{"type": "number"}},
+		{name: "not a number", value: "3", rule: map[string]interface{}{"type": "number"}, want: []string{"Must be a number"}},
+		{name: "not a boolean", value: "yes", rule: map[string]interface{}{"type": "boolean"}, want: []string{"Must be a boolean"}},
+		{name: "email", value: "ann@example.com", rule: map[string]interface{}{"type": "email"}},
+		{name: "bad email", value: "ann", rule: map[string]interface{}{"type": "email"}, want: []string{"Invalid email format"}},
+		{name: "email not a string", value: 1.0, rule: map[string]interface{}{"type": "email"}, want: []string{"Must be a email string"}},
+		{name: "too short", value: "ab", rule: map[string]interface{}{"minLength": 3.0}, want: []string{"Must be at least 3 characters"}},
+		{name: "length counts characters", value: "héllo", rule: map[string]interface{}{"maxLength": 5.0}},
+		{name: "too long", value: "abcdef", rule: map[string]interface{}{"maxLength": 5.0}, want: []string{"Must be at most 5 characters"}},
+		{name: "pattern", value: "A12", rule: map[string]interface{}{"pattern": `^[A-Z]\d+$`}},
+		{name: "pattern mismatch", value: "12", rule: map[string]interface{}{"pattern": `^[A-Z]\d+$`}, want: []string{"Does not match the required pattern"}},
+		{
+			name:  "several violations",
+			value: "a",
+			rule:  map[string]interface{}{"minLength": 2.0, "pattern": `\d`},
+			want:  []string{"Must be at least 2 characters", "Does not match the required pattern"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseFieldRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ValidateField(tt.value, rule); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateForm(t *testing.T) {
+	dp := NewDataProcessor()
+	values := map[string]interface{}{"name": "Ann", "age": "x"}
+	schema := map[string]interface{}{
+		"name":  map[string]interface{}{"required": true, "type": "string"},
+		"age":   map[string]interface{}{"type": "number"},
+		"email": map[string]interface{}{"required": true},
+	}
+
+	result, err := dp.ValidateForm(values, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["valid"] != false || result["errorCount"] != 2 {
+		t.Fatalf("valid = %v, errorCount = %v", result["valid"], result["errorCount"])
+	}
+	fields := result["fields"].(map[string]interface{})
+	if fields["name"].(map[string]interface{})["valid"] != true {
+		t.Error("name should be valid")
+	}
+	if fields["email"].(map[string]interface{})["valid"] != false {
+		t.Error("email should be invalid")
+	}
+
+	if _, err := dp.ValidateForm(values, map[string]interface{}{"name": "required"}); err == nil {
+		t.Error("expected an error for a rule that is not an object")
+	}
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// FormatDuration renders a duration for people, e.g. "1h 23m 4s". Durations
+// under a second are shown in a single unit ("250ms", "850µs", "42ns") and
+// longer ones drop sub-second precision and zero-valued parts.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		// -math.MinInt64 overflows; a nanosecond less reads the same
+		if d == math.MinInt64 {
+			d++
+		}
+		return "-" + FormatDuration(-d)
+	}
+
+	switch {
+	case d == 0:
+		return "0s"
+	case d < time.Microsecond:
+		return fmt.Sprintf("%dns", d.Nanoseconds())
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+
+	units := []struct {
+		size   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	var parts []string
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+			d -= n * u.size
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatBytes renders a byte count with one decimal, e.g. "1.2 MB". IEC
+// units (KiB, MiB, ...) use powers of 1024, SI units (kB, MB, ...) powers of
+// 1000.
+func FormatBytes(n int64, iec bool) string {
+	if n < 0 {
+		if n == math.MinInt64 {
+			n++
+		}
+		return "-" + FormatBytes(-n, iec)
+	}
+
+	unit, prefixes, suffix := int64(1000), "kMGTPE", "B"
+	if iec {
+		unit, prefixes, suffix = 1024, "KMGTPE", "iB"
+	}
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := unit, 0
+	for m := n / unit; m >= unit && exp < len(prefixes)-1; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %c%s", float64(n)/float64(div), prefixes[exp], suffix)
+}
+
+// HumanizeDuration formats a duration given in the unit "ms" (default),
+// "us", "ns" or "s"
+func (dp *DataProcessor) HumanizeDuration(value float64, unit string) (map[string]interface{}, error) {
+	scale := map[string]time.Duration{
+		"":   time.Millisecond,
+		"ms": time.Millisecond,
+		"us": time.Microsecond,
+		"µs": time.Microsecond,
+		"ns": time.Nanosecond,
+		"s":  time.Second,
+	}[unit]
+	if scale == 0 {
+		return nil, fmt.Errorf("unknown duration unit: %s (expected ms, us, ns or s)", unit)
+	}
+	// As a float64, MaxInt64 rounds up to 2^63, which is already too large
+	if math.IsNaN(value) || math.Abs(value*float64(scale)) >= math.MaxInt64 {
+		return nil, fmt.Errorf("duration out of range")
+	}
+
+	d := time.Duration(value * float64(scale))
+	return map[string]interface{}{
+		"formatted":    FormatDuration(d),
+		"nanoseconds":  float64(d.Nanoseconds()),
+		"milliseconds": float64(d) / float64(time.Millisecond),
+	}, nil
+}
+
+// HumanizeBytes formats a byte count using SI or IEC units
+func (dp *DataProcessor) HumanizeBytes(value float64, iec bool) (map[string]interface{}, error) {
+	if math.IsNaN(value) || math.Abs(value) >= math.MaxInt64 {
+		return nil, fmt.Errorf("byte count out of range")
+	}
+
+	standard := "si"
+	if iec {
+		standard = "iec"
+	}
+	return map[string]interface{}{
+		"formatted": FormatBytes(int64(value), iec),
+		"bytes":     int64(value),
+		"standard":  standard,
+	}, nil
+}
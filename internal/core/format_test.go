@@ -0,0 +1,127 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{42, "42ns"},
+		{850 * time.Microsecond, "850µs"},
+		{250*time.Millisecond + 999*time.Microsecond, "250ms"},
+		{time.Second, "1s"},
+		{time.Hour + 23*time.Minute + 4*time.Second + 500*time.Millisecond, "1h 23m 4s"},
+		{49*time.Hour + 5*time.Second, "2d 1h 5s"},
+		{-90 * time.Second, "-1m 30s"},
+		{-5 * time.Millisecond, "-5ms"},
+		{math.MaxInt64, "106751d 23h 47m 16s"},
+		{math.MinInt64, "-106751d 23h 47m 16s"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%d) = %q, want %q", int64(tt.d), got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		iec  bool
+		want string
+	}{
+		{0, false, "0 B"},
+		{999, false, "999 B"},
+		{1000, false, "1.0 kB"},
+		{1000, true, "1000 B"},
+		{1024, true, "1.0 KiB"},
+		{1536, true, "1.5 KiB"},
+		{1_234_567, false, "1.2 MB"},
+		{5 << 30, true, "5.0 GiB"},
+		{-2048, true, "-2.0 KiB"},
+		{math.MaxInt64, false, "9.2 EB"},
+		{math.MaxInt64, true, "8.0 EiB"},
+		{math.MinInt64, true, "-8.0 EiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n, tt.iec); got != tt.want {
+			t.Errorf("FormatBytes(%d, %v) = %q, want %q", tt.n, tt.iec, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		value     float64
+		unit      string
+		formatted string
+		ms        float64
+	}{
+		{1500, "", "1s", 1500},
+		{1500, "ms", "1s", 1500},
+		{90, "s", "1m 30s", 90000},
+		{2500, "us", "2ms", 2.5},
+		{2500, "µs", "2ms", 2.5},
+		{-7, "ns", "-7ns", -0.000007},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.HumanizeDuration(tt.value, tt.unit)
+		if err != nil {
+			t.Fatalf("%v %s: %v", tt.value, tt.unit, err)
+		}
+		if result["formatted"] != tt.formatted || result["milliseconds"] != tt.ms {
+			t.Errorf("%v %s: got %v", tt.value, tt.unit, result)
+		}
+	}
+
+	for _, tt := range []struct {
+		value float64
+		unit  string
+		err   string
+	}{
+		{1, "weeks", "unknown duration unit"},
+		{math.NaN(), "ms", "out of range"},
+		{math.Inf(1), "ms", "out of range"},
+		{1 << 63, "ns", "out of range"},
+		{-(1 << 63), "ns", "out of range"},
+		{1e10, "s", "out of range"},
+	} {
+		_, err := dp.HumanizeDuration(tt.value, tt.unit)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v %s: error %v does not mention %q", tt.value, tt.unit, err, tt.err)
+		}
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.HumanizeBytes(1536.9, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["formatted"] != "1.5 KiB" || result["bytes"] != int64(1536) || result["standard"] != "iec" {
+		t.Errorf("got %v", result)
+	}
+
+	result, _ = dp.HumanizeBytes(2e6, false)
+	if result["formatted"] != "2.0 MB" || result["standard"] != "si" {
+		t.Errorf("got %v", result)
+	}
+
+	for _, value := range []float64{math.NaN(), math.Inf(-1), 1 << 63, -(1 << 63)} {
+		if _, err := dp.HumanizeBytes(value, false); err == nil {
+			t.Errorf("%v: expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+)
+
+// maxMultipartParts bounds the number of parts read from one body
+const maxMultipartParts = 1000
+
+// multipartBoundary extracts the boundary from a Content-Type header, or
+// returns the argument itself when it is a bare boundary
+func multipartBoundary(contentType string) (string, error) {
+	contentType = strings.TrimSpace(contentType)
+	if contentType == "" {
+		return "", fmt.Errorf("boundary or multipart Content-Type required")
+	}
+	if !strings.Contains(contentType, ";") && !strings.HasPrefix(strings.ToLower(contentType), "multipart/") {
+		return strings.Trim(contentType, `"`), nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid Content-Type: %w", err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return "", fmt.Errorf("Content-Type %s is not multipart", mediaType)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return "", fmt.Errorf("Content-Type has no boundary parameter")
+	}
+	return boundary, nil
+}
+
+// ParseMultipart parses a multipart/form-data body. Text parts are
+// returned as fields (repeated names become arrays) and parts with a
+// filename as file metadata; includeFiles adds each file's content as
+// base64.
+func (dp *DataProcessor) ParseMultipart(body, contentType string, includeFiles bool) (map[string]interface{}, error) {
+	boundary, err := multipartBoundary(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(body, "--"+boundary) {
+		return nil, fmt.Errorf("body does not contain the boundary %q", boundary)
+	}
+
+	reader := multipart.NewReader(strings.NewReader(body), boundary)
+	fields := url.Values{}
+	files := []interface{}{}
+	parts := 0
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed multipart body after %d parts: %w", parts, err)
+		}
+		if parts++; parts > maxMultipartParts {
+			return nil, fmt.Errorf("body has more than %d parts", maxMultipartParts)
+		}
+
+		var content bytes.Buffer
+		if _, err := io.Copy(&content, part); err != nil {
+			return nil, fmt.Errorf("malformed multipart body in part %d: %w", parts, err)
+		}
+		name := part.FormName()
+		if name == "" {
+			return nil, fmt.Errorf("part %d has no form-data name", parts)
+		}
+
+		if part.FileName() == "" {
+			fields.Add(name, content.String())
+			continue
+		}
+		file := map[string]interface{}{
+			"field":       name,
+			"filename":    part.FileName(),
+			"contentType": part.Header.Get("Content-Type"),
+			"size":        content.Len(),
+		}
+		if includeFiles {
+			file["content"] = base64.StdEncoding.EncodeToString(content.Bytes())
+		}
+		files = append(files, file)
+	}
+	if parts == 0 {
+		return nil, fmt.Errorf("multipart body has no parts")
+	}
+
+	return map[string]interface{}{
+		"fields":   queryToMap(fields),
+		"files":    files,
+		"parts":    parts,
+		"boundary": boundary,
+	}, nil
+}
+
+// ParseFormURLEncoded parses an application/x-www-form-urlencoded body.
+// When contentType is given it must be that media type.
+func (dp *DataProcessor) ParseFormURLEncoded(body, contentType string) (map[string]interface{}, error) {
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Content-Type: %w", err)
+		}
+		if mediaType != "application/x-www-form-urlencoded" {
+			return nil, fmt.Errorf("Content-Type %s is not application/x-www-form-urlencoded", mediaType)
+		}
+	}
+
+	values, err := url.ParseQuery(strings.TrimSpace(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form body: %w", err)
+	}
+
+	return map[string]interface{}{
+		"fields": queryToMap(values),
+		"count":  len(values),
+	}, nil
+}
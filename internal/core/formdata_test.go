@@ -0,0 +1,103 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMultipartBody = "--XyZ\r\n" +
+	"Content-Disposition: form-data; name=\"title\"\r\n\r\n" +
+	"Hello\r\n" +
+	"--XyZ\r\n" +
+	"Content-Disposition: form-data; name=\"tag\"\r\n\r\n" +
+	"a\r\n" +
+	"--XyZ\r\n" +
+	"Content-Disposition: form-data; name=\"tag\"\r\n\r\n" +
+	"b\r\n" +
+	"--XyZ\r\n" +
+	"Content-Disposition: form-data; name=\"upload\"; filename=\"notes.txt\"\r\n" +
+	"Content-Type: text/plain\r\n\r\n" +
+	"hi there\r\n" +
+	"--XyZ--\r\n"
+
+func TestParseMultipart(t *testing.T) {
+	dp := NewDataProcessor()
+	for _, contentType := range []string{"multipart/form-data; boundary=XyZ", `multipart/form-data; boundary="XyZ"`, "XyZ", `"XyZ"`} {
+		result, err := dp.ParseMultipart(testMultipartBody, contentType, true)
+		if err != nil {
+			t.Errorf("%s: %v", contentType, err)
+			continue
+		}
+		wantFields := map[string]interface{}{"title": "Hello", "tag": []interface{}{"a", "b"}}
+		if !reflect.DeepEqual(result["fields"], wantFields) {
+			t.Errorf("%s: fields = %v, want %v", contentType, result["fields"], wantFields)
+		}
+		wantFiles := []interface{}{map[string]interface{}{
+			"field":       "upload",
+			"filename":    "notes.txt",
+			"contentType": "text/plain",
+			"size":        8,
+			"content":     "aGkgdGhlcmU=",
+		}}
+		if !reflect.DeepEqual(result["files"], wantFiles) {
+			t.Errorf("%s: files = %v, want %v", contentType, result["files"], wantFiles)
+		}
+		if result["parts"] != 4 || result["boundary"] != "XyZ" {
+			t.Errorf("%s: result = %v", contentType, result)
+		}
+	}
+
+	// file content is only included on request
+	result, err := dp.ParseMultipart(testMultipartBody, "XyZ", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file := result["files"].([]interface{})[0].(map[string]interface{}); file["content"] != nil {
+		t.Errorf("content = %v, want none", file["content"])
+	}
+}
+
+func TestParseMultipartErrors(t *testing.T) {
+	tests := []struct {
+		name, body, contentType, err string
+	}{
+		{"no boundary", testMultipartBody, "", "boundary or multipart Content-Type required"},
+		{"not multipart", testMultipartBody, "text/plain; charset=utf-8", "is not multipart"},
+		{"missing parameter", testMultipartBody, "multipart/form-data", "no boundary parameter"},
+		{"wrong boundary", testMultipartBody, "other", "does not contain the boundary"},
+		{"no parts", "--XyZ--\r\n", "XyZ", "has no parts"},
+		{"unnamed part", "--XyZ\r\nContent-Type: text/plain\r\n\r\nx\r\n--XyZ--\r\n", "XyZ", "no form-data name"},
+		{"truncated", "--XyZ\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nx", "XyZ", "malformed"},
+		{"too many parts", strings.Repeat("--XyZ\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nx\r\n", maxMultipartParts+1) + "--XyZ--\r\n", "XyZ", "more than"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dp.ParseMultipart(tt.body, tt.contentType, false)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseFormURLEncoded(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.ParseFormURLEncoded("name=Ada+Lovelace&tag=a&tag=b&note=50%25\n", "application/x-www-form-urlencoded; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"name": "Ada Lovelace", "tag": []interface{}{"a", "b"}, "note": "50%"}
+	if !reflect.DeepEqual(result["fields"], want) || result["count"] != 3 {
+		t.Errorf("result = %v, want fields %v", result, want)
+	}
+
+	if _, err := dp.ParseFormURLEncoded("a=1", "application/json"); err == nil {
+		t.Error("expected an error for the wrong Content-Type")
+	}
+	if _, err := dp.ParseFormURLEncoded("a=%zz", ""); err == nil {
+		t.Error("expected an error for a bad escape")
+	}
+}
@@ -0,0 +1,869 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxFormulaDepth bounds expression nesting and chains of cell references
+const maxFormulaDepth = 256
+
+// formulaTokenKind classifies a formula token
+type formulaTokenKind int
+
+const (
+	tokEOF formulaTokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type formulaToken struct {
+	kind formulaTokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// formulaOperators lists the operators, longest first so that <= wins over <
+var formulaOperators = []string{"<=", ">=", "<>", "!=", "==", "&&", "||", "+", "-", "*", "/", "%", "^", "<", ">", "=", "!", "(", ")", ",", ":"}
+
+// tokenizeFormula splits a formula into numbers, double-quoted strings,
+// identifiers and operators
+func tokenizeFormula(src string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && src[j] >= '0' && src[j] <= '9' {
+					for i = j; i < len(src) && src[i] >= '0' && src[i] <= '9'; i++ {
+					}
+				}
+			}
+			n, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", src[start:i], start)
+			}
+			tokens = append(tokens, formulaToken{kind: tokNumber, text: src[start:i], num: n, pos: start})
+
+		case c == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if src[i] == '"' {
+					// a doubled quote is an escaped quote, as in spreadsheets
+					if i+1 < len(src) && src[i+1] == '"' {
+						sb.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: tokString, text: sb.String(), pos: start})
+
+		case c == '_' || c == '$' || c < 0x80 && unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '$' || src[i] == '.' ||
+				src[i] < 0x80 && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])))) {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: tokIdent, text: src[start:i], pos: start})
+
+		default:
+			matched := false
+			for _, op := range formulaOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, formulaToken{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, formulaToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+// formulaNode is a parsed formula expression
+type formulaNode interface{}
+
+type (
+	literalNode struct{ value interface{} }
+	refNode     struct{ name string }
+	rangeNode   struct{ from, to string }
+	unaryNode   struct {
+		op      string
+		operand formulaNode
+	}
+	binaryNode struct {
+		op          string
+		left, right formulaNode
+	}
+	callNode struct {
+		name string
+		args []formulaNode
+	}
+)
+
+// Binding powers, lowest first. ^ is right-associative.
+const (
+	bpOr = iota + 1
+	bpAnd
+	bpNot
+	bpCompare
+	bpAdd
+	bpMul
+	bpUnary
+	bpPow
+)
+
+// infixPower returns the binding power of a binary operator, or 0 if the
+// token is not one
+func infixPower(t formulaToken) int {
+	switch {
+	case t.kind == tokOp:
+		switch t.text {
+		case "||":
+			return bpOr
+		case "&&":
+			return bpAnd
+		case "=", "==", "<>", "!=", "<", "<=", ">", ">=":
+			return bpCompare
+		case "+", "-":
+			return bpAdd
+		case "*", "/", "%":
+			return bpMul
+		case "^":
+			return bpPow
+		}
+	case t.kind == tokIdent:
+		switch strings.ToLower(t.text) {
+		case "or":
+			return bpOr
+		case "and":
+			return bpAnd
+		}
+	}
+	return 0
+}
+
+// formulaParser is a Pratt parser over formula tokens
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+	depth  int
+}
+
+func (p *formulaParser) peek() formulaToken { return p.tokens[p.pos] }
+
+func (p *formulaParser) next() formulaToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *formulaParser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return fmt.Errorf("expected %q at position %d", op, t.pos)
+	}
+	return nil
+}
+
+// parseFormula parses a complete formula; a leading = is optional
+func parseFormula(src string) (formulaNode, error) {
+	src = strings.TrimPrefix(strings.TrimSpace(src), "=")
+	tokens, err := tokenizeFormula(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &formulaParser{tokens: tokens}
+	node, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return node, nil
+}
+
+// parseExpr parses operators binding tighter than minPower
+func (p *formulaParser) parseExpr(minPower int) (formulaNode, error) {
+	if p.depth++; p.depth > maxFormulaDepth {
+		return nil, fmt.Errorf("formula is nested deeper than %d levels", maxFormulaDepth)
+	}
+	defer func() { p.depth-- }()
+
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		power := infixPower(t)
+		if power == 0 || power <= minPower {
+			return left, nil
+		}
+		p.next()
+		// ^ is right-associative, so its right side may contain another ^
+		rightMin := power
+		if power == bpPow {
+			rightMin = power - 1
+		}
+		right, err := p.parseExpr(rightMin)
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if t.kind == tokIdent {
+			op = strings.ToLower(op)
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parsePrefix parses a literal, reference, call, group or prefix operator
+func (p *formulaParser) parsePrefix() (formulaNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return literalNode{t.num}, nil
+	case tokString:
+		return literalNode{t.text}, nil
+
+	case tokOp:
+		switch t.text {
+		case "(":
+			node, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "-", "+":
+			operand, err := p.parseExpr(bpUnary)
+			if err != nil {
+				return nil, err
+			}
+			return unaryNode{op: t.text, operand: operand}, nil
+		case "!":
+			operand, err := p.parseExpr(bpNot)
+			if err != nil {
+				return nil, err
+			}
+			return unaryNode{op: "not", operand: operand}, nil
+		}
+
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "not":
+			operand, err := p.parseExpr(bpNot)
+			if err != nil {
+				return nil, err
+			}
+			return unaryNode{op: "not", operand: operand}, nil
+		}
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			return p.parseCall(t)
+		}
+		if next := p.peek(); next.kind == tokOp && next.text == ":" {
+			p.next()
+			end := p.next()
+			if end.kind != tokIdent {
+				return nil, fmt.Errorf("expected a cell after ':' at position %d", end.pos)
+			}
+			return rangeNode{from: t.text, to: end.text}, nil
+		}
+		return refNode{t.text}, nil
+
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// parseCall parses a function's parenthesised argument list
+func (p *formulaParser) parseCall(name formulaToken) (formulaNode, error) {
+	p.next()
+	call := callNode{name: strings.ToUpper(name.text)}
+	if t := p.peek(); t.kind == tokOp && t.text == ")" {
+		p.next()
+		return call, nil
+	}
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		t := p.next()
+		if t.kind == tokOp && t.text == ")" {
+			return call, nil
+		}
+		if t.kind != tokOp || t.text != "," {
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", t.pos)
+		}
+	}
+}
+
+// formulaEval evaluates formulas against named values, evaluating cells
+// that hold formulas on demand
+type formulaEval struct {
+	ctx    context.Context
+	cells  map[string]interface{}
+	cache  map[string]interface{}
+	active []string
+}
+
+// resolveName finds a cell, falling back to its upper-case spelling so that
+// a1 and A1 refer to the same cell
+func (e *formulaEval) resolveName(name string) (string, bool) {
+	if _, ok := e.cells[name]; ok {
+		return name, true
+	}
+	upper := strings.ToUpper(name)
+	_, ok := e.cells[upper]
+	return upper, ok
+}
+
+// cell returns the value of a named cell, evaluating it if it holds a
+// formula (a string starting with =)
+func (e *formulaEval) cell(name string) (interface{}, error) {
+	key, ok := e.resolveName(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown reference %q", name)
+	}
+	if v, ok := e.cache[key]; ok {
+		return v, nil
+	}
+	for i, active := range e.active {
+		if active == key {
+			cycle := append(append([]string{}, e.active[i:]...), key)
+			return nil, fmt.Errorf("circular reference: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(e.active) >= maxFormulaDepth {
+		return nil, fmt.Errorf("references are nested deeper than %d cells", maxFormulaDepth)
+	}
+	if err := e.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	raw := e.cells[key]
+	var value interface{}
+	switch v := raw.(type) {
+	case string:
+		if !strings.HasPrefix(v, "=") {
+			value = v
+			break
+		}
+		node, err := parseFormula(v)
+		if err != nil {
+			return nil, fmt.Errorf("cell %s: %w", key, err)
+		}
+		e.active = append(e.active, key)
+		value, err = e.eval(node)
+		e.active = e.active[:len(e.active)-1]
+		if err != nil {
+			return nil, err
+		}
+	case float64, bool:
+		value = v
+	case int:
+		value = float64(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case float64, bool, string:
+				list[i] = item
+			default:
+				return nil, fmt.Errorf("cell %s: list items must be numbers, booleans or strings", key)
+			}
+		}
+		value = list
+	case nil:
+		value = 0.0
+	default:
+		return nil, fmt.Errorf("cell %s has unsupported type %T", key, raw)
+	}
+	e.cache[key] = value
+	return value, nil
+}
+
+// maxCellRow is the last spreadsheet row, as in Excel
+const maxCellRow = 1048576
+
+// splitCellName splits a spreadsheet cell such as B12 into column and row
+func splitCellName(name string) (string, int, bool) {
+	name = strings.ToUpper(strings.ReplaceAll(name, "$", ""))
+	i := 0
+	for i < len(name) && name[i] >= 'A' && name[i] <= 'Z' {
+		i++
+	}
+	if i == 0 || i > 3 || i == len(name) {
+		return "", 0, false
+	}
+	row, err := strconv.Atoi(name[i:])
+	if err != nil || row < 1 || row > maxCellRow {
+		return "", 0, false
+	}
+	return name[:i], row, true
+}
+
+func columnIndex(col string) int {
+	n := 0
+	for i := 0; i < len(col); i++ {
+		n = n*26 + int(col[i]-'A'+1)
+	}
+	return n
+}
+
+func columnName(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('A' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+// maxRangeCells bounds the size of an A1:B2 range
+const maxRangeCells = 100000
+
+// expandRange returns the values of every present cell in a rectangular
+// range; missing cells are skipped, as blank cells are in spreadsheets
+func (e *formulaEval) expandRange(r rangeNode) ([]interface{}, error) {
+	c1, r1, ok1 := splitCellName(r.from)
+	c2, r2, ok2 := splitCellName(r.to)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("invalid range %s:%s", r.from, r.to)
+	}
+	x1, x2 := columnIndex(c1), columnIndex(c2)
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if r1 > r2 {
+		r1, r2 = r2, r1
+	}
+	// each side is checked on its own so the product cannot overflow
+	if x2-x1+1 > maxRangeCells || r2-r1+1 > maxRangeCells/(x2-x1+1) {
+		return nil, fmt.Errorf("range %s:%s has more than %d cells", r.from, r.to, maxRangeCells)
+	}
+
+	var values []interface{}
+	for x := x1; x <= x2; x++ {
+		for y := r1; y <= r2; y++ {
+			// missing cells never reach e.cell, which checks the context
+			if err := e.ctx.Err(); err != nil {
+				return nil, err
+			}
+			name := columnName(x) + strconv.Itoa(y)
+			if _, ok := e.resolveName(name); !ok {
+				continue
+			}
+			v, err := e.cell(name)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// formulaTypeName names a value's type for error messages and results
+func formulaTypeName(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func formulaNumber(v interface{}, context string) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case bool:
+		if n {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("%s needs a number, got %s", context, formulaTypeName(v))
+}
+
+func formulaBool(v interface{}, context string) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case float64:
+		return b != 0, nil
+	}
+	return false, fmt.Errorf("%s needs a boolean, got %s", context, formulaTypeName(v))
+}
+
+// eval evaluates a parsed node
+func (e *formulaEval) eval(node formulaNode) (interface{}, error) {
+	switch n := node.(type) {
+	case literalNode:
+		return n.value, nil
+
+	case refNode:
+		return e.cell(n.name)
+
+	case rangeNode:
+		return e.expandRange(n)
+
+	case unaryNode:
+		v, err := e.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "not" {
+			b, err := formulaBool(v, "not")
+			return !b, err
+		}
+		x, err := formulaNumber(v, "unary "+n.op)
+		if n.op == "-" {
+			x = -x
+		}
+		return x, err
+
+	case binaryNode:
+		return e.evalBinary(n)
+
+	case callNode:
+		return e.evalCall(n)
+	}
+	return nil, fmt.Errorf("unknown formula node %T", node)
+}
+
+// evalBinary evaluates an operator; and/or short-circuit
+func (e *formulaEval) evalBinary(n binaryNode) (interface{}, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "and", "&&", "or", "||":
+		l, err := formulaBool(left, n.op)
+		if err != nil {
+			return nil, err
+		}
+		isAnd := n.op == "and" || n.op == "&&"
+		if l != isAnd {
+			return l, nil
+		}
+		right, err := e.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return formulaBool(right, n.op)
+	}
+
+	right, err := e.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "=", "==", "<>", "!=":
+		equal := formulaEqual(left, right)
+		if n.op == "<>" || n.op == "!=" {
+			return !equal, nil
+		}
+		return equal, nil
+	case "<", "<=", ">", ">=":
+		cmp, err := formulaCompare(left, right, n.op)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	}
+
+	l, err := formulaNumber(left, "operator "+n.op)
+	if err != nil {
+		return nil, err
+	}
+	r, err := formulaNumber(right, "operator "+n.op)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	case "^":
+		result := math.Pow(l, r)
+		if math.IsNaN(result) || math.IsInf(result, 0) {
+			return nil, fmt.Errorf("%v ^ %v is not a finite number", l, r)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func formulaEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return ok && x == y
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return false
+}
+
+func formulaCompare(a, b interface{}, op string) (int, error) {
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	x, err := formulaNumber(a, "operator "+op)
+	if err != nil {
+		return 0, err
+	}
+	y, err := formulaNumber(b, "operator "+op)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// numericArgs evaluates arguments and flattens lists and ranges into
+// numbers. Strings and booleans inside lists are skipped, as spreadsheets
+// do; given directly they are an error.
+func (e *formulaEval) numericArgs(name string, args []formulaNode) ([]float64, error) {
+	var numbers []float64
+	for _, arg := range args {
+		v, err := e.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := v.([]interface{}); ok {
+			for _, item := range list {
+				if x, ok := item.(float64); ok {
+					numbers = append(numbers, x)
+				}
+			}
+			continue
+		}
+		x, err := formulaNumber(v, name)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, x)
+	}
+	return numbers, nil
+}
+
+// evalCall evaluates a built-in function
+func (e *formulaEval) evalCall(n callNode) (interface{}, error) {
+	switch n.name {
+	case "IF":
+		if len(n.args) < 2 || len(n.args) > 3 {
+			return nil, fmt.Errorf("IF takes 2 or 3 arguments")
+		}
+		cond, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		b, err := formulaBool(cond, "IF")
+		if err != nil {
+			return nil, err
+		}
+		// only the chosen branch is evaluated
+		if b {
+			return e.eval(n.args[1])
+		}
+		if len(n.args) == 3 {
+			return e.eval(n.args[2])
+		}
+		return false, nil
+
+	case "ROUND":
+		if len(n.args) < 1 || len(n.args) > 2 {
+			return nil, fmt.Errorf("ROUND takes 1 or 2 arguments")
+		}
+		args, err := e.numericArgs(n.name, n.args)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != len(n.args) {
+			return nil, fmt.Errorf("ROUND needs numbers")
+		}
+		digits := 0.0
+		if len(args) == 2 {
+			digits = math.Trunc(args[1])
+		}
+		scale := math.Pow(10, digits)
+		return math.Round(args[0]*scale) / scale, nil
+
+	case "SUM", "AVG", "AVERAGE", "MIN", "MAX", "COUNT":
+		numbers, err := e.numericArgs(n.name, n.args)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "COUNT":
+			return float64(len(numbers)), nil
+		case "SUM":
+			sum := 0.0
+			for _, x := range numbers {
+				sum += x
+			}
+			return sum, nil
+		}
+		if len(numbers) == 0 {
+			return nil, fmt.Errorf("%s needs at least one number", n.name)
+		}
+		switch n.name {
+		case "MIN", "MAX":
+			best := numbers[0]
+			for _, x := range numbers[1:] {
+				if n.name == "MIN" && x < best || n.name == "MAX" && x > best {
+					best = x
+				}
+			}
+			return best, nil
+		}
+		sum := 0.0
+		for _, x := range numbers {
+			sum += x
+		}
+		return sum / float64(len(numbers)), nil
+	}
+	return nil, fmt.Errorf("unknown function %s", n.name)
+}
+
+// formulaRefs collects the cell names a node references directly
+func formulaRefs(node formulaNode, seen map[string]bool, refs *[]interface{}) {
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			*refs = append(*refs, name)
+		}
+	}
+	switch n := node.(type) {
+	case refNode:
+		add(n.name)
+	case rangeNode:
+		add(n.from + ":" + n.to)
+	case unaryNode:
+		formulaRefs(n.operand, seen, refs)
+	case binaryNode:
+		formulaRefs(n.left, seen, refs)
+		formulaRefs(n.right, seen, refs)
+	case callNode:
+		for _, arg := range n.args {
+			formulaRefs(arg, seen, refs)
+		}
+	}
+}
+
+// EvaluateFormula evaluates a spreadsheet-style formula against named
+// values. Cells holding strings that start with = are formulas themselves
+// and are evaluated on demand; circular references are reported as errors.
+func (dp *DataProcessor) EvaluateFormula(ctx context.Context, formula string, cells map[string]interface{}) (map[string]interface{}, error) {
+	node, err := parseFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	if cells == nil {
+		cells = map[string]interface{}{}
+	}
+
+	e := &formulaEval{ctx: ctx, cells: cells, cache: map[string]interface{}{}}
+	value, err := e.eval(node)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := value.([]interface{}); ok && list == nil {
+		value = []interface{}{}
+	}
+
+	refs := []interface{}{}
+	formulaRefs(node, map[string]bool{}, &refs)
+	return map[string]interface{}{
+		"result":     value,
+		"type":       formulaTypeName(value),
+		"references": refs,
+	}, nil
+}
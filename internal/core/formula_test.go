@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateFormula(t *testing.T) {
+	cells := map[string]interface{}{
+		"A1":    2.0,
+		"A2":    3.0,
+		"A3":    "=A1*A2",
+		"B1":    "text",
+		"B2":    true,
+		"price": 9.5,
+		"qty":   4,
+		"list":  []interface{}{1.0, "skip", 2.0, true},
+		"blank": nil,
+	}
+	tests := []struct {
+		formula string
+		want    interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"2 ^ 3 ^ 2", 512.0},
+		{"-2 ^ 2", -4.0},
+		{"10 % 4", 2.0},
+		{"1.5e2", 150.0},
+		{"=A1 + a2", 5.0},
+		{"A3 + 1", 7.0},
+		{"price * qty", 38.0},
+		{"blank + 1", 1.0},
+		{"SUM(A1:A3)", 11.0},
+		{"sum(list)", 3.0},
+		{"COUNT(A1:A9)", 3.0},
+		{"AVERAGE(1, 2, 6)", 3.0},
+		{"MIN(A1:A3, 1)", 1.0},
+		{"MAX(A1:A3)", 6.0},
+		{"ROUND(2.345, 2)", 2.35},
+		{"ROUND(2.5)", 3.0},
+		{"IF(A1 > A2, \"big\", \"small\")", "small"},
+		{"IF(false, 1)", false},
+		{"IF(true, 1, 1/0)", 1.0},
+		{"B1 = \"text\"", true},
+		{"\"say \"\"hi\"\"\"", "say \"hi\""},
+		{"\"a\" < \"b\"", true},
+		{"1 <> 2 and not false", true},
+		{"false || B2", true},
+		{"false && 1/0", false},
+		{"!(1 >= 2)", true},
+		{"A1:A2", []interface{}{2.0, 3.0}},
+		{"C1:C2", []interface{}{}},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.EvaluateFormula(context.Background(), tt.formula, cells)
+		if err != nil {
+			t.Errorf("%s: %v", tt.formula, err)
+			continue
+		}
+		if !reflect.DeepEqual(result["result"], tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.formula, result["result"], tt.want)
+		}
+	}
+}
+
+func TestEvaluateFormulaErrors(t *testing.T) {
+	cells := map[string]interface{}{
+		"A1":   "=B1 + 1",
+		"B1":   "=A1 + 1",
+		"C1":   "=C1",
+		"D1":   "text",
+		"E1":   map[string]interface{}{},
+		"F1":   "=1 +",
+		"list": []interface{}{map[string]interface{}{}},
+	}
+	tests := []struct {
+		formula string
+		err     string
+	}{
+		{"A1", "circular reference: A1 -> B1 -> A1"},
+		{"C1", "circular reference: C1 -> C1"},
+		{"missing", "unknown reference"},
+		{"1 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"(-8) ^ 0.5", "not a finite number"},
+		{"D1 + 1", "needs a number, got string"},
+		{"D1 and true", "needs a boolean"},
+		{"E1", "unsupported type"},
+		{"F1", "cell F1: unexpected end of formula"},
+		{"list", "list items must be"},
+		{"NOPE(1)", "unknown function NOPE"},
+		{"IF(1)", "IF takes 2 or 3 arguments"},
+		{"MIN()", "MIN needs at least one number"},
+		{"SUM(D1)", "SUM needs a number"},
+		{"1 2", "unexpected \"2\""},
+		{"(1 + 2", "expected \")\""},
+		{"\"open", "unterminated string"},
+		{"1 # 2", "unexpected character"},
+		{"A1:", "expected a cell after ':'"},
+		{"A1:ZZZ999999", "more than"},
+		{"A1:XFD1048576", "more than"},
+		// sizes whose cell count would overflow
+		{"SUM(A1:B9223372036854775807)", "invalid range"},
+		{"SUM(A1:C4611686018427387904)", "invalid range"},
+		{"A1:A1048577", "invalid range"},
+		{"foo:bar", "invalid range"},
+		{strings.Repeat("(", 300) + "1" + strings.Repeat(")", 300), "nested deeper"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		_, err := dp.EvaluateFormula(context.Background(), tt.formula, cells)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want it to contain %q", tt.formula, err, tt.err)
+		}
+	}
+}
+
+func TestEvaluateFormulaReferenceChain(t *testing.T) {
+	// A long but acyclic chain of cells is fine up to the depth limit
+	cells := map[string]interface{}{"C1": 1.0}
+	for i := 2; i <= 100; i++ {
+		cells["C"+strconv.Itoa(i)] = "=C" + strconv.Itoa(i-1) + " + 1"
+	}
+	dp := NewDataProcessor()
+	result, err := dp.EvaluateFormula(context.Background(), "C100", cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["result"] != 100.0 {
+		t.Errorf("result = %v, want 100", result["result"])
+	}
+
+	cells["C300"] = 0.0
+	for i := 301; i <= 600; i++ {
+		cells["C"+strconv.Itoa(i)] = "=C" + strconv.Itoa(i-1)
+	}
+	if _, err := dp.EvaluateFormula(context.Background(), "C600", cells); err == nil || !strings.Contains(err.Error(), "nested deeper") {
+		t.Errorf("err = %v, want a depth error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := dp.EvaluateFormula(ctx, "C100", cells); err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	// a range of missing cells still checks the context
+	if _, err := dp.EvaluateFormula(ctx, "SUM(Z1:Z1000)", cells); err != context.Canceled {
+		t.Errorf("range err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestEvaluateFormulaResult(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.EvaluateFormula(context.Background(), "SUM(A1:B2) + x * x", map[string]interface{}{"A1": 1.0, "x": 2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["result"] != 5.0 || result["type"] != "number" {
+		t.Errorf("result = %v", result)
+	}
+	if refs := result["references"]; !reflect.DeepEqual(refs, []interface{}{"A1:B2", "x"}) {
+		t.Errorf("references = %v", refs)
+	}
+}
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadiusKm is the mean Earth radius used for great-circle distances
+const EarthRadiusKm = 6371.0088
+
+// distanceUnits converts kilometres to each supported unit
+var distanceUnits = map[string]float64{
+	"km": 1,
+	"m":  1000,
+	"mi": 1 / 1.609344,
+	"nm": 1 / 1.852,
+}
+
+// LatLng is a point in decimal degrees
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+// Validate checks that the point lies within latitude -90..90 and
+// longitude -180..180
+func (p LatLng) Validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude %v is out of range -90 to 90", p.Lat)
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude %v is out of range -180 to 180", p.Lng)
+	}
+	return nil
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// distanceFactor returns the factor converting kilometres to unit
+func distanceFactor(unit string) (float64, error) {
+	if unit == "" {
+		unit = "km"
+	}
+	factor, ok := distanceUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown distance unit %q (expected km, m, mi or nm)", unit)
+	}
+	return factor, nil
+}
+
+// HaversineKm returns the great-circle distance between two points in
+// kilometres
+func HaversineKm(a, b LatLng) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GeoBounds is a latitude/longitude box. When CrossesAntimeridian is set,
+// MinLng is greater than MaxLng and the box covers MinLng..180 and
+// -180..MaxLng.
+type GeoBounds struct {
+	MinLat, MaxLat      float64
+	MinLng, MaxLng      float64
+	CrossesAntimeridian bool
+}
+
+// BoundingBox returns the smallest lat/lng box containing every point within
+// radiusKm of center. Boxes reaching a pole span all longitudes.
+func BoundingBox(center LatLng, radiusKm float64) GeoBounds {
+	r := radiusKm / EarthRadiusKm
+	lat, lng := radians(center.Lat), radians(center.Lng)
+	minLat, maxLat := lat-r, lat+r
+
+	if minLat <= -math.Pi/2 || maxLat >= math.Pi/2 || r >= math.Pi {
+		return GeoBounds{
+			MinLat: degrees(math.Max(minLat, -math.Pi/2)),
+			MaxLat: degrees(math.Min(maxLat, math.Pi/2)),
+			MinLng: -180,
+			MaxLng: 180,
+		}
+	}
+
+	dLng := math.Asin(math.Sin(r) / math.Cos(lat))
+	minLng, maxLng := lng-dLng, lng+dLng
+	if minLng < -math.Pi {
+		minLng += 2 * math.Pi
+	}
+	if maxLng > math.Pi {
+		maxLng -= 2 * math.Pi
+	}
+
+	return GeoBounds{
+		MinLat:              degrees(minLat),
+		MaxLat:              degrees(maxLat),
+		MinLng:              degrees(minLng),
+		MaxLng:              degrees(maxLng),
+		CrossesAntimeridian: minLng > maxLng,
+	}
+}
+
+// GeoDistance returns the haversine distance between two points in unit
+// (km, m, mi or nm)
+func (dp *DataProcessor) GeoDistance(a, b LatLng, unit string) (map[string]interface{}, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	factor, err := distanceFactor(unit)
+	if err != nil {
+		return nil, err
+	}
+	if unit == "" {
+		unit = "km"
+	}
+
+	return map[string]interface{}{
+		"distance": HaversineKm(a, b) * factor,
+		"unit":     unit,
+	}, nil
+}
+
+// GeoBoundingBox returns the bounds of the circle of radius (in unit)
+// around center
+func (dp *DataProcessor) GeoBoundingBox(center LatLng, radius float64, unit string) (map[string]interface{}, error) {
+	if err := center.Validate(); err != nil {
+		return nil, err
+	}
+	factor, err := distanceFactor(unit)
+	if err != nil {
+		return nil, err
+	}
+	if radius < 0 || math.IsNaN(radius) || math.IsInf(radius, 0) {
+		return nil, fmt.Errorf("radius must be a non-negative number")
+	}
+
+	box := BoundingBox(center, radius/factor)
+	return map[string]interface{}{
+		"minLat":              box.MinLat,
+		"maxLat":              box.MaxLat,
+		"minLng":              box.MinLng,
+		"maxLng":              box.MaxLng,
+		"crossesAntimeridian": box.CrossesAntimeridian,
+	}, nil
+}
+
+// geoEdgeEpsilon is the tolerance in degrees for treating a point as lying
+// on a polygon edge
+const geoEdgeEpsilon = 1e-9
+
+// unwrapPolygon returns the polygon's vertices with longitudes shifted by
+// multiples of 360 so that no edge spans more than 180 degrees, making
+// polygons that cross the antimeridian continuous
+func unwrapPolygon(polygon []LatLng) []LatLng {
+	out := make([]LatLng, len(polygon))
+	out[0] = polygon[0]
+	for i := 1; i < len(polygon); i++ {
+		lng := polygon[i].Lng
+		prev := out[i-1].Lng
+		for lng-prev > 180 {
+			lng -= 360
+		}
+		for lng-prev < -180 {
+			lng += 360
+		}
+		out[i] = LatLng{Lat: polygon[i].Lat, Lng: lng}
+	}
+	return out
+}
+
+// onSegment reports whether p lies on the segment a-b, treating longitude
+// as x and latitude as y
+func onSegment(p, a, b LatLng) bool {
+	cross := (b.Lng-a.Lng)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lng-a.Lng)
+	if math.Abs(cross) > geoEdgeEpsilon {
+		return false
+	}
+	return p.Lng >= math.Min(a.Lng, b.Lng)-geoEdgeEpsilon && p.Lng <= math.Max(a.Lng, b.Lng)+geoEdgeEpsilon &&
+		p.Lat >= math.Min(a.Lat, b.Lat)-geoEdgeEpsilon && p.Lat <= math.Max(a.Lat, b.Lat)+geoEdgeEpsilon
+}
+
+// pointInRing runs the ray-casting test on a planar ring, counting points on
+// an edge as inside
+func pointInRing(p LatLng, ring []LatLng) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// PointInPolygon reports whether p lies inside polygon using ray casting on
+// the lat/lng plane. Edges are straight lines in degrees rather than
+// great-circle arcs, polygons crossing the antimeridian are handled by
+// unwrapping their longitudes, and points exactly on an edge or vertex count
+// as inside. The polygon may be open or closed (first vertex repeated).
+func PointInPolygon(p LatLng, polygon []LatLng) bool {
+	ring := unwrapPolygon(polygon)
+	for _, shift := range []float64{0, 360, -360} {
+		if pointInRing(LatLng{Lat: p.Lat, Lng: p.Lng + shift}, ring) {
+			return true
+		}
+	}
+	return false
+}
+
+// PointInPolygon validates the point and polygon and reports whether the
+// point is inside
+func (dp *DataProcessor) PointInPolygon(p LatLng, polygon []LatLng) (map[string]interface{}, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if n := len(polygon); n > 1 && polygon[0] == polygon[n-1] {
+		polygon = polygon[:n-1]
+	}
+	if len(polygon) < 3 {
+		return nil, fmt.Errorf("polygon needs at least 3 distinct vertices, got %d", len(polygon))
+	}
+	for i, v := range polygon {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("vertex %d: %w", i, err)
+		}
+	}
+
+	return map[string]interface{}{
+		"inside":   PointInPolygon(p, polygon),
+		"vertices": len(polygon),
+	}, nil
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeoDistance(t *testing.T) {
+	dp := NewDataProcessor()
+	london := LatLng{Lat: 51.5074, Lng: -0.1278}
+	paris := LatLng{Lat: 48.8566, Lng: 2.3522}
+
+	tests := []struct {
+		a, b LatLng
+		unit string
+		want float64
+	}{
+		{london, paris, "", 343.56},
+		{london, paris, "m", 343560},
+		{london, paris, "mi", 213.47},
+		{london, paris, "nm", 185.50},
+		{london, london, "km", 0},
+		// Across the antimeridian the short way round
+		{LatLng{Lat: 0, Lng: 179.5}, LatLng{Lat: 0, Lng: -179.5}, "km", 111.19},
+		{LatLng{Lat: 90, Lng: 0}, LatLng{Lat: -90, Lng: 0}, "km", math.Pi * EarthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		result, err := dp.GeoDistance(tt.a, tt.b, tt.unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := result["distance"].(float64); math.Abs(got-tt.want) > tt.want*0.001+1e-9 {
+			t.Errorf("%v to %v in %q = %v, want %v", tt.a, tt.b, tt.unit, got, tt.want)
+		}
+	}
+
+	if _, err := dp.GeoDistance(london, LatLng{Lat: 91}, "km"); err == nil {
+		t.Error("expected an error for latitude 91")
+	}
+	if _, err := dp.GeoDistance(LatLng{Lng: math.NaN()}, paris, "km"); err == nil {
+		t.Error("expected an error for a NaN longitude")
+	}
+	if _, err := dp.GeoDistance(london, paris, "ft"); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name   string
+		center LatLng
+		radius float64
+		want   GeoBounds
+	}{
+		{
+			name:   "equator",
+			center: LatLng{Lat: 0, Lng: 0},
+			radius: 111.19,
+			want:   GeoBounds{MinLat: -1, MaxLat: 1, MinLng: -1, MaxLng: 1},
+		},
+		{
+			name:   "antimeridian",
+			center: LatLng{Lat: 0, Lng: 179.5},
+			radius: 111.19,
+			want:   GeoBounds{MinLat: -1, MaxLat: 1, MinLng: 178.5, MaxLng: -179.5, CrossesAntimeridian: true},
+		},
+		{
+			name:   "reaches the pole",
+			center: LatLng{Lat: 89.5, Lng: 40},
+			radius: 111.19,
+			want:   GeoBounds{MinLat: 88.5, MaxLat: 90, MinLng: -180, MaxLng: 180},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BoundingBox(tt.center, tt.radius)
+			near := func(a, b float64) bool { return math.Abs(a-b) < 0.001 }
+			if !near(got.MinLat, tt.want.MinLat) || !near(got.MaxLat, tt.want.MaxLat) ||
+				!near(got.MinLng, tt.want.MinLng) || !near(got.MaxLng, tt.want.MaxLng) ||
+				got.CrossesAntimeridian != tt.want.CrossesAntimeridian {
+				t.Errorf("box = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Every point on the circle falls inside the box
+	center := LatLng{Lat: 60, Lng: 10}
+	box := BoundingBox(center, 500)
+	for bearing := 0.0; bearing < 360; bearing += 5 {
+		p := destination(center, bearing, 500)
+		if p.Lat < box.MinLat-1e-9 || p.Lat > box.MaxLat+1e-9 || p.Lng < box.MinLng-1e-9 || p.Lng > box.MaxLng+1e-9 {
+			t.Errorf("bearing %v: %+v lies outside %+v", bearing, p, box)
+		}
+	}
+}
+
+// destination returns the point distKm from start along the initial bearing
+func destination(start LatLng, bearing, distKm float64) LatLng {
+	d := distKm / EarthRadiusKm
+	lat1, lng1, b := radians(start.Lat), radians(start.Lng), radians(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return LatLng{Lat: degrees(lat2), Lng: degrees(lng2)}
+}
+
+func TestGeoBoundingBoxHandler(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GeoBoundingBox(LatLng{}, 60.04, "nm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxLat := result["maxLat"].(float64); math.Abs(maxLat-1) > 0.001 {
+		t.Errorf("maxLat = %v, want about 1", maxLat)
+	}
+
+	for _, radius := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := dp.GeoBoundingBox(LatLng{}, radius, "km"); err == nil {
+			t.Errorf("radius %v: expected an error", radius)
+		}
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	square := []LatLng{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+	// A U shape open to the north
+	concave := []LatLng{{0, 0}, {0, 30}, {30, 30}, {30, 20}, {10, 20}, {10, 10}, {30, 10}, {30, 0}}
+	// A box straddling the antimeridian from 170E to 170W
+	pacific := []LatLng{{-10, 170}, {-10, -170}, {10, -170}, {10, 170}}
+
+	tests := []struct {
+		name    string
+		p       LatLng
+		polygon []LatLng
+		want    bool
+	}{
+		{"inside", LatLng{5, 5}, square, true},
+		{"outside", LatLng{5, 15}, square, false},
+		{"on an edge", LatLng{0, 5}, square, true},
+		{"on a vertex", LatLng{10, 10}, square, true},
+		{"in the notch of a concave polygon", LatLng{20, 15}, concave, false},
+		{"in an arm of a concave polygon", LatLng{20, 5}, concave, true},
+		{"east of the antimeridian", LatLng{0, 175}, pacific, true},
+		{"west of the antimeridian", LatLng{0, -175}, pacific, true},
+		{"on the antimeridian", LatLng{0, 180}, pacific, true},
+		{"outside a wrapped polygon", LatLng{0, 0}, pacific, false},
+		{"closed ring", LatLng{5, 5}, append(square, square[0]), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInPolygon(tt.p, tt.polygon); got != tt.want {
+				t.Errorf("inside = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPointInPolygonHandler(t *testing.T) {
+	dp := NewDataProcessor()
+	triangle := []LatLng{{0, 0}, {0, 10}, {10, 0}, {0, 0}}
+
+	result, err := dp.PointInPolygon(LatLng{2, 2}, triangle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The closing vertex is not counted
+	if result["inside"] != true || result["vertices"] != 3 {
+		t.Errorf("result = %v", result)
+	}
+
+	tests := []struct {
+		name    string
+		p       LatLng
+		polygon []LatLng
+	}{
+		{"too few vertices", LatLng{}, []LatLng{{0, 0}, {1, 1}, {0, 0}}},
+		{"invalid point", LatLng{Lat: 100}, triangle},
+		{"invalid vertex", LatLng{}, []LatLng{{0, 0}, {0, 200}, {10, 0}}},
+	}
+	for _, tt := range tests {
+		if _, err := dp.PointInPolygon(tt.p, tt.polygon); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
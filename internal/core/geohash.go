@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// geohashAlphabet is the geohash base32 alphabet (no a, i, l or o)
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest supported geohash
+const MaxGeohashPrecision = 12
+
+// GeohashEncode returns the geohash of p with the given number of characters
+func GeohashEncode(p LatLng, precision int) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	if precision < 1 || precision > MaxGeohashPrecision {
+		return "", fmt.Errorf("precision must be between 1 and %d", MaxGeohashPrecision)
+	}
+
+	latLo, latHi := -90.0, 90.0
+	lngLo, lngHi := -180.0, 180.0
+	var sb strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for sb.Len() < precision {
+		if even {
+			mid := (lngLo + lngHi) / 2
+			if p.Lng >= mid {
+				ch |= 1 << (4 - bit)
+				lngLo = mid
+			} else {
+				lngHi = mid
+			}
+		} else {
+			mid := (latLo + latHi) / 2
+			if p.Lat >= mid {
+				ch |= 1 << (4 - bit)
+				latLo = mid
+			} else {
+				latHi = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String(), nil
+}
+
+// GeohashDecode returns the cell bounds of a geohash. Decoding is
+// case-insensitive.
+func GeohashDecode(hash string) (GeoBounds, error) {
+	if hash == "" || len(hash) > MaxGeohashPrecision {
+		return GeoBounds{}, fmt.Errorf("geohash must be 1 to %d characters", MaxGeohashPrecision)
+	}
+
+	latLo, latHi := -90.0, 90.0
+	lngLo, lngHi := -180.0, 180.0
+	even := true
+	for i, r := range strings.ToLower(hash) {
+		idx := strings.IndexRune(geohashAlphabet, r)
+		if idx < 0 {
+			return GeoBounds{}, fmt.Errorf("invalid geohash character %q at position %d", r, i)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx>>bit&1 == 1
+			if even {
+				mid := (lngLo + lngHi) / 2
+				if set {
+					lngLo = mid
+				} else {
+					lngHi = mid
+				}
+			} else {
+				mid := (latLo + latHi) / 2
+				if set {
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return GeoBounds{MinLat: latLo, MaxLat: latHi, MinLng: lngLo, MaxLng: lngHi}, nil
+}
+
+// GeohashNeighbors returns the eight cells of the same precision around
+// hash, keyed by compass direction. Longitude wraps across the antimeridian;
+// cells beyond a pole are omitted.
+func GeohashNeighbors(hash string) (map[string]string, error) {
+	box, err := GeohashDecode(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	dLat := box.MaxLat - box.MinLat
+	dLng := box.MaxLng - box.MinLng
+	center := LatLng{Lat: (box.MinLat + box.MaxLat) / 2, Lng: (box.MinLng + box.MaxLng) / 2}
+	directions := []struct {
+		name        string
+		north, east float64
+	}{
+		{"n", 1, 0}, {"ne", 1, 1}, {"e", 0, 1}, {"se", -1, 1},
+		{"s", -1, 0}, {"sw", -1, -1}, {"w", 0, -1}, {"nw", 1, -1},
+	}
+
+	neighbors := make(map[string]string, len(directions))
+	for _, d := range directions {
+		lat := center.Lat + d.north*dLat
+		if lat > 90 || lat < -90 {
+			continue
+		}
+		lng := center.Lng + d.east*dLng
+		if lng > 180 {
+			lng -= 360
+		} else if lng < -180 {
+			lng += 360
+		}
+		neighbor, err := GeohashEncode(LatLng{Lat: lat, Lng: lng}, len(hash))
+		if err != nil {
+			return nil, err
+		}
+		neighbors[d.name] = neighbor
+	}
+	return neighbors, nil
+}
+
+// GeohashEncode encodes a point as a geohash of the given precision
+func (dp *DataProcessor) GeohashEncode(p LatLng, precision int) (map[string]interface{}, error) {
+	hash, err := GeohashEncode(p, precision)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"geohash":   hash,
+		"precision": precision,
+	}, nil
+}
+
+// GeohashDecode returns the center and bounds of a geohash cell, with the
+// error margins in degrees
+func (dp *DataProcessor) GeohashDecode(hash string) (map[string]interface{}, error) {
+	box, err := GeohashDecode(hash)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"lat":    (box.MinLat + box.MaxLat) / 2,
+		"lng":    (box.MinLng + box.MaxLng) / 2,
+		"latErr": (box.MaxLat - box.MinLat) / 2,
+		"lngErr": (box.MaxLng - box.MinLng) / 2,
+		"bounds": map[string]interface{}{
+			"minLat": box.MinLat,
+			"maxLat": box.MaxLat,
+			"minLng": box.MinLng,
+			"maxLng": box.MaxLng,
+		},
+	}, nil
+}
+
+// GeohashNeighbors returns the adjacent cells of a geohash
+func (dp *DataProcessor) GeohashNeighbors(hash string) (map[string]interface{}, error) {
+	neighbors, err := GeohashNeighbors(hash)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(neighbors))
+	for dir, h := range neighbors {
+		result[dir] = h
+	}
+	return map[string]interface{}{
+		"geohash":   strings.ToLower(hash),
+		"neighbors": result,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGeohashEncode(t *testing.T) {
+	tests := []struct {
+		p         LatLng
+		precision int
+		want      string
+	}{
+		{LatLng{Lat: 57.64911, Lng: 10.40744}, 11, "u4pruydqqvj"},
+		{LatLng{Lat: 42.6, Lng: -5.6}, 5, "ezs42"},
+		{LatLng{Lat: 0, Lng: 0}, 1, "s"},
+		{LatLng{Lat: -90, Lng: -180}, 12, "000000000000"},
+		{LatLng{Lat: 90, Lng: 180}, 12, "zzzzzzzzzzzz"},
+	}
+
+	for _, tt := range tests {
+		got, err := GeohashEncode(tt.p, tt.precision)
+		if err != nil || got != tt.want {
+			t.Errorf("encode %+v = %q, %v; want %q", tt.p, got, err, tt.want)
+		}
+	}
+
+	for _, precision := range []int{0, MaxGeohashPrecision + 1} {
+		if _, err := GeohashEncode(LatLng{}, precision); err == nil {
+			t.Errorf("precision %d: expected an error", precision)
+		}
+	}
+	if _, err := GeohashEncode(LatLng{Lat: -91}, 5); err == nil {
+		t.Error("expected an error for an invalid point")
+	}
+}
+
+func TestGeohashDecode(t *testing.T) {
+	box, err := GeohashDecode("EZS42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GeoBounds{MinLat: 42.5830078125, MaxLat: 42.626953125, MinLng: -5.625, MaxLng: -5.5810546875}
+	if box != want {
+		t.Errorf("bounds = %+v, want %+v", box, want)
+	}
+
+	// Encoding a decoded cell's center gives back the same hash
+	for _, hash := range []string{"u4pruydqqvj", "9q8yy", "r3gx2f", "0"} {
+		box, _ := GeohashDecode(hash)
+		center := LatLng{Lat: (box.MinLat + box.MaxLat) / 2, Lng: (box.MinLng + box.MaxLng) / 2}
+		if got, _ := GeohashEncode(center, len(hash)); got != hash {
+			t.Errorf("%s round-tripped to %s", hash, got)
+		}
+	}
+
+	for _, hash := range []string{"", "abc", "u4pruydqqvjxx"} {
+		if _, err := GeohashDecode(hash); err == nil {
+			t.Errorf("%q: expected an error", hash)
+		}
+	}
+}
+
+func TestGeohashNeighbors(t *testing.T) {
+	got, err := GeohashNeighbors("dqcjq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"n": "dqcjw", "ne": "dqcjx", "e": "dqcjr", "se": "dqcjp",
+		"s": "dqcjn", "sw": "dqcjj", "w": "dqcjm", "nw": "dqcjt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("neighbors = %v, want %v", got, want)
+	}
+
+	// Eastern neighbors wrap across the antimeridian
+	edge, _ := GeohashEncode(LatLng{Lat: 10, Lng: 179.99}, 4)
+	got, _ = GeohashNeighbors(edge)
+	box, _ := GeohashDecode(got["e"])
+	if box.MinLng != -180 {
+		t.Errorf("east of %s is %s at %+v", edge, got["e"], box)
+	}
+
+	// Cells beyond the pole are left out
+	polar, _ := GeohashEncode(LatLng{Lat: 89.99, Lng: 0}, 3)
+	got, _ = GeohashNeighbors(polar)
+	if _, ok := got["n"]; ok || len(got) != 5 {
+		t.Errorf("polar neighbors = %v", got)
+	}
+}
+
+func TestGeohashHandlers(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GeohashDecode("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lat := result["lat"].(float64); math.Abs(lat-42.605) > 0.001 {
+		t.Errorf("lat = %v", lat)
+	}
+	if latErr := result["latErr"].(float64); math.Abs(latErr-0.02197265625) > 1e-12 {
+		t.Errorf("latErr = %v", latErr)
+	}
+
+	result, err = dp.GeohashNeighbors("DQCJQ")
+	if err != nil || result["geohash"] != "dqcjq" {
+		t.Errorf("neighbors result = %v, %v", result, err)
+	}
+}
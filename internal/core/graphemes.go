@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// CountGraphemes returns the number of user-perceived characters (extended
+// grapheme clusters) in s. Flags, emoji with skin-tone modifiers and letters
+// with combining accents each count as one.
+func CountGraphemes(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
+
+// AnalyzeGraphemes reports byte, rune and grapheme counts along with the
+// emoji found in the text
+func (dp *DataProcessor) AnalyzeGraphemes(input string) (map[string]interface{}, error) {
+	if input == "" {
+		return nil, fmt.Errorf("empty input provided")
+	}
+
+	graphemes := 0
+	emojiCounts := make(map[string]int)
+	var emojiOrder []string
+	combining := 0
+
+	g := uniseg.NewGraphemes(input)
+	for g.Next() {
+		graphemes++
+		runes := g.Runes()
+		if len(runes) > 1 {
+			combining++
+		}
+		if isEmojiCluster(runes) {
+			cluster := g.Str()
+			if emojiCounts[cluster] == 0 {
+				emojiOrder = append(emojiOrder, cluster)
+			}
+			emojiCounts[cluster]++
+		}
+	}
+
+	emoji := make([]interface{}, 0, len(emojiOrder))
+	totalEmoji := 0
+	for _, e := range emojiOrder {
+		emoji = append(emoji, map[string]interface{}{
+			"emoji": e,
+			"count": emojiCounts[e],
+		})
+		totalEmoji += emojiCounts[e]
+	}
+
+	return map[string]interface{}{
+		"graphemeCount":   graphemes,
+		"runeCount":       utf8.RuneCountInString(input),
+		"byteCount":       len(input),
+		"emojiCount":      totalEmoji,
+		"uniqueEmoji":     len(emojiOrder),
+		"emoji":           emoji,
+		"multiRuneGlyphs": combining,
+		"displayWidth":    uniseg.StringWidth(input),
+	}, nil
+}
+
+// isEmojiCluster reports whether a grapheme cluster is an emoji, based on
+// the pictographic and regional indicator blocks
+func isEmojiCluster(runes []rune) bool {
+	for _, r := range runes {
+		switch {
+		case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, flags
+			return true
+		case r >= 0x2600 && r <= 0x27BF: // Misc symbols and dingbats
+			return true
+		case r >= 0x2B00 && r <= 0x2BFF: // Arrows and stars such as ⭐
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountGraphemes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"e\u0301", 1}, // combining acute
+		{"\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7", 2},   // two flags
+		{"\U0001F44D\U0001F3FD", 1},                       // skin tone modifier
+		{"\U0001F469\u200D\U0001F469\u200D\U0001F467", 1}, // ZWJ family
+		{"\r\n", 1},
+		{"한국어", 3},
+	}
+
+	for _, tt := range tests {
+		if got := CountGraphemes(tt.input); got != tt.want {
+			t.Errorf("CountGraphemes(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeGraphemes(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.AnalyzeGraphemes("hi \U0001F44D\U0001F3FD\U0001F44D\U0001F3FD \u2B50 \U0001F1EF\U0001F1F5 cafe\u0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"graphemeCount":   14,
+		"runeCount":       18,
+		"byteCount":       39,
+		"emojiCount":      4,
+		"uniqueEmoji":     3,
+		"multiRuneGlyphs": 4,
+		"emoji": []interface{}{
+			map[string]interface{}{"emoji": "\U0001F44D\U0001F3FD", "count": 2},
+			map[string]interface{}{"emoji": "\u2B50", "count": 1},
+			map[string]interface{}{"emoji": "\U0001F1EF\U0001F1F5", "count": 1},
+		},
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(result[key], value) {
+			t.Errorf("%s = %v, want %v", key, result[key], value)
+		}
+	}
+
+	if _, err := dp.AnalyzeGraphemes(""); err == nil {
+		t.Error("expected an error for empty input")
+	}
+}
+
+func TestProcessTextCharacterCount(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.ProcessText("Ein Tag in K\u00f6ln \U0001F1E9\U0001F1EA.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["characterCount"] != 18 {
+		t.Errorf("characterCount = %v, want 18", result["characterCount"])
+	}
+}
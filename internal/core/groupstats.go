@@ -0,0 +1,248 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// groupStatsSortKeys are the statistics groups can be sorted by besides
+// their key and row count
+var groupStatsSortKeys = map[string]string{
+	"count": "count", "sum": "sum", "mean": "mean", "median": "median",
+	"min": "min", "max": "max", "stddev": "standardDev", "standardDev": "standardDev",
+}
+
+// rowField is a field of a row object, given as a dotted path or a JSON
+// pointer as in business rules
+type rowField []string
+
+func parseRowField(field string) (rowField, error) {
+	if field == "" {
+		return nil, fmt.Errorf("field required")
+	}
+	tokens, err := parsePointer(fieldPointer(field))
+	if err != nil {
+		return nil, err
+	}
+	return rowField(tokens), nil
+}
+
+// get reads the field from row, with ok=false when it is missing
+func (f rowField) get(row interface{}) (interface{}, bool) {
+	v, err := pointerGet(row, f)
+	return v, err == nil
+}
+
+// numericValue reads a finite number, accepting numeric strings such as
+// CSV cells
+func numericValue(v interface{}) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	default:
+		return 0, false
+	}
+	return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// groupKey identifies a grouping value by its canonical JSON, so the
+// string "1" and the number 1 form different groups. A missing field
+// groups with null, as in SQL.
+func groupKey(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// groupKeyRank orders group values by type: null, booleans, numbers,
+// strings, then arrays and objects
+func groupKeyRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64, int:
+		return 2
+	case string:
+		return 3
+	}
+	return 4
+}
+
+// compareGroupValues orders group values by type, then numbers
+// numerically, strings and other values lexically by their canonical keys
+func compareGroupValues(a, b interface{}, keyA, keyB string) int {
+	ra, rb := groupKeyRank(a), groupKeyRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	if ra == 2 {
+		fa, _ := numericValue(a)
+		fb, _ := numericValue(b)
+		return compareFloats(fa, fb)
+	}
+	return strings.Compare(keyA, keyB)
+}
+
+// valueGroup collects one group's rows
+type valueGroup struct {
+	key     string
+	value   interface{}
+	rows    int
+	values  []float64
+	skipped int
+}
+
+// groupRows splits rows by the value of groupBy, returning the groups in
+// order of first appearance and each row's group
+func groupRows(rows []interface{}, groupBy rowField) ([]*valueGroup, []*valueGroup, error) {
+	order := []*valueGroup{}
+	byKey := make(map[string]*valueGroup)
+	rowGroups := make([]*valueGroup, len(rows))
+	for i, row := range rows {
+		value, _ := groupBy.get(row)
+		key, err := groupKey(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("row %d: group value: %w", i, err)
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &valueGroup{key: key, value: value}
+			byKey[key] = g
+			order = append(order, g)
+		}
+		g.rows++
+		rowGroups[i] = g
+	}
+	return order, rowGroups, nil
+}
+
+// GroupedStats groups an array of objects by the value of one field and
+// summarizes a numeric field per group with CalculateStatistics, like SQL's
+// GROUP BY with aggregates. Values that are missing or not numeric are
+// skipped and counted per group. Groups are sorted by sortBy (key, rows,
+// count, sum, mean, median, min, max or stddev) in ascending or descending
+// order; groups without numeric values sort last.
+func (dp *DataProcessor) GroupedStats(rows []interface{}, groupByField, valueField, sortBy string, descending bool) (map[string]interface{}, error) {
+	groupBy, err := parseRowField(groupByField)
+	if err != nil {
+		return nil, fmt.Errorf("group by: %w", err)
+	}
+	valueOf, err := parseRowField(valueField)
+	if err != nil {
+		return nil, fmt.Errorf("value field: %w", err)
+	}
+	if sortBy == "" {
+		sortBy = "key"
+	}
+	statKey, isStat := groupStatsSortKeys[sortBy]
+	if !isStat && sortBy != "key" && sortBy != "rows" {
+		return nil, fmt.Errorf("unsupported sort key %q (expected key, rows, count, sum, mean, median, min, max or stddev)", sortBy)
+	}
+
+	groups, rowGroups, err := groupRows(rows, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	skipped := 0
+	for i, row := range rows {
+		g := rowGroups[i]
+		raw, _ := valueOf.get(row)
+		if f, ok := numericValue(raw); ok {
+			g.values = append(g.values, f)
+		} else {
+			g.skipped++
+			skipped++
+		}
+	}
+
+	stats := make(map[*valueGroup]map[string]interface{}, len(groups))
+	for _, g := range groups {
+		if len(g.values) > 0 {
+			s := dp.CalculateStatistics(g.values)
+			delete(s, "processingTime")
+			stats[g] = s
+		}
+	}
+
+	sortValue := func(g *valueGroup) float64 {
+		switch v := stats[g][statKey].(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		}
+		return 0
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		var c int
+		switch {
+		case sortBy == "key":
+			c = compareGroupValues(a.value, b.value, a.key, b.key)
+		case sortBy == "rows":
+			c = a.rows - b.rows
+		case stats[a] == nil || stats[b] == nil:
+			// groups without values sort last in either order
+			return stats[a] != nil && stats[b] == nil
+		default:
+			c = compareFloats(sortValue(a), sortValue(b))
+		}
+		if descending {
+			c = -c
+		}
+		return c < 0
+	})
+
+	list := make([]interface{}, len(groups))
+	for i, g := range groups {
+		var groupStats interface{}
+		if s := stats[g]; s != nil {
+			groupStats = s
+		}
+		list[i] = map[string]interface{}{
+			"key":     g.value,
+			"rows":    g.rows,
+			"skipped": g.skipped,
+			"stats":   groupStats,
+		}
+	}
+
+	return map[string]interface{}{
+		"groupBy":    groupByField,
+		"field":      valueField,
+		"sortBy":     sortBy,
+		"groups":     list,
+		"groupCount": len(groups),
+		"rowCount":   len(rows),
+		"skipped":    skipped,
+	}, nil
+}
+
+// compareFloats returns -1, 0 or 1 as a is less than, equal to or greater
+// than b
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// groupKeys returns the key of each group in a GroupedStats result
+func groupKeys(result map[string]interface{}) []interface{} {
+	groups := result["groups"].([]interface{})
+	keys := make([]interface{}, len(groups))
+	for i, g := range groups {
+		keys[i] = g.(map[string]interface{})["key"]
+	}
+	return keys
+}
+
+func salesRows() []interface{} {
+	row := func(region interface{}, amount interface{}) interface{} {
+		r := map[string]interface{}{"amount": amount}
+		if region != nil {
+			r["region"] = region
+		}
+		return r
+	}
+	return []interface{}{
+		row("west", 10.0),
+		row("east", 5.0),
+		row("west", 30.0),
+		row("north", "n/a"),
+		row("east", "7.5"),
+		row(nil, 1.0),
+		row("west", nil),
+	}
+}
+
+func TestGroupedStats(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GroupedStats(salesRows(), "region", "amount", "", false)
+	if err != nil {
+		t.Fatalf("GroupedStats: %v", err)
+	}
+	if result["groupCount"] != 4 || result["rowCount"] != 7 || result["skipped"] != 2 || result["sortBy"] != "key" {
+		t.Errorf("result = %v", result)
+	}
+	// a missing group field groups as null, which sorts first
+	if got, want := groupKeys(result), []interface{}{nil, "east", "north", "west"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+
+	groups := result["groups"].([]interface{})
+	east := groups[1].(map[string]interface{})
+	stats := east["stats"].(map[string]interface{})
+	// numeric strings are counted
+	if east["rows"] != 2 || east["skipped"] != 0 || stats["sum"] != 12.5 || stats["count"] != 2 {
+		t.Errorf("east = %v", east)
+	}
+	if _, ok := stats["processingTime"]; ok {
+		t.Error("processingTime included in group stats")
+	}
+	north := groups[2].(map[string]interface{})
+	if north["stats"] != nil || north["skipped"] != 1 {
+		t.Errorf("north = %v", north)
+	}
+	west := groups[3].(map[string]interface{})
+	if west["rows"] != 3 || west["skipped"] != 1 || west["stats"].(map[string]interface{})["mean"] != 20.0 {
+		t.Errorf("west = %v", west)
+	}
+}
+
+func TestGroupedStatsSort(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		sortBy     string
+		descending bool
+		want       []interface{}
+	}{
+		{"key", true, []interface{}{"west", "north", "east", nil}},
+		{"rows", false, []interface{}{"north", nil, "east", "west"}},
+		{"sum", false, []interface{}{nil, "east", "west", "north"}},
+		// groups without values stay last when descending
+		{"sum", true, []interface{}{"west", "east", nil, "north"}},
+		{"stddev", true, []interface{}{"west", "east", nil, "north"}},
+		// ties keep the order of first appearance
+		{"count", false, []interface{}{nil, "west", "east", "north"}},
+	}
+	for _, tt := range tests {
+		result, err := dp.GroupedStats(salesRows(), "region", "amount", tt.sortBy, tt.descending)
+		if err != nil {
+			t.Fatalf("GroupedStats(%s): %v", tt.sortBy, err)
+		}
+		if got := groupKeys(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sortBy %s descending %v = %v, want %v", tt.sortBy, tt.descending, got, tt.want)
+		}
+	}
+}
+
+func TestGroupedStatsKeyTypes(t *testing.T) {
+	dp := NewDataProcessor()
+	rows := []interface{}{
+		map[string]interface{}{"k": "1", "v": 1.0},
+		map[string]interface{}{"k": 10.0, "v": 1.0},
+		map[string]interface{}{"k": 2.0, "v": 1.0},
+		map[string]interface{}{"k": true, "v": 1.0},
+		map[string]interface{}{"k": 1.0, "v": 1.0},
+		map[string]interface{}{"k": []interface{}{1.0}, "v": 1.0},
+	}
+	result, err := dp.GroupedStats(rows, "k", "v", "key", false)
+	if err != nil {
+		t.Fatalf("GroupedStats: %v", err)
+	}
+	// the string "1" and the number 1 are different groups; numbers sort
+	// numerically
+	want := []interface{}{true, 1.0, 2.0, 10.0, "1", []interface{}{1.0}}
+	if got := groupKeys(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+}
+
+func TestGroupedStatsNestedFields(t *testing.T) {
+	dp := NewDataProcessor()
+	rows := []interface{}{
+		map[string]interface{}{"user": map[string]interface{}{"team": "a"}, "score": map[string]interface{}{"total": 3.0}},
+		map[string]interface{}{"user": map[string]interface{}{"team": "a"}, "score": map[string]interface{}{"total": 5.0}},
+	}
+	result, err := dp.GroupedStats(rows, "user.team", "/score/total", "", false)
+	if err != nil {
+		t.Fatalf("GroupedStats: %v", err)
+	}
+	groups := result["groups"].([]interface{})
+	if len(groups) != 1 || groups[0].(map[string]interface{})["stats"].(map[string]interface{})["sum"] != 8.0 {
+		t.Errorf("groups = %v", groups)
+	}
+}
+
+func TestGroupedStatsErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	if _, err := dp.GroupedStats(salesRows(), "", "amount", "", false); err == nil {
+		t.Error("expected an error without a group-by field")
+	}
+	if _, err := dp.GroupedStats(salesRows(), "region", "", "", false); err == nil {
+		t.Error("expected an error without a value field")
+	}
+	if _, err := dp.GroupedStats(salesRows(), "region", "amount", "mode", false); err == nil {
+		t.Error("expected an error for an unknown sort key")
+	}
+}
+
+func TestNumericValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want float64
+		ok   bool
+	}{
+		{2.5, 2.5, true},
+		{3, 3, true},
+		{" 4.5 ", 4.5, true},
+		{"1e3", 1000, true},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+		{"abc", 0, false},
+		{true, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := numericValue(tt.in)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("numericValue(%v) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
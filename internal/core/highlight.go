@@ -0,0 +1,349 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxHighlightLength caps the characters HighlightCode tokenizes
+const MaxHighlightLength = 1 << 20
+
+// Token types produced by HighlightCode
+const (
+	TokenKeyword     = "keyword"
+	TokenLiteral     = "literal"
+	TokenString      = "string"
+	TokenComment     = "comment"
+	TokenNumber      = "number"
+	TokenIdentifier  = "identifier"
+	TokenProperty    = "property"
+	TokenOperator    = "operator"
+	TokenPunctuation = "punctuation"
+)
+
+// CodeToken is a span of source runes [Start, End)
+type CodeToken struct {
+	Type  string
+	Start int
+	End   int
+	// Unterminated marks a string or comment that runs to the end of the
+	// input (or line) without closing
+	Unterminated bool
+}
+
+// lexerSpec describes the lexical rules of a language
+type lexerSpec struct {
+	keywords     map[string]bool
+	literals     map[string]bool
+	lineComments []string
+	blockComment [2]string
+	// quotes close on the same line; multiline quotes may span lines
+	quotes          string
+	multilineQuotes string
+	escapes         bool
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// lexers holds the supported languages
+var lexers = map[string]*lexerSpec{
+	"json": {
+		literals: wordSet("true false null"),
+		quotes:   `"`,
+		escapes:  true,
+	},
+	"go": {
+		keywords: wordSet(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var`),
+		literals:        wordSet("true false nil iota"),
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          `"'`,
+		multilineQuotes: "`",
+		escapes:         true,
+	},
+	"javascript": {
+		keywords: wordSet(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while with yield`),
+		literals:        wordSet("true false null undefined NaN Infinity"),
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          `"'`,
+		multilineQuotes: "`",
+		escapes:         true,
+	},
+	"generic": {
+		literals:        wordSet("true false null nil None True False"),
+		lineComments:    []string{"//", "#"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          `"'`,
+		multilineQuotes: "`",
+		escapes:         true,
+	},
+}
+
+// languageAliases maps common names to a lexer
+var languageAliases = map[string]string{
+	"js": "javascript", "jsx": "javascript", "mjs": "javascript", "ts": "javascript", "typescript": "javascript",
+	"golang": "go", "text": "generic", "plain": "generic", "": "generic",
+}
+
+// highlightOperators are grouped greedily, longest first
+var highlightOperators = []string{
+	">>>=", "...", "===", "!==", "<<=", ">>=", ">>>", "&^=", "**=", "&&=", "||=", "??=",
+	"==", "!=", "<=", ">=", "&&", "||", "++", "--", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=",
+	"<<", ">>", "&^", ":=", "<-", "=>", "**", "??", "?.",
+}
+
+// TokenizeCode splits src into typed tokens for language (json, go,
+// javascript or generic). Whitespace is not returned. Unterminated strings
+// and comments become a single token to the end of the line or input
+// rather than an error.
+func TokenizeCode(ctx context.Context, src, language string) ([]CodeToken, string, error) {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := languageAliases[lang]; ok {
+		lang = alias
+	}
+	spec, ok := lexers[lang]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported language %q (expected json, go, javascript or generic)", language)
+	}
+	runes := []rune(src)
+	if len(runes) > MaxHighlightLength {
+		return nil, "", fmt.Errorf("code is limited to %d characters", MaxHighlightLength)
+	}
+
+	has := func(i int, s string) bool {
+		if s == "" || i+len([]rune(s)) > len(runes) {
+			return false
+		}
+		return string(runes[i:i+len([]rune(s))]) == s
+	}
+
+	tokens := []CodeToken{}
+	// tokens can step over any fixed index, so check against a moving mark
+	nextCheck := 0
+	for i := 0; i < len(runes); {
+		if i >= nextCheck {
+			if err := ctx.Err(); err != nil {
+				return nil, "", err
+			}
+			ReportProgress(ctx, i, len(runes))
+			nextCheck = i + 4096
+		}
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+
+		case has(i, spec.blockComment[0]):
+			i += len([]rune(spec.blockComment[0]))
+			closed := false
+			for i < len(runes) {
+				if has(i, spec.blockComment[1]) {
+					i += len([]rune(spec.blockComment[1]))
+					closed = true
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, CodeToken{Type: TokenComment, Start: start, End: i, Unterminated: !closed})
+			continue
+		}
+
+		if lineComment := func() bool {
+			for _, prefix := range spec.lineComments {
+				if has(i, prefix) {
+					return true
+				}
+			}
+			return false
+		}(); lineComment {
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			tokens = append(tokens, CodeToken{Type: TokenComment, Start: start, End: i})
+			continue
+		}
+
+		if strings.ContainsRune(spec.quotes, r) || strings.ContainsRune(spec.multilineQuotes, r) {
+			multiline := strings.ContainsRune(spec.multilineQuotes, r)
+			// Go raw strings have no escapes
+			escapes := spec.escapes && !(lang == "go" && r == '`')
+			i++
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				if escapes && c == '\\' && i+1 < len(runes) {
+					i += 2
+					continue
+				}
+				if c == '\n' && !multiline {
+					break
+				}
+				i++
+				if c == r {
+					closed = true
+					break
+				}
+			}
+			typ := TokenString
+			if lang == "json" && closed && nextNonSpace(runes, i) == ':' {
+				typ = TokenProperty
+			}
+			tokens = append(tokens, CodeToken{Type: typ, Start: start, End: i, Unterminated: !closed})
+			continue
+		}
+
+		operator := ""
+		for _, op := range highlightOperators {
+			if has(i, op) {
+				operator = op
+				break
+			}
+		}
+
+		switch {
+		case operator != "":
+			i += len(operator)
+			tokens = append(tokens, CodeToken{Type: TokenOperator, Start: start, End: i})
+
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]),
+			// JSON has no operators, so a minus sign belongs to the number
+			lang == "json" && r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			i = scanNumber(runes, i+1)
+			tokens = append(tokens, CodeToken{Type: TokenNumber, Start: start, End: i})
+
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+			for i < len(runes) && (runes[i] == '_' || runes[i] == '$' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			word := string(runes[start:i])
+			typ := TokenIdentifier
+			switch {
+			case spec.keywords[word]:
+				typ = TokenKeyword
+			case spec.literals[word]:
+				typ = TokenLiteral
+			}
+			tokens = append(tokens, CodeToken{Type: typ, Start: start, End: i})
+
+		case strings.ContainsRune("()[]{},;:.", r):
+			i++
+			tokens = append(tokens, CodeToken{Type: TokenPunctuation, Start: start, End: i})
+
+		default:
+			i++
+			tokens = append(tokens, CodeToken{Type: TokenOperator, Start: start, End: i})
+		}
+	}
+	return tokens, lang, nil
+}
+
+// nextNonSpace returns the first non-space rune at or after i, or 0
+func nextNonSpace(runes []rune, i int) rune {
+	for ; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return 0
+}
+
+// scanNumber consumes a numeric literal: decimal, hex, octal or binary,
+// with underscores, fractions, exponents and suffixes such as 10n or 2i
+func scanNumber(runes []rune, i int) int {
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case unicode.IsDigit(c) || unicode.IsLetter(c) || c == '_' || c == '.':
+			// an exponent may be followed by a sign
+			if (c == 'e' || c == 'E' || c == 'p' || c == 'P') && i+1 < len(runes) &&
+				(runes[i+1] == '+' || runes[i+1] == '-') && !isHexPrefixed(runes, i) {
+				i += 2
+				continue
+			}
+			if c == '.' && i+1 < len(runes) && runes[i+1] == '.' {
+				// a range or spread operator, not a fraction
+				return i
+			}
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isHexPrefixed reports whether the number containing position i started
+// with 0x, where e is a digit rather than an exponent
+func isHexPrefixed(runes []rune, i int) bool {
+	j := i
+	for j > 0 && (unicode.IsDigit(runes[j-1]) || unicode.IsLetter(runes[j-1]) || runes[j-1] == '_' || runes[j-1] == '.') {
+		j--
+	}
+	return j+1 < len(runes) && runes[j] == '0' && (runes[j+1] == 'x' || runes[j+1] == 'X') &&
+		runes[i] != 'p' && runes[i] != 'P'
+}
+
+// utf16Offsets maps each rune index, and the end, to its offset in
+// UTF-16 code units, the unit of JavaScript string indexes
+func utf16Offsets(runes []rune) []int {
+	offsets := make([]int, len(runes)+1)
+	for i, r := range runes {
+		n := 1
+		if r > 0xFFFF {
+			// encoded as a surrogate pair
+			n = 2
+		}
+		offsets[i+1] = offsets[i] + n
+	}
+	return offsets
+}
+
+// HighlightCode tokenizes a snippet for syntax highlighting. Token offsets
+// are UTF-16 code units so they can be passed straight to String.slice.
+func (dp *DataProcessor) HighlightCode(ctx context.Context, src, language string) (map[string]interface{}, error) {
+	tokens, lang, err := TokenizeCode(ctx, src, language)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(src)
+	offsets := utf16Offsets(runes)
+
+	list := make([]interface{}, len(tokens))
+	counts := map[string]interface{}{}
+	for i, t := range tokens {
+		token := map[string]interface{}{
+			"type":  t.Type,
+			"start": offsets[t.Start],
+			"end":   offsets[t.End],
+			"text":  string(runes[t.Start:t.End]),
+		}
+		if t.Unterminated {
+			token["unterminated"] = true
+		}
+		list[i] = token
+		n, _ := counts[t.Type].(int)
+		counts[t.Type] = n + 1
+	}
+
+	return map[string]interface{}{
+		"language": lang,
+		"tokens":   list,
+		"counts":   counts,
+	}, nil
+}
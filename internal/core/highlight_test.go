@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tokenStrings renders tokens as type:text for comparison
+func tokenStrings(src string, tokens []CodeToken) []string {
+	runes := []rune(src)
+	out := []string{}
+	for _, t := range tokens {
+		s := t.Type + ":" + string(runes[t.Start:t.End])
+		if t.Unterminated {
+			s += "!"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestTokenizeCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		src      string
+		want     []string
+	}{
+		{
+			name:     "json",
+			language: "JSON",
+			src:      `{"a": [1, -2.5e3, true, null, "x\"y"]}`,
+			want: []string{"punctuation:{", `property:"a"`, "punctuation::", "punctuation:[", "number:1", "punctuation:,",
+				"number:-2.5e3", "punctuation:,", "literal:true", "punctuation:,", "literal:null", "punctuation:,",
+				`string:"x\"y"`, "punctuation:]", "punctuation:}"},
+		},
+		{
+			name:     "go",
+			language: "golang",
+			src:      "func f() { x := `a\\n\nb` // done\n\treturn 0x1e-2 }",
+			want: []string{"keyword:func", "identifier:f", "punctuation:(", "punctuation:)", "punctuation:{",
+				"identifier:x", "operator::=", "string:`a\\n\nb`", "comment:// done", "keyword:return",
+				"number:0x1e", "operator:-", "number:2", "punctuation:}"},
+		},
+		{
+			name:     "javascript",
+			language: "ts",
+			src:      "const big = 10n ** 2; a?.b ?? [...xs] /* c */ === undefined",
+			want: []string{"keyword:const", "identifier:big", "operator:=", "number:10n", "operator:**", "number:2",
+				"punctuation:;", "identifier:a", "operator:?.", "identifier:b", "operator:??", "punctuation:[",
+				"operator:...", "identifier:xs", "punctuation:]", "comment:/* c */", "operator:===", "literal:undefined"},
+		},
+		{
+			name: "generic",
+			src:  "# note\nx = 1.5e+3 @ None",
+			want: []string{"comment:# note", "identifier:x", "operator:=", "number:1.5e+3", "operator:@", "literal:None"},
+		},
+		{
+			name:     "unterminated",
+			language: "go",
+			src:      "s := \"open\nt /* never closed",
+			want:     []string{"identifier:s", "operator::=", `string:"open!`, "identifier:t", "comment:/* never closed!"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, _, err := TokenizeCode(context.Background(), tt.src, tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tokenStrings(tt.src, tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeCodeErrors(t *testing.T) {
+	if _, _, err := TokenizeCode(context.Background(), "x", "cobol"); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+	if _, _, err := TokenizeCode(context.Background(), strings.Repeat("x", MaxHighlightLength+1), "go"); err == nil {
+		t.Error("expected an error for code over the limit")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := TokenizeCode(ctx, "x", "go"); err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestHighlightCodeOffsets(t *testing.T) {
+	// 😀 is two UTF-16 code units, so offsets after it shift by one
+	src := `"😀é" + x`
+	dp := NewDataProcessor()
+	result, err := dp.HighlightCode(context.Background(), src, "js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["language"] != "javascript" {
+		t.Errorf("language = %v", result["language"])
+	}
+
+	tokens := result["tokens"].([]interface{})
+	wantSpans := [][2]int{{0, 5}, {6, 7}, {8, 9}}
+	for i, span := range wantSpans {
+		token := tokens[i].(map[string]interface{})
+		if token["start"] != span[0] || token["end"] != span[1] {
+			t.Errorf("token %d (%v) spans %v-%v, want %v", i, token["text"], token["start"], token["end"], span)
+		}
+	}
+	if counts := result["counts"].(map[string]interface{}); counts["string"] != 1 || counts["operator"] != 1 || counts["identifier"] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+}
+
+func TestTokenizeCodeProgress(t *testing.T) {
+	// tokens of 1000 runes step over every multiple of the check interval
+	src := strings.Repeat(`"`+strings.Repeat("x", 998)+`" `, 30)
+	reports := 0
+	ctx := WithProgress(context.Background(), func(done, total int) { reports++ })
+	if _, _, err := TokenizeCode(ctx, src, "generic"); err != nil {
+		t.Fatal(err)
+	}
+	if reports < 5 {
+		t.Errorf("progress reported %d times, want one per 4096 runes", reports)
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ibanLengths is the IBAN length of each country in the SWIFT IBAN
+// registry
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "QA": 29, "RO": 24, "RS": 22, "RU": 33, "SA": 24,
+	"SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28, "TL": 23,
+	"TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// BankCodeError explains why an IBAN or BIC failed validation. Code is
+// "characters", "country", "length", "checksum" or "format".
+type BankCodeError struct {
+	Code    string
+	Message string
+}
+
+func (e *BankCodeError) Error() string {
+	return e.Message
+}
+
+// compactBankCode removes spaces and hyphens and upper-cases the rest
+func compactBankCode(code string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '\t':
+			return -1
+		}
+		return r
+	}, code))
+}
+
+func isUpperAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpperAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ibanMod97 computes the ISO 7064 MOD 97-10 remainder of an IBAN with its
+// first four characters moved to the end and letters expanded to 10-35
+func ibanMod97(iban string) int {
+	rearranged := iban[4:] + iban[:4]
+	rem := 0
+	for i := 0; i < len(rearranged); i++ {
+		c := rearranged[i]
+		if c >= 'A' && c <= 'Z' {
+			v := int(c-'A') + 10
+			rem = (rem*100 + v) % 97
+		} else {
+			rem = (rem*10 + int(c-'0')) % 97
+		}
+	}
+	return rem
+}
+
+// CheckIBAN validates a compact, upper-case IBAN: its characters, country,
+// the registered length for that country and the mod-97 checksum
+func CheckIBAN(iban string) error {
+	if len(iban) < 4 || !isUpperAlnum(iban) {
+		return &BankCodeError{"characters", "IBAN must contain only letters and digits and start with a country code and check digits"}
+	}
+	country := iban[:2]
+	if !isUpperAlpha(country) || iban[2] < '0' || iban[2] > '9' || iban[3] < '0' || iban[3] > '9' {
+		return &BankCodeError{"format", "IBAN must start with a two-letter country code and two check digits"}
+	}
+	want, ok := ibanLengths[country]
+	if !ok {
+		return &BankCodeError{"country", fmt.Sprintf("%s does not use IBANs", country)}
+	}
+	if len(iban) != want {
+		return &BankCodeError{"length", fmt.Sprintf("%s IBANs have %d characters, got %d", country, want, len(iban))}
+	}
+	if ibanMod97(iban) != 1 {
+		return &BankCodeError{"checksum", "IBAN check digits do not match"}
+	}
+	return nil
+}
+
+// FormatIBAN groups a compact IBAN into blocks of four for display
+func FormatIBAN(iban string) string {
+	var b strings.Builder
+	for i := 0; i < len(iban); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(iban[i:min(i+4, len(iban))])
+	}
+	return b.String()
+}
+
+// ValidateIBAN checks an IBAN, ignoring spaces and case. Invalid IBANs are
+// reported in the result rather than as an error.
+func (dp *DataProcessor) ValidateIBAN(input string) (map[string]interface{}, error) {
+	iban := compactBankCode(input)
+	err := CheckIBAN(iban)
+
+	result := map[string]interface{}{
+		"valid":      err == nil,
+		"electronic": iban,
+	}
+	if len(iban) >= 2 && isUpperAlpha(iban[:2]) {
+		result["country"] = iban[:2]
+		if n, ok := ibanLengths[iban[:2]]; ok {
+			result["expectedLength"] = n
+		}
+	}
+	if err != nil {
+		result["error"] = err.Error()
+		result["errorCode"] = err.(*BankCodeError).Code
+		return result, nil
+	}
+
+	result["checkDigits"] = iban[2:4]
+	result["bban"] = iban[4:]
+	result["formatted"] = FormatIBAN(iban)
+	return result, nil
+}
+
+// CheckBIC validates a compact, upper-case BIC (ISO 9362): a four-letter
+// institution code, two-letter country code, two-character location code
+// and an optional three-character branch code
+func CheckBIC(bic string) error {
+	if !isUpperAlnum(bic) {
+		return &BankCodeError{"characters", "BIC must contain only letters and digits"}
+	}
+	if len(bic) != 8 && len(bic) != 11 {
+		return &BankCodeError{"length", fmt.Sprintf("BIC must have 8 or 11 characters, got %d", len(bic))}
+	}
+	if !isUpperAlpha(bic[4:6]) {
+		return &BankCodeError{"format", "BIC characters 5-6 must be a country code"}
+	}
+	return nil
+}
+
+// ValidateBIC checks a BIC, ignoring spaces and case, and splits it into
+// its parts. Invalid BICs are reported in the result rather than as an
+// error.
+func (dp *DataProcessor) ValidateBIC(input string) (map[string]interface{}, error) {
+	bic := compactBankCode(input)
+	err := CheckBIC(bic)
+
+	result := map[string]interface{}{
+		"valid": err == nil,
+		"bic":   bic,
+	}
+	if err != nil {
+		result["error"] = err.Error()
+		result["errorCode"] = err.(*BankCodeError).Code
+		return result, nil
+	}
+
+	branch := "XXX"
+	if len(bic) == 11 {
+		branch = bic[8:]
+	}
+	result["institution"] = bic[:4]
+	result["country"] = bic[4:6]
+	result["location"] = bic[6:8]
+	result["branch"] = branch
+	result["primaryOffice"] = branch == "XXX"
+	// a location code ending in 0 marks a test and training BIC
+	result["test"] = bic[7] == '0'
+	result["bic11"] = bic[:8] + branch
+	return result, nil
+}
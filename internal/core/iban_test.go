@@ -0,0 +1,92 @@
+package core
+
+import "testing"
+
+func TestValidateIBAN(t *testing.T) {
+	tests := []struct {
+		input     string
+		valid     bool
+		errorCode string
+		formatted string
+	}{
+		{input: "GB82 WEST 1234 5698 7654 32", valid: true, formatted: "GB82 WEST 1234 5698 7654 32"},
+		{input: "de89-3704-0044-0532-0130-00", valid: true, formatted: "DE89 3704 0044 0532 0130 00"},
+		{input: "NO9386011117947", valid: true, formatted: "NO93 8601 1117 947"},
+		{input: "GB82WEST12345698765433", errorCode: "checksum"},
+		{input: "GB82WEST1234569876543", errorCode: "length"},
+		{input: "US64SVBKUS6S3300958879", errorCode: "country"},
+		{input: "1282WEST12345698765432", errorCode: "format"},
+		{input: "GBX2WEST12345698765432", errorCode: "format"},
+		{input: "GB82_WEST", errorCode: "characters"},
+		{input: "GB8", errorCode: "characters"},
+		{input: "", errorCode: "characters"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.ValidateIBAN(tt.input)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if result["valid"] != tt.valid {
+			t.Errorf("%q: valid = %v, want %v (%v)", tt.input, result["valid"], tt.valid, result["error"])
+			continue
+		}
+		if tt.valid && result["formatted"] != tt.formatted {
+			t.Errorf("%q: formatted = %v, want %q", tt.input, result["formatted"], tt.formatted)
+		}
+		if !tt.valid && result["errorCode"] != tt.errorCode {
+			t.Errorf("%q: errorCode = %v, want %q", tt.input, result["errorCode"], tt.errorCode)
+		}
+	}
+
+	result, _ := dp.ValidateIBAN("gb82west12345698765432")
+	if result["country"] != "GB" || result["expectedLength"] != 22 || result["checkDigits"] != "82" || result["bban"] != "WEST12345698765432" {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	tests := []struct {
+		input     string
+		valid     bool
+		errorCode string
+		branch    string
+		test      bool
+	}{
+		{input: "DEUTDEFF", valid: true, branch: "XXX"},
+		{input: "deut de ff 500", valid: true, branch: "500"},
+		{input: "NEDSZAJJXXX", valid: true, branch: "XXX"},
+		{input: "TESTDE20", valid: true, branch: "XXX", test: true},
+		{input: "DEUTDEF", errorCode: "length"},
+		{input: "DEUTDEFF5", errorCode: "length"},
+		{input: "DEUT12FF", errorCode: "format"},
+		{input: "DEUT_EFF", errorCode: "characters"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		result, err := dp.ValidateBIC(tt.input)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if result["valid"] != tt.valid {
+			t.Errorf("%q: valid = %v, want %v (%v)", tt.input, result["valid"], tt.valid, result["error"])
+			continue
+		}
+		if !tt.valid {
+			if result["errorCode"] != tt.errorCode {
+				t.Errorf("%q: errorCode = %v, want %q", tt.input, result["errorCode"], tt.errorCode)
+			}
+			continue
+		}
+		if result["branch"] != tt.branch || result["test"] != tt.test || result["primaryOffice"] != (tt.branch == "XXX") {
+			t.Errorf("%q: result = %v", tt.input, result)
+		}
+	}
+
+	result, _ := dp.ValidateBIC("DEUTDEFF")
+	if result["institution"] != "DEUT" || result["country"] != "DE" || result["location"] != "FF" || result["bic11"] != "DEUTDEFFXXX" {
+		t.Errorf("result = %v", result)
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"net"
+
+	"golang.org/x/net/idna"
+)
+
+// acePrefix marks a label encoded with Punycode
+const acePrefix = "xn--"
+
+// ToASCIIHost converts an internationalized host name to its ASCII (punycode)
+// form with the IDNA lookup profile, e.g. "Bücher.example" becomes
+// "xn--bcher-kva.example". IP addresses and empty hosts are returned as is.
+func ToASCIIHost(host string) (string, error) {
+	if host == "" || net.ParseIP(host) != nil {
+		return host, nil
+	}
+	return idna.Lookup.ToASCII(host)
+}
+
+// ToUnicodeHost converts punycode labels in a host name back to Unicode
+func ToUnicodeHost(host string) (string, error) {
+	if host == "" || net.ParseIP(host) != nil {
+		return host, nil
+	}
+	return idna.Lookup.ToUnicode(host)
+}
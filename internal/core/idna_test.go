@@ -0,0 +1,48 @@
+package core
+
+import "testing"
+
+func TestIDNHostRoundTrip(t *testing.T) {
+	tests := []struct {
+		host    string
+		ascii   string
+		unicode string
+	}{
+		{host: "bücher.example", ascii: "xn--bcher-kva.example", unicode: "bücher.example"},
+		{host: "Bücher.Example", ascii: "xn--bcher-kva.example", unicode: "bücher.example"},
+		{host: "例え.テスト", ascii: "xn--r8jz45g.xn--zckzah", unicode: "例え.テスト"},
+		{host: "xn--bcher-kva.example", ascii: "xn--bcher-kva.example", unicode: "bücher.example"},
+		{host: "münchen.de", ascii: "xn--mnchen-3ya.de", unicode: "münchen.de"},
+		{host: "example.com", ascii: "example.com", unicode: "example.com"},
+		{host: "127.0.0.1", ascii: "127.0.0.1", unicode: "127.0.0.1"},
+		{host: "::1", ascii: "::1", unicode: "::1"},
+		{host: "", ascii: "", unicode: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			ascii, err := ToASCIIHost(tt.host)
+			if err != nil {
+				t.Fatalf("ToASCIIHost: %v", err)
+			}
+			if ascii != tt.ascii {
+				t.Errorf("ToASCIIHost = %q, want %q", ascii, tt.ascii)
+			}
+			unicode, err := ToUnicodeHost(ascii)
+			if err != nil {
+				t.Fatalf("ToUnicodeHost: %v", err)
+			}
+			if unicode != tt.unicode {
+				t.Errorf("ToUnicodeHost = %q, want %q", unicode, tt.unicode)
+			}
+		})
+	}
+}
+
+func TestToASCIIHostInvalid(t *testing.T) {
+	for _, host := range []string{"exa mple.com", "-bad.example", "xn--a.example", "under_score.example"} {
+		if ascii, err := ToASCIIHost(host); err == nil {
+			t.Errorf("ToASCIIHost(%q) = %q, expected an error", host, ascii)
+		}
+	}
+}
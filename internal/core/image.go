@@ -0,0 +1,181 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+)
+
+// Image size limits, checked from the header before any pixels are decoded
+const (
+	MaxImageDimension = 8192
+	MaxImagePixels    = 16 * 1024 * 1024
+)
+
+// DecodeImage decodes PNG or JPEG bytes after checking the dimensions in
+// the header against MaxImageDimension and MaxImagePixels
+func DecodeImage(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("unsupported or corrupt image (expected PNG or JPEG): %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", fmt.Errorf("image has no pixels")
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension ||
+		config.Width*config.Height > MaxImagePixels {
+		return nil, "", fmt.Errorf("image is %dx%d; the limit is %d pixels per side and %d pixels in total",
+			config.Width, config.Height, MaxImageDimension, MaxImagePixels)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+	return img, format, nil
+}
+
+// ImageHistogram holds per-channel pixel counts
+type ImageHistogram struct {
+	Bins      int
+	Pixels    int
+	R, G, B   []int
+	A         []int
+	Luminance []int
+	sum       [5]float64
+}
+
+// histogramChannels lists the channel names in result order
+var histogramChannels = []string{"r", "g", "b", "a", "luminance"}
+
+func (h *ImageHistogram) channel(i int) []int {
+	return [][]int{h.R, h.G, h.B, h.A, h.Luminance}[i]
+}
+
+// ComputeHistogram counts 8-bit channel values of img into bins buckets.
+// Colours are un-premultiplied so transparent pixels keep their hue, and
+// luminance uses the Rec. 601 weights.
+func ComputeHistogram(ctx context.Context, img image.Image, bins int) (*ImageHistogram, error) {
+	if bins < 2 || bins > 256 {
+		return nil, fmt.Errorf("bins must be between 2 and 256")
+	}
+	h := &ImageHistogram{
+		Bins:      bins,
+		R:         make([]int, bins),
+		G:         make([]int, bins),
+		B:         make([]int, bins),
+		A:         make([]int, bins),
+		Luminance: make([]int, bins),
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ReportProgress(ctx, y-bounds.Min.Y, bounds.Dy())
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			lum := uint8(math.Round(0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)))
+			for i, v := range [5]uint8{c.R, c.G, c.B, c.A, lum} {
+				h.channel(i)[int(v)*bins/256]++
+				h.sum[i] += float64(v)
+			}
+		}
+	}
+	h.Pixels = bounds.Dx() * bounds.Dy()
+	return h, nil
+}
+
+// Mean returns the average 0-255 value of channel i (r, g, b, a, luminance)
+func (h *ImageHistogram) Mean(i int) float64 {
+	if h.Pixels == 0 {
+		return 0
+	}
+	return h.sum[i] / float64(h.Pixels)
+}
+
+// HistogramSimilarity compares the R, G and B histograms of two images by
+// normalized histogram intersection: 1 for identical colour distributions,
+// 0 for disjoint ones. Image sizes may differ.
+func HistogramSimilarity(a, b *ImageHistogram) (float64, [3]float64) {
+	var perChannel [3]float64
+	total := 0.0
+	for i := 0; i < 3; i++ {
+		ca, cb := a.channel(i), b.channel(i)
+		sum := 0.0
+		for j := range ca {
+			sum += math.Min(float64(ca[j])/float64(a.Pixels), float64(cb[j])/float64(b.Pixels))
+		}
+		perChannel[i] = sum
+		total += sum
+	}
+	return total / 3, perChannel
+}
+
+// ImageHistogram decodes an image and returns its per-channel histograms,
+// channel means and brightness (mean luminance from 0 to 1)
+func (dp *DataProcessor) ImageHistogram(ctx context.Context, data []byte, bins int) (map[string]interface{}, error) {
+	img, format, err := DecodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	h, err := ComputeHistogram(ctx, img, bins)
+	if err != nil {
+		return nil, err
+	}
+
+	histograms := make(map[string]interface{}, len(histogramChannels))
+	means := make(map[string]interface{}, len(histogramChannels))
+	for i, name := range histogramChannels {
+		counts := h.channel(i)
+		list := make([]interface{}, len(counts))
+		for j, n := range counts {
+			list[j] = n
+		}
+		histograms[name] = list
+		means[name] = h.Mean(i)
+	}
+
+	return map[string]interface{}{
+		"format":     format,
+		"width":      img.Bounds().Dx(),
+		"height":     img.Bounds().Dy(),
+		"pixels":     h.Pixels,
+		"bins":       bins,
+		"histograms": histograms,
+		"mean":       means,
+		"brightness": h.Mean(4) / 255,
+	}, nil
+}
+
+// ImageCompare returns the histogram similarity of two images
+func (dp *DataProcessor) ImageCompare(ctx context.Context, a, b []byte, bins int) (map[string]interface{}, error) {
+	var hists [2]*ImageHistogram
+	for i, data := range [][]byte{a, b} {
+		img, _, err := DecodeImage(data)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i+1, err)
+		}
+		if hists[i], err = ComputeHistogram(ctx, img, bins); err != nil {
+			return nil, err
+		}
+	}
+
+	similarity, perChannel := HistogramSimilarity(hists[0], hists[1])
+	return map[string]interface{}{
+		"similarity": similarity,
+		"channels": map[string]interface{}{
+			"r": perChannel[0],
+			"g": perChannel[1],
+			"b": perChannel[2],
+		},
+		"brightnessDelta": (hists[1].Mean(4) - hists[0].Mean(4)) / 255,
+		"bins":            bins,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+// encodePNG returns a w×h PNG filled by fill
+func encodePNG(t *testing.T, w, h int, fill func(x, y int) color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, fill(x, y))
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func solid(c color.Color) func(x, y int) color.Color {
+	return func(x, y int) color.Color { return c }
+}
+
+func TestDecodeImage(t *testing.T) {
+	if _, format, err := DecodeImage(encodePNG(t, 2, 2, solid(color.White))); err != nil || format != "png" {
+		t.Errorf("DecodeImage = %q, %v", format, err)
+	}
+	if _, _, err := DecodeImage([]byte("not an image")); err == nil || !strings.Contains(err.Error(), "expected PNG or JPEG") {
+		t.Errorf("err = %v, want an unsupported image error", err)
+	}
+	// the size limit is checked from the header
+	wide := encodePNG(t, MaxImageDimension+1, 1, solid(color.Black))
+	if _, _, err := DecodeImage(wide); err == nil || !strings.Contains(err.Error(), "the limit is") {
+		t.Errorf("err = %v, want a size limit error", err)
+	}
+}
+
+func TestImageHistogram(t *testing.T) {
+	// left half red, right half half-transparent blue
+	data := encodePNG(t, 4, 2, func(x, y int) color.Color {
+		if x < 2 {
+			return color.NRGBA{R: 255, A: 255}
+		}
+		return color.NRGBA{B: 255, A: 128}
+	})
+
+	dp := NewDataProcessor()
+	result, err := dp.ImageHistogram(context.Background(), data, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["format"] != "png" || result["width"] != 4 || result["height"] != 2 || result["pixels"] != 8 {
+		t.Errorf("result = %v", result)
+	}
+
+	histograms := result["histograms"].(map[string]interface{})
+	want := map[string][]interface{}{
+		"r": {4, 0, 0, 4},
+		"g": {8, 0, 0, 0},
+		"b": {4, 0, 0, 4},
+		"a": {0, 0, 4, 4},
+		// luminance of red is 76 and of blue is 29
+		"luminance": {4, 4, 0, 0},
+	}
+	for name, counts := range want {
+		got := histograms[name].([]interface{})
+		for i := range counts {
+			if got[i] != counts[i] {
+				t.Errorf("%s histogram = %v, want %v", name, got, counts)
+				break
+			}
+		}
+	}
+
+	means := result["mean"].(map[string]interface{})
+	if means["r"] != 127.5 || means["a"] != 191.5 {
+		t.Errorf("means = %v", means)
+	}
+	if brightness := result["brightness"].(float64); math.Abs(brightness-52.5/255) > 1e-9 {
+		t.Errorf("brightness = %v, want %v", brightness, 52.5/255)
+	}
+
+	for _, bins := range []int{1, 257} {
+		if _, err := dp.ImageHistogram(context.Background(), data, bins); err == nil {
+			t.Errorf("bins %d: expected an error", bins)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := dp.ImageHistogram(ctx, data, 4); err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestImageCompare(t *testing.T) {
+	red := encodePNG(t, 4, 4, solid(color.NRGBA{R: 255, A: 255}))
+	bigRed := encodePNG(t, 8, 2, solid(color.NRGBA{R: 255, A: 255}))
+	blue := encodePNG(t, 4, 4, solid(color.NRGBA{B: 255, A: 255}))
+	half := encodePNG(t, 4, 4, func(x, y int) color.Color {
+		if x < 2 {
+			return color.NRGBA{R: 255, A: 255}
+		}
+		return color.NRGBA{B: 255, A: 255}
+	})
+
+	tests := []struct {
+		name       string
+		a, b       []byte
+		similarity float64
+	}{
+		{"identical colours at different sizes", red, bigRed, 1},
+		{"red and blue", red, blue, 1.0 / 3},
+		{"half overlap", red, half, 2.0 / 3},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.ImageCompare(context.Background(), tt.a, tt.b, 16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result["similarity"].(float64); math.Abs(got-tt.similarity) > 1e-9 {
+				t.Errorf("similarity = %v, want %v", got, tt.similarity)
+			}
+		})
+	}
+
+	if _, err := dp.ImageCompare(context.Background(), red, []byte("x"), 16); err == nil || !strings.HasPrefix(err.Error(), "image 2:") {
+		t.Errorf("err = %v, want an image 2 error", err)
+	}
+}
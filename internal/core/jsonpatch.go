@@ -0,0 +1,353 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to target. Null values in
+// the patch delete the corresponding member; non-object patches replace the
+// target entirely.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	result := make(map[string]interface{}, len(targetObj))
+	for key, value := range targetObj {
+		result[key] = value
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = MergePatch(result[key], value)
+	}
+	return result
+}
+
+// ApplyPatch applies an RFC 6902 JSON Patch to doc and returns the new
+// document. Operations are applied to a copy, so doc is left unchanged when
+// any operation, including a failed test, returns an error.
+func ApplyPatch(doc interface{}, patch []interface{}) (interface{}, error) {
+	doc = deepCopyJSON(doc)
+
+	for i, raw := range patch {
+		op, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d: must be an object", i)
+		}
+
+		var err error
+		doc, err = applyPatchOp(doc, op)
+		if err != nil {
+			name, _ := op["op"].(string)
+			return nil, fmt.Errorf("operation %d (%s): %w", i, name, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOp(doc interface{}, op map[string]interface{}) (interface{}, error) {
+	name, _ := op["op"].(string)
+	pathStr, ok := op["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing path")
+	}
+	path, err := parsePointer(pathStr)
+	if err != nil {
+		return nil, err
+	}
+
+	value, hasValue := op["value"]
+	needsValue := name == "add" || name == "replace" || name == "test"
+	if needsValue && !hasValue {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch name {
+	case "add":
+		return pointerAdd(doc, path, deepCopyJSON(value))
+
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+		return doc, err
+
+	case "replace":
+		if doc, _, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, deepCopyJSON(value))
+
+	case "move", "copy":
+		fromStr, ok := op["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing from")
+		}
+		from, err := parsePointer(fromStr)
+		if err != nil {
+			return nil, err
+		}
+
+		var moved interface{}
+		if name == "move" {
+			if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+				return nil, fmt.Errorf("cannot move %q into one of its children", fromStr)
+			}
+			if doc, moved, err = pointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if moved, err = pointerGet(doc, from); err != nil {
+				return nil, err
+			}
+			moved = deepCopyJSON(moved)
+		}
+		return pointerAdd(doc, path, moved)
+
+	case "test":
+		actual, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, value) {
+			return nil, fmt.Errorf("test failed: value at %q does not match", pathStr)
+		}
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("unknown op %q", name)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token; "-" refers to the end of the array
+// and is only valid when allowEnd is set
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+// pointerGet returns the value at path
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	node := doc
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: member %q does not exist", token)
+			}
+			node = child
+		case []interface{}:
+			idx, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("path not found: cannot index into %s", valueType(node))
+		}
+	}
+	return node, nil
+}
+
+// pointerUpdate replaces the parent container of path's last token with the
+// result of fn, rebuilding the chain so that array changes are kept
+func pointerUpdate(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	token := path[0]
+	switch n := doc.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("path not found: member %q does not exist", token)
+		}
+		updated, err := pointerUpdate(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		idx, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := pointerUpdate(n[idx], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = updated
+		return n, nil
+	default:
+		return nil, fmt.Errorf("path not found: cannot index into %s", valueType(doc))
+	}
+}
+
+// pointerAdd inserts value at path, replacing object members and shifting
+// array elements
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			idx, err := arrayIndex(token, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[idx+1:], p[idx:])
+			p[idx] = value
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot add to %s", valueType(parent))
+		}
+	})
+}
+
+// pointerRemove deletes the value at path and returns it
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the document root")
+	}
+
+	var removed interface{}
+	doc, err := pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			value, ok := p[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: member %q does not exist", token)
+			}
+			removed = value
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			idx, err := arrayIndex(token, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = p[idx]
+			return append(p[:idx:idx], p[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove from %s", valueType(parent))
+		}
+	})
+	return doc, removed, err
+}
+
+// deepCopyJSON copies decoded JSON so later edits don't alias the original
+func deepCopyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			out[key] = deepCopyJSON(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = deepCopyJSON(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// patchResult encodes a patched document for the handler response
+func patchResult(doc interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"result": doc,
+		"json":   string(encoded),
+	}, nil
+}
+
+// MergeJSON applies an RFC 7386 merge patch to a JSON document
+func (dp *DataProcessor) MergeJSON(target, patch string) (map[string]interface{}, error) {
+	var targetDoc, patchDoc interface{}
+	if err := json.Unmarshal([]byte(target), &targetDoc); err != nil {
+		return nil, fmt.Errorf("invalid target JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(patch), &patchDoc); err != nil {
+		return nil, fmt.Errorf("invalid patch JSON: %w", err)
+	}
+
+	return patchResult(MergePatch(targetDoc, patchDoc))
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to a JSON document
+func (dp *DataProcessor) ApplyJSONPatch(document, patch string) (map[string]interface{}, error) {
+	var doc interface{}
+	var ops []interface{}
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, fmt.Errorf("invalid document JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(patch), &ops); err != nil {
+		return nil, fmt.Errorf("invalid patch: must be a JSON array of operations: %w", err)
+	}
+
+	result, err := ApplyPatch(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+	return patchResult(result)
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeTestJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test JSON %s: %v", s, err)
+	}
+	return v
+}
+
+// TestMergePatch runs the examples from RFC 7386 appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		target := decodeTestJSON(t, tt.target)
+		got := MergePatch(target, decodeTestJSON(t, tt.patch))
+		if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s + %s = %v, want %v", tt.target, tt.patch, got, want)
+		}
+		if !reflect.DeepEqual(target, decodeTestJSON(t, tt.target)) {
+			t.Errorf("%s + %s modified the target", tt.target, tt.patch)
+		}
+	}
+}
+
+// TestApplyPatch runs examples from RFC 6902 appendix A
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"test passes", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"escaped keys", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+		{"add array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"copy", `{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`, `{"a":{"b":[1]},"c":{"b":[1,2]}}`},
+		{"replace root", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"move to itself", `{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeTestJSON(t, tt.doc)
+			var ops []interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ApplyPatch(doc, ops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(doc, decodeTestJSON(t, tt.doc)) {
+				t.Error("the input document was modified")
+			}
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, err string
+	}{
+		{"test fails", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "test failed"},
+		{"missing member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "path not found"},
+		{"index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":1}]`, "out of range"},
+		{"leading zero index", `{"foo":["a","b"]}`, `[{"op":"remove","path":"/foo/01"}]`, "invalid array index"},
+		{"end index outside add", `{"foo":["a"]}`, `[{"op":"remove","path":"/foo/-"}]`, "invalid array index"},
+		{"remove root", `{}`, `[{"op":"remove","path":""}]`, "document root"},
+		{"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, "into one of its children"},
+		{"unknown op", `{}`, `[{"op":"frobnicate","path":"/a"}]`, "unknown op"},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, "missing value"},
+		{"missing from", `{}`, `[{"op":"copy","path":"/a"}]`, "missing from"},
+		{"missing path", `{}`, `[{"op":"remove"}]`, "missing path"},
+		{"bad pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, "must start with /"},
+		{"not an object", `{}`, `["add"]`, "must be an object"},
+		{"index into scalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":1}]`, "cannot add to number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ApplyPatch(decodeTestJSON(t, tt.doc), ops)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %v does not mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	doc := decodeTestJSON(t, `{"a":[1,2]}`)
+	ops := []interface{}{
+		map[string]interface{}{"op": "remove", "path": "/a/0"},
+		map[string]interface{}{"op": "test", "path": "/a/0", "value": 3.0},
+	}
+	if _, err := ApplyPatch(doc, ops); err == nil {
+		t.Fatal("expected the test operation to fail")
+	}
+	if !reflect.DeepEqual(doc, decodeTestJSON(t, `{"a":[1,2]}`)) {
+		t.Errorf("a failed patch modified the document: %v", doc)
+	}
+}
+
+func TestPatchProcessor(t *testing.T) {
+	dp := NewDataProcessor()
+
+	result, err := dp.MergeJSON(`{"a":1,"b":2}`, `{"b":null,"c":3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["json"] != `{"a":1,"c":3}` {
+		t.Errorf("merge json = %v", result["json"])
+	}
+
+	result, err = dp.ApplyJSONPatch(`[1,2]`, `[{"op":"add","path":"/0","value":0}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["json"] != `[0,1,2]` {
+		t.Errorf("patch json = %v", result["json"])
+	}
+
+	for _, args := range [][2]string{{`{`, `{}`}, {`{}`, `{`}} {
+		if _, err := dp.MergeJSON(args[0], args[1]); err == nil {
+			t.Errorf("MergeJSON(%s, %s): expected an error", args[0], args[1])
+		}
+	}
+	if _, err := dp.ApplyJSONPatch(`{}`, `{"op":"add"}`); err == nil {
+		t.Error("expected an error for a patch that is not an array")
+	}
+}
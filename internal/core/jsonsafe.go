@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSONLimits bounds a JSON document. A zero field disables that check.
+type JSONLimits struct {
+	MaxDepth    int // nesting of arrays and objects
+	MaxSize     int // bytes of input
+	MaxElements int // items in any one array or keys in any one object
+}
+
+// DefaultJSONLimits are applied to every JSON argument the handlers accept
+var DefaultJSONLimits = JSONLimits{
+	MaxDepth:    64,
+	MaxSize:     10 << 20,
+	MaxElements: 100000,
+}
+
+// JSONLimitError reports which limit a document exceeded
+type JSONLimitError struct {
+	Limit  string // "depth", "size" or "elements"
+	Max    int
+	Offset int64 // input offset where the limit was hit
+}
+
+func (e *JSONLimitError) Error() string {
+	switch e.Limit {
+	case "size":
+		return fmt.Sprintf("JSON exceeds the maximum size of %d bytes", e.Max)
+	case "depth":
+		return fmt.Sprintf("JSON exceeds the maximum nesting depth of %d at offset %d", e.Max, e.Offset)
+	default:
+		return fmt.Sprintf("JSON container exceeds %d elements at offset %d", e.Max, e.Offset)
+	}
+}
+
+// JSONShape describes the structure CheckJSON walked
+type JSONShape struct {
+	Size        int
+	Depth       int
+	MaxElements int
+	Values      int
+}
+
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	count     int
+}
+
+// CheckJSON verifies that data is a single JSON value within limits. It
+// walks the token stream, so a document that is too deep or too wide is
+// rejected before anything is decoded into memory.
+func CheckJSON(data string, limits JSONLimits) (JSONShape, error) {
+	return CheckJSONContext(context.Background(), data, limits)
+}
+
+// CheckJSONContext is CheckJSON with a context that is checked while
+// walking the token stream of a large document
+func CheckJSONContext(ctx context.Context, data string, limits JSONLimits) (JSONShape, error) {
+	shape := JSONShape{Size: len(data)}
+	if limits.MaxSize > 0 && len(data) > limits.MaxSize {
+		return shape, &JSONLimitError{Limit: "size", Max: limits.MaxSize}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+
+	var stack []*jsonFrame
+	done := false
+	for tokens := 0; ; tokens++ {
+		if tokens%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return shape, err
+			}
+		}
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return shape, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if done {
+			return shape, fmt.Errorf("invalid JSON: unexpected data after top-level value at offset %d", decoder.InputOffset())
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			done = len(stack) == 0
+			continue
+		}
+
+		if top != nil {
+			if top.object && top.expectKey {
+				// Object key
+				top.expectKey = false
+				top.count++
+				if err := checkElements(top, limits, decoder, &shape); err != nil {
+					return shape, err
+				}
+				continue
+			}
+			if top.object {
+				top.expectKey = true
+			} else {
+				top.count++
+				if err := checkElements(top, limits, decoder, &shape); err != nil {
+					return shape, err
+				}
+			}
+		}
+		shape.Values++
+
+		if delim, ok := tok.(json.Delim); ok {
+			stack = append(stack, &jsonFrame{object: delim == '{', expectKey: delim == '{'})
+			if len(stack) > shape.Depth {
+				shape.Depth = len(stack)
+			}
+			if limits.MaxDepth > 0 && len(stack) > limits.MaxDepth {
+				return shape, &JSONLimitError{Limit: "depth", Max: limits.MaxDepth, Offset: decoder.InputOffset()}
+			}
+			continue
+		}
+		done = len(stack) == 0
+	}
+
+	if !done {
+		return shape, fmt.Errorf("invalid JSON: unexpected end of input")
+	}
+	return shape, nil
+}
+
+func checkElements(frame *jsonFrame, limits JSONLimits, decoder *json.Decoder, shape *JSONShape) error {
+	if frame.count > shape.MaxElements {
+		shape.MaxElements = frame.count
+	}
+	if limits.MaxElements > 0 && frame.count > limits.MaxElements {
+		return &JSONLimitError{Limit: "elements", Max: limits.MaxElements, Offset: decoder.InputOffset()}
+	}
+	return nil
+}
+
+// ValidateJSONSafe checks a document against limits and reports its shape
+// and, when it is rejected, which limit it exceeded
+func (dp *DataProcessor) ValidateJSONSafe(ctx context.Context, input string, limits JSONLimits) (map[string]interface{}, error) {
+	shape, err := CheckJSONContext(ctx, input, limits)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"valid":       err == nil,
+		"size":        shape.Size,
+		"depth":       shape.Depth,
+		"maxElements": shape.MaxElements,
+		"values":      shape.Values,
+		"limits": map[string]interface{}{
+			"maxDepth":    limits.MaxDepth,
+			"maxSize":     limits.MaxSize,
+			"maxElements": limits.MaxElements,
+		},
+	}
+	if err != nil {
+		result["error"] = err.Error()
+		var limitErr *JSONLimitError
+		if errors.As(err, &limitErr) {
+			result["limit"] = limitErr.Limit
+		}
+	}
+	return result, nil
+}
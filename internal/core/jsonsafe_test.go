@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckJSON(t *testing.T) {
+	limits := JSONLimits{MaxDepth: 3, MaxSize: 200, MaxElements: 4}
+	tests := []struct {
+		name  string
+		input string
+		shape JSONShape
+		limit string // expected limit error, if any
+		err   bool   // expected invalid JSON
+	}{
+		{name: "scalar", input: `42`, shape: JSONShape{Size: 2, Values: 1}},
+		{name: "object", input: `{"a":[1,2],"b":{"c":null}}`, shape: JSONShape{Size: 26, Depth: 2, MaxElements: 2, Values: 6}},
+		{name: "at the limits", input: `[[[1,2,3,4]]]`, shape: JSONShape{Size: 13, Depth: 3, MaxElements: 4, Values: 7}},
+		{name: "too deep", input: `[[[[1]]]]`, limit: "depth"},
+		{name: "too many array items", input: `[1,2,3,4,5]`, limit: "elements"},
+		{name: "too many keys", input: `{"a":1,"b":2,"c":3,"d":4,"e":5}`, limit: "elements"},
+		{name: "too large", input: `"` + strings.Repeat("x", 200) + `"`, limit: "size"},
+		{name: "empty", input: ``, err: true},
+		{name: "truncated", input: `{"a":[1`, err: true},
+		{name: "trailing value", input: `{} {}`, err: true},
+		{name: "bad syntax", input: `{"a" 1}`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := CheckJSON(tt.input, limits)
+			var limitErr *JSONLimitError
+			switch {
+			case tt.limit != "":
+				if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+					t.Errorf("err = %v, want a %s limit error", err, tt.limit)
+				}
+			case tt.err:
+				if err == nil || errors.As(err, &limitErr) {
+					t.Errorf("err = %v, want invalid JSON", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if shape != tt.shape {
+					t.Errorf("shape = %+v, want %+v", shape, tt.shape)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckJSONDeepNesting(t *testing.T) {
+	// Far deeper than a recursive decoder could handle comfortably; the
+	// walk stops as soon as the limit is passed
+	deep := strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000)
+	_, err := CheckJSON(deep, JSONLimits{MaxDepth: 64})
+	var limitErr *JSONLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "depth" || limitErr.Offset != 65 {
+		t.Errorf("err = %v, want a depth error at offset 65", err)
+	}
+
+	nested := strings.Repeat("[", 5000) + strings.Repeat("]", 5000)
+	shape, err := CheckJSON(nested, JSONLimits{})
+	if err != nil || shape.Depth != 5000 {
+		t.Errorf("unlimited check: depth %d, %v", shape.Depth, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CheckJSONContext(ctx, deep, JSONLimits{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestValidateJSONSafe(t *testing.T) {
+	dp := NewDataProcessor()
+	limits := JSONLimits{MaxDepth: 2}
+
+	result, err := dp.ValidateJSONSafe(context.Background(), `{"a":[1]}`, limits)
+	if err != nil || result["valid"] != true || result["depth"] != 2 {
+		t.Errorf("valid document: %v, %v", result, err)
+	}
+	if _, ok := result["error"]; ok {
+		t.Errorf("valid document reported an error: %v", result["error"])
+	}
+
+	result, err = dp.ValidateJSONSafe(context.Background(), `{"a":[[1]]}`, limits)
+	if err != nil || result["valid"] != false || result["limit"] != "depth" {
+		t.Errorf("deep document: %v, %v", result, err)
+	}
+	if !strings.Contains(result["error"].(string), "maximum nesting depth of 2") {
+		t.Errorf("error = %v", result["error"])
+	}
+
+	// Invalid JSON is reported without a limit
+	result, _ = dp.ValidateJSONSafe(context.Background(), `{`, limits)
+	if _, ok := result["limit"]; ok || result["valid"] != false {
+		t.Errorf("invalid document: %v", result)
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ErrStopStream can be returned from a StreamJSONArray callback to stop
+// reading early without an error
+var ErrStopStream = errors.New("stop stream")
+
+// StreamJSONArray reads a top-level JSON array from r and calls fn with
+// each element's raw bytes in turn. Only the current element is held in
+// memory: decoding the whole array first needs memory for every element
+// at once, which for large arrays is several times the size of the
+// input. Elements are validated as they are read, so a syntax error late
+// in the array is only reported after earlier elements were processed.
+func StreamJSONArray(ctx context.Context, r io.Reader, fn func(index int, element json.RawMessage) error) (int, error) {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("expected a JSON array")
+	}
+
+	count := 0
+	for decoder.More() {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+		}
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return count, fmt.Errorf("invalid element %d: %w", count, err)
+		}
+		if err := fn(count, element); err != nil {
+			if errors.Is(err, ErrStopStream) {
+				return count + 1, nil
+			}
+			return count, err
+		}
+		count++
+	}
+	if _, err := decoder.Token(); err != nil {
+		return count, fmt.Errorf("invalid JSON after element %d: %w", count, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return count, fmt.Errorf("invalid JSON: unexpected data after the array")
+	}
+	return count, nil
+}
+
+// JSONPredicate tests the value at a JSON pointer within an element
+type JSONPredicate struct {
+	// Path is an RFC 6901 pointer such as /user/age; empty means the
+	// element itself
+	Path string
+	// Op is exists, eq, ne, gt, gte, lt, lte, contains, matches (a regular
+	// expression) or in (one of an array of values)
+	Op    string
+	Value interface{}
+
+	tokens []string
+	re     *regexp.Regexp
+}
+
+// compile validates the predicate
+func (p *JSONPredicate) compile() error {
+	tokens, err := parsePointer(p.Path)
+	if err != nil {
+		return err
+	}
+	p.tokens = tokens
+	switch p.Op {
+	case "exists", "eq", "ne", "gt", "gte", "lt", "lte", "contains":
+		return nil
+	case "matches":
+		pattern, ok := p.Value.(string)
+		if !ok {
+			return fmt.Errorf("matches requires a regular expression string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		p.re = re
+		return nil
+	case "in":
+		if _, ok := p.Value.([]interface{}); !ok {
+			return fmt.Errorf("in requires an array of values")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown predicate op %q (expected exists, eq, ne, gt, gte, lt, lte, contains, matches or in)", p.Op)
+}
+
+// Match reports whether element satisfies the predicate. Missing paths
+// match only ne; ordering ops compare numbers with numbers and strings with
+// strings.
+func (p *JSONPredicate) Match(element interface{}) bool {
+	value, err := pointerGet(element, p.tokens)
+	missing := err != nil
+	switch p.Op {
+	case "exists":
+		return !missing
+	case "ne":
+		return missing || !reflect.DeepEqual(value, p.Value)
+	}
+	if missing {
+		return false
+	}
+
+	switch p.Op {
+	case "eq":
+		return reflect.DeepEqual(value, p.Value)
+	case "contains":
+		switch v := value.(type) {
+		case string:
+			s, ok := p.Value.(string)
+			return ok && strings.Contains(v, s)
+		case []interface{}:
+			for _, item := range v {
+				if reflect.DeepEqual(item, p.Value) {
+					return true
+				}
+			}
+		}
+		return false
+	case "matches":
+		s, ok := value.(string)
+		return ok && p.re.MatchString(s)
+	case "in":
+		for _, option := range p.Value.([]interface{}) {
+			if reflect.DeepEqual(value, option) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var cmp int
+	switch v := value.(type) {
+	case float64:
+		w, ok := p.Value.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case v < w:
+			cmp = -1
+		case v > w:
+			cmp = 1
+		}
+	case string:
+		w, ok := p.Value.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(v, w)
+	default:
+		return false
+	}
+	switch p.Op {
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	}
+	return cmp <= 0
+}
+
+// FilterJSONArray streams a JSON array, counting the elements that match
+// predicate (all elements when nil) and collecting up to limit of them
+// unless countOnly is set. Memory use is bounded by the largest element
+// plus the collected matches rather than the whole array.
+func (dp *DataProcessor) FilterJSONArray(ctx context.Context, input string, predicate *JSONPredicate, limit int, countOnly bool) (map[string]interface{}, error) {
+	if predicate != nil {
+		if err := predicate.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	matched := 0
+	items := []interface{}{}
+	scanned, err := StreamJSONArray(ctx, strings.NewReader(input), func(_ int, raw json.RawMessage) error {
+		var element interface{}
+		if predicate != nil || !countOnly {
+			if err := json.Unmarshal(raw, &element); err != nil {
+				return err
+			}
+		}
+		if predicate != nil && !predicate.Match(element) {
+			return nil
+		}
+		matched++
+		if !countOnly && (limit <= 0 || len(items) < limit) {
+			items = append(items, element)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"scanned": scanned,
+		"matched": matched,
+	}
+	if !countOnly {
+		result["items"] = items
+		result["truncated"] = len(items) < matched
+	}
+	return result, nil
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStreamJSONArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		stopAt  int
+		count   int
+		wantErr string
+	}{
+		{name: "empty array", input: "[]", count: 0},
+		{name: "elements", input: `[1, "two", {"three": 3}, [4], null]`, count: 5},
+		{name: "whitespace", input: " \n[ 1 ,2 ]\n ", count: 2},
+		{name: "stop early", input: "[1,2,3,4]", stopAt: 2, count: 2},
+		{name: "not an array", input: `{"a":1}`, wantErr: "expected a JSON array"},
+		{name: "empty input", input: "", wantErr: "invalid JSON"},
+		{name: "bad element", input: "[1,2,}", count: 2, wantErr: "invalid element 2"},
+		{name: "unterminated", input: "[1,2", count: 2, wantErr: "invalid element 2"},
+		{name: "mismatched close", input: "[1,2}", count: 2, wantErr: "invalid JSON after element 2"},
+		{name: "trailing data", input: "[1] [2]", count: 1, wantErr: "unexpected data after the array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []string
+			count, err := StreamJSONArray(context.Background(), strings.NewReader(tt.input), func(i int, raw json.RawMessage) error {
+				if i != len(seen) {
+					t.Errorf("index %d, want %d", i, len(seen))
+				}
+				seen = append(seen, string(raw))
+				if tt.stopAt > 0 && len(seen) == tt.stopAt {
+					return ErrStopStream
+				}
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.count {
+				t.Errorf("count = %d, want %d", count, tt.count)
+			}
+		})
+	}
+}
+
+func TestStreamJSONArrayCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := StreamJSONArray(ctx, strings.NewReader("[1,2]"), func(int, json.RawMessage) error { return nil }); err != context.Canceled {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFilterJSONArray(t *testing.T) {
+	dp := NewDataProcessor()
+	input := `[
+		{"name": "ann", "age": 31, "tags": ["admin"]},
+		{"name": "bob", "age": 17},
+		{"name": "cy", "age": 45, "tags": ["dev", "admin"]},
+		{"name": "dee"}
+	]`
+
+	tests := []struct {
+		name      string
+		predicate *JSONPredicate
+		limit     int
+		matched   int
+		names     []string
+		wantErr   bool
+	}{
+		{name: "all", matched: 4, names: []string{"ann", "bob", "cy", "dee"}},
+		{name: "limit", limit: 2, matched: 4, names: []string{"ann", "bob"}},
+		{name: "gte", predicate: &JSONPredicate{Path: "/age", Op: "gte", Value: 31.0}, matched: 2, names: []string{"ann", "cy"}},
+		{name: "lt", predicate: &JSONPredicate{Path: "/age", Op: "lt", Value: 18.0}, matched: 1, names: []string{"bob"}},
+		{name: "exists", predicate: &JSONPredicate{Path: "/tags", Op: "exists"}, matched: 2, names: []string{"ann", "cy"}},
+		{name: "ne includes missing", predicate: &JSONPredicate{Path: "/age", Op: "ne", Value: 17.0}, matched: 3, names: []string{"ann", "cy", "dee"}},
+		{name: "contains in array", predicate: &JSONPredicate{Path: "/tags", Op: "contains", Value: "dev"}, matched: 1, names: []string{"cy"}},
+		{name: "contains in string", predicate: &JSONPredicate{Path: "/name", Op: "contains", Value: "n"}, matched: 1, names: []string{"ann"}},
+		{name: "matches", predicate: &JSONPredicate{Path: "/name", Op: "matches", Value: "^[bc]"}, matched: 2, names: []string{"bob", "cy"}},
+		{name: "in", predicate: &JSONPredicate{Path: "/name", Op: "in", Value: []interface{}{"bob", "dee"}}, matched: 2, names: []string{"bob", "dee"}},
+		{name: "string compared with number", predicate: &JSONPredicate{Path: "/name", Op: "gt", Value: 1.0}, matched: 0},
+		{name: "bad op", predicate: &JSONPredicate{Path: "/age", Op: "between"}, wantErr: true},
+		{name: "bad pattern", predicate: &JSONPredicate{Path: "/name", Op: "matches", Value: "("}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.FilterJSONArray(context.Background(), input, tt.predicate, tt.limit, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["scanned"] != 4 || result["matched"] != tt.matched {
+				t.Fatalf("scanned = %v, matched = %v, want 4 and %d", result["scanned"], result["matched"], tt.matched)
+			}
+			items := result["items"].([]interface{})
+			if len(items) != len(tt.names) {
+				t.Fatalf("got %d items, want %d", len(items), len(tt.names))
+			}
+			for i, item := range items {
+				if name := item.(map[string]interface{})["name"]; name != tt.names[i] {
+					t.Errorf("item %d = %v, want %s", i, name, tt.names[i])
+				}
+			}
+			if result["truncated"] != (len(items) < tt.matched) {
+				t.Errorf("truncated = %v", result["truncated"])
+			}
+		})
+	}
+
+	result, err := dp.FilterJSONArray(context.Background(), input, nil, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result["items"]; ok || result["matched"] != 4 {
+		t.Errorf("countOnly result = %v", result)
+	}
+}
+
+// largeJSONArray returns an array of n objects of a few hundred bytes each
+func largeJSONArray(n int) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"id":%d,"name":"user-%d","active":%t,"tags":["a","b","c"],"bio":"%s"}`,
+			i, i, i%3 == 0, strings.Repeat("x", 200))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// BenchmarkFilterJSONArray compares counting matches in a large array by
+// streaming it with decoding the whole array first. Both allocate about the
+// same in total and streaming is somewhat slower; what it saves is peak
+// memory, which BenchmarkFilterJSONArrayPeakHeap reports.
+func BenchmarkFilterJSONArray(b *testing.B) {
+	input := largeJSONArray(20000)
+	predicate := &JSONPredicate{Path: "/active", Op: "eq", Value: true}
+	if err := predicate.compile(); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(input)))
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		dp := NewDataProcessor()
+		for i := 0; i < b.N; i++ {
+			if _, err := dp.FilterJSONArray(context.Background(), input, predicate, 0, true); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var all []interface{}
+			if err := json.Unmarshal([]byte(input), &all); err != nil {
+				b.Fatal(err)
+			}
+			matched := 0
+			for _, element := range all {
+				if predicate.Match(element) {
+					matched++
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkFilterJSONArrayPeakHeap reports the largest live heap while
+// each approach runs, which is what streaming bounds
+func BenchmarkFilterJSONArrayPeakHeap(b *testing.B) {
+	input := largeJSONArray(20000)
+	b.Run("stream", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			peak := livePeak(func(sample func()) {
+				StreamJSONArray(context.Background(), strings.NewReader(input), func(i int, raw json.RawMessage) error {
+					var element interface{}
+					json.Unmarshal(raw, &element)
+					if i%2000 == 0 {
+						sample()
+					}
+					return nil
+				})
+			})
+			b.ReportMetric(float64(peak)/(1<<20), "peak-MiB")
+		}
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			peak := livePeak(func(sample func()) {
+				var all []interface{}
+				json.Unmarshal([]byte(input), &all)
+				sample()
+				runtime.KeepAlive(all)
+			})
+			b.ReportMetric(float64(peak)/(1<<20), "peak-MiB")
+		}
+	})
+}
+
+// livePeak runs fn and returns the largest heap in use, above what was in
+// use before, at the points where fn calls sample
+func livePeak(fn func(sample func())) uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	base, peak := stats.HeapAlloc, uint64(0)
+	fn(func() {
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		if stats.HeapAlloc > base && stats.HeapAlloc-base > peak {
+			peak = stats.HeapAlloc - base
+		}
+	})
+	return peak
+}
@@ -46,11 +46,8 @@ func (dp *DataProcessor) ProcessText(input string) (map[string]interface{}, erro
 
 	// Word frequency analysis
 	wordFreq := make(map[string]int)
-	for _, word := range words {
-		cleaned := strings.ToLower(strings.Trim(word, ".,!?;:\"'"))
-		if cleaned != "" {
-			wordFreq[cleaned]++
-		}
+	for _, word := range tokenize(input) {
+		wordFreq[word]++
 	}
 
 	// Find most common words
@@ -84,6 +81,7 @@ func (dp *DataProcessor) ProcessText(input string) (map[string]interface{}, erro
 
 	result := map[string]interface{}{
 		"originalLength":      len(input),
+		"characterCount":      CountGraphemes(input),
 		"wordCount":           len(words),
 		"sentenceCount":       len(cleanSentences),
 		"avgWordsPerSentence": math.Round(avgWordsPerSentence*100) / 100,
@@ -183,7 +181,27 @@ func (dp *DataProcessor) GenerateID(idType string) string {
 
 // Helper methods
 
+// tokenize splits text into lowercase words with surrounding punctuation
+// removed
+func tokenize(text string) []string {
+	words := strings.Fields(text)
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		cleaned := strings.ToLower(strings.Trim(word, ".,!?;:\"'"))
+		if cleaned != "" {
+			tokens = append(tokens, cleaned)
+		}
+	}
+	return tokens
+}
+
 func (dp *DataProcessor) calculateReadabilityScore(wordCount, sentenceCount, uniqueWords int) float64 {
+	return readabilityScore(wordCount, sentenceCount, uniqueWords)
+}
+
+// readabilityScore rates text from 0 to 100 by sentence length and
+// lexical diversity
+func readabilityScore(wordCount, sentenceCount, uniqueWords int) float64 {
 	if sentenceCount == 0 {
 		return 0.0
 	}
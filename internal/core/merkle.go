@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Merkle trees hash each item into a leaf and combine pairs of hashes level
+// by level up to a single root:
+//
+//	leaf = SHA-256(0x00 || item bytes)
+//	node = SHA-256(0x01 || left || right)
+//
+// The item bytes are a string's UTF-8 encoding, or for any other JSON
+// value its canonical JSON (RFC 8785), so equal values hash equally on
+// every replica. The prefixes keep a leaf from being passed off as an
+// interior node. A level with an odd count promotes its last hash to the
+// next level unchanged rather than pairing it with itself, which would let
+// two different lists share a root.
+
+// merkleHash is a SHA-256 leaf or node hash
+type merkleHash = [sha256.Size]byte
+
+// MerkleStep is one sibling on the path from a leaf to the root
+type MerkleStep struct {
+	Hash merkleHash
+	// Left is set when the sibling is combined on the left
+	Left bool
+}
+
+// MerkleTree holds every level of hashes, leaves first
+type MerkleTree struct {
+	Levels [][]merkleHash
+}
+
+// merkleItemBytes returns the bytes hashed for an item
+func merkleItemBytes(item interface{}) ([]byte, error) {
+	if s, ok := item.(string); ok {
+		return []byte(s), nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("item cannot be encoded as JSON: %w", err)
+	}
+	return CanonicalJSON(data)
+}
+
+// MerkleLeafHash hashes an item as a leaf
+func MerkleLeafHash(item interface{}) (merkleHash, error) {
+	data, err := merkleItemBytes(item)
+	if err != nil {
+		return merkleHash{}, err
+	}
+	return sha256.Sum256(append([]byte{0x00}, data...)), nil
+}
+
+// merkleNode combines two child hashes
+func merkleNode(left, right merkleHash) merkleHash {
+	buf := make([]byte, 0, 1+2*sha256.Size)
+	buf = append(buf, 0x01)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// BuildMerkleTree hashes items into a tree; at least one item is required
+func BuildMerkleTree(ctx context.Context, items []interface{}) (*MerkleTree, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("at least one item required")
+	}
+
+	leaves := make([]merkleHash, len(items))
+	for i, item := range items {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ReportProgress(ctx, i, len(items))
+		}
+		h, err := MerkleLeafHash(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		leaves[i] = h
+	}
+
+	tree := &MerkleTree{Levels: [][]merkleHash{leaves}}
+	for level := leaves; len(level) > 1; {
+		next := make([]merkleHash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, merkleNode(level[i], level[i+1]))
+			}
+		}
+		tree.Levels = append(tree.Levels, next)
+		level = next
+	}
+	return tree, nil
+}
+
+// Root returns the root hash
+func (t *MerkleTree) Root() merkleHash {
+	return t.Levels[len(t.Levels)-1][0]
+}
+
+// Proof returns the siblings needed to recompute the root from leaf index.
+// Levels where the node was promoted contribute no step.
+func (t *MerkleTree) Proof(index int) ([]MerkleStep, error) {
+	if index < 0 || index >= len(t.Levels[0]) {
+		return nil, fmt.Errorf("index %d is out of range for %d items", index, len(t.Levels[0]))
+	}
+	steps := []MerkleStep{}
+	for _, level := range t.Levels[:len(t.Levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			steps = append(steps, MerkleStep{Hash: level[sibling], Left: sibling < index})
+		}
+		index /= 2
+	}
+	return steps, nil
+}
+
+// VerifyMerkleProof recomputes the root from a leaf hash and its proof
+func VerifyMerkleProof(leaf merkleHash, proof []MerkleStep, root merkleHash) bool {
+	h := leaf
+	for _, step := range proof {
+		if step.Left {
+			h = merkleNode(step.Hash, h)
+		} else {
+			h = merkleNode(h, step.Hash)
+		}
+	}
+	return h == root
+}
+
+// parseMerkleHash decodes a hex SHA-256 hash
+func parseMerkleHash(s string) (merkleHash, error) {
+	var h merkleHash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != sha256.Size {
+		return h, fmt.Errorf("%q is not a hex SHA-256 hash", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func merkleHex(h merkleHash) string {
+	return hex.EncodeToString(h[:])
+}
+
+// MerkleRoot builds a tree over items and returns its root. With
+// includeLevels every level of hashes is returned too, leaves first, so
+// two replicas can walk down from the root to the subtrees that differ.
+func (dp *DataProcessor) MerkleRoot(ctx context.Context, items []interface{}, includeLevels bool) (map[string]interface{}, error) {
+	tree, err := BuildMerkleTree(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"root":   merkleHex(tree.Root()),
+		"leaves": len(items),
+		"depth":  len(tree.Levels) - 1,
+	}
+	if includeLevels {
+		levels := make([]interface{}, len(tree.Levels))
+		for i, level := range tree.Levels {
+			hashes := make([]interface{}, len(level))
+			for j, h := range level {
+				hashes[j] = merkleHex(h)
+			}
+			levels[i] = hashes
+		}
+		result["levels"] = levels
+	}
+	return result, nil
+}
+
+// MerkleProof returns the inclusion proof of the item at index
+func (dp *DataProcessor) MerkleProof(ctx context.Context, items []interface{}, index int) (map[string]interface{}, error) {
+	tree, err := BuildMerkleTree(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := tree.Proof(index)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := make([]interface{}, len(steps))
+	for i, step := range steps {
+		side := "right"
+		if step.Left {
+			side = "left"
+		}
+		proof[i] = map[string]interface{}{
+			"hash": merkleHex(step.Hash),
+			"side": side,
+		}
+	}
+	return map[string]interface{}{
+		"root":     merkleHex(tree.Root()),
+		"index":    index,
+		"leafHash": merkleHex(tree.Levels[0][index]),
+		"proof":    proof,
+	}, nil
+}
+
+// MerkleVerify checks that item is included under root using a proof as
+// returned by MerkleProof: a list of {hash, side} objects
+func (dp *DataProcessor) MerkleVerify(item interface{}, proof []interface{}, root string) (map[string]interface{}, error) {
+	rootHash, err := parseMerkleHash(root)
+	if err != nil {
+		return nil, fmt.Errorf("root: %w", err)
+	}
+	steps := make([]MerkleStep, len(proof))
+	for i, raw := range proof {
+		step, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("proof step %d must be an object with hash and side", i)
+		}
+		hexHash, _ := step["hash"].(string)
+		h, err := parseMerkleHash(hexHash)
+		if err != nil {
+			return nil, fmt.Errorf("proof step %d: %w", i, err)
+		}
+		side, _ := step["side"].(string)
+		if side != "left" && side != "right" {
+			return nil, fmt.Errorf("proof step %d: side must be left or right", i)
+		}
+		steps[i] = MerkleStep{Hash: h, Left: side == "left"}
+	}
+
+	leaf, err := MerkleLeafHash(item)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"valid":    VerifyMerkleProof(leaf, steps, rootHash),
+		"leafHash": merkleHex(leaf),
+	}, nil
+}
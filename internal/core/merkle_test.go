@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestMerkleTreeShape(t *testing.T) {
+	leaf := func(s string) merkleHash { return sha256.Sum256(append([]byte{0x00}, s...)) }
+	a, b, c := leaf("a"), leaf("b"), leaf("c")
+
+	tests := []struct {
+		items []interface{}
+		root  merkleHash
+		depth int
+	}{
+		{[]interface{}{"a"}, a, 0},
+		{[]interface{}{"a", "b"}, merkleNode(a, b), 1},
+		// the odd leaf is promoted, not paired with itself
+		{[]interface{}{"a", "b", "c"}, merkleNode(merkleNode(a, b), c), 2},
+		{[]interface{}{"a", "b", "c", "c"}, merkleNode(merkleNode(a, b), merkleNode(c, c)), 2},
+	}
+	for _, tt := range tests {
+		tree, err := BuildMerkleTree(context.Background(), tt.items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tree.Root() != tt.root || len(tree.Levels)-1 != tt.depth {
+			t.Errorf("%v: root %x depth %d, want %x depth %d", tt.items, tree.Root(), len(tree.Levels)-1, tt.root, tt.depth)
+		}
+	}
+
+	if _, err := BuildMerkleTree(context.Background(), nil); err == nil {
+		t.Error("expected an error for no items")
+	}
+	if _, err := BuildMerkleTree(context.Background(), []interface{}{make(chan int)}); err == nil {
+		t.Error("expected an error for an item that is not JSON")
+	}
+}
+
+func TestMerkleLeafHashCanonical(t *testing.T) {
+	a, _ := MerkleLeafHash(map[string]interface{}{"b": 1, "a": []interface{}{1.0, "x"}})
+	b, _ := MerkleLeafHash(map[string]interface{}{"a": []interface{}{1, "x"}, "b": 1.0})
+	if a != b {
+		t.Error("equal JSON values hash differently")
+	}
+}
+
+func TestMerkleProofs(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		items := make([]interface{}, size)
+		for i := range items {
+			items[i] = fmt.Sprintf("item-%d", i)
+		}
+		tree, err := BuildMerkleTree(context.Background(), items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range items {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyMerkleProof(tree.Levels[0][i], proof, tree.Root()) {
+				t.Errorf("size %d: proof for item %d does not verify", size, i)
+			}
+			// the proof is for this leaf only
+			other := tree.Levels[0][(i+1)%size]
+			if size > 1 && VerifyMerkleProof(other, proof, tree.Root()) {
+				t.Errorf("size %d: proof for item %d verifies another leaf", size, i)
+			}
+		}
+		if _, err := tree.Proof(size); err == nil {
+			t.Errorf("size %d: expected an error for an index out of range", size)
+		}
+	}
+}
+
+func TestMerkleHandlers(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{"a", map[string]interface{}{"n": 1}, "c"}
+
+	rootResult, err := dp.MerkleRoot(context.Background(), items, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootResult["leaves"] != 3 || rootResult["depth"] != 2 || len(rootResult["levels"].([]interface{})) != 3 {
+		t.Errorf("root result = %v", rootResult)
+	}
+
+	proofResult, err := dp.MerkleProof(context.Background(), items, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := proofResult["root"].(string)
+	if root != rootResult["root"] {
+		t.Errorf("proof root = %s, want %s", root, rootResult["root"])
+	}
+	proof := proofResult["proof"].([]interface{})
+
+	verify, err := dp.MerkleVerify(map[string]interface{}{"n": 1.0}, proof, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verify["valid"] != true || verify["leafHash"] != proofResult["leafHash"] {
+		t.Errorf("verify = %v", verify)
+	}
+	if verify, _ := dp.MerkleVerify("b", proof, root); verify["valid"] != false {
+		t.Error("a different item verified")
+	}
+
+	badProofs := [][]interface{}{
+		{"not an object"},
+		{map[string]interface{}{"hash": "zz", "side": "left"}},
+		{map[string]interface{}{"hash": root, "side": "up"}},
+	}
+	for _, bad := range badProofs {
+		if _, err := dp.MerkleVerify("a", bad, root); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+	if _, err := dp.MerkleVerify("a", nil, "abc"); err == nil {
+		t.Error("expected an error for a bad root")
+	}
+}
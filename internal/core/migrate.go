@@ -0,0 +1,98 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SchemaVersionKey is the state field MigrateState records the resulting
+// version in
+const SchemaVersionKey = "schemaVersion"
+
+// Migration upgrades a decoded state by one version. It may modify and
+// return the state it is given or build a new one.
+type Migration func(state map[string]interface{}) (map[string]interface{}, error)
+
+// Migrator holds the migrations for a stored data schema, each keyed by the
+// version it upgrades to
+type Migrator struct {
+	mu    sync.Mutex
+	steps map[int]Migration
+}
+
+// NewMigrator creates a migrator with no migrations
+func NewMigrator() *Migrator {
+	return &Migrator{steps: make(map[int]Migration)}
+}
+
+// Register adds the migration from version-1 to version, replacing any
+// earlier one for that version
+func (m *Migrator) Register(version int, fn Migration) error {
+	if version < 1 {
+		return fmt.Errorf("migration version must be at least 1, got %d", version)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps[version] = fn
+	return nil
+}
+
+// Versions returns the number of registered migrations
+func (m *Migrator) Versions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.steps)
+}
+
+// MigrateState applies the migrations from fromVersion+1 through toVersion
+// in order to a copy of state, so a failed step leaves the input untouched.
+// The resulting version is recorded under SchemaVersionKey. Migrating to the
+// current version is a no-op; downgrades and gaps in the chain are errors.
+func (m *Migrator) MigrateState(state map[string]interface{}, fromVersion, toVersion int) (map[string]interface{}, error) {
+	if fromVersion < 0 || toVersion < 0 {
+		return nil, fmt.Errorf("versions must not be negative")
+	}
+	if toVersion < fromVersion {
+		return nil, fmt.Errorf("cannot migrate down from version %d to %d", fromVersion, toVersion)
+	}
+
+	m.mu.Lock()
+	steps := make([]Migration, 0, toVersion-fromVersion)
+	for v := fromVersion + 1; v <= toVersion; v++ {
+		fn, ok := m.steps[v]
+		if !ok {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("no migration registered from version %d to %d", v-1, v)
+		}
+		steps = append(steps, fn)
+	}
+	m.mu.Unlock()
+
+	current, _ := deepCopyJSON(state).(map[string]interface{})
+	if current == nil {
+		current = map[string]interface{}{}
+	}
+
+	applied := make([]interface{}, 0, len(steps))
+	for i, fn := range steps {
+		version := fromVersion + i + 1
+		next, err := fn(current)
+		if err != nil {
+			return nil, fmt.Errorf("migration to version %d failed: %w", version, err)
+		}
+		if next == nil {
+			return nil, fmt.Errorf("migration to version %d returned no state", version)
+		}
+		current = next
+		applied = append(applied, version)
+	}
+	current[SchemaVersionKey] = toVersion
+
+	return map[string]interface{}{
+		"state":       current,
+		"fromVersion": fromVersion,
+		"version":     toVersion,
+		"applied":     applied,
+		"migrated":    len(applied) > 0,
+	}, nil
+}
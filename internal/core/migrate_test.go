@@ -0,0 +1,97 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testMigrator renames "name" to "title" in version 1 and adds "tags" in
+// version 2
+func testMigrator() *Migrator {
+	m := NewMigrator()
+	m.Register(1, func(state map[string]interface{}) (map[string]interface{}, error) {
+		state["title"] = state["name"]
+		delete(state, "name")
+		return state, nil
+	})
+	m.Register(2, func(state map[string]interface{}) (map[string]interface{}, error) {
+		state["tags"] = []interface{}{}
+		return state, nil
+	})
+	return m
+}
+
+func TestMigrateState(t *testing.T) {
+	m := testMigrator()
+	if m.Versions() != 2 {
+		t.Errorf("versions = %d, want 2", m.Versions())
+	}
+
+	input := map[string]interface{}{"name": "notes", "nested": map[string]interface{}{"n": 1.0}}
+	result, err := m.MigrateState(input, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantState := map[string]interface{}{
+		"title":          "notes",
+		"tags":           []interface{}{},
+		"nested":         map[string]interface{}{"n": 1.0},
+		SchemaVersionKey: 2,
+	}
+	if !reflect.DeepEqual(result["state"], wantState) {
+		t.Errorf("state = %v, want %v", result["state"], wantState)
+	}
+	if !reflect.DeepEqual(result["applied"], []interface{}{1, 2}) || result["migrated"] != true {
+		t.Errorf("result = %v", result)
+	}
+	// The input is left untouched
+	if _, ok := input["name"]; !ok || len(input) != 2 {
+		t.Errorf("input was modified: %v", input)
+	}
+
+	// Starting part way runs only the remaining steps
+	result, _ = m.MigrateState(map[string]interface{}{"title": "x"}, 1, 2)
+	if !reflect.DeepEqual(result["applied"], []interface{}{2}) {
+		t.Errorf("applied = %v, want [2]", result["applied"])
+	}
+
+	// Migrating to the current version is a no-op that still records it
+	result, err = m.MigrateState(nil, 2, 2)
+	if err != nil || result["migrated"] != false || !reflect.DeepEqual(result["state"], map[string]interface{}{SchemaVersionKey: 2}) {
+		t.Errorf("no-op migration = %v, %v", result, err)
+	}
+}
+
+func TestMigrateStateErrors(t *testing.T) {
+	m := testMigrator()
+	m.Register(4, func(state map[string]interface{}) (map[string]interface{}, error) { return state, nil })
+	m.Register(5, func(map[string]interface{}) (map[string]interface{}, error) { return nil, nil })
+	m.Register(6, func(map[string]interface{}) (map[string]interface{}, error) { return nil, errors.New("boom") })
+
+	tests := []struct {
+		name     string
+		from, to int
+		err      string
+	}{
+		{name: "negative", from: -1, to: 1, err: "must not be negative"},
+		{name: "downgrade", from: 2, to: 1, err: "cannot migrate down"},
+		{name: "gap", from: 2, to: 4, err: "no migration registered from version 2 to 3"},
+		{name: "nil state", from: 3, to: 5, err: "migration to version 5 returned no state"},
+		{name: "step error", from: 5, to: 6, err: "migration to version 6 failed: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.MigrateState(map[string]interface{}{}, tt.from, tt.to)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+
+	if err := m.Register(0, nil); err == nil {
+		t.Error("expected an error registering version 0")
+	}
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// VectorClock maps replica IDs to the number of operations seen from each
+type VectorClock map[string]int
+
+// Copy returns an independent copy of the clock
+func (vc VectorClock) Copy() VectorClock {
+	out := make(VectorClock, len(vc))
+	for replica, n := range vc {
+		out[replica] = n
+	}
+	return out
+}
+
+// Merge raises each entry to the maximum of both clocks
+func (vc VectorClock) Merge(other VectorClock) {
+	for replica, n := range other {
+		if n > vc[replica] {
+			vc[replica] = n
+		}
+	}
+}
+
+// Compare returns "before", "after", "equal" or "concurrent" describing how
+// vc relates causally to other
+func (vc VectorClock) Compare(other VectorClock) string {
+	less, greater := false, false
+	for _, replica := range clockReplicas(vc, other) {
+		switch a, b := vc[replica], other[replica]; {
+		case a < b:
+			less = true
+		case a > b:
+			greater = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return "concurrent"
+	case less:
+		return "before"
+	case greater:
+		return "after"
+	default:
+		return "equal"
+	}
+}
+
+func clockReplicas(clocks ...VectorClock) []string {
+	seen := make(map[string]bool)
+	var replicas []string
+	for _, vc := range clocks {
+		for replica := range vc {
+			if !seen[replica] {
+				seen[replica] = true
+				replicas = append(replicas, replica)
+			}
+		}
+	}
+	sort.Strings(replicas)
+	return replicas
+}
+
+// sum returns the total number of operations the clock has seen
+func (vc VectorClock) sum() int {
+	total := 0
+	for _, n := range vc {
+		total += n
+	}
+	return total
+}
+
+// Operation is a single entry in an OpLog. Clock is the replica's clock just
+// after the operation, so Clock[Replica] is its sequence number.
+type Operation struct {
+	ID        string      `json:"id"`
+	Replica   string      `json:"replica"`
+	Clock     VectorClock `json:"clock"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// OpLog is an append-only, causally ordered log of operations from any
+// number of replicas. It holds no storage of its own; callers persist the
+// JSON form.
+type OpLog struct {
+	Ops   []Operation `json:"ops"`
+	Clock VectorClock `json:"clock"`
+}
+
+// ParseOpLog decodes a log from JSON. An empty string or null yields an
+// empty log.
+func ParseOpLog(data string) (*OpLog, error) {
+	log := &OpLog{}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), log); err != nil {
+			return nil, fmt.Errorf("invalid operation log: %w", err)
+		}
+	}
+	if log.Ops == nil {
+		log.Ops = []Operation{}
+	}
+	if log.Clock == nil {
+		log.Clock = VectorClock{}
+	}
+	return log, nil
+}
+
+// Append records a new operation from replica. Its clock includes every
+// operation already in the log, so it happens after all of them.
+func (l *OpLog) Append(replica string, data interface{}) Operation {
+	l.Clock[replica]++
+	op := Operation{
+		ID:        fmt.Sprintf("%s:%d", replica, l.Clock[replica]),
+		Replica:   replica,
+		Clock:     l.Clock.Copy(),
+		Timestamp: time.Now().UnixMilli(),
+		Data:      data,
+	}
+	l.Ops = append(l.Ops, op)
+	return op
+}
+
+// Since returns the operations not yet covered by clock, i.e. the delta a
+// replica at that clock needs
+func (l *OpLog) Since(clock VectorClock) []Operation {
+	delta := []Operation{}
+	for _, op := range l.Ops {
+		if op.Clock[op.Replica] > clock[op.Replica] {
+			delta = append(delta, op)
+		}
+	}
+	return delta
+}
+
+// Merge adds the operations of other that are not already in the log and
+// restores causal order
+func (l *OpLog) Merge(other *OpLog) int {
+	seen := make(map[string]bool, len(l.Ops))
+	for _, op := range l.Ops {
+		seen[op.ID] = true
+	}
+
+	added := 0
+	for _, op := range other.Ops {
+		if seen[op.ID] {
+			continue
+		}
+		seen[op.ID] = true
+		l.Ops = append(l.Ops, op)
+		l.Clock.Merge(op.Clock)
+		added++
+	}
+	l.Clock.Merge(other.Clock)
+	l.sortCausal()
+	return added
+}
+
+// sortCausal orders operations so each comes after everything it causally
+// depends on. If a happened before b its clock sum is strictly smaller, so
+// sorting by sum is a valid causal order; concurrent operations are ordered
+// by replica and sequence number so every replica agrees.
+func (l *OpLog) sortCausal() {
+	sort.SliceStable(l.Ops, func(i, j int) bool {
+		a, b := l.Ops[i], l.Ops[j]
+		if sa, sb := a.Clock.sum(), b.Clock.sum(); sa != sb {
+			return sa < sb
+		}
+		if a.Replica != b.Replica {
+			return a.Replica < b.Replica
+		}
+		return a.Clock[a.Replica] < b.Clock[b.Replica]
+	})
+}
+
+// toJSON converts a value to plain decoded JSON for handler responses
+func toJSON(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(encoded, &out)
+	return out, err
+}
+
+// opLogResult returns a log in both decoded and JSON string form
+func opLogResult(log *OpLog) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(log)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"log":  decoded,
+		"json": string(encoded),
+	}, nil
+}
+
+// OpLogAppend appends an operation from replica to a serialized log
+func (dp *DataProcessor) OpLogAppend(logJSON, replica string, data interface{}) (map[string]interface{}, error) {
+	if replica == "" {
+		return nil, fmt.Errorf("replica ID required")
+	}
+	log, err := ParseOpLog(logJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	op := log.Append(replica, data)
+
+	result, err := opLogResult(log)
+	if err != nil {
+		return nil, err
+	}
+	if result["op"], err = toJSON(op); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// OpLogSince returns the operations of a serialized log that a replica with
+// the given clock has not seen
+func (dp *DataProcessor) OpLogSince(logJSON, clockJSON string) (map[string]interface{}, error) {
+	log, err := ParseOpLog(logJSON)
+	if err != nil {
+		return nil, err
+	}
+	clock := VectorClock{}
+	if clockJSON != "" {
+		if err := json.Unmarshal([]byte(clockJSON), &clock); err != nil {
+			return nil, fmt.Errorf("invalid vector clock: %w", err)
+		}
+	}
+
+	delta := log.Since(clock)
+	ops, err := toJSON(delta)
+	if err != nil {
+		return nil, err
+	}
+	clockValue, err := toJSON(log.Clock)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"ops":   ops,
+		"count": len(delta),
+		"clock": clockValue,
+	}, nil
+}
+
+// OpLogMerge combines two serialized logs, dropping duplicate operations
+func (dp *DataProcessor) OpLogMerge(aJSON, bJSON string) (map[string]interface{}, error) {
+	a, err := ParseOpLog(aJSON)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ParseOpLog(bJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	added := a.Merge(b)
+
+	result, err := opLogResult(a)
+	if err != nil {
+		return nil, err
+	}
+	result["added"] = added
+	return result, nil
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVectorClockCompare(t *testing.T) {
+	tests := []struct {
+		a, b VectorClock
+		want string
+	}{
+		{VectorClock{}, VectorClock{}, "equal"},
+		{VectorClock{"a": 1}, VectorClock{"a": 1, "b": 0}, "equal"},
+		{VectorClock{"a": 1}, VectorClock{"a": 2}, "before"},
+		{VectorClock{"a": 1}, VectorClock{"a": 1, "b": 1}, "before"},
+		{VectorClock{"a": 3, "b": 1}, VectorClock{"a": 2}, "after"},
+		{VectorClock{"a": 2}, VectorClock{"b": 1}, "concurrent"},
+		{VectorClock{"a": 2, "b": 1}, VectorClock{"a": 1, "b": 2}, "concurrent"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v vs %v = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVectorClockMerge(t *testing.T) {
+	vc := VectorClock{"a": 2, "b": 5}
+	copied := vc.Copy()
+	vc.Merge(VectorClock{"a": 4, "b": 1, "c": 1})
+
+	if want := (VectorClock{"a": 4, "b": 5, "c": 1}); !reflect.DeepEqual(vc, want) {
+		t.Errorf("merged = %v, want %v", vc, want)
+	}
+	if want := (VectorClock{"a": 2, "b": 5}); !reflect.DeepEqual(copied, want) {
+		t.Errorf("copy changed to %v", copied)
+	}
+}
+
+// cloneOpLog copies a log through its JSON form, as replicas exchange them
+func cloneOpLog(t *testing.T, log *OpLog) *OpLog {
+	t.Helper()
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := ParseOpLog(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clone
+}
+
+func TestOpLogAppendAndSince(t *testing.T) {
+	log, _ := ParseOpLog("")
+	first := log.Append("a", "x")
+	second := log.Append("a", "y")
+	third := log.Append("b", "z")
+
+	if first.ID != "a:1" || second.ID != "a:2" || third.ID != "b:1" {
+		t.Errorf("ids = %s %s %s", first.ID, second.ID, third.ID)
+	}
+	if first.Clock.Compare(second.Clock) != "before" || second.Clock.Compare(third.Clock) != "before" {
+		t.Error("appended operations are not causally ordered")
+	}
+	if want := (VectorClock{"a": 2, "b": 1}); !reflect.DeepEqual(log.Clock, want) {
+		t.Errorf("clock = %v, want %v", log.Clock, want)
+	}
+
+	tests := []struct {
+		clock VectorClock
+		want  []string
+	}{
+		{VectorClock{}, []string{"a:1", "a:2", "b:1"}},
+		{VectorClock{"a": 1}, []string{"a:2", "b:1"}},
+		{VectorClock{"a": 2, "b": 1}, nil},
+		{VectorClock{"b": 1, "c": 9}, []string{"a:1", "a:2"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, op := range log.Since(tt.clock) {
+			got = append(got, op.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Since(%v) = %v, want %v", tt.clock, got, tt.want)
+		}
+	}
+}
+
+func TestOpLogMergeCommutative(t *testing.T) {
+	shared, _ := ParseOpLog("")
+	shared.Append("a", 1)
+
+	// Three replicas diverge from a shared history
+	a, b, c := cloneOpLog(t, shared), cloneOpLog(t, shared), cloneOpLog(t, shared)
+	a.Append("a", 2)
+	a.Append("a", 3)
+	b.Append("b", 1)
+	c.Append("c", 1)
+	c.Merge(cloneOpLog(t, b))
+	c.Append("c", 2)
+
+	ab := cloneOpLog(t, a)
+	if added := ab.Merge(cloneOpLog(t, b)); added != 1 {
+		t.Errorf("added %d operations, want 1", added)
+	}
+	ba := cloneOpLog(t, b)
+	ba.Merge(cloneOpLog(t, a))
+	if !reflect.DeepEqual(ab, ba) {
+		t.Errorf("merge is not commutative:\n%v\n%v", ab, ba)
+	}
+
+	// Associative: (a+b)+c == a+(b+c)
+	left := cloneOpLog(t, ab)
+	left.Merge(cloneOpLog(t, c))
+	bc := cloneOpLog(t, b)
+	bc.Merge(cloneOpLog(t, c))
+	right := cloneOpLog(t, a)
+	right.Merge(bc)
+	if !reflect.DeepEqual(left, right) {
+		t.Errorf("merge is not associative:\n%v\n%v", left, right)
+	}
+
+	// Idempotent
+	again := cloneOpLog(t, left)
+	if added := again.Merge(cloneOpLog(t, left)); added != 0 || !reflect.DeepEqual(again, left) {
+		t.Errorf("merging a log into itself added %d operations", added)
+	}
+
+	// Every operation comes after the ones it depends on
+	for i, op := range left.Ops {
+		for _, earlier := range left.Ops[:i] {
+			if op.Clock.Compare(earlier.Clock) == "before" {
+				t.Errorf("%s is ordered after %s, which depends on it", earlier.ID, op.ID)
+			}
+		}
+	}
+	if want := (VectorClock{"a": 3, "b": 1, "c": 2}); !reflect.DeepEqual(left.Clock, want) {
+		t.Errorf("clock = %v, want %v", left.Clock, want)
+	}
+}
+
+func TestOpLogProcessor(t *testing.T) {
+	dp := NewDataProcessor()
+
+	result, err := dp.OpLogAppend("", "a", map[string]interface{}{"set": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := result["op"].(map[string]interface{})
+	if op["id"] != "a:1" {
+		t.Errorf("op = %v", op)
+	}
+
+	result, err = dp.OpLogAppend(result["json"].(string), "b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logJSON := result["json"].(string)
+
+	since, err := dp.OpLogSince(logJSON, `{"a":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since["count"] != 1 || !reflect.DeepEqual(since["clock"], map[string]interface{}{"a": 1.0, "b": 1.0}) {
+		t.Errorf("since = %v", since)
+	}
+
+	merged, err := dp.OpLogMerge(logJSON, logJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged["added"] != 0 {
+		t.Errorf("merging a log with itself added %v", merged["added"])
+	}
+
+	if _, err := dp.OpLogAppend("", "", nil); err == nil {
+		t.Error("expected an error without a replica ID")
+	}
+	if _, err := dp.OpLogSince("{", ""); err == nil {
+		t.Error("expected an error for an invalid log")
+	}
+	if _, err := dp.OpLogSince("", "[1]"); err == nil {
+		t.Error("expected an error for an invalid clock")
+	}
+	if _, err := dp.OpLogMerge("", "x"); err == nil {
+		t.Error("expected an error for an invalid log")
+	}
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultOutboxLimit caps the requests an Outbox holds
+const DefaultOutboxLimit = 1000
+
+// OutboxEntry is a queued outbound write request
+type OutboxEntry struct {
+	// ID is the client-generated request ID, sent as the Idempotency-Key
+	// so a server can discard a replay of a request it already applied
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Key optionally names the resource the request writes; a newer
+	// request with the same key supersedes an older queued one
+	Key       string `json:"key,omitempty"`
+	Enqueued  int64  `json:"enqueued"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Outbox is a FIFO queue of write requests waiting to be sent. Requests are
+// replayed strictly in order so later writes never overtake earlier ones.
+type Outbox struct {
+	Entries []*OutboxEntry `json:"entries"`
+	Limit   int            `json:"-"`
+}
+
+// NewOutbox creates an empty outbox holding up to DefaultOutboxLimit
+// requests
+func NewOutbox() *Outbox {
+	return &Outbox{Entries: []*OutboxEntry{}, Limit: DefaultOutboxLimit}
+}
+
+// DecodeOutbox restores an outbox saved with Encode
+func DecodeOutbox(data string) (*Outbox, error) {
+	o := NewOutbox()
+	if data == "" {
+		return o, nil
+	}
+	if err := json.Unmarshal([]byte(data), o); err != nil {
+		return nil, fmt.Errorf("invalid outbox: %w", err)
+	}
+	if o.Entries == nil {
+		o.Entries = []*OutboxEntry{}
+	}
+	return o, nil
+}
+
+// Encode serializes the queued requests as JSON
+func (o *Outbox) Encode() (string, error) {
+	data, err := json.Marshal(o)
+	return string(data), err
+}
+
+// index returns the position of the entry with id, or -1
+func (o *Outbox) index(id string) int {
+	for i, e := range o.Entries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Enqueue appends entry. An entry whose ID is already queued is a
+// duplicate and leaves the queue unchanged. A queued entry with the same
+// Key is removed and returned as superseded; the new entry still goes to
+// the back so it cannot overtake writes queued after the old one.
+func (o *Outbox) Enqueue(entry OutboxEntry) (duplicate bool, superseded *OutboxEntry, err error) {
+	if entry.ID == "" {
+		return false, nil, fmt.Errorf("request ID required")
+	}
+	if entry.URL == "" {
+		return false, nil, fmt.Errorf("request URL required")
+	}
+	if o.index(entry.ID) >= 0 {
+		return true, nil, nil
+	}
+
+	match := -1
+	if entry.Key != "" {
+		for i, e := range o.Entries {
+			if e.Key == entry.Key {
+				match = i
+				break
+			}
+		}
+	}
+	size := len(o.Entries)
+	if match >= 0 {
+		size--
+	}
+	if o.Limit > 0 && size >= o.Limit {
+		return false, nil, fmt.Errorf("outbox is full (%d requests)", o.Limit)
+	}
+	if match >= 0 {
+		superseded = o.Entries[match]
+		o.Entries = append(o.Entries[:match], o.Entries[match+1:]...)
+	}
+
+	if entry.Enqueued == 0 {
+		entry.Enqueued = time.Now().UnixMilli()
+	}
+	o.Entries = append(o.Entries, &entry)
+	return false, superseded, nil
+}
+
+// Next returns the oldest queued request, or nil when the queue is empty
+func (o *Outbox) Next() *OutboxEntry {
+	if len(o.Entries) == 0 {
+		return nil
+	}
+	return o.Entries[0]
+}
+
+// Ack removes a request that was delivered, or rejected permanently
+func (o *Outbox) Ack(id string) bool {
+	i := o.index(id)
+	if i < 0 {
+		return false
+	}
+	o.Entries = append(o.Entries[:i], o.Entries[i+1:]...)
+	return true
+}
+
+// Fail records a failed attempt; the request stays queued in place
+func (o *Outbox) Fail(id string, reason string) bool {
+	i := o.index(id)
+	if i < 0 {
+		return false
+	}
+	o.Entries[i].Attempts++
+	o.Entries[i].LastError = reason
+	return true
+}
+
+// Summary lists the queued requests without their bodies
+func (o *Outbox) Summary() map[string]interface{} {
+	entries := make([]interface{}, len(o.Entries))
+	for i, e := range o.Entries {
+		entry := map[string]interface{}{
+			"id":       e.ID,
+			"method":   e.Method,
+			"url":      e.URL,
+			"enqueued": e.Enqueued,
+			"attempts": e.Attempts,
+			"bytes":    len(e.Body),
+		}
+		if e.Key != "" {
+			entry["key"] = e.Key
+		}
+		if e.LastError != "" {
+			entry["lastError"] = e.LastError
+		}
+		entries[i] = entry
+	}
+	return map[string]interface{}{
+		"pending": len(o.Entries),
+		"entries": entries,
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// outboxIDs lists the queued request IDs in order
+func outboxIDs(o *Outbox) []string {
+	ids := []string{}
+	for _, e := range o.Entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestOutboxEnqueue(t *testing.T) {
+	o := NewOutbox()
+	for _, id := range []string{"a", "b", "c"} {
+		if duplicate, superseded, err := o.Enqueue(OutboxEntry{ID: id, Method: "PUT", URL: "/items/" + id}); err != nil || duplicate || superseded != nil {
+			t.Fatalf("Enqueue(%s) = %v, %v, %v", id, duplicate, superseded, err)
+		}
+	}
+
+	// a replayed ID is a duplicate and changes nothing
+	duplicate, _, err := o.Enqueue(OutboxEntry{ID: "b", URL: "/other"})
+	if err != nil || !duplicate {
+		t.Errorf("Enqueue(b again) = %v, %v, want a duplicate", duplicate, err)
+	}
+	if got, want := outboxIDs(o), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+	if o.Entries[0].Enqueued == 0 {
+		t.Error("enqueue time not set")
+	}
+
+	if _, _, err := o.Enqueue(OutboxEntry{URL: "/x"}); err == nil {
+		t.Error("expected an error without an ID")
+	}
+	if _, _, err := o.Enqueue(OutboxEntry{ID: "d"}); err == nil {
+		t.Error("expected an error without a URL")
+	}
+}
+
+func TestOutboxSupersede(t *testing.T) {
+	o := NewOutbox()
+	o.Enqueue(OutboxEntry{ID: "1", URL: "/doc", Key: "doc", Body: "v1"})
+	o.Enqueue(OutboxEntry{ID: "2", URL: "/other"})
+
+	// the newer write to doc replaces the old one at the back of the queue
+	_, superseded, err := o.Enqueue(OutboxEntry{ID: "3", URL: "/doc", Key: "doc", Body: "v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if superseded == nil || superseded.ID != "1" {
+		t.Errorf("superseded = %v, want request 1", superseded)
+	}
+	if got, want := outboxIDs(o), []string{"2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+}
+
+func TestOutboxLimit(t *testing.T) {
+	o := NewOutbox()
+	o.Limit = 2
+	o.Enqueue(OutboxEntry{ID: "1", URL: "/a", Key: "a"})
+	o.Enqueue(OutboxEntry{ID: "2", URL: "/b"})
+	if _, _, err := o.Enqueue(OutboxEntry{ID: "3", URL: "/c"}); err == nil {
+		t.Error("expected an error when the outbox is full")
+	}
+	// superseding frees the slot it replaces
+	if _, superseded, err := o.Enqueue(OutboxEntry{ID: "4", URL: "/a", Key: "a"}); err != nil || superseded == nil {
+		t.Errorf("Enqueue superseding in a full outbox = %v, %v", superseded, err)
+	}
+	if got, want := outboxIDs(o), []string{"2", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+}
+
+func TestOutboxAckAndFail(t *testing.T) {
+	o := NewOutbox()
+	if o.Next() != nil {
+		t.Fatal("Next on an empty outbox should be nil")
+	}
+	o.Enqueue(OutboxEntry{ID: "1", URL: "/a"})
+	o.Enqueue(OutboxEntry{ID: "2", URL: "/b"})
+
+	// a failed request stays at the head so order is kept
+	if !o.Fail("1", "HTTP 503") || !o.Fail("1", "offline") {
+		t.Fatal("Fail(1) = false")
+	}
+	if next := o.Next(); next.ID != "1" || next.Attempts != 2 || next.LastError != "offline" {
+		t.Errorf("Next = %+v", next)
+	}
+
+	if !o.Ack("1") || o.Ack("1") || o.Fail("missing", "x") {
+		t.Error("Ack or Fail reported the wrong result")
+	}
+	if next := o.Next(); next.ID != "2" {
+		t.Errorf("Next = %s, want 2", next.ID)
+	}
+}
+
+func TestOutboxEncode(t *testing.T) {
+	o := NewOutbox()
+	o.Enqueue(OutboxEntry{ID: "1", Method: "POST", URL: "/a", Headers: map[string]string{"X-A": "1"}, Body: `{"n":1}`, Key: "k"})
+	o.Fail("1", "offline")
+
+	data, err := o.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := DecodeOutbox(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Entries, o.Entries) || restored.Limit != DefaultOutboxLimit {
+		t.Errorf("restored = %+v, want %+v", restored, o)
+	}
+
+	for _, data := range []string{"", `{}`, `{"entries":null}`} {
+		o, err := DecodeOutbox(data)
+		if err != nil || o.Entries == nil || len(o.Entries) != 0 {
+			t.Errorf("DecodeOutbox(%q) = %+v, %v", data, o, err)
+		}
+	}
+	if _, err := DecodeOutbox("not json"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+
+	summary := o.Summary()
+	entry := summary["entries"].([]interface{})[0].(map[string]interface{})
+	if summary["pending"] != 1 || entry["bytes"] != 7 || entry["key"] != "k" || entry["lastError"] != "offline" || entry["body"] != nil {
+		t.Errorf("summary = %v", summary)
+	}
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// OutlineHeading is a heading in a document outline. Children are the
+// headings beneath it up to the next heading of the same or a higher level.
+type OutlineHeading struct {
+	Level    int
+	Text     string
+	Slug     string
+	Line     int
+	Children []*OutlineHeading
+}
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnder    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdFence        = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	mdImageOrLink  = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	mdUnderscores  = regexp.MustCompile(`(^|[^\pL\pN])_+([^_]+?)_+([^\pL\pN]|$)`)
+	mdEscape       = regexp.MustCompile(`\\([!-/:-@\[-` + "`" + `{-~])`)
+	htmlTag        = regexp.MustCompile(`<[^>]*>`)
+	htmlHeadingTag = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h([1-6])\s*>`)
+	htmlIDAttr     = regexp.MustCompile(`(?i)\sid\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlSkipBlock  = regexp.MustCompile(`(?is)<!--.*?-->|<(script|style|template)\b.*?</(script|style|template)\s*>`)
+)
+
+// cleanHeadingText strips inline markup and collapses whitespace
+func cleanHeadingText(s string, markdown bool) string {
+	if markdown {
+		s = mdImageOrLink.ReplaceAllString(s, "$1")
+		// strip emphasis between escapes so an escaped \* stays literal
+		var b strings.Builder
+		last := 0
+		for _, m := range mdEscape.FindAllStringSubmatchIndex(s, -1) {
+			b.WriteString(stripEmphasis(s[last:m[0]]))
+			b.WriteString(s[m[2]:m[3]])
+			last = m[1]
+		}
+		b.WriteString(stripEmphasis(s[last:]))
+		s = b.String()
+	}
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// stripEmphasis removes bold, italic, strikethrough and code span markers
+func stripEmphasis(s string) string {
+	s = strings.NewReplacer("**", "", "~~", "", "*", "", "`", "").Replace(s)
+	return mdUnderscores.ReplaceAllString(s, "$1$2$3")
+}
+
+// Slugify turns heading text into an anchor the way GitHub does: lower
+// case, punctuation removed and spaces replaced with hyphens
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// markdownHeadings finds ATX (# Title) and setext (Title over === or ---)
+// headings outside fenced code blocks
+func markdownHeadings(src string) []*OutlineHeading {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	headings := []*OutlineHeading{}
+	fence := ""
+	// paragraph is the previous line when it could be a setext heading
+	paragraph := -1
+	for i, line := range lines {
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(line[len(m[0]):]) == "":
+				// a closing fence takes no info string
+				fence = ""
+			}
+			paragraph = -1
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			headings = append(headings, &OutlineHeading{
+				Level: len(m[1]),
+				Text:  cleanHeadingText(m[2], true),
+				Line:  i + 1,
+			})
+			paragraph = -1
+			continue
+		}
+		if m := setextUnder.FindStringSubmatch(line); m != nil && paragraph >= 0 {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			headings = append(headings, &OutlineHeading{
+				Level: level,
+				Text:  cleanHeadingText(lines[paragraph], true),
+				Line:  paragraph + 1,
+			})
+			paragraph = -1
+			continue
+		}
+
+		paragraph = -1
+		// indented code and blank lines cannot be setext heading text
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "\t") {
+			paragraph = i
+		}
+	}
+	return headings
+}
+
+// htmlHeadings finds <h1> to <h6> elements outside comments, scripts,
+// styles and templates. A heading's id attribute is kept as its slug.
+func htmlHeadings(src string) []*OutlineHeading {
+	// blank out skipped blocks, keeping newlines so line numbers hold
+	src = htmlSkipBlock.ReplaceAllStringFunc(src, func(block string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, block)
+	})
+
+	headings := []*OutlineHeading{}
+	for _, m := range htmlHeadingTag.FindAllStringSubmatchIndex(src, -1) {
+		open, closing := src[m[2]:m[3]], src[m[8]:m[9]]
+		if open != closing {
+			continue
+		}
+		heading := &OutlineHeading{
+			Level: int(open[0] - '0'),
+			Text:  cleanHeadingText(src[m[6]:m[7]], false),
+			Line:  strings.Count(src[:m[0]], "\n") + 1,
+		}
+		if m[4] >= 0 {
+			if id := htmlIDAttr.FindStringSubmatch(src[m[4]:m[5]]); id != nil {
+				heading.Slug = html.UnescapeString(id[1] + id[2] + id[3])
+			}
+		}
+		headings = append(headings, heading)
+	}
+	return headings
+}
+
+// ParseHeadings returns a document's headings in reading order with unique
+// slugs. format is markdown, html or auto, which picks html when the
+// document contains a heading element.
+func ParseHeadings(src, format string) ([]*OutlineHeading, string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "", "auto":
+		format = "markdown"
+		if htmlHeadingTag.MatchString(src) {
+			format = "html"
+		}
+	case "md":
+		format = "markdown"
+	case "htm":
+		format = "html"
+	}
+
+	var headings []*OutlineHeading
+	switch format {
+	case "markdown":
+		headings = markdownHeadings(src)
+	case "html":
+		headings = htmlHeadings(src)
+	default:
+		return nil, "", fmt.Errorf("unsupported format %q (expected markdown, html or auto)", format)
+	}
+
+	// explicit ids are claimed first so generated slugs avoid them
+	used := make(map[string]bool)
+	for _, h := range headings {
+		if h.Slug != "" {
+			used[h.Slug] = true
+		}
+	}
+	for _, h := range headings {
+		if h.Slug != "" {
+			continue
+		}
+		base := Slugify(h.Text)
+		if base == "" {
+			base = "section"
+		}
+		slug := base
+		for n := 1; used[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[slug] = true
+		h.Slug = slug
+	}
+	return headings, format, nil
+}
+
+// BuildOutline nests headings under the nearest preceding heading of a
+// higher level. Skipped levels are not filled in: an h3 straight after an
+// h1 becomes its child.
+func BuildOutline(headings []*OutlineHeading) []*OutlineHeading {
+	roots := []*OutlineHeading{}
+	stack := []*OutlineHeading{}
+	for _, h := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, h)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		stack = append(stack, h)
+	}
+	return roots
+}
+
+// outlineList converts headings to result objects
+func outlineList(headings []*OutlineHeading) []interface{} {
+	list := make([]interface{}, len(headings))
+	for i, h := range headings {
+		list[i] = map[string]interface{}{
+			"text":     h.Text,
+			"level":    h.Level,
+			"slug":     h.Slug,
+			"line":     h.Line,
+			"children": outlineList(h.Children),
+		}
+	}
+	return list
+}
+
+// ExtractOutline returns a Markdown or HTML document's headings as a
+// nested outline for a table of contents, along with a flat list in
+// reading order
+func (dp *DataProcessor) ExtractOutline(src, format string) (map[string]interface{}, error) {
+	headings, format, err := ParseHeadings(src, format)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := make([]interface{}, len(headings))
+	for i, h := range headings {
+		flat[i] = map[string]interface{}{
+			"text":  h.Text,
+			"level": h.Level,
+			"slug":  h.Slug,
+			"line":  h.Line,
+		}
+	}
+
+	return map[string]interface{}{
+		"format":   format,
+		"outline":  outlineList(BuildOutline(headings)),
+		"headings": flat,
+		"count":    len(headings),
+	}, nil
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// headingTexts returns the level and text of each heading
+func headingTexts(headings []*OutlineHeading) []string {
+	out := make([]string, len(headings))
+	for i, h := range headings {
+		out[i] = string(rune('0'+h.Level)) + " " + h.Text
+	}
+	return out
+}
+
+func TestMarkdownHeadings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"atx", "# One\n## Two ##\n###### Six", []string{"1 One", "2 Two", "6 Six"}},
+		{"atx needs a space", "#hashtag\n####### seven", []string{}},
+		{"closing hashes need a space", "# C#", []string{"1 C#"}},
+		{"empty atx", "#", []string{"1 "}},
+		{"indented code", "    # code\n   # three spaces", []string{"1 three spaces"}},
+		{"setext", "Title\n=====\n\nSub\n---", []string{"1 Title", "2 Sub"}},
+		{"rule without paragraph", "text\n\n---", []string{}},
+		{"inline markup", "# **Bold** _em_ `code` [link](http://x) \\*star", []string{"1 Bold em code link *star"}},
+		{"snake case kept", "# snake_case_name", []string{"1 snake_case_name"}},
+		{"entities", "# Fish &amp; Chips", []string{"1 Fish & Chips"}},
+		{"fenced", "```\n# not\n```\n# yes", []string{"1 yes"}},
+		{"tilde fence", "~~~\n# not\n```\n~~~\n# yes", []string{"1 yes"}},
+		{"longer outer fence", "````md\n```\n# not\n```\n````\n# yes", []string{"1 yes"}},
+		{"info string does not close", "```\n```go\n# not\n```\n# yes", []string{"1 yes"}},
+		{"crlf", "Title\r\n===\r\n# Next\r\n", []string{"1 Title", "1 Next"}},
+	}
+	for _, tt := range tests {
+		got := headingTexts(markdownHeadings(tt.src))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: headings = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownHeadingLines(t *testing.T) {
+	headings := markdownHeadings("intro\n\n# A\ntext\n\nB\n---\n")
+	var lines []int
+	for _, h := range headings {
+		lines = append(lines, h.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 6}) {
+		t.Errorf("lines = %v, want [3 6]", lines)
+	}
+}
+
+func TestHTMLHeadings(t *testing.T) {
+	src := "<h1 class=\"x\">Hello <em>World</em></h1>\n" +
+		"<!-- <h2>comment</h2> -->\n" +
+		"<script>\n'<h2>script</h2>'\n</script>\n" +
+		"<H2 id='intro'>Intro &amp; more</H2>\n" +
+		"<h3>mismatched</h4>\n" +
+		"<h3 data-id=\"no\">Three</h3>"
+	headings := htmlHeadings(src)
+	if got := headingTexts(headings); !reflect.DeepEqual(got, []string{"1 Hello World", "2 Intro & more", "3 Three"}) {
+		t.Fatalf("headings = %q", got)
+	}
+	if headings[1].Slug != "intro" || headings[1].Line != 6 {
+		t.Errorf("intro = %+v, want slug intro on line 6", headings[1])
+	}
+	if headings[2].Slug != "" {
+		t.Errorf("data-id taken as id: %q", headings[2].Slug)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello World", "hello-world"},
+		{"What's new?", "whats-new"},
+		{"snake_case and-dash", "snake_case-and-dash"},
+		{"Ünïcode 2", "ünïcode-2"},
+		{"  two  spaces", "--two--spaces"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseHeadingsSlugs(t *testing.T) {
+	headings, _, err := ParseHeadings("# Intro\n# Intro\n# Intro 1\n# ???", "markdown")
+	if err != nil {
+		t.Fatalf("ParseHeadings: %v", err)
+	}
+	var slugs []string
+	for _, h := range headings {
+		slugs = append(slugs, h.Slug)
+	}
+	if want := []string{"intro", "intro-1", "intro-1-1", "section"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("slugs = %q, want %q", slugs, want)
+	}
+
+	// explicit ids are reserved before slugs are generated
+	headings, _, _ = ParseHeadings("<h1>Setup</h1><h2 id=\"setup\">Later</h2>", "html")
+	if headings[0].Slug != "setup-1" || headings[1].Slug != "setup" {
+		t.Errorf("slugs = %q, %q, want setup-1, setup", headings[0].Slug, headings[1].Slug)
+	}
+}
+
+func TestParseHeadingsFormat(t *testing.T) {
+	tests := []struct {
+		src, format, want string
+	}{
+		{"# Title", "", "markdown"},
+		{"<h1>Title</h1>", "auto", "html"},
+		{"# Title", "MD", "markdown"},
+		{"<h1>Title</h1>", "htm", "html"},
+		{"<h1>Title</h1>", " HTML ", "html"},
+	}
+	for _, tt := range tests {
+		_, format, err := ParseHeadings(tt.src, tt.format)
+		if err != nil || format != tt.want {
+			t.Errorf("ParseHeadings(%q, %q) format = %q, %v, want %q", tt.src, tt.format, format, err, tt.want)
+		}
+	}
+	if _, _, err := ParseHeadings("# Title", "rst"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestBuildOutline(t *testing.T) {
+	headings, _, _ := ParseHeadings("# A\n### A1\n## A2\n### A2a\n# B\n## B1", "markdown")
+	roots := BuildOutline(headings)
+	if len(roots) != 2 || roots[0].Text != "A" || roots[1].Text != "B" {
+		t.Fatalf("roots = %q", headingTexts(roots))
+	}
+	// a skipped level nests directly under the nearest higher heading
+	if got := headingTexts(roots[0].Children); !reflect.DeepEqual(got, []string{"3 A1", "2 A2"}) {
+		t.Errorf("A children = %q", got)
+	}
+	if got := headingTexts(roots[0].Children[1].Children); !reflect.DeepEqual(got, []string{"3 A2a"}) {
+		t.Errorf("A2 children = %q", got)
+	}
+
+	// a document starting below h1 has several roots
+	headings, _, _ = ParseHeadings("### Deep\n## Shallower\n### Child", "markdown")
+	if got := headingTexts(BuildOutline(headings)); !reflect.DeepEqual(got, []string{"3 Deep", "2 Shallower"}) {
+		t.Errorf("roots = %q", got)
+	}
+}
+
+func TestExtractOutline(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.ExtractOutline("# Guide\n## Install\n## Use", "")
+	if err != nil {
+		t.Fatalf("ExtractOutline: %v", err)
+	}
+	if result["format"] != "markdown" || result["count"] != 3 || len(result["headings"].([]interface{})) != 3 {
+		t.Errorf("result = %v", result)
+	}
+	outline := result["outline"].([]interface{})
+	root := outline[0].(map[string]interface{})
+	if len(outline) != 1 || root["slug"] != "guide" || len(root["children"].([]interface{})) != 2 {
+		t.Errorf("outline = %v", outline)
+	}
+
+	result, err = dp.ExtractOutline("no headings here", "markdown")
+	if err != nil || result["count"] != 0 || len(result["outline"].([]interface{})) != 0 {
+		t.Errorf("empty outline = %v, %v", result, err)
+	}
+}
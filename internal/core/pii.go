@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// PIICategories lists the kinds of personal data RedactPII can detect, in
+// the order they are applied
+var PIICategories = []string{"email", "creditCard", "phone", "ip"}
+
+var (
+	piiEmailRegex = regexp.MustCompile(emailPattern)
+	piiCardRegex  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	piiPhoneRegex = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\b\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`)
+	piiIPv4Regex  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	piiIPv6Regex  = regexp.MustCompile(`(?i)\b(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}\b`)
+)
+
+var piiPlaceholders = map[string]string{
+	"email":      "[EMAIL]",
+	"creditCard": "[CREDIT_CARD]",
+	"phone":      "[PHONE]",
+	"ip":         "[IP]",
+}
+
+// RedactPII replaces emails, Luhn-valid card numbers, phone numbers and IP
+// addresses in text with placeholders. An empty categories list redacts all
+// of them. Card numbers are redacted before phones so long digit runs are
+// not mistaken for phone numbers.
+func (dp *DataProcessor) RedactPII(text string, categories []string) (map[string]interface{}, error) {
+	enabled := make(map[string]bool)
+	if len(categories) == 0 {
+		categories = PIICategories
+	}
+	for _, c := range categories {
+		if _, ok := piiPlaceholders[c]; !ok {
+			return nil, fmt.Errorf("unknown PII category: %s (expected one of %s)", c, strings.Join(PIICategories, ", "))
+		}
+		enabled[c] = true
+	}
+
+	counts := make(map[string]interface{})
+	total := 0
+	redact := func(category string, re *regexp.Regexp, accept func(string) bool) {
+		if !enabled[category] {
+			return
+		}
+		n := 0
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			if accept != nil && !accept(match) {
+				return match
+			}
+			n++
+			return piiPlaceholders[category]
+		})
+		counts[category] = n
+		total += n
+	}
+
+	redact("email", piiEmailRegex, nil)
+	redact("creditCard", piiCardRegex, LuhnValid)
+	redact("phone", piiPhoneRegex, nil)
+	if enabled["ip"] {
+		redact("ip", piiIPv4Regex, func(s string) bool { return net.ParseIP(s) != nil })
+		v4 := counts["ip"].(int)
+		redact("ip", piiIPv6Regex, func(s string) bool {
+			return strings.Count(s, ":") >= 2 && net.ParseIP(s) != nil
+		})
+		counts["ip"] = counts["ip"].(int) + v4
+		total = 0
+		for _, c := range counts {
+			total += c.(int)
+		}
+	}
+
+	return map[string]interface{}{
+		"redacted": text,
+		"counts":   counts,
+		"total":    total,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactPII(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		categories []string
+		want       string
+		counts     map[string]interface{}
+	}{
+		{
+			name:   "email",
+			text:   "mail ann.lee+x@example.co.uk today",
+			want:   "mail [EMAIL] today",
+			counts: map[string]interface{}{"email": 1, "creditCard": 0, "phone": 0, "ip": 0},
+		},
+		{
+			name:   "card numbers",
+			text:   "card 4111 1111 1111 1111, also 4111-1111-1111-1111 and 5500005555555559",
+			want:   "card [CREDIT_CARD], also [CREDIT_CARD] and [CREDIT_CARD]",
+			counts: map[string]interface{}{"email": 0, "creditCard": 3, "phone": 0, "ip": 0},
+		},
+		{
+			name:   "luhn invalid card is kept",
+			text:   "order 4111111111111112",
+			want:   "order 4111111111111112",
+			counts: map[string]interface{}{"email": 0, "creditCard": 0, "phone": 0, "ip": 0},
+		},
+		{
+			name:   "phones",
+			text:   "call (555) 123-4567 or +1 555.123.4567 or 555 123 4567",
+			want:   "call [PHONE] or [PHONE] or [PHONE]",
+			counts: map[string]interface{}{"email": 0, "creditCard": 0, "phone": 3, "ip": 0},
+		},
+		{
+			name:   "ip addresses",
+			text:   "from 192.168.0.1 and 2001:db8::1, not 999.1.1.1 or 12:30:45",
+			want:   "from [IP] and [IP], not 999.1.1.1 or 12:30:45",
+			counts: map[string]interface{}{"email": 0, "creditCard": 0, "phone": 0, "ip": 2},
+		},
+		{
+			name:       "selected categories",
+			text:       "ann@example.com from 10.0.0.1",
+			categories: []string{"ip"},
+			want:       "ann@example.com from [IP]",
+			counts:     map[string]interface{}{"ip": 1},
+		},
+		{
+			name:   "nothing to redact",
+			text:   "version 1.2.3 shipped",
+			want:   "version 1.2.3 shipped",
+			counts: map[string]interface{}{"email": 0, "creditCard": 0, "phone": 0, "ip": 0},
+		},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.RedactPII(tt.text, tt.categories)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["redacted"] != tt.want {
+				t.Errorf("redacted = %q, want %q", result["redacted"], tt.want)
+			}
+			if !reflect.DeepEqual(result["counts"], tt.counts) {
+				t.Errorf("counts = %v, want %v", result["counts"], tt.counts)
+			}
+			total := 0
+			for _, n := range tt.counts {
+				total += n.(int)
+			}
+			if result["total"] != total {
+				t.Errorf("total = %v, want %d", result["total"], total)
+			}
+		})
+	}
+
+	if _, err := dp.RedactPII("x", []string{"ssn"}); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"79927398713", true},
+		{"79927398710", false},
+		{"4111 1111 1111 1111", true},
+		{"4111-1111-1111-1112", false},
+		{"0", false},
+		{"00", true},
+		{"", false},
+		{"4111a111", false},
+	}
+
+	for _, tt := range tests {
+		if got := LuhnValid(tt.number); got != tt.want {
+			t.Errorf("LuhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
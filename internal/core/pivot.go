@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// pivotAggregates are the aggregation functions a pivot table supports
+var pivotAggregates = map[string]func([]float64) float64{
+	"sum": func(v []float64) float64 {
+		total := 0.0
+		for _, f := range v {
+			total += f
+		}
+		return total
+	},
+	"count": func(v []float64) float64 { return float64(len(v)) },
+	"mean": func(v []float64) float64 {
+		total := 0.0
+		for _, f := range v {
+			total += f
+		}
+		return total / float64(len(v))
+	},
+	"min": func(v []float64) float64 {
+		m := v[0]
+		for _, f := range v[1:] {
+			m = min(m, f)
+		}
+		return m
+	},
+	"max": func(v []float64) float64 {
+		m := v[0]
+		for _, f := range v[1:] {
+			m = max(m, f)
+		}
+		return m
+	},
+}
+
+// sortedGroupIndex sorts groups by value and maps each to its position
+func sortedGroupIndex(groups []*valueGroup) map[*valueGroup]int {
+	sort.SliceStable(groups, func(i, j int) bool {
+		return compareGroupValues(groups[i].value, groups[j].value, groups[i].key, groups[j].key) < 0
+	})
+	index := make(map[*valueGroup]int, len(groups))
+	for i, g := range groups {
+		index[g] = i
+	}
+	return index
+}
+
+// Pivot builds a pivot table from an array of objects: one row per value
+// of rowsBy, one column per value of columnsBy, and in each cell the
+// aggregate (sum, count, mean, min or max) of valueField over the matching
+// objects. Row and column totals aggregate the underlying values rather
+// than the cells, so a mean total is the mean of all values in the row.
+// Rows and columns are sorted by value. Combinations without values are
+// filled with zero when fillZero is set and null otherwise. Non-numeric
+// values are skipped; with count, an empty valueField counts objects.
+func (dp *DataProcessor) Pivot(rows []interface{}, rowsBy, columnsBy, valueField, aggregate string, fillZero bool) (map[string]interface{}, error) {
+	if aggregate == "" {
+		aggregate = "sum"
+	}
+	aggregateFn, ok := pivotAggregates[aggregate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported aggregate %q (expected sum, count, mean, min or max)", aggregate)
+	}
+	rowOf, err := parseRowField(rowsBy)
+	if err != nil {
+		return nil, fmt.Errorf("row field: %w", err)
+	}
+	columnOf, err := parseRowField(columnsBy)
+	if err != nil {
+		return nil, fmt.Errorf("column field: %w", err)
+	}
+	var valueOf rowField
+	if valueField != "" || aggregate != "count" {
+		if valueOf, err = parseRowField(valueField); err != nil {
+			return nil, fmt.Errorf("value field: %w", err)
+		}
+	}
+
+	rowGroups, rowOfItem, err := groupRows(rows, rowOf)
+	if err != nil {
+		return nil, err
+	}
+	columnGroups, columnOfItem, err := groupRows(rows, columnOf)
+	if err != nil {
+		return nil, err
+	}
+	rowIndex := sortedGroupIndex(rowGroups)
+	columnIndex := sortedGroupIndex(columnGroups)
+
+	cells := make([][][]float64, len(rowGroups))
+	for i := range cells {
+		cells[i] = make([][]float64, len(columnGroups))
+	}
+	var all []float64
+	skipped := 0
+	for i, item := range rows {
+		value := 1.0
+		if valueOf != nil {
+			raw, _ := valueOf.get(item)
+			f, ok := numericValue(raw)
+			if !ok {
+				skipped++
+				continue
+			}
+			value = f
+		}
+		r, c := rowIndex[rowOfItem[i]], columnIndex[columnOfItem[i]]
+		cells[r][c] = append(cells[r][c], value)
+		rowOfItem[i].values = append(rowOfItem[i].values, value)
+		columnOfItem[i].values = append(columnOfItem[i].values, value)
+		all = append(all, value)
+	}
+
+	var empty interface{}
+	if fillZero {
+		empty = 0.0
+	}
+	cell := func(values []float64) interface{} {
+		if len(values) == 0 {
+			return empty
+		}
+		return aggregateFn(values)
+	}
+
+	rowKeys := make([]interface{}, len(rowGroups))
+	rowTotals := make([]interface{}, len(rowGroups))
+	matrix := make([]interface{}, len(rowGroups))
+	for r, g := range rowGroups {
+		rowKeys[r] = g.value
+		rowTotals[r] = cell(g.values)
+		line := make([]interface{}, len(columnGroups))
+		for c := range columnGroups {
+			line[c] = cell(cells[r][c])
+		}
+		matrix[r] = line
+	}
+	columnKeys := make([]interface{}, len(columnGroups))
+	columnTotals := make([]interface{}, len(columnGroups))
+	for c, g := range columnGroups {
+		columnKeys[c] = g.value
+		columnTotals[c] = cell(g.values)
+	}
+
+	return map[string]interface{}{
+		"aggregate":    aggregate,
+		"rows":         rowKeys,
+		"columns":      columnKeys,
+		"matrix":       matrix,
+		"rowTotals":    rowTotals,
+		"columnTotals": columnTotals,
+		"grandTotal":   cell(all),
+		"skipped":      skipped,
+	}, nil
+}
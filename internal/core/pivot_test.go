@@ -0,0 +1,125 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func pivotRows() []interface{} {
+	row := func(region, quarter string, amount interface{}) interface{} {
+		return map[string]interface{}{"region": region, "quarter": quarter, "amount": amount}
+	}
+	return []interface{}{
+		row("west", "Q2", 10.0),
+		row("east", "Q1", 4.0),
+		row("west", "Q1", 6.0),
+		row("west", "Q2", 20.0),
+		row("east", "Q1", "n/a"),
+		row("north", "Q1", 3.0),
+	}
+}
+
+func TestPivot(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Pivot(pivotRows(), "region", "quarter", "amount", "", false)
+	if err != nil {
+		t.Fatalf("Pivot: %v", err)
+	}
+	want := map[string]interface{}{
+		"aggregate": "sum",
+		"rows":      []interface{}{"east", "north", "west"},
+		"columns":   []interface{}{"Q1", "Q2"},
+		"matrix": []interface{}{
+			[]interface{}{4.0, nil},
+			[]interface{}{3.0, nil},
+			[]interface{}{6.0, 30.0},
+		},
+		"rowTotals":    []interface{}{4.0, 3.0, 36.0},
+		"columnTotals": []interface{}{13.0, 30.0},
+		"grandTotal":   43.0,
+		"skipped":      1,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Pivot = %v, want %v", result, want)
+	}
+}
+
+func TestPivotAggregates(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		aggregate  string
+		westQ2     interface{}
+		westTotal  interface{}
+		grandTotal interface{}
+	}{
+		{"count", 2.0, 3.0, 5.0},
+		{"mean", 15.0, 12.0, 8.6},
+		{"min", 10.0, 6.0, 3.0},
+		{"max", 20.0, 20.0, 20.0},
+	}
+	for _, tt := range tests {
+		result, err := dp.Pivot(pivotRows(), "region", "quarter", "amount", tt.aggregate, false)
+		if err != nil {
+			t.Fatalf("Pivot(%s): %v", tt.aggregate, err)
+		}
+		west := result["matrix"].([]interface{})[2].([]interface{})
+		if west[1] != tt.westQ2 {
+			t.Errorf("%s: west Q2 = %v, want %v", tt.aggregate, west[1], tt.westQ2)
+		}
+		// totals aggregate the values, not the cells
+		if got := result["rowTotals"].([]interface{})[2]; got != tt.westTotal {
+			t.Errorf("%s: west total = %v, want %v", tt.aggregate, got, tt.westTotal)
+		}
+		if result["grandTotal"] != tt.grandTotal {
+			t.Errorf("%s: grand total = %v, want %v", tt.aggregate, result["grandTotal"], tt.grandTotal)
+		}
+	}
+}
+
+func TestPivotCountRows(t *testing.T) {
+	dp := NewDataProcessor()
+	// with no value field, count counts objects including non-numeric ones
+	result, err := dp.Pivot(pivotRows(), "region", "quarter", "", "count", true)
+	if err != nil {
+		t.Fatalf("Pivot: %v", err)
+	}
+	matrix := []interface{}{
+		[]interface{}{2.0, 0.0},
+		[]interface{}{1.0, 0.0},
+		[]interface{}{1.0, 2.0},
+	}
+	if !reflect.DeepEqual(result["matrix"], matrix) || result["grandTotal"] != 6.0 || result["skipped"] != 0 {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestPivotFillZero(t *testing.T) {
+	dp := NewDataProcessor()
+	rows := []interface{}{map[string]interface{}{"r": "a", "c": "x", "v": "none"}}
+	result, err := dp.Pivot(rows, "r", "c", "v", "sum", true)
+	if err != nil {
+		t.Fatalf("Pivot: %v", err)
+	}
+	// a group whose only values are skipped is still listed
+	if !reflect.DeepEqual(result["matrix"], []interface{}{[]interface{}{0.0}}) || result["grandTotal"] != 0.0 {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestPivotErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name                                     string
+		rowsBy, columnsBy, valueField, aggregate string
+	}{
+		{"unknown aggregate", "region", "quarter", "amount", "median"},
+		{"no row field", "", "quarter", "amount", "sum"},
+		{"no column field", "region", "", "amount", "sum"},
+		{"no value field", "region", "quarter", "", "sum"},
+	}
+	for _, tt := range tests {
+		if _, err := dp.Pivot(pivotRows(), tt.rowsBy, tt.columnsBy, tt.valueField, tt.aggregate, false); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "context"
+
+// ProgressFunc receives how much of a computation is done out of total
+type ProgressFunc func(done, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context that carries fn to long-running
+// computations, which report through ReportProgress
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress passes progress to the context's ProgressFunc, if any.
+// Computations call it where they already check ctx for cancellation.
+func ReportProgress(ctx context.Context, done, total int) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && total > 0 {
+		fn(done, total)
+	}
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Set sketch bounds. More buckets narrow the candidates when replicas
+// differ in many items, at the cost of a larger sketch to exchange.
+const (
+	DefaultSketchBuckets = 128
+	MaxSketchBuckets     = 65536
+)
+
+// SetSketch summarizes a set of items, each an ID with a version value
+// such as a content hash or update timestamp. Every item is assigned to a
+// bucket by its ID, and a bucket's hash is the XOR of its items'
+// SHA-256(id || 0x00 || version) digests, so it does not depend on order.
+// Two replicas exchange sketches and only need to compare the items in
+// buckets whose hash or count differs.
+type SetSketch struct {
+	Hashes [][sha256.Size]byte
+	Counts []int
+}
+
+// sketchBucket assigns an ID to one of n buckets
+func sketchBucket(id string, n int) int {
+	sum := sha256.Sum256([]byte(id))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(n))
+}
+
+// sketchDigest hashes an item's ID and version together
+func sketchDigest(id string, version interface{}) ([sha256.Size]byte, error) {
+	data, err := merkleItemBytes(version)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("item %q: %w", id, err)
+	}
+	buf := make([]byte, 0, len(id)+1+len(data))
+	buf = append(buf, id...)
+	buf = append(buf, 0x00)
+	buf = append(buf, data...)
+	return sha256.Sum256(buf), nil
+}
+
+// BuildSetSketch summarizes items, a map of ID to version, into buckets
+func BuildSetSketch(ctx context.Context, items map[string]interface{}, buckets int) (*SetSketch, error) {
+	if buckets < 1 || buckets > MaxSketchBuckets {
+		return nil, fmt.Errorf("buckets must be between 1 and %d", MaxSketchBuckets)
+	}
+	s := &SetSketch{
+		Hashes: make([][sha256.Size]byte, buckets),
+		Counts: make([]int, buckets),
+	}
+	n := 0
+	for id, version := range items {
+		if n++; n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ReportProgress(ctx, n, len(items))
+		}
+		digest, err := sketchDigest(id, version)
+		if err != nil {
+			return nil, err
+		}
+		b := sketchBucket(id, buckets)
+		for i := range digest {
+			s.Hashes[b][i] ^= digest[i]
+		}
+		s.Counts[b]++
+	}
+	return s, nil
+}
+
+// DiffBuckets returns the indexes of buckets whose hash or count differs
+func (s *SetSketch) DiffBuckets(other *SetSketch) ([]int, error) {
+	if len(s.Hashes) != len(other.Hashes) {
+		return nil, fmt.Errorf("sketches have %d and %d buckets; both sides must use the same count", len(s.Hashes), len(other.Hashes))
+	}
+	diff := []int{}
+	for i := range s.Hashes {
+		if s.Hashes[i] != other.Hashes[i] || s.Counts[i] != other.Counts[i] {
+			diff = append(diff, i)
+		}
+	}
+	return diff, nil
+}
+
+// idsInBuckets lists the sorted IDs of items that fall in the given buckets
+func idsInBuckets(items map[string]interface{}, buckets []int, n int) []string {
+	wanted := make(map[int]bool, len(buckets))
+	for _, b := range buckets {
+		wanted[b] = true
+	}
+	ids := []string{}
+	for id := range items {
+		if wanted[sketchBucket(id, n)] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// idList converts IDs to a result array
+func idList(ids []string) []interface{} {
+	out := make([]interface{}, len(ids))
+	for i, id := range ids {
+		out[i] = id
+	}
+	return out
+}
+
+// bucketList converts bucket indexes to a result array
+func bucketList(buckets []int) []interface{} {
+	out := make([]interface{}, len(buckets))
+	for i, b := range buckets {
+		out[i] = b
+	}
+	return out
+}
+
+// sketchMap converts a sketch to a result object
+func sketchMap(s *SetSketch) map[string]interface{} {
+	hashes := make([]interface{}, len(s.Hashes))
+	counts := make([]interface{}, len(s.Counts))
+	total := 0
+	for i := range s.Hashes {
+		hashes[i] = hex.EncodeToString(s.Hashes[i][:])
+		counts[i] = s.Counts[i]
+		total += s.Counts[i]
+	}
+	return map[string]interface{}{
+		"buckets": hashes,
+		"counts":  counts,
+		"items":   total,
+	}
+}
+
+// parseSketch reads a sketch object as produced by sketchMap
+func parseSketch(v map[string]interface{}) (*SetSketch, error) {
+	hashes, ok := v["buckets"].([]interface{})
+	if !ok || len(hashes) == 0 || len(hashes) > MaxSketchBuckets {
+		return nil, fmt.Errorf("sketch must have a buckets array of 1 to %d hashes", MaxSketchBuckets)
+	}
+	counts, ok := v["counts"].([]interface{})
+	if !ok || len(counts) != len(hashes) {
+		return nil, fmt.Errorf("sketch must have a counts array matching its buckets")
+	}
+
+	s := &SetSketch{
+		Hashes: make([][sha256.Size]byte, len(hashes)),
+		Counts: make([]int, len(counts)),
+	}
+	for i := range hashes {
+		str, _ := hashes[i].(string)
+		h, err := parseMerkleHash(str)
+		if err != nil {
+			return nil, fmt.Errorf("sketch bucket %d: %w", i, err)
+		}
+		s.Hashes[i] = h
+		switch count := counts[i].(type) {
+		case float64:
+			s.Counts[i] = int(count)
+		case int:
+			s.Counts[i] = count
+		default:
+			s.Counts[i] = -1
+		}
+		if s.Counts[i] < 0 {
+			return nil, fmt.Errorf("sketch count %d must be a non-negative number", i)
+		}
+	}
+	return s, nil
+}
+
+// SetSketch summarizes items, an object of ID to version, for exchange
+// with another replica
+func (dp *DataProcessor) SetSketch(ctx context.Context, items map[string]interface{}, buckets int) (map[string]interface{}, error) {
+	s, err := BuildSetSketch(ctx, items, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return sketchMap(s), nil
+}
+
+// ReconcileSets compares local items with the other replica. remote is
+// either its sketch, which gives the local IDs in differing buckets as
+// candidates to send or re-check (the other side does the same with this
+// side's sketch), or its full items object, which gives the exact
+// differences. buckets is ignored when remote is a sketch.
+func (dp *DataProcessor) ReconcileSets(ctx context.Context, local map[string]interface{}, remote map[string]interface{}, buckets int) (map[string]interface{}, error) {
+	if _, isSketch := remote["buckets"].([]interface{}); isSketch {
+		remoteSketch, err := parseSketch(remote)
+		if err != nil {
+			return nil, err
+		}
+		localSketch, err := BuildSetSketch(ctx, local, len(remoteSketch.Hashes))
+		if err != nil {
+			return nil, err
+		}
+		diff, err := localSketch.DiffBuckets(remoteSketch)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := idsInBuckets(local, diff, len(remoteSketch.Hashes))
+		return map[string]interface{}{
+			"differingBuckets": bucketList(diff),
+			"candidates":       idList(candidates),
+			"bucketCount":      len(remoteSketch.Hashes),
+			"identical":        len(diff) == 0,
+		}, nil
+	}
+
+	localSketch, err := BuildSetSketch(ctx, local, buckets)
+	if err != nil {
+		return nil, err
+	}
+	remoteSketch, err := BuildSetSketch(ctx, remote, buckets)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := localSketch.DiffBuckets(remoteSketch)
+	if err != nil {
+		return nil, err
+	}
+
+	// only items in differing buckets can differ
+	onlyLocal, onlyRemote, changed := []string{}, []string{}, []string{}
+	for _, id := range idsInBuckets(local, diff, buckets) {
+		remoteVersion, ok := remote[id]
+		if !ok {
+			onlyLocal = append(onlyLocal, id)
+			continue
+		}
+		a, _ := sketchDigest(id, local[id])
+		b, _ := sketchDigest(id, remoteVersion)
+		if a != b {
+			changed = append(changed, id)
+		}
+	}
+	for _, id := range idsInBuckets(remote, diff, buckets) {
+		if _, ok := local[id]; !ok {
+			onlyRemote = append(onlyRemote, id)
+		}
+	}
+
+	return map[string]interface{}{
+		"differingBuckets": bucketList(diff),
+		"onlyLocal":        idList(onlyLocal),
+		"onlyRemote":       idList(onlyRemote),
+		"changed":          idList(changed),
+		"bucketCount":      buckets,
+		"identical":        len(diff) == 0,
+	}, nil
+}
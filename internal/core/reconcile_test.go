@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSetSketchOrderIndependent(t *testing.T) {
+	items := map[string]interface{}{}
+	for i := 0; i < 500; i++ {
+		items[fmt.Sprintf("id-%d", i)] = float64(i)
+	}
+	a, err := BuildSetSketch(context.Background(), items, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := BuildSetSketch(context.Background(), items, 16)
+	if diff, _ := a.DiffBuckets(b); len(diff) != 0 {
+		t.Errorf("identical sets differ in buckets %v", diff)
+	}
+
+	total := 0
+	for _, n := range a.Counts {
+		total += n
+	}
+	if total != 500 {
+		t.Errorf("counts add up to %d, want 500", total)
+	}
+
+	items["id-7"] = "changed"
+	c, _ := BuildSetSketch(context.Background(), items, 16)
+	diff, _ := a.DiffBuckets(c)
+	if want := []int{sketchBucket("id-7", 16)}; !reflect.DeepEqual(diff, want) {
+		t.Errorf("DiffBuckets = %v, want %v", diff, want)
+	}
+
+	other, _ := BuildSetSketch(context.Background(), items, 8)
+	if _, err := a.DiffBuckets(other); err == nil {
+		t.Error("expected an error for sketches of different sizes")
+	}
+	for _, buckets := range []int{0, MaxSketchBuckets + 1} {
+		if _, err := BuildSetSketch(context.Background(), items, buckets); err == nil {
+			t.Errorf("buckets %d: expected an error", buckets)
+		}
+	}
+}
+
+func TestReconcileSetsFull(t *testing.T) {
+	local := map[string]interface{}{"a": 1.0, "b": "v1", "c": map[string]interface{}{"x": 1.0}, "d": true}
+	remote := map[string]interface{}{"a": 1, "b": "v2", "c": map[string]interface{}{"x": 1}, "e": nil}
+
+	dp := NewDataProcessor()
+	result, err := dp.ReconcileSets(context.Background(), local, remote, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]interface{}{
+		"onlyLocal":  {"d"},
+		"onlyRemote": {"e"},
+		"changed":    {"b"},
+	}
+	for key, ids := range want {
+		if !reflect.DeepEqual(result[key], ids) {
+			t.Errorf("%s = %v, want %v", key, result[key], ids)
+		}
+	}
+	if result["identical"] != false || result["bucketCount"] != 4 {
+		t.Errorf("result = %v", result)
+	}
+
+	result, _ = dp.ReconcileSets(context.Background(), local, local, 4)
+	if result["identical"] != true || len(result["changed"].([]interface{})) != 0 {
+		t.Errorf("reconciling a set with itself = %v", result)
+	}
+}
+
+func TestReconcileSetsSketch(t *testing.T) {
+	dp := NewDataProcessor()
+	remote := map[string]interface{}{"a": 1.0, "b": 2.0, "c": 3.0}
+	local := map[string]interface{}{"a": 1.0, "b": 20.0, "c": 3.0, "d": 4.0}
+
+	// the sketch goes through JSON as it would between replicas
+	sketch, err := dp.SetSketch(context.Background(), remote, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(sketch)
+	var received map[string]interface{}
+	json.Unmarshal(data, &received)
+	if received["items"] != 3.0 {
+		t.Errorf("items = %v, want 3", received["items"])
+	}
+
+	result, err := dp.ReconcileSets(context.Background(), local, received, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every differing item is a candidate; others may share a bucket
+	candidates := map[interface{}]bool{}
+	for _, id := range result["candidates"].([]interface{}) {
+		candidates[id] = true
+	}
+	if !candidates["b"] || !candidates["d"] || result["bucketCount"] != 64 || result["identical"] != false {
+		t.Errorf("result = %v", result)
+	}
+
+	result, _ = dp.ReconcileSets(context.Background(), remote, received, 0)
+	if result["identical"] != true || len(result["candidates"].([]interface{})) != 0 {
+		t.Errorf("reconciling against its own sketch = %v", result)
+	}
+}
+
+func TestReconcileSetsBadSketch(t *testing.T) {
+	hash := fmt.Sprintf("%064x", 0)
+	tests := []map[string]interface{}{
+		{"buckets": []interface{}{}},
+		{"buckets": []interface{}{hash}},
+		{"buckets": []interface{}{hash}, "counts": []interface{}{1.0, 2.0}},
+		{"buckets": []interface{}{"xyz"}, "counts": []interface{}{1.0}},
+		{"buckets": []interface{}{hash}, "counts": []interface{}{-1.0}},
+		{"buckets": []interface{}{hash}, "counts": []interface{}{"one"}},
+	}
+	dp := NewDataProcessor()
+	for _, sketch := range tests {
+		if _, err := dp.ReconcileSets(context.Background(), map[string]interface{}{}, sketch, 0); err == nil {
+			t.Errorf("%v: expected an error", sketch)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+)
+
+// MaxResizeDimension caps each side of a resized image
+const MaxResizeDimension = 4096
+
+// ResizeOptions controls ResizeImage
+type ResizeOptions struct {
+	// Width and Height are the target size; with contain, either may be 0
+	// to derive it from the aspect ratio
+	Width, Height int
+	// Fit is contain (default, fit inside the box), cover (fill the box and
+	// crop the overflow) or stretch (ignore the aspect ratio)
+	Fit string
+	// Format is png or jpeg; by default the input format is kept
+	Format string
+	// Quality is the JPEG quality from 1 to 100 (default 85)
+	Quality int
+}
+
+// resizeGeometry works out the scaled size and the crop applied to it for a
+// source of sw x sh pixels
+func resizeGeometry(sw, sh int, opts ResizeOptions) (scaledW, scaledH int, crop image.Rectangle, err error) {
+	w, h := opts.Width, opts.Height
+	if w < 0 || h < 0 || w == 0 && h == 0 {
+		return 0, 0, crop, fmt.Errorf("width or height must be positive")
+	}
+	if w > MaxResizeDimension || h > MaxResizeDimension {
+		return 0, 0, crop, fmt.Errorf("target size is limited to %d pixels per side", MaxResizeDimension)
+	}
+	aspect := float64(sw) / float64(sh)
+
+	switch opts.Fit {
+	case "", "contain":
+		if w == 0 || h != 0 && float64(w)/float64(h) > aspect {
+			// the height is the limiting side
+			scaledH = h
+			scaledW = int(math.Round(float64(h) * aspect))
+		} else {
+			scaledW = w
+			scaledH = int(math.Round(float64(w) / aspect))
+		}
+	case "cover", "stretch":
+		if w == 0 || h == 0 {
+			return 0, 0, crop, fmt.Errorf("%s needs both width and height", opts.Fit)
+		}
+		if opts.Fit == "stretch" {
+			scaledW, scaledH = w, h
+			break
+		}
+		if float64(w)/float64(h) > aspect {
+			scaledW = w
+			scaledH = int(math.Round(float64(w) / aspect))
+		} else {
+			scaledH = h
+			scaledW = int(math.Round(float64(h) * aspect))
+		}
+	default:
+		return 0, 0, crop, fmt.Errorf("unknown fit %q (expected contain, cover or stretch)", opts.Fit)
+	}
+
+	scaledW, scaledH = max(scaledW, 1), max(scaledH, 1)
+	if scaledW > MaxResizeDimension || scaledH > MaxResizeDimension {
+		return 0, 0, crop, fmt.Errorf("resized image would be %dx%d; the limit is %d pixels per side", scaledW, scaledH, MaxResizeDimension)
+	}
+	crop = image.Rect(0, 0, scaledW, scaledH)
+	if opts.Fit == "cover" {
+		x0, y0 := (scaledW-w)/2, (scaledH-h)/2
+		crop = image.Rect(x0, y0, x0+w, y0+h)
+	}
+	return scaledW, scaledH, crop, nil
+}
+
+// scaleImage resamples src to w x h. Enlarging and mild shrinking use
+// bilinear interpolation; shrinking by more than half averages every source
+// pixel under each destination pixel so fine detail does not alias.
+// Channels are premultiplied so transparent pixels do not bleed colour.
+func scaleImage(ctx context.Context, src image.Image, w, h int) (*image.RGBA, error) {
+	b := src.Bounds()
+	in, ok := src.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		in = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(in, in.Bounds(), src, b.Min, draw.Src)
+	}
+	sw, sh := in.Rect.Dx(), in.Rect.Dy()
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	sx, sy := float64(sw)/float64(w), float64(sh)/float64(h)
+
+	pixel := func(x, y int) []uint8 {
+		i := in.PixOffset(x, y)
+		return in.Pix[i : i+4]
+	}
+
+	for y := 0; y < h; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ReportProgress(ctx, y, h)
+		for x := 0; x < w; x++ {
+			var acc [4]float64
+			if sx > 2 || sy > 2 {
+				x0, x1 := int(float64(x)*sx), int(math.Ceil(float64(x+1)*sx))
+				y0, y1 := int(float64(y)*sy), int(math.Ceil(float64(y+1)*sy))
+				x1, y1 = min(x1, sw), min(y1, sh)
+				n := float64((x1 - x0) * (y1 - y0))
+				for yy := y0; yy < y1; yy++ {
+					for xx := x0; xx < x1; xx++ {
+						p := pixel(xx, yy)
+						for c := 0; c < 4; c++ {
+							acc[c] += float64(p[c])
+						}
+					}
+				}
+				for c := range acc {
+					acc[c] /= n
+				}
+			} else {
+				// sample at pixel centres
+				fx := math.Max(0, math.Min((float64(x)+0.5)*sx-0.5, float64(sw-1)))
+				fy := math.Max(0, math.Min((float64(y)+0.5)*sy-0.5, float64(sh-1)))
+				x0, y0 := int(fx), int(fy)
+				x1, y1 := min(x0+1, sw-1), min(y0+1, sh-1)
+				tx, ty := fx-float64(x0), fy-float64(y0)
+				p00, p10, p01, p11 := pixel(x0, y0), pixel(x1, y0), pixel(x0, y1), pixel(x1, y1)
+				for c := 0; c < 4; c++ {
+					top := float64(p00[c])*(1-tx) + float64(p10[c])*tx
+					bottom := float64(p01[c])*(1-tx) + float64(p11[c])*tx
+					acc[c] = top*(1-ty) + bottom*ty
+				}
+			}
+			o := out.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				out.Pix[o+c] = uint8(math.Round(acc[c]))
+			}
+		}
+	}
+	return out, nil
+}
+
+// ResizeImage decodes a PNG or JPEG, resizes it according to opts and
+// re-encodes it
+func ResizeImage(ctx context.Context, data []byte, opts ResizeOptions) ([]byte, image.Rectangle, string, error) {
+	img, format, err := DecodeImage(data)
+	if err != nil {
+		return nil, image.Rectangle{}, "", err
+	}
+	scaledW, scaledH, crop, err := resizeGeometry(img.Bounds().Dx(), img.Bounds().Dy(), opts)
+	if err != nil {
+		return nil, image.Rectangle{}, "", err
+	}
+
+	scaled, err := scaleImage(ctx, img, scaledW, scaledH)
+	if err != nil {
+		return nil, image.Rectangle{}, "", err
+	}
+	result := scaled.SubImage(crop)
+
+	if opts.Format != "" {
+		format = opts.Format
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		err = png.Encode(&buf, result)
+	case "jpeg", "jpg":
+		format = "jpeg"
+		quality := opts.Quality
+		if quality <= 0 {
+			quality = 85
+		}
+		err = jpeg.Encode(&buf, result, &jpeg.Options{Quality: min(quality, 100)})
+	default:
+		return nil, image.Rectangle{}, "", fmt.Errorf("unknown output format %q (expected png or jpeg)", format)
+	}
+	if err != nil {
+		return nil, image.Rectangle{}, "", fmt.Errorf("failed to encode %s: %w", format, err)
+	}
+	return buf.Bytes(), result.Bounds(), format, nil
+}
+
+// ResizeImage resizes an image and returns it as base64 with its new size
+func (dp *DataProcessor) ResizeImage(ctx context.Context, data []byte, opts ResizeOptions) (map[string]interface{}, error) {
+	out, bounds, format, err := ResizeImage(ctx, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	fit := opts.Fit
+	if fit == "" {
+		fit = "contain"
+	}
+
+	return map[string]interface{}{
+		"image":  base64.StdEncoding.EncodeToString(out),
+		"width":  bounds.Dx(),
+		"height": bounds.Dy(),
+		"format": format,
+		"fit":    fit,
+		"size":   len(out),
+	}, nil
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeGeometry(t *testing.T) {
+	tests := []struct {
+		name             string
+		sw, sh           int
+		opts             ResizeOptions
+		scaledW, scaledH int
+		crop             image.Rectangle
+	}{
+		{"contain by width", 400, 200, ResizeOptions{Width: 100}, 100, 50, image.Rect(0, 0, 100, 50)},
+		{"contain by height", 400, 200, ResizeOptions{Height: 50}, 100, 50, image.Rect(0, 0, 100, 50)},
+		{"contain in a tall box", 400, 200, ResizeOptions{Width: 100, Height: 100}, 100, 50, image.Rect(0, 0, 100, 50)},
+		{"contain in a wide box", 200, 400, ResizeOptions{Width: 100, Height: 100, Fit: "contain"}, 50, 100, image.Rect(0, 0, 50, 100)},
+		{"cover crops the sides", 400, 200, ResizeOptions{Width: 100, Height: 100, Fit: "cover"}, 200, 100, image.Rect(50, 0, 150, 100)},
+		{"cover crops top and bottom", 200, 400, ResizeOptions{Width: 100, Height: 100, Fit: "cover"}, 100, 200, image.Rect(0, 50, 100, 150)},
+		{"stretch", 400, 200, ResizeOptions{Width: 30, Height: 70, Fit: "stretch"}, 30, 70, image.Rect(0, 0, 30, 70)},
+		{"at least one pixel", 1000, 1, ResizeOptions{Width: 10}, 10, 1, image.Rect(0, 0, 10, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, crop, err := resizeGeometry(tt.sw, tt.sh, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w != tt.scaledW || h != tt.scaledH || crop != tt.crop {
+				t.Errorf("resizeGeometry = %dx%d %v, want %dx%d %v", w, h, crop, tt.scaledW, tt.scaledH, tt.crop)
+			}
+		})
+	}
+}
+
+func TestResizeGeometryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sw, sh int
+		opts   ResizeOptions
+	}{
+		{"no size", 10, 10, ResizeOptions{}},
+		{"negative", 10, 10, ResizeOptions{Width: -1, Height: 5}},
+		{"too large", 10, 10, ResizeOptions{Width: MaxResizeDimension + 1}},
+		{"scaled side too large", 1, 100, ResizeOptions{Width: 100}},
+		{"cover without height", 10, 10, ResizeOptions{Width: 5, Fit: "cover"}},
+		{"unknown fit", 10, 10, ResizeOptions{Width: 5, Fit: "fill"}},
+	}
+	for _, tt := range tests {
+		if _, _, _, err := resizeGeometry(tt.sw, tt.sh, tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	// a one-pixel black and white checkerboard averages to grey when shrunk
+	checker := encodePNG(t, 64, 32, func(x, y int) color.Color {
+		if (x+y)%2 == 0 {
+			return color.White
+		}
+		return color.Black
+	})
+
+	dp := NewDataProcessor()
+	result, err := dp.ResizeImage(context.Background(), checker, ResizeOptions{Width: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["width"] != 8 || result["height"] != 4 || result["format"] != "png" || result["fit"] != "contain" {
+		t.Errorf("result = %v", result)
+	}
+	data, err := base64.StdEncoding.DecodeString(result["image"].(string))
+	if err != nil || result["size"] != len(data) {
+		t.Fatalf("image = %d bytes, size %v, err %v", len(data), result["size"], err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := img.At(3, 2).RGBA(); r>>8 < 120 || r>>8 > 135 {
+		t.Errorf("pixel = %d, want mid grey", r>>8)
+	}
+
+	// cover output is exactly the box and can change format
+	out, bounds, format, err := ResizeImage(context.Background(), checker, ResizeOptions{Width: 10, Height: 10, Fit: "cover", Format: "jpg", Quality: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds.Dx() != 10 || bounds.Dy() != 10 || format != "jpeg" {
+		t.Errorf("ResizeImage = %v %s", bounds, format)
+	}
+	if _, decoded, err := image.Decode(bytes.NewReader(out)); err != nil || decoded != "jpeg" {
+		t.Errorf("output decodes as %q, %v", decoded, err)
+	}
+
+	if _, _, _, err := ResizeImage(context.Background(), checker, ResizeOptions{Width: 8, Format: "gif"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := ResizeImage(ctx, checker, ResizeOptions{Width: 8}); err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestScaleImageEnlarge(t *testing.T) {
+	// enlarging a solid image keeps its colour, including translucent pixels
+	src := image.NewNRGBA(image.Rect(5, 5, 7, 7))
+	for y := 5; y < 7; y++ {
+		for x := 5; x < 7; x++ {
+			src.Set(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 128})
+		}
+	}
+	out, err := scaleImage(context.Background(), src, 9, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBAModel.Convert(src.At(5, 5))
+	for _, p := range []image.Point{{0, 0}, {4, 4}, {8, 8}} {
+		if got := out.At(p.X, p.Y); got != want {
+			t.Errorf("pixel %v = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed HTTP request is retried
+type RetryPolicy struct {
+	// Retries is the number of attempts after the first
+	Retries int
+	// BaseDelay doubles after each attempt up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Unsafe also retries non-idempotent methods such as POST, which may
+	// apply a request twice if the first response was lost
+	Unsafe bool
+}
+
+// DefaultRetryPolicy retries idempotent requests three times starting at
+// 250ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Retries:   3,
+		BaseDelay: 250 * time.Millisecond,
+		MaxDelay:  5 * time.Second,
+	}
+}
+
+// idempotentMethods are safe to repeat per RFC 9110
+var idempotentMethods = map[string]bool{
+	"GET": true, "HEAD": true, "OPTIONS": true, "TRACE": true, "PUT": true, "DELETE": true,
+}
+
+// RetryableStatus reports whether a response status is worth retrying:
+// request timeouts, rate limiting and server errors other than those that
+// will not change on a repeat (501 and 505)
+func RetryableStatus(status int) bool {
+	switch status {
+	case 408, 429:
+		return true
+	case 501, 505:
+		return false
+	}
+	return status >= 500 && status <= 599
+}
+
+// ShouldRetry reports whether attempt (counting from 1) of method should
+// be followed by another. status is 0 when the request failed without a
+// response.
+func (p RetryPolicy) ShouldRetry(method string, attempt, status int) bool {
+	if attempt > p.Retries {
+		return false
+	}
+	if !p.Unsafe && !idempotentMethods[strings.ToUpper(method)] {
+		return false
+	}
+	return status == 0 || RetryableStatus(status)
+}
+
+// Delay returns how long to wait after attempt (counting from 1). The
+// exponential delay is jittered between half and all of its value so
+// clients that failed together do not retry together. A Retry-After header
+// value takes precedence when present, capped at MaxDelay.
+func (p RetryPolicy) Delay(attempt int, retryAfter string, now time.Time) time.Duration {
+	if d, ok := ParseRetryAfter(retryAfter, now); ok {
+		return min(d, p.MaxDelay)
+	}
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		// clamp rather than overflow into a negative duration
+		return time.Duration(min(int64(secs), math.MaxInt64/int64(time.Second))) * time.Second, true
+	}
+	t, err := time.Parse(cookieTimeFormat, value)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
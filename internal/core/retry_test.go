@@ -0,0 +1,110 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryableStatus(t *testing.T) {
+	for status, want := range map[int]bool{
+		200: false, 400: false, 404: false, 408: true, 429: true,
+		500: true, 501: false, 502: true, 503: true, 504: true, 505: false, 599: true,
+	} {
+		if got := RetryableStatus(status); got != want {
+			t.Errorf("RetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	p := RetryPolicy{Retries: 2}
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		method  string
+		attempt int
+		status  int
+		want    bool
+	}{
+		{"network error", p, "GET", 1, 0, true},
+		{"server error", p, "get", 2, 503, true},
+		{"out of retries", p, "GET", 3, 503, false},
+		{"client error", p, "GET", 1, 404, false},
+		{"unsafe method", p, "POST", 1, 503, false},
+		{"idempotent PUT", p, "PUT", 1, 0, true},
+		{"unsafe allowed", RetryPolicy{Retries: 2, Unsafe: true}, "POST", 1, 503, true},
+		{"no retries", RetryPolicy{}, "GET", 1, 0, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.ShouldRetry(tt.method, tt.attempt, tt.status); got != tt.want {
+			t.Errorf("%s: ShouldRetry = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{4, 400 * time.Millisecond, 800 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := p.Delay(tt.attempt, "", now); d < tt.min || d > tt.max {
+				t.Fatalf("Delay(%d) = %v, want between %v and %v", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+
+	// Retry-After wins, capped at MaxDelay
+	for header, want := range map[string]time.Duration{
+		"0":                             0,
+		"1":                             time.Second,
+		"120":                           time.Second,
+		"10000000000":                   time.Second,
+		"Mon, 01 Jan 2024 12:00:00 GMT": 0,
+	} {
+		if d := p.Delay(1, header, now); d != want {
+			t.Errorf("Delay with Retry-After %q = %v, want %v", header, d, want)
+		}
+	}
+
+	if d := (RetryPolicy{}).Delay(3, "", now); d != 0 {
+		t.Errorf("Delay with no base = %v, want 0", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"30", 30 * time.Second, true},
+		{" 5 ", 5 * time.Second, true},
+		{"Mon, 01 Jan 2024 12:01:30 GMT", 90 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"2024-01-01T12:00:00Z", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRetryAfter(%q) = %v %v, want %v %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if d, ok := ParseRetryAfter("10000000000", now); !ok || d <= 0 {
+		t.Errorf("ParseRetryAfter of a huge value = %v %v, want a large positive duration", d, ok)
+	}
+}
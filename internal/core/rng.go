@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// RNG is a PCG32 (XSH-RR) generator. Its output depends only on the seed,
+// so a sequence replays identically across runs, platforms and Go
+// versions, unlike math/rand whose algorithms may change.
+type RNG struct {
+	state uint64
+	inc   uint64
+}
+
+const pcgMultiplier = 6364136223846793005
+
+// NewRNG creates a generator from a seed, using the standard PCG32 seeding
+// with a fixed stream
+func NewRNG(seed uint64) *RNG {
+	r := &RNG{inc: 1442695040888963407<<1 | 1}
+	r.Uint32()
+	r.state += seed
+	r.Uint32()
+	return r
+}
+
+// StringSeed derives a seed from a string such as a level name, using
+// 64-bit FNV-1a
+func StringSeed(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// Uint32 returns the next 32 random bits
+func (r *RNG) Uint32() uint32 {
+	old := r.state
+	r.state = old*pcgMultiplier + r.inc
+	xorshifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := int(old >> 59)
+	return bits.RotateLeft32(xorshifted, -rot)
+}
+
+// Uint64 returns the next 64 random bits
+func (r *RNG) Uint64() uint64 {
+	return uint64(r.Uint32())<<32 | uint64(r.Uint32())
+}
+
+// Float64 returns a number in [0, 1) with 53 random bits
+func (r *RNG) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Uint64n returns an unbiased number in [0, n) using Lemire's method
+func (r *RNG) Uint64n(n uint64) uint64 {
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// IntRange returns an integer in [min, max], both inclusive
+func (r *RNG) IntRange(min, max int64) (int64, error) {
+	if min > max {
+		return 0, fmt.Errorf("min %d is greater than max %d", min, max)
+	}
+	span := uint64(max - min)
+	if span == math.MaxUint64 {
+		return int64(r.Uint64()), nil
+	}
+	return min + int64(r.Uint64n(span+1)), nil
+}
+
+// Shuffle permutes n items with Fisher-Yates, calling swap to exchange them
+func (r *RNG) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, int(r.Uint64n(uint64(i+1))))
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRNGMatchesReference(t *testing.T) {
+	// First outputs of the PCG32 reference implementation's demo, seeded
+	// with pcg32_srandom(42, 54)
+	r := &RNG{inc: 54<<1 | 1}
+	r.Uint32()
+	r.state += 42
+	r.Uint32()
+	for i, want := range []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330} {
+		if got := r.Uint32(); got != want {
+			t.Errorf("output %d = %#x, want %#x", i, got, want)
+		}
+	}
+
+	// NewRNG uses the default stream; these must never change
+	r = NewRNG(42)
+	for i, want := range []uint32{492690617, 1919685028, 3561993920, 683038915} {
+		if got := r.Uint32(); got != want {
+			t.Errorf("NewRNG(42) output %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestRNGReproducible(t *testing.T) {
+	draw := func(seed uint64) []int64 {
+		r := NewRNG(seed)
+		out := make([]int64, 50)
+		for i := range out {
+			out[i], _ = r.IntRange(-1000, 1000)
+		}
+		return out
+	}
+	if !reflect.DeepEqual(draw(7), draw(7)) {
+		t.Error("the same seed produced different sequences")
+	}
+	if reflect.DeepEqual(draw(7), draw(8)) {
+		t.Error("different seeds produced the same sequence")
+	}
+	if StringSeed("level-1") != StringSeed("level-1") || StringSeed("level-1") == StringSeed("level-2") {
+		t.Error("string seeds are not stable and distinct")
+	}
+}
+
+func TestRNGDistribution(t *testing.T) {
+	r := NewRNG(StringSeed("dice"))
+	const rolls = 60000
+	counts := make(map[int64]int)
+	for i := 0; i < rolls; i++ {
+		n, err := r.IntRange(1, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[n]++
+	}
+	for face := int64(1); face <= 6; face++ {
+		if c := counts[face]; math.Abs(float64(c)-rolls/6) > rolls/6*0.05 {
+			t.Errorf("face %d came up %d times in %d rolls", face, c, rolls)
+		}
+	}
+	if len(counts) != 6 {
+		t.Errorf("rolled values outside 1-6: %v", counts)
+	}
+
+	sum := 0.0
+	for i := 0; i < rolls; i++ {
+		f := r.Float64()
+		if f < 0 || f >= 1 {
+			t.Fatalf("Float64 = %v, outside [0, 1)", f)
+		}
+		sum += f
+	}
+	if mean := sum / rolls; math.Abs(mean-0.5) > 0.01 {
+		t.Errorf("Float64 mean = %v, want about 0.5", mean)
+	}
+}
+
+func TestRNGIntRange(t *testing.T) {
+	r := NewRNG(1)
+	if n, err := r.IntRange(5, 5); err != nil || n != 5 {
+		t.Errorf("IntRange(5, 5) = %d, %v", n, err)
+	}
+	if _, err := r.IntRange(2, 1); err == nil {
+		t.Error("expected an error when min is greater than max")
+	}
+	// The full int64 range doesn't overflow the span
+	if _, err := r.IntRange(math.MinInt64, math.MaxInt64); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if n := r.Uint64n(3); n >= 3 {
+			t.Fatalf("Uint64n(3) = %d", n)
+		}
+	}
+}
+
+func TestRNGShuffle(t *testing.T) {
+	shuffle := func(seed uint64) []int {
+		items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		NewRNG(seed).Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+		return items
+	}
+
+	got := shuffle(3)
+	if !reflect.DeepEqual(got, shuffle(3)) {
+		t.Error("the same seed shuffled differently")
+	}
+	sorted := append([]int(nil), got...)
+	sort.Ints(sorted)
+	if !reflect.DeepEqual(sorted, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("shuffle lost or duplicated items: %v", got)
+	}
+
+	// Every item lands in the first position about equally often
+	first := make(map[int]int)
+	for seed := uint64(0); seed < 10000; seed++ {
+		first[shuffle(seed)[0]]++
+	}
+	for item, c := range first {
+		if c < 800 || c > 1200 {
+			t.Errorf("item %d came first %d times in 10000 shuffles", item, c)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is a business rule checked against a data object: the value of
+// Field compared by Op (any JSONPredicate op, or missing) with Value, or
+// with the value of another field when ValueField is set
+type Rule struct {
+	ID         string
+	Field      string
+	Op         string
+	Value      interface{}
+	ValueField string
+	Message    string
+
+	predicate *JSONPredicate
+	negate    bool
+	refTokens []string
+}
+
+// fieldPointer converts a field reference to a JSON pointer. Fields are
+// either pointers such as /address/city or dotted paths such as
+// address.city; an empty field is the whole object.
+func fieldPointer(field string) string {
+	if field == "" || strings.HasPrefix(field, "/") {
+		return field
+	}
+	parts := strings.Split(field, ".")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~", "~0"), "/", "~1")
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// ParseRules reads rule definitions: objects with field, op, and value or
+// valueField, plus an optional id and message
+func ParseRules(raw []interface{}) ([]*Rule, error) {
+	rules := make([]*Rule, len(raw))
+	for i, item := range raw {
+		def, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rule %d must be an object", i)
+		}
+		r := &Rule{Value: def["value"]}
+		r.ID, _ = def["id"].(string)
+		r.Field, _ = def["field"].(string)
+		r.Op, _ = def["op"].(string)
+		r.ValueField, _ = def["valueField"].(string)
+		r.Message, _ = def["message"].(string)
+		if r.Op == "" {
+			return nil, fmt.Errorf("rule %d: op required", i)
+		}
+
+		op := r.Op
+		if op == "missing" {
+			op, r.negate = "exists", true
+		}
+		// the comparison value is filled in per evaluation for cross-field
+		// rules; a placeholder of the right shape lets compile check the op
+		placeholder := r.Value
+		if r.ValueField != "" {
+			tokens, err := parsePointer(fieldPointer(r.ValueField))
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: valueField: %w", i, err)
+			}
+			r.refTokens = tokens
+			switch op {
+			case "matches":
+				placeholder = ""
+			case "in":
+				placeholder = []interface{}{}
+			}
+		}
+		r.predicate = &JSONPredicate{Path: fieldPointer(r.Field), Op: op, Value: placeholder}
+		if err := r.predicate.compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules[i] = r
+	}
+	return rules, nil
+}
+
+// Evaluate checks the rule against data. A cross-field rule whose
+// reference field is missing fails with ok=false.
+func (r *Rule) Evaluate(data interface{}) (passed, ok bool) {
+	p := r.predicate
+	if r.refTokens != nil {
+		ref, err := pointerGet(data, r.refTokens)
+		if err != nil {
+			return false, false
+		}
+		p = &JSONPredicate{Path: p.Path, Op: p.Op, Value: ref}
+		if err := p.compile(); err != nil {
+			// the referenced value has the wrong shape for the op
+			return false, true
+		}
+	}
+	return p.Match(data) != r.negate, true
+}
+
+// ruleOpPhrases word the comparison ops for default messages
+var ruleOpPhrases = map[string]string{
+	"eq": "must equal", "ne": "must not equal", "gt": "must be greater than",
+	"gte": "must be at least", "lt": "must be less than", "lte": "must be at most",
+	"contains": "must contain", "matches": "must match", "in": "must be one of",
+}
+
+// defaultMessage describes a rule that has no message of its own
+func (r *Rule) defaultMessage() string {
+	field := r.Field
+	if field == "" {
+		field = "value"
+	}
+	switch r.Op {
+	case "exists":
+		return field + " is required"
+	case "missing":
+		return field + " must not be set"
+	}
+	target := fmt.Sprintf("%v", r.Value)
+	if r.ValueField != "" {
+		target = r.ValueField
+	}
+	return fmt.Sprintf("%s %s %s", field, ruleOpPhrases[r.Op], target)
+}
+
+// EvaluateRules checks data against each rule and reports which passed,
+// with the messages of those that failed. Rules are checked in order and
+// all of them are evaluated.
+func (dp *DataProcessor) EvaluateRules(data interface{}, rawRules []interface{}) (map[string]interface{}, error) {
+	rules, err := ParseRules(rawRules)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, len(rules))
+	failures := []interface{}{}
+	passedCount := 0
+	for i, r := range rules {
+		passed, ok := r.Evaluate(data)
+		message := r.Message
+		if message == "" {
+			message = r.defaultMessage()
+		}
+		result := map[string]interface{}{
+			"index":  i,
+			"field":  r.Field,
+			"op":     r.Op,
+			"passed": passed,
+		}
+		if r.ID != "" {
+			result["id"] = r.ID
+		}
+		if !ok {
+			result["reason"] = fmt.Sprintf("comparison field %s is missing", r.ValueField)
+		}
+		if passed {
+			passedCount++
+		} else {
+			result["message"] = message
+			failures = append(failures, message)
+		}
+		results[i] = result
+	}
+
+	return map[string]interface{}{
+		"valid":    passedCount == len(rules),
+		"passed":   passedCount,
+		"failed":   len(rules) - passedCount,
+		"results":  results,
+		"messages": failures,
+	}, nil
+}
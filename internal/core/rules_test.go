@@ -0,0 +1,156 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldPointer(t *testing.T) {
+	tests := []struct {
+		field, want string
+	}{
+		{"", ""},
+		{"/address/city", "/address/city"},
+		{"address.city", "/address/city"},
+		{"name", "/name"},
+		{"a~b.c/d", "/a~0b/c~1d"},
+		{"items.0", "/items/0"},
+	}
+	for _, tt := range tests {
+		if got := fieldPointer(tt.field); got != tt.want {
+			t.Errorf("fieldPointer(%q) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule interface{}
+	}{
+		{"not an object", "age >= 18"},
+		{"no op", map[string]interface{}{"field": "age"}},
+		{"unknown op", map[string]interface{}{"field": "age", "op": "between"}},
+		{"bad pattern", map[string]interface{}{"field": "name", "op": "matches", "value": "("}},
+		{"in without array", map[string]interface{}{"field": "name", "op": "in", "value": "a"}},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRules([]interface{}{tt.rule}); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	data := map[string]interface{}{
+		"age":      21.0,
+		"name":     "Ada",
+		"email":    "ada@example.com",
+		"role":     "admin",
+		"start":    "2024-01-01",
+		"end":      "2023-12-31",
+		"password": "secret",
+		"confirm":  "secret",
+		"pattern":  "^[A-Z]",
+		"roles":    []interface{}{"admin", "editor"},
+		"address":  map[string]interface{}{"city": "London"},
+	}
+	tests := []struct {
+		name   string
+		rule   map[string]interface{}
+		passed bool
+		ok     bool
+	}{
+		{"gte", map[string]interface{}{"field": "age", "op": "gte", "value": 18.0}, true, true},
+		{"lt fails", map[string]interface{}{"field": "age", "op": "lt", "value": 18.0}, false, true},
+		{"dotted path", map[string]interface{}{"field": "address.city", "op": "eq", "value": "London"}, true, true},
+		{"pointer path", map[string]interface{}{"field": "/address/city", "op": "ne", "value": "Paris"}, true, true},
+		{"matches", map[string]interface{}{"field": "email", "op": "matches", "value": `^[^@]+@[^@]+$`}, true, true},
+		{"in", map[string]interface{}{"field": "role", "op": "in", "value": []interface{}{"admin", "owner"}}, true, true},
+		{"exists", map[string]interface{}{"field": "name", "op": "exists"}, true, true},
+		{"exists fails", map[string]interface{}{"field": "phone", "op": "exists"}, false, true},
+		{"missing", map[string]interface{}{"field": "phone", "op": "missing"}, true, true},
+		{"missing fails", map[string]interface{}{"field": "name", "op": "missing"}, false, true},
+		{"cross-field eq", map[string]interface{}{"field": "confirm", "op": "eq", "valueField": "password"}, true, true},
+		{"cross-field order", map[string]interface{}{"field": "end", "op": "gte", "valueField": "start"}, false, true},
+		{"cross-field matches", map[string]interface{}{"field": "name", "op": "matches", "valueField": "pattern"}, true, true},
+		{"cross-field in", map[string]interface{}{"field": "role", "op": "in", "valueField": "roles"}, true, true},
+		{"reference missing", map[string]interface{}{"field": "age", "op": "eq", "valueField": "minAge"}, false, false},
+		{"reference wrong shape", map[string]interface{}{"field": "role", "op": "in", "valueField": "name"}, false, true},
+	}
+	for _, tt := range tests {
+		rules, err := ParseRules([]interface{}{tt.rule})
+		if err != nil {
+			t.Errorf("%s: ParseRules: %v", tt.name, err)
+			continue
+		}
+		passed, ok := rules[0].Evaluate(data)
+		if passed != tt.passed || ok != tt.ok {
+			t.Errorf("%s: Evaluate = %v, %v, want %v, %v", tt.name, passed, ok, tt.passed, tt.ok)
+		}
+	}
+}
+
+func TestRuleDefaultMessage(t *testing.T) {
+	tests := []struct {
+		rule map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"field": "age", "op": "gte", "value": 18.0}, "age must be at least 18"},
+		{map[string]interface{}{"field": "email", "op": "exists"}, "email is required"},
+		{map[string]interface{}{"field": "legacyId", "op": "missing"}, "legacyId must not be set"},
+		{map[string]interface{}{"field": "end", "op": "gt", "valueField": "start"}, "end must be greater than start"},
+		{map[string]interface{}{"op": "ne", "value": "x"}, "value must not equal x"},
+	}
+	for _, tt := range tests {
+		rules, err := ParseRules([]interface{}{tt.rule})
+		if err != nil {
+			t.Fatalf("ParseRules: %v", err)
+		}
+		if got := rules[0].defaultMessage(); got != tt.want {
+			t.Errorf("defaultMessage = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	dp := NewDataProcessor()
+	data := map[string]interface{}{"age": 16.0, "name": "Ada"}
+	rules := []interface{}{
+		map[string]interface{}{"id": "adult", "field": "age", "op": "gte", "value": 18.0, "message": "Must be an adult"},
+		map[string]interface{}{"field": "name", "op": "exists"},
+		map[string]interface{}{"field": "age", "op": "lte", "valueField": "maxAge"},
+	}
+	result, err := dp.EvaluateRules(data, rules)
+	if err != nil {
+		t.Fatalf("EvaluateRules: %v", err)
+	}
+	if result["valid"] != false || result["passed"] != 1 || result["failed"] != 2 {
+		t.Errorf("result = %v", result)
+	}
+	wantMessages := []interface{}{"Must be an adult", "age must be at most maxAge"}
+	if !reflect.DeepEqual(result["messages"], wantMessages) {
+		t.Errorf("messages = %v, want %v", result["messages"], wantMessages)
+	}
+
+	results := result["results"].([]interface{})
+	first := results[0].(map[string]interface{})
+	if first["id"] != "adult" || first["passed"] != false || first["message"] != "Must be an adult" {
+		t.Errorf("results[0] = %v", first)
+	}
+	if second := results[1].(map[string]interface{}); second["passed"] != true || second["message"] != nil {
+		t.Errorf("results[1] = %v", second)
+	}
+	if third := results[2].(map[string]interface{}); third["reason"] != "comparison field maxAge is missing" {
+		t.Errorf("results[2] = %v", third)
+	}
+
+	// no rules is trivially valid
+	result, err = dp.EvaluateRules(data, nil)
+	if err != nil || result["valid"] != true {
+		t.Errorf("no rules = %v, %v", result, err)
+	}
+	if _, err := dp.EvaluateRules(data, []interface{}{42.0}); err == nil {
+		t.Error("expected an error for an invalid rule")
+	}
+}
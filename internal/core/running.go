@@ -0,0 +1,55 @@
+package core
+
+import "math"
+
+// RunningStats accumulates count, mean, variance, min and max one value at
+// a time using Welford's online algorithm, without storing the values
+type RunningStats struct {
+	count int
+	mean  float64
+	m2    float64
+	min   float64
+	max   float64
+}
+
+// Add incorporates a value into the statistics
+func (rs *RunningStats) Add(value float64) {
+	rs.count++
+	if rs.count == 1 {
+		rs.min, rs.max = value, value
+	} else {
+		rs.min = math.Min(rs.min, value)
+		rs.max = math.Max(rs.max, value)
+	}
+
+	delta := value - rs.mean
+	rs.mean += delta / float64(rs.count)
+	rs.m2 += delta * (value - rs.mean)
+}
+
+// Count returns the number of values added
+func (rs *RunningStats) Count() int {
+	return rs.count
+}
+
+// Result returns the current statistics. Variance is the population
+// variance, matching CalculateStatistics.
+func (rs *RunningStats) Result() map[string]interface{} {
+	if rs.count == 0 {
+		return map[string]interface{}{
+			"count": 0,
+		}
+	}
+
+	variance := rs.m2 / float64(rs.count)
+
+	return map[string]interface{}{
+		"count":       rs.count,
+		"mean":        math.Round(rs.mean*100) / 100,
+		"variance":    math.Round(variance*100) / 100,
+		"standardDev": math.Round(math.Sqrt(variance)*100) / 100,
+		"min":         rs.min,
+		"max":         rs.max,
+		"sum":         math.Round(rs.mean*float64(rs.count)*100) / 100,
+	}
+}
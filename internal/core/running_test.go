@@ -0,0 +1,72 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRunningStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+	}{
+		{name: "single", values: []float64{4}},
+		{name: "small", values: []float64{2, 4, 4, 4, 5, 5, 7, 9}},
+		{name: "negative", values: []float64{-3.5, 0, 12.25, -7}},
+		{name: "constant", values: []float64{1.1, 1.1, 1.1}},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rs RunningStats
+			for _, v := range tt.values {
+				rs.Add(v)
+			}
+			got := rs.Result()
+			want := dp.CalculateStatistics(tt.values)
+			for _, key := range []string{"count", "mean", "variance", "standardDev", "min", "max", "sum"} {
+				if got[key] != want[key] {
+					t.Errorf("%s = %v, CalculateStatistics gives %v", key, got[key], want[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRunningStatsEmpty(t *testing.T) {
+	var rs RunningStats
+	result := rs.Result()
+	if len(result) != 1 || result["count"] != 0 || rs.Count() != 0 {
+		t.Errorf("got %v for no values", result)
+	}
+}
+
+func TestRunningStatsLargeOffset(t *testing.T) {
+	// A naive sum of squares loses the variance entirely at this offset
+	var rs RunningStats
+	for _, v := range []float64{4, 7, 13, 16} {
+		rs.Add(1e9 + v)
+	}
+	result := rs.Result()
+	if result["variance"] != 22.5 || result["mean"] != 1e9+10 {
+		t.Errorf("got variance %v mean %v, want 22.5 and %v", result["variance"], result["mean"], 1e9+10)
+	}
+}
+
+func TestRunningStatsIncremental(t *testing.T) {
+	var rs RunningStats
+	sum := 0.0
+	for i := 1; i <= 1000; i++ {
+		rs.Add(float64(i))
+		sum += float64(i)
+		if rs.Count() != i {
+			t.Fatalf("count = %d after %d values", rs.Count(), i)
+		}
+	}
+	result := rs.Result()
+	// Population variance of 1..n is (n^2-1)/12
+	if result["sum"] != sum || result["variance"] != math.Round((1000*1000-1)/12.0*100)/100 {
+		t.Errorf("got %v", result)
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSchemaSampleSize is the number of rows examined by InferSchema
+const DefaultSchemaSampleSize = 1000
+
+// dateLayouts are the string formats recognized as dates
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func isDateString(s string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType classifies a decoded JSON value
+func valueType(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, int, int64:
+		return "number"
+	case string:
+		if isDateString(val) {
+			return "date"
+		}
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// stringFormat detects a well-known format for a string value
+func stringFormat(s string) string {
+	if isDateString(s) {
+		return "date"
+	}
+	for _, format := range []string{"email", "url"} {
+		if ok, _ := ValidateByType(s, format); ok {
+			return format
+		}
+	}
+	return ""
+}
+
+type columnStats struct {
+	types   map[string]int
+	formats map[string]int
+	present int
+	strings int
+}
+
+// InferSchema examines an array of objects and describes each field's type,
+// nullability and string format. Columns with mixed types report a union
+// such as "number|string". Only the first sampleSize rows are examined.
+func (dp *DataProcessor) InferSchema(rows []interface{}, sampleSize int) (map[string]interface{}, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows provided")
+	}
+	if sampleSize <= 0 {
+		sampleSize = DefaultSchemaSampleSize
+	}
+
+	sampled := rows
+	if len(sampled) > sampleSize {
+		sampled = sampled[:sampleSize]
+	}
+
+	columns := make(map[string]*columnStats)
+	var order []string
+
+	for i, row := range sampled {
+		obj, ok := row.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("row %d is not an object", i)
+		}
+
+		// Visit keys in sorted order so column order is deterministic
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			col, exists := columns[key]
+			if !exists {
+				col = &columnStats{types: map[string]int{}, formats: map[string]int{}}
+				columns[key] = col
+				order = append(order, key)
+			}
+
+			value := obj[key]
+			col.present++
+			col.types[valueType(value)]++
+
+			if s, ok := value.(string); ok {
+				col.strings++
+				if format := stringFormat(s); format != "" {
+					col.formats[format]++
+				}
+			}
+		}
+	}
+
+	schema := make([]interface{}, 0, len(order))
+	for _, name := range order {
+		col := columns[name]
+
+		var types []string
+		for t := range col.types {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		sort.Strings(types)
+
+		columnType := strings.Join(types, "|")
+		if columnType == "" {
+			columnType = "null"
+		}
+
+		column := map[string]interface{}{
+			"name":     name,
+			"type":     columnType,
+			"nullable": col.types["null"] > 0 || col.present < len(sampled),
+			"present":  col.present,
+			"missing":  len(sampled) - col.present,
+		}
+
+		// Report a format only when every string in the column has it
+		for format, count := range col.formats {
+			if count == col.strings {
+				column["format"] = format
+			}
+		}
+
+		schema = append(schema, column)
+	}
+
+	result := map[string]interface{}{
+		"columns":     schema,
+		"rowCount":    len(rows),
+		"sampledRows": len(sampled),
+		"sampled":     len(sampled) < len(rows),
+	}
+	if len(sampled) < len(rows) {
+		result["note"] = fmt.Sprintf("Schema inferred from the first %d of %d rows", len(sampled), len(rows))
+	}
+
+	return result, nil
+}
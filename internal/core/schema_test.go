@@ -0,0 +1,101 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"id": 1.0, "email": "a@example.com", "seen": "2024-01-02", "tags": []interface{}{"x"}, "mixed": "one"},
+		map[string]interface{}{"id": 2.0, "email": "b@example.com", "seen": "2024-01-03T10:00:00Z", "tags": nil, "mixed": 2.0, "extra": true},
+		map[string]interface{}{"id": 3.0, "email": nil, "seen": "2024-01-04 10:00:00", "tags": []interface{}{}, "mixed": "https://example.com"},
+	}
+
+	dp := NewDataProcessor()
+	result, err := dp.InferSchema(rows, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []interface{}{
+		map[string]interface{}{"name": "email", "type": "string", "nullable": true, "present": 3, "missing": 0, "format": "email"},
+		map[string]interface{}{"name": "id", "type": "number", "nullable": false, "present": 3, "missing": 0},
+		map[string]interface{}{"name": "mixed", "type": "number|string", "nullable": false, "present": 3, "missing": 0},
+		map[string]interface{}{"name": "seen", "type": "date", "nullable": false, "present": 3, "missing": 0, "format": "date"},
+		map[string]interface{}{"name": "tags", "type": "array", "nullable": true, "present": 3, "missing": 0},
+		map[string]interface{}{"name": "extra", "type": "boolean", "nullable": true, "present": 1, "missing": 2},
+	}
+	columns := result["columns"].([]interface{})
+	if len(columns) != len(want) {
+		t.Fatalf("got %d columns, want %d: %v", len(columns), len(want), columns)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(columns[i], want[i]) {
+			t.Errorf("column %d = %v, want %v", i, columns[i], want[i])
+		}
+	}
+	if result["sampled"] != false || result["rowCount"] != 3 {
+		t.Errorf("got rowCount %v sampled %v", result["rowCount"], result["sampled"])
+	}
+}
+
+func TestInferSchemaColumnTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   string
+	}{
+		{name: "all null", values: []interface{}{nil, nil}, want: "null"},
+		{name: "object", values: []interface{}{map[string]interface{}{}}, want: "object"},
+		{name: "date and string", values: []interface{}{"2024-01-02", "soon"}, want: "date|string"},
+		{name: "boolean and null", values: []interface{}{true, nil}, want: "boolean"},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		rows := make([]interface{}, len(tt.values))
+		for i, v := range tt.values {
+			rows[i] = map[string]interface{}{"v": v}
+		}
+		result, err := dp.InferSchema(rows, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		column := result["columns"].([]interface{})[0].(map[string]interface{})
+		if column["type"] != tt.want {
+			t.Errorf("%s: type = %v, want %s", tt.name, column["type"], tt.want)
+		}
+	}
+}
+
+func TestInferSchemaSampling(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"a": 2.0},
+		map[string]interface{}{"a": "late", "b": 1.0},
+	}
+
+	dp := NewDataProcessor()
+	result, err := dp.InferSchema(rows, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := result["columns"].([]interface{})
+	if len(columns) != 1 || columns[0].(map[string]interface{})["type"] != "number" {
+		t.Errorf("columns = %v, want only a number column from the sample", columns)
+	}
+	if result["sampled"] != true || result["sampledRows"] != 2 || result["note"] == nil {
+		t.Errorf("got %v", result)
+	}
+}
+
+func TestInferSchemaErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	if _, err := dp.InferSchema(nil, 0); err == nil {
+		t.Error("expected an error for no rows")
+	}
+	if _, err := dp.InferSchema([]interface{}{map[string]interface{}{}, "x"}, 0); err == nil {
+		t.Error("expected an error for a row that is not an object")
+	}
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a parsed SemVer 2.0.0 version
+type SemVer struct {
+	Major, Minor, Patch uint64
+	Prerelease          []string
+	Build               []string
+}
+
+// ParseSemVer parses a SemVer 2.0.0 string. Surrounding whitespace and a
+// leading "v" are accepted since tags are commonly written that way;
+// everything else follows the spec strictly.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	input := strings.TrimSpace(s)
+	input = strings.TrimPrefix(strings.TrimPrefix(input, "v"), "V")
+	if input == "" {
+		return v, fmt.Errorf("empty version")
+	}
+
+	core, build, hasBuild := strings.Cut(input, "+")
+	core, pre, hasPre := strings.Cut(core, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	nums := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := parseSemVerNumber(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q: %s", s, err)
+		}
+		*nums[i] = n
+	}
+
+	if hasPre {
+		ids, err := semVerIdentifiers(pre, true)
+		if err != nil {
+			return v, fmt.Errorf("invalid prerelease in %q: %s", s, err)
+		}
+		v.Prerelease = ids
+	}
+	if hasBuild {
+		ids, err := semVerIdentifiers(build, false)
+		if err != nil {
+			return v, fmt.Errorf("invalid build metadata in %q: %s", s, err)
+		}
+		v.Build = ids
+	}
+	return v, nil
+}
+
+// parseSemVerNumber parses a numeric identifier, which has no leading zeros
+func parseSemVerNumber(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty numeric component")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return n, nil
+}
+
+// semVerIdentifiers splits dot-separated identifiers made of [0-9A-Za-z-].
+// Numeric prerelease identifiers must not have leading zeros.
+func semVerIdentifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("empty identifier")
+		}
+		numeric := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return nil, fmt.Errorf("identifier %q has invalid character %q", id, c)
+			}
+		}
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", id)
+		}
+	}
+	return ids, nil
+}
+
+// String returns the canonical form of v, without a "v" prefix
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v sorts before, equal to or after other
+// under SemVer precedence. Build metadata is ignored; a prerelease sorts
+// before its release, and prerelease identifiers compare numerically when
+// both are numbers, numbers before text, and a shorter list first when
+// one is a prefix of the other.
+func (v SemVer) Compare(other SemVer) int {
+	for _, pair := range [3][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareOrdered(pair[0], pair[1])
+		}
+	}
+
+	a, b := v.Prerelease, other.Prerelease
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		na, errA := strconv.ParseUint(a[i], 10, 64)
+		nb, errB := strconv.ParseUint(b[i], 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return compareOrdered(na, nb)
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareOrdered(len(a), len(b))
+}
+
+func compareOrdered[T int | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// semVerMap converts v to a result object
+func semVerMap(v SemVer) map[string]interface{} {
+	list := func(ids []string) []interface{} {
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = id
+		}
+		return out
+	}
+	return map[string]interface{}{
+		"version":    v.String(),
+		"major":      float64(v.Major),
+		"minor":      float64(v.Minor),
+		"patch":      float64(v.Patch),
+		"prerelease": list(v.Prerelease),
+		"build":      list(v.Build),
+		"stable":     len(v.Prerelease) == 0 && v.Major > 0,
+	}
+}
+
+// ParseSemVer validates and normalizes a version string
+func (dp *DataProcessor) ParseSemVer(input string) (map[string]interface{}, error) {
+	v, err := ParseSemVer(input)
+	if err != nil {
+		return nil, err
+	}
+	return semVerMap(v), nil
+}
+
+// CompareSemVer orders two versions by SemVer precedence
+func (dp *DataProcessor) CompareSemVer(a, b string) (map[string]interface{}, error) {
+	va, err := ParseSemVer(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := ParseSemVer(b)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := va.Compare(vb)
+	return map[string]interface{}{
+		"comparison": cmp,
+		"a":          va.String(),
+		"b":          vb.String(),
+		"equal":      cmp == 0,
+		"identical":  va.String() == vb.String(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SemVer
+		str   string
+	}{
+		{"1.2.3", SemVer{Major: 1, Minor: 2, Patch: 3}, "1.2.3"},
+		{" v0.0.0 ", SemVer{}, "0.0.0"},
+		{"V10.20.30", SemVer{Major: 10, Minor: 20, Patch: 30}, "10.20.30"},
+		{"1.0.0-alpha-1.0", SemVer{Major: 1, Prerelease: []string{"alpha-1", "0"}}, "1.0.0-alpha-1.0"},
+		{"1.0.0+build.01-x", SemVer{Major: 1, Build: []string{"build", "01-x"}}, "1.0.0+build.01-x"},
+		{"1.0.0-rc.1+sha.5114f85", SemVer{Major: 1, Prerelease: []string{"rc", "1"}, Build: []string{"sha", "5114f85"}}, "1.0.0-rc.1+sha.5114f85"},
+		{"18446744073709551615.0.0", SemVer{Major: 1<<64 - 1}, "18446744073709551615.0.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSemVer(tt.input)
+		if err != nil {
+			t.Errorf("ParseSemVer(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) || got.String() != tt.str {
+			t.Errorf("ParseSemVer(%q) = %+v (%s), want %+v (%s)", tt.input, got, got, tt.want, tt.str)
+		}
+	}
+}
+
+func TestParseSemVerErrors(t *testing.T) {
+	for _, input := range []string{
+		"", "v", "1", "1.2", "1.2.3.4", "01.2.3", "1.02.3", "1.2.-3", "1.2.x",
+		"1.2.3-", "1.2.3-alpha..1", "1.2.3-01", "1.2.3-alpha_1", "1.2.3+", "1.2.3+a..b",
+		"18446744073709551616.0.0",
+	} {
+		if v, err := ParseSemVer(input); err == nil {
+			t.Errorf("ParseSemVer(%q) = %v, expected an error", input, v)
+		}
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	// the precedence example from the SemVer 2.0.0 spec, plus build metadata
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i, a := range ordered {
+		va, _ := ParseSemVer(a)
+		for j, b := range ordered {
+			vb, _ := ParseSemVer(b)
+			want := compareOrdered(i, j)
+			if got := va.Compare(vb); got != want {
+				t.Errorf("%s vs %s = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	dp := NewDataProcessor()
+	result, err := dp.CompareSemVer("v1.0.0+build.1", "1.0.0+build.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["comparison"] != 0 || result["equal"] != true || result["identical"] != false {
+		t.Errorf("result = %v", result)
+	}
+	if _, err := dp.CompareSemVer("1.0.0", "1.0"); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
+
+func TestParseSemVerResult(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		input  string
+		stable bool
+	}{
+		{"1.0.0", true},
+		{"0.9.0", false},
+		{"2.0.0-rc.1", false},
+	}
+	for _, tt := range tests {
+		result, err := dp.ParseSemVer(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result["stable"] != tt.stable || result["version"] != tt.input {
+			t.Errorf("%s: result = %v", tt.input, result)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// MovingAverage computes the simple moving average of values over window
+// points. Positions before a full window is available are nil (leading
+// nulls) rather than partial averages, so every value in the series is
+// averaged over the same number of points. If alpha is in (0, 1] the
+// exponential moving average is also returned, seeded with the first value.
+func (dp *DataProcessor) MovingAverage(values []float64, window int, alpha float64) (map[string]interface{}, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values provided")
+	}
+	if window < 1 {
+		return nil, fmt.Errorf("window must be at least 1")
+	}
+
+	sma := make([]interface{}, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= window {
+			sum -= values[i-window]
+		}
+		if i >= window-1 {
+			sma[i] = math.Round(sum/float64(window)*10000) / 10000
+		}
+	}
+
+	result := map[string]interface{}{
+		"sma":    sma,
+		"window": window,
+		"count":  len(values),
+	}
+
+	if alpha > 0 && alpha <= 1 {
+		ema := make([]interface{}, len(values))
+		prev := values[0]
+		for i, v := range values {
+			if i > 0 {
+				prev = alpha*v + (1-alpha)*prev
+			}
+			ema[i] = math.Round(prev*10000) / 10000
+		}
+		result["ema"] = ema
+		result["alpha"] = alpha
+	} else if alpha != 0 {
+		return nil, fmt.Errorf("alpha must be between 0 and 1")
+	}
+
+	return result, nil
+}
+
+// Point is a single (x, y) sample in a series
+type Point struct {
+	X float64
+	Y float64
+}
+
+// LTTB downsamples points to threshold points using the
+// Largest-Triangle-Three-Buckets algorithm, which keeps the points that best
+// preserve the visual shape of the series. Points must be sorted by X. A
+// threshold of at least len(points) returns the input unchanged and a
+// threshold below 3 returns only the endpoints.
+func LTTB(points []Point, threshold int) []Point {
+	n := len(points)
+	if threshold >= n || n <= 2 {
+		result := make([]Point, n)
+		copy(result, points)
+		return result
+	}
+	if threshold < 3 {
+		return []Point{points[0], points[n-1]}
+	}
+
+	sampled := make([]Point, 0, threshold)
+	sampled = append(sampled, points[0])
+
+	// Buckets exclude the first and last points, which are always kept
+	bucketSize := float64(n-2) / float64(threshold-2)
+	a := 0
+
+	for i := 0; i < threshold-2; i++ {
+		// Average of the next bucket is the third triangle vertex
+		nextStart := int(math.Floor(float64(i+1)*bucketSize)) + 1
+		nextEnd := int(math.Floor(float64(i+2)*bucketSize)) + 1
+		if nextEnd > n {
+			nextEnd = n
+		}
+		avgX, avgY := 0.0, 0.0
+		for j := nextStart; j < nextEnd; j++ {
+			avgX += points[j].X
+			avgY += points[j].Y
+		}
+		count := float64(nextEnd - nextStart)
+		avgX /= count
+		avgY /= count
+
+		// Pick the point in this bucket forming the largest triangle
+		start := int(math.Floor(float64(i)*bucketSize)) + 1
+		end := int(math.Floor(float64(i+1)*bucketSize)) + 1
+
+		maxArea := -1.0
+		maxIndex := start
+		for j := start; j < end; j++ {
+			area := math.Abs((points[a].X-avgX)*(points[j].Y-points[a].Y)-
+				(points[a].X-points[j].X)*(avgY-points[a].Y)) / 2
+			if area > maxArea {
+				maxArea = area
+				maxIndex = j
+			}
+		}
+
+		sampled = append(sampled, points[maxIndex])
+		a = maxIndex
+	}
+
+	return append(sampled, points[n-1])
+}
+
+// Downsample reduces a series to at most target points for charting. The
+// first and last points are always kept, so target must be at least 2.
+func (dp *DataProcessor) Downsample(points []Point, target int) (map[string]interface{}, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no points provided")
+	}
+	if target < 2 {
+		return nil, fmt.Errorf("target must be at least 2")
+	}
+
+	sampled := LTTB(points, target)
+	out := make([]interface{}, len(sampled))
+	for i, p := range sampled {
+		out[i] = map[string]interface{}{"x": p.X, "y": p.Y}
+	}
+
+	return map[string]interface{}{
+		"points":        out,
+		"originalCount": len(points),
+		"count":         len(sampled),
+	}, nil
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		window int
+		alpha  float64
+		sma    []interface{}
+		ema    []interface{}
+	}{
+		{
+			name:   "leading nulls",
+			values: []float64{1, 2, 3, 4, 5},
+			window: 3,
+			sma:    []interface{}{nil, nil, 2.0, 3.0, 4.0},
+		},
+		{
+			name:   "window of one",
+			values: []float64{4, -2, 7},
+			window: 1,
+			sma:    []interface{}{4.0, -2.0, 7.0},
+		},
+		{
+			name:   "window longer than series",
+			values: []float64{1, 2},
+			window: 5,
+			sma:    []interface{}{nil, nil},
+		},
+		{
+			name:   "window equal to series",
+			values: []float64{1, 2, 4},
+			window: 3,
+			sma:    []interface{}{nil, nil, 2.3333},
+		},
+		{
+			name:   "ema",
+			values: []float64{10, 20, 30},
+			window: 2,
+			alpha:  0.5,
+			sma:    []interface{}{nil, 15.0, 25.0},
+			ema:    []interface{}{10.0, 15.0, 22.5},
+		},
+		{
+			name:   "ema with alpha one follows the series",
+			values: []float64{3, 1, 2},
+			window: 1,
+			alpha:  1,
+			sma:    []interface{}{3.0, 1.0, 2.0},
+			ema:    []interface{}{3.0, 1.0, 2.0},
+		},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.MovingAverage(tt.values, tt.window, tt.alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result["sma"], tt.sma) {
+				t.Errorf("sma = %v, want %v", result["sma"], tt.sma)
+			}
+			ema, ok := result["ema"]
+			if ok != (tt.ema != nil) || ok && !reflect.DeepEqual(ema, tt.ema) {
+				t.Errorf("ema = %v, want %v", ema, tt.ema)
+			}
+		})
+	}
+}
+
+func TestMovingAverageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		window int
+		alpha  float64
+	}{
+		{name: "no values", window: 1},
+		{name: "zero window", values: []float64{1}},
+		{name: "negative window", values: []float64{1}, window: -2},
+		{name: "alpha above one", values: []float64{1}, window: 1, alpha: 1.5},
+		{name: "negative alpha", values: []float64{1}, window: 1, alpha: -0.1},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		if _, err := dp.MovingAverage(tt.values, tt.window, tt.alpha); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestLTTB(t *testing.T) {
+	flat := func(n int) []Point {
+		points := make([]Point, n)
+		for i := range points {
+			points[i] = Point{X: float64(i)}
+		}
+		return points
+	}
+	spike := flat(10)
+	spike[5].Y = 10
+	dip := flat(10)
+	dip[3].Y = -4
+	dip[7].Y = 6
+
+	tests := []struct {
+		name      string
+		points    []Point
+		threshold int
+		want      []Point
+	}{
+		{name: "threshold above length", points: flat(3), threshold: 5, want: flat(3)},
+		{name: "threshold equal to length", points: flat(4), threshold: 4, want: flat(4)},
+		{name: "two points", points: flat(2), threshold: 1, want: flat(2)},
+		{name: "endpoints below three", points: flat(5), threshold: 2, want: []Point{{X: 0}, {X: 4}}},
+		{name: "keeps a spike", points: spike, threshold: 3, want: []Point{{X: 0}, {X: 5, Y: 10}, {X: 9}}},
+		{name: "keeps extremes", points: dip, threshold: 4, want: []Point{{X: 0}, {X: 3, Y: -4}, {X: 7, Y: 6}, {X: 9}}},
+		{name: "empty", points: nil, threshold: 3, want: []Point{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LTTB(tt.points, tt.threshold)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLTTBCopiesInput(t *testing.T) {
+	points := []Point{{X: 0, Y: 1}, {X: 1, Y: 2}}
+	got := LTTB(points, 10)
+	got[0].Y = 99
+	if points[0].Y != 1 {
+		t.Error("LTTB returned the input slice")
+	}
+}
+
+func TestLTTBShape(t *testing.T) {
+	points := make([]Point, 1000)
+	for i := range points {
+		points[i] = Point{X: float64(i), Y: float64(i % 37)}
+	}
+
+	for _, threshold := range []int{3, 10, 99, 999} {
+		got := LTTB(points, threshold)
+		if len(got) != threshold {
+			t.Fatalf("threshold %d: got %d points", threshold, len(got))
+		}
+		if got[0] != points[0] || got[len(got)-1] != points[len(points)-1] {
+			t.Errorf("threshold %d: endpoints not kept", threshold)
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i].X <= got[i-1].X {
+				t.Fatalf("threshold %d: points out of order at %d", threshold, i)
+			}
+		}
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	dp := NewDataProcessor()
+	points := []Point{{0, 0}, {1, 5}, {2, 0}, {3, 0}}
+
+	result, err := dp.Downsample(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		map[string]interface{}{"x": 0.0, "y": 0.0},
+		map[string]interface{}{"x": 1.0, "y": 5.0},
+		map[string]interface{}{"x": 3.0, "y": 0.0},
+	}
+	if !reflect.DeepEqual(result["points"], want) || result["count"] != 3 || result["originalCount"] != 4 {
+		t.Errorf("got %v", result)
+	}
+
+	for _, target := range []int{-1, 0, 1} {
+		if _, err := dp.Downsample(points, target); err == nil {
+			t.Errorf("target %d: expected an error", target)
+		}
+	}
+	if _, err := dp.Downsample(nil, 3); err == nil {
+		t.Error("expected an error for no points")
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// stopwords are common English words ignored when comparing documents
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "he": true, "her": true, "his": true, "i": true, "if": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "she": true, "so": true, "that": true, "the": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "to": true, "was": true, "we": true,
+	"were": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// termFrequencies counts the non-stopword tokens in text
+func termFrequencies(text string) map[string]float64 {
+	tf := make(map[string]float64)
+	for _, token := range tokenize(text) {
+		if !stopwords[token] {
+			tf[token]++
+		}
+	}
+	return tf
+}
+
+func cosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, wa := range a {
+		dot += wa * b[term]
+		normA += wa * wa
+	}
+	for _, wb := range b {
+		normB += wb * wb
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// TextSimilarity compares two documents by the cosine of their term
+// frequency vectors. Without a corpus there is no IDF weighting, so common
+// words that survive the stopword list count as much as rare ones; use
+// CorpusSimilarity when a collection of documents is available.
+func (dp *DataProcessor) TextSimilarity(a, b string) (map[string]interface{}, error) {
+	if a == "" || b == "" {
+		return nil, fmt.Errorf("both documents are required")
+	}
+
+	tfA, tfB := termFrequencies(a), termFrequencies(b)
+
+	shared := 0
+	for term := range tfA {
+		if tfB[term] > 0 {
+			shared++
+		}
+	}
+
+	return map[string]interface{}{
+		"similarity":  math.Round(cosineSimilarity(tfA, tfB)*10000) / 10000,
+		"method":      "tf-cosine",
+		"sharedTerms": shared,
+	}, nil
+}
+
+// CorpusSimilarity scores each document in corpus against query using
+// TF-IDF weighted cosine similarity. IDF is computed over the corpus plus
+// the query, smoothed so terms present everywhere still carry some weight.
+// Results are sorted by descending similarity.
+func (dp *DataProcessor) CorpusSimilarity(query string, corpus []string) (map[string]interface{}, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query document is required")
+	}
+	if len(corpus) == 0 {
+		return nil, fmt.Errorf("corpus must contain at least one document")
+	}
+
+	docs := make([]map[string]float64, len(corpus)+1)
+	docs[0] = termFrequencies(query)
+	for i, doc := range corpus {
+		docs[i+1] = termFrequencies(doc)
+	}
+
+	docFreq := make(map[string]int)
+	for _, tf := range docs {
+		for term := range tf {
+			docFreq[term]++
+		}
+	}
+
+	n := float64(len(docs))
+	weight := func(tf map[string]float64) map[string]float64 {
+		weighted := make(map[string]float64, len(tf))
+		for term, count := range tf {
+			idf := math.Log((1+n)/(1+float64(docFreq[term]))) + 1
+			weighted[term] = count * idf
+		}
+		return weighted
+	}
+
+	queryVec := weight(docs[0])
+	scores := make([]map[string]interface{}, len(corpus))
+	for i := range corpus {
+		scores[i] = map[string]interface{}{
+			"index":      i,
+			"similarity": math.Round(cosineSimilarity(queryVec, weight(docs[i+1]))*10000) / 10000,
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i]["similarity"].(float64) > scores[j]["similarity"].(float64)
+	})
+
+	results := make([]interface{}, len(scores))
+	for i, s := range scores {
+		results[i] = s
+	}
+
+	return map[string]interface{}{
+		"results": results,
+		"method":  "tfidf-cosine",
+		"count":   len(corpus),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestTextSimilarity(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       string
+		similarity float64
+		shared     int
+	}{
+		{name: "identical", a: "red apples and green pears", b: "red apples and green pears", similarity: 1, shared: 4},
+		{name: "case and stopwords", a: "The Cat sat", b: "a cat SAT", similarity: 1, shared: 2},
+		{name: "disjoint", a: "red apples", b: "blue cars", similarity: 0, shared: 0},
+		{name: "partial", a: "red apples", b: "red cars", similarity: 0.5, shared: 1},
+		{name: "only stopwords", a: "the and of", b: "the and of", similarity: 0, shared: 0},
+		{name: "repetition", a: "go go go", b: "go", similarity: 1, shared: 1},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.TextSimilarity(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["similarity"] != tt.similarity || result["sharedTerms"] != tt.shared {
+				t.Errorf("got similarity %v shared %v, want %v and %d",
+					result["similarity"], result["sharedTerms"], tt.similarity, tt.shared)
+			}
+		})
+	}
+
+	if _, err := dp.TextSimilarity("", "x"); err == nil {
+		t.Error("expected an error for an empty document")
+	}
+}
+
+func TestTextSimilaritySymmetric(t *testing.T) {
+	dp := NewDataProcessor()
+	a, b := "sync replicas exchange operations", "replicas merge operations and exchange clocks"
+	ab, _ := dp.TextSimilarity(a, b)
+	ba, _ := dp.TextSimilarity(b, a)
+	if ab["similarity"] != ba["similarity"] {
+		t.Errorf("similarity is not symmetric: %v and %v", ab["similarity"], ba["similarity"])
+	}
+}
+
+func TestCorpusSimilarity(t *testing.T) {
+	corpus := []string{
+		"cooking pasta with tomato sauce",
+		"vector clocks order operations between replicas",
+		"replicas sync",
+		"",
+	}
+
+	dp := NewDataProcessor()
+	result, err := dp.CorpusSimilarity("how do replicas order operations", corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := result["results"].([]interface{})
+	if len(results) != len(corpus) || result["count"] != len(corpus) {
+		t.Fatalf("got %d results for %d documents", len(results), len(corpus))
+	}
+
+	var order []int
+	prev := 2.0
+	for _, r := range results {
+		score := r.(map[string]interface{})
+		similarity := score["similarity"].(float64)
+		if similarity > prev {
+			t.Errorf("results are not sorted: %v", results)
+		}
+		prev = similarity
+		order = append(order, score["index"].(int))
+	}
+	if order[0] != 1 || order[1] != 2 {
+		t.Errorf("ranked %v, want documents 1 and 2 first", order)
+	}
+	// Unrelated and empty documents keep their corpus order
+	if order[2] != 0 || order[3] != 3 {
+		t.Errorf("ranked %v, want ties in corpus order", order)
+	}
+}
+
+func TestCorpusSimilarityIDF(t *testing.T) {
+	// "data" appears everywhere, so sharing only it counts for less than
+	// it does without IDF weighting
+	corpus := []string{"data merkle", "data data data", "data tree"}
+
+	dp := NewDataProcessor()
+	result, err := dp.CorpusSimilarity("data merkle", corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := result["results"].([]interface{})
+	first := results[0].(map[string]interface{})
+	if first["index"] != 0 || first["similarity"] != 1.0 {
+		t.Errorf("first result = %v, want document 0 with similarity 1", first)
+	}
+	common := results[1].(map[string]interface{})["similarity"].(float64)
+	tf, _ := dp.TextSimilarity("data merkle", "data data data")
+	if common >= tf["similarity"].(float64) {
+		t.Errorf("sharing only a common term scored %v, %v without IDF", common, tf["similarity"])
+	}
+}
+
+func TestCorpusSimilarityErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	if _, err := dp.CorpusSimilarity("", []string{"x"}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+	if _, err := dp.CorpusSimilarity("x", nil); err == nil {
+		t.Error("expected an error for an empty corpus")
+	}
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotFormat identifies an exported app state
+	SnapshotFormat = "local-first-state"
+	// SnapshotVersion is the snapshot layout written by this build
+	SnapshotVersion = 1
+)
+
+// Snapshot is a portable backup of an app's local data: localStorage
+// entries, IndexedDB records supplied by the page, and CRDT states keyed by
+// the handle they were exported from
+type Snapshot struct {
+	Format       string                   `json:"format"`
+	Version      int                      `json:"version"`
+	CreatedAt    string                   `json:"createdAt"`
+	Namespace    string                   `json:"namespace,omitempty"`
+	LocalStorage map[string]string        `json:"localStorage,omitempty"`
+	IndexedDB    map[string][]interface{} `json:"indexedDB,omitempty"`
+	Registers    map[string]*LWWRegister  `json:"registers,omitempty"`
+	Counters     map[string]*Counter      `json:"counters,omitempty"`
+	ORSets       map[string]*ORSet        `json:"orsets,omitempty"`
+}
+
+// NewSnapshot creates an empty snapshot at the current version
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		Format:       SnapshotFormat,
+		Version:      SnapshotVersion,
+		CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+		LocalStorage: map[string]string{},
+		IndexedDB:    map[string][]interface{}{},
+		Registers:    map[string]*LWWRegister{},
+		Counters:     map[string]*Counter{},
+		ORSets:       map[string]*ORSet{},
+	}
+}
+
+// Encode serializes the snapshot as JSON, or as base64 gzipped JSON when
+// compress is set
+func (s *Snapshot) Encode(compress bool) (string, error) {
+	if !compress {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+
+	data, err := GzipJSON(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Summary counts the entries of each kind in the snapshot
+func (s *Snapshot) Summary() map[string]interface{} {
+	records := 0
+	for _, store := range s.IndexedDB {
+		records += len(store)
+	}
+	return map[string]interface{}{
+		"version":      s.Version,
+		"createdAt":    s.CreatedAt,
+		"namespace":    s.Namespace,
+		"localStorage": len(s.LocalStorage),
+		"stores":       len(s.IndexedDB),
+		"records":      records,
+		"registers":    len(s.Registers),
+		"counters":     len(s.Counters),
+		"orsets":       len(s.ORSets),
+	}
+}
+
+// DecodeSnapshot parses a snapshot produced by Encode in either form and
+// rejects snapshots from an unknown format or an unsupported version
+func DecodeSnapshot(data string) (*Snapshot, error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return nil, fmt.Errorf("empty snapshot")
+	}
+
+	raw := []byte(data)
+	if data[0] != '{' {
+		compressed, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot is neither JSON nor base64: %w", err)
+		}
+		var decoded json.RawMessage
+		if err := GunzipJSON(compressed, &decoded); err != nil {
+			return nil, err
+		}
+		raw = decoded
+	}
+
+	var header struct {
+		Format  string `json:"format"`
+		Version int    `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if header.Format != SnapshotFormat {
+		return nil, fmt.Errorf("not a %s snapshot (format %q)", SnapshotFormat, header.Format)
+	}
+	if header.Version < 1 || header.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (this build reads versions 1 to %d)", header.Version, SnapshotVersion)
+	}
+
+	s := &Snapshot{}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if err := s.normalize(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// normalize fills in missing maps and validates CRDT states so an imported
+// snapshot behaves like one built with NewSnapshot
+func (s *Snapshot) normalize() error {
+	if s.LocalStorage == nil {
+		s.LocalStorage = map[string]string{}
+	}
+	if s.IndexedDB == nil {
+		s.IndexedDB = map[string][]interface{}{}
+	}
+	if s.Registers == nil {
+		s.Registers = map[string]*LWWRegister{}
+	}
+	for key, reg := range s.Registers {
+		if reg == nil {
+			s.Registers[key] = &LWWRegister{}
+		}
+	}
+
+	counters := map[string]*Counter{}
+	for key, c := range s.Counters {
+		if c == nil {
+			return fmt.Errorf("counter %s: missing state", key)
+		}
+		counter, err := NewCounter(c.Kind)
+		if err != nil {
+			return fmt.Errorf("counter %s: %w", key, err)
+		}
+		counter.P.Merge(c.P)
+		if counter.N != nil {
+			counter.N.Merge(c.N)
+		}
+		counters[key] = counter
+	}
+	s.Counters = counters
+
+	sets := map[string]*ORSet{}
+	for key, state := range s.ORSets {
+		set := NewORSet()
+		if state != nil {
+			set.Merge(state)
+		}
+		sets[key] = set
+	}
+	s.ORSets = sets
+	return nil
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	s := NewSnapshot()
+	s.Namespace = "app:"
+	s.LocalStorage["app:theme"] = "dark"
+	s.IndexedDB["notes"] = []interface{}{map[string]interface{}{"id": 1.0, "text": "hi"}}
+	s.Registers["1"] = &LWWRegister{Value: "x", Timestamp: 5, Replica: "a"}
+	counter, _ := NewCounter("pn")
+	counter.Increment("a", 3)
+	counter.Increment("b", -1)
+	s.Counters["2"] = counter
+	set := NewORSet()
+	set.Add("x", "a")
+	s.ORSets["3"] = set
+
+	for _, compress := range []bool{false, true} {
+		encoded, err := s.Encode(compress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compress == strings.HasPrefix(encoded, "{") {
+			t.Errorf("compress=%v produced %.20q", compress, encoded)
+		}
+
+		decoded, err := DecodeSnapshot(encoded)
+		if err != nil {
+			t.Fatalf("compress=%v: %v", compress, err)
+		}
+		if !reflect.DeepEqual(decoded, s) {
+			t.Errorf("compress=%v: decoded %+v, want %+v", compress, decoded, s)
+		}
+	}
+
+	want := map[string]interface{}{
+		"version": SnapshotVersion, "createdAt": s.CreatedAt, "namespace": "app:",
+		"localStorage": 1, "stores": 1, "records": 1, "registers": 1, "counters": 1, "orsets": 1,
+	}
+	if got := s.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("summary = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeSnapshotNormalizes(t *testing.T) {
+	s, err := DecodeSnapshot(`{"format":"local-first-state","version":1,"registers":{"1":null},"orsets":{"2":null},"counters":{"3":{"kind":"g","p":{"a":2}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.LocalStorage == nil || s.IndexedDB == nil {
+		t.Error("missing maps were not filled in")
+	}
+	if s.Registers["1"] == nil || s.ORSets["2"] == nil || s.ORSets["2"].Adds == nil {
+		t.Errorf("null CRDT states were not replaced: %+v %+v", s.Registers, s.ORSets)
+	}
+	if s.Counters["3"].Value() != 2 {
+		t.Errorf("counter value = %d, want 2", s.Counters["3"].Value())
+	}
+}
+
+func TestDecodeSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name, data, err string
+	}{
+		{name: "empty", data: "  ", err: "empty snapshot"},
+		{name: "not base64", data: "%%%", err: "neither JSON nor base64"},
+		{name: "base64 but not gzip", data: "aGVsbG8=", err: "invalid gzip data"},
+		{name: "invalid JSON", data: `{"format":`, err: "invalid snapshot"},
+		{name: "other format", data: `{"format":"other","version":1}`, err: "not a local-first-state snapshot"},
+		{name: "future version", data: `{"format":"local-first-state","version":2}`, err: "unsupported snapshot version 2"},
+		{name: "missing version", data: `{"format":"local-first-state"}`, err: "unsupported snapshot version 0"},
+		{name: "unknown counter kind", data: `{"format":"local-first-state","version":1,"counters":{"1":{"kind":"x"}}}`, err: "counter 1"},
+		{name: "null counter", data: `{"format":"local-first-state","version":1,"counters":{"1":null}}`, err: "missing state"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSnapshot(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
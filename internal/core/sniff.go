@@ -0,0 +1,205 @@
+package core
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strings"
+)
+
+// SniffSampleRows is how many records SniffTabular examines
+const SniffSampleRows = 100
+
+// sniffDelimiters are the candidate delimiters, in order of preference
+// when they fit equally well
+var sniffDelimiters = []rune{',', '\t', ';', '|'}
+
+// delimiterFit is how well a delimiter splits the sample
+type delimiterFit struct {
+	delimiter   rune
+	records     [][]string
+	columns     int
+	consistency float64
+}
+
+// fitDelimiter parses the sample with delimiter and measures how many
+// records share the most common field count. A delimiter that never
+// splits a record does not fit at all.
+func fitDelimiter(text string, delimiter rune) delimiterFit {
+	fit := delimiterFit{delimiter: delimiter}
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	for len(fit.records) < SniffSampleRows {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fit
+		}
+		fit.records = append(fit.records, record)
+	}
+
+	counts := make(map[int]int)
+	for _, record := range fit.records {
+		counts[len(record)]++
+	}
+	for n, c := range counts {
+		if c > counts[fit.columns] || c == counts[fit.columns] && n > fit.columns {
+			fit.columns = n
+		}
+	}
+	if fit.columns > 1 {
+		fit.consistency = float64(counts[fit.columns]) / float64(len(fit.records))
+	}
+	return fit
+}
+
+// sniffHeader guesses whether the first record is a header by comparing
+// it with the rest column by column: a header is unique, non-empty text,
+// and is likely where it is text above numbers or differs in length from
+// fixed-length values below it
+func sniffHeader(records [][]string, columns int) bool {
+	if len(records) < 2 || len(records[0]) != columns {
+		return false
+	}
+	seen := make(map[string]bool)
+	for _, name := range records[0] {
+		name = strings.TrimSpace(name)
+		if _, numeric := numericValue(name); name == "" || numeric || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+
+	votes := 0
+	for c := 0; c < columns; c++ {
+		numeric, length, sameLength, inBody := true, -1, true, false
+		for _, record := range records[1:] {
+			if len(record) != columns {
+				continue
+			}
+			v := strings.TrimSpace(record[c])
+			if _, ok := numericValue(v); !ok && v != "" {
+				numeric = false
+			}
+			if length >= 0 && len(v) != length {
+				sameLength = false
+			}
+			length = len(v)
+			if v == strings.TrimSpace(records[0][c]) {
+				inBody = true
+			}
+		}
+		switch {
+		case inBody:
+			votes--
+		case numeric && length >= 0:
+			votes++
+		case sameLength && length >= 0 && len(strings.TrimSpace(records[0][c])) != length:
+			votes++
+		}
+	}
+	return votes > 0
+}
+
+// sniffQuoted reports whether any field in the sample starts with a quote
+func sniffQuoted(text, delimiter string) bool {
+	if strings.HasPrefix(text, `"`) || strings.Contains(text, "\n\"") {
+		return true
+	}
+	return delimiter != "" && strings.Contains(text, delimiter+`"`)
+}
+
+// SniffTabular guesses the dialect of pasted tabular text: the delimiter
+// (comma, tab, semicolon or pipe), the column count, whether the first
+// row is a header and whether fields are quoted. Each delimiter is scored
+// by the share of records with its most common field count; confidence is
+// that share for the best delimiter, reduced when another fits nearly as
+// well, so ragged rows and mixed delimiters lower it. With no delimiter
+// splitting the text, it is treated as a single column with an empty
+// delimiter and zero confidence.
+func (dp *DataProcessor) SniffTabular(text string) (map[string]interface{}, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	var best, runnerUp delimiterFit
+	candidates := make([]interface{}, 0, len(sniffDelimiters))
+	for _, d := range sniffDelimiters {
+		fit := fitDelimiter(text, d)
+		candidates = append(candidates, map[string]interface{}{
+			"delimiter":   string(d),
+			"columns":     fit.columns,
+			"consistency": math.Round(fit.consistency*100) / 100,
+		})
+		switch {
+		case fit.consistency > best.consistency ||
+			fit.consistency == best.consistency && fit.consistency > 0 && fit.columns > best.columns:
+			runnerUp, best = best, fit
+		case fit.consistency > runnerUp.consistency:
+			runnerUp = fit
+		}
+	}
+
+	delimiter := ""
+	columns := 1
+	records := best.records
+	if best.consistency > 0 {
+		delimiter = string(best.delimiter)
+		columns = best.columns
+	} else {
+		records = nil
+		for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+			if line != "" && len(records) < SniffSampleRows {
+				records = append(records, []string{line})
+			}
+		}
+	}
+	confidence := best.consistency * (1 - runnerUp.consistency/2)
+
+	hasHeader := sniffHeader(records, columns)
+	ragged := 0
+	for _, record := range records {
+		if len(record) != columns {
+			ragged++
+		}
+	}
+	var header interface{}
+	if hasHeader {
+		names := make([]interface{}, len(records[0]))
+		for i, name := range records[0] {
+			names[i] = strings.TrimSpace(name)
+		}
+		header = names
+	}
+	preview := make([]interface{}, 0, 5)
+	for _, record := range records[:min(len(records), 5)] {
+		fields := make([]interface{}, len(record))
+		for i, f := range record {
+			fields[i] = f
+		}
+		preview = append(preview, fields)
+	}
+
+	lineTerminator := "\n"
+	if strings.Contains(text, "\r\n") {
+		lineTerminator = "\r\n"
+	}
+
+	return map[string]interface{}{
+		"delimiter":      delimiter,
+		"columns":        columns,
+		"hasHeader":      hasHeader,
+		"header":         header,
+		"quoted":         sniffQuoted(text, delimiter),
+		"quoteChar":      `"`,
+		"lineTerminator": lineTerminator,
+		"confidence":     math.Round(confidence*100) / 100,
+		"sampledRows":    len(records),
+		"raggedRows":     ragged,
+		"candidates":     candidates,
+		"preview":        preview,
+	}, nil
+}
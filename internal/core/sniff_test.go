@@ -0,0 +1,142 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSniffTabularDelimiters(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name       string
+		text       string
+		delimiter  string
+		columns    int
+		confidence float64
+	}{
+		{"comma", "name,age\nada,36\nbob,41\n", ",", 2, 1},
+		{"tab", "name\tage\tcity\nada\t36\tLondon\nbob\t41\tParis", "\t", 3, 1},
+		// commas split two of the three lines, which lowers confidence
+		{"semicolon with decimal commas", "item;price\ntea;1,50\ncake;2,75\n", ";", 2, 0.67},
+		{"pipe", "a|b|c|d\n1|2|3|4\n", "|", 4, 1},
+		{"quoted delimiters", "name,note\n\"Smith, J\",\"said \"\"hi\"\"\"\nLee,ok\n", ",", 2, 1},
+	}
+	for _, tt := range tests {
+		result, err := dp.SniffTabular(tt.text)
+		if err != nil {
+			t.Fatalf("%s: SniffTabular: %v", tt.name, err)
+		}
+		if result["delimiter"] != tt.delimiter || result["columns"] != tt.columns {
+			t.Errorf("%s: delimiter %q with %v columns, want %q with %d", tt.name, result["delimiter"], result["columns"], tt.delimiter, tt.columns)
+		}
+		if result["confidence"] != tt.confidence {
+			t.Errorf("%s: confidence = %v, want %v", tt.name, result["confidence"], tt.confidence)
+		}
+	}
+}
+
+func TestSniffTabularHeader(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name   string
+		text   string
+		header []interface{}
+	}{
+		{"text over numbers", "name,score\nada,90\nbob,85\n", []interface{}{"name", "score"}},
+		{"fixed-length codes", "country,code\nFrance,FR\nSpain,ES\n", []interface{}{"country", "code"}},
+		{"numeric first row", "1,2\n3,4\n", nil},
+		{"repeated names", "a,a\nx,1\n", nil},
+		{"empty name", "a,\nx,1\n", nil},
+		{"first row repeats in body", "ada,bob\nada,bob\n", nil},
+		{"single row", "name,score\n", nil},
+	}
+	for _, tt := range tests {
+		result, err := dp.SniffTabular(tt.text)
+		if err != nil {
+			t.Fatalf("%s: SniffTabular: %v", tt.name, err)
+		}
+		if result["hasHeader"] != (tt.header != nil) {
+			t.Errorf("%s: hasHeader = %v", tt.name, result["hasHeader"])
+		}
+		if tt.header != nil && !reflect.DeepEqual(result["header"], tt.header) {
+			t.Errorf("%s: header = %v, want %v", tt.name, result["header"], tt.header)
+		}
+	}
+}
+
+func TestSniffTabularRagged(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.SniffTabular("a,b,c\n1,2,3\n4,5\n6,7,8\n")
+	if err != nil {
+		t.Fatalf("SniffTabular: %v", err)
+	}
+	if result["columns"] != 3 || result["raggedRows"] != 1 || result["sampledRows"] != 4 {
+		t.Errorf("result = %v", result)
+	}
+	if result["confidence"] != 0.75 {
+		t.Errorf("confidence = %v, want 0.75", result["confidence"])
+	}
+}
+
+func TestSniffTabularAmbiguous(t *testing.T) {
+	dp := NewDataProcessor()
+	// both delimiters split every line into two fields
+	result, err := dp.SniffTabular("a,b;c\nd,e;f\n")
+	if err != nil {
+		t.Fatalf("SniffTabular: %v", err)
+	}
+	if result["delimiter"] != "," || result["confidence"] != 0.5 {
+		t.Errorf("delimiter %q, confidence %v, want \",\" and 0.5", result["delimiter"], result["confidence"])
+	}
+}
+
+func TestSniffTabularSingleColumn(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.SniffTabular("apple\nbanana\n\ncherry\n")
+	if err != nil {
+		t.Fatalf("SniffTabular: %v", err)
+	}
+	if result["delimiter"] != "" || result["columns"] != 1 || result["confidence"] != 0.0 || result["sampledRows"] != 3 {
+		t.Errorf("result = %v", result)
+	}
+	want := []interface{}{[]interface{}{"apple"}, []interface{}{"banana"}, []interface{}{"cherry"}}
+	if !reflect.DeepEqual(result["preview"], want) {
+		t.Errorf("preview = %v, want %v", result["preview"], want)
+	}
+}
+
+func TestSniffTabularDetails(t *testing.T) {
+	dp := NewDataProcessor()
+	text := "\ufeffid,name\r\n" + strings.Repeat("1,\"x\"\r\n", 150)
+	result, err := dp.SniffTabular(text)
+	if err != nil {
+		t.Fatalf("SniffTabular: %v", err)
+	}
+	if result["lineTerminator"] != "\r\n" || result["quoted"] != true {
+		t.Errorf("lineTerminator %q, quoted %v", result["lineTerminator"], result["quoted"])
+	}
+	if result["sampledRows"] != SniffSampleRows || len(result["preview"].([]interface{})) != 5 {
+		t.Errorf("sampledRows %v, preview %d", result["sampledRows"], len(result["preview"].([]interface{})))
+	}
+	// the byte order mark is not part of the first name
+	if header := result["header"].([]interface{}); header[0] != "id" {
+		t.Errorf("header = %v", header)
+	}
+
+	result, _ = dp.SniffTabular("a,b\n1,2\n")
+	if result["quoted"] != false || result["lineTerminator"] != "\n" {
+		t.Errorf("quoted %v, lineTerminator %q", result["quoted"], result["lineTerminator"])
+	}
+}
+
+func TestSniffTabularEmpty(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.SniffTabular("")
+	if err != nil {
+		t.Fatalf("SniffTabular: %v", err)
+	}
+	if result["sampledRows"] != 0 || result["hasHeader"] != false || result["confidence"] != 0.0 {
+		t.Errorf("result = %v", result)
+	}
+}
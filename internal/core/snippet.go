@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultSnippetLength is the snippet length in characters when none is given
+const DefaultSnippetLength = 160
+
+// snippetMatch is a match of a query term over runes [start, end)
+type snippetMatch struct {
+	start, end int
+	term       string
+}
+
+// isWordRune reports whether r is part of a word for match boundaries
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// SnippetTerms lowercases and deduplicates query terms, longest first so
+// the longest term wins where several start at the same place
+func SnippetTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, t := range terms {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return len([]rune(out[i])) > len([]rune(out[j]))
+	})
+	return out
+}
+
+// findSnippetMatches finds case-insensitive, non-overlapping matches of
+// terms that start at the beginning of a word, so "run" matches "Running"
+// but not "rerun"
+func findSnippetMatches(runes []rune, terms []string) []snippetMatch {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	termRunes := make([][]rune, len(terms))
+	for i, t := range terms {
+		termRunes[i] = []rune(t)
+	}
+
+	matches := []snippetMatch{}
+	for i := 0; i < len(lower); {
+		if i > 0 && isWordRune(lower[i-1]) && isWordRune(lower[i]) {
+			i++
+			continue
+		}
+		matched := false
+		for k, t := range termRunes {
+			if i+len(t) <= len(lower) && string(lower[i:i+len(t)]) == string(t) {
+				matches = append(matches, snippetMatch{i, i + len(t), terms[k]})
+				i += len(t)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return matches
+}
+
+// snippetWindow picks up to maxLength runes around the first match, or
+// from the start without one, trimmed to whole words where that keeps the
+// match in view
+func snippetWindow(runes []rune, matches []snippetMatch, maxLength int) (int, int) {
+	n := len(runes)
+	if n <= maxLength {
+		return 0, n
+	}
+
+	start, end := 0, maxLength
+	if len(matches) > 0 && matches[0].end-matches[0].start >= maxLength {
+		// the match alone fills the snippet
+		start, end = matches[0].start, matches[0].start+maxLength
+	} else if len(matches) > 0 {
+		first := matches[0]
+		start = max(first.start-(maxLength-(first.end-first.start))/2, 0)
+		end = min(start+maxLength, n)
+		start = max(end-maxLength, 0)
+		// a partial word at either edge is dropped unless it is the match
+		if start > 0 && isWordRune(runes[start-1]) {
+			for s := start; s < first.start; s++ {
+				if !isWordRune(runes[s]) {
+					start = s
+					break
+				}
+			}
+		}
+		if end < n && isWordRune(runes[end]) {
+			for e := end; e > first.end; e-- {
+				if !isWordRune(runes[e-1]) {
+					end = e
+					break
+				}
+			}
+		}
+	} else if isWordRune(runes[end]) {
+		for e := end; e > 0; e-- {
+			if !isWordRune(runes[e-1]) {
+				end = e
+				break
+			}
+		}
+	}
+
+	for start < end && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	for end > start && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+// Snippet returns an excerpt of text of up to maxLength characters
+// centred on the first match of the query terms, with an ellipsis where it
+// was cut. Matching is case-insensitive at the start of words. Without a
+// match the excerpt is the start of the text. Highlight offsets are
+// UTF-16 code units within the snippet; marked is the snippet HTML-escaped
+// with matches wrapped in <mark>.
+func (dp *DataProcessor) Snippet(text string, terms []string, maxLength int) (map[string]interface{}, error) {
+	if maxLength < 1 {
+		return nil, fmt.Errorf("maximum length must be at least 1")
+	}
+	terms = SnippetTerms(terms)
+
+	runes := []rune(text)
+	matches := findSnippetMatches(runes, terms)
+	start, end := snippetWindow(runes, matches, maxLength)
+
+	const ellipsis = "…"
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = ellipsis
+	}
+	if end < len(runes) {
+		suffix = ellipsis
+	}
+	excerpt := []rune(prefix + string(runes[start:end]) + suffix)
+	shift := start - len([]rune(prefix))
+	offsets := utf16Offsets(excerpt)
+
+	var marked strings.Builder
+	marked.WriteString(prefix)
+	pos := start
+	highlights := []interface{}{}
+	matchedTerms := []interface{}{}
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if !seen[m.term] {
+			seen[m.term] = true
+			matchedTerms = append(matchedTerms, m.term)
+		}
+		// matches cut by the window are highlighted as far as they show
+		ms, me := max(m.start, start), min(m.end, end)
+		if ms >= me {
+			continue
+		}
+		marked.WriteString(html.EscapeString(string(runes[pos:ms])))
+		marked.WriteString("<mark>" + html.EscapeString(string(runes[ms:me])) + "</mark>")
+		pos = me
+		highlights = append(highlights, map[string]interface{}{
+			"start": offsets[ms-shift],
+			"end":   offsets[me-shift],
+			"term":  m.term,
+		})
+	}
+	marked.WriteString(html.EscapeString(string(runes[pos:end])))
+	marked.WriteString(suffix)
+
+	return map[string]interface{}{
+		"snippet":      string(excerpt),
+		"marked":       marked.String(),
+		"highlights":   highlights,
+		"matched":      len(matches) > 0,
+		"matchCount":   len(matches),
+		"matchedTerms": matchedTerms,
+		"truncated":    prefix != "" || suffix != "",
+	}, nil
+}
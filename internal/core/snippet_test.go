@@ -0,0 +1,143 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnippetTerms(t *testing.T) {
+	got := SnippetTerms([]string{"Go", " go ", "", "golang", "  ", "Gö"})
+	if want := []string{"golang", "go", "gö"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SnippetTerms = %q, want %q", got, want)
+	}
+}
+
+func TestFindSnippetMatches(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  []snippetMatch
+	}{
+		{"Running late, rerun it", []string{"run"}, []snippetMatch{{0, 3, "run"}}},
+		{"new york, New Yorker", []string{"new york"}, []snippetMatch{{0, 8, "new york"}, {10, 18, "new york"}}},
+		// the longest term wins at the same start
+		{"golang go", []string{"golang", "go"}, []snippetMatch{{0, 6, "golang"}, {7, 9, "go"}}},
+		{"ÜBER über", []string{"über"}, []snippetMatch{{0, 4, "über"}, {5, 9, "über"}}},
+		{"snake_case case", []string{"case"}, []snippetMatch{{11, 15, "case"}}},
+		{"no match", []string{"xyz"}, []snippetMatch{}},
+	}
+	for _, tt := range tests {
+		got := findSnippetMatches([]rune(tt.text), SnippetTerms(tt.terms))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("findSnippetMatches(%q, %q) = %v, want %v", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSnippetShortText(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Snippet("Tom & Jerry <3", []string{"jerry"}, DefaultSnippetLength)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	if result["snippet"] != "Tom & Jerry <3" || result["truncated"] != false {
+		t.Errorf("result = %v", result)
+	}
+	if want := "Tom &amp; <mark>Jerry</mark> &lt;3"; result["marked"] != want {
+		t.Errorf("marked = %q, want %q", result["marked"], want)
+	}
+	want := []interface{}{map[string]interface{}{"start": 6, "end": 11, "term": "jerry"}}
+	if !reflect.DeepEqual(result["highlights"], want) {
+		t.Errorf("highlights = %v, want %v", result["highlights"], want)
+	}
+}
+
+func TestSnippetWindow(t *testing.T) {
+	dp := NewDataProcessor()
+	text := strings.Repeat("alpha beta ", 20) + "needle " + strings.Repeat("gamma delta ", 20)
+	result, err := dp.Snippet(text, []string{"needle"}, 40)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	snippet := result["snippet"].(string)
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || result["truncated"] != true {
+		t.Errorf("snippet = %q, want ellipses at both ends", snippet)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(snippet, "…"), "…")
+	if n := len([]rune(body)); n > 40 {
+		t.Errorf("snippet body is %d characters, want at most 40", n)
+	}
+	if !strings.Contains(body, "needle") {
+		t.Errorf("snippet %q does not contain the match", snippet)
+	}
+	// whole words only at the edges
+	for _, word := range strings.Fields(body) {
+		switch word {
+		case "alpha", "beta", "gamma", "delta", "needle":
+		default:
+			t.Errorf("snippet %q has a partial word %q", snippet, word)
+		}
+	}
+}
+
+func TestSnippetWithoutMatch(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Snippet("the quick brown fox jumps", []string{"cat"}, 12)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	if result["snippet"] != "the quick…" || result["matched"] != false || result["matchCount"] != 0 {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestSnippetLongMatch(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Snippet("see supercalifragilistic here", []string{"supercalifragilistic"}, 5)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	if result["snippet"] != "…super…" || result["marked"] != "…<mark>super</mark>…" {
+		t.Errorf("result = %v", result)
+	}
+	// offsets count the leading ellipsis
+	want := []interface{}{map[string]interface{}{"start": 1, "end": 6, "term": "supercalifragilistic"}}
+	if !reflect.DeepEqual(result["highlights"], want) {
+		t.Errorf("highlights = %v, want %v", result["highlights"], want)
+	}
+}
+
+func TestSnippetUTF16Offsets(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Snippet("\U0001F600 smile", []string{"smile"}, DefaultSnippetLength)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	// the emoji is two UTF-16 code units
+	want := []interface{}{map[string]interface{}{"start": 3, "end": 8, "term": "smile"}}
+	if !reflect.DeepEqual(result["highlights"], want) {
+		t.Errorf("highlights = %v, want %v", result["highlights"], want)
+	}
+}
+
+func TestSnippetMatchedTerms(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.Snippet("b a b c", []string{"a", "b", "z"}, DefaultSnippetLength)
+	if err != nil {
+		t.Fatalf("Snippet: %v", err)
+	}
+	if got, want := result["matchedTerms"], []interface{}{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matchedTerms = %v, want %v", got, want)
+	}
+	if result["matchCount"] != 3 {
+		t.Errorf("matchCount = %v, want 3", result["matchCount"])
+	}
+}
+
+func TestSnippetErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	if _, err := dp.Snippet("text", nil, 0); err == nil {
+		t.Error("expected an error for a zero length")
+	}
+}
@@ -0,0 +1,639 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlTokenKind classifies a SQL token
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlQuotedIdent
+	sqlString
+	sqlNumber
+	sqlComment
+	sqlPunct
+	sqlOperator
+	sqlParam
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// keyword reports whether the token is the given upper-case keyword
+func (t sqlToken) keyword(word string) bool {
+	return t.kind == sqlWord && strings.EqualFold(t.text, word)
+}
+
+// sqlKeywords are the words that are re-cased and never treated as names
+var sqlKeywords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		ADD ALL ALTER AND AS ASC BEGIN BETWEEN BY CASE CAST COLLATE COLUMN COMMIT
+		CONFLICT CONSTRAINT CREATE CROSS DEFAULT DELETE DESC DISTINCT DO DROP ELSE
+		END ESCAPE EXCEPT EXISTS EXPLAIN FALSE FOREIGN FROM FULL GLOB GROUP HAVING
+		IF IGNORE IN INDEX INNER INSERT INTERSECT INTO IS JOIN KEY LEFT LIKE LIMIT
+		NATURAL NOT NOTHING NULL OFFSET ON OR ORDER OUTER OVER PARTITION PRIMARY
+		RECURSIVE REFERENCES REPLACE RETURNING RIGHT ROLLBACK ROWID SELECT SET TABLE
+		TEMP TEMPORARY THEN TO TRANSACTION TRIGGER TRUE UNION UNIQUE UPDATE USING
+		VALUES VIEW VIRTUAL WHEN WHERE WINDOW WITH WITHOUT`) {
+		sqlKeywords[word] = true
+	}
+}
+
+func isSQLKeyword(t sqlToken) bool {
+	return t.kind == sqlWord && sqlKeywords[strings.ToUpper(t.text)]
+}
+
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// tokenizeSQL splits SQL into tokens, keeping string literals, quoted
+// identifiers and comments whole so that nothing inside them is treated as
+// a keyword. Whitespace is dropped.
+func tokenizeSQL(src string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+
+		case c == '-' && strings.HasPrefix(src[i:], "--"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+			tokens = append(tokens, sqlToken{sqlComment, strings.TrimRight(src[start:i], "\r")})
+			continue
+
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", start)
+			}
+			i += end + 4
+			tokens = append(tokens, sqlToken{sqlComment, src[start:i]})
+			continue
+
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated quote at offset %d", start)
+				}
+				if src[i] == closing {
+					// doubled quotes escape themselves
+					if closing != ']' && i+1 < len(src) && src[i+1] == closing {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			kind := sqlQuotedIdent
+			if c == '\'' {
+				kind = sqlString
+			}
+			tokens = append(tokens, sqlToken{kind, src[start:i]})
+			continue
+
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			for i < len(src) && (isSQLWordByte(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlNumber, src[start:i]})
+			continue
+
+		case isSQLWordByte(c):
+			for i < len(src) && isSQLWordByte(src[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlWord, src[start:i]})
+			continue
+
+		case c == '?' || (c == ':' || c == '@' || c == '$') && i+1 < len(src) && isSQLWordByte(src[i+1]):
+			i++
+			for i < len(src) && isSQLWordByte(src[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlParam, src[start:i]})
+			continue
+
+		case strings.IndexByte("(),;.", c) >= 0:
+			i++
+			tokens = append(tokens, sqlToken{sqlPunct, src[start:i]})
+			continue
+		}
+
+		for _, op := range []string{"<=", ">=", "<>", "!=", "==", "||", "<<", ">>"} {
+			if strings.HasPrefix(src[i:], op) {
+				i += len(op)
+				break
+			}
+		}
+		if i == start {
+			i++
+		}
+		tokens = append(tokens, sqlToken{sqlOperator, src[start:i]})
+	}
+	return tokens, nil
+}
+
+// isNameToken reports whether a token can be a table or column name
+func isNameToken(t sqlToken) bool {
+	return t.kind == sqlQuotedIdent || t.kind == sqlWord && !isSQLKeyword(t)
+}
+
+// sqlClauses are keywords that start a new line at the statement's indent
+var sqlClauses = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "INSERT": true, "VALUES": true, "UPDATE": true, "SET": true,
+	"DELETE": true, "RETURNING": true, "WITH": true, "WINDOW": true,
+	"JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "CROSS": true,
+	"FULL": true, "NATURAL": true,
+}
+
+// SQLFormatOptions controls FormatSQL
+type SQLFormatOptions struct {
+	// KeywordCase is "upper" (default) or "lower"
+	KeywordCase string
+	// Indent is the number of spaces per level (default 2)
+	Indent int
+}
+
+// sqlFormatter builds formatted SQL line by line
+type sqlFormatter struct {
+	opts   SQLFormatOptions
+	out    strings.Builder
+	line   strings.Builder
+	indent int
+}
+
+func (f *sqlFormatter) newline(indent int) {
+	if text := strings.TrimRight(f.line.String(), " "); strings.TrimSpace(text) != "" {
+		f.out.WriteString(text)
+		f.out.WriteByte('\n')
+	}
+	f.line.Reset()
+	f.line.WriteString(strings.Repeat(" ", indent*f.opts.Indent))
+	f.indent = indent
+}
+
+func (f *sqlFormatter) atLineStart() bool {
+	return strings.TrimSpace(f.line.String()) == ""
+}
+
+// sqlParen tracks an open parenthesis while formatting, along with the
+// state to restore when a subquery closes
+type sqlParen struct {
+	subquery     bool
+	indent       int
+	lineIndent   int
+	clause       string
+	inSelectList bool
+}
+
+// FormatSQL re-indents SQL with one clause per line, select lists and
+// WHERE/HAVING conditions split across lines and subqueries indented. Keywords are
+// re-cased; string literals, quoted identifiers and comments are left as
+// written.
+func FormatSQL(src string, opts SQLFormatOptions) (string, error) {
+	tokens, err := tokenizeSQL(src)
+	if err != nil {
+		return "", err
+	}
+	if opts.Indent <= 0 {
+		opts.Indent = 2
+	}
+	if opts.KeywordCase == "" {
+		opts.KeywordCase = "upper"
+	}
+	if opts.KeywordCase != "upper" && opts.KeywordCase != "lower" {
+		return "", fmt.Errorf("keyword case must be upper or lower")
+	}
+
+	f := &sqlFormatter{opts: opts}
+	base := 0
+	var parens []sqlParen
+	inSelectList := false
+	clause := ""
+
+	for i, t := range tokens {
+		text := t.text
+		if isSQLKeyword(t) {
+			if opts.KeywordCase == "upper" {
+				text = strings.ToUpper(text)
+			} else {
+				text = strings.ToLower(text)
+			}
+		}
+		upper := strings.ToUpper(t.text)
+		var prev sqlToken
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		parenDepth := 0
+		for _, p := range parens {
+			if !p.subquery {
+				parenDepth++
+			}
+		}
+		topLevel := len(parens) == 0 || parens[len(parens)-1].subquery
+
+		switch {
+		case t.kind == sqlComment:
+			if strings.HasPrefix(text, "--") {
+				if !f.atLineStart() {
+					f.line.WriteByte(' ')
+				}
+				f.line.WriteString(text)
+				f.newline(f.indent)
+				continue
+			}
+
+		case t.kind == sqlPunct && text == ";":
+			f.line.WriteString(";")
+			f.newline(0)
+			f.out.WriteByte('\n')
+			base, parens, inSelectList, clause = 0, nil, false, ""
+			continue
+
+		case t.kind == sqlWord && sqlClauses[upper] && topLevel && isSQLKeyword(t):
+			// multi-word clauses such as LEFT OUTER JOIN and DELETE FROM stay
+			// on one line
+			inline := upper == "JOIN" && (prev.keyword("LEFT") || prev.keyword("RIGHT") || prev.keyword("INNER") ||
+				prev.keyword("CROSS") || prev.keyword("FULL") || prev.keyword("NATURAL") || prev.keyword("OUTER")) ||
+				upper == "FROM" && prev.keyword("DELETE") || upper == "UPDATE" && prev.keyword("DO")
+			if inline {
+				f.line.WriteByte(' ')
+			} else if !(prev.kind == sqlPunct && prev.text == "(") {
+				f.newline(base)
+			}
+			if !inline {
+				clause = upper
+			}
+			inSelectList = upper == "SELECT"
+			f.line.WriteString(text)
+			if upper == "SELECT" {
+				// DISTINCT stays on the SELECT line
+				if i+1 < len(tokens) && (tokens[i+1].keyword("DISTINCT") || tokens[i+1].keyword("ALL")) {
+					continue
+				}
+				f.newline(base + 1)
+			}
+			continue
+
+		case inSelectList && (t.keyword("DISTINCT") || t.keyword("ALL")) && prev.keyword("SELECT"):
+			f.line.WriteString(" " + text)
+			f.newline(base + 1)
+			continue
+
+		case (t.keyword("AND") || t.keyword("OR")) && topLevel && parenDepth == 0 &&
+			(clause == "WHERE" || clause == "HAVING") &&
+			!betweenAnd(tokens, i):
+			f.newline(base + 1)
+			f.line.WriteString(text)
+			continue
+
+		case t.kind == sqlPunct && text == "(":
+			subquery := i+1 < len(tokens) && (tokens[i+1].keyword("SELECT") || tokens[i+1].keyword("WITH"))
+			// INSERT INTO t (a, b) is a column list, not a call
+			columnList := i >= 2 && (tokens[i-2].keyword("INTO") || tokens[i-2].keyword("TABLE"))
+			if (needsSpaceBefore(prev, t) || columnList) && !f.atLineStart() {
+				f.line.WriteByte(' ')
+			}
+			f.line.WriteString("(")
+			parens = append(parens, sqlParen{subquery: subquery, indent: base, lineIndent: f.indent,
+				clause: clause, inSelectList: inSelectList})
+			if subquery {
+				base = f.indent + 1
+				f.newline(base)
+			}
+			continue
+
+		case t.kind == sqlPunct && text == ")":
+			if len(parens) > 0 {
+				p := parens[len(parens)-1]
+				parens = parens[:len(parens)-1]
+				if p.subquery {
+					// the closing parenthesis lines up with the line that
+					// opened it, and the outer clause carries on after it
+					base = p.indent
+					f.newline(p.lineIndent)
+					f.line.WriteString(")")
+					clause, inSelectList = p.clause, p.inSelectList
+					continue
+				}
+			}
+			f.line.WriteString(")")
+			continue
+
+		case t.kind == sqlPunct && text == ",":
+			f.line.WriteString(",")
+			if inSelectList && topLevel && parenDepth == 0 {
+				f.newline(base + 1)
+			}
+			continue
+		}
+
+		if needsSpaceBefore(prev, t) && !f.atLineStart() {
+			f.line.WriteByte(' ')
+		}
+		f.line.WriteString(text)
+		if t.kind == sqlComment {
+			f.line.WriteByte(' ')
+		}
+	}
+	f.newline(0)
+	return strings.TrimRight(f.out.String(), "\n"), nil
+}
+
+// betweenAnd reports whether the AND at i belongs to a BETWEEN expression
+func betweenAnd(tokens []sqlToken, i int) bool {
+	for j := i - 1; j >= 0 && j >= i-4; j-- {
+		if tokens[j].keyword("BETWEEN") {
+			return true
+		}
+		if tokens[j].keyword("AND") || tokens[j].keyword("OR") {
+			return false
+		}
+	}
+	return false
+}
+
+// needsSpaceBefore decides whether to separate t from the previous token
+func needsSpaceBefore(prev, t sqlToken) bool {
+	if prev.text == "" {
+		return false
+	}
+	switch {
+	case t.kind == sqlPunct && (t.text == "," || t.text == ")" || t.text == "." || t.text == ";"):
+		return false
+	case prev.kind == sqlPunct && (prev.text == "(" || prev.text == "."):
+		return false
+	case t.kind == sqlPunct && t.text == "(":
+		// function calls hug their parenthesis; keywords such as IN do not
+		return !(prev.kind == sqlWord && !isSQLKeyword(prev) || prev.kind == sqlQuotedIdent)
+	}
+	return true
+}
+
+// SQLStatement is the analysis of one statement
+type SQLStatement struct {
+	Type    string
+	Tables  []string
+	Columns []string
+	CTEs    []string
+	Params  int
+}
+
+// sqlName joins a dotted name such as main.users or u.id starting at i and
+// returns it with the index after it
+func sqlName(tokens []sqlToken, i int) (string, int) {
+	name := tokens[i].text
+	i++
+	for i+1 < len(tokens) && tokens[i].kind == sqlPunct && tokens[i].text == "." &&
+		(isNameToken(tokens[i+1]) || tokens[i+1].kind == sqlOperator && tokens[i+1].text == "*") {
+		name += "." + tokens[i+1].text
+		i += 2
+	}
+	return name, i
+}
+
+// splitSQLStatements splits tokens on semicolons, dropping comments
+func splitSQLStatements(tokens []sqlToken) [][]sqlToken {
+	var statements [][]sqlToken
+	var current []sqlToken
+	for _, t := range tokens {
+		switch {
+		case t.kind == sqlComment:
+		case t.kind == sqlPunct && t.text == ";":
+			if len(current) > 0 {
+				statements = append(statements, current)
+			}
+			current = nil
+		default:
+			current = append(current, t)
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements
+}
+
+// AnalyzeSQL reports the type, tables and columns of each statement using
+// token patterns rather than a full grammar. Tables are names following
+// FROM, JOIN, INTO, UPDATE and TABLE; columns are the remaining
+// non-keyword names, excluding functions, aliases and CTE names.
+func AnalyzeSQL(src string) ([]SQLStatement, error) {
+	tokens, err := tokenizeSQL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []SQLStatement
+	for _, stmt := range splitSQLStatements(tokens) {
+		statements = append(statements, analyzeSQLStatement(stmt))
+	}
+	return statements, nil
+}
+
+func analyzeSQLStatement(tokens []sqlToken) SQLStatement {
+	var s SQLStatement
+	seenTable := map[string]bool{}
+	seenColumn := map[string]bool{}
+	skip := map[string]bool{}
+	addTable := func(name string) {
+		if !seenTable[name] {
+			seenTable[name] = true
+			s.Tables = append(s.Tables, name)
+		}
+	}
+	addColumn := func(name string) {
+		if !seenColumn[name] {
+			seenColumn[name] = true
+			s.Columns = append(s.Columns, name)
+		}
+	}
+
+	// the statement type is the first keyword after any WITH clause
+	depth := 0
+	for i, t := range tokens {
+		if t.kind == sqlPunct && t.text == "(" {
+			depth++
+		} else if t.kind == sqlPunct && t.text == ")" {
+			depth--
+		}
+		if depth == 0 && t.kind == sqlWord && isSQLKeyword(t) && !t.keyword("WITH") && !t.keyword("RECURSIVE") &&
+			!t.keyword("AS") && !t.keyword("EXPLAIN") {
+			s.Type = strings.ToUpper(t.text)
+			break
+		}
+		// CTE names are "name AS (" or "name (cols) AS ("
+		if t.keyword("AS") && i > 0 && i+1 < len(tokens) && tokens[i+1].text == "(" && depth == 0 {
+			j := i - 1
+			if tokens[j].text == ")" {
+				for j > 0 && tokens[j].text != "(" {
+					j--
+				}
+				j--
+			}
+			if j >= 0 && isNameToken(tokens[j]) {
+				s.CTEs = append(s.CTEs, tokens[j].text)
+				skip[tokens[j].text] = true
+			}
+		}
+	}
+	if s.Type == "" && len(tokens) > 0 {
+		s.Type = strings.ToUpper(tokens[0].text)
+	}
+
+	// names introduced with AS are aliases, not columns
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i-1].keyword("AS") && isNameToken(tokens[i]) {
+			skip[tokens[i].text] = true
+		}
+	}
+
+	// first pass: tables and their aliases
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == sqlParam {
+			s.Params++
+			continue
+		}
+		if !(t.keyword("FROM") || t.keyword("JOIN") || t.keyword("INTO") || t.keyword("UPDATE") || t.keyword("TABLE")) {
+			continue
+		}
+		// FROM a, b JOIN c: read a comma-separated list of table references
+		for j := i + 1; j < len(tokens); {
+			for j < len(tokens) && (tokens[j].keyword("IF") || tokens[j].keyword("NOT") || tokens[j].keyword("EXISTS") ||
+				tokens[j].keyword("OR") || tokens[j].keyword("REPLACE") || tokens[j].keyword("IGNORE")) {
+				j++
+			}
+			if j >= len(tokens) || !isNameToken(tokens[j]) {
+				break
+			}
+			name, next := sqlName(tokens, j)
+			j = next
+			// function-valued sources such as json_each(x) are not tables, but
+			// the list carries on after their arguments
+			if j < len(tokens) && tokens[j].text == "(" && !t.keyword("INTO") && !t.keyword("TABLE") {
+				for depth := 0; j < len(tokens); j++ {
+					if tokens[j].text == "(" {
+						depth++
+					} else if tokens[j].text == ")" {
+						if depth--; depth == 0 {
+							j++
+							break
+						}
+					}
+				}
+			} else {
+				if !skip[name] {
+					addTable(name)
+				}
+				skip[name] = true
+			}
+			if j < len(tokens) && tokens[j].keyword("AS") {
+				j++
+			}
+			if j < len(tokens) && isNameToken(tokens[j]) {
+				skip[tokens[j].text] = true
+				j++
+			}
+			if !(t.keyword("FROM") && j < len(tokens) && tokens[j].text == ",") {
+				break
+			}
+			j++
+		}
+	}
+
+	// second pass: columns are the remaining names
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if !isNameToken(t) {
+			if t.kind == sqlOperator && t.text == "*" && i > 0 && (tokens[i-1].keyword("SELECT") || tokens[i-1].text == ",") {
+				addColumn("*")
+			}
+			continue
+		}
+		name, next := sqlName(tokens, i)
+		i = next - 1
+		if skip[name] || next < len(tokens) && tokens[next].text == "(" {
+			continue
+		}
+		start := next - 1 - 2*strings.Count(name, ".")
+		if start > 0 && tokens[start-1].keyword("AS") {
+			continue
+		}
+		// a bare name following a column or closing paren in a select list
+		// is an alias: SELECT count(*) total
+		if start > 0 && (tokens[start-1].text == ")" || isNameToken(tokens[start-1])) && s.Type == "SELECT" {
+			continue
+		}
+		addColumn(name)
+	}
+	return s
+}
+
+// FormatSQL formats SQL for display
+func (dp *DataProcessor) FormatSQL(sql string, opts SQLFormatOptions) (map[string]interface{}, error) {
+	formatted, err := FormatSQL(sql, opts)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"formatted": formatted,
+		"lines":     strings.Count(formatted, "\n") + 1,
+	}, nil
+}
+
+// AnalyzeSQL lists the type, tables and columns of each statement
+func (dp *DataProcessor) AnalyzeSQL(sql string) (map[string]interface{}, error) {
+	statements, err := AnalyzeSQL(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := func(values []string) []interface{} {
+		out := make([]interface{}, len(values))
+		for i, v := range values {
+			out[i] = v
+		}
+		return out
+	}
+	list := make([]interface{}, len(statements))
+	for i, s := range statements {
+		list[i] = map[string]interface{}{
+			"type":       s.Type,
+			"tables":     strs(s.Tables),
+			"columns":    strs(s.Columns),
+			"ctes":       strs(s.CTEs),
+			"parameters": s.Params,
+		}
+	}
+	return map[string]interface{}{
+		"statements": list,
+		"count":      len(statements),
+	}, nil
+}
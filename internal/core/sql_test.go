@@ -0,0 +1,127 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts SQLFormatOptions
+		want string
+	}{
+		{
+			name: "clauses and conditions",
+			src:  "select a, b from users where x = 1 and y between 1 and 5 order by a",
+			want: "SELECT\n  a,\n  b\nFROM users\nWHERE x = 1\n  AND y BETWEEN 1 AND 5\nORDER BY a",
+		},
+		{
+			name: "subquery in where",
+			src:  "select a from t where a in (select b from u) and c = 1",
+			want: "SELECT\n  a\nFROM t\nWHERE a IN (\n  SELECT\n    b\n  FROM u\n)\n  AND c = 1",
+		},
+		{
+			name: "subquery in select list",
+			src:  "select (select max(x) from t) as m, y from u",
+			want: "SELECT\n  (\n    SELECT\n      max(x)\n    FROM t\n  ) AS m,\n  y\nFROM u",
+		},
+		{
+			name: "common table expression and join",
+			src:  "with recent as (select * from posts) select p.title from recent p left outer join users u on u.id = p.uid",
+			want: "WITH recent AS (\n  SELECT\n    *\n  FROM posts\n)\nSELECT\n  p.title\nFROM recent p\nLEFT OUTER JOIN users u ON u.id = p.uid",
+		},
+		{
+			name: "literals and comments kept",
+			src:  "SELECT DISTINCT count(*) total FROM t -- Note\nWHERE a='select' ;select 1",
+			want: "SELECT DISTINCT\n  count(*) total\nFROM t -- Note\nWHERE a = 'select';\n\nSELECT\n  1",
+		},
+		{
+			name: "insert",
+			src:  "insert into t (a, b) values (?, $2)",
+			want: "INSERT INTO t (a, b)\nVALUES (?, $2)",
+		},
+		{
+			name: "lower case and wider indent",
+			src:  "SELECT a FROM t WHERE a = 1 OR b = 2",
+			opts: SQLFormatOptions{KeywordCase: "lower", Indent: 4},
+			want: "select\n    a\nfrom t\nwhere a = 1\n    or b = 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSQL(tt.src, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatSQL =\n%s\nwant\n%s", got, tt.want)
+			}
+			// formatting is idempotent
+			again, err := FormatSQL(got, tt.opts)
+			if err != nil || again != got {
+				t.Errorf("reformatting changed the output:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatSQLErrors(t *testing.T) {
+	for _, src := range []string{"select 'open", "select \"open", "select 1 /* open"} {
+		if _, err := FormatSQL(src, SQLFormatOptions{}); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+	if _, err := FormatSQL("select 1", SQLFormatOptions{KeywordCase: "title"}); err == nil {
+		t.Error("expected an error for an unknown keyword case")
+	}
+}
+
+func TestAnalyzeSQL(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []SQLStatement
+	}{
+		{
+			"select a, b from users where x = 1 or z in (select id from t)",
+			[]SQLStatement{{Type: "SELECT", Tables: []string{"users", "t"}, Columns: []string{"a", "b", "x", "z", "id"}}},
+		},
+		{
+			"with recent as (select * from posts) select p.title, count(*) total from recent p join users u on u.id = p.uid",
+			[]SQLStatement{{Type: "SELECT", Tables: []string{"posts", "users"}, Columns: []string{"*", "p.title", "u.id", "p.uid"}, CTEs: []string{"recent"}}},
+		},
+		{
+			"insert or replace into main.t (a, b) values (?, ?); delete from logs where ts < :cutoff",
+			[]SQLStatement{
+				{Type: "INSERT", Tables: []string{"main.t"}, Columns: []string{"a", "b"}, Params: 2},
+				{Type: "DELETE", Tables: []string{"logs"}, Columns: []string{"ts"}, Params: 1},
+			},
+		},
+		{
+			"select value from json_each(x), items i -- from comment\nwhere \"order\" > 1",
+			[]SQLStatement{{Type: "SELECT", Tables: []string{"items"}, Columns: []string{"value", "x", `"order"`}}},
+		},
+		{
+			"update accounts set balance = balance - 1 where id = @id",
+			[]SQLStatement{{Type: "UPDATE", Tables: []string{"accounts"}, Columns: []string{"balance", "id"}, Params: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := AnalyzeSQL(tt.src)
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.src, got, tt.want)
+		}
+	}
+
+	if _, err := AnalyzeSQL("select 'open"); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Errorf("err = %v, want an unterminated quote error", err)
+	}
+}
@@ -0,0 +1,336 @@
+package core
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// htmlBlockElements start on a new line; those mapped to 2 are separated
+// from their neighbours by a blank line
+var htmlBlockElements = map[string]int{
+	"p": 2, "h1": 2, "h2": 2, "h3": 2, "h4": 2, "h5": 2, "h6": 2, "blockquote": 2, "pre": 2,
+	"ul": 2, "ol": 2, "dl": 2, "table": 2, "figure": 2, "hr": 2, "form": 2, "fieldset": 2,
+	"div": 1, "section": 1, "article": 1, "header": 1, "footer": 1, "nav": 1, "aside": 1, "main": 1,
+	"li": 1, "dt": 1, "dd": 1, "tr": 1, "caption": 1, "figcaption": 1, "address": 1,
+	"details": 1, "summary": 1, "br": 1, "option": 1, "legend": 1,
+}
+
+// htmlSkipElements have content that is not readable body text
+var htmlSkipElements = map[string]bool{
+	"script": true, "style": true, "template": true, "noscript": true, "title": true,
+}
+
+// htmlTagToken is a start or end tag found by nextHTMLTag
+type htmlTagToken struct {
+	name  string
+	end   bool
+	attrs map[string]string
+}
+
+// nextHTMLTag parses the tag at src[i], which is '<'. It returns ok=false
+// when the '<' does not start a tag and is plain text. Comments, doctypes
+// and processing instructions are returned with an empty name.
+func nextHTMLTag(src string, i int) (tag htmlTagToken, next int, ok bool) {
+	rest := src[i+1:]
+	switch {
+	case strings.HasPrefix(rest, "!--"):
+		end := strings.Index(rest[3:], "-->")
+		if end < 0 {
+			return tag, len(src), true
+		}
+		return tag, i + 1 + 3 + end + 3, true
+	case strings.HasPrefix(rest, "!") || strings.HasPrefix(rest, "?"):
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return tag, len(src), true
+		}
+		return tag, i + 1 + end + 1, true
+	}
+
+	j := i + 1
+	if j < len(src) && src[j] == '/' {
+		tag.end = true
+		j++
+	}
+	start := j
+	for j < len(src) && (isASCIILetter(src[j]) || j > start && (src[j] >= '0' && src[j] <= '9' || src[j] == '-')) {
+		j++
+	}
+	if j == start {
+		return tag, i, false
+	}
+	tag.name = strings.ToLower(src[start:j])
+	tag.attrs = make(map[string]string)
+
+	// attributes: name, name=value, name="value" or name='value'
+	for j < len(src) {
+		for j < len(src) && (isHTMLSpace(src[j]) || src[j] == '/') {
+			j++
+		}
+		if j >= len(src) {
+			break
+		}
+		if src[j] == '>' {
+			return tag, j + 1, true
+		}
+		nameStart := j
+		for j < len(src) && !isHTMLSpace(src[j]) && src[j] != '=' && src[j] != '>' && src[j] != '/' {
+			j++
+		}
+		name := strings.ToLower(src[nameStart:j])
+		for j < len(src) && isHTMLSpace(src[j]) {
+			j++
+		}
+		value := ""
+		if j < len(src) && src[j] == '=' {
+			j++
+			for j < len(src) && isHTMLSpace(src[j]) {
+				j++
+			}
+			if j < len(src) && (src[j] == '"' || src[j] == '\'') {
+				quote := src[j]
+				end := strings.IndexByte(src[j+1:], quote)
+				if end < 0 {
+					return tag, len(src), true
+				}
+				value = src[j+1 : j+1+end]
+				j += end + 2
+			} else {
+				valueStart := j
+				for j < len(src) && !isHTMLSpace(src[j]) && src[j] != '>' {
+					j++
+				}
+				value = src[valueStart:j]
+			}
+		}
+		if _, seen := tag.attrs[name]; !seen && name != "" {
+			tag.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	// a tag cut off at the end of the input is dropped
+	return tag, len(src), true
+}
+
+// indexEndTag returns the index of the first </name end tag in s, matching
+// the name case-insensitively, or -1
+func indexEndTag(s, name string) int {
+	for i := 0; ; i += 2 {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		i += j
+		rest := s[i+2:]
+		if len(rest) >= len(name) && strings.EqualFold(rest[:len(name)], name) &&
+			(len(rest) == len(name) || isHTMLSpace(rest[len(name)]) || rest[len(name)] == '/' || rest[len(name)] == '>') {
+			return i
+		}
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// plainTextWriter collapses whitespace and turns block boundaries into
+// line breaks
+type plainTextWriter struct {
+	b      strings.Builder
+	breaks int
+	space  bool
+}
+
+// lineBreak requests n line breaks (1 or 2) before the next text
+func (w *plainTextWriter) lineBreak(n int) {
+	if w.b.Len() > 0 {
+		w.breaks = max(w.breaks, n)
+	}
+	w.space = false
+}
+
+// flush writes any pending break or space before text
+func (w *plainTextWriter) flush() {
+	switch {
+	case w.breaks > 0:
+		w.b.WriteString(strings.Repeat("\n", w.breaks))
+	case w.space && w.b.Len() > 0 && !strings.HasSuffix(w.b.String(), " "):
+		// a list marker already ends in a space
+		w.b.WriteByte(' ')
+	}
+	w.breaks, w.space = 0, false
+}
+
+// text writes s with runs of whitespace collapsed to one space
+func (w *plainTextWriter) text(s string) {
+	for s != "" {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		if unicode.IsSpace(r) {
+			w.space = true
+			continue
+		}
+		w.flush()
+		w.b.WriteRune(r)
+	}
+}
+
+// preformatted writes s as is
+func (w *plainTextWriter) preformatted(s string) {
+	if s == "" {
+		return
+	}
+	w.flush()
+	w.b.WriteString(s)
+}
+
+// HTMLLink is a link found while stripping HTML
+type HTMLLink struct {
+	Text string
+	Href string
+}
+
+// StripHTML converts HTML to readable plain text. Block elements become
+// line breaks, list items are prefixed with "- ", scripts, styles and the
+// document title are dropped, whitespace is collapsed outside <pre> and
+// entities are decoded. With showLinks, a link whose URL differs from its
+// text is written as "text (url)". Malformed markup is tolerated: stray
+// '<' characters are kept as text and unclosed tags are ignored.
+func StripHTML(src string, showLinks bool) (string, []HTMLLink) {
+	w := &plainTextWriter{}
+	links := []HTMLLink{}
+	pre := 0
+	type openLink struct {
+		href  string
+		start int
+	}
+	var anchors []openLink
+
+	for i := 0; i < len(src); {
+		lt := strings.IndexByte(src[i:], '<')
+		if lt < 0 {
+			lt = len(src) - i
+		}
+		if lt > 0 {
+			text := html.UnescapeString(src[i : i+lt])
+			if pre > 0 {
+				w.preformatted(text)
+			} else {
+				w.text(text)
+			}
+			i += lt
+			continue
+		}
+
+		tag, next, ok := nextHTMLTag(src, i)
+		if !ok {
+			// a '<' that does not open a tag is text
+			w.text("<")
+			i++
+			continue
+		}
+		i = next
+
+		switch {
+		case tag.name == "":
+		case htmlSkipElements[tag.name] && !tag.end:
+			// skip to the matching end tag, or the end of the input
+			end := indexEndTag(src[i:], tag.name)
+			if end < 0 {
+				i = len(src)
+				break
+			}
+			i += end
+			if gt := strings.IndexByte(src[i:], '>'); gt >= 0 {
+				i += gt + 1
+			} else {
+				i = len(src)
+			}
+
+		case tag.name == "pre":
+			if tag.end {
+				pre = max(pre-1, 0)
+			} else {
+				pre++
+			}
+			w.lineBreak(2)
+
+		case tag.name == "a" && !tag.end:
+			w.flush()
+			anchors = append(anchors, openLink{href: tag.attrs["href"], start: w.b.Len()})
+
+		case tag.name == "a" && tag.end:
+			if len(anchors) == 0 {
+				break
+			}
+			a := anchors[len(anchors)-1]
+			anchors = anchors[:len(anchors)-1]
+			text := strings.TrimSpace(w.b.String()[min(a.start, w.b.Len()):])
+			href := strings.TrimSpace(a.href)
+			if href == "" || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+				break
+			}
+			links = append(links, HTMLLink{Text: text, Href: href})
+			if showLinks && href != text && !strings.HasPrefix(href, "#") {
+				w.space = true
+				w.text("(" + href + ")")
+			}
+
+		case tag.name == "img" && !tag.end:
+			if alt := tag.attrs["alt"]; alt != "" {
+				w.space = w.space || w.b.Len() > 0
+				w.text(alt)
+			}
+
+		case tag.name == "td" || tag.name == "th":
+			if !tag.end {
+				w.space = true
+			}
+
+		case tag.name == "li" && !tag.end:
+			w.lineBreak(1)
+			w.flush()
+			w.b.WriteString("- ")
+
+		default:
+			if n, ok := htmlBlockElements[tag.name]; ok {
+				if tag.name == "br" && w.b.Len() > 0 {
+					// consecutive <br>s each add a line
+					w.b.WriteString(strings.Repeat("\n", w.breaks))
+					w.breaks = 1
+					w.space = false
+					break
+				}
+				w.lineBreak(n)
+			}
+		}
+	}
+
+	return w.b.String(), links
+}
+
+// StripHTML converts an HTML string to plain text for previews and search
+// indexing, also returning the links it contains
+func (dp *DataProcessor) StripHTML(src string, showLinks bool) (map[string]interface{}, error) {
+	text, links := StripHTML(src, showLinks)
+
+	list := make([]interface{}, len(links))
+	for i, l := range links {
+		list[i] = map[string]interface{}{
+			"text": l.Text,
+			"href": l.Href,
+		}
+	}
+
+	return map[string]interface{}{
+		"text":           text,
+		"characterCount": CountGraphemes(text),
+		"wordCount":      len(strings.Fields(text)),
+		"links":          list,
+	}, nil
+}
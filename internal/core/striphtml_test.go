@@ -0,0 +1,112 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", "just text", "just text"},
+		{"whitespace", "  a \n\t b  ", "a b"},
+		{"inline", "a <b>bold</b> and <em>em</em>", "a bold and em"},
+		{"paragraphs", "<p>one</p><p>two</p>", "one\n\ntwo"},
+		{"divs", "<div>one</div><div>two</div>", "one\ntwo"},
+		{"headings", "<h1>Title</h1>text", "Title\n\ntext"},
+		{"br", "a<br>b<br/><br />c", "a\nb\n\nc"},
+		{"list", "<ul><li>one</li><li>two</li></ul>", "- one\n- two"},
+		{"list item whitespace", "<ul>\n  <li>\n    one\n  </li>\n</ul>", "- one"},
+		{"table", "<table><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></table>", "a b\nc"},
+		{"entities", "Fish &amp; Chips &lt;3 &nbsp;", "Fish & Chips <3"},
+		{"stray lt", "1 < 2 and a <3", "1 < 2 and a <3"},
+		{"pre", "<p>x</p><pre>  keep\n    this</pre>after", "x\n\n  keep\n    this\n\nafter"},
+		{"script and style", "a<script>var x = '<p>';</script>b<style>p{}</style>c", "abc"},
+		{"upper case end tag", "a<SCRIPT>x</Script >b", "ab"},
+		{"end tag prefix", "a<script>x</scripts>y</script>b", "ab"},
+		{"unclosed script", "a<script>never closed", "a"},
+		{"title", "<head><title>Page</title></head><body>Body</body>", "Body"},
+		{"comment", "a<!-- <p>hidden</p> -->b", "ab"},
+		{"doctype", "<!DOCTYPE html><p>x</p>", "x"},
+		{"img alt", "see <img src=x.png alt=\"a cat\"> here", "see a cat here"},
+		{"attribute with gt", "<a title=\"1 > 0\">x</a>", "x"},
+		{"unclosed tag", "text <b", "text"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := StripHTML(tt.src, false); got != tt.want {
+			t.Errorf("%s: StripHTML(%q) = %q, want %q", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestStripHTMLSkipsNonASCII(t *testing.T) {
+	// lower-casing İ shortens it, which must not shift the end tag
+	src := "<script>" + strings.Repeat("İ", 8) + "if (a > b) {}</script>after"
+	if got, _ := StripHTML(src, false); got != "after" {
+		t.Errorf("StripHTML = %q, want %q", got, "after")
+	}
+}
+
+func TestStripHTMLLinks(t *testing.T) {
+	src := `<a href="https://example.com">Example</a>, ` +
+		`<a href='https://example.com'>https://example.com</a>, ` +
+		`<a href="#top">top</a>, <a href="javascript:void(0)">js</a>, <a>bare</a>`
+	text, links := StripHTML(src, true)
+	if want := "Example (https://example.com), https://example.com, top, js, bare"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	want := []HTMLLink{
+		{"Example", "https://example.com"},
+		{"https://example.com", "https://example.com"},
+		{"top", "#top"},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("links = %v, want %v", links, want)
+	}
+
+	// urls are left out of the text unless asked for
+	if text, _ := StripHTML(src, false); text != "Example, https://example.com, top, js, bare" {
+		t.Errorf("text without links = %q", text)
+	}
+}
+
+func TestNextHTMLTag(t *testing.T) {
+	input := `<A HREF="x&amp;y" href=dup data-x = 'q' checked>rest`
+	tag, next, ok := nextHTMLTag(input, 0)
+	if !ok || tag.name != "a" || tag.end || input[next:] != "rest" {
+		t.Fatalf("tag = %+v, next = %d, ok = %v", tag, next, ok)
+	}
+	want := map[string]string{"href": "x&y", "data-x": "q", "checked": ""}
+	if !reflect.DeepEqual(tag.attrs, want) {
+		t.Errorf("attrs = %v, want %v", tag.attrs, want)
+	}
+
+	if tag, _, ok := nextHTMLTag("</h2 >", 0); !ok || tag.name != "h2" || !tag.end {
+		t.Errorf("end tag = %+v, %v", tag, ok)
+	}
+	for _, s := range []string{"< b>", "<1>", "<"} {
+		if _, next, ok := nextHTMLTag(s, 0); ok || next != 0 {
+			t.Errorf("nextHTMLTag(%q) = %d, %v, want text", s, next, ok)
+		}
+	}
+}
+
+func TestStripHTMLResult(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.StripHTML("<p>Hello <a href=\"/w\">world</a></p>", false)
+	if err != nil {
+		t.Fatalf("StripHTML: %v", err)
+	}
+	if result["text"] != "Hello world" || result["wordCount"] != 2 || result["characterCount"] != 11 {
+		t.Errorf("result = %v", result)
+	}
+	links := result["links"].([]interface{})
+	if len(links) != 1 || links[0].(map[string]interface{})["href"] != "/w" {
+		t.Errorf("links = %v", links)
+	}
+}
@@ -0,0 +1,339 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDiffEdits bounds the Myers search. Texts further apart than this are
+// patched by replacing the differing region wholesale, which is still exact
+// but not minimal.
+const maxDiffEdits = 4000
+
+// TextEdit replaces Delete runes at Offset in the original text with Insert.
+// Offsets count Unicode code points, not bytes or UTF-16 units.
+type TextEdit struct {
+	Offset int    `json:"offset"`
+	Delete int    `json:"delete,omitempty"`
+	Insert string `json:"insert,omitempty"`
+}
+
+// TextPatch turns a text of BaseLength runes into one of Length runes. Edits
+// are sorted by offset, do not overlap and are all relative to the original.
+type TextPatch struct {
+	BaseLength int        `json:"baseLength"`
+	Length     int        `json:"length"`
+	Edits      []TextEdit `json:"edits"`
+}
+
+// DiffText computes a patch from a to b with Myers' O(ND) diff over runes,
+// after trimming the common prefix and suffix
+func DiffText(ctx context.Context, a, b string) (*TextPatch, error) {
+	ra, rb := []rune(a), []rune(b)
+	patch := &TextPatch{BaseLength: len(ra), Length: len(rb), Edits: []TextEdit{}}
+
+	prefix := 0
+	for prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(ra)-prefix && suffix < len(rb)-prefix && ra[len(ra)-1-suffix] == rb[len(rb)-1-suffix] {
+		suffix++
+	}
+	midA, midB := ra[prefix:len(ra)-suffix], rb[prefix:len(rb)-suffix]
+	if len(midA) == 0 && len(midB) == 0 {
+		return patch, nil
+	}
+
+	ops, err := myersDiff(ctx, midA, midB)
+	if err != nil {
+		return nil, err
+	}
+	if ops == nil {
+		patch.Edits = append(patch.Edits, TextEdit{Offset: prefix, Delete: len(midA), Insert: string(midB)})
+		return patch, nil
+	}
+
+	var current *TextEdit
+	var insert strings.Builder
+	flush := func() {
+		if current != nil {
+			current.Insert = insert.String()
+			patch.Edits = append(patch.Edits, *current)
+			current = nil
+			insert.Reset()
+		}
+	}
+	for _, op := range ops {
+		pos := prefix + op.a
+		switch op.kind {
+		case diffEqual:
+			flush()
+		case diffDelete:
+			if current == nil || current.Offset+current.Delete != pos {
+				flush()
+				current = &TextEdit{Offset: pos}
+			}
+			current.Delete++
+		case diffInsert:
+			if current == nil || current.Offset+current.Delete != pos {
+				flush()
+				current = &TextEdit{Offset: pos}
+			}
+			insert.WriteRune(midB[op.b])
+		}
+	}
+	flush()
+	return patch, nil
+}
+
+const (
+	diffEqual = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is one step of an edit script: keep or delete a[a], or insert b[b]
+// before a[a]
+type diffOp struct {
+	kind int
+	a, b int
+}
+
+// myersDiff returns the shortest edit script from a to b, or nil if it
+// needs more than maxDiffEdits edits. It uses the linear-space variant of
+// Myers' algorithm, splitting at the middle snake, so memory stays
+// proportional to the texts rather than to the square of the edit count.
+func myersDiff(ctx context.Context, a, b []rune) ([]diffOp, error) {
+	size := len(a) + len(b) + 2
+	d := &differ{ctx: ctx, a: a, b: b, vf: make([]int, 2*size), vb: make([]int, 2*size)}
+	if err := d.diff(0, len(a), 0, len(b), maxDiffEdits); err != nil {
+		if err == errDiffTooLong {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d.ops, nil
+}
+
+// errDiffTooLong stops a diff that needs more than maxDiffEdits edits
+var errDiffTooLong = errors.New("diff exceeds the edit limit")
+
+// differ holds the state of one linear-space diff. vf and vb are the
+// furthest reaching x on each diagonal searching forward from the start
+// and backward from the end; they are reused by every subproblem.
+type differ struct {
+	ctx    context.Context
+	a, b   []rune
+	vf, vb []int
+	ops    []diffOp
+	rounds int
+}
+
+// diff appends the edit script from a[x0:x1] to b[y0:y1], failing with
+// errDiffTooLong when it needs more than limit edits
+func (d *differ) diff(x0, x1, y0, y1, limit int) error {
+	switch {
+	case x0 == x1:
+		for y := y0; y < y1; y++ {
+			d.ops = append(d.ops, diffOp{diffInsert, x0, y})
+		}
+		return nil
+	case y0 == y1:
+		for x := x0; x < x1; x++ {
+			d.ops = append(d.ops, diffOp{diffDelete, x, y0})
+		}
+		return nil
+	}
+
+	edits, sx, sy, ex, ey, err := d.middleSnake(x0, x1, y0, y1, limit)
+	if err != nil {
+		return err
+	}
+	if edits <= 1 {
+		d.diffShort(x0, x1, y0, y1)
+		return nil
+	}
+	// both halves need fewer edits than the whole, so no further limit
+	if err := d.diff(x0, sx, y0, sy, edits); err != nil {
+		return err
+	}
+	for x, y := sx, sy; x < ex; x, y = x+1, y+1 {
+		d.ops = append(d.ops, diffOp{diffEqual, x, y})
+	}
+	return d.diff(ex, x1, ey, y1, edits)
+}
+
+// diffShort appends the script for ranges at most one edit apart: equal,
+// or one rune inserted or deleted
+func (d *differ) diffShort(x0, x1, y0, y1 int) {
+	x, y := x0, y0
+	for x < x1 && y < y1 && d.a[x] == d.b[y] {
+		d.ops = append(d.ops, diffOp{diffEqual, x, y})
+		x++
+		y++
+	}
+	switch {
+	case x1-x > y1-y:
+		d.ops = append(d.ops, diffOp{diffDelete, x, y})
+		x++
+	case y1-y > x1-x:
+		d.ops = append(d.ops, diffOp{diffInsert, x, y})
+		y++
+	}
+	for ; x < x1; x, y = x+1, y+1 {
+		d.ops = append(d.ops, diffOp{diffEqual, x, y})
+	}
+}
+
+// middleSnake finds the number of edits from a[x0:x1] to b[y0:y1] and the
+// snake of equal runes, from (sx, sy) to (ex, ey), where the forward and
+// backward searches meet. Both ranges must be non-empty.
+func (d *differ) middleSnake(x0, x1, y0, y1, limit int) (edits, sx, sy, ex, ey int, err error) {
+	n, m := x1-x0, y1-y0
+	delta := n - m
+	odd := delta%2 != 0
+	offset := len(d.vf) / 2
+	d.vf[offset+1], d.vb[offset+1] = 0, 0
+
+	for r := 0; r <= (n+m+1)/2; r++ {
+		if 2*r-1 > limit {
+			return 0, 0, 0, 0, 0, errDiffTooLong
+		}
+		if d.rounds%64 == 0 {
+			if err := d.ctx.Err(); err != nil {
+				return 0, 0, 0, 0, 0, err
+			}
+		}
+		d.rounds++
+
+		for k := -r; k <= r; k += 2 {
+			var x int
+			if k == -r || (k != r && d.vf[offset+k-1] < d.vf[offset+k+1]) {
+				x = d.vf[offset+k+1]
+			} else {
+				x = d.vf[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[x0+x] == d.b[y0+y] {
+				x++
+				y++
+			}
+			d.vf[offset+k] = x
+			if back := delta - k; odd && back >= -(r-1) && back <= r-1 && x+d.vb[offset+back] >= n {
+				return 2*r - 1, x0 + startX, y0 + startY, x0 + x, y0 + y, nil
+			}
+		}
+
+		if 2*r > limit {
+			return 0, 0, 0, 0, 0, errDiffTooLong
+		}
+		for k := -r; k <= r; k += 2 {
+			var x int
+			if k == -r || (k != r && d.vb[offset+k-1] < d.vb[offset+k+1]) {
+				x = d.vb[offset+k+1]
+			} else {
+				x = d.vb[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[x1-1-x] == d.b[y1-1-y] {
+				x++
+				y++
+			}
+			d.vb[offset+k] = x
+			if fwd := delta - k; !odd && fwd >= -r && fwd <= r && x+d.vf[offset+fwd] >= n {
+				return 2 * r, x1 - x, y1 - y, x1 - startX, y1 - startY, nil
+			}
+		}
+	}
+	// unreachable: the searches meet within (n+m+1)/2 rounds
+	return 0, 0, 0, 0, 0, errDiffTooLong
+}
+
+// Apply applies the patch to text, which must be the text it was computed
+// from
+func (p *TextPatch) Apply(text string) (string, error) {
+	runes := []rune(text)
+	if len(runes) != p.BaseLength {
+		return "", fmt.Errorf("patch expects a %d-character text, got %d", p.BaseLength, len(runes))
+	}
+
+	var out strings.Builder
+	out.Grow(len(text))
+	pos := 0
+	for i, edit := range p.Edits {
+		if edit.Offset < pos || edit.Delete < 0 || edit.Offset+edit.Delete > len(runes) {
+			return "", fmt.Errorf("edit %d (offset %d, delete %d) is out of order or out of range", i, edit.Offset, edit.Delete)
+		}
+		out.WriteString(string(runes[pos:edit.Offset]))
+		out.WriteString(edit.Insert)
+		pos = edit.Offset + edit.Delete
+	}
+	out.WriteString(string(runes[pos:]))
+
+	result := out.String()
+	if n := utf8.RuneCountInString(result); n != p.Length {
+		return "", fmt.Errorf("patched text has %d characters, patch expects %d", n, p.Length)
+	}
+	return result, nil
+}
+
+// ComputeTextPatch returns the offset-based edits turning oldText into
+// newText, for sending only what changed
+func (dp *DataProcessor) ComputeTextPatch(ctx context.Context, oldText, newText string) (map[string]interface{}, error) {
+	patch, err := DiffText(ctx, oldText, newText)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]interface{}, len(patch.Edits))
+	inserted, deleted := 0, 0
+	for i, edit := range patch.Edits {
+		edits[i] = map[string]interface{}{
+			"offset": edit.Offset,
+			"delete": edit.Delete,
+			"insert": edit.Insert,
+		}
+		inserted += utf8.RuneCountInString(edit.Insert)
+		deleted += edit.Delete
+	}
+
+	return map[string]interface{}{
+		"patch":      string(encoded),
+		"edits":      edits,
+		"baseLength": patch.BaseLength,
+		"length":     patch.Length,
+		"inserted":   inserted,
+		"deleted":    deleted,
+		"patchBytes": len(encoded),
+	}, nil
+}
+
+// ApplyTextPatch applies a patch produced by ComputeTextPatch
+func (dp *DataProcessor) ApplyTextPatch(text, patchJSON string) (map[string]interface{}, error) {
+	var patch TextPatch
+	if err := json.Unmarshal([]byte(patchJSON), &patch); err != nil {
+		return nil, fmt.Errorf("invalid text patch: %w", err)
+	}
+
+	result, err := patch.Apply(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"text":   result,
+		"length": patch.Length,
+	}, nil
+}
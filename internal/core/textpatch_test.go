@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiffTextEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits []TextEdit
+	}{
+		{name: "equal", a: "same", b: "same", edits: []TextEdit{}},
+		{name: "insert", a: "helo", b: "hello", edits: []TextEdit{{Offset: 3, Insert: "l"}}},
+		{name: "delete", a: "hello", b: "hlo", edits: []TextEdit{{Offset: 1, Delete: 2}}},
+		{name: "replace", a: "cat", b: "cut", edits: []TextEdit{{Offset: 1, Delete: 1, Insert: "u"}}},
+		{name: "from empty", a: "", b: "new", edits: []TextEdit{{Offset: 0, Insert: "new"}}},
+		{name: "to empty", a: "old", b: "", edits: []TextEdit{{Offset: 0, Delete: 3}}},
+		{name: "two regions", a: "abcdef", b: "aXcdeY", edits: []TextEdit{{Offset: 1, Delete: 1, Insert: "X"}, {Offset: 5, Delete: 1, Insert: "Y"}}},
+		// Offsets count runes, so the emoji and accented letter are one each
+		{name: "multi-byte", a: "café 😀 ok", b: "café 😃 ok", edits: []TextEdit{{Offset: 5, Delete: 1, Insert: "😃"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := DiffText(context.Background(), tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(patch.Edits, tt.edits) {
+				t.Errorf("edits = %+v, want %+v", patch.Edits, tt.edits)
+			}
+			got, err := patch.Apply(tt.a)
+			if err != nil || got != tt.b {
+				t.Errorf("apply = %q, %v; want %q", got, err, tt.b)
+			}
+		})
+	}
+}
+
+func TestDiffTextRoundTripRandom(t *testing.T) {
+	alphabet := []rune("ab é😀漢\n")
+	random := func(r *rand.Rand, n int) string {
+		runes := make([]rune, n)
+		for i := range runes {
+			runes[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return string(runes)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := random(r, r.Intn(30)), random(r, r.Intn(30))
+		patch, err := DiffText(context.Background(), a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := patch.Apply(a)
+		if err != nil || got != b {
+			t.Fatalf("%q -> %q: applied %q, %v", a, b, got, err)
+		}
+		if edited, want := editCount(patch), indelDistance(a, b); edited != want {
+			t.Fatalf("%q -> %q: %d runes edited, want the minimum %d", a, b, edited, want)
+		}
+	}
+}
+
+// editCount is the number of runes a patch deletes and inserts
+func editCount(p *TextPatch) int {
+	n := 0
+	for _, e := range p.Edits {
+		n += e.Delete + len([]rune(e.Insert))
+	}
+	return n
+}
+
+// indelDistance is the insert/delete edit distance between a and b
+func indelDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			if ra[i-1] == rb[j-1] {
+				cur[j] = prev[j-1]
+			} else {
+				cur[j] = min(prev[j], cur[j-1]) + 1
+			}
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func TestDiffTextBeyondEditLimit(t *testing.T) {
+	a := strings.Repeat("a", maxDiffEdits)
+	b := strings.Repeat("b", maxDiffEdits)
+	patch, err := DiffText(context.Background(), "x"+a+"y", "x"+b+"y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The differing middle is replaced in one edit
+	if want := []TextEdit{{Offset: 1, Delete: maxDiffEdits, Insert: b}}; !reflect.DeepEqual(patch.Edits, want) {
+		t.Errorf("got %d edits, want one wholesale replacement", len(patch.Edits))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DiffText(ctx, "abc", "xyz"); err == nil {
+		t.Error("expected an error for a cancelled context")
+	}
+}
+
+func TestDiffTextLargeRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	a := []rune(strings.Repeat("the quick brown fox jumps over the lazy dog ", 70))
+	b := append([]rune(nil), a...)
+	for i := 0; i < 300; i++ {
+		b[r.Intn(len(b))] = 'Z'
+	}
+	patch, err := DiffText(context.Background(), string(a), string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := patch.Apply(string(a))
+	if err != nil || got != string(b) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if edited, want := editCount(patch), indelDistance(string(a), string(b)); edited != want {
+		t.Errorf("%d runes edited, want the minimum %d", edited, want)
+	}
+}
+
+func TestDiffTextAllocation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	runes := func(alphabet string) string {
+		set := []rune(alphabet)
+		out := make([]rune, 3000)
+		for i := range out {
+			out[i] = set[r.Intn(len(set))]
+		}
+		return string(out)
+	}
+	// Unrelated texts blow the edit limit; the diff must give up without
+	// keeping a quadratic trace of the search
+	a, b := runes("abcdefgh"), runes("stuvwxyz")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	patch, err := DiffText(context.Background(), a, b)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch.Edits) != 1 {
+		t.Errorf("got %d edits, want one wholesale replacement", len(patch.Edits))
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("allocated %d bytes, want under 1 MiB", allocated)
+	}
+}
+
+func TestApplyTextPatch(t *testing.T) {
+	dp := NewDataProcessor()
+	computed, err := dp.ComputeTextPatch(context.Background(), "naïve text", "naïve café text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if computed["inserted"] != 5 || computed["deleted"] != 0 {
+		t.Errorf("computed = %v", computed)
+	}
+	patch := computed["patch"].(string)
+
+	applied, err := dp.ApplyTextPatch("naïve text", patch)
+	if err != nil || applied["text"] != "naïve café text" {
+		t.Errorf("applied = %v, %v", applied, err)
+	}
+
+	tests := []struct {
+		name, text, patch string
+	}{
+		{name: "wrong base", text: "short", patch: patch},
+		{name: "invalid JSON", text: "", patch: "{"},
+		{name: "overlapping edits", text: "abc", patch: `{"baseLength":3,"length":3,"edits":[{"offset":1,"delete":1},{"offset":0}]}`},
+		{name: "out of range", text: "abc", patch: `{"baseLength":3,"length":0,"edits":[{"offset":2,"delete":5}]}`},
+		{name: "wrong length", text: "abc", patch: `{"baseLength":3,"length":9,"edits":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dp.ApplyTextPatch(tt.text, tt.patch); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
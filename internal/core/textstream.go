@@ -0,0 +1,169 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// maxPendingText bounds the text a TextAnalyzer holds back between chunks.
+// A run without whitespace longer than this is split into separate words.
+const maxPendingText = 1 << 16
+
+// TextAnalyzer computes the statistics of ProcessText over text that
+// arrives in chunks, keeping counts and word frequencies rather than the
+// text itself. The trailing partial word and grapheme cluster of each
+// chunk are held back until the next chunk shows where they end, so the
+// result does not depend on where the text was split.
+type TextAnalyzer struct {
+	pending    string
+	bytes      int
+	chunks     int
+	graphemes  int
+	words      int
+	sentences  int
+	inSentence bool
+	freq       map[string]int
+}
+
+// NewTextAnalyzer creates an empty analyzer
+func NewTextAnalyzer() *TextAnalyzer {
+	return &TextAnalyzer{freq: make(map[string]int)}
+}
+
+// Feed adds the next chunk of text
+func (t *TextAnalyzer) Feed(chunk string) {
+	t.chunks++
+	t.bytes += len(chunk)
+	buf := t.pending + chunk
+
+	// the trailing word may continue in the next chunk
+	wordStart := len(buf)
+	for wordStart > 0 {
+		r, size := utf8.DecodeLastRuneInString(buf[:wordStart])
+		if unicode.IsSpace(r) {
+			break
+		}
+		wordStart -= size
+	}
+	if len(buf)-wordStart > maxPendingText {
+		wordStart = len(buf)
+	}
+
+	// cut at the last cluster boundary before the word, always holding back
+	// the final cluster, which a combining mark in the next chunk could
+	// extend
+	cut, state := 0, -1
+	for rest := buf; rest != ""; {
+		_, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		end := len(buf) - len(rest)
+		if rest == "" || end > wordStart {
+			break
+		}
+		cut = end
+	}
+
+	t.consume(buf[:cut])
+	t.pending = buf[cut:]
+}
+
+// consume counts text that ends at a word and cluster boundary
+func (t *TextAnalyzer) consume(s string) {
+	t.graphemes += CountGraphemes(s)
+	t.words += len(strings.Fields(s))
+	t.sentences, t.inSentence = countSentences(s, t.sentences, t.inSentence)
+	for _, word := range tokenize(s) {
+		t.freq[word]++
+	}
+}
+
+// countSentences continues a sentence count over s. Like ProcessText, a
+// sentence is any text between full stops that is not only whitespace.
+func countSentences(s string, count int, inSentence bool) (int, bool) {
+	for _, r := range s {
+		switch {
+		case r == '.':
+			if inSentence {
+				count++
+			}
+			inSentence = false
+		case !unicode.IsSpace(r):
+			inSentence = true
+		}
+	}
+	return count, inSentence
+}
+
+// Result returns the statistics of all text fed so far, including any text
+// still held back, with the top most frequent words (ties alphabetical)
+func (t *TextAnalyzer) Result(top int) map[string]interface{} {
+	graphemes := t.graphemes + CountGraphemes(t.pending)
+	words := t.words + len(strings.Fields(t.pending))
+	sentences, inSentence := countSentences(t.pending, t.sentences, t.inSentence)
+	if inSentence {
+		sentences++
+	}
+
+	// overlay the held-back words without changing the frequency map
+	tail := make(map[string]int)
+	for _, word := range tokenize(t.pending) {
+		tail[word]++
+	}
+	unique := len(t.freq)
+	for word := range tail {
+		if t.freq[word] == 0 {
+			unique++
+		}
+	}
+
+	type wordCount struct {
+		Word  string
+		Count int
+	}
+	counts := make([]wordCount, 0, unique)
+	for word, n := range t.freq {
+		counts = append(counts, wordCount{word, n + tail[word]})
+	}
+	for word, n := range tail {
+		if t.freq[word] == 0 {
+			counts = append(counts, wordCount{word, n})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Word < counts[j].Word
+	})
+	if top > len(counts) {
+		top = len(counts)
+	}
+	topWords := make([]interface{}, top)
+	for i := range topWords {
+		topWords[i] = map[string]interface{}{
+			"word":  counts[i].Word,
+			"count": counts[i].Count,
+		}
+	}
+
+	avgWordsPerSentence := float64(words)
+	if sentences > 0 {
+		avgWordsPerSentence = float64(words) / float64(sentences)
+	}
+
+	return map[string]interface{}{
+		"originalLength":      t.bytes,
+		"chunks":              t.chunks,
+		"characterCount":      graphemes,
+		"wordCount":           words,
+		"sentenceCount":       sentences,
+		"avgWordsPerSentence": math.Round(avgWordsPerSentence*100) / 100,
+		"uniqueWords":         unique,
+		"topWords":            topWords,
+		"readabilityScore":    readabilityScore(words, sentences, unique),
+	}
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// analyze feeds chunks to a fresh analyzer and returns its result
+func analyze(top int, chunks ...string) map[string]interface{} {
+	a := NewTextAnalyzer()
+	for _, chunk := range chunks {
+		a.Feed(chunk)
+	}
+	return a.Result(top)
+}
+
+func TestTextAnalyzerMatchesProcessText(t *testing.T) {
+	dp := NewDataProcessor()
+	input := "The cat sat. The cat ran!  Café au lait \U0001F1E9\U0001F1EA. the end"
+	want, err := dp.ProcessText(input)
+	if err != nil {
+		t.Fatalf("ProcessText: %v", err)
+	}
+	got := analyze(5, input)
+	for _, key := range []string{"originalLength", "characterCount", "wordCount", "sentenceCount", "avgWordsPerSentence", "uniqueWords", "readabilityScore"} {
+		if got[key] != want[key] {
+			t.Errorf("%s = %v, want %v", key, got[key], want[key])
+		}
+	}
+	top := got["topWords"].([]interface{})
+	if first := top[0].(map[string]interface{}); first["word"] != "the" || first["count"] != 3 {
+		t.Errorf("top word = %v, want the x3", first)
+	}
+}
+
+func TestTextAnalyzerSplitAnywhere(t *testing.T) {
+	// combining marks, a flag and multi-byte runes straddle every split
+	input := "Café naïve. \U0001F1E9\U0001F1EA flag  here.\nlast words"
+	want := analyze(10, input)
+	want["chunks"] = 2
+	for i := 0; i <= len(input); i++ {
+		got := analyze(10, input[:i], input[i:])
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("split at %d = %v, want %v", i, got, want)
+		}
+	}
+
+	// one byte at a time
+	bytes := make([]string, len(input))
+	for i := 0; i < len(input); i++ {
+		bytes[i] = input[i : i+1]
+	}
+	got := analyze(10, bytes...)
+	if got["chunks"] != len(input) {
+		t.Errorf("chunks = %v, want %d", got["chunks"], len(input))
+	}
+	got["chunks"] = 2
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("byte chunks = %v, want %v", got, want)
+	}
+}
+
+func TestTextAnalyzerTopWords(t *testing.T) {
+	// ties are broken alphabetically
+	got := analyze(2, "b a c ", "b a c d")["topWords"].([]interface{})
+	want := []interface{}{
+		map[string]interface{}{"word": "a", "count": 2},
+		map[string]interface{}{"word": "b", "count": 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("topWords = %v, want %v", got, want)
+	}
+
+	// top larger than the vocabulary
+	if n := len(analyze(10, "one two")["topWords"].([]interface{})); n != 2 {
+		t.Errorf("len(topWords) = %d, want 2", n)
+	}
+}
+
+func TestTextAnalyzerResultIsRepeatable(t *testing.T) {
+	a := NewTextAnalyzer()
+	a.Feed("one two tw")
+	first := a.Result(5)
+	if again := a.Result(5); !reflect.DeepEqual(again, first) {
+		t.Errorf("second Result = %v, want %v", again, first)
+	}
+
+	// the held-back word continues with the next chunk
+	a.Feed("o.")
+	got := a.Result(5)
+	if got["wordCount"] != 3 || got["uniqueWords"] != 2 || got["sentenceCount"] != 1 {
+		t.Errorf("after continuing = %v, want 3 words, 2 unique, 1 sentence", got)
+	}
+}
+
+func TestTextAnalyzerLongRun(t *testing.T) {
+	// a run without whitespace is not held back without bound
+	a := NewTextAnalyzer()
+	run := strings.Repeat("x", maxPendingText)
+	a.Feed(run)
+	a.Feed(run)
+	if len(a.pending) > maxPendingText {
+		t.Errorf("pending = %d bytes, want at most %d", len(a.pending), maxPendingText)
+	}
+	if got := a.Result(1)["characterCount"]; got != 2*maxPendingText {
+		t.Errorf("characterCount = %v, want %d", got, 2*maxPendingText)
+	}
+}
+
+func TestTextAnalyzerEmpty(t *testing.T) {
+	got := analyze(5)
+	if got["wordCount"] != 0 || got["sentenceCount"] != 0 || got["readabilityScore"] != 0.0 {
+		t.Errorf("empty = %v", got)
+	}
+	if n := len(got["topWords"].([]interface{})); n != 0 {
+		t.Errorf("len(topWords) = %d, want 0", n)
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// absent marks a member missing from one side of a three-way merge
+type absentValue struct{}
+
+var absent = absentValue{}
+
+// MergeConflict describes a path changed differently on both sides
+type MergeConflict struct {
+	Path   string
+	Kind   string // "add/add", "edit/edit" or "edit/delete"
+	Base   interface{}
+	Local  interface{}
+	Remote interface{}
+}
+
+// toMap returns the conflict for the handler response; values missing on a
+// side (deleted or never added) are left out
+func (c MergeConflict) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"path": c.Path,
+		"kind": c.Kind,
+	}
+	for key, value := range map[string]interface{}{"base": c.Base, "local": c.Local, "remote": c.Remote} {
+		if value != absent {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+// ThreeWayMergeDocs merges local and remote edits of a common base document.
+// Changes made on only one side are taken automatically and objects are
+// merged member by member. Where both sides changed the same value
+// differently a conflict is reported and the local value is kept.
+func ThreeWayMergeDocs(base, local, remote interface{}) (interface{}, []MergeConflict) {
+	var conflicts []MergeConflict
+	merged := threeWayMerge("", base, local, remote, &conflicts)
+	return merged, conflicts
+}
+
+func threeWayMerge(path string, base, local, remote interface{}, conflicts *[]MergeConflict) interface{} {
+	switch {
+	case reflect.DeepEqual(local, remote):
+		return local
+	case reflect.DeepEqual(base, local):
+		return remote
+	case reflect.DeepEqual(base, remote):
+		return local
+	}
+
+	localObj, localIsObj := local.(map[string]interface{})
+	remoteObj, remoteIsObj := remote.(map[string]interface{})
+	if localIsObj && remoteIsObj {
+		baseObj, _ := base.(map[string]interface{})
+		return mergeObjects(path, baseObj, localObj, remoteObj, conflicts)
+	}
+
+	kind := "edit/edit"
+	switch {
+	case base == absent:
+		kind = "add/add"
+	case local == absent || remote == absent:
+		kind = "edit/delete"
+	}
+	*conflicts = append(*conflicts, MergeConflict{
+		Path:   path,
+		Kind:   kind,
+		Base:   base,
+		Local:  local,
+		Remote: remote,
+	})
+	return local
+}
+
+func mergeObjects(path string, base, local, remote map[string]interface{}, conflicts *[]MergeConflict) interface{} {
+	keys := make(map[string]bool)
+	for _, obj := range []map[string]interface{}{base, local, remote} {
+		for key := range obj {
+			keys[key] = true
+		}
+	}
+
+	// Visit keys in order so conflicts are reported deterministically
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	lookup := func(obj map[string]interface{}, key string) interface{} {
+		if value, ok := obj[key]; ok {
+			return value
+		}
+		return absent
+	}
+
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range sorted {
+		childPath := path + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		value := threeWayMerge(childPath, lookup(base, key), lookup(local, key), lookup(remote, key), conflicts)
+		if value != absent {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// ThreeWayMerge merges local and remote JSON documents against their common
+// base and reports conflicting changes
+func (dp *DataProcessor) ThreeWayMerge(base, local, remote string) (map[string]interface{}, error) {
+	docs := make([]interface{}, 3)
+	for i, input := range []struct{ name, json string }{{"base", base}, {"local", local}, {"remote", remote}} {
+		if err := json.Unmarshal([]byte(input.json), &docs[i]); err != nil {
+			return nil, fmt.Errorf("invalid %s JSON: %w", input.name, err)
+		}
+	}
+
+	merged, conflicts := ThreeWayMergeDocs(docs[0], docs[1], docs[2])
+
+	result, err := patchResult(merged)
+	if err != nil {
+		return nil, err
+	}
+	conflictList := make([]interface{}, len(conflicts))
+	for i, c := range conflicts {
+		conflictList[i] = c.toMap()
+	}
+	result["conflicts"] = conflictList
+	result["hasConflicts"] = len(conflicts) > 0
+	return result, nil
+}
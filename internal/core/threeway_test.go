@@ -0,0 +1,126 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreeWayMergeDocs(t *testing.T) {
+	tests := []struct {
+		name                string
+		base, local, remote string
+		want                string
+		conflicts           []string // path and kind of each conflict
+	}{
+		{
+			name: "independent edits", base: `{"a":1,"b":1}`, local: `{"a":2,"b":1}`, remote: `{"a":1,"b":3}`,
+			want: `{"a":2,"b":3}`,
+		},
+		{
+			name: "same edit on both sides", base: `{"a":1}`, local: `{"a":2}`, remote: `{"a":2}`,
+			want: `{"a":2}`,
+		},
+		{
+			name: "one side deletes", base: `{"a":1,"b":1}`, local: `{"b":1}`, remote: `{"a":1,"b":2}`,
+			want: `{"b":2}`,
+		},
+		{
+			name: "both add different members", base: `{}`, local: `{"x":1}`, remote: `{"y":2}`,
+			want: `{"x":1,"y":2}`,
+		},
+		{
+			name: "nested objects", base: `{"p":{"q":1,"r":1}}`, local: `{"p":{"q":2,"r":1}}`, remote: `{"p":{"q":1,"r":2,"s":3}}`,
+			want: `{"p":{"q":2,"r":2,"s":3}}`,
+		},
+		{
+			name: "edit/edit keeps local", base: `{"a":1}`, local: `{"a":2}`, remote: `{"a":3}`,
+			want: `{"a":2}`, conflicts: []string{"/a edit/edit"},
+		},
+		{
+			name: "add/add", base: `{}`, local: `{"a":1}`, remote: `{"a":2}`,
+			want: `{"a":1}`, conflicts: []string{"/a add/add"},
+		},
+		{
+			name: "edit/delete keeps local edit", base: `{"a":1}`, local: `{"a":2}`, remote: `{}`,
+			want: `{"a":2}`, conflicts: []string{"/a edit/delete"},
+		},
+		{
+			name: "delete/edit keeps local delete", base: `{"a":1}`, local: `{}`, remote: `{"a":2}`,
+			want: `{}`, conflicts: []string{"/a edit/delete"},
+		},
+		{
+			name: "arrays are values", base: `{"l":[1]}`, local: `{"l":[1,2]}`, remote: `{"l":[0,1]}`,
+			want: `{"l":[1,2]}`, conflicts: []string{"/l edit/edit"},
+		},
+		{
+			name: "escaped paths and sorted conflicts", base: `{"b/c":1,"a~":1}`, local: `{"b/c":2,"a~":2}`, remote: `{"b/c":3,"a~":3}`,
+			want: `{"b/c":2,"a~":2}`, conflicts: []string{"/a~0 edit/edit", "/b~1c edit/edit"},
+		},
+		{
+			name: "root scalars", base: `1`, local: `2`, remote: `3`,
+			want: `2`, conflicts: []string{" edit/edit"},
+		},
+		{
+			name: "object replaced by scalar", base: `{"a":{"b":1}}`, local: `{"a":{"b":2}}`, remote: `{"a":"gone"}`,
+			want: `{"a":{"b":2}}`, conflicts: []string{"/a edit/edit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := ThreeWayMergeDocs(decodeTestJSON(t, tt.base), decodeTestJSON(t, tt.local), decodeTestJSON(t, tt.remote))
+			if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(merged, want) {
+				t.Errorf("merged = %v, want %v", merged, want)
+			}
+			var got []string
+			for _, c := range conflicts {
+				got = append(got, c.Path+" "+c.Kind)
+			}
+			if !reflect.DeepEqual(got, tt.conflicts) {
+				t.Errorf("conflicts = %q, want %q", got, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestThreeWayMergeSymmetric(t *testing.T) {
+	// Without conflicts the result doesn't depend on which side is local
+	base := `{"a":1,"b":{"c":1,"d":1},"e":1}`
+	x := `{"a":2,"b":{"c":1,"d":1}}`
+	y := `{"a":1,"b":{"c":1,"d":5},"e":1,"f":6}`
+
+	xy, conflicts := ThreeWayMergeDocs(decodeTestJSON(t, base), decodeTestJSON(t, x), decodeTestJSON(t, y))
+	yx, _ := ThreeWayMergeDocs(decodeTestJSON(t, base), decodeTestJSON(t, y), decodeTestJSON(t, x))
+	if len(conflicts) != 0 || !reflect.DeepEqual(xy, yx) {
+		t.Errorf("merges differ: %v and %v (%v)", xy, yx, conflicts)
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.ThreeWayMerge(`{"a":1,"b":1}`, `{"b":2}`, `{"a":3,"b":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []interface{}{
+		map[string]interface{}{"path": "/a", "kind": "edit/delete", "base": 1.0, "remote": 3.0},
+	}
+	if !reflect.DeepEqual(result["conflicts"], want) || result["hasConflicts"] != true {
+		t.Errorf("conflicts = %v, want %v", result["conflicts"], want)
+	}
+	if result["json"] != `{"b":2}` {
+		t.Errorf("json = %v", result["json"])
+	}
+
+	result, _ = dp.ThreeWayMerge(`{}`, `{"a":1}`, `{}`)
+	if result["hasConflicts"] != false || len(result["conflicts"].([]interface{})) != 0 {
+		t.Errorf("got %v", result)
+	}
+
+	for i, docs := range [][3]string{{`{`, `{}`, `{}`}, {`{}`, `x`, `{}`}, {`{}`, `{}`, ``}} {
+		if _, err := dp.ThreeWayMerge(docs[0], docs[1], docs[2]); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
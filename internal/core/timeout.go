@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// ErrTimeout is returned when an operation exceeds its deadline
+var ErrTimeout = errors.New("operation timed out")
+
+// DefaultTimeout is the deadline applied to handler computations
+const DefaultTimeout = 2 * time.Second
+
+// timeoutYieldInterval is how often a deadline context's Err lets other
+// goroutines run
+const timeoutYieldInterval = 10 * time.Millisecond
+
+// deadlineContext reports its deadline from Err by reading the clock. On
+// js/wasm nothing preempts a busy goroutine, so the timer behind
+// context.WithDeadline cannot fire while a computation holds the thread;
+// checking the clock directly works regardless. Err also yields now and
+// then so other goroutines are not starved. It is meant for a single
+// goroutine.
+type deadlineContext struct {
+	context.Context
+	deadline  time.Time
+	lastYield time.Time
+}
+
+func (c *deadlineContext) Err() error {
+	now := time.Now()
+	if !now.Before(c.deadline) {
+		return context.DeadlineExceeded
+	}
+	if now.Sub(c.lastYield) >= timeoutYieldInterval {
+		c.lastYield = now
+		runtime.Gosched()
+	}
+	return c.Context.Err()
+}
+
+// RunWithTimeout runs fn on the calling goroutine with a context whose
+// deadline is limit from now, and returns ErrTimeout if fn stops because
+// the deadline passed. The deadline is cooperative: long-running loops
+// must check ctx.Err(), which also yields to other goroutines, and fn is
+// not interrupted otherwise. A limit of zero or less disables the deadline.
+func RunWithTimeout[T any](limit time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if limit <= 0 {
+		return fn(context.Background())
+	}
+
+	now := time.Now()
+	parent, cancel := context.WithDeadline(context.Background(), now.Add(limit))
+	defer cancel()
+
+	value, err := fn(&deadlineContext{Context: parent, deadline: now.Add(limit), lastYield: now})
+	if errors.Is(err, context.DeadlineExceeded) {
+		var zero T
+		return zero, ErrTimeout
+	}
+	return value, err
+}
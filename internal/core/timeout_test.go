@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// spin loops until ctx reports an error or the loop runs for a second,
+// the way long operations poll their context
+func spin(ctx context.Context) (int, error) {
+	start := time.Now()
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if time.Since(start) > time.Second {
+			return n, nil
+		}
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   time.Duration
+		fn      func(ctx context.Context) (int, error)
+		want    int
+		wantErr error
+	}{
+		{
+			name:  "fast operation",
+			limit: time.Second,
+			fn:    func(ctx context.Context) (int, error) { return 42, nil },
+			want:  42,
+		},
+		{
+			name:    "slow operation",
+			limit:   20 * time.Millisecond,
+			fn:      spin,
+			wantErr: ErrTimeout,
+		},
+		{
+			name:    "operation error",
+			limit:   time.Second,
+			fn:      func(ctx context.Context) (int, error) { return 0, errors.New("boom") },
+			wantErr: errors.New("boom"),
+		},
+		{
+			name:  "disabled",
+			limit: 0,
+			fn: func(ctx context.Context) (int, error) {
+				if _, ok := ctx.Deadline(); ok {
+					return 0, errors.New("unexpected deadline")
+				}
+				return 7, nil
+			},
+			want: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			got, err := RunWithTimeout(tt.limit, tt.fn)
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("took %v", elapsed)
+			}
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()):
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithTimeoutLetsOtherGoroutinesRun(t *testing.T) {
+	ran := make(chan struct{})
+	go close(ran)
+
+	_, err := RunWithTimeout(50*time.Millisecond, func(ctx context.Context) (bool, error) {
+		for {
+			select {
+			case <-ran:
+				return true, nil
+			default:
+			}
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("goroutine did not run before the deadline: %v", err)
+	}
+}
+
+func TestCheckJSONContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CheckJSONContext(ctx, "[1,2,3]", DefaultJSONLimits); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+}
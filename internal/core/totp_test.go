@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	key, err := decodeTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if got := totpCode(key, uint64(tt.unix)/totpStep, 8); got != tt.want {
+			t.Errorf("T=%d: got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateTOTP(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GenerateTOTP(rfc6238Secret, time.Unix(59, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The 6-digit code is the last six digits of the 8-digit vector
+	if result["code"] != "287082" {
+		t.Errorf("code = %v, want 287082", result["code"])
+	}
+	if result["expiresIn"] != 1 {
+		t.Errorf("expiresIn = %v, want 1", result["expiresIn"])
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	dp := NewDataProcessor()
+	at := time.Unix(1111111111, 0)
+	counter := int64(1111111111 / totpStep)
+	key, _ := decodeTOTPSecret(rfc6238Secret)
+	codeAt := func(offset int64) string { return totpCode(key, uint64(counter+offset), totpDigits) }
+
+	tests := []struct {
+		name      string
+		code      string
+		window    int
+		valid     bool
+		drift     int
+		wantError bool
+	}{
+		{name: "current", code: codeAt(0), window: 0, valid: true},
+		{name: "previous step within window", code: codeAt(-1), window: 1, valid: true, drift: -1},
+		{name: "next step within window", code: codeAt(1), window: 1, valid: true, drift: 1},
+		{name: "outside window", code: codeAt(2), window: 1},
+		{name: "surrounding whitespace", code: " " + codeAt(0) + " ", window: 0, valid: true},
+		{name: "wrong code", code: "000000", window: 0},
+		{name: "largest window", code: codeAt(-MaxTOTPWindow), window: MaxTOTPWindow, valid: true, drift: -MaxTOTPWindow},
+		{name: "window too large", code: codeAt(0), window: MaxTOTPWindow + 1, wantError: true},
+		{name: "negative window", code: codeAt(0), window: -1, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dp.ValidateTOTP(rfc6238Secret, tt.code, tt.window, at)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["valid"] != tt.valid {
+				t.Fatalf("valid = %v, want %v", result["valid"], tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if result["drift"] != tt.drift {
+				t.Errorf("drift = %v, want %d", result["drift"], tt.drift)
+			}
+			if result["counter"] != counter+int64(tt.drift) {
+				t.Errorf("counter = %v, want %d", result["counter"], counter+int64(tt.drift))
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.GenerateTOTPSecret("Acme", "ann@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := result["secret"].(string)
+	if key, err := decodeTOTPSecret(secret); err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, %v", secret, len(key), err)
+	}
+	uri := result["uri"].(string)
+	if !strings.HasPrefix(uri, "otpauth://totp/Acme:ann@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("uri = %s", uri)
+	}
+
+	if _, err := dp.GenerateTOTPSecret("Acme", ""); err == nil {
+		t.Error("expected an error without an account")
+	}
+}
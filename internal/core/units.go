@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// unit converts to its dimension's base unit as (value + Shift) * Num / Den.
+// Only temperatures have a shift; keeping the ratio as two numbers lets
+// conversions such as Fahrenheit to Celsius come out exact.
+type unit struct {
+	Dimension string
+	Num, Den  float64
+	Shift     float64
+}
+
+func (u unit) toBase(v float64) float64   { return (v + u.Shift) * u.Num / u.Den }
+func (u unit) fromBase(v float64) float64 { return v*u.Den/u.Num - u.Shift }
+
+// absoluteZeroC is the lowest temperature, in the Celsius base unit
+const absoluteZeroC = -273.15
+
+// unitRegistry maps lower-case unit names and aliases to their conversion.
+// Base units are metres, kilograms and degrees Celsius.
+var unitRegistry = map[string]unit{}
+
+func registerUnit(dimension string, num, den, shift float64, names ...string) {
+	for _, name := range names {
+		unitRegistry[name] = unit{Dimension: dimension, Num: num, Den: den, Shift: shift}
+	}
+}
+
+func init() {
+	registerUnit("length", 1, 1, 0, "m", "meter", "meters", "metre", "metres")
+	registerUnit("length", 1000, 1, 0, "km", "kilometer", "kilometers", "kilometre", "kilometres")
+	registerUnit("length", 0.01, 1, 0, "cm", "centimeter", "centimeters", "centimetre", "centimetres")
+	registerUnit("length", 0.001, 1, 0, "mm", "millimeter", "millimeters", "millimetre", "millimetres")
+	registerUnit("length", 1609.344, 1, 0, "mi", "mile", "miles")
+	registerUnit("length", 0.9144, 1, 0, "yd", "yard", "yards")
+	registerUnit("length", 0.3048, 1, 0, "ft", "foot", "feet")
+	registerUnit("length", 0.0254, 1, 0, "in", "inch", "inches")
+	registerUnit("length", 1852, 1, 0, "nmi", "nautical mile", "nautical miles")
+
+	registerUnit("weight", 1, 1, 0, "kg", "kilogram", "kilograms")
+	registerUnit("weight", 0.001, 1, 0, "g", "gram", "grams")
+	registerUnit("weight", 1e-6, 1, 0, "mg", "milligram", "milligrams")
+	registerUnit("weight", 1000, 1, 0, "t", "tonne", "tonnes")
+	registerUnit("weight", 0.45359237, 1, 0, "lb", "lbs", "pound", "pounds")
+	registerUnit("weight", 0.028349523125, 1, 0, "oz", "ounce", "ounces")
+	registerUnit("weight", 6.35029318, 1, 0, "st", "stone", "stones")
+
+	registerUnit("temperature", 1, 1, 0, "c", "°c", "celsius")
+	registerUnit("temperature", 1, 1, absoluteZeroC, "k", "kelvin")
+	registerUnit("temperature", 5, 9, -32, "f", "°f", "fahrenheit")
+}
+
+// lookupUnit finds a registered unit, or a currency code present in rates
+func lookupUnit(name string, rates map[string]float64) (unit, error) {
+	if u, ok := unitRegistry[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return u, nil
+	}
+	code := strings.ToUpper(strings.TrimSpace(name))
+	if rate, ok := rates[code]; ok {
+		if rate <= 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+			return unit{}, fmt.Errorf("rate for %s must be a positive number", code)
+		}
+		// rates are units of the currency per unit of the base currency
+		return unit{Dimension: "currency", Num: 1, Den: rate}, nil
+	}
+	if len(code) == 3 && len(rates) == 0 {
+		return unit{}, fmt.Errorf("unknown unit %q (currency conversion needs a rate table)", name)
+	}
+	return unit{}, fmt.Errorf("unknown unit %q", name)
+}
+
+// ConvertUnit converts value between two units of the same dimension.
+// Currencies are looked up in rates, which gives the amount of each
+// currency per unit of a common base currency; the base itself should be
+// listed with a rate of 1.
+func ConvertUnit(value float64, from, to string, rates map[string]float64) (float64, string, error) {
+	src, err := lookupUnit(from, rates)
+	if err != nil {
+		return 0, "", err
+	}
+	dst, err := lookupUnit(to, rates)
+	if err != nil {
+		return 0, "", err
+	}
+	if src.Dimension != dst.Dimension {
+		return 0, "", fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, src.Dimension, to, dst.Dimension)
+	}
+
+	base := src.toBase(value)
+	if src.Dimension == "temperature" && base < absoluteZeroC-1e-9 {
+		return 0, "", fmt.Errorf("%v %s is below absolute zero", value, from)
+	}
+	return dst.fromBase(base), src.Dimension, nil
+}
+
+// ConvertUnits converts a value between length, weight, temperature or
+// currency units
+func (dp *DataProcessor) ConvertUnits(value float64, from, to string, rates map[string]float64) (map[string]interface{}, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("value must be a finite number")
+	}
+	normalized := make(map[string]float64, len(rates))
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+	rates = normalized
+
+	result, dimension, err := ConvertUnit(value, from, to, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"value":     value,
+		"from":      from,
+		"to":        to,
+		"result":    result,
+		"dimension": dimension,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestConvertUnit(t *testing.T) {
+	rates := map[string]float64{"USD": 1, "EUR": 0.9, "JPY": 150}
+	tests := []struct {
+		value     float64
+		from, to  string
+		want      float64
+		dimension string
+	}{
+		{1, "mi", "km", 1.609344, "length"},
+		{12, "inches", "Feet", 1, "length"},
+		{1, "nautical mile", "m", 1852, "length"},
+		{1, "lb", "oz", 16, "weight"},
+		{1, "stone", "lbs", 14, "weight"},
+		{2.5, "t", "kg", 2500, "weight"},
+		{100, "C", "F", 212, "temperature"},
+		{-40, "fahrenheit", "celsius", -40, "temperature"},
+		{0, "k", "°c", -273.15, "temperature"},
+		{98.6, "f", "c", 37, "temperature"},
+		{90, "eur", "USD", 100, "currency"},
+		{1, "EUR", "JPY", 166.6666667, "currency"},
+	}
+
+	for _, tt := range tests {
+		got, dimension, err := ConvertUnit(tt.value, tt.from, tt.to, rates)
+		if err != nil {
+			t.Errorf("%v %s to %s: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-6 || dimension != tt.dimension {
+			t.Errorf("%v %s to %s = %v %s, want %v %s", tt.value, tt.from, tt.to, got, dimension, tt.want, tt.dimension)
+		}
+	}
+}
+
+func TestConvertUnitErrors(t *testing.T) {
+	tests := []struct {
+		from, to string
+		value    float64
+		rates    map[string]float64
+		err      string
+	}{
+		{from: "m", to: "kg", value: 1, err: "cannot convert"},
+		{from: "parsec", to: "m", value: 1, err: "unknown unit"},
+		{from: "USD", to: "EUR", value: 1, err: "needs a rate table"},
+		{from: "USD", to: "EUR", value: 1, rates: map[string]float64{"USD": 1, "EUR": 0}, err: "positive number"},
+		{from: "k", to: "c", value: -1, err: "below absolute zero"},
+		{from: "f", to: "c", value: -500, err: "below absolute zero"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := ConvertUnit(tt.value, tt.from, tt.to, tt.rates)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v %s to %s: err = %v, want it to contain %q", tt.value, tt.from, tt.to, err, tt.err)
+		}
+	}
+}
+
+func TestConvertUnitsHandler(t *testing.T) {
+	dp := NewDataProcessor()
+	// Rate table codes are matched case-insensitively
+	result, err := dp.ConvertUnits(10, "usd", "gbp", map[string]float64{"usd": 1, "gbp": 0.8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["result"] != 8.0 || result["dimension"] != "currency" {
+		t.Errorf("result = %v", result)
+	}
+
+	if _, err := dp.ConvertUnits(math.NaN(), "m", "km", nil); err == nil {
+		t.Error("expected an error for NaN")
+	}
+	if _, err := dp.ConvertUnits(math.Inf(1), "m", "km", nil); err == nil {
+		t.Error("expected an error for infinity")
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseURL splits a URL into its components. Relative URLs are accepted and
+// reported with absolute set to false. Malformed input yields valid=false
+// and the parse error rather than a Go error.
+func (dp *DataProcessor) ParseURL(input string) (map[string]interface{}, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("empty URL provided")
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return map[string]interface{}{
+			"valid": false,
+			"error": err.Error(),
+			"input": input,
+		}, nil
+	}
+
+	hostname := u.Hostname()
+	asciiHost, err := ToASCIIHost(hostname)
+	if err != nil {
+		return map[string]interface{}{
+			"valid": false,
+			"error": fmt.Sprintf("invalid host: %v", err),
+			"input": input,
+		}, nil
+	}
+	unicodeHost, err := ToUnicodeHost(asciiHost)
+	if err != nil {
+		unicodeHost = hostname
+	}
+
+	result := map[string]interface{}{
+		"valid":       true,
+		"absolute":    u.IsAbs(),
+		"scheme":      u.Scheme,
+		"host":        u.Host,
+		"hostname":    hostname,
+		"asciiHost":   asciiHost,
+		"unicodeHost": unicodeHost,
+		"port":        u.Port(),
+		"path":        u.Path,
+		"rawQuery":    u.RawQuery,
+		"query":       queryToMap(u.Query()),
+		"fragment":    u.Fragment,
+		"input":       input,
+	}
+
+	if u.User != nil {
+		result["username"] = u.User.Username()
+	}
+
+	return result, nil
+}
+
+// queryToMap converts url.Values to a JS-friendly map. Keys with a single
+// value map to a string; repeated keys map to an array of strings.
+func queryToMap(values url.Values) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, vals := range values {
+		if len(vals) == 1 {
+			result[key] = vals[0]
+			continue
+		}
+		list := make([]interface{}, len(vals))
+		for i, v := range vals {
+			list[i] = v
+		}
+		result[key] = list
+	}
+	return result
+}
+
+// ParseQueryString decodes a query string (with or without the leading "?")
+// into a map. Repeated keys become arrays.
+func (dp *DataProcessor) ParseQueryString(input string) (map[string]interface{}, error) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), "?")
+
+	values, err := url.ParseQuery(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string: %w", err)
+	}
+
+	return map[string]interface{}{
+		"params": queryToMap(values),
+		"count":  len(values),
+	}, nil
+}
+
+// BuildQueryString encodes a map into a query string with keys in sorted
+// order. Array values produce repeated keys and nil values produce "key=".
+func (dp *DataProcessor) BuildQueryString(params map[string]interface{}) (string, error) {
+	values := url.Values{}
+	for key, value := range params {
+		switch v := value.(type) {
+		case []interface{}:
+			if len(v) == 0 {
+				values[key] = []string{}
+			}
+			for _, item := range v {
+				str, err := queryValueString(item)
+				if err != nil {
+					return "", fmt.Errorf("invalid value for %q: %w", key, err)
+				}
+				values.Add(key, str)
+			}
+		default:
+			str, err := queryValueString(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid value for %q: %w", key, err)
+			}
+			values.Add(key, str)
+		}
+	}
+
+	return values.Encode(), nil
+}
+
+func queryValueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
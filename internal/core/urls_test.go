@@ -0,0 +1,155 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		input string
+		valid bool
+		want  map[string]interface{}
+	}{
+		{
+			input: "https://user@bücher.example:8443/a/b?x=1&x=2&y=z#top",
+			valid: true,
+			want: map[string]interface{}{
+				"scheme":      "https",
+				"hostname":    "bücher.example",
+				"asciiHost":   "xn--bcher-kva.example",
+				"unicodeHost": "bücher.example",
+				"port":        "8443",
+				"path":        "/a/b",
+				"fragment":    "top",
+				"username":    "user",
+				"query":       map[string]interface{}{"x": []interface{}{"1", "2"}, "y": "z"},
+			},
+		},
+		{
+			input: "http://xn--mnchen-3ya.de/",
+			valid: true,
+			want:  map[string]interface{}{"asciiHost": "xn--mnchen-3ya.de", "unicodeHost": "münchen.de"},
+		},
+		{
+			input: "http://[::1]:8080/",
+			valid: true,
+			want:  map[string]interface{}{"hostname": "::1", "asciiHost": "::1", "port": "8080"},
+		},
+		{
+			input: "/relative/path?q",
+			valid: true,
+			want:  map[string]interface{}{"absolute": false, "hostname": "", "path": "/relative/path"},
+		},
+		{input: "http://exa mple.com/", valid: false},
+		{input: "http://under_score.example/", valid: false},
+		{input: "://missing-scheme", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := dp.ParseURL(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result["valid"] != tt.valid {
+				t.Fatalf("valid = %v, want %v (%v)", result["valid"], tt.valid, result["error"])
+			}
+			for key, want := range tt.want {
+				if !reflect.DeepEqual(result[key], want) {
+					t.Errorf("%s = %#v, want %#v", key, result[key], want)
+				}
+			}
+		})
+	}
+
+	if _, err := dp.ParseURL(""); err == nil {
+		t.Error("expected an error for an empty URL")
+	}
+}
+
+func TestBuildQueryString(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{name: "sorted keys", params: map[string]interface{}{"b": "2", "a": "1"}, want: "a=1&b=2"},
+		{name: "escaping", params: map[string]interface{}{"q": "a b&c=d", "ü": "é"}, want: "q=a+b%26c%3Dd&%C3%BC=%C3%A9"},
+		{name: "arrays repeat keys", params: map[string]interface{}{"tag": []interface{}{"x", 2.5, true}}, want: "tag=x&tag=2.5&tag=true"},
+		{name: "empty array", params: map[string]interface{}{"tag": []interface{}{}, "a": "1"}, want: "a=1"},
+		{name: "nil", params: map[string]interface{}{"flag": nil}, want: "flag="},
+		{name: "integral float", params: map[string]interface{}{"n": 3.0, "big": 1e21}, want: "big=1000000000000000000000&n=3"},
+		{name: "empty", params: map[string]interface{}{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dp.BuildQueryString(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"obj": map[string]interface{}{"a": 1}},
+		{"list": []interface{}{[]interface{}{"nested"}}},
+	} {
+		if _, err := dp.BuildQueryString(params); err == nil {
+			t.Errorf("expected an error for %v", params)
+		}
+	}
+}
+
+func TestParseQueryString(t *testing.T) {
+	dp := NewDataProcessor()
+	tests := []struct {
+		input string
+		want  map[string]interface{}
+	}{
+		{"?a=1&b=2", map[string]interface{}{"a": "1", "b": "2"}},
+		{"a=1&a=2&c", map[string]interface{}{"a": []interface{}{"1", "2"}, "c": ""}},
+		{"q=a+b%26c", map[string]interface{}{"q": "a b&c"}},
+		{"", map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		result, err := dp.ParseQueryString(tt.input)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(result["params"], tt.want) {
+			t.Errorf("%q: params = %#v, want %#v", tt.input, result["params"], tt.want)
+		}
+		if result["count"] != len(tt.want) {
+			t.Errorf("%q: count = %v, want %d", tt.input, result["count"], len(tt.want))
+		}
+	}
+
+	if _, err := dp.ParseQueryString("a=%zz"); err == nil {
+		t.Error("expected an error for a bad escape")
+	}
+}
+
+func TestQueryStringRoundTrip(t *testing.T) {
+	dp := NewDataProcessor()
+	params := map[string]interface{}{"a": "1 2", "b": []interface{}{"x", "y"}, "c": ""}
+
+	query, err := dp.BuildQueryString(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := dp.ParseQueryString(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result["params"], params) {
+		t.Errorf("round trip of %q = %#v, want %#v", query, result["params"], params)
+	}
+}
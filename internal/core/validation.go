@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// emailPattern matches an email address; it is anchored for validation and
+// used unanchored to find addresses in text
+const emailPattern = `[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`
+
+var (
+	emailRegex = regexp.MustCompile(`^` + emailPattern + `$`)
+	phoneRegex = regexp.MustCompile(`^\+?[\d\s\-\(\)]{10,}$`)
+)
+
+// ValidateByType checks input against one of the built-in formats: email,
+// url, phone or json. The email check is a quick pattern match: it rejects
+// valid addresses such as quoted local parts and internationalized domains
+// and accepts invalid ones such as a..b@example.com. NormalizeEmail parses
+// addresses properly.
+func ValidateByType(input, validationType string) (bool, string) {
+	switch validationType {
+	case "email":
+		if emailRegex.MatchString(input) {
+			return true, "Valid email address"
+		}
+		return false, "Invalid email format"
+
+	case "url":
+		if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+			return true, "Valid URL"
+		}
+		return false, "URL must start with http:// or https://"
+
+	case "phone":
+		if phoneRegex.MatchString(input) {
+			return true, "Valid phone number format"
+		}
+		return false, "Invalid phone number format"
+
+	case "json":
+		if _, err := CheckJSON(input, DefaultJSONLimits); err != nil {
+			var limitErr *JSONLimitError
+			if errors.As(err, &limitErr) {
+				return false, limitErr.Error()
+			}
+			return false, "Invalid JSON format"
+		}
+		return true, "Valid JSON"
+
+	default:
+		return false, fmt.Sprintf("Unknown validation type: %s", validationType)
+	}
+}
+
+// LuhnValid reports whether a string of digits passes the Luhn checksum
+// used by payment card numbers. Spaces and dashes are ignored.
+func LuhnValid(number string) bool {
+	sum, count := 0, 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		count++
+	}
+	return count > 1 && sum%10 == 0
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxValueBins bounds the number of bins ValueCounts creates
+const MaxValueBins = 10000
+
+// ValueCountOptions controls how ValueCounts tallies values
+type ValueCountOptions struct {
+	// Field reads the value from each item, as a dotted path or JSON
+	// pointer, when the items are objects
+	Field string
+	// IgnoreCase counts strings that differ only in case or surrounding
+	// whitespace together, under the first spelling seen
+	IgnoreCase bool
+	// Bins buckets numeric values into this many equal-width ranges
+	Bins int
+	// Top keeps the most frequent values and folds the rest into an
+	// "other" entry; zero keeps them all
+	Top int
+}
+
+// valueCount is one distinct value or bin and how often it occurs
+type valueCount struct {
+	key        string
+	value      interface{}
+	count      int
+	start, end float64
+}
+
+// percentOf returns count as a percentage of total, to two decimals
+func percentOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 100
+}
+
+// binValues counts numeric values in equal-width bins between their
+// minimum and maximum. Each bin includes its start; the last also includes
+// its end.
+func binValues(values []float64, bins int) []*valueCount {
+	if len(values) == 0 {
+		return []*valueCount{}
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	if lo == hi {
+		bins = 1
+	}
+	width := (hi - lo) / float64(bins)
+
+	counts := make([]*valueCount, bins)
+	for i := range counts {
+		start, end, closing := lo+width*float64(i), lo+width*float64(i+1), ")"
+		if i == bins-1 {
+			end, closing = hi, "]"
+		}
+		counts[i] = &valueCount{
+			value: fmt.Sprintf("[%.6g, %.6g%s", start, end, closing),
+			start: start,
+			end:   end,
+		}
+	}
+	for _, v := range values {
+		i := bins - 1
+		if width > 0 {
+			i = min(int((v-lo)/width), bins-1)
+		}
+		counts[i].count++
+	}
+	return counts
+}
+
+// ValueCounts tallies the distinct values of an array, or of a field of
+// an array of objects, with each value's count and percentage, most
+// frequent first. Missing and null values are reported separately and not
+// counted. With Bins, numeric values are counted in ranges instead and
+// other values are skipped. With Top, the remaining values are summed in
+// an "other" entry.
+func (dp *DataProcessor) ValueCounts(items []interface{}, opts ValueCountOptions) (map[string]interface{}, error) {
+	if opts.Bins < 0 || opts.Top < 0 {
+		return nil, fmt.Errorf("bins and top must not be negative")
+	}
+	if opts.Bins > MaxValueBins {
+		return nil, fmt.Errorf("bins must be at most %d", MaxValueBins)
+	}
+	var field rowField
+	if opts.Field != "" {
+		var err error
+		if field, err = parseRowField(opts.Field); err != nil {
+			return nil, fmt.Errorf("field: %w", err)
+		}
+	}
+
+	missing, skipped := 0, 0
+	var numbers []float64
+	counts := []*valueCount{}
+	byKey := make(map[string]*valueCount)
+	for i, item := range items {
+		value := item
+		if field != nil {
+			value, _ = field.get(item)
+		}
+		if value == nil {
+			missing++
+			continue
+		}
+		if opts.Bins > 0 {
+			if f, ok := numericValue(value); ok {
+				numbers = append(numbers, f)
+			} else {
+				skipped++
+			}
+			continue
+		}
+
+		keyValue := value
+		if s, ok := value.(string); ok && opts.IgnoreCase {
+			keyValue = strings.ToLower(strings.TrimSpace(s))
+		}
+		key, err := groupKey(keyValue)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		c := byKey[key]
+		if c == nil {
+			c = &valueCount{key: key, value: value}
+			byKey[key] = c
+			counts = append(counts, c)
+		}
+		c.count++
+	}
+
+	total := len(items) - missing - skipped
+	distinct := len(counts)
+	if opts.Bins > 0 {
+		counts = binValues(numbers, opts.Bins)
+		distinct = len(counts)
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		if opts.Bins > 0 {
+			return a.start < b.start
+		}
+		return compareGroupValues(a.value, b.value, a.key, b.key) < 0
+	})
+
+	var other interface{}
+	if opts.Top > 0 && len(counts) > opts.Top {
+		rest := 0
+		for _, c := range counts[opts.Top:] {
+			rest += c.count
+		}
+		other = map[string]interface{}{
+			"count":      rest,
+			"percentage": percentOf(rest, total),
+			"distinct":   len(counts) - opts.Top,
+		}
+		counts = counts[:opts.Top]
+	}
+
+	list := make([]interface{}, len(counts))
+	for i, c := range counts {
+		entry := map[string]interface{}{
+			"value":      c.value,
+			"count":      c.count,
+			"percentage": percentOf(c.count, total),
+		}
+		if opts.Bins > 0 {
+			entry["start"] = c.start
+			entry["end"] = c.end
+		}
+		list[i] = entry
+	}
+
+	return map[string]interface{}{
+		"values":   list,
+		"other":    other,
+		"total":    total,
+		"distinct": distinct,
+		"missing":  missing,
+		"skipped":  skipped,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countPairs returns each value and count of a ValueCounts result
+func countPairs(result map[string]interface{}) [][2]interface{} {
+	values := result["values"].([]interface{})
+	pairs := make([][2]interface{}, len(values))
+	for i, v := range values {
+		entry := v.(map[string]interface{})
+		pairs[i] = [2]interface{}{entry["value"], entry["count"]}
+	}
+	return pairs
+}
+
+func TestValueCounts(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{"b", "a", "b", nil, 1.0, "1", "a", "b"}
+	result, err := dp.ValueCounts(items, ValueCountOptions{})
+	if err != nil {
+		t.Fatalf("ValueCounts: %v", err)
+	}
+	// ties are ordered by value, numbers before strings
+	want := [][2]interface{}{{"b", 3}, {"a", 2}, {1.0, 1}, {"1", 1}}
+	if got := countPairs(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+	if result["total"] != 7 || result["distinct"] != 4 || result["missing"] != 1 || result["other"] != nil {
+		t.Errorf("result = %v", result)
+	}
+	if pct := result["values"].([]interface{})[0].(map[string]interface{})["percentage"]; pct != 42.86 {
+		t.Errorf("percentage = %v, want 42.86", pct)
+	}
+}
+
+func TestValueCountsField(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{
+		map[string]interface{}{"user": map[string]interface{}{"plan": "pro"}},
+		map[string]interface{}{"user": map[string]interface{}{"plan": "free"}},
+		map[string]interface{}{"user": map[string]interface{}{"plan": "pro"}},
+		map[string]interface{}{"user": map[string]interface{}{}},
+		"not an object",
+	}
+	result, err := dp.ValueCounts(items, ValueCountOptions{Field: "user.plan"})
+	if err != nil {
+		t.Fatalf("ValueCounts: %v", err)
+	}
+	want := [][2]interface{}{{"pro", 2}, {"free", 1}}
+	if got := countPairs(result); !reflect.DeepEqual(got, want) || result["missing"] != 2 {
+		t.Errorf("values = %v, missing = %v", got, result["missing"])
+	}
+}
+
+func TestValueCountsIgnoreCase(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{"Apple", " apple ", "APPLE", "pear"}
+	result, err := dp.ValueCounts(items, ValueCountOptions{IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("ValueCounts: %v", err)
+	}
+	// counted under the first spelling
+	want := [][2]interface{}{{"Apple", 3}, {"pear", 1}}
+	if got := countPairs(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+}
+
+func TestValueCountsTop(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{"a", "a", "a", "b", "b", "c", "d"}
+	result, err := dp.ValueCounts(items, ValueCountOptions{Top: 2})
+	if err != nil {
+		t.Fatalf("ValueCounts: %v", err)
+	}
+	if got := countPairs(result); !reflect.DeepEqual(got, [][2]interface{}{{"a", 3}, {"b", 2}}) {
+		t.Errorf("values = %v", got)
+	}
+	want := map[string]interface{}{"count": 2, "percentage": 28.57, "distinct": 2}
+	if !reflect.DeepEqual(result["other"], want) {
+		t.Errorf("other = %v, want %v", result["other"], want)
+	}
+	if result["distinct"] != 4 {
+		t.Errorf("distinct = %v, want 4", result["distinct"])
+	}
+}
+
+func TestValueCountsBins(t *testing.T) {
+	dp := NewDataProcessor()
+	items := []interface{}{0.0, 1.0, 2.5, 5.0, 7.5, 9.0, 10.0, "3", "x", nil}
+	result, err := dp.ValueCounts(items, ValueCountOptions{Bins: 2})
+	if err != nil {
+		t.Fatalf("ValueCounts: %v", err)
+	}
+	// the last bin includes the maximum
+	want := []interface{}{
+		map[string]interface{}{"value": "[0, 5)", "count": 4, "percentage": 50.0, "start": 0.0, "end": 5.0},
+		map[string]interface{}{"value": "[5, 10]", "count": 4, "percentage": 50.0, "start": 5.0, "end": 10.0},
+	}
+	if !reflect.DeepEqual(result["values"], want) {
+		t.Errorf("values = %v, want %v", result["values"], want)
+	}
+	if result["total"] != 8 || result["skipped"] != 1 || result["missing"] != 1 {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestBinValues(t *testing.T) {
+	// equal values share one bin
+	counts := binValues([]float64{3, 3, 3}, 5)
+	if len(counts) != 1 || counts[0].count != 3 || counts[0].value != "[3, 3]" {
+		t.Errorf("binValues = %+v", counts[0])
+	}
+	if counts := binValues(nil, 4); len(counts) != 0 {
+		t.Errorf("binValues(nil) = %v", counts)
+	}
+}
+
+func TestValueCountsErrors(t *testing.T) {
+	dp := NewDataProcessor()
+	for _, opts := range []ValueCountOptions{{Bins: -1}, {Top: -1}, {Bins: MaxValueBins + 1}} {
+		if _, err := dp.ValueCounts([]interface{}{1.0}, opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
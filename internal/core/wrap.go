@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// WrapOptions controls how WrapText reflows text
+type WrapOptions struct {
+	// Indent is prepended to the first line of each paragraph
+	Indent string
+	// HangingIndent is prepended to every following line of a paragraph
+	HangingIndent string
+	// PreserveParagraphs keeps blank-line separated paragraphs apart instead
+	// of reflowing all text as one paragraph
+	PreserveParagraphs bool
+}
+
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// WrapText reflows text to lines of at most width characters, breaking at
+// word boundaries. Words longer than the available width are split.
+func WrapText(text string, width int, opts WrapOptions) string {
+	if width < 1 {
+		width = 1
+	}
+
+	paragraphs := []string{text}
+	if opts.PreserveParagraphs {
+		paragraphs = paragraphBreak.Split(text, -1)
+	}
+
+	wrapped := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		words := strings.Fields(p)
+		if len(words) == 0 {
+			continue
+		}
+		wrapped = append(wrapped, wrapParagraph(words, width, opts))
+	}
+
+	return strings.Join(wrapped, "\n\n")
+}
+
+func wrapParagraph(words []string, width int, opts WrapOptions) string {
+	var lines []string
+	var line strings.Builder
+	lineLen := 0
+
+	prefix := func() string {
+		if len(lines) == 0 {
+			return opts.Indent
+		}
+		return opts.HangingIndent
+	}
+	available := func() int {
+		avail := width - utf8.RuneCountInString(prefix())
+		if avail < 1 {
+			avail = 1
+		}
+		return avail
+	}
+	flush := func() {
+		lines = append(lines, prefix()+line.String())
+		line.Reset()
+		lineLen = 0
+	}
+
+	for _, word := range words {
+		wordLen := utf8.RuneCountInString(word)
+
+		if lineLen > 0 && lineLen+1+wordLen > available() {
+			flush()
+		}
+
+		// Split words that can't fit on a line of their own
+		for wordLen > available() {
+			if lineLen > 0 {
+				flush()
+			}
+			runes := []rune(word)
+			line.WriteString(string(runes[:available()]))
+			word = string(runes[available():])
+			wordLen = len(runes) - available()
+			flush()
+		}
+
+		if wordLen == 0 {
+			continue
+		}
+		if lineLen > 0 {
+			line.WriteByte(' ')
+			lineLen++
+		}
+		line.WriteString(word)
+		lineLen += wordLen
+	}
+
+	if lineLen > 0 {
+		flush()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WrapText reflows text to the given width and reports the resulting lines
+func (dp *DataProcessor) WrapText(text string, width int, opts WrapOptions) (map[string]interface{}, error) {
+	if width < 1 {
+		return nil, fmt.Errorf("width must be at least 1")
+	}
+
+	wrapped := WrapText(text, width, opts)
+	lineCount := 0
+	if wrapped != "" {
+		lineCount = strings.Count(wrapped, "\n") + 1
+	}
+
+	return map[string]interface{}{
+		"text":      wrapped,
+		"width":     width,
+		"lineCount": lineCount,
+	}, nil
+}
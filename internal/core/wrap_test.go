@@ -0,0 +1,117 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		opts  WrapOptions
+		want  string
+	}{
+		{
+			name:  "word boundaries",
+			text:  "the quick brown fox jumps over the lazy dog",
+			width: 10,
+			want:  "the quick\nbrown fox\njumps over\nthe lazy\ndog",
+		},
+		{
+			name:  "collapses whitespace",
+			text:  "  a \t b\n\nc  ",
+			width: 80,
+			want:  "a b c",
+		},
+		{
+			name:  "splits long words",
+			text:  "ab abcdefghij c",
+			width: 4,
+			want:  "ab\nabcd\nefgh\nij c",
+		},
+		{
+			name:  "word exactly the width",
+			text:  "abcd ef",
+			width: 4,
+			want:  "abcd\nef",
+		},
+		{
+			name:  "counts runes",
+			text:  "héllo wörld",
+			width: 5,
+			want:  "héllo\nwörld",
+		},
+		{
+			name:  "indents",
+			text:  "one two three four",
+			width: 9,
+			opts:  WrapOptions{Indent: "- ", HangingIndent: "  "},
+			want:  "- one two\n  three\n  four",
+		},
+		{
+			name:  "indent wider than width",
+			text:  "ab",
+			width: 2,
+			opts:  WrapOptions{Indent: ">>>"},
+			want:  ">>>a\nb",
+		},
+		{
+			name:  "paragraphs",
+			text:  "one two\nthree\n\n  \n\nfour five",
+			width: 9,
+			opts:  WrapOptions{PreserveParagraphs: true},
+			want:  "one two\nthree\n\nfour five",
+		},
+		{
+			name:  "zero width",
+			text:  "ab c",
+			width: 0,
+			want:  "a\nb\nc",
+		},
+		{name: "empty", text: " \n ", width: 5, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapText(tt.text, tt.width, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapTextWidth(t *testing.T) {
+	text := strings.Repeat("lorem ipsum dolor sit amet, consectetur adipiscing elit ", 20)
+	opts := WrapOptions{Indent: "  ", HangingIndent: "    "}
+
+	for _, width := range []int{6, 13, 40, 72} {
+		for _, line := range strings.Split(WrapText(text, width, opts), "\n") {
+			if n := utf8.RuneCountInString(line); n > width {
+				t.Errorf("width %d: line %q is %d characters", width, line, n)
+			}
+		}
+	}
+}
+
+func TestWrapTextProcessor(t *testing.T) {
+	dp := NewDataProcessor()
+	result, err := dp.WrapText("a b c", 3, WrapOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["text"] != "a b\nc" || result["lineCount"] != 2 {
+		t.Errorf("got %v", result)
+	}
+
+	result, _ = dp.WrapText("", 3, WrapOptions{})
+	if result["lineCount"] != 0 {
+		t.Errorf("empty text has %v lines", result["lineCount"])
+	}
+
+	if _, err := dp.WrapText("a", 0, WrapOptions{}); err == nil {
+		t.Error("expected an error for zero width")
+	}
+}
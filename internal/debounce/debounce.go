@@ -0,0 +1,84 @@
+// Package debounce coalesces bursts of calls, such as disk writes, into a
+// single delayed call.
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDelay is how long disk writes are held back so bursts coalesce
+const DefaultDelay = 500 * time.Millisecond
+
+// Debouncer runs the most recently triggered function once triggers stop
+// arriving for delay. A steady stream of triggers still runs it at least
+// every maxWait so writes are never postponed indefinitely.
+type Debouncer struct {
+	delay   time.Duration
+	maxWait time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+	fn    func()
+	first time.Time // when the pending run was first triggered
+
+	running sync.Mutex // serializes runs so Flush can wait for one in flight
+}
+
+// New creates a Debouncer. maxWait is raised to delay if it is smaller.
+func New(delay, maxWait time.Duration) *Debouncer {
+	if maxWait < delay {
+		maxWait = delay
+	}
+	return &Debouncer{delay: delay, maxWait: maxWait}
+}
+
+// Trigger schedules fn, replacing any function still waiting to run
+func (d *Debouncer) Trigger(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.fn = fn
+	if d.timer == nil {
+		d.first = time.Now()
+		d.timer = time.AfterFunc(d.delay, d.fire)
+		return
+	}
+
+	wait := d.delay
+	if remaining := d.maxWait - time.Since(d.first); remaining < wait {
+		wait = remaining
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	d.timer.Reset(wait)
+}
+
+// Flush runs the pending function immediately, if any, and waits for a run
+// already in progress to finish
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.mu.Unlock()
+
+	d.fire()
+}
+
+func (d *Debouncer) fire() {
+	d.running.Lock()
+	defer d.running.Unlock()
+
+	d.mu.Lock()
+	fn := d.fn
+	d.fn = nil
+	d.timer = nil
+	d.mu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+}
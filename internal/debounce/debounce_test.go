@@ -0,0 +1,86 @@
+package debounce
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncerCoalesces(t *testing.T) {
+	d := New(20*time.Millisecond, time.Second)
+	var runs, last atomic.Int32
+
+	for i := 1; i <= 5; i++ {
+		d.Trigger(func() {
+			runs.Add(1)
+			last.Store(int32(i))
+		})
+		time.Sleep(2 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if runs.Load() != 1 || last.Load() != 5 {
+		t.Errorf("got %d runs ending with trigger %d, want 1 run of trigger 5", runs.Load(), last.Load())
+	}
+}
+
+func TestDebouncerMaxWait(t *testing.T) {
+	d := New(30*time.Millisecond, 60*time.Millisecond)
+	var runs atomic.Int32
+
+	// Triggers keep arriving faster than the delay for 150ms
+	deadline := time.Now().Add(150 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		d.Trigger(func() { runs.Add(1) })
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runs.Load(); n < 2 {
+		t.Errorf("ran %d times while triggers kept arriving, want at least 2", n)
+	}
+	d.Flush()
+}
+
+func TestDebouncerFlush(t *testing.T) {
+	d := New(time.Hour, time.Hour)
+	var runs atomic.Int32
+
+	d.Flush()
+	if runs.Load() != 0 {
+		t.Fatal("Flush ran with nothing pending")
+	}
+
+	d.Trigger(func() { runs.Add(1) })
+	d.Flush()
+	if runs.Load() != 1 {
+		t.Fatalf("Flush ran %d times, want 1", runs.Load())
+	}
+	d.Flush()
+	if runs.Load() != 1 {
+		t.Errorf("a second Flush ran the function again")
+	}
+}
+
+func TestDebouncerFlushWaitsForRun(t *testing.T) {
+	d := New(time.Millisecond, time.Millisecond)
+	started := make(chan struct{})
+	var done atomic.Bool
+
+	d.Trigger(func() {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		done.Store(true)
+	})
+	<-started
+	d.Flush()
+
+	if !done.Load() {
+		t.Error("Flush returned before the run in progress finished")
+	}
+}
+
+func TestNewRaisesMaxWait(t *testing.T) {
+	d := New(time.Second, time.Millisecond)
+	if d.maxWait != time.Second {
+		t.Errorf("maxWait = %v, want the delay", d.maxWait)
+	}
+}
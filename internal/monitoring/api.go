@@ -0,0 +1,182 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsHandler serves the aggregated request statistics as JSON
+func (m *Monitor) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, m.GetStats())
+	})
+}
+
+// LogsHandler serves the in-memory request logs as JSON. The optional query
+// parameters method, status (e.g. 404 or 5xx), path (prefix) and limit are
+// combined to select a subset.
+func (m *Monitor) LogsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filters, err := parseLogFilters(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+
+		limit := 100
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid limit"})
+				return
+			}
+		}
+
+		results := FilterLogs(m.GetRecentLogs(0), filters...)
+		total := len(results)
+		if limit > 0 && len(results) > limit {
+			results = results[len(results)-limit:]
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"total":   total,
+			"results": results,
+		})
+	})
+}
+
+// InFlightHandler lists requests that are still running, oldest first.
+// The optional threshold query parameter (milliseconds, default 0) hides
+// requests younger than that; the listing request itself is never shown.
+func (m *Monitor) InFlightHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		threshold := 0
+		if v := r.URL.Query().Get("threshold"); v != "" {
+			var err error
+			threshold, err = strconv.Atoi(v)
+			if err != nil || threshold < 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid threshold"})
+				return
+			}
+		}
+
+		self, _ := r.Context().Value(inFlightKey{}).(uint64)
+		requests := make([]InFlightRequest, 0)
+		for _, req := range m.InFlight(time.Duration(threshold) * time.Millisecond) {
+			if req.ID != self {
+				requests = append(requests, req)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"threshold_ms": threshold,
+			"total":        len(requests),
+			"requests":     requests,
+		})
+	})
+}
+
+// LogFilter is a predicate over request logs
+type LogFilter func(RequestLog) bool
+
+// FilterLogs returns the logs that satisfy every filter
+func FilterLogs(logs []RequestLog, filters ...LogFilter) []RequestLog {
+	results := make([]RequestLog, 0, len(logs))
+	for _, log := range logs {
+		matched := true
+		for _, f := range filters {
+			if !f(log) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, log)
+		}
+	}
+	return results
+}
+
+// MethodFilter matches requests with the given HTTP method
+func MethodFilter(method string) LogFilter {
+	return func(log RequestLog) bool {
+		return strings.EqualFold(log.Method, method)
+	}
+}
+
+// StatusFilter matches an exact status code ("404") or a class ("4xx")
+func StatusFilter(status string) (LogFilter, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if len(status) == 3 && strings.HasSuffix(status, "xx") {
+		class, err := strconv.Atoi(status[:1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status class: %s", status)
+		}
+		return func(log RequestLog) bool {
+			return log.Status/100 == class
+		}, nil
+	}
+
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return func(log RequestLog) bool {
+		return log.Status == code
+	}, nil
+}
+
+// PathFilter matches requests whose normalized path is prefix or lies
+// below it, so /api matches /api/logs but not /apix
+func PathFilter(prefix string) LogFilter {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(log RequestLog) bool {
+		path := NormalizePath(log.Path)
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+}
+
+func parseLogFilters(query url.Values) ([]LogFilter, error) {
+	var filters []LogFilter
+
+	if method := query.Get("method"); method != "" {
+		filters = append(filters, MethodFilter(method))
+	}
+	if status := query.Get("status"); status != "" {
+		f, err := StatusFilter(status)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	if path := query.Get("path"); path != "" {
+		filters = append(filters, PathFilter(path))
+	}
+
+	return filters, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
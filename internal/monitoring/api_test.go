@@ -0,0 +1,199 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/ringbuf"
+)
+
+// newTestMonitor returns a monitor that keeps logs in memory only
+func newTestMonitor(logs ...RequestLog) *Monitor {
+	m := &Monitor{
+		logs:        ringbuf.New[RequestLog](maxLogs),
+		lastRequest: time.Now(),
+		inFlight:    make(map[uint64]InFlightRequest),
+		upgraded:    make(map[uint64]struct{}),
+		closed:      true,
+	}
+	for _, log := range logs {
+		m.logs.Push(log)
+	}
+	return m
+}
+
+var testLogs = []RequestLog{
+	{Method: "GET", Path: "/api/logs", Status: 200},
+	{Method: "POST", Path: "/api/sync/doc", Status: 201},
+	{Method: "GET", Path: "/apix", Status: 404},
+	{Method: "GET", Path: "/api", Status: 500},
+	{Method: "DELETE", Path: "/api//sync/", Status: 503},
+}
+
+func TestLogFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter func() (LogFilter, error)
+		want   []string
+	}{
+		{name: "method", filter: func() (LogFilter, error) { return MethodFilter("get"), nil }, want: []string{"/api/logs", "/apix", "/api"}},
+		{name: "exact status", filter: func() (LogFilter, error) { return StatusFilter("404") }, want: []string{"/apix"}},
+		{name: "status class", filter: func() (LogFilter, error) { return StatusFilter("5XX") }, want: []string{"/api", "/api//sync/"}},
+		{name: "path prefix", filter: func() (LogFilter, error) { return PathFilter("/api"), nil }, want: []string{"/api/logs", "/api/sync/doc", "/api", "/api//sync/"}},
+		{name: "path with trailing slash", filter: func() (LogFilter, error) { return PathFilter("/api/"), nil }, want: []string{"/api/logs", "/api/sync/doc", "/api", "/api//sync/"}},
+		{name: "path segment boundary", filter: func() (LogFilter, error) { return PathFilter("/api/sync"), nil }, want: []string{"/api/sync/doc", "/api//sync/"}},
+		{name: "root path", filter: func() (LogFilter, error) { return PathFilter("/"), nil }, want: []string{"/api/logs", "/api/sync/doc", "/apix", "/api", "/api//sync/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.filter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := FilterLogs(testLogs, filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d logs, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, log := range got {
+				if log.Path != tt.want[i] {
+					t.Errorf("log %d path = %s, want %s", i, log.Path, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStatusFilterInvalid(t *testing.T) {
+	for _, status := range []string{"abc", "xxx", "4x"} {
+		if _, err := StatusFilter(status); err == nil {
+			t.Errorf("StatusFilter(%q): expected an error", status)
+		}
+	}
+}
+
+func TestLogsHandler(t *testing.T) {
+	handler := newTestMonitor(testLogs...).LogsHandler()
+
+	tests := []struct {
+		query   string
+		status  int
+		total   int
+		results int
+	}{
+		{query: "", status: http.StatusOK, total: 5, results: 5},
+		{query: "method=GET&status=2xx", status: http.StatusOK, total: 1, results: 1},
+		{query: "path=/api&limit=2", status: http.StatusOK, total: 4, results: 2},
+		{query: "status=oops", status: http.StatusBadRequest},
+		{query: "limit=-1", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/logs?"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var body struct {
+				Total   int          `json:"total"`
+				Results []RequestLog `json:"results"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Total != tt.total || len(body.Results) != tt.results {
+				t.Errorf("total = %d, results = %d, want %d and %d", body.Total, len(body.Results), tt.total, tt.results)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/logs", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestInFlightHandler(t *testing.T) {
+	m := newTestMonitor()
+	mux := http.NewServeMux()
+	started, release := make(chan struct{}), make(chan struct{})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	mux.Handle("/debug/inflight", m.InFlightHandler())
+	handler := m.Middleware(mux)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slow", nil))
+	}()
+	<-started
+
+	get := func(query string) (int, []InFlightRequest) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inflight"+query, nil))
+		var body struct {
+			Total    int               `json:"total"`
+			Requests []InFlightRequest `json:"requests"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, body.Requests
+	}
+
+	// the listing request itself is not shown
+	status, requests := get("")
+	if status != http.StatusOK || len(requests) != 1 || requests[0].Method != http.MethodPost || requests[0].Path != "/slow" {
+		t.Errorf("status %d, requests = %+v", status, requests)
+	}
+	if _, requests := get("?threshold=60000"); len(requests) != 0 {
+		t.Errorf("requests over a minute old = %+v, want none", requests)
+	}
+	for _, query := range []string{"?threshold=-1", "?threshold=soon"} {
+		if status, _ := get(query); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, status, http.StatusBadRequest)
+		}
+	}
+
+	// monitoring polls are not counted in the stats
+	if stats := m.GetStats(); stats["in_flight"] != 1 {
+		t.Errorf("in_flight = %v, want 1", stats["in_flight"])
+	}
+
+	close(release)
+	<-done
+	if requests := m.InFlight(0); len(requests) != 0 {
+		t.Errorf("requests after finishing = %+v, want none", requests)
+	}
+	if stats := m.GetStats(); stats["in_flight"] != 0 {
+		t.Errorf("in_flight = %v, want 0", stats["in_flight"])
+	}
+}
+
+func TestInFlightAfterPanic(t *testing.T) {
+	m := newTestMonitor()
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	func() {
+		defer func() { recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if requests := m.InFlight(0); len(requests) != 0 {
+		t.Errorf("requests = %+v, want none", requests)
+	}
+}
@@ -0,0 +1,95 @@
+package monitoring
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// EndpointStat summarizes the requests made to a single normalized path
+type EndpointStat struct {
+	Path        string  `json:"path"`
+	Count       int     `json:"count"`
+	AvgDuration float64 `json:"avg_duration_ms"`
+	P95Duration int64   `json:"p95_duration_ms"`
+}
+
+// NormalizePath strips the query string and collapses repeated and trailing
+// slashes so requests to the same endpoint share a bucket
+func NormalizePath(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path
+}
+
+// TopEndpoints returns the n busiest paths by request count and the n
+// slowest paths by p95 duration
+func TopEndpoints(logs []RequestLog, n int) (busiest, slowest []EndpointStat) {
+	durations := make(map[string][]int64)
+	for _, log := range logs {
+		path := NormalizePath(log.Path)
+		durations[path] = append(durations[path], log.Duration)
+	}
+
+	stats := make([]EndpointStat, 0, len(durations))
+	for path, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+
+		var total int64
+		for _, d := range ds {
+			total += d
+		}
+
+		// Nearest-rank p95
+		rank := int(math.Ceil(0.95*float64(len(ds)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+
+		stats = append(stats, EndpointStat{
+			Path:        path,
+			Count:       len(ds),
+			AvgDuration: math.Round(float64(total)/float64(len(ds))*100) / 100,
+			P95Duration: ds[rank],
+		})
+	}
+
+	busiest = make([]EndpointStat, len(stats))
+	copy(busiest, stats)
+	sort.Slice(busiest, func(i, j int) bool {
+		if busiest[i].Count != busiest[j].Count {
+			return busiest[i].Count > busiest[j].Count
+		}
+		return busiest[i].Path < busiest[j].Path
+	})
+
+	slowest = make([]EndpointStat, len(stats))
+	copy(slowest, stats)
+	sort.Slice(slowest, func(i, j int) bool {
+		if slowest[i].P95Duration != slowest[j].P95Duration {
+			return slowest[i].P95Duration > slowest[j].P95Duration
+		}
+		if slowest[i].AvgDuration != slowest[j].AvgDuration {
+			return slowest[i].AvgDuration > slowest[j].AvgDuration
+		}
+		return slowest[i].Path < slowest[j].Path
+	})
+
+	if n > 0 && len(busiest) > n {
+		busiest = busiest[:n]
+		slowest = slowest[:n]
+	}
+	return busiest, slowest
+}
@@ -0,0 +1,83 @@
+package monitoring
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/logs", "/api/logs"},
+		{"/api/logs/", "/api/logs"},
+		{"/api//logs?limit=5", "/api/logs"},
+		{"/api/logs#top", "/api/logs"},
+		{"///", "/"},
+		{"", "/"},
+		{"?x=1", "/"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePath(tt.path); got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTopEndpoints(t *testing.T) {
+	var logs []RequestLog
+	add := func(path string, durations ...int64) {
+		for _, d := range durations {
+			logs = append(logs, RequestLog{Path: path, Duration: d})
+		}
+	}
+	add("/api/logs", 1, 2, 3)
+	add("/api/logs/?limit=1", 4)
+	add("/api/stats", 100, 1)
+	// 20 samples: the nearest-rank p95 is the 19th, not the outlier
+	add("/", 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 50, 1000)
+
+	busiest, slowest := TopEndpoints(logs, 2)
+
+	want := []EndpointStat{
+		{Path: "/", Count: 20, AvgDuration: 53.4, P95Duration: 50},
+		{Path: "/api/logs", Count: 4, AvgDuration: 2.5, P95Duration: 4},
+	}
+	if !reflect.DeepEqual(busiest, want) {
+		t.Errorf("busiest = %+v, want %+v", busiest, want)
+	}
+
+	want = []EndpointStat{
+		{Path: "/api/stats", Count: 2, AvgDuration: 50.5, P95Duration: 100},
+		{Path: "/", Count: 20, AvgDuration: 53.4, P95Duration: 50},
+	}
+	if !reflect.DeepEqual(slowest, want) {
+		t.Errorf("slowest = %+v, want %+v", slowest, want)
+	}
+}
+
+func TestTopEndpointsTies(t *testing.T) {
+	logs := []RequestLog{{Path: "/b", Duration: 5}, {Path: "/a", Duration: 5}, {Path: "/c", Duration: 1}}
+
+	busiest, slowest := TopEndpoints(logs, 0)
+	if got := paths(busiest); !reflect.DeepEqual(got, []string{"/a", "/b", "/c"}) {
+		t.Errorf("busiest = %v", got)
+	}
+	if got := paths(slowest); !reflect.DeepEqual(got, []string{"/a", "/b", "/c"}) {
+		t.Errorf("slowest = %v", got)
+	}
+
+	if busiest, slowest := TopEndpoints(nil, 5); len(busiest) != 0 || len(slowest) != 0 {
+		t.Errorf("got %v and %v for no logs", busiest, slowest)
+	}
+}
+
+func paths(stats []EndpointStat) []string {
+	var out []string
+	for _, s := range stats {
+		out = append(out, s.Path)
+	}
+	return out
+}
@@ -1,14 +1,20 @@
 package monitoring
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/mbarlow/local-first/internal/ringbuf"
 )
 
 type RequestLog struct {
@@ -21,19 +27,66 @@ type RequestLog struct {
 	RemoteIP  string    `json:"remote_ip,omitempty"`
 }
 
+const (
+	// maxLogs is how many requests the monitor keeps in memory
+	maxLogs = 1000
+	// DefaultMaxLogBytes caps the approximate memory held by in-memory
+	// request logs, which matters when paths or user agents are very long
+	DefaultMaxLogBytes = 4 << 20
+)
+
 type Monitor struct {
 	logFile string
 	mu      sync.RWMutex
-	logs    []RequestLog
+	logs    *ringbuf.Buffer[RequestLog]
+	pending sync.WaitGroup
+	
+	// closed is set by Flush, after which requests are no longer written
+	// to the log file, so no write is added to pending while Flush waits
+	closed bool
+	
+	// logBytes is the approximate size of logs, bounded by maxLogBytes
+	logBytes    int
+	maxLogBytes int
+	
+	// lastRequest is the time of the last request that was not a
+	// monitoring poll, used to detect an idle server
+	lastRequest time.Time
+	
+	// inFlight holds requests that have started but not finished
+	inFlight     map[uint64]InFlightRequest
+	lastInFlight uint64
+	
+	// upgraded holds the IDs of hijacked connections, such as sync
+	// WebSockets, that are still open
+	upgraded map[uint64]struct{}
 }
 
+// InFlightRequest is a request that is still being handled
+type InFlightRequest struct {
+	ID        uint64    `json:"id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Started   time.Time `json:"started"`
+	RunningMs int64     `json:"running_ms"`
+	RemoteIP  string    `json:"remote_ip,omitempty"`
+}
+
+// inFlightKey is the context key holding a request's in-flight ID
+type inFlightKey struct{}
+
 func NewMonitor() *Monitor {
 	logDir := filepath.Join(".", ".local-first")
 	os.MkdirAll(logDir, 0755)
 	
 	return &Monitor{
 		logFile: filepath.Join(logDir, "requests.jsonl"),
-		logs:    make([]RequestLog, 0),
+		logs:    ringbuf.New[RequestLog](maxLogs),
+		
+		maxLogBytes: DefaultMaxLogBytes,
+		lastRequest: time.Now(),
+		inFlight:    make(map[uint64]InFlightRequest),
+		upgraded:    make(map[uint64]struct{}),
 	}
 }
 
@@ -41,10 +94,19 @@ func (m *Monitor) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		
+		// Track the request until it returns, even if the handler panics
+		id := m.beginRequest(r, start)
+		defer m.endRequest(id)
+		r = r.WithContext(context.WithValue(r.Context(), inFlightKey{}, id))
+		
 		// Create a response writer wrapper to capture status code
 		wrapper := &responseWrapper{
 			ResponseWriter: w,
 			statusCode:     200, // default
+			// an upgraded connection such as a sync WebSocket stays open as
+			// long as the client is connected, so it is not a request in
+			// flight
+			onHijack: func() { m.upgradeRequest(id) },
 		}
 		
 		// Call the next handler
@@ -71,14 +133,28 @@ func (m *Monitor) logRequest(reqLog RequestLog) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	// Add to in-memory logs (keep last 1000)
-	m.logs = append(m.logs, reqLog)
-	if len(m.logs) > 1000 {
-		m.logs = m.logs[1:]
+	// Add to in-memory logs, keeping the last maxLogs within maxLogBytes
+	if old, evicted := m.logs.Push(reqLog); evicted {
+		m.logBytes -= old.size()
+	}
+	m.logBytes += reqLog.size()
+	for m.maxLogBytes > 0 && m.logBytes > m.maxLogBytes && m.logs.Len() > 1 {
+		old, _ := m.logs.DropOldest()
+		m.logBytes -= old.size()
+	}
+	
+	if !isMonitoringPath(reqLog.Path) {
+		m.lastRequest = reqLog.Timestamp
 	}
 	
 	// Write to file
-	go m.writeToFile(reqLog)
+	if !m.closed {
+		m.pending.Add(1)
+		go func() {
+			defer m.pending.Done()
+			m.writeToFile(reqLog)
+		}()
+	}
 	
 	// Print to console in development
 	logMsg := fmt.Sprintf("%s %s %d %v",
@@ -108,60 +184,202 @@ func (m *Monitor) writeToFile(reqLog RequestLog) {
 	file.Write([]byte("\n"))
 }
 
-func (m *Monitor) GetRecentLogs(limit int) []RequestLog {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// beginRequest records a request as in flight and returns its ID
+func (m *Monitor) beginRequest(r *http.Request, start time.Time) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	
-	if limit <= 0 || limit > len(m.logs) {
-		limit = len(m.logs)
+	m.lastInFlight++
+	m.inFlight[m.lastInFlight] = InFlightRequest{
+		ID:       m.lastInFlight,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Started:  start,
+		RemoteIP: r.RemoteAddr,
 	}
+	return m.lastInFlight
+}
+
+// upgradeRequest moves a request whose connection was hijacked from the
+// in-flight set to the open upgraded connections
+func (m *Monitor) upgradeRequest(id uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inFlight, id)
+	m.upgraded[id] = struct{}{}
+}
+
+// endRequest removes a finished request, or a closed upgraded connection
+func (m *Monitor) endRequest(id uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inFlight, id)
+	delete(m.upgraded, id)
+}
+
+// InFlight returns the requests that have been running for at least
+// minAge, oldest first
+func (m *Monitor) InFlight(minAge time.Duration) []InFlightRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	
-	if limit == 0 {
-		return []RequestLog{}
+	now := time.Now()
+	requests := make([]InFlightRequest, 0, len(m.inFlight))
+	for _, req := range m.inFlight {
+		running := now.Sub(req.Started)
+		if running < minAge {
+			continue
+		}
+		req.RunningMs = running.Milliseconds()
+		requests = append(requests, req)
 	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].ID < requests[j].ID
+	})
+	return requests
+}
+
+// SetMaxLogBytes caps the approximate memory used by in-memory request logs.
+// Zero leaves only the count limit.
+func (m *Monitor) SetMaxLogBytes(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxLogBytes = n
+}
+
+// size approximates the memory a log entry holds
+func (r RequestLog) size() int {
+	return 64 + len(r.Method) + len(r.Path) + len(r.UserAgent) + len(r.RemoteIP)
+}
+
+// Flush blocks until all background file writes have completed. Requests
+// logged afterwards are kept in memory but not written to the file, as
+// Flush is called on shutdown. It is safe to call multiple times.
+func (m *Monitor) Flush() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.pending.Wait()
+}
+
+// LastRequest returns when the last non-monitoring request arrived, or when
+// the monitor was created if there has been none
+func (m *Monitor) LastRequest() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastRequest
+}
+
+// isMonitoringPath reports whether a request only polls the monitor itself,
+// so that dashboards watching the server don't keep it from looking idle
+func isMonitoringPath(path string) bool {
+	return path == "/api/stats" || path == "/api/logs" || path == "/debug/inflight"
+}
+
+func (m *Monitor) GetRecentLogs(limit int) []RequestLog {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	
-	start := len(m.logs) - limit
-	result := make([]RequestLog, limit)
-	copy(result, m.logs[start:])
-	
-	return result
+	return m.logs.Recent(limit)
 }
 
 func (m *Monitor) GetStats() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	if len(m.logs) == 0 {
+	// monitoring polls, including the one asking for these stats, are not
+	// counted as in flight
+	inFlight := 0
+	var oldestMs int64
+	for _, req := range m.inFlight {
+		if isMonitoringPath(req.Path) {
+			continue
+		}
+		inFlight++
+		oldestMs = max(oldestMs, time.Since(req.Started).Milliseconds())
+	}
+	
+	logs := m.logs.Recent(0)
+	if len(logs) == 0 {
 		return map[string]interface{}{
-			"total_requests": 0,
-			"avg_duration":   0,
-			"status_codes":   map[string]int{},
+			"total_requests":      0,
+			"avg_duration":        0,
+			"status_codes":        map[string]int{},
+			"busiest_paths":       []EndpointStat{},
+			"slowest_paths":       []EndpointStat{},
+			"last_request":        m.lastRequest,
+			"in_flight":           inFlight,
+			"oldest_in_flight_ms": oldestMs,
+			"upgraded":            len(m.upgraded),
 		}
 	}
 	
 	statusCodes := make(map[string]int)
 	var totalDuration int64
 	
-	for _, log := range m.logs {
+	for _, log := range logs {
 		statusCodes[fmt.Sprintf("%d", log.Status)]++
 		totalDuration += log.Duration
 	}
 	
-	avgDuration := totalDuration / int64(len(m.logs))
+	avgDuration := totalDuration / int64(len(logs))
+	busiest, slowest := TopEndpoints(logs, 10)
 	
 	return map[string]interface{}{
-		"total_requests": len(m.logs),
-		"avg_duration":   avgDuration,
-		"status_codes":   statusCodes,
+		"total_requests":      len(logs),
+		"avg_duration":        avgDuration,
+		"status_codes":        statusCodes,
+		"busiest_paths":       busiest,
+		"slowest_paths":       slowest,
+		"last_request":        m.lastRequest,
+		"in_flight":           inFlight,
+		"oldest_in_flight_ms": oldestMs,
+		"upgraded":            len(m.upgraded),
 	}
 }
 
 type responseWrapper struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	wroteHeader bool
+	onHijack    func()
 }
 
+// WriteHeader records the first status sent, e.g. 206 for range requests
 func (rw *responseWrapper) WriteHeader(code int) {
-	rw.statusCode = code
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
 	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWrapper) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Hijack takes over the connection, as a WebSocket upgrade does. The
+// handshake is written directly to the connection, so the status is
+// recorded as 101.
+func (rw *responseWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !rw.wroteHeader {
+		rw.statusCode = http.StatusSwitchingProtocols
+		rw.wroteHeader = true
+	}
+	if rw.onHijack != nil {
+		rw.onHijack()
+	}
+	return conn, buf, nil
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWrapper) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
\ No newline at end of file
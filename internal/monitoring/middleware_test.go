@@ -0,0 +1,160 @@
+package monitoring
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/synchub"
+)
+
+func TestMiddlewareRecordsStatus(t *testing.T) {
+	files := http.FileServer(http.FS(fstest.MapFS{
+		"app.wasm": {Data: []byte(strings.Repeat("x", 100))},
+	}))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		rng     string
+		want    int
+	}{
+		{
+			name:    "implicit ok",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			want:    http.StatusOK,
+		},
+		{
+			name: "first status wins",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: http.StatusAccepted,
+		},
+		{name: "range request", handler: files.ServeHTTP, rng: "bytes=0-9", want: http.StatusPartialContent},
+		{name: "unsatisfiable range", handler: files.ServeHTTP, rng: "bytes=200-", want: http.StatusRequestedRangeNotSatisfiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMonitor()
+			req := httptest.NewRequest(http.MethodGet, "/app.wasm", nil)
+			if tt.rng != "" {
+				req.Header.Set("Range", tt.rng)
+			}
+			rec := httptest.NewRecorder()
+			m.Middleware(tt.handler).ServeHTTP(rec, req)
+
+			logs := m.GetRecentLogs(0)
+			if len(logs) != 1 {
+				t.Fatalf("got %d logs, want 1", len(logs))
+			}
+			if logs[0].Status != tt.want || rec.Code != tt.want {
+				t.Errorf("logged %d, sent %d, want %d", logs[0].Status, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareUnwrap(t *testing.T) {
+	m := newTestMonitor()
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush through the wrapper: %v", err)
+		}
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !rec.Flushed {
+		t.Error("response was not flushed")
+	}
+}
+
+func TestMiddlewareUpgradedNotInFlight(t *testing.T) {
+	m := newTestMonitor()
+	mux := http.NewServeMux()
+	mux.Handle("/ws/sync", synchub.NewHub(10, 100).LiveHandler())
+	server := httptest.NewServer(m.Middleware(mux))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	request := "GET /ws/sync?doc=notes HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d", resp.StatusCode)
+	}
+
+	// The socket is still open, but no longer counts as in flight
+	if n := m.GetStats()["in_flight"]; n != 0 {
+		t.Errorf("in_flight = %v with an open sync socket, want 0", n)
+	}
+	if reqs := m.InFlight(0); len(reqs) != 0 {
+		t.Errorf("InFlight = %+v, want none", reqs)
+	}
+	if n := m.GetStats()["upgraded"]; n != 1 {
+		t.Errorf("upgraded = %v, want 1", n)
+	}
+
+	// Once the socket closes the upgrade is logged as a 101
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(m.GetRecentLogs(0)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	logs := m.GetRecentLogs(0)
+	if len(logs) != 1 || logs[0].Status != http.StatusSwitchingProtocols {
+		t.Errorf("logs = %+v, want one 101", logs)
+	}
+	if n := m.GetStats()["upgraded"]; n != 0 {
+		t.Errorf("upgraded = %v after the socket closed, want 0", n)
+	}
+}
+
+func TestMonitorMaxLogBytes(t *testing.T) {
+	m := NewMonitor()
+	m.closed = true // keep the test's requests out of the log file
+	entry := RequestLog{Method: "GET", Path: "/" + strings.Repeat("p", 200)}
+	m.SetMaxLogBytes(entry.size() * 5)
+
+	for i := 0; i < 20; i++ {
+		m.logRequest(entry)
+	}
+	if got := len(m.GetRecentLogs(maxLogs)); got != 5 {
+		t.Errorf("kept %d logs, want 5", got)
+	}
+	if m.logBytes != entry.size()*5 {
+		t.Errorf("logBytes = %d, want %d", m.logBytes, entry.size()*5)
+	}
+
+	// Evicting by count keeps the byte total in step
+	m.SetMaxLogBytes(0)
+	small := RequestLog{Method: "GET", Path: "/"}
+	for i := 0; i < maxLogs; i++ {
+		m.logRequest(small)
+	}
+	if m.logBytes != small.size()*maxLogs {
+		t.Errorf("logBytes = %d, want %d", m.logBytes, small.size()*maxLogs)
+	}
+}
@@ -0,0 +1,96 @@
+// Package ringbuf provides a fixed-capacity ring buffer for keeping the most
+// recent items, such as log entries, without growing or re-slicing.
+package ringbuf
+
+// Buffer holds up to a fixed number of items, overwriting the oldest when
+// full. It is not safe for concurrent use; callers hold their own lock.
+type Buffer[T any] struct {
+	items []T
+	start int // index of the oldest item
+	count int
+}
+
+// New creates a buffer holding at most capacity items
+func New[T any](capacity int) *Buffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Buffer[T]{items: make([]T, capacity)}
+}
+
+// Len returns the number of items held
+func (b *Buffer[T]) Len() int {
+	return b.count
+}
+
+// Cap returns the maximum number of items held
+func (b *Buffer[T]) Cap() int {
+	return len(b.items)
+}
+
+// Push appends v. When the buffer is full the oldest item is overwritten
+// and returned with evicted set.
+func (b *Buffer[T]) Push(v T) (old T, evicted bool) {
+	if b.count < len(b.items) {
+		b.items[(b.start+b.count)%len(b.items)] = v
+		b.count++
+		return old, false
+	}
+
+	old = b.items[b.start]
+	b.items[b.start] = v
+	b.start = (b.start + 1) % len(b.items)
+	return old, true
+}
+
+// DropOldest removes and returns the oldest item
+func (b *Buffer[T]) DropOldest() (old T, ok bool) {
+	if b.count == 0 {
+		return old, false
+	}
+
+	var zero T
+	old = b.items[b.start]
+	// Clear the slot so the buffer doesn't keep the item reachable
+	b.items[b.start] = zero
+	b.start = (b.start + 1) % len(b.items)
+	b.count--
+	return old, true
+}
+
+// Last returns a pointer to the newest item so it can be updated in place.
+// The pointer is only valid until the next Push.
+func (b *Buffer[T]) Last() (*T, bool) {
+	if b.count == 0 {
+		return nil, false
+	}
+	return &b.items[(b.start+b.count-1)%len(b.items)], true
+}
+
+// Recent returns a copy of the newest n items, oldest first. n <= 0 or
+// greater than Len returns every item.
+func (b *Buffer[T]) Recent(n int) []T {
+	if n <= 0 || n > b.count {
+		n = b.count
+	}
+
+	out := make([]T, n)
+	skip := b.count - n
+	for i := range out {
+		out[i] = b.items[(b.start+skip+i)%len(b.items)]
+	}
+	return out
+}
+
+// Each calls fn for every item from oldest to newest
+func (b *Buffer[T]) Each(fn func(T)) {
+	for i := 0; i < b.count; i++ {
+		fn(b.items[(b.start+i)%len(b.items)])
+	}
+}
+
+// Reset removes every item
+func (b *Buffer[T]) Reset() {
+	clear(b.items)
+	b.start, b.count = 0, 0
+}
@@ -0,0 +1,99 @@
+package ringbuf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPushEvictsOldest(t *testing.T) {
+	b := New[int](3)
+	for i := 1; i <= 3; i++ {
+		if _, evicted := b.Push(i); evicted {
+			t.Fatalf("push %d evicted before the buffer was full", i)
+		}
+	}
+	for i, want := range []int{1, 2} {
+		old, evicted := b.Push(i + 4)
+		if !evicted || old != want {
+			t.Errorf("push %d evicted %d, %v; want %d", i+4, old, evicted, want)
+		}
+	}
+
+	if got := b.Recent(0); !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Errorf("Recent(0) = %v, want [3 4 5]", got)
+	}
+	if got := b.Recent(2); !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Errorf("Recent(2) = %v, want [4 5]", got)
+	}
+	if got := b.Recent(10); len(got) != 3 {
+		t.Errorf("Recent(10) = %v, want all 3 items", got)
+	}
+	if b.Len() != 3 || b.Cap() != 3 {
+		t.Errorf("len %d, cap %d", b.Len(), b.Cap())
+	}
+
+	var seen []int
+	b.Each(func(v int) { seen = append(seen, v) })
+	if !reflect.DeepEqual(seen, []int{3, 4, 5}) {
+		t.Errorf("Each visited %v", seen)
+	}
+}
+
+func TestDropOldestAndLast(t *testing.T) {
+	b := New[*int](2)
+	if _, ok := b.DropOldest(); ok {
+		t.Error("DropOldest on an empty buffer reported an item")
+	}
+	if _, ok := b.Last(); ok {
+		t.Error("Last on an empty buffer reported an item")
+	}
+
+	one, two, three := 1, 2, 3
+	b.Push(&one)
+	b.Push(&two)
+	b.Push(&three)
+
+	last, _ := b.Last()
+	if **last != 3 {
+		t.Errorf("last = %d, want 3", **last)
+	}
+	*last = &one // updated in place
+	if got := b.Recent(1); *got[0] != 1 {
+		t.Errorf("newest after update = %d, want 1", *got[0])
+	}
+
+	old, ok := b.DropOldest()
+	if !ok || *old != 2 || b.Len() != 1 {
+		t.Errorf("dropped %v, %v; len %d", old, ok, b.Len())
+	}
+	// The freed slot no longer references the item
+	for _, p := range b.items {
+		if p == &two {
+			t.Error("dropped item is still referenced")
+		}
+	}
+
+	// Pushing after a drop fills the freed slot without evicting
+	if _, evicted := b.Push(&two); evicted {
+		t.Error("push into a freed slot evicted an item")
+	}
+	if got := b.Recent(0); *got[0] != 1 || *got[1] != 2 {
+		t.Errorf("order after refill = %d %d, want 1 2", *got[0], *got[1])
+	}
+}
+
+func TestReset(t *testing.T) {
+	b := New[string](0)
+	if b.Cap() != 1 {
+		t.Errorf("cap = %d, want a minimum of 1", b.Cap())
+	}
+	b.Push("a")
+	b.Reset()
+	if b.Len() != 0 || len(b.Recent(0)) != 0 || b.items[0] != "" {
+		t.Errorf("buffer not empty after reset: %+v", b)
+	}
+	b.Push("b")
+	if got := b.Recent(0); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("Recent after reset = %v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package synchub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// pushRequest is the body of a POST to the sync endpoint
+type pushRequest struct {
+	Clock core.VectorClock `json:"clock"`
+	Ops   []core.Operation `json:"ops"`
+}
+
+// Handler serves the sync endpoint. Both methods take the document ID in the
+// doc query parameter:
+//
+//	GET  ?doc=ID&clock={"a":3}   returns operations missing from clock
+//	POST ?doc=ID {"clock":{...},"ops":[...]}   merges ops and returns the
+//	                                           ones the replica still lacks
+//
+// Only operations missing from the replica's clock are returned. Bodies may
+// be gzipped (Content-Encoding: gzip) and responses are gzipped when the
+// client accepts it.
+func (h *Hub) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		docID := r.URL.Query().Get("doc")
+		if !ValidDocID(docID) {
+			writeError(w, http.StatusBadRequest, "missing or invalid doc parameter")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			clock := core.VectorClock{}
+			if v := r.URL.Query().Get("clock"); v != "" {
+				if err := json.Unmarshal([]byte(v), &clock); err != nil {
+					writeError(w, http.StatusBadRequest, "invalid clock: "+err.Error())
+					return
+				}
+			}
+			writeSyncJSON(w, r, h.Pull(docID, clock))
+
+		case http.MethodPost:
+			var req pushRequest
+			if err := decodeSyncBody(r, &req); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("body exceeds %d bytes", tooLarge.Limit))
+					return
+				}
+				writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+				return
+			}
+			if req.Clock == nil {
+				req.Clock = core.VectorClock{}
+			}
+
+			result, err := h.Push(docID, req.Clock, req.Ops)
+			switch {
+			case errors.Is(err, ErrTooManyDocs), errors.Is(err, ErrTooManyOps):
+				writeError(w, http.StatusInsufficientStorage, err.Error())
+			case err != nil:
+				writeError(w, http.StatusBadRequest, err.Error())
+			default:
+				writeSyncJSON(w, r, result)
+			}
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// decodeSyncBody reads a JSON body, gunzipping it if the client sent it
+// compressed
+func decodeSyncBody(r *http.Request, v interface{}) error {
+	if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return core.GunzipJSON(data, v)
+}
+
+// writeSyncJSON writes a sync response, gzipped if the client accepts it
+func writeSyncJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+
+	data, err := core.GzipJSON(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,111 @@
+package synchub
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+func TestHandlerBodyTooLarge(t *testing.T) {
+	handler := http.MaxBytesHandler(NewHub(0, 0).Handler(), 64)
+
+	body, _ := json.Marshal(pushRequest{Ops: []core.Operation{testOp("a", 1), testOp("a", 2)}})
+	if len(body) <= 64 {
+		t.Fatalf("test body of %d bytes is not over the limit", len(body))
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/sync?doc=notes", bytes.NewReader(body))
+	req.ContentLength = -1 // sent chunked, so only reading finds the size
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "exceeds 64 bytes") {
+		t.Errorf("body = %s", rec.Body)
+	}
+}
+
+func TestHandlerSync(t *testing.T) {
+	handler := NewHub(0, 0).Handler()
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body, _ := json.Marshal(pushRequest{Ops: []core.Operation{testOp("a", 1), testOp("a", 2)}})
+	rec := serve(httptest.NewRequest(http.MethodPost, "/api/sync?doc=notes", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("push status = %d: %s", rec.Code, rec.Body)
+	}
+	var pushed SyncResult
+	if err := json.NewDecoder(rec.Body).Decode(&pushed); err != nil || pushed.Added != 2 {
+		t.Errorf("push result %+v, %v", pushed, err)
+	}
+
+	// A gzipped pull of what a replica at {"a":1} is missing
+	req := httptest.NewRequest(http.MethodGet, `/api/sync?doc=notes&clock={"a":1}`, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = serve(req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("response not gzipped: %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pulled SyncResult
+	if err := json.NewDecoder(zr).Decode(&pulled); err != nil {
+		t.Fatal(err)
+	}
+	if got := opIDs(pulled.Ops); len(got) != 1 || got[0] != "a-2" {
+		t.Errorf("pulled %v, want [a-2]", got)
+	}
+
+	// A gzipped push body is accepted too
+	compressed, _ := core.GzipJSON(pushRequest{Ops: []core.Operation{testOp("b", 1)}})
+	req = httptest.NewRequest(http.MethodPost, "/api/sync?doc=notes", bytes.NewReader(compressed))
+	req.Header.Set("Content-Encoding", "gzip")
+	if rec = serve(req); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"added":1`) {
+		t.Errorf("gzipped push: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler := NewHub(1, 0).Handler()
+	push := func(doc string) *http.Request {
+		body, _ := json.Marshal(pushRequest{Ops: []core.Operation{testOp("a", 1)}})
+		return httptest.NewRequest(http.MethodPost, "/api/sync?doc="+doc, bytes.NewReader(body))
+	}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{name: "missing doc", req: httptest.NewRequest(http.MethodGet, "/api/sync", nil), status: http.StatusBadRequest},
+		{name: "invalid clock", req: httptest.NewRequest(http.MethodGet, "/api/sync?doc=notes&clock=nope", nil), status: http.StatusBadRequest},
+		{name: "invalid body", req: httptest.NewRequest(http.MethodPost, "/api/sync?doc=notes", strings.NewReader("{")), status: http.StatusBadRequest},
+		{name: "invalid operation", req: httptest.NewRequest(http.MethodPost, "/api/sync?doc=notes", strings.NewReader(`{"ops":[{"id":"x"}]}`)), status: http.StatusBadRequest},
+		{name: "first document", req: push("notes"), status: http.StatusOK},
+		{name: "too many documents", req: push("todos"), status: http.StatusInsufficientStorage},
+		{name: "wrong method", req: httptest.NewRequest(http.MethodDelete, "/api/sync?doc=notes", nil), status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
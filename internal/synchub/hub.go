@@ -0,0 +1,183 @@
+package synchub
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// Default bounds on the state kept in memory
+const (
+	DefaultMaxDocs = 1000
+	DefaultMaxOps  = 10000
+)
+
+var (
+	// ErrTooManyDocs is returned when a new document would exceed the limit
+	ErrTooManyDocs = errors.New("too many documents")
+	// ErrTooManyOps is returned when a push would grow a document's log
+	// beyond the limit
+	ErrTooManyOps = errors.New("document operation log is full")
+)
+
+var docIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// ValidDocID reports whether id can be used as a document ID
+func ValidDocID(id string) bool {
+	return docIDPattern.MatchString(id)
+}
+
+// Hub relays operation logs between replicas. Each document has its own
+// log; replicas push the operations they have and pull the ones they miss,
+// or subscribe over WebSocket to receive new operations as they arrive.
+type Hub struct {
+	mu      sync.Mutex
+	docs    map[string]*core.OpLog
+	maxDocs int
+	maxOps  int
+	store   Store
+
+	clientsMu    sync.Mutex
+	clients      map[string]map[*liveClient]bool
+	lastClientID int
+}
+
+// NewHub creates an in-memory hub. Non-positive limits use the defaults.
+func NewHub(maxDocs, maxOps int) *Hub {
+	hub, _ := NewHubWithStore(maxDocs, maxOps, MemoryStore{})
+	return hub
+}
+
+// NewHubWithStore creates a hub that loads its documents from store and
+// saves every change back to it
+func NewHubWithStore(maxDocs, maxOps int, store Store) (*Hub, error) {
+	if maxDocs <= 0 {
+		maxDocs = DefaultMaxDocs
+	}
+	if maxOps <= 0 {
+		maxOps = DefaultMaxOps
+	}
+
+	docs, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	return &Hub{
+		docs:    docs,
+		maxDocs: maxDocs,
+		maxOps:  maxOps,
+		store:   store,
+		clients: make(map[string]map[*liveClient]bool),
+	}, nil
+}
+
+// Close writes any pending changes to the store
+func (h *Hub) Close() error {
+	return h.store.Flush()
+}
+
+// SyncResult is what a replica receives after a push or pull
+type SyncResult struct {
+	Ops   []core.Operation `json:"ops"`
+	Clock core.VectorClock `json:"clock"`
+	Added int              `json:"added"`
+}
+
+// Pull returns the operations of a document not covered by clock
+func (h *Hub) Pull(docID string, clock core.VectorClock) SyncResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	log, ok := h.docs[docID]
+	if !ok {
+		return SyncResult{Ops: []core.Operation{}, Clock: core.VectorClock{}}
+	}
+	return SyncResult{Ops: log.Since(clock), Clock: log.Clock.Copy()}
+}
+
+// Push merges a replica's operations into a document and returns the
+// operations the replica is still missing. clock is the replica's own
+// vector clock before the push.
+func (h *Hub) Push(docID string, clock core.VectorClock, ops []core.Operation) (SyncResult, error) {
+	return h.push(docID, clock, ops, nil)
+}
+
+// push stores ops and broadcasts the new ones to live clients other than
+// origin
+func (h *Hub) push(docID string, clock core.VectorClock, ops []core.Operation, origin *liveClient) (SyncResult, error) {
+	for _, op := range ops {
+		if op.ID == "" || op.Replica == "" || op.Clock[op.Replica] < 1 {
+			return SyncResult{}, fmt.Errorf("invalid operation %q: id, replica and clock are required", op.ID)
+		}
+	}
+
+	h.mu.Lock()
+
+	log, ok := h.docs[docID]
+	if !ok {
+		if len(h.docs) >= h.maxDocs {
+			h.mu.Unlock()
+			return SyncResult{}, ErrTooManyDocs
+		}
+		log, _ = core.ParseOpLog("")
+	}
+
+	existing := make(map[string]bool, len(log.Ops))
+	for _, op := range log.Ops {
+		existing[op.ID] = true
+	}
+
+	merged := &core.OpLog{Ops: append([]core.Operation(nil), log.Ops...), Clock: log.Clock.Copy()}
+	added := merged.Merge(&core.OpLog{Ops: ops, Clock: core.VectorClock{}})
+	if len(merged.Ops) > h.maxOps {
+		h.mu.Unlock()
+		return SyncResult{}, ErrTooManyOps
+	}
+	h.docs[docID] = merged
+	// Save only schedules a write; calling it under the lock keeps
+	// concurrent pushes from leaving an older snapshot as the last saved
+	if added > 0 {
+		h.store.Save(docID, merged)
+	}
+	h.mu.Unlock()
+
+	var fresh []core.Operation
+	for _, op := range ops {
+		if !existing[op.ID] {
+			existing[op.ID] = true
+			fresh = append(fresh, op)
+		}
+	}
+	h.broadcast(docID, fresh, merged.Clock.Copy(), origin)
+
+	// The replica already has what it pushed
+	known := clock.Copy()
+	for _, op := range ops {
+		known.Merge(op.Clock)
+	}
+
+	return SyncResult{
+		Ops:   merged.Since(known),
+		Clock: merged.Clock.Copy(),
+		Added: added,
+	}, nil
+}
+
+// Stats returns the number of documents and stored operations
+func (h *Hub) Stats() map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ops := 0
+	for _, log := range h.docs {
+		ops += len(log.Ops)
+	}
+	return map[string]interface{}{
+		"documents":  len(h.docs),
+		"operations": ops,
+	}
+}
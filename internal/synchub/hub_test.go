@@ -0,0 +1,95 @@
+package synchub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// opIDs lists the IDs of ops in order
+func opIDs(ops []core.Operation) []string {
+	ids := make([]string, len(ops))
+	for i, op := range ops {
+		ids[i] = op.ID
+	}
+	return ids
+}
+
+func TestHubPushPull(t *testing.T) {
+	hub := NewHub(0, 0)
+
+	result, err := hub.Push("notes", core.VectorClock{}, []core.Operation{testOp("a", 1), testOp("a", 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 2 || len(result.Ops) != 0 {
+		t.Errorf("first push: added %d, returned %v", result.Added, opIDs(result.Ops))
+	}
+
+	// b learns about a's operations in the response to its own push
+	result, err = hub.Push("notes", core.VectorClock{}, []core.Operation{testOp("b", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opIDs(result.Ops); len(got) != 2 || got[0] != "a-1" || got[1] != "a-2" {
+		t.Errorf("second push returned %v, want [a-1 a-2]", got)
+	}
+	if result.Clock["a"] != 2 || result.Clock["b"] != 1 {
+		t.Errorf("clock = %v", result.Clock)
+	}
+
+	// Pushing known operations again adds nothing
+	result, _ = hub.Push("notes", core.VectorClock{"a": 2, "b": 1}, []core.Operation{testOp("b", 1)})
+	if result.Added != 0 || len(result.Ops) != 0 {
+		t.Errorf("repeated push: added %d, returned %v", result.Added, opIDs(result.Ops))
+	}
+
+	if got := opIDs(hub.Pull("notes", core.VectorClock{"a": 1}).Ops); len(got) != 2 || got[0] != "b-1" || got[1] != "a-2" {
+		t.Errorf("pull returned %v, want [b-1 a-2]", got)
+	}
+	if pulled := hub.Pull("todos", core.VectorClock{}); len(pulled.Ops) != 0 || pulled.Clock == nil {
+		t.Errorf("pull of an unknown document = %+v", pulled)
+	}
+
+	stats := hub.Stats()
+	if stats["documents"] != 1 || stats["operations"] != 3 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestHubPushRejects(t *testing.T) {
+	hub := NewHub(1, 2)
+
+	if _, err := hub.Push("notes", nil, []core.Operation{{ID: "x", Replica: "a"}}); err == nil {
+		t.Error("expected an error for an operation without a clock")
+	}
+	if _, err := hub.Push("notes", nil, []core.Operation{testOp("a", 1), testOp("a", 2), testOp("a", 3)}); !errors.Is(err, ErrTooManyOps) {
+		t.Errorf("err = %v, want ErrTooManyOps", err)
+	}
+	if _, err := hub.Push("notes", nil, []core.Operation{testOp("a", 1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hub.Push("todos", nil, []core.Operation{testOp("a", 1)}); !errors.Is(err, ErrTooManyDocs) {
+		t.Errorf("err = %v, want ErrTooManyDocs", err)
+	}
+	// A rejected push leaves the document as it was
+	if stats := hub.Stats(); stats["documents"] != 1 || stats["operations"] != 1 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestValidDocID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"notes":                   true,
+		"a.b-c_1":                 true,
+		"":                        false,
+		"../etc":                  false,
+		"has space":               false,
+		string(make([]byte, 129)): false,
+	} {
+		if got := ValidDocID(id); got != want {
+			t.Errorf("ValidDocID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
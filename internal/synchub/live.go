@@ -0,0 +1,236 @@
+package synchub
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// liveMessage is exchanged over /ws/sync. Clients send "push" and
+// "presence" messages; the server sends "sync" and "peers" on connect, "ack"
+// in reply to a push, "ops" when another client adds operations, "presence"
+// when a peer's presence changes or it leaves, and "error" for rejected
+// messages.
+type liveMessage struct {
+	Type   string                     `json:"type"`
+	Ops    []core.Operation           `json:"ops,omitempty"`
+	Clock  core.VectorClock           `json:"clock,omitempty"`
+	Added  int                        `json:"added,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+	Client string                     `json:"client,omitempty"`
+	State  json.RawMessage            `json:"state,omitempty"`
+	Left   bool                       `json:"left,omitempty"`
+	Peers  map[string]json.RawMessage `json:"peers,omitempty"`
+}
+
+// liveClient is a WebSocket subscriber to one document
+type liveClient struct {
+	id        string
+	doc       string
+	gzip      bool            // exchange gzipped JSON in binary frames
+	ops       bool            // receive operations, false for presence-only clients
+	presence  json.RawMessage // guarded by Hub.clientsMu
+	conn      *wsConn
+	send      chan []byte
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (c *liveClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// enqueue queues a message without blocking. A client too slow to keep up
+// is disconnected and resyncs when it reconnects.
+func (c *liveClient) enqueue(msg liveMessage) {
+	var data []byte
+	var err error
+	if c.gzip {
+		data, err = core.GzipJSON(msg)
+	} else {
+		data, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return
+	}
+	select {
+	case c.send <- data:
+	case <-c.done:
+	default:
+		log.Printf("Sync client for %s is not keeping up, disconnecting", c.doc)
+		c.close()
+	}
+}
+
+func (h *Hub) subscribe(c *liveClient) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	if h.clients[c.doc] == nil {
+		h.clients[c.doc] = make(map[*liveClient]bool)
+	}
+	h.clients[c.doc][c] = true
+}
+
+func (h *Hub) unsubscribe(c *liveClient) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	delete(h.clients[c.doc], c)
+	if len(h.clients[c.doc]) == 0 {
+		delete(h.clients, c.doc)
+	}
+}
+
+// broadcast sends newly added operations to every client of the document
+// except the one that pushed them
+func (h *Hub) broadcast(docID string, ops []core.Operation, clock core.VectorClock, origin *liveClient) {
+	if len(ops) == 0 {
+		return
+	}
+
+	for _, c := range h.peers(docID, origin) {
+		if c.ops {
+			c.enqueue(liveMessage{Type: "ops", Ops: ops, Clock: clock})
+		}
+	}
+}
+
+// peers returns the clients of a document other than self
+func (h *Hub) peers(docID string, self *liveClient) []*liveClient {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	peers := make([]*liveClient, 0, len(h.clients[docID]))
+	for c := range h.clients[docID] {
+		if c != self {
+			peers = append(peers, c)
+		}
+	}
+	return peers
+}
+
+// LiveHandler serves /ws/sync?doc=ID&clock={...}. On connect the client
+// receives the operations it is missing, then every operation other clients
+// push to the same document, whether over WebSocket or /api/sync. With
+// encoding=gzip, messages in both directions are gzipped JSON sent as
+// binary frames. With ops=false the client only takes part in presence.
+func (h *Hub) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		docID := r.URL.Query().Get("doc")
+		if !ValidDocID(docID) {
+			writeError(w, http.StatusBadRequest, "missing or invalid doc parameter")
+			return
+		}
+		clock := core.VectorClock{}
+		if v := r.URL.Query().Get("clock"); v != "" {
+			if err := json.Unmarshal([]byte(v), &clock); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid clock: "+err.Error())
+				return
+			}
+		}
+
+		conn, err := upgradeWebSocket(w, r)
+		if err != nil {
+			log.Printf("Sync websocket upgrade failed: %v", err)
+			return
+		}
+
+		client := &liveClient{
+			id:   h.nextClientID(),
+			doc:  docID,
+			gzip: r.URL.Query().Get("encoding") == "gzip",
+			ops:  r.URL.Query().Get("ops") != "false",
+			conn: conn,
+			send: make(chan []byte, 64),
+			done: make(chan struct{}),
+		}
+		h.subscribe(client)
+		defer func() {
+			h.unsubscribe(client)
+			h.leavePresence(client)
+			client.close()
+		}()
+
+		go client.writeLoop()
+
+		if client.ops {
+			initial := h.Pull(docID, clock)
+			client.enqueue(liveMessage{Type: "sync", Ops: initial.Ops, Clock: initial.Clock})
+		}
+		client.enqueue(liveMessage{Type: "peers", Client: client.id, Peers: h.presenceOf(docID, client)})
+
+		client.readLoop(h)
+	})
+}
+
+// readLoop handles pushes until the connection closes
+func (c *liveClient) readLoop(h *Hub) {
+	c.conn.conn.SetReadDeadline(time.Now().Add(pongWait))
+	for {
+		data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.conn.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var msg liveMessage
+		if c.gzip {
+			err = core.GunzipJSON(data, &msg)
+		} else {
+			err = json.Unmarshal(data, &msg)
+		}
+		if err == nil && msg.Type == "presence" {
+			if err := h.updatePresence(c, msg.State); err != nil {
+				c.enqueue(liveMessage{Type: "error", Error: err.Error()})
+			}
+			continue
+		}
+		if err != nil || msg.Type != "push" {
+			c.enqueue(liveMessage{Type: "error", Error: "expected a push or presence message"})
+			continue
+		}
+		if msg.Clock == nil {
+			msg.Clock = core.VectorClock{}
+		}
+
+		result, err := h.push(c.doc, msg.Clock, msg.Ops, c)
+		if err != nil {
+			c.enqueue(liveMessage{Type: "error", Error: err.Error()})
+			continue
+		}
+		c.enqueue(liveMessage{Type: "ack", Ops: result.Ops, Clock: result.Clock, Added: result.Added})
+	}
+}
+
+// writeLoop sends queued messages and keepalive pings
+func (c *liveClient) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data := <-c.send:
+			opcode := byte(opText)
+			if c.gzip {
+				opcode = opBinary
+			}
+			if err := c.conn.writeFrame(opcode, data); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.writeFrame(opPing, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
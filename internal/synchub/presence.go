@@ -0,0 +1,73 @@
+package synchub
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// maxPresenceSize bounds one client's presence state, e.g. a cursor and a
+// user name and color
+const maxPresenceSize = 16 << 10
+
+// Presence is ephemeral awareness shared between the live clients of a
+// document. It is only held on the connection, never stored with the
+// document, and is withdrawn as soon as the client disconnects.
+
+func (h *Hub) nextClientID() string {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	h.lastClientID++
+	return fmt.Sprintf("c%d", h.lastClientID)
+}
+
+// presenceOf returns the current presence of every peer of self that has
+// published one
+func (h *Hub) presenceOf(docID string, self *liveClient) map[string]json.RawMessage {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	peers := make(map[string]json.RawMessage)
+	for c := range h.clients[docID] {
+		if c != self && c.presence != nil {
+			peers[c.id] = c.presence
+		}
+	}
+	return peers
+}
+
+// updatePresence records a client's presence and relays it to its peers.
+// A null state clears it.
+func (h *Hub) updatePresence(c *liveClient, state json.RawMessage) error {
+	if len(state) > maxPresenceSize {
+		return fmt.Errorf("presence state exceeds %d bytes", maxPresenceSize)
+	}
+	if string(state) == "null" {
+		state = nil
+	}
+
+	h.clientsMu.Lock()
+	c.presence = state
+	h.clientsMu.Unlock()
+
+	msg := liveMessage{Type: "presence", Client: c.id, State: state, Left: state == nil}
+	for _, peer := range h.peers(c.doc, c) {
+		peer.enqueue(msg)
+	}
+	return nil
+}
+
+// leavePresence tells peers that a disconnected client is gone so no ghost
+// cursors linger
+func (h *Hub) leavePresence(c *liveClient) {
+	h.clientsMu.Lock()
+	had := c.presence != nil
+	c.presence = nil
+	h.clientsMu.Unlock()
+
+	if !had {
+		return
+	}
+	for _, peer := range h.peers(c.doc, c) {
+		peer.enqueue(liveMessage{Type: "presence", Client: c.id, Left: true})
+	}
+}
@@ -0,0 +1,57 @@
+package synchub
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPresence(t *testing.T) {
+	server := httptest.NewServer(NewHub(0, 0).LiveHandler())
+	defer server.Close()
+
+	a := dialLive(t, server, "doc=notes&ops=false")
+	peers := a.receive()
+	if peers.Type != "peers" || len(peers.Peers) != 0 {
+		t.Fatalf("presence-only client got %+v first", peers)
+	}
+	aID := peers.Client
+	a.send(liveMessage{Type: "presence", State: json.RawMessage(`{"cursor":3}`)})
+	a.roundTrip()
+
+	// A new peer sees existing presence on connect
+	b := dialLive(t, server, "doc=notes")
+	if msg := b.receive(); msg.Type != "sync" {
+		t.Fatalf("first message = %+v", msg)
+	}
+	msg := b.receive()
+	if msg.Type != "peers" || string(msg.Peers[aID]) != `{"cursor":3}` {
+		t.Fatalf("peers = %+v", msg)
+	}
+
+	a.send(liveMessage{Type: "presence", State: json.RawMessage(`{"cursor":7}`)})
+	if msg := b.receive(); msg.Type != "presence" || msg.Client != aID || string(msg.State) != `{"cursor":7}` {
+		t.Errorf("update = %+v", msg)
+	}
+
+	// Clearing presence tells peers the client left
+	a.send(liveMessage{Type: "presence", State: json.RawMessage(`null`)})
+	if msg := b.receive(); msg.Type != "presence" || !msg.Left || msg.State != nil {
+		t.Errorf("clear = %+v", msg)
+	}
+
+	big := `"` + strings.Repeat("x", maxPresenceSize) + `"`
+	a.send(liveMessage{Type: "presence", State: json.RawMessage(big)})
+	if msg := a.receive(); msg.Type != "error" || !strings.Contains(msg.Error, "exceeds") {
+		t.Errorf("oversized presence got %+v", msg)
+	}
+
+	// Disconnecting withdraws presence
+	a.send(liveMessage{Type: "presence", State: json.RawMessage(`{"cursor":1}`)})
+	b.receive()
+	a.conn.Close()
+	if msg := b.receive(); msg.Type != "presence" || msg.Client != aID || !msg.Left {
+		t.Errorf("disconnect = %+v", msg)
+	}
+}
@@ -0,0 +1,151 @@
+package synchub
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+	"github.com/mbarlow/local-first/internal/debounce"
+)
+
+// Store persists document logs for the hub
+type Store interface {
+	// Load returns every stored document log by ID
+	Load() (map[string]*core.OpLog, error)
+	// Save records the latest log of a document. The log is not modified
+	// afterwards, so implementations may write it asynchronously. The hub
+	// calls Save with its lock held, so saves arrive in order and must not
+	// block.
+	Save(docID string, log *core.OpLog)
+	// Flush blocks until pending saves have been written
+	Flush() error
+}
+
+// MemoryStore keeps nothing beyond the hub's own in-memory state
+type MemoryStore struct{}
+
+func (MemoryStore) Load() (map[string]*core.OpLog, error) { return map[string]*core.OpLog{}, nil }
+func (MemoryStore) Save(string, *core.OpLog)              {}
+func (MemoryStore) Flush() error                          { return nil }
+
+// FileStore writes each document to <dir>/<docID>.json. Writes for a
+// document are debounced, and each one goes to a temp file that is renamed
+// into place so a crash never leaves a partial file.
+type FileStore struct {
+	dir string
+
+	mu      sync.Mutex
+	writers map[string]*debounce.Debouncer
+	lastErr error
+}
+
+// NewFileStore creates a store in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sync directory: %w", err)
+	}
+	return &FileStore{
+		dir:     dir,
+		writers: make(map[string]*debounce.Debouncer),
+	}, nil
+}
+
+// Load reads all documents in the directory. Unreadable files are logged
+// and skipped so one corrupt document doesn't take down the hub.
+func (s *FileStore) Load() (map[string]*core.OpLog, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]*core.OpLog)
+	for _, entry := range entries {
+		docID, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !ValidDocID(docID) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			log.Printf("Skipping sync document %s: %v", docID, err)
+			continue
+		}
+		oplog, err := core.ParseOpLog(string(data))
+		if err != nil {
+			log.Printf("Skipping sync document %s: %v", docID, err)
+			continue
+		}
+		docs[docID] = oplog
+	}
+	return docs, nil
+}
+
+// Save schedules a debounced write of the document
+func (s *FileStore) Save(docID string, oplog *core.OpLog) {
+	s.mu.Lock()
+	w, ok := s.writers[docID]
+	if !ok {
+		w = debounce.New(debounce.DefaultDelay, 2*time.Second)
+		s.writers[docID] = w
+	}
+	s.mu.Unlock()
+
+	w.Trigger(func() {
+		if err := s.write(docID, oplog); err != nil {
+			log.Printf("Failed to save sync document %s: %v", docID, err)
+			s.mu.Lock()
+			s.lastErr = err
+			s.mu.Unlock()
+		}
+	})
+}
+
+// write stores a document atomically via a temp file and rename
+func (s *FileStore) write(docID string, oplog *core.OpLog) error {
+	data, err := json.Marshal(oplog)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, docID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, docID+".json"))
+}
+
+// Flush writes all pending documents and returns the last write error, if
+// any
+func (s *FileStore) Flush() error {
+	s.mu.Lock()
+	writers := make([]*debounce.Debouncer, 0, len(s.writers))
+	for _, w := range s.writers {
+		writers = append(writers, w)
+	}
+	s.mu.Unlock()
+
+	for _, w := range writers {
+		w.Flush()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.lastErr
+	s.lastErr = nil
+	return err
+}
@@ -0,0 +1,151 @@
+package synchub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+func testOp(replica string, counter int) core.Operation {
+	return core.Operation{
+		ID:        fmt.Sprintf("%s-%d", replica, counter),
+		Replica:   replica,
+		Clock:     core.VectorClock{replica: counter},
+		Timestamp: int64(counter),
+		Data:      counter,
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		docs map[string][]core.Operation
+	}{
+		{name: "empty"},
+		{
+			name: "one document",
+			docs: map[string][]core.Operation{"notes": {testOp("a", 1), testOp("a", 2)}},
+		},
+		{
+			name: "several documents",
+			docs: map[string][]core.Operation{
+				"notes": {testOp("a", 1)},
+				"todos": {testOp("a", 1), testOp("b", 1), testOp("b", 2)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := NewFileStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hub, err := NewHubWithStore(0, 0, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for docID, ops := range tt.docs {
+				if _, err := hub.Push(docID, core.VectorClock{}, ops); err != nil {
+					t.Fatalf("push %s: %v", docID, err)
+				}
+			}
+			if err := hub.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			reopened, err := NewFileStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			docs, err := reopened.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(docs) != len(tt.docs) {
+				t.Fatalf("loaded %d documents, want %d", len(docs), len(tt.docs))
+			}
+			for docID, ops := range tt.docs {
+				if got := len(docs[docID].Ops); got != len(ops) {
+					t.Errorf("%s: loaded %d ops, want %d", docID, got, len(ops))
+				}
+			}
+		})
+	}
+}
+
+func TestFileStoreConcurrentPushesSaveLatest(t *testing.T) {
+	const replicas, perReplica = 8, 25
+
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub, err := NewHubWithStore(0, 0, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for r := 0; r < replicas; r++ {
+		wg.Add(1)
+		go func(replica string) {
+			defer wg.Done()
+			for i := 1; i <= perReplica; i++ {
+				if _, err := hub.Push("doc", core.VectorClock{}, []core.Operation{testOp(replica, i)}); err != nil {
+					t.Errorf("push: %v", err)
+				}
+			}
+		}(fmt.Sprintf("r%d", r))
+	}
+	wg.Wait()
+	if err := hub.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	docs, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(docs["doc"].Ops); got != replicas*perReplica {
+		t.Fatalf("saved %d ops, want %d", got, replicas*perReplica)
+	}
+}
+
+func TestFileStoreLoadSkipsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"notes.json":     `{"ops":[{"id":"a-1","replica":"a","clock":{"a":1}}],"clock":{"a":1}}`,
+		"corrupt.json":   `{"ops":`,
+		"bad name!.json": `{}`,
+		"readme.txt":     `not a document`,
+		"notes.1234.tmp": `{}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Mkdir(filepath.Join(dir, "todos.json"), 0755)
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub, err := NewHubWithStore(0, 0, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := hub.Stats(); stats["documents"] != 1 || stats["operations"] != 1 {
+		t.Errorf("stats = %v, want only the notes document", stats)
+	}
+	if pulled := hub.Pull("notes", core.VectorClock{}); len(pulled.Ops) != 1 || pulled.Clock["a"] != 1 {
+		t.Errorf("pulled %+v", pulled)
+	}
+}
@@ -0,0 +1,261 @@
+package synchub
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mbarlow/local-first/internal/core"
+)
+
+// testClient is a minimal WebSocket client for exercising the hub
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialLive opens a WebSocket to the server's root with the given query
+func dialLive(t *testing.T, server *httptest.Server, query string) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET /?" + query + " HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d", resp.StatusCode)
+	}
+	// The accept value for the sample key from RFC 6455
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return &testClient{t: t, conn: conn, r: r}
+}
+
+// writeFrame sends a masked frame
+func (c *testClient) writeFrame(fin bool, opcode byte, payload []byte) {
+	c.t.Helper()
+	head := []byte{opcode, 0x80}
+	if fin {
+		head[0] |= 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		head[1] |= byte(n)
+	case n <= 0xFFFF:
+		head[1] |= 126
+		head = binary.BigEndian.AppendUint16(head, uint16(n))
+	default:
+		head[1] |= 127
+		head = binary.BigEndian.AppendUint64(head, uint64(n))
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	head = append(head, mask[:]...)
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	if _, err := c.conn.Write(append(head, masked...)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) send(msg liveMessage) {
+	c.t.Helper()
+	data, _ := json.Marshal(msg)
+	c.writeFrame(true, opText, data)
+}
+
+// roundTrip waits until the server has handled every message sent so far.
+// Messages are handled in order and a ping is answered before the next one
+// is read, so the pong proves the earlier ones are done.
+func (c *testClient) roundTrip() {
+	c.t.Helper()
+	c.writeFrame(true, opPing, nil)
+	for {
+		if opcode, _ := c.readFrame(); opcode == opPong {
+			return
+		}
+	}
+}
+
+// readFrame reads one unmasked server frame
+func (c *testClient) readFrame() (byte, []byte) {
+	c.t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+// receive reads the next data message, decoding gzipped binary frames
+func (c *testClient) receive() liveMessage {
+	c.t.Helper()
+	for {
+		opcode, payload := c.readFrame()
+		var msg liveMessage
+		switch opcode {
+		case opText:
+			if err := json.Unmarshal(payload, &msg); err != nil {
+				c.t.Fatal(err)
+			}
+		case opBinary:
+			if err := core.GunzipJSON(payload, &msg); err != nil {
+				c.t.Fatal(err)
+			}
+		default:
+			continue
+		}
+		return msg
+	}
+}
+
+func TestLiveHandlerSync(t *testing.T) {
+	hub := NewHub(0, 0)
+	hub.Push("notes", nil, []core.Operation{testOp("a", 1), testOp("a", 2)})
+	server := httptest.NewServer(hub.LiveHandler())
+	defer server.Close()
+
+	a := dialLive(t, server, `doc=notes&clock={"a":1}`)
+	if msg := a.receive(); msg.Type != "sync" || len(msg.Ops) != 1 || msg.Ops[0].ID != "a-2" {
+		t.Fatalf("first message = %+v", msg)
+	}
+	if msg := a.receive(); msg.Type != "peers" || msg.Client == "" {
+		t.Fatalf("second message = %+v", msg)
+	}
+
+	b := dialLive(t, server, "doc=notes&encoding=gzip")
+	if msg := b.receive(); msg.Type != "sync" || len(msg.Ops) != 2 {
+		t.Fatalf("gzip client sync = %+v", msg)
+	}
+	b.receive()
+
+	// A push from a is acknowledged to a and relayed to b
+	a.send(liveMessage{Type: "push", Clock: core.VectorClock{"a": 2}, Ops: []core.Operation{testOp("c", 1)}})
+	if msg := a.receive(); msg.Type != "ack" || msg.Added != 1 || len(msg.Ops) != 0 {
+		t.Errorf("ack = %+v", msg)
+	}
+	if msg := b.receive(); msg.Type != "ops" || len(msg.Ops) != 1 || msg.Ops[0].ID != "c-1" || msg.Clock["c"] != 1 {
+		t.Errorf("relayed = %+v", msg)
+	}
+
+	// Pushes over HTTP reach live clients too
+	hub.Push("notes", nil, []core.Operation{testOp("d", 1)})
+	if msg := a.receive(); msg.Type != "ops" || msg.Ops[0].ID != "d-1" {
+		t.Errorf("relayed HTTP push = %+v", msg)
+	}
+
+	a.send(liveMessage{Type: "subscribe"})
+	if msg := a.receive(); msg.Type != "error" {
+		t.Errorf("unknown message type got %+v", msg)
+	}
+}
+
+func TestLiveHandlerFrames(t *testing.T) {
+	server := httptest.NewServer(NewHub(0, 0).LiveHandler())
+	defer server.Close()
+
+	c := dialLive(t, server, "doc=notes")
+	c.receive()
+	c.receive()
+
+	c.writeFrame(true, opPing, []byte("hi"))
+	if opcode, payload := c.readFrame(); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("ping answered with opcode %d %q", opcode, payload)
+	}
+
+	// A fragmented message is reassembled before it is handled
+	data, _ := json.Marshal(liveMessage{Type: "push", Ops: []core.Operation{testOp("a", 1)}})
+	half := len(data) / 2
+	c.writeFrame(false, opText, data[:half])
+	c.writeFrame(true, opContinuation, data[half:])
+	if msg := c.receive(); msg.Type != "ack" || msg.Added != 1 {
+		t.Errorf("fragmented push got %+v", msg)
+	}
+
+	c.writeFrame(true, opClose, nil)
+	if opcode, _ := c.readFrame(); opcode != opClose {
+		t.Errorf("close answered with opcode %d", opcode)
+	}
+}
+
+func TestLiveHandlerRejects(t *testing.T) {
+	handler := NewHub(0, 0).LiveHandler()
+	tests := []struct {
+		name    string
+		target  string
+		headers map[string]string
+		status  int
+	}{
+		{name: "invalid doc", target: "/?doc=../x", status: http.StatusBadRequest},
+		{name: "invalid clock", target: "/?doc=notes&clock=nope", status: http.StatusBadRequest},
+		{name: "plain request", target: "/?doc=notes", status: http.StatusUpgradeRequired},
+		{
+			name:    "old version",
+			target:  "/?doc=notes",
+			headers: map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8"},
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "missing key",
+			target:  "/?doc=notes",
+			headers: map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13"},
+			status:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}